@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
-	"regexp"
-	"strings"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"backend/internal/config"
 	"backend/internal/database"
 	"backend/internal/models"
+	"backend/pkg/content"
+	"backend/pkg/scheduler"
 
 	"gorm.io/gorm"
 )
@@ -76,8 +81,14 @@ func main() {
 		log.Fatal("Failed to seed users:", err)
 	}
 
-	// Seed posts
-	if err := seedPosts(db, 1000); err != nil {
+	// Seed posts, stopping between batches on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := seedPosts(ctx, db, 1000); err != nil {
+		var interrupted *scheduler.InterruptedError
+		if errors.As(err, &interrupted) {
+			log.Fatalf("Seeding interrupted: created %d of %d posts", interrupted.Done, interrupted.Total)
+		}
 		log.Fatal("Failed to seed posts:", err)
 	}
 
@@ -96,7 +107,7 @@ func seedCategories(db *gorm.DB) error {
 	for i, name := range categoryNames {
 		category := models.Category{
 			Name:        name,
-			Slug:        generateSlug(name),
+			Slug:        content.Slug(name),
 			Description: fmt.Sprintf("Content related to %s", name),
 			CreatedAt:   time.Now().Add(-time.Duration(i*24) * time.Hour),
 			UpdatedAt:   time.Now().Add(-time.Duration(i*24) * time.Hour),
@@ -127,6 +138,7 @@ func seedUsers(db *gorm.DB) error {
 			Password: "$2a$10$4qY2.zjJhKj8MiL6DX0YJ.UjG7I9x9UlC3FhJ4q8m6h8nZ1pM5f1C", // password: "admin123"
 			Role:     "admin",
 			IsActive: true,
+			EmailVerified: true,
 			CreatedAt: time.Now().Add(-30 * 24 * time.Hour),
 			UpdatedAt: time.Now().Add(-30 * 24 * time.Hour),
 		},
@@ -136,6 +148,7 @@ func seedUsers(db *gorm.DB) error {
 			Password: "$2a$10$4qY2.zjJhKj8MiL6DX0YJ.UjG7I9x9UlC3FhJ4q8m6h8nZ1pM5f1C", // password: "admin123"
 			Role:     "editor",
 			IsActive: true,
+			EmailVerified: true,
 			CreatedAt: time.Now().Add(-25 * 24 * time.Hour),
 			UpdatedAt: time.Now().Add(-25 * 24 * time.Hour),
 		},
@@ -145,6 +158,7 @@ func seedUsers(db *gorm.DB) error {
 			Password: "$2a$10$4qY2.zjJhKj8MiL6DX0YJ.UjG7I9x9UlC3FhJ4q8m6h8nZ1pM5f1C", // password: "admin123"
 			Role:     "author",
 			IsActive: true,
+			EmailVerified: true,
 			CreatedAt: time.Now().Add(-20 * 24 * time.Hour),
 			UpdatedAt: time.Now().Add(-20 * 24 * time.Hour),
 		},
@@ -160,7 +174,7 @@ func seedUsers(db *gorm.DB) error {
 	return nil
 }
 
-func seedPosts(db *gorm.DB, count int) error {
+func seedPosts(ctx context.Context, db *gorm.DB, count int) error {
 	var existingCount int64
 	db.Model(&models.Post{}).Count(&existingCount)
 	
@@ -186,6 +200,10 @@ func seedPosts(db *gorm.DB, count int) error {
 	
 	batchSize := 100
 	for i := 0; i < count; i += batchSize {
+		if err := scheduler.Checkpoint(ctx, i, count); err != nil {
+			return err
+		}
+
 		var posts []models.Post
 		end := i + batchSize
 		if end > count {
@@ -194,7 +212,7 @@ func seedPosts(db *gorm.DB, count int) error {
 		
 		for j := i; j < end; j++ {
 			title := fmt.Sprintf("%s %d", sampleTitles[rand.Intn(len(sampleTitles))], j+1)
-			content := fmt.Sprintf("%s\n\n%s\n\n%s", 
+			body := fmt.Sprintf("%s\n\n%s\n\n%s", 
 				sampleContents[rand.Intn(len(sampleContents))],
 				sampleContents[rand.Intn(len(sampleContents))],
 				sampleContents[rand.Intn(len(sampleContents))],
@@ -204,15 +222,14 @@ func seedPosts(db *gorm.DB, count int) error {
 			
 			post := models.Post{
 				Title:       title,
-				Slug:        generateSlug(title),
-				Content:     content,
-				Excerpt:     content[:100] + "...",
+				Content:     body,
 				Status:      getRandomStatus(),
 				AuthorID:    users[rand.Intn(len(users))].ID,
 				CategoryID:  categories[rand.Intn(len(categories))].ID,
 				CreatedAt:   createdAt,
 				UpdatedAt:   createdAt,
 			}
+			content.Process(&post)
 			
 			posts = append(posts, post)
 		}
@@ -230,18 +247,6 @@ func seedPosts(db *gorm.DB, count int) error {
 	return nil
 }
 
-func generateSlug(title string) string {
-	// Simple slug generation
-	slug := strings.ToLower(title)
-	slug = regexp.MustCompile(`[^a-z0-9\s-]`).ReplaceAllString(slug, "")
-	slug = regexp.MustCompile(`\s+`).ReplaceAllString(slug, "-")
-	slug = regexp.MustCompile(`-+`).ReplaceAllString(slug, "-")
-	slug = strings.Trim(slug, "-")
-	
-	// Add timestamp to ensure uniqueness
-	return fmt.Sprintf("%s-%d", slug, time.Now().Unix())
-}
-
 func getRandomStatus() string {
 	statuses := []string{"published", "draft", "published", "published"} // More published posts
 	return statuses[rand.Intn(len(statuses))]
@@ -3,18 +3,36 @@ package main
 import (
 	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/graphql"
 	"backend/internal/handlers"
 	"backend/internal/middleware"
+	"backend/internal/models"
 	"backend/internal/repositories"
 	"backend/internal/routes"
 	"backend/internal/services"
+	"backend/internal/startup"
+	"backend/pkg/cache"
+	"backend/pkg/events"
+	"backend/pkg/health"
+	"backend/pkg/lifecycle"
 	"backend/pkg/logger"
 	"backend/pkg/metrics"
+	"backend/pkg/scheduler"
+	"backend/pkg/tracing"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
@@ -35,27 +53,51 @@ func main() {
 		zap.String("port", cfg.Server.Port),
 	)
 
+	// Unsafe security settings stop production; elsewhere they only warn
+	if issues := cfg.ValidateSecurity(); len(issues) > 0 {
+		for _, issue := range issues {
+			appLogger.Warn("Unsafe security configuration",
+				zap.String("setting", issue.Setting),
+				zap.String("problem", issue.Problem),
+			)
+		}
+		if cfg.App.Environment == "production" {
+			appLogger.Fatal("Refusing to start with unsafe security configuration",
+				zap.Int("issues", len(issues)),
+			)
+		}
+	}
+
 	// Initialize metrics
 	metrics.SetSystemInfo("1.0.0", runtime.Version(), cfg.Environment)
 
-	// Initialize database
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Name,
-	)
+	// Initialize tracing; without an OTLP endpoint spans are not recorded
+	tracerProvider, err := tracing.NewProvider(context.Background(), &cfg.Tracing)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
 
-	db, err := database.Connect(dsn)
+	// Initialize database
+	db, err := database.Open(&cfg.Database)
 	if err != nil {
 		appLogger.Fatal("Failed to connect to database", zap.Error(err))
 	}
+	if err := db.Use(tracing.NewGormPlugin(tracerProvider)); err != nil {
+		appLogger.Fatal("Failed to trace database queries", zap.Error(err))
+	}
 
-	appLogger.Info("Database connected successfully",
-		zap.String("host", cfg.Database.Host),
-		zap.String("database", cfg.Database.Name),
-	)
+	if cfg.Database.Driver == database.DriverSQLite {
+		appLogger.Info("Database connected successfully",
+			zap.String("driver", cfg.Database.Driver),
+			zap.String("path", cfg.Database.SQLitePath),
+		)
+		appLogger.Warn("SQLite has no FULLTEXT indexes or query timeouts; post search uses LIKE matching and DB_QUERY_TIMEOUT is ignored")
+	} else {
+		appLogger.Info("Database connected successfully",
+			zap.String("host", cfg.Database.Host),
+			zap.String("database", cfg.Database.Name),
+		)
+	}
 
 	// Auto migrate (including new RefreshToken model)
 	if err := database.AutoMigrate(db); err != nil {
@@ -66,26 +108,165 @@ func main() {
 	userRepo := repositories.NewUserRepository(db)
 	postRepo := repositories.NewPostRepository(db)
 	categoryRepo := repositories.NewCategoryRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
 	commentRepo := repositories.NewCommentRepository(db)
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
+	revokedTokenRepo := repositories.NewRevokedAccessTokenRepository(db)
+	passwordResetRepo := repositories.NewPasswordResetTokenRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	postStatsRepo := repositories.NewPostStatsRepository(db)
+	fileUploadRepo := repositories.NewFileUploadRepository(db)
+	statsRepo := repositories.NewStatsRepository(db)
+	maintenanceWindowRepo := repositories.NewMaintenanceWindowRepository(db)
+	auditLogRepo := repositories.NewAuditLogRepository(db)
+
+	// Initialize event bus
+	eventBus := events.NewBus()
+
+	// In-process caches register here so admins can flush them together
+	cacheRegistry := cache.NewRegistry()
 
 	// Initialize services
-	jwtService := services.NewJWTService(refreshTokenRepo)
-	authService := services.NewAuthService(userRepo, jwtService, cfg)
-	postService := services.NewPostService(postRepo, userRepo, categoryRepo)
+	jwtService := services.NewJWTService(refreshTokenRepo, revokedTokenRepo, cfg)
+	notifiers, emailQueue := services.NewNotifiers(&cfg.Notify)
+	authService := services.NewAuthService(userRepo, passwordResetRepo, jwtService, cfg, notifiers...)
+	postService := services.NewPostService(repositories.Repositories{Posts: postRepo, Users: userRepo, Categories: categoryRepo, Tags: tagRepo}, repositories.NewUnitOfWork(db), cfg, eventBus)
 	categoryService := services.NewCategoryService(categoryRepo)
-	commentService := services.NewCommentService(commentRepo, postRepo, userRepo)
-	storageService := services.NewStorageService(cfg)
+	commentService := services.NewCommentService(commentRepo, postRepo, userRepo, cfg, eventBus)
+	storageService, err := services.NewStorageService(cfg, fileUploadRepo)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize storage", zap.Error(err))
+	}
+	postCountService := services.NewPostCountService(postRepo, categoryRepo)
+	postCountService.Subscribe(eventBus)
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, postRepo, commentRepo, cfg, notifiers...)
+	notificationService.Subscribe(eventBus)
+	draftArchiveService := services.NewDraftArchiveService(postRepo, cfg, eventBus)
+	trashPurgeService := services.NewTrashPurgeService(postRepo, cfg)
+	scheduledPublishService := services.NewScheduledPublishService(postRepo, eventBus)
+	postStatsService := services.NewPostStatsService(postRepo, postStatsRepo)
+	userService := services.NewUserService(userRepo, postRepo, jwtService)
+	statsService := services.NewStatsService(statsRepo, cfg)
+	maintenanceService := services.NewMaintenanceService(maintenanceWindowRepo, cacheRegistry)
+	auditLogService := services.NewAuditLogService(auditLogRepo, cfg)
+	var webhookDispatcher *services.WebhookDispatcher
+	if len(cfg.Webhook.URLs) > 0 {
+		webhookDispatcher = services.NewWebhookDispatcher(&cfg.Webhook)
+		webhookDispatcher.Subscribe(eventBus)
+	}
+
+	// Verify dependencies once before serving traffic
+	startupChecker := health.NewHealthChecker()
+	startupChecker.AddChecker("database", health.NewDatabaseChecker(db))
+	startupChecker.AddChecker("migrations", health.NewFuncChecker("migrations", database.CheckMigrations(db)))
+	startupChecker.AddChecker("storage", health.NewFuncChecker("storage", storageService.HealthCheck))
+
+	selfCheck := startup.SelfCheck(context.Background(), startupChecker, "database", "migrations", "storage")
+	startup.LogSummary(appLogger, cfg, selfCheck)
+	if !selfCheck.Passed() && cfg.App.FailOnUnhealthyStartup {
+		appLogger.Fatal("Refusing to start with unhealthy dependencies",
+			zap.Strings("failed_checks", selfCheck.Failed),
+		)
+	}
+
+	// Bootstrap the initial admin account if configured
+	if cfg.Auth.BootstrapAdminEmail != "" {
+		admin, err := authService.BootstrapAdmin(&models.RegisterRequest{
+			Username: cfg.Auth.BootstrapAdminUsername,
+			Email:    cfg.Auth.BootstrapAdminEmail,
+			Password: cfg.Auth.BootstrapAdminPassword,
+			Name:     "Administrator",
+		})
+		if err != nil {
+			appLogger.Fatal("Failed to bootstrap admin account", zap.Error(err))
+		}
+		appLogger.Info("Admin account ready",
+			zap.String("email", admin.Email),
+			zap.String("role", admin.Role),
+		)
+	}
+
+	// Register background workers; they start once the server is listening.
+	// Workers stop in reverse order, so the scheduler stops before pending
+	// notifications from its last runs are drained, and those are drained
+	// before the email queue is flushed.
+	workers := lifecycle.NewManager(cfg.Server.ShutdownTimeout)
+	// Tracing stops last, so spans from the other workers' final runs are
+	// still exported
+	workers.Register("tracing", lifecycle.Hooks{OnStop: tracerProvider.Shutdown})
+	if emailQueue != nil {
+		workers.Register("email", emailQueue)
+	}
+	if webhookDispatcher != nil {
+		workers.Register("webhooks", webhookDispatcher)
+	}
+	workers.Register("notifications", lifecycle.Hooks{
+		OnStop: func(ctx context.Context) error {
+			notificationService.Wait()
+			return nil
+		},
+	})
+
+	jobScheduler := scheduler.NewScheduler(cfg.Jobs.Timeout)
+	jobScheduler.Every("reconcile-post-counts", cfg.Jobs.PostCountReconcileInterval, postCountService.Reconcile)
+	jobScheduler.Every("archive-stale-drafts", cfg.Jobs.DraftArchiveInterval, draftArchiveService.Archive)
+	jobScheduler.Every("publish-scheduled-posts", cfg.Jobs.ScheduledPublishInterval, scheduledPublishService.Publish)
+	jobScheduler.Every("purge-revoked-tokens", cfg.Jobs.RevokedTokenPurgeInterval, jwtService.PurgeRevokedAccessTokens)
+	jobScheduler.Every("purge-trashed-posts", cfg.Jobs.TrashPurgeInterval, trashPurgeService.Purge)
+	jobScheduler.Every("purge-audit-log", cfg.Jobs.AuditPurgeInterval, auditLogService.Purge)
+	// Stopping cancels running jobs, which finish the item in hand and return
+	workers.Register("scheduler", lifecycle.Hooks{
+		OnStart: func(ctx context.Context) error {
+			jobScheduler.Start(ctx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			jobScheduler.Stop()
+			return nil
+		},
+	})
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	postHandler := handlers.NewPostHandler(postService)
+	postHandler := handlers.NewPostHandler(postService, postStatsService)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	commentHandler := handlers.NewCommentHandler(commentService)
-	uploadHandler := handlers.NewUploadHandler(storageService, cfg)
+	uploadHandler := handlers.NewUploadHandler(storageService, fileUploadRepo, cfg)
 	docsHandler := handlers.NewDocsHandler()
-	healthHandler := handlers.NewHealthHandler(db)
-	metricsHandler := handlers.NewMetricsHandler()
+	// The readiness probe answers 503 until the readiness gate opens
+	readiness := health.NewReadiness()
+	healthHandler := handlers.NewHealthHandler(db, &cfg.Health, readiness)
+	metricsHandler := handlers.NewMetricsHandler(&cfg.Metrics)
+
+	graphqlExecutor, err := graphql.NewExecutor(postService, categoryService, commentService, userRepo, categoryRepo)
+	if err != nil {
+		appLogger.Fatal("Failed to build GraphQL schema", zap.Error(err))
+	}
+	graphqlHandler := handlers.NewGraphQLHandler(graphqlExecutor)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	emailHandler := handlers.NewEmailHandler(emailQueue)
+	maintenanceHandler := handlers.NewMaintenanceHandler(cacheRegistry, maintenanceService)
+	userHandler := handlers.NewUserHandler(userService)
+	statsHandler := handlers.NewStatsHandler(statsService)
+	auditLogHandler := handlers.NewAuditLogHandler(auditLogService)
+
+	// Serve metrics off the API port when they have their own listener
+	if !metricsHandler.OnAPIPort() {
+		metricsRouter := gin.New()
+		metricsRouter.GET("/metrics", metricsHandler.Metrics)
+		metricsServer := &http.Server{Addr: cfg.Metrics.ListenAddr, Handler: metricsRouter}
+		workers.Register("metrics", lifecycle.Hooks{
+			OnStart: func(ctx context.Context) error {
+				listener, err := net.Listen("tcp", metricsServer.Addr)
+				if err != nil {
+					return err
+				}
+				go metricsServer.Serve(listener)
+				return nil
+			},
+			OnStop: metricsServer.Shutdown,
+		})
+	}
 
 	appLogger.Info("All handlers initialized successfully")
 
@@ -98,32 +279,67 @@ func main() {
 	}
 
 	r := gin.New()
+	routesHandler := handlers.NewRoutesHandler(r, cfg.App.ExposeRoutes)
 
 	// Observability middleware (applied first for complete request tracking)
-	r.Use(middleware.CorrelationIDMiddleware()) // X-Request-ID correlation
-	r.Use(middleware.LoggingMiddleware())       // Structured logging
-	r.Use(middleware.MetricsMiddleware())       // Prometheus metrics
+	r.Use(middleware.CorrelationIDMiddleware())         // X-Request-ID correlation
+	r.Use(middleware.TracingMiddleware(tracerProvider)) // OpenTelemetry spans
+	r.Use(middleware.LoggingMiddleware())               // Structured logging
+	r.Use(middleware.MetricsMiddleware())               // Prometheus metrics
 
 	// Core middleware
 	r.Use(middleware.RequestIDMiddleware())
-	r.Use(middleware.SecurityHeadersMiddleware())
-	r.Use(middleware.CORSMiddleware())
+	if cfg.Server.Compression {
+		r.Use(middleware.CompressionMiddleware(cfg.Server.CompressionMinSize))
+	}
+	r.Use(middleware.SecurityHeadersMiddleware(&cfg.Security))
+	r.Use(middleware.CORSMiddleware(&cfg.Security))
 	r.Use(middleware.ValidationMiddleware())
 	r.Use(middleware.ErrorHandlerMiddleware())
+	r.Use(middleware.PaginationShape(cfg.App.PaginationShape))
+	r.Use(middleware.FieldSelection(cfg.App.StrictFields))
+	r.Use(middleware.JSONDecoding(cfg.App.StrictJSON, cfg.App.JSONMaxDepth))
+	r.Use(middleware.BodyLimitMiddleware(cfg.Server.MaxBodySize))
+	r.Use(middleware.TimeoutMiddleware(cfg.Server.RequestTimeout))
+
+	// Rate limiting middleware, counting requests in RATE_LIMIT_BACKEND
+	var rateLimitStore middleware.RateLimiterStore
+	if cfg.RateLimit.Backend == middleware.RateLimitBackendRedis {
+		redisOptions, err := redis.ParseURL(cfg.RateLimit.RedisURL)
+		if err != nil {
+			appLogger.Fatal("Invalid RATE_LIMIT_REDIS_URL", zap.Error(err))
+		}
+		redisClient := redis.NewClient(redisOptions)
+		defer redisClient.Close()
+		rateLimitStore = middleware.NewRedisRateLimiter(redisClient)
+	} else {
+		memoryStore := middleware.NewRateLimiter(cfg.RateLimit.IdleTTL)
+		// Ends the idle limiter sweeper on shutdown
+		workers.Register("rate-limiter", lifecycle.Hooks{
+			OnStop: func(ctx context.Context) error {
+				memoryStore.Stop()
+				return nil
+			},
+		})
+		rateLimitStore = memoryStore
+	}
+	r.Use(middleware.AdvancedRateLimitMiddleware(rateLimitStore, jwtService, &cfg.RateLimit))
 
-	// Rate limiting middleware
-	r.Use(middleware.AdvancedRateLimitMiddleware())
+	// Refuse writes during scheduled maintenance windows
+	r.Use(middleware.MaintenanceMiddleware(maintenanceService))
 
 	appLogger.Info("Middleware stack configured",
 		zap.Bool("cors_enabled", true),
+		zap.Bool("compression_enabled", cfg.Server.Compression),
 		zap.Bool("rate_limiting_enabled", true),
+		zap.String("rate_limit_backend", cfg.RateLimit.Backend),
 		zap.Bool("structured_logging_enabled", true),
 		zap.Bool("metrics_enabled", true),
 	)
 
 	// Setup routes with enhanced observability
 	routes.SetupRoutes(r, authHandler, postHandler, categoryHandler, commentHandler,
-		uploadHandler, docsHandler, healthHandler, metricsHandler, jwtService)
+		uploadHandler, docsHandler, healthHandler, metricsHandler, graphqlHandler, notificationHandler, emailHandler, maintenanceHandler, userHandler, routesHandler, statsHandler, auditLogHandler, postRepo, commentRepo, jwtService, rateLimitStore)
 
 	// Start server
 	appLogger.Info("BlogCMS Server starting",
@@ -134,5 +350,50 @@ func main() {
 		zap.String("metrics_url", fmt.Sprintf("http://localhost:%s/metrics", cfg.Server.Port)),
 	)
 
-	log.Fatal(r.Run(":" + cfg.Server.Port))
+	// Take traffic once the database and migrations check out and cached post
+	// counts have been backfilled. Registered last, the gate starts after
+	// every other worker and is the first to close on shutdown.
+	readinessChecker := health.NewHealthChecker()
+	readinessChecker.AddChecker("database", health.NewDatabaseChecker(db))
+	readinessChecker.AddChecker("migrations", health.NewFuncChecker("migrations", database.CheckMigrations(db)))
+	workers.Register("readiness", startup.NewReadinessGate(readiness, readinessChecker, time.Second, postCountService.Reconcile))
+
+	server := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: middleware.TrailingSlash(r, cfg.App.TrailingSlash),
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	if err := workers.Start(context.Background()); err != nil {
+		appLogger.Fatal("Failed to start background workers", zap.Error(err))
+	}
+
+	// Wait for a shutdown signal, or for the server to fail
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-quit:
+		appLogger.Info("Shutting down", zap.String("signal", sig.String()))
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			appLogger.Error("Server stopped unexpectedly", zap.Error(err))
+		}
+	}
+
+	// Stop taking requests first, so no new work reaches the workers. Failing
+	// the readiness probe before draining lets load balancers move away.
+	readiness.MarkNotReady()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to drain in-flight requests", zap.Error(err))
+	}
+
+	if err := workers.Stop(context.Background()); err != nil {
+		appLogger.Error("Background workers did not stop cleanly", zap.Error(err))
+	}
+	appLogger.Info("BlogCMS Server stopped")
 }
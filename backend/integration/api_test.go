@@ -50,18 +50,20 @@ func setupIntegrationTest(t *testing.T) *IntegrationTestSuite {
 	categoryRepo := repositories.NewCategoryRepository(testDB.DB)
 	commentRepo := repositories.NewCommentRepository(testDB.DB)
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(testDB.DB)
+	revokedTokenRepo := repositories.NewRevokedAccessTokenRepository(testDB.DB)
 
 	// Initialize services
-	jwtService := services.NewJWTService(refreshTokenRepo)
-	authService := services.NewAuthService(userRepo, jwtService, cfg)
-	postService := services.NewPostService(postRepo, userRepo, categoryRepo)
+	jwtService := services.NewJWTService(refreshTokenRepo, revokedTokenRepo, cfg)
+	authService := services.NewAuthService(userRepo, repositories.NewPasswordResetTokenRepository(testDB.DB), jwtService, cfg)
+	postService := services.NewPostService(repositories.Repositories{Posts: postRepo, Users: userRepo, Categories: categoryRepo, Tags: repositories.NewTagRepository(testDB.DB)}, repositories.NewUnitOfWork(testDB.DB), cfg, nil)
 	categoryService := services.NewCategoryService(categoryRepo)
-	commentService := services.NewCommentService(commentRepo, postRepo, userRepo)
-	storageService := services.NewStorageService()
+	commentService := services.NewCommentService(commentRepo, postRepo, userRepo, cfg, nil)
+	storageService, err := services.NewStorageService(cfg)
+	require.NoError(t, err)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	postHandler := handlers.NewPostHandler(postService)
+	postHandler := handlers.NewPostHandler(postService, services.NewPostStatsService(postRepo, repositories.NewPostStatsRepository(testDB.DB)))
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	commentHandler := handlers.NewCommentHandler(commentService)
 	uploadHandler := handlers.NewUploadHandler(storageService)
@@ -4,24 +4,46 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Server   ServerConfig
-	App      AppConfig
-	Storage  StorageConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Server    ServerConfig
+	App       AppConfig
+	Storage   StorageConfig
+	Comment   CommentConfig
+	Jobs      JobsConfig
+	Auth      AuthConfig
+	Notify    NotificationConfig
+	Webhook   WebhookConfig
+	Post      PostConfig
+	Health    HealthConfig
+	Metrics   MetricsConfig
+	Tracing   TracingConfig
+	Security  SecurityConfig
+	RateLimit RateLimitConfig
 }
 
 type DatabaseConfig struct {
+	// Driver is "mysql" (the default) or "sqlite". SQLite suits small
+	// single-node sites; it stores everything in SQLitePath and falls back to
+	// LIKE matching for post search.
+	Driver     string
+	SQLitePath string
+
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Name     string
+	// QueryTimeout caps how long the server lets a single SELECT run, even
+	// when the caller's context has no deadline. Zero disables the cap.
+	QueryTimeout time.Duration
 }
 
 type JWTConfig struct {
@@ -32,11 +54,47 @@ type JWTConfig struct {
 type ServerConfig struct {
 	Host string
 	Port string
+	// ShutdownTimeout bounds how long in-flight requests, and then background
+	// workers, get to finish once the server is asked to stop
+	ShutdownTimeout time.Duration
+	// Compression compresses responses for clients that accept it, once they
+	// reach CompressionMinSize bytes
+	Compression        bool
+	CompressionMinSize int
+	// MaxBodySize caps request bodies in bytes, except on the upload routes,
+	// which are bounded by StorageConfig.MaxFileSize instead
+	MaxBodySize int64
+	// RequestTimeout is how long a handler gets before the client is sent
+	// 503 and the request context is cancelled
+	RequestTimeout time.Duration
 }
 
 type AppConfig struct {
 	Environment string
 	Debug       bool
+	// FailOnUnhealthyStartup stops the server when a critical dependency
+	// fails the startup self-check instead of only logging it
+	FailOnUnhealthyStartup bool
+	// PaginationShape is the default list response shape: "meta" for
+	// {data, meta} or "legacy" for {data: {data, total, ...}}. Clients can
+	// override it per request with the X-API-Pagination header.
+	PaginationShape string
+	// StrictJSON rejects create and update bodies with fields the endpoint
+	// does not know, catching misspelt field names
+	StrictJSON bool
+	// StrictFields answers 400 when ?fields= names a field the endpoint
+	// does not expose, instead of leaving it out of the response
+	StrictFields bool
+	// JSONMaxDepth is the deepest nesting of objects and arrays accepted in
+	// create and update bodies. Zero or less disables the check.
+	JSONMaxDepth int
+	// TrailingSlash is how paths with a trailing slash are routed: "strip"
+	// serves them as the path without it, "redirect" redirects there and
+	// "strict" answers 404
+	TrailingSlash string
+	// ExposeRoutes serves the registered route list to admins at
+	// GET /api/v1/admin/routes
+	ExposeRoutes bool
 }
 
 type StorageConfig struct {
@@ -52,6 +110,258 @@ type StorageConfig struct {
 	S3SecretKey      string
 	S3BaseURL        string
 	S3ForcePathStyle bool
+	// Google Cloud Storage settings. GCSCredentialsFile is a service account
+	// key; when empty the application default credentials are used.
+	GCSBucket          string
+	GCSCredentialsFile string
+	GCSBaseURL         string
+	// CDNBaseURL, when set, replaces PublicBaseURL in image references
+	// served in post content, e.g. once uploads are fronted by a CDN
+	CDNBaseURL string
+	// ThumbnailWidth is the width in pixels of the thumbnails generated for
+	// uploaded JPEG, PNG and WebP images. Zero disables thumbnails.
+	ThumbnailWidth int
+}
+
+// PublicBaseURL is the address uploaded files are served from, without a
+// trailing slash
+func (s StorageConfig) PublicBaseURL() string {
+	switch {
+	case s.Driver == "gcs" && s.GCSBaseURL != "":
+		return s.GCSBaseURL
+	case s.Driver == "gcs":
+		return "https://storage.googleapis.com/" + s.GCSBucket
+	case s.Driver != "s3":
+		return s.BaseURL + "/uploads"
+	case s.S3BaseURL != "":
+		return s.S3BaseURL
+	case s.S3Endpoint != "":
+		return s.S3Endpoint + "/" + s.S3Bucket
+	default:
+		return "https://" + s.S3Bucket + ".s3." + s.S3Region + ".amazonaws.com"
+	}
+}
+
+type CommentConfig struct {
+	// MaxDepth is the deepest reply level allowed (top-level comments are depth 0).
+	// A value of 0 or less disables the limit.
+	MaxDepth int
+	// DepthPolicy decides what happens to a reply beyond MaxDepth:
+	// "reject" refuses it, "clamp" attaches it at the deepest allowed level.
+	DepthPolicy string
+	// CloseAfterDays closes comments on posts published more than this many
+	// days ago. Admins can still comment. Zero keeps comments open forever.
+	CloseAfterDays int
+	// MaxLength caps a comment's length in characters, and MaxLengthByRole
+	// raises or lowers it for particular roles. A value of 0 or less
+	// disables the limit.
+	MaxLength       int
+	MaxLengthByRole map[string]int
+	// MaxLinks is the most URLs a single comment may contain. A value of 0
+	// or less disables the limit.
+	MaxLinks int
+	// TrustedDomains lists email domains whose users' comments are approved
+	// straight away; everyone else's wait for moderation. Empty, the
+	// default, holds every comment for moderation.
+	TrustedDomains []string
+	// BlockedWords and BlockedLinks mark a new comment as spam, rejecting it
+	// even for trusted domains. Words match whole words, ignoring case;
+	// link patterns match a whole URL in the comment, where * stands for any
+	// run of characters. HeldWords instead hold a comment for moderation.
+	BlockedWords []string
+	BlockedLinks []string
+	HeldWords    []string
+	// SpamMaxLinks rejects new comments with more links than this as spam.
+	// Unlike MaxLinks the comment is stored, so its writer is not told why.
+	// A value of 0 or less disables the check.
+	SpamMaxLinks int
+}
+
+type JobsConfig struct {
+	// PostCountReconcileInterval is how often cached category post counts are
+	// recomputed from the posts table. Zero disables the job.
+	PostCountReconcileInterval time.Duration
+	// DraftArchiveInterval is how often drafts nobody has touched for
+	// DraftArchiveAfterDays are archived. Zero, the default, disables the job.
+	DraftArchiveInterval  time.Duration
+	DraftArchiveAfterDays int
+	// ScheduledPublishInterval is how often drafts whose PublishAt has passed
+	// are published. Zero disables the job.
+	ScheduledPublishInterval time.Duration
+	// RevokedTokenPurgeInterval is how often revoked access tokens that have
+	// since expired are removed from the denylist. Zero disables the job.
+	RevokedTokenPurgeInterval time.Duration
+	// TrashPurgeInterval is how often posts deleted more than
+	// TrashRetentionDays ago are removed for good. Zero disables the job.
+	TrashPurgeInterval time.Duration
+	TrashRetentionDays int
+	// AuditPurgeInterval is how often audit log entries older than
+	// AuditRetentionDays are deleted. Zero disables the job.
+	AuditPurgeInterval time.Duration
+	AuditRetentionDays int
+	// Timeout bounds each run of a background job. Zero leaves runs unbounded.
+	Timeout time.Duration
+}
+
+type AuthConfig struct {
+	// DefaultRole is granted to self-registered users. It can never be admin.
+	DefaultRole string
+	// Bootstrap admin account created at startup when BootstrapAdminEmail is set
+	BootstrapAdminEmail    string
+	BootstrapAdminUsername string
+	BootstrapAdminPassword string
+	// PasswordPepper is a server-side secret mixed into every password before
+	// it is hashed. Empty disables peppering.
+	PasswordPepper string
+	// PreviousPasswordPepper is the pepper in use before the current one, or
+	// empty if passwords were not peppered. Hashes made with it still verify
+	// and are replaced with current ones as users log in.
+	PreviousPasswordPepper string
+	// RegistrationsPerIP caps how many accounts may be registered from one
+	// client IP within RegistrationWindow. Zero disables the cap.
+	RegistrationsPerIP int
+	RegistrationWindow time.Duration
+	// RequireEmailVerification stops users who have not verified their email
+	// address from creating posts
+	RequireEmailVerification bool
+	// LoginMaxFailures consecutive wrong passwords within LoginLockout lock
+	// the account for LoginLockout. Zero disables lockout.
+	LoginMaxFailures int
+	LoginLockout     time.Duration
+}
+
+type NotificationConfig struct {
+	// SiteURL is the public frontend address used for links in notifications
+	SiteURL string
+	// SMTP settings; email notifications are disabled when SMTPHost is empty
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// EmailQueueSize bounds how many emails may wait to be sent; further
+	// emails are dropped and recorded as failures
+	EmailQueueSize int
+	// EmailMaxAttempts is how many times an email is tried before it is
+	// given up on
+	EmailMaxAttempts int
+	// EmailRetryBackoff is the wait before the first retry; it doubles
+	// with every further attempt
+	EmailRetryBackoff time.Duration
+}
+
+type WebhookConfig struct {
+	// URLs receive a JSON POST for every post and comment event; webhooks
+	// are disabled when empty
+	URLs []string
+	// Workers deliver queued events concurrently
+	Workers int
+	// QueueSize bounds how many deliveries may wait for a worker
+	QueueSize int
+	// QueuePolicy decides what happens to a delivery when the queue is full:
+	// "drop" discards it, "block" makes the publisher wait up to
+	// QueueBlockTimeout for room before discarding it
+	QueuePolicy       string
+	QueueBlockTimeout time.Duration
+	// Timeout bounds a single delivery request
+	Timeout time.Duration
+	// FailureThreshold consecutive failed deliveries suspend an endpoint for
+	// SuspendFor; the next delivery after that is tried again. Zero never
+	// suspends.
+	FailureThreshold int
+	SuspendFor       time.Duration
+}
+
+type PostConfig struct {
+	// LimitByRole caps how many non-deleted posts a user with the role may
+	// own. Roles without a positive limit are unlimited, and admins and
+	// editors are always exempt.
+	LimitByRole map[string]int
+	// MinInterval is the least time an author must wait after creating a
+	// post before creating another. Zero disables the check, and admins and
+	// editors are always exempt.
+	MinInterval time.Duration
+	// DuplicateTitles decides what happens when a post is saved with the
+	// same title as another post, ignoring case: "off" allows it, "warn"
+	// saves it and returns a warning, and "strict" rejects it
+	DuplicateTitles string
+	// ImageHosts are the hosts a post's thumbnail URL may point at. When
+	// empty, only the storage and CDN hosts are allowed. Relative paths to
+	// uploaded files are always allowed.
+	ImageHosts []string
+	// DeletedRelations decides how a post shows an author or category that
+	// was deleted: "unscoped" shows them as stored, and "placeholder"
+	// replaces them with a placeholder named "(deleted)"
+	DeletedRelations string
+}
+
+type HealthConfig struct {
+	// PublicDetails shows system information and per-check details on the
+	// health endpoints to everyone. Otherwise anonymous callers only see the
+	// overall status, and details need an admin token or a request from one
+	// of InternalNetworks.
+	PublicDetails bool
+	// InternalNetworks lists the CIDR ranges whose requests see details.
+	// They are matched against the connecting address, not X-Forwarded-For.
+	InternalNetworks []string
+}
+
+type MetricsConfig struct {
+	// Guard protects /metrics: "open" serves it to anyone, "token" requires
+	// Token, "network" only answers requests from AllowedNetworks, and
+	// "listener" serves it on ListenAddr instead of the API port
+	Guard string
+	// Token is accepted as a bearer token or as the basic auth password
+	Token string
+	// AllowedNetworks are matched against the connecting address, not
+	// X-Forwarded-For
+	AllowedNetworks []string
+	ListenAddr      string
+}
+
+type TracingConfig struct {
+	// Enabled exports spans over OTLP/HTTP. It is set when
+	// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is,
+	// and the exporter reads those itself. Otherwise tracing is a no-op.
+	Enabled     bool
+	ServiceName string
+	// SampleRatio is the share of new traces recorded. Requests continuing
+	// a trace follow the caller's sampling decision.
+	SampleRatio float64
+}
+
+type RateLimitConfig struct {
+	// Backend keeps the request counts: "memory" in this process, or
+	// "redis" at RedisURL so the limits hold across restarts and replicas.
+	// Requests are allowed while Redis is unreachable.
+	Backend  string
+	RedisURL string
+	// IdleTTL is how long the memory backend keeps the counts of a client
+	// that stopped sending requests. Zero keeps them forever.
+	IdleTTL time.Duration
+	// UserReadRequestsPerMinute and UserWriteRequestsPerMinute limit each
+	// authenticated user on their own, wherever they connect from, instead
+	// of counting them against their IP address. Zero leaves that kind of
+	// request limited by IP only.
+	UserReadRequestsPerMinute  int
+	UserWriteRequestsPerMinute int
+}
+
+type SecurityConfig struct {
+	// AllowedOrigins are accepted by CORS on top of the local development
+	// origins. "*" accepts any origin.
+	AllowedOrigins []string
+	// CORSAllowCredentials lets browsers send cookies and Authorization
+	// headers on cross-origin requests
+	CORSAllowCredentials bool
+	CORSMaxAge           time.Duration
+	// HSTS sends Strict-Transport-Security. Browsers ignore it over plain
+	// HTTP, so it only makes sense when PublicURL is https.
+	HSTS bool
+	// ContentSecurityPolicy is sent on every response. Empty sends none.
+	ContentSecurityPolicy string
+	// PublicURL is the address clients reach the API at
+	PublicURL string
 }
 
 func LoadConfig() *Config {
@@ -60,29 +370,101 @@ func LoadConfig() *Config {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	tracingSampleRatio, _ := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
 	maxFileSize, _ := strconv.ParseInt(getEnv("STORAGE_MAX_FILE_SIZE", "5242880"), 10, 64) // 5MB default
+	thumbnailWidth, _ := strconv.Atoi(getEnv("STORAGE_THUMBNAIL_WIDTH", "400"))
 	expireHours, _ := strconv.Atoi(getEnv("JWT_EXPIRE_HOURS", "24"))
 	debug := getEnv("APP_DEBUG", "false") == "true"
+	commentMaxDepth, _ := strconv.Atoi(getEnv("COMMENT_MAX_DEPTH", "1"))
+	commentCloseAfterDays, _ := strconv.Atoi(getEnv("COMMENTS_CLOSE_AFTER_DAYS", "0"))
+	commentMaxLength, _ := strconv.Atoi(getEnv("COMMENT_MAX_LENGTH", "1000"))
+	commentMaxLengthEditor, _ := strconv.Atoi(getEnv("COMMENT_MAX_LENGTH_EDITOR", "5000"))
+	commentMaxLengthAdmin, _ := strconv.Atoi(getEnv("COMMENT_MAX_LENGTH_ADMIN", "5000"))
+	commentMaxLinks, _ := strconv.Atoi(getEnv("COMMENT_MAX_LINKS", "3"))
+	commentSpamMaxLinks, _ := strconv.Atoi(getEnv("COMMENT_SPAM_MAX_LINKS", "0"))
+	postCountReconcileInterval, _ := time.ParseDuration(getEnv("POST_COUNT_RECONCILE_INTERVAL", "1h"))
+	jobTimeout, _ := time.ParseDuration(getEnv("JOB_TIMEOUT", "5m"))
+	draftArchiveInterval, _ := time.ParseDuration(getEnv("DRAFT_ARCHIVE_INTERVAL", "0"))
+	draftArchiveAfterDays, _ := strconv.Atoi(getEnv("DRAFT_ARCHIVE_AFTER_DAYS", "90"))
+	jsonMaxDepth, _ := strconv.Atoi(getEnv("API_JSON_MAX_DEPTH", "32"))
+	scheduledPublishInterval, _ := time.ParseDuration(getEnv("SCHEDULED_PUBLISH_INTERVAL", "1m"))
+	revokedTokenPurgeInterval, _ := time.ParseDuration(getEnv("REVOKED_TOKEN_PURGE_INTERVAL", "1h"))
+	trashPurgeInterval, _ := time.ParseDuration(getEnv("TRASH_PURGE_INTERVAL", "24h"))
+	trashRetentionDays, _ := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", "30"))
+	auditPurgeInterval, _ := time.ParseDuration(getEnv("AUDIT_PURGE_INTERVAL", "24h"))
+	auditRetentionDays, _ := strconv.Atoi(getEnv("AUDIT_RETENTION_DAYS", "365"))
+	queryTimeout, _ := time.ParseDuration(getEnv("DB_QUERY_TIMEOUT", "30s"))
+	shutdownTimeout, _ := time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "30s"))
+	compressionMinSize, _ := strconv.Atoi(getEnv("COMPRESSION_MIN_SIZE", "1024"))
+	maxBodySize, _ := strconv.ParseInt(getEnv("MAX_BODY_SIZE", "1048576"), 10, 64) // 1MB default
+	requestTimeout, _ := time.ParseDuration(getEnv("REQUEST_TIMEOUT", "30s"))
+	corsMaxAge, _ := time.ParseDuration(getEnv("CORS_MAX_AGE", "12h"))
+	emailQueueSize, _ := strconv.Atoi(getEnv("EMAIL_QUEUE_SIZE", "100"))
+	emailMaxAttempts, _ := strconv.Atoi(getEnv("EMAIL_MAX_ATTEMPTS", "3"))
+	emailRetryBackoff, _ := time.ParseDuration(getEnv("EMAIL_RETRY_BACKOFF", "2s"))
+	webhookWorkers, _ := strconv.Atoi(getEnv("WEBHOOK_WORKERS", "4"))
+	webhookQueueSize, _ := strconv.Atoi(getEnv("WEBHOOK_QUEUE_SIZE", "1000"))
+	webhookBlockTimeout, _ := time.ParseDuration(getEnv("WEBHOOK_QUEUE_BLOCK_TIMEOUT", "5s"))
+	webhookTimeout, _ := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	webhookFailureThreshold, _ := strconv.Atoi(getEnv("WEBHOOK_FAILURE_THRESHOLD", "5"))
+	webhookSuspendFor, _ := time.ParseDuration(getEnv("WEBHOOK_SUSPEND_FOR", "1m"))
+	// Unlike most settings, an explicitly empty policy is kept
+	contentSecurityPolicy, ok := os.LookupEnv("SECURITY_CSP")
+	if !ok {
+		contentSecurityPolicy = "default-src 'self'"
+	}
+	postLimitAuthor, _ := strconv.Atoi(getEnv("POST_LIMIT_AUTHOR", "0"))
+	postMinInterval, _ := time.ParseDuration(getEnv("POST_MIN_INTERVAL", "0s"))
+	registrationsPerIP, _ := strconv.Atoi(getEnv("REGISTRATIONS_PER_IP", "10"))
+	registrationWindow, _ := time.ParseDuration(getEnv("REGISTRATION_WINDOW", "24h"))
+	loginMaxFailures, _ := strconv.Atoi(getEnv("LOGIN_MAX_FAILURES", "5"))
+	loginLockout, _ := time.ParseDuration(getEnv("LOGIN_LOCKOUT", "15m"))
+	rateLimitIdleTTL, _ := time.ParseDuration(getEnv("RATE_LIMIT_IDLE_TTL", "10m"))
+	userReadRequestsPerMinute, _ := strconv.Atoi(getEnv("RATE_LIMIT_USER_READ_PER_MINUTE", "120"))
+	userWriteRequestsPerMinute, _ := strconv.Atoi(getEnv("RATE_LIMIT_USER_WRITE_PER_MINUTE", "60"))
+	appEnv := getEnv("APP_ENV", "development")
+	// Metrics are open during development and need a token in production
+	metricsGuard := "open"
+	if appEnv == "production" {
+		metricsGuard = "token"
+	}
+	// The route list is a debugging aid, so production leaves it out
+	exposeRoutes := strconv.FormatBool(appEnv != "production")
 
 	return &Config{
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "3306"),
-			User:     getEnv("DB_USER", "root"),
-			Password: getEnv("DB_PASS", ""),
-			Name:     getEnv("DB_NAME", "blog_cms"),
+			Driver:       getEnv("DB_DRIVER", "mysql"),
+			SQLitePath:   getEnv("DB_SQLITE_PATH", "./data/blogcms.db"),
+			Host:         getEnv("DB_HOST", "localhost"),
+			Port:         getEnv("DB_PORT", "3306"),
+			User:         getEnv("DB_USER", "root"),
+			Password:     getEnv("DB_PASS", ""),
+			Name:         getEnv("DB_NAME", "blog_cms"),
+			QueryTimeout: queryTimeout,
 		},
 		JWT: JWTConfig{
 			Secret:      getEnv("JWT_SECRET", "default-secret-key"),
 			ExpireHours: expireHours,
 		},
 		Server: ServerConfig{
-			Host: getEnv("SERVER_HOST", "localhost"),
-			Port: getEnv("SERVER_PORT", "8080"),
+			Host:               getEnv("SERVER_HOST", "localhost"),
+			Port:               getEnv("SERVER_PORT", "8080"),
+			ShutdownTimeout:    shutdownTimeout,
+			Compression:        getEnv("COMPRESSION_ENABLED", "true") == "true",
+			CompressionMinSize: compressionMinSize,
+			MaxBodySize:        maxBodySize,
+			RequestTimeout:     requestTimeout,
 		},
 		App: AppConfig{
-			Environment: getEnv("APP_ENV", "development"),
-			Debug:       debug,
+			Environment:            appEnv,
+			Debug:                  debug,
+			FailOnUnhealthyStartup: getEnv("STARTUP_FAIL_ON_UNHEALTHY", "true") == "true",
+			PaginationShape:        getEnv("API_PAGINATION_SHAPE", "meta"),
+			StrictJSON:             getEnv("API_STRICT_JSON", "false") == "true",
+			StrictFields:           getEnv("API_STRICT_FIELDS", "false") == "true",
+			JSONMaxDepth:           jsonMaxDepth,
+			TrailingSlash:          getEnv("API_TRAILING_SLASH", "strip"),
+			ExposeRoutes:           getEnv("API_EXPOSE_ROUTES", exposeRoutes) == "true",
 		},
 		Storage: StorageConfig{
 			Driver:           getEnv("STORAGE_DRIVER", "local"),
@@ -96,8 +478,126 @@ func LoadConfig() *Config {
 			S3SecretKey:      getEnv("AWS_SECRET_ACCESS_KEY", ""),
 			S3BaseURL:        getEnv("S3_BASE_URL", ""),
 			S3ForcePathStyle: getEnv("S3_FORCE_PATH_STYLE", "true") == "true",
+			CDNBaseURL:       strings.TrimRight(getEnv("STORAGE_CDN_BASE_URL", ""), "/"),
+			ThumbnailWidth:   thumbnailWidth,
+
+			GCSBucket:          getEnv("GCS_BUCKET_NAME", ""),
+			GCSCredentialsFile: getEnv("GCS_CREDENTIALS_FILE", ""),
+			GCSBaseURL:         strings.TrimRight(getEnv("GCS_BASE_URL", ""), "/"),
+		},
+		Comment: CommentConfig{
+			MaxDepth:       commentMaxDepth,
+			DepthPolicy:    getEnv("COMMENT_DEPTH_POLICY", "reject"),
+			CloseAfterDays: commentCloseAfterDays,
+			MaxLength:      commentMaxLength,
+			MaxLengthByRole: map[string]int{
+				"editor": commentMaxLengthEditor,
+				"admin":  commentMaxLengthAdmin,
+			},
+			MaxLinks:       commentMaxLinks,
+			TrustedDomains: splitList(strings.ToLower(getEnv("COMMENT_TRUSTED_DOMAINS", ""))),
+			BlockedWords:   splitList(getEnv("COMMENT_BLOCKED_WORDS", "")),
+			BlockedLinks:   splitList(getEnv("COMMENT_BLOCKED_LINKS", "")),
+			HeldWords:      splitList(getEnv("COMMENT_HELD_WORDS", "")),
+			SpamMaxLinks:   commentSpamMaxLinks,
+		},
+		Jobs: JobsConfig{
+			PostCountReconcileInterval: postCountReconcileInterval,
+			DraftArchiveInterval:       draftArchiveInterval,
+			DraftArchiveAfterDays:      draftArchiveAfterDays,
+			ScheduledPublishInterval:   scheduledPublishInterval,
+			RevokedTokenPurgeInterval:  revokedTokenPurgeInterval,
+			TrashPurgeInterval:         trashPurgeInterval,
+			TrashRetentionDays:         trashRetentionDays,
+			AuditPurgeInterval:         auditPurgeInterval,
+			AuditRetentionDays:         auditRetentionDays,
+			Timeout:                    jobTimeout,
+		},
+		Auth: AuthConfig{
+			DefaultRole:              getEnv("NEW_USER_DEFAULT_ROLE", "author"),
+			BootstrapAdminEmail:      getEnv("BOOTSTRAP_ADMIN_EMAIL", ""),
+			BootstrapAdminUsername:   getEnv("BOOTSTRAP_ADMIN_USERNAME", "admin"),
+			BootstrapAdminPassword:   getEnv("BOOTSTRAP_ADMIN_PASSWORD", ""),
+			PasswordPepper:           getEnv("PASSWORD_PEPPER", ""),
+			PreviousPasswordPepper:   getEnv("PASSWORD_PEPPER_PREVIOUS", ""),
+			RegistrationsPerIP:       registrationsPerIP,
+			RegistrationWindow:       registrationWindow,
+			RequireEmailVerification: getEnv("REQUIRE_EMAIL_VERIFICATION", "false") == "true",
+			LoginMaxFailures:         loginMaxFailures,
+			LoginLockout:             loginLockout,
+		},
+		Notify: NotificationConfig{
+			SiteURL:           getEnv("SITE_URL", "http://localhost:3000"),
+			SMTPHost:          getEnv("SMTP_HOST", ""),
+			SMTPPort:          getEnv("SMTP_PORT", "587"),
+			SMTPUsername:      getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:      getEnv("SMTP_PASSWORD", ""),
+			SMTPFrom:          getEnv("SMTP_FROM", "no-reply@blogcms.local"),
+			EmailQueueSize:    emailQueueSize,
+			EmailMaxAttempts:  emailMaxAttempts,
+			EmailRetryBackoff: emailRetryBackoff,
 		},
+		Webhook: WebhookConfig{
+			URLs:              splitList(getEnv("WEBHOOK_URLS", "")),
+			Workers:           webhookWorkers,
+			QueueSize:         webhookQueueSize,
+			QueuePolicy:       getEnv("WEBHOOK_QUEUE_POLICY", "drop"),
+			QueueBlockTimeout: webhookBlockTimeout,
+			Timeout:           webhookTimeout,
+			FailureThreshold:  webhookFailureThreshold,
+			SuspendFor:        webhookSuspendFor,
+		},
+		Health: HealthConfig{
+			PublicDetails:    getEnv("HEALTH_PUBLIC_DETAILS", "false") == "true",
+			InternalNetworks: splitList(getEnv("HEALTH_INTERNAL_NETWORKS", "127.0.0.1/32,::1/128")),
+		},
+		Metrics: MetricsConfig{
+			Guard:           getEnv("METRICS_GUARD", metricsGuard),
+			Token:           getEnv("METRICS_TOKEN", ""),
+			AllowedNetworks: splitList(getEnv("METRICS_ALLOWED_NETWORKS", "127.0.0.1/32,::1/128")),
+			ListenAddr:      getEnv("METRICS_LISTEN_ADDR", "127.0.0.1:9090"),
+		},
+		Tracing: TracingConfig{
+			Enabled:     getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "") != "" || getEnv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "") != "",
+			ServiceName: getEnv("OTEL_SERVICE_NAME", "blogcms-api"),
+			SampleRatio: tracingSampleRatio,
+		},
+		Security: SecurityConfig{
+			AllowedOrigins:        splitList(getEnv("ALLOWED_ORIGINS", "")),
+			CORSAllowCredentials:  getEnv("CORS_ALLOW_CREDENTIALS", "true") == "true",
+			CORSMaxAge:            corsMaxAge,
+			HSTS:                  getEnv("SECURITY_HSTS", "true") == "true",
+			ContentSecurityPolicy: contentSecurityPolicy,
+			PublicURL:             getEnv("BASE_URL", "http://localhost:8080"),
+		},
+		RateLimit: RateLimitConfig{
+			Backend:                    getEnv("RATE_LIMIT_BACKEND", "memory"),
+			RedisURL:                   getEnv("RATE_LIMIT_REDIS_URL", "redis://localhost:6379/0"),
+			IdleTTL:                    rateLimitIdleTTL,
+			UserReadRequestsPerMinute:  userReadRequestsPerMinute,
+			UserWriteRequestsPerMinute: userWriteRequestsPerMinute,
+		},
+		Post: PostConfig{
+			LimitByRole: map[string]int{
+				"author": postLimitAuthor,
+			},
+			MinInterval:      postMinInterval,
+			DuplicateTitles:  getEnv("POST_DUPLICATE_TITLES", "off"),
+			ImageHosts:       splitList(getEnv("POST_IMAGE_HOSTS", "")),
+			DeletedRelations: getEnv("POST_DELETED_RELATIONS", "unscoped"),
+		},
+	}
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
 
 func getEnv(key, defaultValue string) string {
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SecurityIssue is an unsafe or ineffective security setting
+type SecurityIssue struct {
+	Setting string
+	Problem string
+}
+
+func (i SecurityIssue) String() string {
+	return i.Setting + ": " + i.Problem
+}
+
+// ValidateSecurity reports combinations of security settings that are unsafe
+// or silently do nothing. Production refuses to start with any of them; other
+// environments only warn.
+func (c *Config) ValidateSecurity() []SecurityIssue {
+	var issues []SecurityIssue
+	security := c.Security
+
+	if security.CORSAllowCredentials && security.AllowsAnyOrigin() {
+		issues = append(issues, SecurityIssue{
+			Setting: "ALLOWED_ORIGINS",
+			Problem: "\"*\" with CORS_ALLOW_CREDENTIALS=true lets any site make authenticated requests; list the allowed origins or disable credentials",
+		})
+	}
+
+	if security.HSTS {
+		if publicURL, err := url.Parse(security.PublicURL); err != nil || publicURL.Scheme != "https" {
+			issues = append(issues, SecurityIssue{
+				Setting: "SECURITY_HSTS",
+				Problem: "Strict-Transport-Security is ignored by browsers over plain HTTP; serve BASE_URL over https or disable HSTS",
+			})
+		}
+	}
+
+	if strings.TrimSpace(security.ContentSecurityPolicy) == "" {
+		issues = append(issues, SecurityIssue{
+			Setting: "SECURITY_CSP",
+			Problem: "no Content-Security-Policy is sent",
+		})
+	}
+
+	return issues
+}
+
+// AllowsAnyOrigin reports whether CORS accepts requests from every origin
+func (s SecurityConfig) AllowsAnyOrigin() bool {
+	for _, origin := range s.AllowedOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
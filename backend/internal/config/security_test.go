@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func safeSecurityConfig() *Config {
+	return &Config{Security: SecurityConfig{
+		AllowedOrigins:        []string{"https://blog.example.com"},
+		CORSAllowCredentials:  true,
+		HSTS:                  true,
+		ContentSecurityPolicy: "default-src 'self'",
+		PublicURL:             "https://api.example.com",
+	}}
+}
+
+func issueSettings(issues []SecurityIssue) []string {
+	settings := make([]string, len(issues))
+	for i, issue := range issues {
+		settings[i] = issue.Setting
+	}
+	return settings
+}
+
+func TestValidateSecurity(t *testing.T) {
+	t.Run("a safe configuration has no issues", func(t *testing.T) {
+		assert.Empty(t, safeSecurityConfig().ValidateSecurity())
+	})
+
+	t.Run("credentials with a wildcard origin are flagged", func(t *testing.T) {
+		cfg := safeSecurityConfig()
+		cfg.Security.AllowedOrigins = append(cfg.Security.AllowedOrigins, "*")
+
+		assert.Equal(t, []string{"ALLOWED_ORIGINS"}, issueSettings(cfg.ValidateSecurity()))
+
+		cfg.Security.CORSAllowCredentials = false
+		assert.Empty(t, cfg.ValidateSecurity())
+	})
+
+	t.Run("HSTS over plain HTTP is flagged", func(t *testing.T) {
+		cfg := safeSecurityConfig()
+		cfg.Security.PublicURL = "http://api.example.com"
+
+		assert.Equal(t, []string{"SECURITY_HSTS"}, issueSettings(cfg.ValidateSecurity()))
+
+		cfg.Security.HSTS = false
+		assert.Empty(t, cfg.ValidateSecurity())
+	})
+
+	t.Run("an empty content security policy is flagged", func(t *testing.T) {
+		cfg := safeSecurityConfig()
+		cfg.Security.ContentSecurityPolicy = "  "
+
+		assert.Equal(t, []string{"SECURITY_CSP"}, issueSettings(cfg.ValidateSecurity()))
+	})
+
+	t.Run("every problem is reported at once", func(t *testing.T) {
+		cfg := safeSecurityConfig()
+		cfg.Security.AllowedOrigins = []string{"*"}
+		cfg.Security.PublicURL = "http://localhost:8080"
+		cfg.Security.ContentSecurityPolicy = ""
+
+		assert.Len(t, cfg.ValidateSecurity(), 3)
+	})
+}
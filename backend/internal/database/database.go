@@ -1,8 +1,13 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"backend/internal/config"
 	"backend/internal/models"
@@ -11,10 +16,45 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 )
 
 var DB *gorm.DB
 
+// Supported values for DatabaseConfig.Driver
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// sqliteBusyTimeout is how long a SQLite connection waits for another
+// connection's write lock before failing with "database is locked"
+const sqliteBusyTimeout = 5 * time.Second
+
+// Open connects to the database selected by cfg.Driver: MySQL by default, or
+// a SQLite file for small single-node deployments
+func Open(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case "", DriverMySQL:
+		return Connect(WithQueryTimeout(MySQLDSN(cfg), cfg.QueryTimeout))
+	case DriverSQLite:
+		return ConnectSQLiteFile(cfg.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// MySQLDSN builds the MySQL connection string for cfg
+func MySQLDSN(cfg *config.DatabaseConfig) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		cfg.User,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.Name,
+	)
+}
+
 // Connect initializes database connection with provided DSN
 func Connect(dsn string) (*gorm.DB, error) {
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
@@ -29,6 +69,22 @@ func Connect(dsn string) (*gorm.DB, error) {
 	return db, nil
 }
 
+// WithQueryTimeout adds a MySQL max_execution_time session variable to dsn so
+// the server aborts any SELECT running longer than timeout. This is a safety
+// net for queries issued without a context deadline, such as from background
+// jobs. A non-positive timeout returns dsn unchanged.
+func WithQueryTimeout(dsn string, timeout time.Duration) string {
+	if timeout <= 0 {
+		return dsn
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%smax_execution_time=%d", dsn, separator, timeout.Milliseconds())
+}
+
 // ConnectSQLite initializes SQLite database connection for testing
 func ConnectSQLite(dsn string) (*gorm.DB, error) {
 	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
@@ -42,18 +98,62 @@ func ConnectSQLite(dsn string) (*gorm.DB, error) {
 	return db, nil
 }
 
-// AutoMigrate runs database migrations for the provided database instance
-func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running database migrations...")
+// ConnectSQLiteFile opens (creating if needed) a SQLite database file for
+// production use. WAL mode lets readers proceed while a write is in progress,
+// and the busy timeout makes concurrent writers wait for the lock instead of
+// failing immediately.
+func ConnectSQLiteFile(path string) (*gorm.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("SQLite database path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create SQLite database directory: %w", err)
+	}
 
-	err := db.AutoMigrate(
+	dsn := fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=%d&_foreign_keys=on",
+		path, sqliteBusyTimeout.Milliseconds())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SQLite database: %w", err)
+	}
+
+	log.Println("SQLite database opened at", path)
+	return db, nil
+}
+
+// migratedModels lists every model managed by AutoMigrate
+func migratedModels() []interface{} {
+	return []interface{}{
 		&models.User{},
 		&models.Category{},
+		&models.Tag{},
 		&models.Post{},
 		&models.Comment{},
 		&models.RefreshToken{},
+		&models.PasswordResetToken{},
+		&models.RevokedAccessToken{},
 		&models.FileUpload{},
-	)
+		&models.Notification{},
+		&models.PostViewDay{},
+		&models.RecentView{},
+		&models.MaintenanceWindow{},
+		&models.AuditLog{},
+	}
+}
+
+// AutoMigrate runs database migrations for the provided database instance
+func AutoMigrate(db *gorm.DB) error {
+	log.Println("Running database migrations...")
+
+	if db.Dialector.Name() == DriverSQLite {
+		if err := useSQLiteColumnTypes(db); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
+	err := db.AutoMigrate(migratedModels()...)
 
 	if err != nil {
 		return fmt.Errorf("migration failed: %w", err)
@@ -63,17 +163,45 @@ func AutoMigrate(db *gorm.DB) error {
 	return nil
 }
 
+// useSQLiteColumnTypes swaps MySQL-only column types in the models' parsed
+// schemas for ones SQLite accepts, so the same models migrate on both. ENUM
+// columns become TEXT; the service layer already validates their values. The
+// schemas are cached per connection, so a MySQL connection in the same
+// process is unaffected.
+func useSQLiteColumnTypes(db *gorm.DB) error {
+	for _, model := range migratedModels() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		for _, field := range stmt.Schema.Fields {
+			if strings.HasPrefix(strings.ToLower(string(field.DataType)), "enum(") {
+				field.DataType = schema.String
+			}
+		}
+	}
+	return nil
+}
+
+// CheckMigrations returns a check that fails if any migrated model's table
+// is missing
+func CheckMigrations(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		migrator := db.WithContext(ctx).Migrator()
+		for _, model := range migratedModels() {
+			if !migrator.HasTable(model) {
+				return fmt.Errorf("table for %T is missing", model)
+			}
+		}
+		return nil
+	}
+}
+
 func InitDatabase(cfg *config.Config) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.Name,
-	)
+	dsn := MySQLDSN(&cfg.Database)
 
 	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+	DB, err = gorm.Open(mysql.Open(WithQueryTimeout(dsn, cfg.Database.QueryTimeout)), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 
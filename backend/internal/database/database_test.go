@@ -0,0 +1,16 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithQueryTimeout(t *testing.T) {
+	base := "user:pass@tcp(localhost:3306)/blog"
+
+	assert.Equal(t, base+"?max_execution_time=30000", WithQueryTimeout(base, 30*time.Second))
+	assert.Equal(t, base+"?parseTime=True&max_execution_time=1500", WithQueryTimeout(base+"?parseTime=True", 1500*time.Millisecond))
+	assert.Equal(t, base, WithQueryTimeout(base, 0))
+}
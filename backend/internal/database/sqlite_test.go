@@ -0,0 +1,1087 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// openSQLiteFile opens and migrates a fresh SQLite file in a temp directory
+func openSQLiteFile(t *testing.T) (*gorm.DB, string) {
+	path := filepath.Join(t.TempDir(), "data", "blog.db")
+
+	db, err := Open(&config.DatabaseConfig{Driver: DriverSQLite, SQLitePath: path})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	require.NoError(t, AutoMigrate(db))
+	return db, path
+}
+
+func TestConnectSQLiteFile(t *testing.T) {
+	db, path := openSQLiteFile(t)
+
+	_, err := os.Stat(path)
+	require.NoError(t, err, "database file should be created along with its directory")
+
+	var journalMode string
+	require.NoError(t, db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error)
+	assert.Equal(t, "wal", journalMode)
+
+	var busyTimeout int
+	require.NoError(t, db.Raw("PRAGMA busy_timeout").Scan(&busyTimeout).Error)
+	assert.Equal(t, int(sqliteBusyTimeout.Milliseconds()), busyTimeout)
+
+	require.NoError(t, CheckMigrations(db)(context.Background()))
+}
+
+func TestSQLiteFile_IncrementViewsConcurrently(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	postRepo := repositories.NewPostRepository(db)
+
+	author := &models.User{Username: "author", Email: "author@example.com", Name: "Author", Password: "hash", Role: "author"}
+	require.NoError(t, repositories.NewUserRepository(db).Create(author))
+	category := &models.Category{Name: "Go", Slug: "go"}
+	require.NoError(t, repositories.NewCategoryRepository(db).Create(category))
+	post := &models.Post{Title: "Popular", Slug: "popular", Content: "Body", CategoryID: category.ID, AuthorID: author.ID, Status: "published"}
+	draft := &models.Post{Title: "Unpublished", Slug: "unpublished", Content: "Body", CategoryID: category.ID, AuthorID: author.ID, Status: "draft"}
+	require.NoError(t, postRepo.Create(post))
+	require.NoError(t, postRepo.Create(draft))
+
+	const views = 100
+	counts := make(chan uint, views)
+	var wg sync.WaitGroup
+	for i := 0; i < views; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count, err := postRepo.IncrementViews(post.ID)
+			assert.NoError(t, err)
+			counts <- count
+		}()
+	}
+	wg.Wait()
+	close(counts)
+
+	// Every increment saw its own count, so each one is returned once
+	seen := make(map[uint]bool, views)
+	for count := range counts {
+		assert.False(t, seen[count], "count %d returned twice", count)
+		seen[count] = true
+	}
+	assert.Len(t, seen, views)
+
+	stored, err := postRepo.GetByID(post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(views), stored.ViewCount)
+
+	_, err = postRepo.IncrementViews(draft.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestSQLiteFile_RandomPublishedPost(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	postRepo := repositories.NewPostRepository(db)
+
+	author := &models.User{Username: "author", Email: "author@example.com", Name: "Author", Password: "hash", Role: "author"}
+	require.NoError(t, repositories.NewUserRepository(db).Create(author))
+	category := &models.Category{Name: "Go", Slug: "go"}
+	require.NoError(t, repositories.NewCategoryRepository(db).Create(category))
+
+	// Every third post is a draft
+	posts := make([]models.Post, 300)
+	for i := range posts {
+		status := "published"
+		if i%3 == 0 {
+			status = "draft"
+		}
+		slug := fmt.Sprintf("post-%d", i)
+		posts[i] = models.Post{Title: slug, Slug: slug, Content: "Body", CategoryID: category.ID, AuthorID: author.ID, Status: status}
+	}
+	require.NoError(t, db.CreateInBatches(posts, 100).Error)
+
+	// Record every statement from here on
+	var statements []string
+	record := func(tx *gorm.DB) { statements = append(statements, tx.Statement.SQL.String()) }
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record_query", record))
+	require.NoError(t, db.Callback().Row().After("gorm:row").Register("test:record_row", record))
+
+	total, err := postRepo.CountPublished()
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), total)
+
+	for _, offset := range []int64{0, 57, total - 1} {
+		statements = nil
+		post, err := postRepo.GetPublishedByOffset(offset)
+		require.NoError(t, err)
+		assert.Equal(t, "published", post.Status)
+		assert.Equal(t, author.ID, post.Author.ID)
+		assert.Equal(t, category.ID, post.Category.ID)
+
+		// One row is read, plus one query per preloaded association,
+		// however many posts there are
+		assert.LessOrEqual(t, len(statements), 4)
+		var postQueries []string
+		for _, statement := range statements {
+			assert.NotContains(t, strings.ToUpper(statement), "RANDOM()")
+			if strings.Contains(statement, "FROM `posts`") {
+				postQueries = append(postQueries, statement)
+			}
+		}
+		require.Len(t, postQueries, 1)
+		assert.Contains(t, postQueries[0], "LIMIT 1")
+	}
+
+	_, err = postRepo.GetPublishedByOffset(total)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestSQLiteFile_PostTrash(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	postRepo := repositories.NewPostRepository(db)
+
+	author := &models.User{Username: "author", Email: "author@example.com", Name: "Author", Password: "hash", Role: "author"}
+	require.NoError(t, repositories.NewUserRepository(db).Create(author))
+	category := &models.Category{Name: "Go", Slug: "go"}
+	require.NoError(t, repositories.NewCategoryRepository(db).Create(category))
+
+	newPost := func(slug string) *models.Post {
+		post := &models.Post{Title: slug, Slug: slug, Content: "Body", CategoryID: category.ID, AuthorID: author.ID, Status: "published"}
+		require.NoError(t, postRepo.Create(post))
+		return post
+	}
+
+	t.Run("deleted posts can be listed and restored", func(t *testing.T) {
+		post := newPost("restorable")
+		require.NoError(t, postRepo.Delete(post.ID))
+
+		_, err := postRepo.GetByID(post.ID)
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		trashed, total, err := postRepo.ListTrashed(1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, trashed, 1)
+		assert.Equal(t, post.ID, trashed[0].ID)
+		assert.True(t, trashed[0].DeletedAt.Valid)
+		assert.Equal(t, author.ID, trashed[0].Author.ID)
+
+		require.NoError(t, postRepo.Restore(post.ID))
+		restored, err := postRepo.GetByID(post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "restorable", restored.Slug)
+
+		_, total, err = postRepo.ListTrashed(1, 10)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+
+		// Only posts in the trash can be restored
+		assert.ErrorIs(t, postRepo.Restore(post.ID), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("purging removes only posts deleted before the cutoff", func(t *testing.T) {
+		old := newPost("deleted-long-ago")
+		recent := newPost("deleted-just-now")
+		live := newPost("still-live")
+
+		// Give the old post everything that points at a post
+		require.NoError(t, db.Create(&models.Comment{PostID: old.ID, UserID: author.ID, Content: "Gone", Status: "approved"}).Error)
+		require.NoError(t, db.Model(old).Association("Tags").Append(&models.Tag{Name: "Old", Slug: "old"}))
+		require.NoError(t, db.Create(&models.PostViewDay{PostID: old.ID, Day: "2026-01-01", Views: 3}).Error)
+		require.NoError(t, db.Create(&models.RecentView{UserID: author.ID, PostID: old.ID, ViewedAt: time.Now()}).Error)
+
+		require.NoError(t, postRepo.Delete(old.ID))
+		require.NoError(t, postRepo.Delete(recent.ID))
+		cutoff := time.Now().Add(-24 * time.Hour)
+		require.NoError(t, db.Unscoped().Model(&models.Post{}).Where("id = ?", old.ID).
+			Update("deleted_at", cutoff.Add(-time.Hour)).Error)
+
+		purged, err := postRepo.PurgeTrashed(cutoff)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		var remaining []uint
+		require.NoError(t, db.Unscoped().Model(&models.Post{}).Order("id").Pluck("id", &remaining).Error)
+		assert.NotContains(t, remaining, old.ID)
+		assert.Contains(t, remaining, recent.ID)
+		assert.Contains(t, remaining, live.ID)
+
+		for _, table := range []string{"comments", "post_tags", "post_view_days", "recent_views"} {
+			var count int64
+			require.NoError(t, db.Table(table).Where("post_id = ?", old.ID).Count(&count).Error)
+			assert.Zero(t, count, table)
+		}
+
+		// The recently deleted post can still be restored
+		require.NoError(t, postRepo.Restore(recent.ID))
+
+		purged, err = postRepo.PurgeTrashed(cutoff)
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+	})
+}
+
+func TestSQLiteFile_BulkPostActions(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	postRepo := repositories.NewPostRepository(db)
+
+	author := &models.User{Username: "author", Email: "author@example.com", Name: "Author", Password: "hash", Role: "author"}
+	require.NoError(t, repositories.NewUserRepository(db).Create(author))
+	category := &models.Category{Name: "Go", Slug: "go"}
+	require.NoError(t, repositories.NewCategoryRepository(db).Create(category))
+
+	published := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Now().Add(time.Hour)
+	posts := []models.Post{
+		{Title: "Draft", Slug: "draft", Content: "Body", CategoryID: category.ID, AuthorID: author.ID, Status: "draft"},
+		{Title: "Scheduled", Slug: "scheduled", Content: "Body", CategoryID: category.ID, AuthorID: author.ID, Status: "draft", PublishAt: &later},
+		{Title: "Live", Slug: "live", Content: "Body", CategoryID: category.ID, AuthorID: author.ID, Status: "published", PublishedAt: &published},
+	}
+	require.NoError(t, db.Create(&posts).Error)
+	ids := []uint{posts[0].ID, posts[1].ID, posts[2].ID}
+
+	var statements []string
+	record := func(tx *gorm.DB) { statements = append(statements, tx.Statement.SQL.String()) }
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:record_update", record))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:record_delete", record))
+
+	t.Run("publishing updates every post in one statement", func(t *testing.T) {
+		statements = nil
+		updated, err := postRepo.BulkUpdateStatus(ids, "published")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), updated)
+		require.Len(t, statements, 1)
+		assert.Contains(t, statements[0], "IN (")
+
+		var stored []models.Post
+		require.NoError(t, db.Order("id").Find(&stored, ids).Error)
+		for _, post := range stored {
+			assert.Equal(t, "published", post.Status)
+			assert.Nil(t, post.PublishAt)
+			require.NotNil(t, post.PublishedAt)
+		}
+		// A post that was already published keeps when it first went live
+		assert.True(t, stored[2].PublishedAt.Equal(published), "got %v", stored[2].PublishedAt)
+	})
+
+	t.Run("a failed transaction leaves the posts alone", func(t *testing.T) {
+		failure := errors.New("later step failed")
+		err := postRepo.WithTransaction(func(repo repositories.PostRepository) error {
+			if _, err := repo.BulkUpdateStatus(ids, "archived"); err != nil {
+				return err
+			}
+			if _, err := repo.BulkDelete(ids[:1]); err != nil {
+				return err
+			}
+			return failure
+		})
+		require.ErrorIs(t, err, failure)
+
+		var stored []models.Post
+		require.NoError(t, db.Order("id").Find(&stored, ids).Error)
+		require.Len(t, stored, 3)
+		for _, post := range stored {
+			assert.Equal(t, "published", post.Status)
+		}
+	})
+
+	t.Run("deleting soft-deletes every post in one statement", func(t *testing.T) {
+		statements = nil
+		deleted, err := postRepo.BulkDelete(ids[:2])
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		require.Len(t, statements, 1)
+
+		_, total, err := postRepo.ListTrashed(1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+	})
+}
+
+func TestSQLiteFile_CreatePostWithTags(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	postRepo := repositories.NewPostRepository(db)
+	categoryRepo := repositories.NewCategoryRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	postService := services.NewPostService(repositories.Repositories{Posts: postRepo, Categories: categoryRepo, Tags: tagRepo}, repositories.NewUnitOfWork(db), &config.Config{}, nil)
+
+	author := &models.User{Username: "author", Email: "author@example.com", Name: "Author", Password: "hash", Role: "author"}
+	require.NoError(t, repositories.NewUserRepository(db).Create(author))
+	category := &models.Category{Name: "Go", Slug: "go"}
+	require.NoError(t, categoryRepo.Create(category))
+	create := func(title string) (*models.Post, error) {
+		post, _, err := postService.Create(&models.CreatePostRequest{Title: title, Content: "Body", CategoryID: category.ID, Tags: []string{"Go", "Testing"}}, author.ID, "author")
+		return post, err
+	}
+
+	t.Run("the post is saved with its tags", func(t *testing.T) {
+		post, err := create("Tagged")
+		require.NoError(t, err)
+
+		stored, err := postRepo.GetByID(post.ID)
+		require.NoError(t, err)
+		assert.Len(t, stored.Tags, 2)
+	})
+
+	t.Run("tag queries with a cancelled context are abandoned", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := tagRepo.WithContext(ctx).GetBySlug("go")
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = tagRepo.GetBySlug("go")
+		assert.NoError(t, err)
+	})
+
+	t.Run("failing to tag the post leaves no post behind", func(t *testing.T) {
+		require.NoError(t, db.Migrator().DropTable("post_tags"))
+
+		_, err := create("Untaggable")
+		require.Error(t, err)
+
+		var count int64
+		require.NoError(t, db.Unscoped().Model(&models.Post{}).Where("title = ?", "Untaggable").Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
+
+func TestSQLiteFile_TransferPosts(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	postRepo := repositories.NewPostRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	postService := services.NewPostService(repositories.Repositories{Posts: postRepo, Users: userRepo}, repositories.NewUnitOfWork(db), &config.Config{}, nil)
+
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Name: "Admin", Password: "hash", Role: "admin"}
+	leaving := &models.User{Username: "leaving", Email: "leaving@example.com", Name: "Leaving", Password: "hash", Role: "author"}
+	staying := &models.User{Username: "staying", Email: "staying@example.com", Name: "Staying", Password: "hash", Role: "editor"}
+	for _, user := range []*models.User{admin, leaving, staying} {
+		require.NoError(t, userRepo.Create(user))
+	}
+	category := &models.Category{Name: "Go", Slug: "go"}
+	require.NoError(t, db.Create(category).Error)
+	var posts []*models.Post
+	for _, slug := range []string{"one", "two", "three"} {
+		post := &models.Post{Title: slug, Slug: slug, Content: "Body", Status: "published", AuthorID: leaving.ID, CategoryID: category.ID}
+		require.NoError(t, postRepo.Create(post))
+		posts = append(posts, post)
+	}
+	require.NoError(t, postRepo.Delete(posts[2].ID))
+
+	t.Run("a single post changes author and is audited", func(t *testing.T) {
+		post, err := postService.Transfer(posts[0].ID, staying.ID, admin.ID)
+		require.NoError(t, err)
+		assert.Equal(t, staying.ID, post.AuthorID)
+		require.NotNil(t, post.UpdatedBy)
+		assert.Equal(t, admin.ID, *post.UpdatedBy)
+
+		var entry models.AuditLog
+		require.NoError(t, db.Where("action = ?", models.AuditPostTransfer).First(&entry).Error)
+		assert.Equal(t, admin.ID, entry.ActorID)
+		assert.Equal(t, posts[0].ID, entry.TargetID)
+		assert.EqualValues(t, leaving.ID, entry.Details["from_author_id"])
+	})
+
+	t.Run("the rest of the author's posts move together, trashed ones too", func(t *testing.T) {
+		transferred, err := postService.TransferAll(leaving.ID, staying.ID, admin.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), transferred)
+
+		var left int64
+		require.NoError(t, db.Unscoped().Model(&models.Post{}).Where("author_id = ?", leaving.ID).Count(&left).Error)
+		assert.Zero(t, left)
+
+		var entry models.AuditLog
+		require.NoError(t, db.Where("action = ?", models.AuditPostsTransfer).First(&entry).Error)
+		assert.EqualValues(t, 2, entry.Details["posts"])
+	})
+}
+
+func TestSQLiteFile_CommentModeration(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	commentRepo := repositories.NewCommentRepository(db)
+	commentService := services.NewCommentService(commentRepo, repositories.NewPostRepository(db), nil, &config.Config{}, nil)
+
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Name: "Admin", Password: "hash", Role: "admin"}
+	reader := &models.User{Username: "reader", Email: "reader@example.com", Name: "Reader", Password: "hash", Role: "author"}
+	require.NoError(t, db.Create([]*models.User{admin, reader}).Error)
+	category := &models.Category{Name: "Go", Slug: "go"}
+	require.NoError(t, db.Create(category).Error)
+	post := &models.Post{Title: "Moderated", Slug: "moderated", Content: "Body", Status: "published", AuthorID: admin.ID, CategoryID: category.ID}
+	require.NoError(t, db.Create(post).Error)
+	now := time.Now()
+	spam := &models.Comment{PostID: post.ID, UserID: reader.ID, Content: "Buy now", Status: "pending", CreatedAt: now.Add(-2 * time.Hour)}
+	useful := &models.Comment{PostID: post.ID, UserID: reader.ID, Content: "Useful", Status: "pending", CreatedAt: now.Add(-time.Hour)}
+	require.NoError(t, db.Create([]*models.Comment{useful, spam}).Error)
+
+	t.Run("the queue lists pending comments oldest first with post and author", func(t *testing.T) {
+		pending, total, err := commentService.ListPending(1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, pending, 2)
+		assert.Equal(t, spam.ID, pending[0].ID)
+		require.NotNil(t, pending[0].Post)
+		assert.Equal(t, "Moderated", pending[0].Post.Title)
+		require.NotNil(t, pending[0].User)
+		assert.Equal(t, "Reader", pending[0].User.Name)
+	})
+
+	t.Run("approving and rejecting record the moderator", func(t *testing.T) {
+		approved, err := commentService.Approve(useful.ID, admin.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "approved", approved.Status)
+		require.NotNil(t, approved.ModeratedBy)
+		assert.Equal(t, admin.ID, *approved.ModeratedBy)
+		assert.NotNil(t, approved.ModeratedAt)
+
+		rejected, err := commentService.Reject(spam.ID, admin.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "rejected", rejected.Status)
+
+		_, total, err := commentService.ListPending(1, 10)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("rejected comments are hidden from the post", func(t *testing.T) {
+		comments, total, err := commentService.GetByPost(post.ID, 1, 10, 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, comments, 1)
+		assert.Equal(t, useful.ID, comments[0].ID)
+	})
+}
+
+func TestSQLiteFile_CategoryPostCounts(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	categoryRepo := repositories.NewCategoryRepository(db)
+	postRepo := repositories.NewPostRepository(db)
+
+	author := &models.User{Username: "author", Email: "author@example.com", Name: "Author", Password: "hash", Role: "author"}
+	require.NoError(t, db.Create(author).Error)
+	golang := &models.Category{Name: "Go", Slug: "go"}
+	vue := &models.Category{Name: "Vue", Slug: "vue"}
+	empty := &models.Category{Name: "Empty", Slug: "empty"}
+	for _, category := range []*models.Category{golang, vue, empty} {
+		require.NoError(t, categoryRepo.Create(category))
+	}
+	seed := func(category *models.Category, slug, status string) *models.Post {
+		post := &models.Post{Title: slug, Slug: slug, Content: "Body", Status: status, AuthorID: author.ID, CategoryID: category.ID}
+		require.NoError(t, postRepo.Create(post))
+		return post
+	}
+	seed(golang, "go-1", "published")
+	seed(golang, "go-2", "published")
+	seed(golang, "go-draft", "draft")
+	require.NoError(t, postRepo.Delete(seed(golang, "go-deleted", "published").ID))
+	seed(vue, "vue-1", "published")
+	seed(vue, "vue-archived", "archived")
+
+	counts := func(categories []models.Category) map[string]int64 {
+		bySlug := make(map[string]int64, len(categories))
+		for _, category := range categories {
+			bySlug[category.Slug] = category.PostCount
+		}
+		return bySlug
+	}
+	want := map[string]int64{"go": 2, "vue": 1, "empty": 0}
+
+	t.Run("listing counts only published posts", func(t *testing.T) {
+		categories, total, err := categoryRepo.ListWithCounts(1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, want, counts(categories))
+	})
+
+	t.Run("searching counts them the same way", func(t *testing.T) {
+		categories, _, err := categoryRepo.Search(&models.CategorySearchRequest{Sort: "name", Order: "asc"})
+		require.NoError(t, err)
+		assert.Equal(t, want, counts(categories))
+	})
+}
+
+func TestSQLiteFile_AuditLog(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	auditRepo := repositories.NewAuditLogRepository(db)
+	auditService := services.NewAuditLogService(auditRepo, &config.Config{Jobs: config.JobsConfig{AuditRetentionDays: 30}})
+
+	// Twelve entries a day apart, the oldest 12 days ago, alternating between
+	// two admins; every third one is a bulk transfer
+	now := time.Now()
+	for i := 0; i < 12; i++ {
+		entry := &models.AuditLog{
+			ActorID:    uint(1 + i%2),
+			Action:     models.AuditPostTransfer,
+			TargetType: "post",
+			TargetID:   uint(100 + i),
+			CreatedAt:  now.AddDate(0, 0, i-12),
+		}
+		if i%3 == 0 {
+			entry.Action, entry.TargetType = models.AuditPostsTransfer, "user"
+		}
+		require.NoError(t, auditRepo.Create(entry))
+	}
+	targets := func(entries []models.AuditLog) []uint {
+		ids := make([]uint, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.TargetID
+		}
+		return ids
+	}
+
+	t.Run("filtered pages follow each other without gaps or repeats", func(t *testing.T) {
+		filter := models.AuditLogFilter{ActorID: 1, Action: models.AuditPostTransfer}
+		var seen []uint
+		cursor := ""
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 5, "pagination does not end")
+			page, err := auditService.List(filter, cursor, 2)
+			require.NoError(t, err)
+			seen = append(seen, targets(page.Entries)...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		// Actor 1 wrote the even entries; 100, 106 were bulk transfers
+		assert.Equal(t, []uint{110, 108, 104, 102}, seen)
+	})
+
+	t.Run("new entries do not shift later pages", func(t *testing.T) {
+		first, err := auditService.List(models.AuditLogFilter{}, "", 3)
+		require.NoError(t, err)
+		require.NoError(t, auditRepo.Create(&models.AuditLog{ActorID: 1, Action: models.AuditPostTransfer, TargetType: "post", TargetID: 999}))
+
+		second, err := auditService.List(models.AuditLogFilter{}, first.NextCursor, 3)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{111, 110, 109}, targets(first.Entries))
+		assert.Equal(t, []uint{108, 107, 106}, targets(second.Entries))
+		require.NoError(t, db.Where("target_id = ?", 999).Delete(&models.AuditLog{}).Error)
+	})
+
+	t.Run("date ranges include from and exclude to", func(t *testing.T) {
+		from, to := now.AddDate(0, 0, -5), now.AddDate(0, 0, -2)
+		page, err := auditService.List(models.AuditLogFilter{TargetType: "post", From: &from, To: &to}, "", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{108, 107}, targets(page.Entries))
+	})
+
+	t.Run("export writes every matching entry", func(t *testing.T) {
+		var exported []uint
+		err := auditService.Export(models.AuditLogFilter{TargetType: "user"}, func(entry models.AuditLog) error {
+			exported = append(exported, entry.TargetID)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint{109, 106, 103, 100}, exported)
+	})
+
+	t.Run("an unknown cursor is rejected", func(t *testing.T) {
+		_, err := auditService.List(models.AuditLogFilter{}, "abc", 10)
+		assert.ErrorIs(t, err, services.ErrInvalidAuditCursor)
+	})
+
+	t.Run("purge removes entries older than the retention", func(t *testing.T) {
+		require.NoError(t, auditRepo.Create(&models.AuditLog{ActorID: 1, Action: models.AuditPostTransfer, TargetType: "post", TargetID: 1, CreatedAt: now.AddDate(0, 0, -31)}))
+
+		require.NoError(t, auditService.Purge(context.Background()))
+
+		var left []models.AuditLog
+		require.NoError(t, db.Order("id DESC").Find(&left).Error)
+		assert.Len(t, left, 12)
+		assert.NotContains(t, targets(left), uint(1))
+	})
+}
+
+func TestSQLiteFile_CategoryTree(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	categoryRepo := repositories.NewCategoryRepository(db)
+
+	create := func(name string, parentID *uint) *models.Category {
+		category := &models.Category{Name: name, Slug: strings.ToLower(name), ParentID: parentID}
+		require.NoError(t, categoryRepo.Create(category))
+		return category
+	}
+	programming := create("Programming", nil)
+	golang := create("Go", &programming.ID)
+	create("Generics", &golang.ID)
+	create("Databases", &programming.ID)
+	create("Design", nil)
+
+	tree, err := categoryRepo.GetTree()
+	require.NoError(t, err)
+	require.Len(t, tree, 2)
+	assert.Equal(t, "Design", tree[0].Name)
+	assert.Equal(t, "Programming", tree[1].Name)
+	require.Len(t, tree[1].Children, 2)
+	assert.Equal(t, "Databases", tree[1].Children[0].Name)
+	assert.Equal(t, "Go", tree[1].Children[1].Name)
+	require.Len(t, tree[1].Children[1].Children, 1)
+	assert.Equal(t, "Generics", tree[1].Children[1].Children[0].Name)
+
+	children, err := categoryRepo.CountChildren(programming.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), children)
+
+	// Moving Programming's children to the top level
+	require.NoError(t, categoryRepo.ReparentChildren(programming.ID, nil))
+	tree, err = categoryRepo.GetTree()
+	require.NoError(t, err)
+	assert.Len(t, tree, 4)
+}
+
+func TestSQLiteFile_PostWithDeletedRelations(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+	postRepo := repositories.NewPostRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	categoryRepo := repositories.NewCategoryRepository(db)
+
+	author := &models.User{Username: "author", Email: "author@example.com", Name: "Former Author", Password: "hash", Role: "author"}
+	require.NoError(t, userRepo.Create(author))
+	category := &models.Category{Name: "Retired", Slug: "retired"}
+	require.NoError(t, categoryRepo.Create(category))
+	post := &models.Post{Title: "Orphaned", Slug: "orphaned", Content: "Body", CategoryID: category.ID, AuthorID: author.ID, Status: "published"}
+	require.NoError(t, postRepo.Create(post))
+	require.NoError(t, userRepo.Delete(author.ID))
+	require.NoError(t, categoryRepo.Delete(category.ID))
+
+	getPost := func(mode string) *models.Post {
+		cfg := &config.Config{Post: config.PostConfig{DeletedRelations: mode}}
+		postService := services.NewPostService(repositories.Repositories{Posts: postRepo, Users: userRepo, Categories: categoryRepo}, repositories.NewUnitOfWork(db), cfg, nil)
+		found, err := postService.GetByID(post.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found.Author)
+		require.NotNil(t, found.Category)
+		return found
+	}
+
+	t.Run("unscoped shows the deleted author and category as stored", func(t *testing.T) {
+		found := getPost(services.DeletedRelationsUnscoped)
+
+		assert.Equal(t, "Former Author", found.Author.Name)
+		assert.Equal(t, "Retired", found.Category.Name)
+	})
+
+	t.Run("placeholder replaces them", func(t *testing.T) {
+		found := getPost(services.DeletedRelationsPlaceholder)
+
+		assert.Equal(t, author.ID, found.Author.ID)
+		assert.Equal(t, models.DeletedName, found.Author.Name)
+		assert.Empty(t, found.Author.Email)
+		assert.Equal(t, category.ID, found.Category.ID)
+		assert.Equal(t, models.DeletedName, found.Category.Name)
+	})
+
+	t.Run("listings fill them in too", func(t *testing.T) {
+		cfg := &config.Config{Post: config.PostConfig{DeletedRelations: services.DeletedRelationsPlaceholder}}
+		postService := services.NewPostService(repositories.Repositories{Posts: postRepo, Users: userRepo, Categories: categoryRepo}, repositories.NewUnitOfWork(db), cfg, nil)
+
+		posts, _, err := postService.List(1, 10, map[string]interface{}{})
+		require.NoError(t, err)
+		require.Len(t, posts, 1)
+		require.NotNil(t, posts[0].Author)
+		assert.Equal(t, models.DeletedName, posts[0].Author.Name)
+	})
+}
+
+func TestOpen_UnknownDriver(t *testing.T) {
+	_, err := Open(&config.DatabaseConfig{Driver: "postgres"})
+
+	assert.Error(t, err)
+}
+
+func TestSQLiteFile_Repositories(t *testing.T) {
+	db, _ := openSQLiteFile(t)
+
+	userRepo := repositories.NewUserRepository(db)
+	categoryRepo := repositories.NewCategoryRepository(db)
+	postRepo := repositories.NewPostRepository(db)
+	commentRepo := repositories.NewCommentRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	postStatsRepo := repositories.NewPostStatsRepository(db)
+
+	author := &models.User{Username: "author", Email: "author@example.com", Name: "Author", Password: "hash", Role: "author"}
+	require.NoError(t, userRepo.Create(author))
+	found, err := userRepo.GetByEmail("author@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, author.ID, found.ID)
+
+	t.Run("notification preferences are stored unset until chosen", func(t *testing.T) {
+		assert.Nil(t, found.NotificationPreferences.CommentOnPostEmail)
+
+		off := false
+		found.NotificationPreferences.CommentOnPostEmail = &off
+		require.NoError(t, userRepo.Update(found))
+
+		reloaded, err := userRepo.GetByID(author.ID)
+		require.NoError(t, err)
+		require.NotNil(t, reloaded.NotificationPreferences.CommentOnPostEmail)
+		assert.False(t, *reloaded.NotificationPreferences.CommentOnPostEmail)
+		assert.Nil(t, reloaded.NotificationPreferences.CommentOnPostInApp)
+	})
+
+	category := &models.Category{Name: "Go", Slug: "go"}
+	require.NoError(t, categoryRepo.Create(category))
+	t.Run("duplicate slugs are reported portably", func(t *testing.T) {
+		err := categoryRepo.Create(&models.Category{Name: "Go", Slug: "go"})
+		assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+	})
+
+	published := &models.Post{Title: "Concurrency in Go", Slug: "concurrency-in-go", Content: "Goroutines and channels", CategoryID: category.ID, AuthorID: author.ID, Status: "published"}
+	draft := &models.Post{Title: "Go generics", Slug: "go-generics", Content: "Type parameters", CategoryID: category.ID, AuthorID: author.ID, Status: "draft"}
+	require.NoError(t, postRepo.Create(published))
+	require.NoError(t, postRepo.Create(draft))
+
+	t.Run("search falls back to LIKE matching", func(t *testing.T) {
+		posts, total, mode, err := postRepo.Search(&models.PostSearchRequest{Query: "channels"})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.SearchModeLike, mode)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, posts, 1)
+		assert.Equal(t, published.ID, posts[0].ID)
+		assert.Equal(t, "Go", posts[0].Category.Name)
+	})
+
+	t.Run("listing filters by status", func(t *testing.T) {
+		posts, total, err := postRepo.GetByAuthor(author.ID, "published", 1, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, posts, 1)
+		assert.Equal(t, published.ID, posts[0].ID)
+	})
+
+	t.Run("search limits unpublished posts to their author", func(t *testing.T) {
+		_, total, _, err := postRepo.Search(&models.PostSearchRequest{Query: "Go", OwnUnpublishedOf: author.ID})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+
+		_, total, _, err = postRepo.Search(&models.PostSearchRequest{Query: "Go", OwnUnpublishedOf: author.ID + 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+
+		_, total, _, err = postRepo.Search(&models.PostSearchRequest{Status: "draft", OwnUnpublishedOf: author.ID + 1})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("titles are matched ignoring case, skipping deleted posts", func(t *testing.T) {
+		found, err := postRepo.FindByTitle("CONCURRENCY in go", 0)
+		require.NoError(t, err)
+		assert.Equal(t, published.ID, found.ID)
+
+		_, err = postRepo.FindByTitle("Concurrency in Go", published.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		removed := &models.Post{Title: "Removed post", Slug: "removed-post", Content: "Gone", CategoryID: category.ID, AuthorID: author.ID, Status: "archived"}
+		require.NoError(t, postRepo.Create(removed))
+		require.NoError(t, postRepo.Delete(removed.ID))
+		_, err = postRepo.FindByTitle("Removed post", 0)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("updating a preloaded post keeps its new category", func(t *testing.T) {
+		other := &models.Category{Name: "Rust", Slug: "rust"}
+		require.NoError(t, categoryRepo.Create(other))
+		moved := &models.Post{Title: "Moving post", Slug: "moving-post", Content: "Elsewhere", CategoryID: category.ID, AuthorID: author.ID, Status: "archived"}
+		require.NoError(t, postRepo.Create(moved))
+
+		loaded, err := postRepo.GetByID(moved.ID)
+		require.NoError(t, err)
+		require.NotNil(t, loaded.Category)
+		loaded.CategoryID = other.ID
+		require.NoError(t, postRepo.Update(loaded))
+
+		reloaded, err := postRepo.GetByID(moved.ID)
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, reloaded.CategoryID)
+		require.NoError(t, postRepo.Delete(moved.ID))
+	})
+
+	t.Run("stale drafts are archived once", func(t *testing.T) {
+		longAgo := time.Now().AddDate(0, 0, -100)
+		require.NoError(t, db.Model(&models.Post{}).Where("id IN ?", []uint{published.ID, draft.ID}).UpdateColumn("updated_at", longAgo).Error)
+		cutoff := time.Now().AddDate(0, 0, -90)
+
+		stale, err := postRepo.ListStaleDrafts(context.Background(), cutoff)
+		require.NoError(t, err)
+		require.Len(t, stale, 1)
+		assert.Equal(t, draft.ID, stale[0].ID)
+
+		archived, err := postRepo.ArchiveDraft(context.Background(), draft.ID, cutoff)
+		require.NoError(t, err)
+		assert.True(t, archived)
+
+		archived, err = postRepo.ArchiveDraft(context.Background(), draft.ID, cutoff)
+		require.NoError(t, err)
+		assert.False(t, archived)
+
+		// Restore the draft for the subtests below
+		require.NoError(t, db.Model(&models.Post{}).Where("id = ?", draft.ID).UpdateColumn("status", "draft").Error)
+	})
+
+	t.Run("recent comments join posts and users", func(t *testing.T) {
+		require.NoError(t, commentRepo.Create(&models.Comment{PostID: published.ID, UserID: author.ID, Content: "Nice one", Status: "approved"}))
+		require.NoError(t, commentRepo.Create(&models.Comment{PostID: draft.ID, UserID: author.ID, Content: "Hidden", Status: "approved"}))
+
+		comments, err := commentRepo.GetRecent(5)
+
+		require.NoError(t, err)
+		require.Len(t, comments, 1)
+		assert.Equal(t, "concurrency-in-go", comments[0].Post.Slug)
+		assert.Equal(t, "Author", comments[0].Author.Name)
+	})
+
+	t.Run("comment tree lists parents before replies with authors", func(t *testing.T) {
+		root := &models.Comment{PostID: published.ID, UserID: author.ID, Content: "Root", Status: "approved"}
+		require.NoError(t, commentRepo.Create(root))
+		reply := &models.Comment{PostID: published.ID, UserID: author.ID, ParentID: &root.ID, Depth: 1, Content: "Reply", Status: "approved", CreatedAt: root.CreatedAt.Add(-time.Hour)}
+		require.NoError(t, commentRepo.Create(reply))
+
+		comments, err := commentRepo.GetTree(published.ID)
+
+		require.NoError(t, err)
+		require.NotEmpty(t, comments)
+		assert.Equal(t, reply.ID, comments[len(comments)-1].ID)
+		require.NotNil(t, comments[0].User)
+		assert.Equal(t, "Author", comments[0].User.Name)
+	})
+
+	t.Run("post stats aggregate daily views and comment statuses", func(t *testing.T) {
+		ctx := context.Background()
+		for _, day := range []string{"2026-01-01", "2026-01-09", "2026-01-10", "2026-01-10"} {
+			require.NoError(t, postStatsRepo.RecordView(ctx, published.ID, day))
+		}
+		require.NoError(t, postStatsRepo.RecordView(ctx, draft.ID, "2026-01-10"))
+
+		total, recent, err := postStatsRepo.CountViews(ctx, published.ID, "2026-01-09")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), total)
+		assert.Equal(t, int64(3), recent)
+
+		total, recent, err = postStatsRepo.CountViews(ctx, 9999, "2026-01-09")
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Zero(t, recent)
+
+		require.NoError(t, commentRepo.Create(&models.Comment{PostID: published.ID, UserID: author.ID, Content: "Awaiting review", Status: "pending"}))
+		counts, err := postStatsRepo.CountCommentsByStatus(ctx, published.ID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"approved": 3, "pending": 1}, counts)
+	})
+
+	t.Run("recent views move rereads to the front and keep only the newest", func(t *testing.T) {
+		ctx := context.Background()
+		start := time.Now()
+		for i, postID := range []uint{1, 2, 3, 1, 4} {
+			require.NoError(t, postStatsRepo.RecordRecentView(ctx, author.ID, postID, start.Add(time.Duration(i)*time.Second), 3))
+		}
+
+		postIDs, err := postStatsRepo.ListRecentViews(ctx, author.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{4, 1, 3}, postIDs)
+	})
+
+	t.Run("batch lookups are keyed by id and skip missing and deleted rows", func(t *testing.T) {
+		retired := &models.Category{Name: "Retired", Slug: "retired"}
+		require.NoError(t, categoryRepo.Create(retired))
+		require.NoError(t, categoryRepo.Delete(retired.ID))
+
+		categories, err := categoryRepo.GetByIDs([]uint{category.ID, retired.ID, 9999})
+		require.NoError(t, err)
+		require.Len(t, categories, 1)
+		assert.Equal(t, "Go", categories[category.ID].Name)
+
+		reader := &models.User{Username: "reader", Email: "reader@example.com", Name: "Reader", Password: "hash", Role: "author"}
+		gone := &models.User{Username: "gone", Email: "gone@example.com", Name: "Gone", Password: "hash", Role: "author"}
+		require.NoError(t, userRepo.Create(reader))
+		require.NoError(t, userRepo.Create(gone))
+		require.NoError(t, userRepo.Delete(gone.ID))
+
+		users, err := userRepo.GetByIDs([]uint{author.ID, reader.ID, gone.ID, 9999})
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, "author", users[author.ID].Username)
+		assert.Equal(t, "reader", users[reader.ID].Username)
+
+		empty, err := userRepo.GetByIDs(nil)
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("comment summaries count approved comments and find the newest", func(t *testing.T) {
+		commenter := &models.User{Username: "commenter", Email: "commenter@example.com", Name: "Commenter", Password: "hash", Role: "author"}
+		require.NoError(t, userRepo.Create(commenter))
+
+		newPost := func(slug, status string) *models.Post {
+			post := &models.Post{Title: slug, Slug: slug, Content: "Body", CategoryID: category.ID, AuthorID: author.ID, Status: status}
+			require.NoError(t, postRepo.Create(post))
+			return post
+		}
+		busy := newPost("busy-post", "published")
+		quiet := newPost("quiet-post", "published")
+		silent := newPost("silent-post", "published")
+		hidden := newPost("hidden-post", "draft")
+
+		base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		comment := func(post *models.Post, user *models.User, status string, minutes int) *models.Comment {
+			created := &models.Comment{PostID: post.ID, UserID: user.ID, Content: "Hi", Status: status, CreatedAt: base.Add(time.Duration(minutes) * time.Minute)}
+			require.NoError(t, commentRepo.Create(created))
+			return created
+		}
+		comment(busy, author, "approved", 1)
+		newest := comment(busy, commenter, "approved", 5)
+		comment(busy, author, "approved", 3)
+		comment(busy, author, "pending", 10)
+		require.NoError(t, commentRepo.Delete(comment(busy, author, "approved", 20).ID))
+		quietest := comment(quiet, author, "approved", 2)
+		comment(hidden, author, "approved", 30)
+
+		summaries, err := commentRepo.GetSummaries([]uint{busy.ID, quiet.ID, silent.ID, hidden.ID})
+		require.NoError(t, err)
+		require.Len(t, summaries, 2)
+
+		assert.Equal(t, int64(3), summaries[busy.ID].Count)
+		require.NotNil(t, summaries[busy.ID].Latest)
+		assert.Equal(t, newest.ID, summaries[busy.ID].Latest.ID)
+		assert.Equal(t, "commenter", summaries[busy.ID].Latest.Author.Username)
+		assert.True(t, base.Add(5*time.Minute).Equal(summaries[busy.ID].Latest.CreatedAt))
+
+		assert.Equal(t, int64(1), summaries[quiet.ID].Count)
+		require.NotNil(t, summaries[quiet.ID].Latest)
+		assert.Equal(t, quietest.ID, summaries[quiet.ID].Latest.ID)
+
+		assert.NotContains(t, summaries, silent.ID)
+		assert.NotContains(t, summaries, hidden.ID)
+	})
+
+	t.Run("search ranks title matches before content matches", func(t *testing.T) {
+		base := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+		titled := &models.Post{Title: "Ranking search results", Slug: "ranking-search-results", Content: "Order matters", CategoryID: category.ID, AuthorID: author.ID, Status: "published", CreatedAt: base}
+		mentioned := &models.Post{Title: "Relevance", Slug: "relevance", Content: "A note on ranking", CategoryID: category.ID, AuthorID: author.ID, Status: "published", CreatedAt: base.Add(time.Hour)}
+		require.NoError(t, postRepo.Create(titled))
+		require.NoError(t, postRepo.Create(mentioned))
+
+		posts, total, mode, err := postRepo.Search(&models.PostSearchRequest{Query: "ranking"})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.SearchModeLike, mode)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, posts, 2)
+		assert.Equal(t, titled.ID, posts[0].ID, "the title match should rank first even though it is older")
+		assert.Equal(t, mentioned.ID, posts[1].ID)
+	})
+
+	t.Run("notifications track read state", func(t *testing.T) {
+		notification := &models.Notification{UserID: author.ID, Type: models.NotificationCommentOnPost, Message: "New comment"}
+		require.NoError(t, notificationRepo.Create(notification))
+
+		unread, err := notificationRepo.CountUnread(author.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), unread)
+
+		require.NoError(t, notificationRepo.MarkRead(author.ID, notification.ID))
+		unread, err = notificationRepo.CountUnread(author.ID)
+		require.NoError(t, err)
+		assert.Zero(t, unread)
+	})
+
+	t.Run("posts are counted per author", func(t *testing.T) {
+		writer := &models.User{Username: "writer", Email: "writer@example.com", Name: "Writer", Password: "hash", Role: "author"}
+		drafter := &models.User{Username: "drafter", Email: "drafter@example.com", Name: "Drafter", Password: "hash", Role: "author"}
+		idle := &models.User{Username: "idle", Email: "idle@example.com", Name: "Idle", Password: "hash", Role: "author"}
+		for _, user := range []*models.User{writer, drafter, idle} {
+			require.NoError(t, userRepo.Create(user))
+		}
+		seed := []*models.Post{
+			{Title: "Writer one", Slug: "writer-one", Content: "One", CategoryID: category.ID, AuthorID: writer.ID, Status: "published"},
+			{Title: "Writer two", Slug: "writer-two", Content: "Two", CategoryID: category.ID, AuthorID: writer.ID, Status: "published"},
+			{Title: "Writer draft", Slug: "writer-draft", Content: "Three", CategoryID: category.ID, AuthorID: writer.ID, Status: "draft"},
+			{Title: "Writer removed", Slug: "writer-removed", Content: "Four", CategoryID: category.ID, AuthorID: writer.ID, Status: "published"},
+			{Title: "Drafter draft", Slug: "drafter-draft", Content: "Five", CategoryID: category.ID, AuthorID: drafter.ID, Status: "draft"},
+		}
+		for _, post := range seed {
+			require.NoError(t, postRepo.Create(post))
+		}
+		require.NoError(t, postRepo.Delete(seed[3].ID))
+
+		count, err := postRepo.CountByAuthor(writer.ID, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		count, err = postRepo.CountByAuthor(writer.ID, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = postRepo.CountByAuthor(drafter.ID, true)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		counts, err := postRepo.CountByAuthors([]uint{writer.ID, drafter.ID, idle.ID})
+		require.NoError(t, err)
+		assert.Equal(t, map[uint]int64{writer.ID: 3, drafter.ID: 1}, counts)
+
+		counts, err = postRepo.CountByAuthors(nil)
+		require.NoError(t, err)
+		assert.Empty(t, counts)
+	})
+
+	t.Run("an author's latest post time counts deleted posts", func(t *testing.T) {
+		poster := &models.User{Username: "poster", Email: "poster@example.com", Name: "Poster", Password: "hash", Role: "author"}
+		require.NoError(t, userRepo.Create(poster))
+
+		latest, err := postRepo.LatestCreatedAt(poster.ID)
+		require.NoError(t, err)
+		assert.True(t, latest.IsZero())
+
+		base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+		older := &models.Post{Title: "Older", Slug: "poster-older", Content: "Older", CategoryID: category.ID, AuthorID: poster.ID, Status: "published", CreatedAt: base}
+		newer := &models.Post{Title: "Newer", Slug: "poster-newer", Content: "Newer", CategoryID: category.ID, AuthorID: poster.ID, Status: "draft", CreatedAt: base.Add(time.Minute)}
+		require.NoError(t, postRepo.Create(older))
+		require.NoError(t, postRepo.Create(newer))
+		require.NoError(t, postRepo.Delete(newer.ID))
+
+		latest, err = postRepo.LatestCreatedAt(poster.ID)
+		require.NoError(t, err)
+		assert.True(t, latest.Equal(base.Add(time.Minute)), "got %v", latest)
+	})
+
+	t.Run("queries with a cancelled context are abandoned", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := postRepo.WithContext(ctx).GetByID(1)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, _, err = postRepo.WithContext(ctx).List(1, 10, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = userRepo.WithContext(ctx).GetByID(author.ID)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, _, err = categoryRepo.WithContext(ctx).List(1, 10)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, _, err = commentRepo.WithContext(ctx).List(1, 10, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// The original repositories keep working
+		_, err = userRepo.GetByID(author.ID)
+		assert.NoError(t, err)
+	})
+}
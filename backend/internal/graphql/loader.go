@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"sync"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+// loader batches lookups by ID for the lifetime of one request. IDs requested
+// while a level of the query is being resolved are collected and fetched with
+// a single query the first time any of their thunks runs; results are cached
+// so repeated references cost nothing.
+type loader[T any] struct {
+	mu      sync.Mutex
+	fetch   func(ids []uint) (map[uint]*T, error)
+	pending []uint
+	cache   map[uint]*T
+}
+
+func newLoader[T any](fetch func(ids []uint) (map[uint]*T, error)) *loader[T] {
+	return &loader[T]{fetch: fetch, cache: make(map[uint]*T)}
+}
+
+// prime seeds the cache with a value that was already loaded, e.g. through a
+// gorm Preload, so it is never fetched again
+func (l *loader[T]) prime(id uint, value *T) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.cache[id]; !ok {
+		l.cache[id] = value
+	}
+}
+
+// load queues id for the next batch and returns a thunk the executor resolves
+// once every sibling field has had the chance to queue its own ID
+func (l *loader[T]) load(id uint) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, ok := l.cache[id]; !ok {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if err := l.flush(); err != nil {
+			return nil, err
+		}
+		if value := l.cache[id]; value != nil {
+			return value, nil
+		}
+		return nil, nil
+	}
+}
+
+// flush fetches every pending ID. Callers must hold l.mu.
+func (l *loader[T]) flush() error {
+	if len(l.pending) == 0 {
+		return nil
+	}
+
+	ids := l.pending
+	l.pending = nil
+
+	found, err := l.fetch(ids)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		// Missing IDs are cached as nil so they are not fetched again
+		l.cache[id] = found[id]
+	}
+	return nil
+}
+
+// loaders holds the per-request loaders shared by every resolver
+type loaders struct {
+	users      *loader[models.User]
+	categories *loader[models.Category]
+}
+
+func newLoaders(userRepo repositories.UserRepository, categoryRepo repositories.CategoryRepository) *loaders {
+	return &loaders{
+		users:      newLoader(userRepo.GetByIDs),
+		categories: newLoader(categoryRepo.GetByIDs),
+	}
+}
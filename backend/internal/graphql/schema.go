@@ -0,0 +1,356 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+// Request is the body of a GraphQL HTTP request
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Viewer identifies who a query runs on behalf of. The zero value is an
+// anonymous caller.
+type Viewer struct {
+	UserID uint
+	Role   string
+}
+
+type requestState struct {
+	viewer  Viewer
+	loaders *loaders
+}
+
+type stateKey struct{}
+
+func stateFrom(ctx context.Context) *requestState {
+	state, _ := ctx.Value(stateKey{}).(*requestState)
+	if state == nil {
+		return &requestState{}
+	}
+	return state
+}
+
+// Executor runs read-only GraphQL queries against the blog's posts,
+// categories and comments. It applies the same visibility rules as the REST
+// API: drafts and archived posts are only visible to their author and admins.
+type Executor struct {
+	schema          graphql.Schema
+	postService     services.PostService
+	categoryService services.CategoryService
+	commentService  services.CommentService
+	userRepo        repositories.UserRepository
+	categoryRepo    repositories.CategoryRepository
+}
+
+func NewExecutor(
+	postService services.PostService,
+	categoryService services.CategoryService,
+	commentService services.CommentService,
+	userRepo repositories.UserRepository,
+	categoryRepo repositories.CategoryRepository,
+) (*Executor, error) {
+	e := &Executor{
+		postService:     postService,
+		categoryService: categoryService,
+		commentService:  commentService,
+		userRepo:        userRepo,
+		categoryRepo:    categoryRepo,
+	}
+
+	schema, err := e.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	e.schema = schema
+
+	return e, nil
+}
+
+// Execute runs a single query for viewer. Each call gets its own loaders, so
+// cached authors and categories never leak between requests.
+func (e *Executor) Execute(ctx context.Context, req Request, viewer Viewer) *graphql.Result {
+	ctx = context.WithValue(ctx, stateKey{}, &requestState{
+		viewer:  viewer,
+		loaders: newLoaders(e.userRepo.WithContext(ctx), e.categoryRepo.WithContext(ctx)),
+	})
+
+	return graphql.Do(graphql.Params{
+		Schema:         e.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+}
+
+func (e *Executor) buildSchema() (graphql.Schema, error) {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"username": &graphql.Field{Type: graphql.String},
+			"name":     &graphql.Field{Type: graphql.String},
+			"role":     &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":        &graphql.Field{Type: graphql.String},
+			"slug":        &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"post_count":  &graphql.Field{Type: graphql.Int},
+			"created_at":  &graphql.Field{Type: graphql.DateTime},
+			"updated_at":  &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	postType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Post",
+		Fields: graphql.Fields{
+			"id":               &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":            &graphql.Field{Type: graphql.String},
+			"slug":             &graphql.Field{Type: graphql.String},
+			"content":          &graphql.Field{Type: graphql.String},
+			"excerpt":          &graphql.Field{Type: graphql.String},
+			"thumbnail_url":    &graphql.Field{Type: graphql.String},
+			"status":           &graphql.Field{Type: graphql.String},
+			"comments_enabled": &graphql.Field{Type: graphql.Boolean},
+			"word_count":       &graphql.Field{Type: graphql.Int},
+			"reading_time":     &graphql.Field{Type: graphql.Int},
+			"published_at":     &graphql.Field{Type: graphql.DateTime},
+			"created_at":       &graphql.Field{Type: graphql.DateTime},
+			"updated_at":       &graphql.Field{Type: graphql.DateTime},
+			"author": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					post := p.Source.(*models.Post)
+					users := stateFrom(p.Context).loaders.users
+					if post.Author != nil {
+						users.prime(post.AuthorID, post.Author)
+					}
+					return users.load(post.AuthorID), nil
+				},
+			},
+			"category": &graphql.Field{
+				Type: categoryType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					post := p.Source.(*models.Post)
+					categories := stateFrom(p.Context).loaders.categories
+					if post.Category != nil {
+						categories.prime(post.CategoryID, post.Category)
+					}
+					return categories.load(post.CategoryID), nil
+				},
+			},
+		},
+	})
+
+	commentType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Comment",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"post_id":    &graphql.Field{Type: graphql.Int},
+			"parent_id":  &graphql.Field{Type: graphql.Int},
+			"depth":      &graphql.Field{Type: graphql.Int},
+			"content":    &graphql.Field{Type: graphql.String},
+			"status":     &graphql.Field{Type: graphql.String},
+			"created_at": &graphql.Field{Type: graphql.DateTime},
+			"updated_at": &graphql.Field{Type: graphql.DateTime},
+			"author": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					comment := p.Source.(*models.Comment)
+					users := stateFrom(p.Context).loaders.users
+					if comment.User != nil {
+						users.prime(comment.UserID, comment.User)
+					}
+					return users.load(comment.UserID), nil
+				},
+			},
+		},
+	})
+
+	pageArgs := graphql.FieldConfigArgument{
+		"page":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+		"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit},
+	}
+	withPageArgs := func(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		for name, arg := range pageArgs {
+			args[name] = arg
+		}
+		return args
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"posts": &graphql.Field{
+				Type: graphql.NewList(postType),
+				Args: withPageArgs(graphql.FieldConfigArgument{
+					"q":           &graphql.ArgumentConfig{Type: graphql.String},
+					"category_id": &graphql.ArgumentConfig{Type: graphql.Int},
+					"author_id":   &graphql.ArgumentConfig{Type: graphql.Int},
+					"status":      &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: e.resolvePosts,
+			},
+			"post": &graphql.Field{
+				Type: postType,
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.Int},
+					"slug": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: e.resolvePost,
+			},
+			"categories": &graphql.Field{
+				Type:    graphql.NewList(categoryType),
+				Args:    withPageArgs(graphql.FieldConfigArgument{}),
+				Resolve: e.resolveCategories,
+			},
+			"comments": &graphql.Field{
+				Type: graphql.NewList(commentType),
+				Args: withPageArgs(graphql.FieldConfigArgument{
+					"post_id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				}),
+				Resolve: e.resolveComments,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func pagination(args map[string]interface{}) (page, limit int) {
+	page, _ = args["page"].(int)
+	if page < 1 {
+		page = 1
+	}
+	limit, _ = args["limit"].(int)
+	if limit < 1 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return page, limit
+}
+
+func (e *Executor) resolvePosts(p graphql.ResolveParams) (interface{}, error) {
+	viewer := stateFrom(p.Context).viewer
+	page, limit := pagination(p.Args)
+
+	req := &models.PostSearchRequest{Page: page, Limit: limit}
+	req.Query, _ = p.Args["q"].(string)
+	req.Status, _ = p.Args["status"].(string)
+	if id, ok := p.Args["category_id"].(int); ok && id > 0 {
+		req.CategoryID = uint(id)
+	}
+	if id, ok := p.Args["author_id"].(int); ok && id > 0 {
+		req.AuthorID = uint(id)
+	}
+
+	// Unpublished posts are only listed for editors and admins, or for
+	// authors listing their own posts
+	ownPosts := viewer.UserID != 0 && req.AuthorID == viewer.UserID
+	if !services.CanEditAnyPost(viewer.Role) && !ownPosts {
+		if req.Status != "" && req.Status != "published" {
+			return []*models.Post{}, nil
+		}
+		req.Status = "published"
+	}
+
+	posts, _, _, err := e.postService.WithContext(p.Context).Search(req, viewer.UserID, viewer.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Post, len(posts))
+	for i := range posts {
+		result[i] = &posts[i]
+	}
+	return result, nil
+}
+
+func (e *Executor) resolvePost(p graphql.ResolveParams) (interface{}, error) {
+	var (
+		post *models.Post
+		err  error
+	)
+
+	if id, ok := p.Args["id"].(int); ok {
+		post, err = e.postService.WithContext(p.Context).GetByID(uint(id))
+	} else if slug, ok := p.Args["slug"].(string); ok {
+		post, err = e.postService.WithContext(p.Context).GetBySlug(slug)
+	} else {
+		return nil, errors.New("either id or slug is required")
+	}
+	if err != nil {
+		// Unknown posts resolve to null, like any other missing field
+		return nil, nil
+	}
+
+	viewer := stateFrom(p.Context).viewer
+	if !services.CanViewPost(post, viewer.UserID, viewer.Role) {
+		return nil, nil
+	}
+	return post, nil
+}
+
+func (e *Executor) resolveCategories(p graphql.ResolveParams) (interface{}, error) {
+	page, limit := pagination(p.Args)
+
+	categories, _, err := e.categoryService.WithContext(p.Context).List(page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Category, len(categories))
+	for i := range categories {
+		result[i] = &categories[i]
+	}
+	return result, nil
+}
+
+func (e *Executor) resolveComments(p graphql.ResolveParams) (interface{}, error) {
+	postID, _ := p.Args["post_id"].(int)
+
+	// Comments are only as visible as the post they belong to
+	post, err := e.postService.WithContext(p.Context).GetByID(uint(postID))
+	if err != nil {
+		return []*models.Comment{}, nil
+	}
+	viewer := stateFrom(p.Context).viewer
+	if !services.CanViewPost(post, viewer.UserID, viewer.Role) {
+		return []*models.Comment{}, nil
+	}
+
+	page, limit := pagination(p.Args)
+	comments, _, err := e.commentService.WithContext(p.Context).GetByPost(post.ID, page, limit, viewer.UserID, viewer.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Comment, len(comments))
+	for i := range comments {
+		result[i] = &comments[i]
+	}
+	return result, nil
+}
@@ -0,0 +1,258 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePostService serves posts from memory without preloading associations,
+// so author and category must go through the loaders
+type fakePostService struct {
+	services.PostService
+	posts []models.Post
+}
+
+func (s *fakePostService) WithContext(ctx context.Context) services.PostService {
+	return s
+}
+
+func (s *fakePostService) Search(req *models.PostSearchRequest, viewerID uint, viewerRole string) ([]models.Post, int64, string, error) {
+	var matches []models.Post
+	for _, post := range s.posts {
+		if req.Status != "" && post.Status != req.Status {
+			continue
+		}
+		if req.AuthorID != 0 && post.AuthorID != req.AuthorID {
+			continue
+		}
+		matches = append(matches, post)
+	}
+	return matches, int64(len(matches)), models.SearchModeNone, nil
+}
+
+func (s *fakePostService) GetByID(id uint) (*models.Post, error) {
+	for _, post := range s.posts {
+		if post.ID == id {
+			return &post, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (s *fakePostService) GetBySlug(slug string) (*models.Post, error) {
+	for _, post := range s.posts {
+		if post.Slug == slug {
+			return &post, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+type fakeCategoryService struct {
+	services.CategoryService
+	categories []models.Category
+}
+
+func (s *fakeCategoryService) WithContext(ctx context.Context) services.CategoryService {
+	return s
+}
+
+func (s *fakeCategoryService) List(page, perPage int) ([]models.Category, int64, error) {
+	return s.categories, int64(len(s.categories)), nil
+}
+
+type fakeCommentService struct {
+	services.CommentService
+	comments []models.Comment
+}
+
+func (s *fakeCommentService) WithContext(ctx context.Context) services.CommentService {
+	return s
+}
+
+func (s *fakeCommentService) GetByPost(postID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Comment, int64, error) {
+	var comments []models.Comment
+	for _, comment := range s.comments {
+		if comment.PostID == postID {
+			comments = append(comments, comment)
+		}
+	}
+	return comments, int64(len(comments)), nil
+}
+
+// countingUserRepo records how many batch lookups were made
+type countingUserRepo struct {
+	repositories.UserRepository
+	users []models.User
+	calls int
+}
+
+func (r *countingUserRepo) WithContext(ctx context.Context) repositories.UserRepository {
+	return r
+}
+
+func (r *countingUserRepo) GetByIDs(ids []uint) (map[uint]*models.User, error) {
+	r.calls++
+	byID := make(map[uint]*models.User)
+	for i, user := range r.users {
+		for _, id := range ids {
+			if user.ID == id {
+				byID[id] = &r.users[i]
+				break
+			}
+		}
+	}
+	return byID, nil
+}
+
+type countingCategoryRepo struct {
+	repositories.CategoryRepository
+	categories []models.Category
+	calls      int
+}
+
+func (r *countingCategoryRepo) WithContext(ctx context.Context) repositories.CategoryRepository {
+	return r
+}
+
+func (r *countingCategoryRepo) GetByIDs(ids []uint) (map[uint]*models.Category, error) {
+	r.calls++
+	byID := make(map[uint]*models.Category)
+	for i, category := range r.categories {
+		for _, id := range ids {
+			if category.ID == id {
+				byID[id] = &r.categories[i]
+				break
+			}
+		}
+	}
+	return byID, nil
+}
+
+type testEnv struct {
+	executor     *Executor
+	userRepo     *countingUserRepo
+	categoryRepo *countingCategoryRepo
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	categories := []models.Category{
+		{ID: 1, Name: "Go", Slug: "go"},
+		{ID: 2, Name: "Vue", Slug: "vue"},
+	}
+	userRepo := &countingUserRepo{users: []models.User{
+		{ID: 1, Username: "alice", Email: "alice@example.com", Role: "author"},
+		{ID: 2, Username: "bob", Email: "bob@example.com", Role: "author"},
+	}}
+	categoryRepo := &countingCategoryRepo{categories: categories}
+
+	postService := &fakePostService{posts: []models.Post{
+		{ID: 1, Title: "Go tips", Slug: "go-tips", AuthorID: 1, CategoryID: 1, Status: "published"},
+		{ID: 2, Title: "Vue tips", Slug: "vue-tips", AuthorID: 2, CategoryID: 2, Status: "published"},
+		{ID: 3, Title: "More Go", Slug: "more-go", AuthorID: 1, CategoryID: 1, Status: "published"},
+		{ID: 4, Title: "Alice draft", Slug: "alice-draft", AuthorID: 1, CategoryID: 2, Status: "draft"},
+	}}
+	commentService := &fakeCommentService{comments: []models.Comment{
+		{ID: 1, PostID: 1, UserID: 2, Content: "Nice", Status: "approved"},
+		{ID: 2, PostID: 4, UserID: 2, Content: "Early look", Status: "approved"},
+	}}
+
+	executor, err := NewExecutor(postService, &fakeCategoryService{categories: categories}, commentService, userRepo, categoryRepo)
+	require.NoError(t, err)
+
+	return &testEnv{executor: executor, userRepo: userRepo, categoryRepo: categoryRepo}
+}
+
+func (env *testEnv) query(t *testing.T, query string, viewer Viewer) map[string]interface{} {
+	result := env.executor.Execute(context.Background(), Request{Query: query}, viewer)
+	require.Empty(t, result.Errors)
+
+	// Round-trip through JSON to compare against what clients receive
+	body, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &data))
+	return data
+}
+
+func TestExecutor_NestedQuery(t *testing.T) {
+	env := newTestEnv(t)
+
+	data := env.query(t, `{ posts { title author { username } category { name } } }`, Viewer{})
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"title": "Go tips", "author": map[string]interface{}{"username": "alice"}, "category": map[string]interface{}{"name": "Go"}},
+		map[string]interface{}{"title": "Vue tips", "author": map[string]interface{}{"username": "bob"}, "category": map[string]interface{}{"name": "Vue"}},
+		map[string]interface{}{"title": "More Go", "author": map[string]interface{}{"username": "alice"}, "category": map[string]interface{}{"name": "Go"}},
+	}, data["posts"])
+
+	// Every author and category is fetched in a single batch
+	assert.Equal(t, 1, env.userRepo.calls)
+	assert.Equal(t, 1, env.categoryRepo.calls)
+}
+
+func TestExecutor_FieldSelection(t *testing.T) {
+	env := newTestEnv(t)
+
+	data := env.query(t, `{ post(slug: "go-tips") { id slug } }`, Viewer{})
+
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "slug": "go-tips"}, data["post"])
+	assert.Zero(t, env.userRepo.calls, "unselected associations are not loaded")
+	assert.Zero(t, env.categoryRepo.calls, "unselected associations are not loaded")
+}
+
+func TestExecutor_Visibility(t *testing.T) {
+	env := newTestEnv(t)
+
+	t.Run("anonymous callers cannot read drafts", func(t *testing.T) {
+		data := env.query(t, `{ post(id: 4) { title } }`, Viewer{})
+		assert.Nil(t, data["post"])
+	})
+
+	t.Run("authors can read their own drafts", func(t *testing.T) {
+		data := env.query(t, `{ post(id: 4) { title } }`, Viewer{UserID: 1, Role: "author"})
+		assert.Equal(t, map[string]interface{}{"title": "Alice draft"}, data["post"])
+	})
+
+	t.Run("other authors cannot read someone else's draft", func(t *testing.T) {
+		data := env.query(t, `{ post(id: 4) { title } }`, Viewer{UserID: 2, Role: "author"})
+		assert.Nil(t, data["post"])
+	})
+
+	t.Run("lists only include drafts for admins", func(t *testing.T) {
+		data := env.query(t, `{ posts(status: "draft") { title } }`, Viewer{UserID: 2, Role: "author"})
+		assert.Empty(t, data["posts"])
+
+		data = env.query(t, `{ posts(status: "draft") { title } }`, Viewer{UserID: 99, Role: "admin"})
+		assert.Len(t, data["posts"], 1)
+	})
+
+	t.Run("comments on hidden posts are hidden", func(t *testing.T) {
+		data := env.query(t, `{ comments(post_id: 4) { content } }`, Viewer{})
+		assert.Empty(t, data["comments"])
+
+		data = env.query(t, `{ comments(post_id: 1) { content author { username } } }`, Viewer{})
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"content": "Nice", "author": map[string]interface{}{"username": "bob"}},
+		}, data["comments"])
+	})
+}
+
+func TestExecutor_IsReadOnly(t *testing.T) {
+	env := newTestEnv(t)
+
+	result := env.executor.Execute(context.Background(), Request{
+		Query: `mutation { deletePost(id: 1) }`,
+	}, Viewer{Role: "admin"})
+
+	assert.NotEmpty(t, result.Errors)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogHandler struct {
+	auditService services.AuditLogService
+}
+
+func NewAuditLogHandler(auditService services.AuditLogService) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditService: auditService,
+	}
+}
+
+// List returns a page of the audit log, newest first. ?cursor= takes the
+// next_cursor of the previous page and ?limit= sets the page size.
+func (h *AuditLogHandler) List(c *gin.Context) {
+	filter, err := auditFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid filter", err.Error()))
+		return
+	}
+	limit := 0
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid limit", "limit must be a positive integer"))
+			return
+		}
+	}
+
+	page, err := h.auditService.WithContext(c.Request.Context()).List(filter, c.Query("cursor"), limit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidAuditCursor) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to retrieve audit log", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Audit log retrieved successfully", page))
+}
+
+// auditCSVHeader names the columns of the CSV export
+var auditCSVHeader = []string{"id", "created_at", "actor_id", "action", "target_type", "target_id", "details"}
+
+// Export streams every entry matching the filter as a download, CSV by
+// default or a JSON array with ?format=json. Entries are written as they are
+// loaded, so a failure part way through cuts the file short.
+func (h *AuditLogHandler) Export(c *gin.Context) {
+	filter, err := auditFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid filter", err.Error()))
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid format", "format must be csv or json"))
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == "json" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-log.%s"`, format))
+	c.Status(http.StatusOK)
+
+	service := h.auditService.WithContext(c.Request.Context())
+	if format == "json" {
+		err = exportAuditJSON(c, service, filter)
+	} else {
+		err = exportAuditCSV(c, service, filter)
+	}
+	if err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func exportAuditCSV(c *gin.Context, service services.AuditLogService, filter models.AuditLogFilter) error {
+	writer := csv.NewWriter(c.Writer)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	err := service.Export(filter, func(entry models.AuditLog) error {
+		details, err := json.Marshal(entry.Details)
+		if err != nil {
+			return err
+		}
+		if err := writer.Write([]string{
+			strconv.FormatUint(uint64(entry.ID), 10),
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatUint(uint64(entry.ActorID), 10),
+			entry.Action,
+			entry.TargetType,
+			strconv.FormatUint(uint64(entry.TargetID), 10),
+			string(details),
+		}); err != nil {
+			return err
+		}
+		return flushCSV(c, writer)
+	})
+	if err != nil {
+		return err
+	}
+	return flushCSV(c, writer)
+}
+
+// flushCSV hands buffered rows to the client as they are written
+func flushCSV(c *gin.Context, writer *csv.Writer) error {
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+func exportAuditJSON(c *gin.Context, service services.AuditLogService, filter models.AuditLogFilter) error {
+	if _, err := c.Writer.WriteString("["); err != nil {
+		return err
+	}
+	first := true
+	err := service.Export(filter, func(entry models.AuditLog) error {
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err := c.Writer.WriteString(","); err != nil {
+				return err
+			}
+		}
+		first = false
+		if _, err := c.Writer.Write(encoded); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.Writer.WriteString("]")
+	return err
+}
+
+// auditFilterFromQuery reads ?actor_id=, ?action=, ?target_type= and the
+// ?from= and ?to= dates, given as RFC 3339 times or YYYY-MM-DD days. A to day
+// includes the whole of that day.
+func auditFilterFromQuery(c *gin.Context) (models.AuditLogFilter, error) {
+	filter := models.AuditLogFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+	if actorParam := c.Query("actor_id"); actorParam != "" {
+		actorID, err := strconv.ParseUint(actorParam, 10, 32)
+		if err != nil {
+			return filter, errors.New("actor_id must be a user ID")
+		}
+		filter.ActorID = uint(actorID)
+	}
+
+	var err error
+	if filter.From, err = parseAuditTime(c.Query("from"), false); err != nil {
+		return filter, fmt.Errorf("from %w", err)
+	}
+	if filter.To, err = parseAuditTime(c.Query("to"), true); err != nil {
+		return filter, fmt.Errorf("to %w", err)
+	}
+	return filter, nil
+}
+
+// parseAuditTime parses an RFC 3339 time or a YYYY-MM-DD day, which stands for
+// its start, or the start of the next day when it is an upper bound. An empty
+// value is no bound.
+func parseAuditTime(value string, upper bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, errors.New("must be an RFC 3339 time or a YYYY-MM-DD date")
+	}
+	if upper {
+		day = day.AddDate(0, 0, 1)
+	}
+	return &day, nil
+}
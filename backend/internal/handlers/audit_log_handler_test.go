@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newAuditRouter serves the audit log from an in-memory database holding a
+// transfer by admin 1 on 2026-03-01, one by admin 2 on 2026-03-02 and a bulk
+// transfer by admin 1 on 2026-03-03
+func newAuditRouter(t *testing.T) *gin.Engine {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+	repo := repositories.NewAuditLogRepository(db)
+	for _, entry := range []*models.AuditLog{
+		{ActorID: 1, Action: models.AuditPostTransfer, TargetType: "post", TargetID: 10, Details: map[string]interface{}{"to_author_id": 4}, CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)},
+		{ActorID: 2, Action: models.AuditPostTransfer, TargetType: "post", TargetID: 11, CreatedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)},
+		{ActorID: 1, Action: models.AuditPostsTransfer, TargetType: "user", TargetID: 3, CreatedAt: time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)},
+	} {
+		require.NoError(t, repo.Create(entry))
+	}
+
+	handler := NewAuditLogHandler(services.NewAuditLogService(repo, &config.Config{}))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/audit", handler.List)
+	router.GET("/audit/export", handler.Export)
+	return router
+}
+
+func getAudit(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestAuditLogHandler_List(t *testing.T) {
+	router := newAuditRouter(t)
+
+	t.Run("pages through the filtered entries", func(t *testing.T) {
+		w := getAudit(router, "/audit?actor_id=1&limit=1")
+		require.Equal(t, http.StatusOK, w.Code)
+		var first struct {
+			Data models.AuditLogPage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+		require.Len(t, first.Data.Entries, 1)
+		assert.Equal(t, uint(3), first.Data.Entries[0].TargetID)
+		require.NotEmpty(t, first.Data.NextCursor)
+
+		w = getAudit(router, "/audit?actor_id=1&limit=1&cursor="+first.Data.NextCursor)
+		require.Equal(t, http.StatusOK, w.Code)
+		var second struct {
+			Data models.AuditLogPage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+		require.Len(t, second.Data.Entries, 1)
+		assert.Equal(t, uint(10), second.Data.Entries[0].TargetID)
+		assert.Empty(t, second.Data.NextCursor)
+	})
+
+	t.Run("rejects bad parameters", func(t *testing.T) {
+		for _, query := range []string{"actor_id=me", "from=yesterday", "limit=0", "cursor=abc"} {
+			assert.Equal(t, http.StatusBadRequest, getAudit(router, "/audit?"+query).Code, query)
+		}
+	})
+}
+
+func TestAuditLogHandler_Export(t *testing.T) {
+	router := newAuditRouter(t)
+
+	t.Run("CSV holds exactly the filtered entries", func(t *testing.T) {
+		w := getAudit(router, "/audit/export?action=post.transfer&from=2026-03-01&to=2026-03-01")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "audit-log.csv")
+
+		rows, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			auditCSVHeader,
+			{"1", "2026-03-01T09:00:00Z", "1", "post.transfer", "post", "10", `{"to_author_id":4}`},
+		}, rows)
+	})
+
+	t.Run("JSON is an array of the filtered entries", func(t *testing.T) {
+		w := getAudit(router, "/audit/export?format=json&target_type=post")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+		var entries []models.AuditLog
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+		require.Len(t, entries, 2)
+		assert.Equal(t, uint(11), entries[0].TargetID)
+		assert.Equal(t, uint(10), entries[1].TargetID)
+	})
+
+	t.Run("an empty result is still a valid file", func(t *testing.T) {
+		w := getAudit(router, "/audit/export?format=json&actor_id=99")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "[]", w.Body.String())
+	})
+
+	t.Run("rejects an unknown format", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, getAudit(router, "/audit/export?format=xml").Code)
+	})
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"backend/internal/middleware"
 	"backend/internal/models"
 	"backend/internal/services"
+	"backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -45,7 +47,19 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	// ClientIP only trusts forwarding headers from the configured proxies
+	req.ClientIP = c.ClientIP()
+
 	user, err := h.authService.Register(&req)
+	if errors.Is(err, services.ErrRegistrationLimitReached) {
+		c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "ERR_REGISTRATION_LIMIT",
+			Details: "Too many accounts have been registered from this address. Please try again later.",
+		})
+		return
+	}
 	if err != nil {
 		var errorCode string
 		switch err.Error() {
@@ -53,6 +67,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			errorCode = "ERR_USERNAME_EXISTS"
 		case "email already exists":
 			errorCode = "ERR_EMAIL_EXISTS"
+		case "role cannot be self-assigned":
+			errorCode = "ERR_ROLE_NOT_ALLOWED"
 		default:
 			errorCode = "ERR_REGISTRATION_FAILED"
 		}
@@ -98,6 +114,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	authResponse, err := h.authService.Login(&req)
+	if errors.Is(err, services.ErrAccountLocked) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "ERR_ACCOUNT_LOCKED",
+			Details: "Too many failed login attempts. Please try again later.",
+		})
+		return
+	}
 	if err != nil {
 		var errorCode string
 		if err.Error() == "invalid email or password" {
@@ -180,7 +205,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	}
 	c.ShouldBindJSON(&req)
 
-	err := h.authService.Logout(userID.(uint), req.RefreshToken)
+	err := h.authService.Logout(userID.(uint), req.RefreshToken, accessClaims(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -208,7 +233,7 @@ func (h *AuthHandler) LogoutAll(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.LogoutAll(userID.(uint))
+	err := h.authService.LogoutAll(userID.(uint), accessClaims(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -253,6 +278,42 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	})
 }
 
+// Me returns the current user's profile along with the permissions their
+// role grants
+func (h *AuthHandler) Me(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Error:   "Authentication required",
+			Code:    "ERR_AUTH_REQUIRED",
+		})
+		return
+	}
+
+	profile, err := h.authService.GetProfile(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "ERR_PROFILE_FETCH_FAILED",
+		})
+		return
+	}
+
+	// Route guards authorize by the role in the token, so report that one
+	role := c.GetString("user_role")
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Current user retrieved successfully",
+		Data: models.MeResponse{
+			User:        *profile,
+			Permissions: middleware.PermissionsForRole(role),
+		},
+	})
+}
+
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -267,7 +328,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	var req models.UpdateProfileRequest
 	
 	// Bind and validate JSON
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := utils.BindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success: false,
 			Error:   "Invalid request data",
@@ -372,3 +433,131 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		Message: "Password changed successfully",
 	})
 }
+
+// ForgotPassword starts a password reset. The response is the same whether
+// or not the email belongs to an account. The token is not returned; sending
+// it to the user is left to an email integration.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+
+	// Bind and validate JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request data",
+			Code:    "ERR_VALIDATION_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// Additional validation using custom validator
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, models.ValidationErrorResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Code:    "ERR_VALIDATION_FAILED",
+			Details: validationErrors,
+		})
+		return
+	}
+
+	if _, err := h.authService.RequestPasswordReset(req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "ERR_PASSWORD_RESET_FAILED",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "If an account with that email exists, a password reset link has been sent",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+
+	// Bind and validate JSON
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request data",
+			Code:    "ERR_VALIDATION_FAILED",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// Additional validation using custom validator
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, models.ValidationErrorResponse{
+			Success: false,
+			Error:   "Validation failed",
+			Code:    "ERR_VALIDATION_FAILED",
+			Details: validationErrors,
+		})
+		return
+	}
+
+	err := h.authService.ResetPassword(req.Token, req.NewPassword)
+	if errors.Is(err, services.ErrInvalidResetToken) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "ERR_INVALID_RESET_TOKEN",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "ERR_PASSWORD_RESET_FAILED",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Password reset successfully",
+	})
+}
+
+// VerifyEmail confirms a user's email address with the token from the link
+// they were sent, given as the token query parameter
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	user, err := h.authService.VerifyEmail(c.Query("token"))
+	if errors.Is(err, services.ErrInvalidVerificationToken) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "ERR_INVALID_VERIFICATION_TOKEN",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "ERR_EMAIL_VERIFICATION_FAILED",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Email verified successfully",
+		Data:    user,
+	})
+}
+
+// accessClaims returns the claims of the access token AuthMiddleware
+// accepted for this request
+func accessClaims(c *gin.Context) *models.JWTClaims {
+	claims, _ := c.Get("jwt_claims")
+	jwtClaims, _ := claims.(*models.JWTClaims)
+	return jwtClaims
+}
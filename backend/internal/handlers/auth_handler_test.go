@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthService serves profiles from an in-memory map
+type fakeAuthService struct {
+	services.AuthService
+	users map[uint]*models.User
+}
+
+func (s *fakeAuthService) GetProfile(userID uint) (*models.User, error) {
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, assert.AnError
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func newMeRouter(user *models.User) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakeAuthService{users: map[uint]*models.User{user.ID: user}}
+	router := gin.New()
+	router.GET("/auth/me", func(c *gin.Context) {
+		// Stand in for AuthMiddleware
+		c.Set("user_id", user.ID)
+		c.Set("user_role", user.Role)
+	}, NewAuthHandler(service).Me)
+	return router
+}
+
+func TestAuthHandler_Me(t *testing.T) {
+	tests := []struct {
+		role        string
+		permissions models.Permissions
+	}{
+		{
+			role:        "author",
+			permissions: models.Permissions{CanPublish: true},
+		},
+		{
+			role:        "editor",
+			permissions: models.Permissions{CanPublish: true},
+		},
+		{
+			role: "admin",
+			permissions: models.Permissions{
+				CanPublish:          true,
+				CanModerate:         true,
+				CanManageUsers:      true,
+				CanManageCategories: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			router := newMeRouter(&models.User{ID: 7, Username: "someone", Email: "someone@example.com", Role: tt.role})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/me", nil))
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var response struct {
+				Success bool              `json:"success"`
+				Data    models.MeResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			assert.True(t, response.Success)
+			assert.Equal(t, uint(7), response.Data.User.ID)
+			assert.Equal(t, tt.role, response.Data.User.Role)
+			assert.Equal(t, tt.permissions, response.Data.Permissions)
+		})
+	}
+}
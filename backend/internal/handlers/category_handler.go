@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -23,20 +25,51 @@ func NewCategoryHandler(categoryService services.CategoryService) *CategoryHandl
 
 func (h *CategoryHandler) Create(c *gin.Context) {
 	var req models.CreateCategoryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := utils.BindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
 		return
 	}
 
-	category, err := h.categoryService.Create(&req)
+	category, err := h.categoryService.WithContext(c.Request.Context()).Create(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to create category", err.Error()))
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrCategorySlugConflict) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to create category", err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusCreated, utils.SuccessResponse("Category created successfully", category))
 }
 
+// maxBulkCategories caps how many categories one bulk create may carry
+const maxBulkCategories = 100
+
+// CreateBulk creates an array of categories at once, e.g. when setting up a
+// new blog. Items that fail, such as duplicates, are reported in the results
+// alongside the ones created.
+func (h *CategoryHandler) CreateBulk(c *gin.Context) {
+	var items []models.BulkCategoryItem
+	if err := utils.BindJSON(c, &items); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+	if len(items) == 0 || len(items) > maxBulkCategories {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data",
+			fmt.Sprintf("between 1 and %d categories can be created at once", maxBulkCategories)))
+		return
+	}
+
+	response, err := h.categoryService.WithContext(c.Request.Context()).CreateBulk(items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to create categories", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Bulk category create finished", response))
+}
+
 func (h *CategoryHandler) GetByID(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
@@ -45,7 +78,7 @@ func (h *CategoryHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	category, err := h.categoryService.GetByID(uint(id))
+	category, err := h.categoryService.WithContext(c.Request.Context()).GetByID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse("Category not found", err.Error()))
 		return
@@ -57,7 +90,7 @@ func (h *CategoryHandler) GetByID(c *gin.Context) {
 func (h *CategoryHandler) GetBySlug(c *gin.Context) {
 	slug := c.Param("slug")
 
-	category, err := h.categoryService.GetBySlug(slug)
+	category, err := h.categoryService.WithContext(c.Request.Context()).GetBySlug(slug)
 	if err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse("Category not found", err.Error()))
 		return
@@ -75,12 +108,12 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 	}
 
 	var req models.UpdateCategoryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := utils.BindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
 		return
 	}
 
-	category, err := h.categoryService.Update(uint(id), &req)
+	category, err := h.categoryService.WithContext(c.Request.Context()).Update(uint(id), &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to update category", err.Error()))
 		return
@@ -97,14 +130,32 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.categoryService.Delete(uint(id)); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to delete category", err.Error()))
+	// ?reparent=true moves subcategories up to the deleted category's parent;
+	// otherwise a category with subcategories is not deleted
+	reparent, _ := strconv.ParseBool(c.Query("reparent"))
+	if err := h.categoryService.WithContext(c.Request.Context()).Delete(uint(id), reparent); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrCategoryHasChildren) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to delete category", err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, utils.SuccessResponse("Category deleted successfully", nil))
 }
 
+// GetTree returns every category with its subcategories nested beneath it
+func (h *CategoryHandler) GetTree(c *gin.Context) {
+	tree, err := h.categoryService.WithContext(c.Request.Context()).GetTree()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve categories", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Category tree retrieved successfully", tree))
+}
+
 func (h *CategoryHandler) List(c *gin.Context) {
 	page, perPage := utils.GetPaginationParams(c)
 	
@@ -115,12 +166,21 @@ func (h *CategoryHandler) List(c *gin.Context) {
 		Query: c.Query("q"),
 	}
 
-	categories, total, err := h.categoryService.Search(searchReq)
+	// Both paths count each category's published posts into post_count
+	service := h.categoryService.WithContext(c.Request.Context())
+	var categories []models.Category
+	var total int64
+	var err error
+	if searchReq.Query == "" && searchReq.Sort == "" {
+		categories, total, err = service.ListWithCounts(page, perPage)
+	} else {
+		categories, total, err = service.Search(searchReq)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve categories", err.Error()))
 		return
 	}
 
 	response := utils.PaginatedAPIResponse(categories, total, page, perPage, "Categories retrieved successfully")
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -23,22 +24,52 @@ func NewCommentHandler(commentService services.CommentService) *CommentHandler {
 
 func (h *CommentHandler) Create(c *gin.Context) {
 	var req models.CreateCommentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := utils.BindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
 		return
 	}
 
 	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
 
-	comment, err := h.commentService.Create(&req, userID.(uint))
+	comment, err := h.commentService.WithContext(c.Request.Context()).Create(&req, userID.(uint), userRole.(string))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to create comment", err.Error()))
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrCommentsClosed) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to create comment", err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusCreated, utils.SuccessResponse("Comment created successfully", comment))
 }
 
+// Preview renders comment markdown the way it will be shown once posted,
+// without storing anything
+func (h *CommentHandler) Preview(c *gin.Context) {
+	var req models.CommentPreviewRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userRole, _ := c.Get("user_role")
+	html, err := h.commentService.WithContext(c.Request.Context()).Preview(req.Content, userRole.(string))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrCommentTooLong) || errors.Is(err, services.ErrCommentTooManyLinks) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to preview comment", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Comment preview rendered successfully", models.CommentPreviewResponse{HTML: html}))
+}
+
+// GetByID returns a single comment. Comments awaiting moderation or rejected
+// are only shown to their author and to admins; anyone else gets a 404.
 func (h *CommentHandler) GetByID(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
@@ -47,12 +78,18 @@ func (h *CommentHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.commentService.GetByID(uint(id))
+	comment, err := h.commentService.WithContext(c.Request.Context()).GetByID(uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse("Comment not found", err.Error()))
 		return
 	}
 
+	viewerID, viewerRole := viewerFromContext(c)
+	if comment.Status != "approved" && viewerRole != "admin" && (viewerID == 0 || viewerID != comment.UserID) {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("Comment not found", services.ErrCommentNotFound.Error()))
+		return
+	}
+
 	c.JSON(http.StatusOK, utils.SuccessResponse("Comment retrieved successfully", comment))
 }
 
@@ -65,7 +102,7 @@ func (h *CommentHandler) Update(c *gin.Context) {
 	}
 
 	var req models.UpdateCommentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := utils.BindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
 		return
 	}
@@ -73,7 +110,7 @@ func (h *CommentHandler) Update(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userRole, _ := c.Get("user_role")
 
-	comment, err := h.commentService.Update(uint(id), &req, userID.(uint), userRole.(string))
+	comment, err := h.commentService.WithContext(c.Request.Context()).Update(uint(id), &req, userID.(uint), userRole.(string))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to update comment", err.Error()))
 		return
@@ -93,7 +130,7 @@ func (h *CommentHandler) Delete(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userRole, _ := c.Get("user_role")
 
-	if err := h.commentService.Delete(uint(id), userID.(uint), userRole.(string)); err != nil {
+	if err := h.commentService.WithContext(c.Request.Context()).Delete(uint(id), userID.(uint), userRole.(string)); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to delete comment", err.Error()))
 		return
 	}
@@ -101,12 +138,131 @@ func (h *CommentHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.SuccessResponse("Comment deleted successfully", nil))
 }
 
+func (h *CommentHandler) Pin(c *gin.Context) {
+	h.setPinned(c, true)
+}
+
+func (h *CommentHandler) Unpin(c *gin.Context) {
+	h.setPinned(c, false)
+}
+
+func (h *CommentHandler) setPinned(c *gin.Context, pinned bool) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid comment ID", err.Error()))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	userRole, _ := c.Get("user_role")
+
+	action, message := h.commentService.WithContext(c.Request.Context()).Pin, "Comment pinned successfully"
+	if !pinned {
+		action, message = h.commentService.WithContext(c.Request.Context()).Unpin, "Comment unpinned successfully"
+	}
+
+	comment, err := action(uint(id), userID.(uint), userRole.(string))
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrCommentNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrCommentPinForbidden):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to update comment pin", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(message, comment))
+}
+
+// ListPending returns the moderation queue, oldest comment first
+func (h *CommentHandler) ListPending(c *gin.Context) {
+	page, perPage := utils.GetPaginationParams(c)
+
+	comments, total, err := h.commentService.WithContext(c.Request.Context()).ListPending(page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve pending comments", err.Error()))
+		return
+	}
+
+	response := utils.PaginatedAPIResponse(comments, total, page, perPage, "Pending comments retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
+}
+
+func (h *CommentHandler) Approve(c *gin.Context) {
+	h.moderate(c, true)
+}
+
+func (h *CommentHandler) Reject(c *gin.Context) {
+	h.moderate(c, false)
+}
+
+func (h *CommentHandler) moderate(c *gin.Context, approve bool) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid comment ID", err.Error()))
+		return
+	}
+
+	service := h.commentService.WithContext(c.Request.Context())
+	action, message := service.Approve, "Comment approved successfully"
+	if !approve {
+		action, message = service.Reject, "Comment rejected successfully"
+	}
+
+	comment, err := action(uint(id), c.GetUint("user_id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrCommentNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to moderate comment", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(message, comment))
+}
+
+// commentStatuses are the values admins may pass as ?status=; "all" lists
+// comments in every status
+var commentStatuses = map[string]bool{"pending": true, "approved": true, "rejected": true, "all": true}
+
+// commentStatusFilter picks the moderation status a comment listing shows.
+// Readers, signed in or not, only ever get approved comments. Admins get the
+// same unless they ask for another status with ?status=, or for every status
+// with ?status=all, which comes back as "". ok is false for an unknown status.
+func commentStatusFilter(c *gin.Context) (status string, ok bool) {
+	status = c.Query("status")
+	if status == "" || c.GetString("user_role") != "admin" {
+		return "approved", true
+	}
+	if !commentStatuses[status] {
+		return "", false
+	}
+	if status == "all" {
+		return "", true
+	}
+	return status, true
+}
+
+// List returns a page of comments across posts, approved ones only unless an
+// admin asks otherwise with ?status=
 func (h *CommentHandler) List(c *gin.Context) {
 	page, perPage := utils.GetPaginationParams(c)
 
+	status, ok := commentStatusFilter(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid status", "status must be pending, approved, rejected or all"))
+		return
+	}
+
 	// Build filters
 	filters := make(map[string]interface{})
-	if status := c.Query("status"); status != "" {
+	if status != "" {
 		filters["status"] = status
 	}
 	if postID := c.Query("post_id"); postID != "" {
@@ -115,16 +271,63 @@ func (h *CommentHandler) List(c *gin.Context) {
 		}
 	}
 
-	comments, total, err := h.commentService.List(page, perPage, filters)
+	viewerID, viewerRole := viewerFromContext(c)
+	comments, total, err := h.commentService.WithContext(c.Request.Context()).List(page, perPage, filters, viewerID, viewerRole)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve comments", err.Error()))
+		respondCommentListError(c, err)
+		return
+	}
+
+	response := utils.PaginatedAPIResponse(comments, total, page, perPage, "Comments retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
+}
+
+// GetRecent returns the latest approved comments across the site, e.g. for a
+// sidebar widget. ?limit= defaults to services.DefaultRecentComments.
+func (h *CommentHandler) GetRecent(c *gin.Context) {
+	limit := 0
+	if limitParam := c.Query("limit"); limitParam != "" {
+		value, err := strconv.Atoi(limitParam)
+		if err != nil || value < 1 {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid limit", "limit must be a positive integer"))
+			return
+		}
+		limit = value
+	}
+
+	comments, err := h.commentService.WithContext(c.Request.Context()).GetRecent(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve recent comments", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Recent comments retrieved successfully", comments))
+}
+
+// GetSummaries returns, for each post in the request body's post_ids that
+// the caller may see, its approved comment count and newest approved
+// comment, e.g. for post listings
+func (h *CommentHandler) GetSummaries(c *gin.Context) {
+	var req models.CommentSummaryRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	viewerID, viewerRole := viewerFromContext(c)
+	summaries, err := h.commentService.WithContext(c.Request.Context()).GetSummaries(req.PostIDs, viewerID, viewerRole)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to retrieve comment summaries", err.Error()))
 		return
 	}
 
-	response := utils.PaginationResponse(comments, total, page, perPage)
-	c.JSON(http.StatusOK, utils.SuccessResponse("Comments retrieved successfully", response))
+	c.JSON(http.StatusOK, utils.SuccessResponse("Comment summaries retrieved successfully", summaries))
 }
 
+// GetByPost returns a page of a post's approved comments as a flat list;
+// admins may ask for other statuses with ?status=. With ?threaded=true it
+// returns the whole reply tree instead, like GetThread, under the same
+// status filter.
 func (h *CommentHandler) GetByPost(c *gin.Context) {
 	postIDParam := c.Param("post_id")
 	postID, err := strconv.ParseUint(postIDParam, 10, 32)
@@ -133,18 +336,43 @@ func (h *CommentHandler) GetByPost(c *gin.Context) {
 		return
 	}
 
+	status, ok := commentStatusFilter(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid status", "status must be pending, approved, rejected or all"))
+		return
+	}
+
+	if c.Query("threaded") == "true" {
+		thread, ok := h.thread(c, uint(postID), status)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, utils.SuccessResponse("Comments retrieved successfully", thread))
+		return
+	}
+
 	page, perPage := utils.GetPaginationParams(c)
 
-	comments, total, err := h.commentService.GetByPost(uint(postID), page, perPage)
+	viewerID, viewerRole := viewerFromContext(c)
+	service := h.commentService.WithContext(c.Request.Context())
+	var comments []models.Comment
+	var total int64
+	if status == "approved" {
+		comments, total, err = service.GetByPost(uint(postID), page, perPage, viewerID, viewerRole)
+	} else {
+		comments, total, err = service.GetByPostWithStatus(uint(postID), status, page, perPage, viewerID, viewerRole)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve comments", err.Error()))
+		respondCommentListError(c, err)
 		return
 	}
 
-	response := utils.PaginationResponse(comments, total, page, perPage)
-	c.JSON(http.StatusOK, utils.SuccessResponse("Comments retrieved successfully", response))
+	response := utils.PaginatedAPIResponse(comments, total, page, perPage, "Comments retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
 }
 
+// GetByUser returns a page of a user's approved comments; admins may ask for
+// other statuses with ?status=
 func (h *CommentHandler) GetByUser(c *gin.Context) {
 	userIDParam := c.Param("user_id")
 	userID, err := strconv.ParseUint(userIDParam, 10, 32)
@@ -153,14 +381,107 @@ func (h *CommentHandler) GetByUser(c *gin.Context) {
 		return
 	}
 
+	status, ok := commentStatusFilter(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid status", "status must be pending, approved, rejected or all"))
+		return
+	}
+
 	page, perPage := utils.GetPaginationParams(c)
 
-	comments, total, err := h.commentService.GetByUser(uint(userID), page, perPage)
+	comments, total, err := h.commentService.WithContext(c.Request.Context()).GetByUser(uint(userID), status, page, perPage)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve comments", err.Error()))
 		return
 	}
 
-	response := utils.PaginationResponse(comments, total, page, perPage)
-	c.JSON(http.StatusOK, utils.SuccessResponse("Comments retrieved successfully", response))
+	response := utils.PaginatedAPIResponse(comments, total, page, perPage, "Comments retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
+}
+
+// GetThread returns a post's approved comments as a reply tree; admins may
+// ask for other statuses with ?status=. With ?flatten=true the thread is
+// returned as a flat list in display order, each comment carrying its
+// parent_id, for clients that build their own tree.
+func (h *CommentHandler) GetThread(c *gin.Context) {
+	postIDParam := c.Param("post_id")
+	postID, err := strconv.ParseUint(postIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid post ID", err.Error()))
+		return
+	}
+
+	status, ok := commentStatusFilter(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid status", "status must be pending, approved, rejected or all"))
+		return
+	}
+
+	thread, ok := h.thread(c, uint(postID), status)
+	if !ok {
+		return
+	}
+
+	if c.Query("flatten") == "true" {
+		c.JSON(http.StatusOK, utils.SuccessResponse("Comments retrieved successfully", models.FlattenCommentTree(thread)))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Comments retrieved successfully", thread))
+}
+
+// thread loads a post's reply tree for the viewer, writing the error
+// response itself when that fails
+func (h *CommentHandler) thread(c *gin.Context, postID uint, status string) ([]*models.CommentNode, bool) {
+	viewerID, viewerRole := viewerFromContext(c)
+	thread, err := h.commentService.WithContext(c.Request.Context()).GetThread(postID, status, viewerID, viewerRole)
+	if err != nil {
+		respondCommentListError(c, err)
+		return nil, false
+	}
+	return thread, true
+}
+
+// respondCommentListError answers a failed comment listing, hiding posts the
+// viewer may not see behind a 404
+func respondCommentListError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrCommentPostNotFound) {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("Post not found", err.Error()))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve comments", err.Error()))
+}
+
+// GetCommentTree returns a post's full comment tree with reply counts.
+// ?max_depth limits how many reply levels are nested below the top-level
+// comments.
+func (h *CommentHandler) GetCommentTree(c *gin.Context) {
+	idParam := c.Param("id")
+	postID, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid post ID", err.Error()))
+		return
+	}
+
+	maxDepth := services.DefaultCommentTreeDepth
+	if maxDepthParam := c.Query("max_depth"); maxDepthParam != "" {
+		maxDepth, err = strconv.Atoi(maxDepthParam)
+		if err != nil || maxDepth < 0 {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid max_depth", "max_depth must be a non-negative integer"))
+			return
+		}
+	}
+
+	viewerID, viewerRole := viewerFromContext(c)
+	tree, err := h.commentService.WithContext(c.Request.Context()).GetCommentTree(uint(postID), maxDepth, viewerID, viewerRole)
+	if err != nil {
+		if errors.Is(err, services.ErrCommentPostNotFound) {
+			c.JSON(http.StatusNotFound, utils.ErrorResponse("Post not found", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve comments", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Comments retrieved successfully", tree))
 }
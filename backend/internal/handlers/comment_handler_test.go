@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCommentService serves one post's comments: an approved top-level
+// comment with a single approved reply, and a second top-level comment still
+// awaiting moderation
+type fakeCommentService struct {
+	services.CommentService
+}
+
+func (s fakeCommentService) WithContext(ctx context.Context) services.CommentService {
+	return s
+}
+
+var topLevelCommentID uint = 1
+
+// pendingCommentAuthorID wrote the fake's pending comment
+const pendingCommentAuthorID uint = 7
+
+func (fakeCommentService) GetByID(id uint) (*models.Comment, error) {
+	if id == 3 {
+		return &models.Comment{ID: 3, PostID: 5, UserID: pendingCommentAuthorID, Status: "pending"}, nil
+	}
+	return &models.Comment{ID: id, PostID: 5, Status: "approved"}, nil
+}
+
+func (fakeCommentService) GetByPost(postID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Comment, int64, error) {
+	return []models.Comment{
+		{ID: 1, PostID: postID, Status: "approved"},
+		{ID: 2, PostID: postID, ParentID: &topLevelCommentID, Depth: 1, Status: "approved"},
+	}, 2, nil
+}
+
+func (fakeCommentService) GetThread(postID uint, status string, viewerID uint, viewerRole string) ([]*models.CommentNode, error) {
+	reply := &models.CommentNode{Comment: models.Comment{ID: 2, PostID: postID, ParentID: &topLevelCommentID, Depth: 1, Status: "approved"}, Replies: []*models.CommentNode{}}
+	all := []*models.CommentNode{
+		{Comment: models.Comment{ID: 1, PostID: postID, Status: "approved"}, Replies: []*models.CommentNode{reply}},
+		{Comment: models.Comment{ID: 3, PostID: postID, UserID: pendingCommentAuthorID, Status: "pending"}, Replies: []*models.CommentNode{}},
+	}
+	thread := []*models.CommentNode{}
+	for _, node := range all {
+		if status == "" || node.Status == status {
+			thread = append(thread, node)
+		}
+	}
+	return thread, nil
+}
+
+func (fakeCommentService) GetSummaries(postIDs []uint, viewerID uint, viewerRole string) ([]models.PostCommentSummary, error) {
+	summaries := make([]models.PostCommentSummary, len(postIDs))
+	for i, id := range postIDs {
+		summaries[i] = models.PostCommentSummary{PostID: id}
+	}
+	return summaries, nil
+}
+
+// Preview rejects anything over 20 characters and otherwise wraps the
+// content in a paragraph
+func (fakeCommentService) Preview(content string, userRole string) (string, error) {
+	if len(content) > 20 {
+		return "", services.ErrCommentTooLong
+	}
+	return "<p>" + content + "</p>", nil
+}
+
+// getComments requests path from the public comment routes. ?as=admin or
+// ?as=<user id> stands in for OptionalAuthMiddleware signing the caller in.
+func getComments(t *testing.T, path string) (int, map[string]interface{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		switch as := c.Query("as"); as {
+		case "":
+		case "admin":
+			c.Set("user_id", uint(1))
+			c.Set("user_role", "admin")
+		default:
+			id, err := strconv.ParseUint(as, 10, 32)
+			require.NoError(t, err)
+			c.Set("user_id", uint(id))
+			c.Set("user_role", "author")
+		}
+	})
+	handler := NewCommentHandler(fakeCommentService{})
+	router.GET("/comments/:id", handler.GetByID)
+	router.GET("/comments/post/:post_id", handler.GetByPost)
+	router.GET("/comments/post/:post_id/thread", handler.GetThread)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func getPostComments(t *testing.T, query string) map[string]interface{} {
+	t.Helper()
+	code, body := getComments(t, "/comments/post/5"+query)
+	require.Equal(t, http.StatusOK, code)
+	return body
+}
+
+// threadIDs returns the top-level comment IDs of a threaded response
+func threadIDs(t *testing.T, body map[string]interface{}) []float64 {
+	t.Helper()
+	data, ok := body["data"].([]interface{})
+	require.True(t, ok)
+	ids := []float64{}
+	for _, node := range data {
+		ids = append(ids, node.(map[string]interface{})["id"].(float64))
+	}
+	return ids
+}
+
+func TestCommentHandler_GetByPostThreaded(t *testing.T) {
+	t.Run("comments stay flat by default", func(t *testing.T) {
+		body := getPostComments(t, "")
+
+		data, ok := body["data"].([]interface{})
+		require.True(t, ok)
+		assert.Len(t, data, 2)
+		assert.Contains(t, body, "meta")
+	})
+
+	t.Run("threaded nests replies under their parent", func(t *testing.T) {
+		body := getPostComments(t, "?threaded=true")
+
+		data, ok := body["data"].([]interface{})
+		require.True(t, ok)
+		require.Len(t, data, 1)
+		top := data[0].(map[string]interface{})
+		assert.EqualValues(t, 1, top["id"])
+		replies := top["replies"].([]interface{})
+		require.Len(t, replies, 1)
+		assert.EqualValues(t, 2, replies[0].(map[string]interface{})["id"])
+	})
+}
+
+func TestCommentHandler_ThreadHidesUnapprovedComments(t *testing.T) {
+	for _, path := range []string{"/comments/post/5/thread", "/comments/post/5?threaded=true"} {
+		t.Run("anonymous callers only see approved comments via "+path, func(t *testing.T) {
+			code, body := getComments(t, path)
+
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, []float64{1}, threadIDs(t, body))
+		})
+
+		t.Run("readers cannot ask for pending comments via "+path, func(t *testing.T) {
+			code, body := getComments(t, path+sep(path)+"status=pending&as=9")
+
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, []float64{1}, threadIDs(t, body))
+		})
+
+		t.Run("admins may ask for every status via "+path, func(t *testing.T) {
+			code, body := getComments(t, path+sep(path)+"status=all&as=admin")
+
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, []float64{1, 3}, threadIDs(t, body))
+		})
+	}
+
+	t.Run("an unknown status is a bad request", func(t *testing.T) {
+		code, _ := getComments(t, "/comments/post/5/thread?status=spam&as=admin")
+
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+// sep joins another query parameter onto path
+func sep(path string) string {
+	if strings.Contains(path, "?") {
+		return "&"
+	}
+	return "?"
+}
+
+func TestCommentHandler_GetByIDHidesUnapprovedComments(t *testing.T) {
+	t.Run("approved comments are public", func(t *testing.T) {
+		code, _ := getComments(t, "/comments/1")
+
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("a pending comment is not found for anonymous callers", func(t *testing.T) {
+		code, _ := getComments(t, "/comments/3")
+
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("a pending comment is not found for other users", func(t *testing.T) {
+		code, _ := getComments(t, "/comments/3?as=9")
+
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("its author and admins still see a pending comment", func(t *testing.T) {
+		code, _ := getComments(t, "/comments/3?as=7")
+		assert.Equal(t, http.StatusOK, code)
+
+		code, _ = getComments(t, "/comments/3?as=admin")
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
+
+func TestCommentHandler_Preview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/comments/preview", func(c *gin.Context) {
+		// Stand-in for AuthMiddleware
+		c.Set("user_role", "author")
+	}, NewCommentHandler(fakeCommentService{}).Preview)
+
+	preview := func(body string) (*httptest.ResponseRecorder, models.APIResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/comments/preview", strings.NewReader(body)))
+		var response models.APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+
+	t.Run("returns the rendered HTML", func(t *testing.T) {
+		w, response := preview(`{"content":"Hello there"}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "<p>Hello there</p>", response.Data.(map[string]interface{})["html"])
+	})
+
+	t.Run("content over the limit is a bad request", func(t *testing.T) {
+		w, response := preview(`{"content":"far too long for this fake service"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.False(t, response.Success)
+	})
+
+	t.Run("content is required", func(t *testing.T) {
+		w, _ := preview(`{}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+// moderatedCommentService serves post 5's comments, one in each status.
+// Post 6 is a draft only admins may see the comments of.
+type moderatedCommentService struct {
+	services.CommentService
+}
+
+const draftPostID uint = 6
+
+func (s moderatedCommentService) WithContext(ctx context.Context) services.CommentService {
+	return s
+}
+
+func (moderatedCommentService) comments(status string) []models.Comment {
+	var comments []models.Comment
+	for i, s := range []string{"approved", "pending", "rejected"} {
+		if status == "" || status == s {
+			comments = append(comments, models.Comment{ID: uint(i + 1), PostID: 5, Status: s})
+		}
+	}
+	return comments
+}
+
+func (s moderatedCommentService) GetByPost(postID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Comment, int64, error) {
+	return s.GetByPostWithStatus(postID, "approved", page, perPage, viewerID, viewerRole)
+}
+
+func (s moderatedCommentService) GetByPostWithStatus(postID uint, status string, page, perPage int, viewerID uint, viewerRole string) ([]models.Comment, int64, error) {
+	if postID == draftPostID && viewerRole != "admin" {
+		return nil, 0, services.ErrCommentPostNotFound
+	}
+	comments := s.comments(status)
+	return comments, int64(len(comments)), nil
+}
+
+func (s moderatedCommentService) List(page, perPage int, filters map[string]interface{}, viewerID uint, viewerRole string) ([]models.Comment, int64, error) {
+	if postID, ok := filters["post_id"].(uint); ok && postID == draftPostID && viewerRole != "admin" {
+		return nil, 0, services.ErrCommentPostNotFound
+	}
+	status, _ := filters["status"].(string)
+	comments := s.comments(status)
+	return comments, int64(len(comments)), nil
+}
+
+// listStatuses requests path as role, or anonymously when role is empty, and
+// returns the status of each comment listed
+func listStatuses(t *testing.T, path, role string) (int, []string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if role != "" {
+			c.Set("user_id", uint(1))
+			c.Set("user_role", role)
+		}
+		c.Next()
+	})
+	handler := NewCommentHandler(moderatedCommentService{})
+	router.GET("/comments", handler.List)
+	router.GET("/comments/post/:post_id", handler.GetByPost)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+
+	var body struct {
+		Data []models.Comment `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	statuses := make([]string, len(body.Data))
+	for i, comment := range body.Data {
+		statuses[i] = comment.Status
+	}
+	return w.Code, statuses
+}
+
+func TestCommentHandler_ModerationStatusFilter(t *testing.T) {
+	for _, path := range []string{"/comments/post/5", "/comments"} {
+		t.Run("anonymous readers only see approved comments "+path, func(t *testing.T) {
+			for _, query := range []string{"", "?status=pending", "?status=all"} {
+				code, statuses := listStatuses(t, path+query, "")
+
+				assert.Equal(t, http.StatusOK, code, query)
+				assert.Equal(t, []string{"approved"}, statuses, query)
+			}
+		})
+
+		t.Run("other signed-in users cannot ask for more "+path, func(t *testing.T) {
+			_, statuses := listStatuses(t, path+"?status=pending", "author")
+
+			assert.Equal(t, []string{"approved"}, statuses)
+		})
+
+		t.Run("admins can ask for pending comments "+path, func(t *testing.T) {
+			_, statuses := listStatuses(t, path, "admin")
+			assert.Equal(t, []string{"approved"}, statuses, "approved by default")
+
+			_, statuses = listStatuses(t, path+"?status=pending", "admin")
+			assert.Equal(t, []string{"pending"}, statuses)
+
+			_, statuses = listStatuses(t, path+"?status=all", "admin")
+			assert.Equal(t, []string{"approved", "pending", "rejected"}, statuses)
+		})
+
+		t.Run("an unknown status is rejected "+path, func(t *testing.T) {
+			code, _ := listStatuses(t, path+"?status=spam", "admin")
+
+			assert.Equal(t, http.StatusBadRequest, code)
+		})
+	}
+}
+
+func TestCommentHandler_DraftPostComments(t *testing.T) {
+	for _, path := range []string{"/comments/post/6", "/comments?post_id=6"} {
+		t.Run("readers get a 404 for a draft's comments via "+path, func(t *testing.T) {
+			code, _ := listStatuses(t, path, "")
+			assert.Equal(t, http.StatusNotFound, code)
+
+			code, _ = listStatuses(t, path, "author")
+			assert.Equal(t, http.StatusNotFound, code)
+		})
+
+		t.Run("admins still see a draft's comments via "+path, func(t *testing.T) {
+			code, statuses := listStatuses(t, path, "admin")
+
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, []string{"approved"}, statuses)
+		})
+	}
+
+	t.Run("a draft's pending comments stay hidden too", func(t *testing.T) {
+		code, _ := listStatuses(t, "/comments/post/6?status=pending", "author")
+
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/services"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailHandler struct {
+	emailQueue *services.EmailQueue
+}
+
+// NewEmailHandler reports on the given email queue, which is nil when email
+// is disabled
+func NewEmailHandler(emailQueue *services.EmailQueue) *EmailHandler {
+	return &EmailHandler{
+		emailQueue: emailQueue,
+	}
+}
+
+// RecentFailures lists the most recent emails that could not be delivered,
+// newest first
+func (h *EmailHandler) RecentFailures(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.SuccessResponse("Email failures retrieved successfully", h.emailQueue.RecentFailures()))
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/graphql"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLHandler struct {
+	executor *graphql.Executor
+}
+
+func NewGraphQLHandler(executor *graphql.Executor) *GraphQLHandler {
+	return &GraphQLHandler{
+		executor: executor,
+	}
+}
+
+// Query executes a read-only GraphQL query. The response follows the GraphQL
+// convention of a {data, errors} body rather than the REST envelope, so
+// standard clients work unchanged.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graphql.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	// Set by OptionalAuthMiddleware when a valid token is present
+	var viewer graphql.Viewer
+	if userID, exists := c.Get("user_id"); exists {
+		viewer.UserID = userID.(uint)
+	}
+	viewer.Role = c.GetString("user_role")
+
+	c.JSON(http.StatusOK, h.executor.Execute(c.Request.Context(), req, viewer))
+}
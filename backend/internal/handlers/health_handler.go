@@ -1,19 +1,31 @@
 package handlers
 
 import (
+	"context"
+	"net"
+	"strings"
+
+	"backend/internal/config"
 	"backend/pkg/health"
+	"backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // HealthHandler handles health check endpoints
 type HealthHandler struct {
-	checker *health.HealthChecker
+	checker          *health.HealthChecker
+	publicDetails    bool
+	internalNetworks []*net.IPNet
 }
 
-// NewHealthHandler creates a new health handler
-func NewHealthHandler(db *gorm.DB) *HealthHandler {
+// NewHealthHandler creates a new health handler. System details are only
+// shown to admins and internal networks unless cfg makes them public. The
+// readiness probe answers 503 until readiness is marked ready; a nil
+// readiness leaves it to the dependency checks.
+func NewHealthHandler(db *gorm.DB, cfg *config.HealthConfig, readiness *health.Readiness) *HealthHandler {
 	checker := health.NewHealthChecker()
 
 	// Add database health checker
@@ -22,14 +34,61 @@ func NewHealthHandler(db *gorm.DB) *HealthHandler {
 	// Add memory health checker (500MB limit)
 	checker.AddChecker("memory", health.NewMemoryChecker(500))
 
-	return &HealthHandler{
-		checker: checker,
+	h := &HealthHandler{
+		checker:          checker,
+		publicDetails:    cfg.PublicDetails,
+		internalNetworks: parseNetworks(cfg.InternalNetworks),
+	}
+	checker.SetDetailPolicy(h.showDetails)
+	if readiness != nil {
+		checker.SetReadiness(readiness)
+	}
+	return h
+}
+
+// showDetails reports whether the caller may see the full health payload
+func (h *HealthHandler) showDetails(c *gin.Context) bool {
+	if h.publicDetails || c.GetString("user_role") == "admin" {
+		return true
+	}
+
+	// RemoteIP ignores X-Forwarded-For, which any client can set
+	ip := net.ParseIP(c.RemoteIP())
+	for _, network := range h.internalNetworks {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNetworks parses CIDR ranges, or single addresses, skipping invalid ones
+func parseNetworks(cidrs []string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			logger.LogWarn(context.Background(), "Ignoring invalid internal network",
+				zap.String("network", cidr),
+			)
+			continue
+		}
+		networks = append(networks, network)
 	}
+	return networks
 }
 
 // HealthCheck handles general health check
 // @Summary Health Check
-// @Description Check the health status of the API and its dependencies
+// @Description Check the health status of the API and its dependencies. Anonymous callers outside the internal networks only get the status.
 // @Tags health
 // @Produce json
 // @Success 200 {object} health.HealthResponse
@@ -40,7 +99,7 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 
 // LivenessCheck handles Kubernetes liveness probe
 // @Summary Liveness Check
-// @Description Check if the application is alive (Kubernetes liveness probe)
+// @Description Check if the application is alive (Kubernetes liveness probe). Anonymous callers outside the internal networks only get the status.
 // @Tags health
 // @Produce json
 // @Success 200 {object} health.HealthResponse
@@ -51,12 +110,13 @@ func (h *HealthHandler) LivenessCheck(c *gin.Context) {
 
 // ReadinessCheck handles Kubernetes readiness probe
 // @Summary Readiness Check
-// @Description Check if the application is ready to serve traffic (Kubernetes readiness probe)
+// @Description Check if the application is ready to serve traffic (Kubernetes readiness probe). Answers 503 while the server is starting up or shutting down. Anonymous callers outside the internal networks only get the status.
 // @Tags health
 // @Produce json
 // @Success 200 {object} health.HealthResponse
 // @Success 503 {object} health.HealthResponse
 // @Router /readyz [get]
+// @Router /health/ready [get]
 func (h *HealthHandler) ReadinessCheck(c *gin.Context) {
 	h.checker.ReadinessHandler(c)
 }
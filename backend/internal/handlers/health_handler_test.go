@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/config"
+	"backend/pkg/health"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newHealthRouter(t *testing.T, cfg *config.HealthConfig, role string) *gin.Engine {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	handler := NewHealthHandler(db, cfg, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if role != "" {
+			c.Set("user_role", role)
+		}
+		c.Next()
+	})
+	router.GET("/health", handler.HealthCheck)
+	router.GET("/healthz", handler.LivenessCheck)
+	router.GET("/readyz", handler.ReadinessCheck)
+	return router
+}
+
+// getHealth requests path from remoteAddr and decodes the JSON body
+func getHealth(t *testing.T, router *gin.Engine, path, remoteAddr string) map[string]interface{} {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	// A spoofed header must not count as an internal address
+	req.Header.Set("X-Forwarded-For", "127.0.0.1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body
+}
+
+func TestHealthHandler_Details(t *testing.T) {
+	cfg := &config.HealthConfig{InternalNetworks: []string{"10.0.0.0/8", "127.0.0.1"}}
+	paths := []string{"/health", "/healthz", "/readyz"}
+
+	t.Run("anonymous public callers only see the status", func(t *testing.T) {
+		router := newHealthRouter(t, cfg, "")
+		for _, path := range paths {
+			body := getHealth(t, router, path, "203.0.113.7:4321")
+
+			assert.Equal(t, map[string]interface{}{"status": "healthy"}, body, path)
+		}
+	})
+
+	t.Run("admins see system details", func(t *testing.T) {
+		router := newHealthRouter(t, cfg, "admin")
+
+		body := getHealth(t, router, "/health", "203.0.113.7:4321")
+
+		assert.Contains(t, body, "system")
+		assert.Contains(t, body["checks"], "database")
+	})
+
+	t.Run("other signed-in users do not", func(t *testing.T) {
+		router := newHealthRouter(t, cfg, "author")
+
+		body := getHealth(t, router, "/health", "203.0.113.7:4321")
+
+		assert.NotContains(t, body, "system")
+	})
+
+	t.Run("internal networks see system details", func(t *testing.T) {
+		router := newHealthRouter(t, cfg, "")
+		for _, remoteAddr := range []string{"10.1.2.3:4321", "127.0.0.1:4321"} {
+			body := getHealth(t, router, "/readyz", remoteAddr)
+
+			assert.Contains(t, body, "system", remoteAddr)
+			assert.Contains(t, body, "checks", remoteAddr)
+		}
+	})
+
+	t.Run("details can be made public", func(t *testing.T) {
+		router := newHealthRouter(t, &config.HealthConfig{PublicDetails: true}, "")
+
+		body := getHealth(t, router, "/healthz", "203.0.113.7:4321")
+
+		assert.Contains(t, body, "system")
+	})
+}
+
+func TestHealthHandler_Readiness(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	readiness := health.NewReadiness()
+	handler := NewHealthHandler(db, &config.HealthConfig{}, readiness)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/healthz", handler.LivenessCheck)
+	router.GET("/readyz", handler.ReadinessCheck)
+	status := func(path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, status("/readyz"), "starting up")
+	assert.Equal(t, http.StatusOK, status("/healthz"), "alive while starting up")
+
+	readiness.MarkReady()
+	assert.Equal(t, http.StatusOK, status("/readyz"), "serving")
+
+	readiness.MarkNotReady()
+	assert.Equal(t, http.StatusServiceUnavailable, status("/readyz"), "shutting down")
+}
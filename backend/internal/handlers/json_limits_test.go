@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJSONLimitsRouter(strict bool, maxDepth int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewPostHandler(&fakePostService{}, &fakePostStatsService{})
+	router := gin.New()
+	router.Use(middleware.JSONDecoding(strict, maxDepth))
+	router.POST("/posts", func(c *gin.Context) {
+		// Stand-in for AuthMiddleware
+		c.Set("user_id", uint(7))
+		c.Set("user_role", "author")
+	}, handler.Create)
+	return router
+}
+
+func createPostWithBody(t *testing.T, router *gin.Engine, body string) (*httptest.ResponseRecorder, models.APIResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body)))
+
+	var response models.APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w, response
+}
+
+func TestPostHandler_CreateJSONLimits(t *testing.T) {
+	content := strings.Repeat("Post content. ", 5)
+	withUnknownField := `{"title":"Hello","content":"` + content + `","category_id":1,"catgory":"typo"}`
+
+	t.Run("unknown fields are ignored by default", func(t *testing.T) {
+		w, _ := createPostWithBody(t, newJSONLimitsRouter(false, 32), withUnknownField)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("unknown fields are rejected when strict", func(t *testing.T) {
+		w, response := createPostWithBody(t, newJSONLimitsRouter(true, 32), withUnknownField)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.False(t, response.Success)
+		assert.Contains(t, w.Body.String(), `unknown field \"catgory\"`)
+	})
+
+	t.Run("known fields pass when strict", func(t *testing.T) {
+		w, _ := createPostWithBody(t, newJSONLimitsRouter(true, 32), `{"title":"Hello","content":"`+content+`","category_id":1}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("deeply nested payloads are rejected", func(t *testing.T) {
+		nested := strings.Repeat("[", 10000) + strings.Repeat("]", 10000)
+		body := `{"title":"Hello","content":"` + content + `","category_id":1,"extra":` + nested + `}`
+
+		w, response := createPostWithBody(t, newJSONLimitsRouter(false, 32), body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.False(t, response.Success)
+		assert.Contains(t, w.Body.String(), "nested too deeply")
+	})
+
+	t.Run("binding rules still apply", func(t *testing.T) {
+		w, _ := createPostWithBody(t, newJSONLimitsRouter(true, 32), `{"title":"Hello","category_id":1}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCommentHandler_GetSummariesJSONLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.JSONDecoding(true, 32))
+	router.POST("/posts/comment-summary", NewCommentHandler(fakeCommentService{}).GetSummaries)
+
+	summarize := func(body string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts/comment-summary", strings.NewReader(body)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, summarize(`{"post_ids":[1,2]}`))
+	assert.Equal(t, http.StatusBadRequest, summarize(`{"post_ids":[1,2],"post_idz":[3]}`))
+	assert.Equal(t, http.StatusBadRequest, summarize(`{"post_ids":[0]}`))
+}
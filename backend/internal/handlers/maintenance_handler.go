@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/pkg/cache"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MaintenanceHandler struct {
+	caches             *cache.Registry
+	maintenanceService services.MaintenanceService
+}
+
+func NewMaintenanceHandler(caches *cache.Registry, maintenanceService services.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		caches:             caches,
+		maintenanceService: maintenanceService,
+	}
+}
+
+// FlushCache clears every registered in-process cache and lists the ones
+// that were flushed
+func (h *MaintenanceHandler) FlushCache(c *gin.Context) {
+	flushed := h.caches.FlushAll()
+	c.JSON(http.StatusOK, utils.SuccessResponse("Caches flushed successfully", gin.H{
+		"flushed": flushed,
+	}))
+}
+
+// ListWindows pages through scheduled maintenance windows, latest start first
+func (h *MaintenanceHandler) ListWindows(c *gin.Context) {
+	page, perPage := utils.GetPaginationParams(c)
+
+	windows, total, err := h.maintenanceService.List(page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve maintenance windows", err.Error()))
+		return
+	}
+
+	response := utils.PaginatedAPIResponse(windows, total, page, perPage, "Maintenance windows retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
+}
+
+func (h *MaintenanceHandler) GetWindow(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid maintenance window ID", err.Error()))
+		return
+	}
+
+	window, err := h.maintenanceService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(maintenanceErrorStatus(err), utils.ErrorResponse("Failed to retrieve maintenance window", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Maintenance window retrieved successfully", window))
+}
+
+func (h *MaintenanceHandler) CreateWindow(c *gin.Context) {
+	var req models.CreateMaintenanceWindowRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	window, err := h.maintenanceService.Create(&req)
+	if err != nil {
+		c.JSON(maintenanceErrorStatus(err), utils.ErrorResponse("Failed to schedule maintenance window", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, utils.SuccessResponse("Maintenance window scheduled successfully", window))
+}
+
+func (h *MaintenanceHandler) UpdateWindow(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid maintenance window ID", err.Error()))
+		return
+	}
+
+	var req models.UpdateMaintenanceWindowRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	window, err := h.maintenanceService.Update(uint(id), &req)
+	if err != nil {
+		c.JSON(maintenanceErrorStatus(err), utils.ErrorResponse("Failed to update maintenance window", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Maintenance window updated successfully", window))
+}
+
+func (h *MaintenanceHandler) DeleteWindow(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid maintenance window ID", err.Error()))
+		return
+	}
+
+	if err := h.maintenanceService.Delete(uint(id)); err != nil {
+		c.JSON(maintenanceErrorStatus(err), utils.ErrorResponse("Failed to delete maintenance window", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Maintenance window deleted successfully", nil))
+}
+
+// Announcements tells visitors about maintenance under way or coming up
+func (h *MaintenanceHandler) Announcements(c *gin.Context) {
+	windows, err := h.maintenanceService.Upcoming()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve announcements", err.Error()))
+		return
+	}
+	if windows == nil {
+		windows = []models.MaintenanceWindow{}
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Announcements retrieved successfully", models.AnnouncementsResponse{
+		Maintenance: windows,
+	}))
+}
+
+func maintenanceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrMaintenanceWindowNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidMaintenanceWindow):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
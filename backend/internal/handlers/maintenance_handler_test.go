@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceHandler_FlushCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := cache.NewRegistry()
+	posts := cache.NewMap[uint, string](registry, "posts")
+
+	source := "original title"
+	load := func() (string, error) { return source, nil }
+	_, err := posts.GetOrLoad(1, load)
+	require.NoError(t, err)
+
+	source = "edited in the database"
+	cached, _ := posts.GetOrLoad(1, load)
+	require.Equal(t, "original title", cached)
+
+	router := gin.New()
+	router.POST("/admin/maintenance/flush-cache", NewMaintenanceHandler(registry, nil).FlushCache)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/maintenance/flush-cache", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data struct {
+			Flushed []string `json:"flushed"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"posts"}, resp.Data.Flushed)
+
+	fresh, _ := posts.GetOrLoad(1, load)
+	assert.Equal(t, "edited in the database", fresh)
+}
@@ -1,26 +1,122 @@
 package handlers
 
 import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"net/http"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/pkg/logger"
 	"backend/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Ways of guarding /metrics, set with METRICS_GUARD
+const (
+	// MetricsGuardOpen serves metrics to anyone
+	MetricsGuardOpen = "open"
+	// MetricsGuardToken requires the configured token, as a bearer token or
+	// the basic auth password
+	MetricsGuardToken = "token"
+	// MetricsGuardNetwork only serves requests from the allowed networks
+	MetricsGuardNetwork = "network"
+	// MetricsGuardListener serves metrics on their own listener only, off
+	// the API port
+	MetricsGuardListener = "listener"
+)
+
 // MetricsHandler handles Prometheus metrics endpoint
-type MetricsHandler struct{}
+type MetricsHandler struct {
+	guard           string
+	token           string
+	allowedNetworks []*net.IPNet
+}
+
+// NewMetricsHandler creates a new metrics handler guarded as cfg says.
+// Unknown guards are treated as token, and token without a token configured
+// refuses every request.
+func NewMetricsHandler(cfg *config.MetricsConfig) *MetricsHandler {
+	h := &MetricsHandler{
+		guard:           cfg.Guard,
+		token:           cfg.Token,
+		allowedNetworks: parseNetworks(cfg.AllowedNetworks),
+	}
+	switch h.guard {
+	case MetricsGuardOpen, MetricsGuardNetwork, MetricsGuardListener:
+	default:
+		h.guard = MetricsGuardToken
+		if h.token == "" {
+			logger.LogWarn(context.Background(), "Metrics are token-protected but METRICS_TOKEN is empty; /metrics refuses every request")
+		}
+	}
+	return h
+}
 
-// NewMetricsHandler creates a new metrics handler
-func NewMetricsHandler() *MetricsHandler {
-	return &MetricsHandler{}
+// OnAPIPort reports whether /metrics belongs on the API router. With the
+// listener guard it is only served on its own listener.
+func (h *MetricsHandler) OnAPIPort() bool {
+	return h.guard != MetricsGuardListener
 }
 
 // Metrics handles Prometheus metrics endpoint
 // @Summary Prometheus Metrics
-// @Description Get Prometheus metrics for monitoring
+// @Description Get Prometheus metrics for monitoring. Depending on METRICS_GUARD a token or a request from an allowed network is required.
 // @Tags metrics
 // @Produce text/plain
 // @Success 200 {string} string "Prometheus metrics"
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Router /metrics [get]
 func (h *MetricsHandler) Metrics(c *gin.Context) {
+	switch h.guard {
+	case MetricsGuardToken:
+		if !h.validToken(c) {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Success: false,
+				Error:   "Metrics token required",
+				Code:    "ERR_METRICS_UNAUTHORIZED",
+				Details: "Provide the metrics token as a bearer token or basic auth password",
+			})
+			return
+		}
+	case MetricsGuardNetwork:
+		if !h.fromAllowedNetwork(c) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Success: false,
+				Error:   "Metrics are not available from this network",
+				Code:    "ERR_METRICS_FORBIDDEN",
+			})
+			return
+		}
+	}
 	metrics.Handler()(c)
 }
+
+// validToken reports whether the request carries the metrics token
+func (h *MetricsHandler) validToken(c *gin.Context) bool {
+	if h.token == "" {
+		return false
+	}
+	presented := services.ExtractTokenFromHeader(c.GetHeader("Authorization"))
+	if presented == "" {
+		_, presented, _ = c.Request.BasicAuth()
+	}
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(h.token)) == 1
+}
+
+// fromAllowedNetwork reports whether the connecting address, not
+// X-Forwarded-For, is in one of the allowed networks
+func (h *MetricsHandler) fromAllowedNetwork(c *gin.Context) bool {
+	ip := net.ParseIP(c.RemoteIP())
+	for _, network := range h.allowedNetworks {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func getMetrics(cfg *config.MetricsConfig, remoteAddr string, prepare func(*http.Request)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics", NewMetricsHandler(cfg).Metrics)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = remoteAddr
+	if prepare != nil {
+		prepare(req)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMetricsHandler_Guard(t *testing.T) {
+	tokenGuard := &config.MetricsConfig{Guard: MetricsGuardToken, Token: "s3cret"}
+
+	t.Run("open serves anyone", func(t *testing.T) {
+		w := getMetrics(&config.MetricsConfig{Guard: MetricsGuardOpen}, "203.0.113.5:4000", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("token rejects requests without it", func(t *testing.T) {
+		w := getMetrics(tokenGuard, "127.0.0.1:4000", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_METRICS_UNAUTHORIZED")
+		assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("token rejects a wrong token", func(t *testing.T) {
+		w := getMetrics(tokenGuard, "127.0.0.1:4000", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer guess")
+		})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("token accepts the bearer token", func(t *testing.T) {
+		w := getMetrics(tokenGuard, "203.0.113.5:4000", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer s3cret")
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("token accepts the basic auth password", func(t *testing.T) {
+		w := getMetrics(tokenGuard, "203.0.113.5:4000", func(req *http.Request) {
+			req.SetBasicAuth("prometheus", "s3cret")
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("token without a configured token refuses everything", func(t *testing.T) {
+		w := getMetrics(&config.MetricsConfig{Guard: MetricsGuardToken}, "127.0.0.1:4000", func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer ")
+		})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("network only serves allowed addresses", func(t *testing.T) {
+		cfg := &config.MetricsConfig{Guard: MetricsGuardNetwork, AllowedNetworks: []string{"10.0.0.0/8"}}
+
+		outside := getMetrics(cfg, "203.0.113.5:4000", func(req *http.Request) {
+			req.Header.Set("X-Forwarded-For", "10.0.0.1")
+		})
+		inside := getMetrics(cfg, "10.1.2.3:4000", nil)
+
+		assert.Equal(t, http.StatusForbidden, outside.Code)
+		assert.Equal(t, http.StatusOK, inside.Code)
+	})
+
+	t.Run("listener keeps metrics off the API router", func(t *testing.T) {
+		assert.False(t, NewMetricsHandler(&config.MetricsConfig{Guard: MetricsGuardListener}).OnAPIPort())
+		assert.True(t, NewMetricsHandler(tokenGuard).OnAPIPort())
+	})
+}
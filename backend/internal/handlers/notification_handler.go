@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService services.NotificationService
+}
+
+func NewNotificationHandler(notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// List returns the current user's notifications, newest first. Pass
+// ?read=false for unread only or ?read=true for read only.
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	page, perPage := utils.GetPaginationParams(c)
+
+	var read *bool
+	if readParam := c.Query("read"); readParam != "" {
+		value, err := strconv.ParseBool(readParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid read filter", err.Error()))
+			return
+		}
+		read = &value
+	}
+
+	notifications, total, err := h.notificationService.List(userID.(uint), read, page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve notifications", err.Error()))
+		return
+	}
+
+	unread, err := h.notificationService.UnreadCount(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve notifications", err.Error()))
+		return
+	}
+
+	response := models.NotificationListResponse{
+		PaginationResponse: utils.PaginationResponse(notifications, total, page, perPage),
+		UnreadCount:        unread,
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notifications retrieved successfully", response))
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid notification ID", err.Error()))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if err := h.notificationService.MarkRead(userID.(uint), uint(id)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to mark notification as read", err.Error()))
+		return
+	}
+
+	h.respondWithUnreadCount(c, userID.(uint), "Notification marked as read")
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := h.notificationService.MarkAllRead(userID.(uint)); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to mark notifications as read", err.Error()))
+		return
+	}
+
+	h.respondWithUnreadCount(c, userID.(uint), "All notifications marked as read")
+}
+
+// GetPreferences returns where the current user wants each kind of
+// notification delivered
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	preferences, err := h.notificationService.GetPreferences(userID.(uint))
+	if err != nil {
+		h.respondWithPreferencesError(c, "Failed to retrieve notification preferences", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notification preferences retrieved successfully", preferences))
+}
+
+// UpdatePreferences changes the channels named in the body and returns the
+// full set of preferences
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req models.UpdateNotificationPreferencesRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	preferences, err := h.notificationService.UpdatePreferences(userID.(uint), &req)
+	if err != nil {
+		h.respondWithPreferencesError(c, "Failed to update notification preferences", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Notification preferences updated successfully", preferences))
+}
+
+func (h *NotificationHandler) respondWithPreferencesError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, services.ErrUserNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, utils.ErrorResponse(message, err.Error()))
+}
+
+// respondWithUnreadCount returns the user's remaining unread count so clients
+// can update their badge without another request
+func (h *NotificationHandler) respondWithUnreadCount(c *gin.Context, userID uint, message string) {
+	unread, err := h.notificationService.UnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to count unread notifications", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(message, models.UnreadCountResponse{UnreadCount: unread}))
+}
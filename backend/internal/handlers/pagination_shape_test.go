@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginationShape(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakePostService{}
+	for i := 1; i <= 12; i++ {
+		service.posts = append(service.posts, models.Post{ID: uint(i), AuthorID: 7, Title: fmt.Sprintf("Post %d", i), Status: "published"})
+	}
+	handler := NewPostHandler(service, &fakePostStatsService{})
+
+	newRouter := func(defaultShape string) *gin.Engine {
+		router := gin.New()
+		router.Use(middleware.PaginationShape(defaultShape))
+		router.GET("/posts/author/:author_id", handler.GetByAuthor)
+		return router
+	}
+
+	get := func(t *testing.T, router *gin.Engine, header string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/posts/author/7?page=2&per_page=5", nil)
+		if header != "" {
+			req.Header.Set(utils.PaginationShapeHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w, body
+	}
+
+	t.Run("meta is the default shape", func(t *testing.T) {
+		w, body := get(t, newRouter(utils.PaginationShapeMeta), "")
+
+		assert.Equal(t, "meta", w.Header().Get(utils.PaginationShapeHeader))
+		assert.Len(t, body["data"], 5)
+		meta := body["meta"].(map[string]interface{})
+		assert.Equal(t, float64(12), meta["total"])
+		assert.Equal(t, float64(3), meta["total_pages"])
+	})
+
+	t.Run("the header asks for the legacy nested shape", func(t *testing.T) {
+		w, body := get(t, newRouter(utils.PaginationShapeMeta), "Legacy")
+
+		assert.Equal(t, "legacy", w.Header().Get(utils.PaginationShapeHeader))
+		assert.NotContains(t, body, "meta")
+		page := body["data"].(map[string]interface{})
+		assert.Len(t, page["data"], 5)
+		assert.Equal(t, float64(12), page["total"])
+		assert.Equal(t, float64(2), page["page"])
+		assert.Equal(t, float64(5), page["per_page"])
+		assert.Equal(t, float64(3), page["total_pages"])
+	})
+
+	t.Run("the configured default applies without a header", func(t *testing.T) {
+		w, body := get(t, newRouter(utils.PaginationShapeLegacy), "")
+
+		assert.Equal(t, "legacy", w.Header().Get(utils.PaginationShapeHeader))
+		assert.Contains(t, body["data"], "total")
+	})
+
+	t.Run("the header overrides the configured default", func(t *testing.T) {
+		_, body := get(t, newRouter(utils.PaginationShapeLegacy), "meta")
+
+		assert.Contains(t, body, "meta")
+	})
+
+	t.Run("unknown values fall back to the configured default", func(t *testing.T) {
+		w, _ := get(t, newRouter(utils.PaginationShapeLegacy), "v3")
+
+		assert.Equal(t, "legacy", w.Header().Get(utils.PaginationShapeHeader))
+	})
+
+	t.Run("both shapes carry the same items", func(t *testing.T) {
+		_, meta := get(t, newRouter(utils.PaginationShapeMeta), "")
+		_, legacy := get(t, newRouter(utils.PaginationShapeMeta), "legacy")
+
+		assert.Equal(t, meta["data"], legacy["data"].(map[string]interface{})["data"])
+	})
+}
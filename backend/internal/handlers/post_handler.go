@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"backend/internal/models"
 	"backend/internal/services"
@@ -12,18 +14,20 @@ import (
 )
 
 type PostHandler struct {
-	postService services.PostService
+	postService  services.PostService
+	statsService services.PostStatsService
 }
 
-func NewPostHandler(postService services.PostService) *PostHandler {
+func NewPostHandler(postService services.PostService, statsService services.PostStatsService) *PostHandler {
 	return &PostHandler{
-		postService: postService,
+		postService:  postService,
+		statsService: statsService,
 	}
 }
 
 func (h *PostHandler) Create(c *gin.Context) {
 	var req models.CreatePostRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := utils.BindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
 		return
 	}
@@ -31,42 +35,123 @@ func (h *PostHandler) Create(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	authorID := userID.(uint)
 
-	post, err := h.postService.Create(&req, authorID)
+	post, warnings, err := h.postService.WithContext(c.Request.Context()).Create(&req, authorID, c.GetString("user_role"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to create post", err.Error()))
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, services.ErrPostLimitReached), errors.Is(err, services.ErrEmailNotVerified):
+			status = http.StatusForbidden
+		case errors.Is(err, services.ErrDuplicateTitle):
+			status = http.StatusConflict
+		case errors.Is(err, services.ErrPostingTooFrequently):
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to create post", err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusCreated, utils.SuccessResponse("Post created successfully", post))
+	response := utils.SuccessResponse("Post created successfully", post)
+	response.Warnings = warnings
+	c.JSON(http.StatusCreated, response)
 }
 
+// GetByID serves GET /posts/:id, where :id is either a numeric ID or a slug.
+// An all-digit identifier is always looked up as an ID, so a post whose slug
+// is all digits must be fetched through /posts/slug/:slug. Static routes such
+// as /posts/by-slug and /posts/slug/:slug are matched before this one.
 func (h *PostHandler) GetByID(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	identifier := c.Param("id")
+
+	var (
+		post *models.Post
+		err  error
+	)
+	if id, parseErr := strconv.ParseUint(identifier, 10, 32); parseErr == nil {
+		post, err = h.postService.WithContext(c.Request.Context()).GetByID(uint(id))
+	} else {
+		post, err = h.postService.WithContext(c.Request.Context()).GetBySlug(identifier)
+	}
+	h.respondWithPost(c, post, err)
+}
+
+// Random serves GET /posts/random, a published post picked at random for
+// "surprise me" links. Drafts and scheduled posts are never picked, even for
+// their authors.
+func (h *PostHandler) Random(c *gin.Context) {
+	post, err := h.postService.WithContext(c.Request.Context()).Random()
+	if errors.Is(err, services.ErrNoPublishedPosts) {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("No published posts", err.Error()))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid post ID", err.Error()))
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to pick a post", err.Error()))
 		return
 	}
 
-	post, err := h.postService.GetByID(uint(id))
-	if err != nil {
-		c.JSON(http.StatusNotFound, utils.ErrorResponse("Post not found", err.Error()))
+	payload, ok := selectPostFields(c, post)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, utils.SuccessResponse("Post retrieved successfully", post))
+	// Every request should get a fresh pick
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, utils.SuccessResponse("Post retrieved successfully", payload))
 }
 
 func (h *PostHandler) GetBySlug(c *gin.Context) {
-	slug := c.Param("slug")
+	post, err := h.postService.WithContext(c.Request.Context()).GetBySlug(c.Param("slug"))
+	h.respondWithPost(c, post, err)
+}
 
-	post, err := h.postService.GetBySlug(slug)
+// respondWithPost returns a single post, treating posts the viewer may not
+// see as missing
+func (h *PostHandler) respondWithPost(c *gin.Context, post *models.Post, err error) {
 	if err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse("Post not found", err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, utils.SuccessResponse("Post retrieved successfully", post))
+	viewerID, viewerRole := viewerFromContext(c)
+	if !services.CanViewPost(post, viewerID, viewerRole) {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("Post not found", "record not found"))
+		return
+	}
+
+	payload, ok := selectPostFields(c, post)
+	if !ok {
+		return
+	}
+	h.statsService.RecordView(c.Request.Context(), post, viewerID)
+	c.JSON(http.StatusOK, utils.SuccessResponse("Post retrieved successfully", payload))
+}
+
+// GetBySlugs returns several posts at once from a comma-separated ?slugs=
+// list, in the order given. Unknown or hidden slugs are left out.
+func (h *PostHandler) GetBySlugs(c *gin.Context) {
+	var slugs []string
+	for _, slug := range strings.Split(c.Query("slugs"), ",") {
+		if slug = strings.TrimSpace(slug); slug != "" {
+			slugs = append(slugs, slug)
+		}
+	}
+	if len(slugs) == 0 {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", "slugs query parameter is required"))
+		return
+	}
+
+	viewerID, viewerRole := viewerFromContext(c)
+
+	posts, err := h.postService.WithContext(c.Request.Context()).GetBySlugs(slugs, viewerID, viewerRole)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to retrieve posts", err.Error()))
+		return
+	}
+
+	payload, ok := selectPostFields(c, posts)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Posts retrieved successfully", payload))
 }
 
 func (h *PostHandler) Update(c *gin.Context) {
@@ -78,7 +163,7 @@ func (h *PostHandler) Update(c *gin.Context) {
 	}
 
 	var req models.UpdatePostRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := utils.BindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
 		return
 	}
@@ -86,13 +171,19 @@ func (h *PostHandler) Update(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userRole, _ := c.Get("user_role")
 
-	post, err := h.postService.Update(uint(id), &req, userID.(uint), userRole.(string))
+	post, warnings, err := h.postService.WithContext(c.Request.Context()).Update(uint(id), &req, userID.(uint), userRole.(string))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to update post", err.Error()))
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrDuplicateTitle) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to update post", err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, utils.SuccessResponse("Post updated successfully", post))
+	response := utils.SuccessResponse("Post updated successfully", post)
+	response.Warnings = warnings
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *PostHandler) Delete(c *gin.Context) {
@@ -106,7 +197,7 @@ func (h *PostHandler) Delete(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	userRole, _ := c.Get("user_role")
 
-	if err := h.postService.Delete(uint(id), userID.(uint), userRole.(string)); err != nil {
+	if err := h.postService.WithContext(c.Request.Context()).Delete(uint(id), userID.(uint), userRole.(string)); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to delete post", err.Error()))
 		return
 	}
@@ -114,6 +205,129 @@ func (h *PostHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.SuccessResponse("Post deleted successfully", nil))
 }
 
+// Bulk publishes, archives or deletes many posts at once, e.g. when
+// moderating seeded content. It is all or nothing: if any post is missing
+// the batch is rolled back and answered with 422, the results saying which.
+// Editors may publish and archive, but only admins may delete.
+func (h *PostHandler) Bulk(c *gin.Context) {
+	var req models.BulkPostRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	if req.Action == models.BulkPostDelete && c.GetString("user_role") != "admin" {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse("Failed to apply bulk post action", "only admins can bulk delete posts"))
+		return
+	}
+
+	response, err := h.postService.WithContext(c.Request.Context()).Bulk(&req)
+	if errors.Is(err, services.ErrBulkRolledBack) {
+		body := utils.ErrorResponse("Bulk post action rolled back", err.Error())
+		body.Data = response
+		c.JSON(http.StatusUnprocessableEntity, body)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to apply bulk post action", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Bulk post action applied", response))
+}
+
+// ListTrash returns deleted posts that can still be restored, most recently
+// deleted first
+func (h *PostHandler) ListTrash(c *gin.Context) {
+	page, perPage := utils.GetPaginationParams(c)
+
+	posts, total, err := h.postService.WithContext(c.Request.Context()).ListTrashed(page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve deleted posts", err.Error()))
+		return
+	}
+
+	response := utils.PaginatedAPIResponse(models.SummarizeTrashedPosts(posts), total, page, perPage, "Deleted posts retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
+}
+
+// Restore brings a deleted post back from the trash
+func (h *PostHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid post ID", err.Error()))
+		return
+	}
+
+	post, err := h.postService.WithContext(c.Request.Context()).Restore(uint(id))
+	if errors.Is(err, services.ErrPostNotTrashed) {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("Post not found in trash", err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to restore post", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Post restored successfully", post))
+}
+
+// Transfer hands a post to another author or editor, e.g. when its author
+// leaves
+func (h *PostHandler) Transfer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid post ID", err.Error()))
+		return
+	}
+
+	var req models.TransferPostRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	post, err := h.postService.WithContext(c.Request.Context()).Transfer(uint(id), req.AuthorID, c.GetUint("user_id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrPostNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrInvalidTransferTarget):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to transfer post", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Post transferred successfully", post))
+}
+
+// TransferAll hands every post of one author to another
+func (h *PostHandler) TransferAll(c *gin.Context) {
+	var req models.TransferPostsRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	transferred, err := h.postService.WithContext(c.Request.Context()).TransferAll(req.FromAuthorID, req.ToAuthorID, c.GetUint("user_id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidTransferTarget) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to transfer posts", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Posts transferred successfully", models.TransferPostsResponse{
+		FromAuthorID: req.FromAuthorID,
+		ToAuthorID:   req.ToAuthorID,
+		Transferred:  transferred,
+	}))
+}
+
 func (h *PostHandler) List(c *gin.Context) {
 	page, perPage := utils.GetPaginationParams(c)
 	
@@ -127,8 +341,7 @@ func (h *PostHandler) List(c *gin.Context) {
 	// Parse category filter
 	if categoryID := c.Query("category_id"); categoryID != "" {
 		if id, err := strconv.ParseUint(categoryID, 10, 32); err == nil {
-			catID := uint(id)
-			searchReq.CategoryID = &catID
+			searchReq.CategoryID = uint(id)
 		}
 	}
 	
@@ -137,14 +350,125 @@ func (h *PostHandler) List(c *gin.Context) {
 		searchReq.Status = status
 	}
 
-	posts, total, err := h.postService.Search(searchReq)
+	viewerID, viewerRole := viewerFromContext(c)
+	posts, total, searchMode, err := h.postService.WithContext(c.Request.Context()).Search(searchReq, viewerID, viewerRole)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve posts", err.Error()))
 		return
 	}
 
-	response := utils.PaginatedAPIResponse(posts, total, page, perPage, "Posts retrieved successfully")
-	c.JSON(http.StatusOK, response)
+	payload, ok := listPayload(c, posts)
+	if !ok {
+		return
+	}
+	response := utils.PaginatedAPIResponse(payload, total, searchReq.Page, searchReq.Limit, "Posts retrieved successfully")
+	response.Meta.SearchMode = searchMode
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
+}
+
+// Stats returns a post's views, comment counts and recent view velocity to
+// its author or an admin
+func (h *PostHandler) Stats(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid post ID", err.Error()))
+		return
+	}
+
+	viewerID, viewerRole := viewerFromContext(c)
+	stats, err := h.statsService.GetStats(c.Request.Context(), uint(id), viewerID, viewerRole)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrPostStatsNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, services.ErrPostStatsForbidden):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, utils.ErrorResponse("Failed to retrieve post stats", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Post stats retrieved successfully", stats))
+}
+
+// View counts a view of a published post the same way reading it does, and
+// returns its new view count
+func (h *PostHandler) View(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid post ID", err.Error()))
+		return
+	}
+
+	post, err := h.postService.WithContext(c.Request.Context()).GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("Post not found", err.Error()))
+		return
+	}
+	viewerID, viewerRole := viewerFromContext(c)
+	if post.Status != "published" || !services.CanViewPost(post, viewerID, viewerRole) {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("Post not found", "record not found"))
+		return
+	}
+
+	count := h.statsService.RecordView(c.Request.Context(), post, viewerID)
+	c.JSON(http.StatusOK, utils.SuccessResponse("View recorded successfully", gin.H{
+		"id":         post.ID,
+		"view_count": count,
+	}))
+}
+
+// Usage reports how many posts the current user owns and their role's limit,
+// for the dashboard
+func (h *PostHandler) Usage(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	usage, err := h.postService.WithContext(c.Request.Context()).Usage(userID.(uint), c.GetString("user_role"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve post usage", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Post usage retrieved successfully", usage))
+}
+
+// RecentlyViewed returns the published posts the caller read last, most
+// recent first. ?limit= picks how many, up to services.RecentViewHistory.
+func (h *PostHandler) RecentlyViewed(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > services.RecentViewHistory {
+		limit = services.RecentViewHistory
+	}
+
+	ids, err := h.statsService.RecentlyViewed(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve recently viewed posts", err.Error()))
+		return
+	}
+
+	// Posts unpublished since they were read drop out, so look them up as
+	// an anonymous reader would
+	posts, err := h.postService.WithContext(c.Request.Context()).GetByIDs(ids, 0, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve recently viewed posts", err.Error()))
+		return
+	}
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+
+	payload, ok := listPayload(c, posts)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse("Recently viewed posts retrieved successfully", payload))
 }
 
 func (h *PostHandler) GetByAuthor(c *gin.Context) {
@@ -157,14 +481,20 @@ func (h *PostHandler) GetByAuthor(c *gin.Context) {
 
 	page, perPage := utils.GetPaginationParams(c)
 
-	posts, total, err := h.postService.GetByAuthor(uint(authorID), page, perPage)
+	viewerID, viewerRole := viewerFromContext(c)
+
+	posts, total, err := h.postService.WithContext(c.Request.Context()).GetByAuthor(uint(authorID), page, perPage, viewerID, viewerRole)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve posts", err.Error()))
 		return
 	}
 
-	response := utils.PaginationResponse(posts, total, page, perPage)
-	c.JSON(http.StatusOK, utils.SuccessResponse("Posts retrieved successfully", response))
+	payload, ok := listPayload(c, posts)
+	if !ok {
+		return
+	}
+	response := utils.PaginatedAPIResponse(payload, total, page, perPage, "Posts retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
 }
 
 func (h *PostHandler) GetByCategory(c *gin.Context) {
@@ -177,12 +507,87 @@ func (h *PostHandler) GetByCategory(c *gin.Context) {
 
 	page, perPage := utils.GetPaginationParams(c)
 
-	posts, total, err := h.postService.GetByCategory(uint(categoryID), page, perPage)
+	viewerID, viewerRole := viewerFromContext(c)
+
+	posts, total, err := h.postService.WithContext(c.Request.Context()).GetByCategory(uint(categoryID), page, perPage, viewerID, viewerRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve posts", err.Error()))
+		return
+	}
+
+	payload, ok := listPayload(c, posts)
+	if !ok {
+		return
+	}
+	response := utils.PaginatedAPIResponse(payload, total, page, perPage, "Posts retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
+}
+
+// GetByTag lists the posts carrying the tag with the given slug, newest first
+func (h *PostHandler) GetByTag(c *gin.Context) {
+	page, perPage := utils.GetPaginationParams(c)
+
+	viewerID, viewerRole := viewerFromContext(c)
+
+	posts, total, err := h.postService.WithContext(c.Request.Context()).GetByTag(c.Param("slug"), page, perPage, viewerID, viewerRole)
 	if err != nil {
+		if errors.Is(err, services.ErrTagNotFound) {
+			c.JSON(http.StatusNotFound, utils.ErrorResponse("Tag not found", err.Error()))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve posts", err.Error()))
 		return
 	}
 
-	response := utils.PaginationResponse(posts, total, page, perPage)
-	c.JSON(http.StatusOK, utils.SuccessResponse("Posts retrieved successfully", response))
+	payload, ok := listPayload(c, posts)
+	if !ok {
+		return
+	}
+	response := utils.PaginatedAPIResponse(payload, total, page, perPage, "Posts retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
+}
+
+// viewerFromContext returns the caller's ID and role as set by
+// OptionalAuthMiddleware, or zero values for anonymous requests
+func viewerFromContext(c *gin.Context) (uint, string) {
+	var viewerID uint
+	if userID, exists := c.Get("user_id"); exists {
+		viewerID = userID.(uint)
+	}
+	return viewerID, c.GetString("user_role")
+}
+
+// postFields are the post fields ?fields= can pick. Relations only expose
+// the fields listed for them, so sparse responses never carry an author's
+// email.
+var postFields = []string{
+	"id", "title", "slug", "content", "excerpt", "thumbnail_url",
+	"category_id", "author_id", "status", "published_at", "publish_at",
+	"comments_enabled", "view_count", "word_count", "reading_time",
+	"created_at", "updated_at",
+	"category.id", "category.name", "category.slug",
+	"author.id", "author.username", "author.name",
+	"tags.id", "tags.name", "tags.slug",
+}
+
+// listPayload returns posts in their summary form, without content, unless
+// the caller opts into full posts with ?fields=full or picks fields of its
+// own. It answers 400 itself when a pick is refused, and then returns false.
+func listPayload(c *gin.Context, posts []models.Post) (interface{}, bool) {
+	if c.Query("fields") == "" {
+		return models.SummarizePosts(posts), true
+	}
+	return selectPostFields(c, posts)
+}
+
+// selectPostFields projects a post, or a list of them, to the fields picked
+// with ?fields=. It answers 400 itself when API_STRICT_FIELDS refuses a
+// field, and then returns false.
+func selectPostFields(c *gin.Context, data interface{}) (interface{}, bool) {
+	selected, err := utils.SelectFields(c, data, postFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid fields", err.Error()))
+		return nil, false
+	}
+	return selected, true
 }
@@ -0,0 +1,782 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakePostService serves Search from an in-memory list of posts
+type fakePostService struct {
+	services.PostService
+	posts        []models.Post
+	trashed      []models.Post
+	mode         string
+	strictTitles bool
+	bulkActions  []string
+}
+
+func (s *fakePostService) WithContext(ctx context.Context) services.PostService {
+	return s
+}
+
+// Create flags titles already taken by a post, refusing them when
+// strictTitles is set and warning otherwise
+func (s *fakePostService) Create(req *models.CreatePostRequest, authorID uint, authorRole string) (*models.Post, []models.Warning, error) {
+	post := &models.Post{ID: uint(len(s.posts) + 1), Title: req.Title, AuthorID: authorID}
+	for _, existing := range s.posts {
+		if strings.EqualFold(existing.Title, req.Title) {
+			if s.strictTitles {
+				return nil, nil, services.ErrDuplicateTitle
+			}
+			return post, []models.Warning{{Code: models.WarningDuplicateTitle, Message: "Another post is already titled \"" + existing.Title + "\""}}, nil
+		}
+	}
+	return post, nil, nil
+}
+
+func (s *fakePostService) Search(req *models.PostSearchRequest, viewerID uint, viewerRole string) ([]models.Post, int64, string, error) {
+	var matches []models.Post
+	for _, post := range s.posts {
+		if req.Query == "" || strings.Contains(strings.ToLower(post.Title), strings.ToLower(req.Query)) {
+			matches = append(matches, post)
+		}
+	}
+
+	mode := models.SearchModeNone
+	if req.Query != "" {
+		mode = s.mode
+	}
+
+	start := (req.Page - 1) * req.Limit
+	if start > len(matches) {
+		start = len(matches)
+	}
+	end := start + req.Limit
+	if end > len(matches) {
+		end = len(matches)
+	}
+	return matches[start:end], int64(len(matches)), mode, nil
+}
+
+func (s *fakePostService) GetByID(id uint) (*models.Post, error) {
+	for _, post := range s.posts {
+		if post.ID == id {
+			return &post, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (s *fakePostService) GetBySlug(slug string) (*models.Post, error) {
+	for _, post := range s.posts {
+		if post.Slug == slug {
+			return &post, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+// GetByAuthor pages through the author's posts, hiding drafts from anyone
+// but the author
+func (s *fakePostService) GetByIDs(ids []uint, viewerID uint, viewerRole string) ([]models.Post, error) {
+	var posts []models.Post
+	for _, id := range ids {
+		for _, post := range s.posts {
+			if post.ID == id && services.CanViewPost(&post, viewerID, viewerRole) {
+				posts = append(posts, post)
+			}
+		}
+	}
+	return posts, nil
+}
+
+func (s *fakePostService) GetByAuthor(authorID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Post, int64, error) {
+	var matches []models.Post
+	for _, post := range s.posts {
+		if post.AuthorID == authorID && (post.Status == "published" || viewerID == authorID) {
+			matches = append(matches, post)
+		}
+	}
+
+	start := (page - 1) * perPage
+	if start > len(matches) {
+		start = len(matches)
+	}
+	end := start + perPage
+	if end > len(matches) {
+		end = len(matches)
+	}
+	return matches[start:end], int64(len(matches)), nil
+}
+
+func (s *fakePostService) ListTrashed(page, perPage int) ([]models.Post, int64, error) {
+	return s.trashed, int64(len(s.trashed)), nil
+}
+
+// Restore moves a post from trashed back to posts
+func (s *fakePostService) Restore(id uint) (*models.Post, error) {
+	for i, post := range s.trashed {
+		if post.ID == id {
+			s.trashed = append(s.trashed[:i], s.trashed[i+1:]...)
+			post.DeletedAt = gorm.DeletedAt{}
+			s.posts = append(s.posts, post)
+			return &post, nil
+		}
+	}
+	return nil, services.ErrPostNotTrashed
+}
+
+// Bulk applies every action, recording the ones it was asked for
+func (s *fakePostService) Bulk(req *models.BulkPostRequest) (*models.BulkPostResponse, error) {
+	s.bulkActions = append(s.bulkActions, req.Action)
+	response := &models.BulkPostResponse{Action: req.Action}
+	for _, id := range req.IDs {
+		response.Results = append(response.Results, models.BulkPostResult{ID: id, Status: models.BulkItemApplied})
+	}
+	return response, nil
+}
+
+// fakePostStatsService counts recorded views and serves stats for post 1,
+// owned by user 7
+type fakePostStatsService struct {
+	services.PostStatsService
+	viewed []uint
+}
+
+func (s *fakePostStatsService) RecordView(ctx context.Context, post *models.Post, viewerID uint) uint {
+	s.viewed = append(s.viewed, post.ID)
+	return uint(len(s.viewed))
+}
+
+func (s *fakePostStatsService) GetStats(ctx context.Context, postID, viewerID uint, viewerRole string) (*models.PostStats, error) {
+	if postID != 1 {
+		return nil, services.ErrPostStatsNotFound
+	}
+	if viewerRole != "admin" && viewerID != 7 {
+		return nil, services.ErrPostStatsForbidden
+	}
+	return &models.PostStats{PostID: postID, Views: 12}, nil
+}
+
+func newSearchRouter(mode string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakePostService{mode: mode}
+	for i := 1; i <= 25; i++ {
+		service.posts = append(service.posts, models.Post{ID: uint(i), Title: fmt.Sprintf("Golang tips %d", i), Status: "published"})
+	}
+	for i := 26; i <= 30; i++ {
+		service.posts = append(service.posts, models.Post{ID: uint(i), Title: fmt.Sprintf("Vue recipes %d", i), Status: "published"})
+	}
+
+	router := gin.New()
+	router.GET("/posts", NewPostHandler(service, &fakePostStatsService{}).List)
+	return router
+}
+
+func searchPosts(t *testing.T, router *gin.Engine, query string) models.PaginatedAPIResponse {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/posts?"+query, nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PaginatedAPIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func TestPostHandler_SearchPagination(t *testing.T) {
+	t.Run("total counts every match, not just the page", func(t *testing.T) {
+		router := newSearchRouter(models.SearchModeFullText)
+
+		response := searchPosts(t, router, "q=golang&page=2&limit=10")
+
+		assert.True(t, response.Success)
+		assert.Len(t, response.Data, 10)
+		assert.Equal(t, models.MetaData{
+			Page:       2,
+			Limit:      10,
+			Total:      25,
+			TotalPages: 3,
+			SearchMode: models.SearchModeFullText,
+		}, response.Meta)
+	})
+
+	t.Run("last page is partial", func(t *testing.T) {
+		router := newSearchRouter(models.SearchModeFullText)
+
+		response := searchPosts(t, router, "q=golang&page=3&limit=10")
+
+		assert.Len(t, response.Data, 5)
+		assert.Equal(t, int64(25), response.Meta.Total)
+	})
+
+	t.Run("search mode reports the LIKE fallback", func(t *testing.T) {
+		router := newSearchRouter(models.SearchModeLike)
+
+		response := searchPosts(t, router, "q=vue")
+
+		assert.Equal(t, int64(5), response.Meta.Total)
+		assert.Equal(t, models.SearchModeLike, response.Meta.SearchMode)
+	})
+
+	t.Run("listing without a query reports no search", func(t *testing.T) {
+		router := newSearchRouter(models.SearchModeFullText)
+
+		response := searchPosts(t, router, "")
+
+		assert.Equal(t, int64(30), response.Meta.Total)
+		assert.Equal(t, 1, response.Meta.Page)
+		assert.Equal(t, 10, response.Meta.Limit)
+		assert.Equal(t, models.SearchModeNone, response.Meta.SearchMode)
+	})
+}
+
+func TestPostHandler_ListSummaryMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakePostService{posts: []models.Post{
+		{ID: 1, Title: "Golang tips", Excerpt: "Short", Content: "A very long body", Status: "published"},
+	}}
+	handler := NewPostHandler(service, &fakePostStatsService{})
+
+	router := gin.New()
+	router.GET("/posts", handler.List)
+	router.GET("/posts/:id", handler.GetByID)
+
+	get := func(t *testing.T, path string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body
+	}
+	firstPost := func(body map[string]interface{}) map[string]interface{} {
+		posts := body["data"].([]interface{})
+		require.Len(t, posts, 1)
+		return posts[0].(map[string]interface{})
+	}
+
+	t.Run("lists omit content by default", func(t *testing.T) {
+		post := firstPost(get(t, "/posts"))
+
+		assert.NotContains(t, post, "content")
+		assert.Equal(t, "Golang tips", post["title"])
+		assert.Equal(t, "Short", post["excerpt"])
+	})
+
+	t.Run("fields=full includes content", func(t *testing.T) {
+		post := firstPost(get(t, "/posts?fields=full"))
+
+		assert.Equal(t, "A very long body", post["content"])
+	})
+
+	t.Run("detail endpoints are unaffected", func(t *testing.T) {
+		post := get(t, "/posts/1")["data"].(map[string]interface{})
+
+		assert.Equal(t, "A very long body", post["content"])
+	})
+}
+
+func TestPostHandler_SparseFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakePostService{posts: []models.Post{{
+		ID: 1, Title: "Golang tips", Slug: "golang-tips", Content: "A very long body", Status: "published",
+		Author:   &models.User{ID: 7, Username: "gopher", Name: "Gopher", Email: "gopher@example.com"},
+		Category: &models.Category{ID: 3, Name: "Go", Slug: "go"},
+	}}}
+	handler := NewPostHandler(service, &fakePostStatsService{})
+
+	newRouter := func(strict bool) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) { c.Set(utils.FieldsStrictKey, strict) })
+		router.GET("/posts", handler.List)
+		router.GET("/posts/:id", handler.GetByID)
+		return router
+	}
+	get := func(t *testing.T, router *gin.Engine, path string, status int) map[string]interface{} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, status, w.Code, w.Body.String())
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body
+	}
+
+	t.Run("detail returns only the picked fields, including nested ones", func(t *testing.T) {
+		post := get(t, newRouter(false), "/posts/1?fields=id,title,slug,author.name", http.StatusOK)["data"]
+
+		assert.Equal(t, map[string]interface{}{
+			"id":     float64(1),
+			"title":  "Golang tips",
+			"slug":   "golang-tips",
+			"author": map[string]interface{}{"name": "Gopher"},
+		}, post)
+	})
+
+	t.Run("lists project every post and may pick content", func(t *testing.T) {
+		posts := get(t, newRouter(false), "/posts?fields=title,content", http.StatusOK)["data"]
+
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"title": "Golang tips", "content": "A very long body"},
+		}, posts)
+	})
+
+	t.Run("a relation on its own picks its exposed fields", func(t *testing.T) {
+		post := get(t, newRouter(false), "/posts/1?fields=author", http.StatusOK)["data"]
+
+		assert.Equal(t, map[string]interface{}{
+			"author": map[string]interface{}{"id": float64(7), "username": "gopher", "name": "Gopher"},
+		}, post)
+	})
+
+	t.Run("fields that are not exposed are ignored by default", func(t *testing.T) {
+		post := get(t, newRouter(false), "/posts/1?fields=id,author.email,secret", http.StatusOK)["data"]
+
+		assert.Equal(t, map[string]interface{}{"id": float64(1)}, post)
+	})
+
+	t.Run("strict mode rejects fields that are not exposed", func(t *testing.T) {
+		body := get(t, newRouter(true), "/posts/1?fields=id,author.email", http.StatusBadRequest)
+
+		assert.Contains(t, body["error"], "author.email")
+	})
+
+	t.Run("strict mode accepts exposed fields", func(t *testing.T) {
+		post := get(t, newRouter(true), "/posts/1?fields=id,category.slug", http.StatusOK)["data"]
+
+		assert.Equal(t, map[string]interface{}{
+			"id":       float64(1),
+			"category": map[string]interface{}{"slug": "go"},
+		}, post)
+	})
+}
+
+func TestPostHandler_GetByAuthorMeta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakePostService{}
+	for i := 1; i <= 23; i++ {
+		service.posts = append(service.posts, models.Post{ID: uint(i), AuthorID: 7, Title: fmt.Sprintf("Post %d", i), Status: "published"})
+	}
+	for i := 24; i <= 30; i++ {
+		service.posts = append(service.posts, models.Post{ID: uint(i), AuthorID: 7, Title: fmt.Sprintf("Draft %d", i), Status: "draft"})
+	}
+	handler := NewPostHandler(service, &fakePostStatsService{})
+
+	router := gin.New()
+	router.GET("/posts/author/:author_id", func(c *gin.Context) {
+		// Stand-in for OptionalAuthMiddleware
+		if c.Query("as") == "author" {
+			c.Set("user_id", uint(7))
+			c.Set("user_role", "author")
+		}
+		handler.GetByAuthor(c)
+	})
+
+	get := func(t *testing.T, query string) models.PaginatedAPIResponse {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/author/7?"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.PaginatedAPIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("anonymous meta counts published posts only", func(t *testing.T) {
+		response := get(t, "page=3&per_page=10")
+
+		assert.True(t, response.Success)
+		assert.Len(t, response.Data, 3)
+		assert.Equal(t, models.MetaData{Page: 3, Limit: 10, Total: 23, TotalPages: 3}, response.Meta)
+	})
+
+	t.Run("the author's own drafts are counted for them", func(t *testing.T) {
+		response := get(t, "page=1&per_page=10&as=author")
+
+		assert.Len(t, response.Data, 10)
+		assert.Equal(t, models.MetaData{Page: 1, Limit: 10, Total: 30, TotalPages: 3}, response.Meta)
+	})
+}
+
+func TestPostHandler_Trash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deletedAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	service := &fakePostService{trashed: []models.Post{
+		{ID: 4, Title: "Binned", Status: "published", DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}},
+	}}
+	handler := NewPostHandler(service, &fakePostStatsService{})
+
+	router := gin.New()
+	router.GET("/admin/posts/trash", handler.ListTrash)
+	router.POST("/admin/posts/:id/restore", handler.Restore)
+
+	t.Run("the trash lists when each post was deleted", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/posts/trash", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data []map[string]interface{} `json:"data"`
+			Meta models.MetaData          `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, "Binned", response.Data[0]["title"])
+		assert.Equal(t, "2026-05-01T12:00:00Z", response.Data[0]["deleted_at"])
+		assert.Equal(t, int64(1), response.Meta.Total)
+	})
+
+	restore := func(id string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/posts/"+id+"/restore", nil))
+		return w.Code
+	}
+
+	t.Run("restoring a trashed post", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, restore("4"))
+		assert.Empty(t, service.trashed)
+	})
+
+	t.Run("posts outside the trash are not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, restore("4"))
+		assert.Equal(t, http.StatusBadRequest, restore("abc"))
+	})
+}
+
+func TestPostHandler_BulkDeleteIsForAdmins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &fakePostService{}
+	router := gin.New()
+	router.POST("/posts/bulk", func(c *gin.Context) {
+		// Stand-in for AuthMiddleware
+		c.Set("user_role", c.Query("role"))
+	}, NewPostHandler(service, &fakePostStatsService{}).Bulk)
+
+	bulk := func(role, action string) int {
+		w := httptest.NewRecorder()
+		body := `{"action":"` + action + `","ids":[1,2]}`
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts/bulk?role="+role, strings.NewReader(body)))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, bulk("editor", models.BulkPostPublish))
+	assert.Equal(t, http.StatusOK, bulk("editor", models.BulkPostArchive))
+	assert.Equal(t, http.StatusForbidden, bulk("editor", models.BulkPostDelete))
+	assert.Equal(t, http.StatusOK, bulk("admin", models.BulkPostDelete))
+	assert.Equal(t, []string{models.BulkPostPublish, models.BulkPostArchive, models.BulkPostDelete}, service.bulkActions)
+}
+
+func TestPostHandler_Stats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewPostHandler(&fakePostService{}, &fakePostStatsService{})
+
+	router := gin.New()
+	router.GET("/posts/:id/stats", func(c *gin.Context) {
+		// Stand-in for AuthMiddleware
+		c.Set("user_id", uint(7))
+		c.Set("user_role", c.Query("role"))
+		if c.Query("as") == "other" {
+			c.Set("user_id", uint(8))
+		}
+		handler.Stats(c)
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("the owner gets the stats", func(t *testing.T) {
+		w := get("/posts/1/stats?role=author")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data models.PostStats `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(12), response.Data.Views)
+	})
+
+	t.Run("admins get any post's stats", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get("/posts/1/stats?role=admin&as=other").Code)
+	})
+
+	t.Run("other users are forbidden", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, get("/posts/1/stats?role=author&as=other").Code)
+	})
+
+	t.Run("unknown posts are not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/posts/2/stats?role=author").Code)
+	})
+
+	t.Run("invalid ids are rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/posts/abc/stats?role=author").Code)
+	})
+}
+
+func TestPostHandler_ReadsRecordViews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stats := &fakePostStatsService{}
+	handler := NewPostHandler(&fakePostService{posts: []models.Post{{ID: 1, Status: "published"}}}, stats)
+
+	router := gin.New()
+	router.GET("/posts/:id", handler.GetByID)
+
+	for _, path := range []string{"/posts/1", "/posts/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, []uint{1}, stats.viewed)
+}
+
+func TestPostHandler_View(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stats := &fakePostStatsService{}
+	handler := NewPostHandler(&fakePostService{posts: []models.Post{
+		{ID: 1, Status: "published"},
+		{ID: 2, AuthorID: 7, Status: "draft"},
+	}}, stats)
+
+	router := gin.New()
+	router.POST("/posts/:id/view", func(c *gin.Context) {
+		// Stand-in for OptionalAuthMiddleware
+		if c.Query("as") == "author" {
+			c.Set("user_id", uint(7))
+			c.Set("user_role", "author")
+		}
+		handler.View(c)
+	})
+	view := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		return w
+	}
+
+	w := view("/posts/1/view")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			ViewCount uint `json:"view_count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, uint(1), response.Data.ViewCount)
+
+	assert.Equal(t, http.StatusNotFound, view("/posts/2/view?as=author").Code, "drafts are not counted")
+	assert.Equal(t, http.StatusNotFound, view("/posts/3/view").Code)
+	assert.Equal(t, http.StatusBadRequest, view("/posts/abc/view").Code)
+	// Views go through the same path as reads
+	assert.Equal(t, []uint{1}, stats.viewed)
+}
+
+func TestPostHandler_GetByIdentifier(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &fakePostService{posts: []models.Post{
+		{ID: 1, Slug: "hello-world", Title: "Hello", AuthorID: 7, Status: "published"},
+		{ID: 2, Slug: "work-in-progress", Title: "Draft", AuthorID: 7, Status: "draft"},
+	}}
+	handler := NewPostHandler(service, &fakePostStatsService{})
+
+	router := gin.New()
+	router.GET("/posts/:id", func(c *gin.Context) {
+		// Stand-in for OptionalAuthMiddleware
+		if c.Query("as") == "author" {
+			c.Set("user_id", uint(7))
+			c.Set("user_role", "author")
+		}
+		handler.GetByID(c)
+	})
+
+	get := func(t *testing.T, path string) (int, models.Post) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var response struct {
+			Data models.Post `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response.Data
+	}
+
+	t.Run("the same post is found by id and by slug", func(t *testing.T) {
+		status, byID := get(t, "/posts/1")
+		require.Equal(t, http.StatusOK, status)
+		status, bySlug := get(t, "/posts/hello-world")
+		require.Equal(t, http.StatusOK, status)
+
+		assert.Equal(t, byID, bySlug)
+		assert.Equal(t, uint(1), bySlug.ID)
+	})
+
+	t.Run("drafts are hidden from other viewers on both paths", func(t *testing.T) {
+		for _, path := range []string{"/posts/2", "/posts/work-in-progress"} {
+			status, _ := get(t, path)
+			assert.Equal(t, http.StatusNotFound, status, path)
+
+			status, post := get(t, path+"?as=author")
+			assert.Equal(t, http.StatusOK, status, path)
+			assert.Equal(t, uint(2), post.ID)
+		}
+	})
+
+	t.Run("unknown identifiers are not found", func(t *testing.T) {
+		for _, path := range []string{"/posts/99", "/posts/no-such-post"} {
+			status, _ := get(t, path)
+			assert.Equal(t, http.StatusNotFound, status, path)
+		}
+	})
+}
+
+func TestPostHandler_CreateDuplicateTitle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	create := func(t *testing.T, strict bool, title string) (*httptest.ResponseRecorder, models.APIResponse) {
+		service := &fakePostService{posts: []models.Post{{ID: 1, Title: "Hello World"}}, strictTitles: strict}
+		handler := NewPostHandler(service, &fakePostStatsService{})
+		router := gin.New()
+		router.POST("/posts", func(c *gin.Context) {
+			// Stand-in for AuthMiddleware
+			c.Set("user_id", uint(7))
+			c.Set("user_role", "author")
+			handler.Create(c)
+		})
+
+		body := fmt.Sprintf(`{"title":%q,"content":%q,"category_id":1}`, title, strings.Repeat("Post content. ", 5))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body)))
+
+		var response models.APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+
+	t.Run("duplicates are created with a warning", func(t *testing.T) {
+		w, response := create(t, false, "hello world")
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		require.Len(t, response.Warnings, 1)
+		assert.Equal(t, models.WarningDuplicateTitle, response.Warnings[0].Code)
+	})
+
+	t.Run("unique titles carry no warnings", func(t *testing.T) {
+		w, _ := create(t, false, "Something new")
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NotContains(t, w.Body.String(), "warnings")
+	})
+
+	t.Run("strict mode answers 409", func(t *testing.T) {
+		w, response := create(t, true, "Hello World")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.False(t, response.Success)
+	})
+}
+
+// recentPostStatsService keeps each user's recently read posts, most recent
+// first
+type recentPostStatsService struct {
+	fakePostStatsService
+	recent map[uint][]uint
+}
+
+func (s *recentPostStatsService) RecordView(ctx context.Context, post *models.Post, viewerID uint) uint {
+	if viewerID == 0 {
+		return 0
+	}
+	ids := []uint{post.ID}
+	for _, id := range s.recent[viewerID] {
+		if id != post.ID {
+			ids = append(ids, id)
+		}
+	}
+	s.recent[viewerID] = ids
+	return 0
+}
+
+func (s *recentPostStatsService) RecentlyViewed(ctx context.Context, userID uint) ([]uint, error) {
+	return s.recent[userID], nil
+}
+
+func TestPostHandler_RecentlyViewed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakePostService{posts: []models.Post{
+		{ID: 1, Slug: "first", Title: "First", Status: "published"},
+		{ID: 2, Slug: "second", Title: "Second", Status: "published"},
+		{ID: 3, Slug: "third", Title: "Third", Status: "published"},
+	}}
+	stats := &recentPostStatsService{recent: make(map[uint][]uint)}
+	handler := NewPostHandler(service, stats)
+
+	asReader := func(c *gin.Context) {
+		// Stand-in for the auth middlewares
+		if c.Query("as") != "" {
+			c.Set("user_id", uint(7))
+			c.Set("user_role", "author")
+		}
+	}
+	router := gin.New()
+	router.GET("/posts/slug/:slug", asReader, handler.GetBySlug)
+	router.GET("/me/recent", asReader, handler.RecentlyViewed)
+
+	get := func(t *testing.T, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+	recentTitles := func(t *testing.T, query string) []string {
+		var response struct {
+			Data []models.PostSummary `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(get(t, "/me/recent?as=reader"+query).Body.Bytes(), &response))
+		var titles []string
+		for _, post := range response.Data {
+			titles = append(titles, post.Title)
+		}
+		return titles
+	}
+
+	for _, slug := range []string{"first", "second", "third", "first"} {
+		get(t, "/posts/slug/"+slug+"?as=reader")
+	}
+	get(t, "/posts/slug/second")
+
+	t.Run("reads are listed most recent first without duplicates", func(t *testing.T) {
+		assert.Equal(t, []string{"First", "Third", "Second"}, recentTitles(t, ""))
+	})
+
+	t.Run("limit picks how many are returned", func(t *testing.T) {
+		assert.Equal(t, []string{"First", "Third"}, recentTitles(t, "&limit=2"))
+	})
+
+	t.Run("posts unpublished since are left out", func(t *testing.T) {
+		service.posts[2].Status = "draft"
+		assert.Equal(t, []string{"First", "Second"}, recentTitles(t, ""))
+	})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteInfo is one registered route
+type RouteInfo struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+}
+
+// RoutesHandler lists the routes registered on the router, for checking
+// which endpoints and middleware are really in place
+type RoutesHandler struct {
+	router  *gin.Engine
+	enabled bool
+}
+
+func NewRoutesHandler(router *gin.Engine, enabled bool) *RoutesHandler {
+	return &RoutesHandler{
+		router:  router,
+		enabled: enabled,
+	}
+}
+
+// Enabled reports whether the route list should be served, see
+// config.AppConfig.ExposeRoutes
+func (h *RoutesHandler) Enabled() bool {
+	return h.enabled
+}
+
+// List returns the registered routes ordered by path, then method
+func (h *RoutesHandler) List(c *gin.Context) {
+	registered := h.router.Routes()
+	routes := make([]RouteInfo, len(registered))
+	for i, route := range registered {
+		routes[i] = RouteInfo{Method: route.Method, Path: route.Path, Handler: route.Handler}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Routes retrieved successfully", routes))
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/services"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatsHandler struct {
+	statsService services.StatsService
+}
+
+func NewStatsHandler(statsService services.StatsService) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+	}
+}
+
+// Get returns user, post and comment counts and the newest posts
+func (h *StatsHandler) Get(c *gin.Context) {
+	stats, err := h.statsService.GetStats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve statistics", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Statistics retrieved successfully", stats))
+}
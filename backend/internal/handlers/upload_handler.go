@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"backend/internal/config"
+	"backend/internal/repositories"
 	"backend/internal/services"
+	"backend/pkg/metrics"
 	"backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -15,12 +18,14 @@ import (
 
 type UploadHandler struct {
 	storageService services.StorageService
+	uploadRepo     repositories.FileUploadRepository
 	config         *config.Config
 }
 
-func NewUploadHandler(storageService services.StorageService, cfg *config.Config) *UploadHandler {
+func NewUploadHandler(storageService services.StorageService, uploadRepo repositories.FileUploadRepository, cfg *config.Config) *UploadHandler {
 	return &UploadHandler{
 		storageService: storageService,
+		uploadRepo:     uploadRepo,
 		config:         cfg,
 	}
 }
@@ -61,20 +66,38 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 	}
 
 	// Upload file using storage service
+	start := time.Now()
 	uploadResponse, err := h.storageService.UploadFile(fileHeader, userID)
+	metrics.RecordUpload(h.config.Storage.Driver, uploadResult(err), fileHeader.Size, time.Since(start))
 	if err != nil {
-		// Check if it's a validation error
-		if strings.Contains(err.Error(), "exceeds maximum allowed size") {
+		switch {
+		case errors.Is(err, services.ErrFileTooLarge):
 			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error(), "ERR_FILE_TOO_LARGE")
-			return
+		case errors.Is(err, services.ErrInvalidImage):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), "ERR_UPLOAD_FAILED")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to store file", "ERR_STORAGE_FAILED")
 		}
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error(), "ERR_UPLOAD_FAILED")
 		return
 	}
 
 	c.JSON(http.StatusOK, uploadResponse)
 }
 
+// uploadResult classifies an upload error for the upload metrics
+func uploadResult(err error) string {
+	switch {
+	case err == nil:
+		return metrics.UploadSuccess
+	case errors.Is(err, services.ErrFileTooLarge):
+		return metrics.UploadTooLarge
+	case errors.Is(err, services.ErrInvalidImage):
+		return metrics.UploadValidationError
+	default:
+		return metrics.UploadStorageError
+	}
+}
+
 // GetUploadInfo provides information about upload requirements
 // @Summary Get upload information
 // @Description Get information about file upload requirements and limits
@@ -140,12 +163,45 @@ func (h *UploadHandler) DeleteImage(c *gin.Context) {
 		return
 	}
 
+	// Files uploaded before uploads were recorded have no row
+	if upload, err := h.uploadRepo.GetByFilename(filename); err == nil {
+		if err := h.uploadRepo.Delete(upload.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to delete file record", err.Error()))
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "File deleted successfully",
 	})
 }
 
+// ListMine returns the current user's uploads, newest first
+// @Summary List my uploads
+// @Description List the files uploaded by the authenticated user
+// @Tags uploads
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} models.APIResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /uploads/mine [get]
+func (h *UploadHandler) ListMine(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	page, perPage := utils.GetPaginationParams(c)
+
+	uploads, total, err := h.uploadRepo.GetByUser(userID.(uint), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve uploads", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Uploads retrieved successfully",
+		utils.PaginationResponse(uploads, total, page, perPage)))
+}
+
 // ServeLocalImage serves local images (only for local storage)
 // @Summary Serve local image
 // @Description Serve a locally stored image file
@@ -190,6 +246,7 @@ func SetupUploadRoutes(router *gin.Engine, uploadHandler *UploadHandler, authMid
 		uploadGroup.GET("/:filename", uploadHandler.ServeLocalImage)
 		
 		// Protected routes (require authentication)
+		uploadGroup.GET("/mine", authMiddleware, uploadHandler.ListMine)
 		uploadGroup.POST("/images", authMiddleware, uploadHandler.UploadImage)
 		uploadGroup.DELETE("/images/:filename", authMiddleware, uploadHandler.DeleteImage)
 	}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeStorageService accepts uploads without touching disk, failing with err
+// when it is set
+type fakeStorageService struct {
+	services.StorageService
+	err error
+}
+
+func (s *fakeStorageService) UploadFile(file *multipart.FileHeader, userID uint) (*models.UploadResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.UploadResponse{Success: true, Filename: file.Filename, Size: file.Size}, nil
+}
+
+func newUploadRouter(storage services.StorageService, driver string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewUploadHandler(storage, nil, &config.Config{Storage: config.StorageConfig{Driver: driver}})
+
+	router := gin.New()
+	router.POST("/uploads/images", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		c.Next()
+	}, handler.UploadImage)
+	return router
+}
+
+func newUploadRequest(t *testing.T, filename string, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="image"; filename="%s"`, filename))
+	header.Set("Content-Type", "image/png")
+	part, err := writer.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/uploads/images", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// metricValue reads a counter from the default registry, returning zero when
+// no series with the given labels has been recorded yet
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, pair := range metric.GetLabel() {
+				if labels[pair.GetName()] == pair.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestUploadHandler_UploadImageMetrics(t *testing.T) {
+	t.Run("a successful upload counts the upload and its bytes", func(t *testing.T) {
+		success := map[string]string{"driver": "local", "result": "success"}
+		uploadsBefore := metricValue(t, "blogcms_uploads_total", success)
+		bytesBefore := metricValue(t, "blogcms_upload_bytes_total", map[string]string{"driver": "local"})
+
+		w := httptest.NewRecorder()
+		newUploadRouter(&fakeStorageService{}, "local").ServeHTTP(w, newUploadRequest(t, "photo.png", []byte("png-bytes")))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, uploadsBefore+1, metricValue(t, "blogcms_uploads_total", success))
+		assert.Equal(t, bytesBefore+float64(len("png-bytes")), metricValue(t, "blogcms_upload_bytes_total", map[string]string{"driver": "local"}))
+	})
+
+	t.Run("rejected uploads record why they failed", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			err    error
+			status int
+			result string
+		}{
+			{"too large", fmt.Errorf("check size: %w", services.ErrFileTooLarge), http.StatusRequestEntityTooLarge, "too_large"},
+			{"wrong type", fmt.Errorf("check type: %w", services.ErrInvalidImage), http.StatusBadRequest, "validation_error"},
+			{"storage failure", errors.New("access denied"), http.StatusInternalServerError, "storage_error"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				failure := map[string]string{"driver": "s3", "result": tt.result}
+				uploadsBefore := metricValue(t, "blogcms_uploads_total", failure)
+				bytesBefore := metricValue(t, "blogcms_upload_bytes_total", map[string]string{"driver": "s3"})
+
+				w := httptest.NewRecorder()
+				newUploadRouter(&fakeStorageService{err: tt.err}, "s3").ServeHTTP(w, newUploadRequest(t, "photo.png", []byte("png-bytes")))
+
+				assert.Equal(t, tt.status, w.Code)
+				assert.Equal(t, uploadsBefore+1, metricValue(t, "blogcms_uploads_total", failure))
+				assert.Equal(t, bytesBefore, metricValue(t, "blogcms_upload_bytes_total", map[string]string{"driver": "s3"}))
+			})
+		}
+	})
+}
+
+// fakeFileUploadRepo keeps upload records in memory
+type fakeFileUploadRepo struct {
+	repositories.FileUploadRepository
+	uploads []models.FileUpload
+}
+
+func (r *fakeFileUploadRepo) GetByFilename(filename string) (*models.FileUpload, error) {
+	for i := range r.uploads {
+		if r.uploads[i].Filename == filename {
+			return &r.uploads[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeFileUploadRepo) GetByUser(userID uint, page, perPage int) ([]models.FileUpload, int64, error) {
+	var mine []models.FileUpload
+	for _, upload := range r.uploads {
+		if upload.UserID == userID {
+			mine = append(mine, upload)
+		}
+	}
+	return mine, int64(len(mine)), nil
+}
+
+func (r *fakeFileUploadRepo) Delete(id uint) error {
+	for i, upload := range r.uploads {
+		if upload.ID == id {
+			r.uploads = append(r.uploads[:i], r.uploads[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *fakeStorageService) DeleteFile(filename string) error {
+	return s.err
+}
+
+func TestUploadHandler_ListMine(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	uploadRepo := &fakeFileUploadRepo{uploads: []models.FileUpload{
+		{ID: 1, Filename: "a.png", UserID: 1},
+		{ID: 2, Filename: "b.png", UserID: 2},
+	}}
+	handler := NewUploadHandler(&fakeStorageService{}, uploadRepo, &config.Config{})
+
+	router := gin.New()
+	router.GET("/uploads/mine", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		c.Next()
+	}, handler.ListMine)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/uploads/mine", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data struct {
+			Data  []models.FileUpload `json:"data"`
+			Total int64               `json:"total"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(1), resp.Data.Total)
+	require.Len(t, resp.Data.Data, 1)
+	assert.Equal(t, "a.png", resp.Data.Data[0].Filename)
+}
+
+func TestUploadHandler_DeleteImageRemovesRecord(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	uploadRepo := &fakeFileUploadRepo{uploads: []models.FileUpload{{ID: 1, Filename: "a.png", UserID: 1}}}
+	handler := NewUploadHandler(&fakeStorageService{}, uploadRepo, &config.Config{})
+
+	router := gin.New()
+	router.DELETE("/uploads/images/:filename", func(c *gin.Context) {
+		c.Set("user_role", "admin")
+		c.Next()
+	}, handler.DeleteImage)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/uploads/images/a.png", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, uploadRepo.uploads)
+}
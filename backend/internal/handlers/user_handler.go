@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandler serves the admin user management endpoints and public author
+// profiles
+type UserHandler struct {
+	userService services.UserService
+}
+
+func NewUserHandler(userService services.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// List pages through users in ID order, each with their post count. ?q= matches part of the username or
+// email and ?role= keeps only users with that role.
+func (h *UserHandler) List(c *gin.Context) {
+	page, perPage := utils.GetPaginationParams(c)
+
+	filter := models.UserListFilter{
+		Search: c.Query("q"),
+		Role:   c.Query("role"),
+	}
+	switch filter.Role {
+	case "", "admin", "editor", "author":
+	default:
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid role", "role must be admin, editor or author"))
+		return
+	}
+
+	users, total, err := h.userService.WithContext(c.Request.Context()).List(filter, page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to retrieve users", err.Error()))
+		return
+	}
+
+	response := utils.PaginatedAPIResponse(users, total, page, perPage, "Users retrieved successfully")
+	c.JSON(http.StatusOK, utils.NegotiatePagination(c, response))
+}
+
+func (h *UserHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid user ID", err.Error()))
+		return
+	}
+
+	user, err := h.userService.WithContext(c.Request.Context()).GetByID(uint(id))
+	if err != nil {
+		c.JSON(userErrorStatus(err), utils.ErrorResponse("Failed to retrieve user", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("User retrieved successfully", user))
+}
+
+// UpdateRole changes a user's role. Admins cannot demote themselves.
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid user ID", err.Error()))
+		return
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	user, err := h.userService.WithContext(c.Request.Context()).UpdateRole(uint(id), req.Role, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(userErrorStatus(err), utils.ErrorResponse("Failed to update user role", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("User role updated successfully", user))
+}
+
+// Delete soft-deletes a user. Admins cannot delete themselves.
+func (h *UserHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid user ID", err.Error()))
+		return
+	}
+
+	if err := h.userService.WithContext(c.Request.Context()).Delete(uint(id), c.GetUint("user_id")); err != nil {
+		c.JSON(userErrorStatus(err), utils.ErrorResponse("Failed to delete user", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("User deleted successfully", nil))
+}
+
+// GetAuthorProfile returns a user's public profile with their published post
+// count
+func (h *UserHandler) GetAuthorProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid author ID", err.Error()))
+		return
+	}
+
+	profile, err := h.userService.WithContext(c.Request.Context()).AuthorProfile(uint(id))
+	if err != nil {
+		c.JSON(userErrorStatus(err), utils.ErrorResponse("Failed to retrieve author", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse("Author retrieved successfully", profile))
+}
+
+func userErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrOwnAccount):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepo keeps users in memory; deleted users disappear like
+// soft-deleted rows do
+type fakeUserRepo struct {
+	repositories.UserRepository
+	users map[uint]*models.User
+}
+
+func (r *fakeUserRepo) WithContext(ctx context.Context) repositories.UserRepository {
+	return r
+}
+
+func (r *fakeUserRepo) GetByID(id uint) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) Update(user *models.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(id uint) error {
+	delete(r.users, id)
+	return nil
+}
+
+func (r *fakeUserRepo) List(filter models.UserListFilter, page, perPage int) ([]models.User, int64, error) {
+	var matches []models.User
+	for _, user := range r.users {
+		if filter.Search != "" && !strings.Contains(user.Username, filter.Search) && !strings.Contains(user.Email, filter.Search) {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		matches = append(matches, *user)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+
+	start := (page - 1) * perPage
+	if start > len(matches) {
+		start = len(matches)
+	}
+	end := start + perPage
+	if end > len(matches) {
+		end = len(matches)
+	}
+	return matches[start:end], int64(len(matches)), nil
+}
+
+// fakePostCounter reports how many posts, and how many published posts, each
+// author owns
+type fakePostCounter struct {
+	repositories.PostRepository
+	counts    map[uint]int64
+	published map[uint]int64
+}
+
+func (r *fakePostCounter) WithContext(ctx context.Context) repositories.PostRepository {
+	return r
+}
+
+func (r *fakePostCounter) CountByAuthor(authorID uint, publishedOnly bool) (int64, error) {
+	if publishedOnly {
+		return r.published[authorID], nil
+	}
+	return r.counts[authorID], nil
+}
+
+func (r *fakePostCounter) CountByAuthors(authorIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64)
+	for _, id := range authorIDs {
+		if count, ok := r.counts[id]; ok {
+			counts[id] = count
+		}
+	}
+	return counts, nil
+}
+
+// revokingJWTService records whose sessions were revoked
+type revokingJWTService struct {
+	services.JWTService
+	revoked []uint
+}
+
+func (s *revokingJWTService) RevokeAllUserTokens(userID uint) error {
+	s.revoked = append(s.revoked, userID)
+	return nil
+}
+
+// newUserRouter serves the admin user routes to user 1, with the role given
+// in ?as= (admin by default)
+func newUserRouter(repo *fakeUserRepo, sessions services.JWTService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	posts := &fakePostCounter{
+		counts:    map[uint]int64{2: 4, 5: 1},
+		published: map[uint]int64{2: 3},
+	}
+	handler := NewUserHandler(services.NewUserService(repo, posts, sessions))
+	router := gin.New()
+	router.Use(middleware.PaginationShape(utils.PaginationShapeMeta))
+	admin := router.Group("/admin", func(c *gin.Context) {
+		// Stand in for AuthMiddleware
+		role := c.Query("as")
+		if role == "" {
+			role = "admin"
+		}
+		c.Set("user_id", uint(1))
+		c.Set("user_role", role)
+	}, middleware.AdminOnly())
+	admin.GET("/users", handler.List)
+	admin.GET("/users/:id", handler.GetByID)
+	admin.PUT("/users/:id/role", handler.UpdateRole)
+	admin.DELETE("/users/:id", handler.Delete)
+	router.GET("/authors/:id", handler.GetAuthorProfile)
+	return router
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	repo := &fakeUserRepo{users: map[uint]*models.User{
+		1: {ID: 1, Username: "admin", Email: "admin@example.com", Role: "admin"},
+	}}
+	for i := 2; i <= 12; i++ {
+		role := "author"
+		if i%3 == 0 {
+			role = "editor"
+		}
+		repo.users[uint(i)] = &models.User{ID: uint(i), Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.org", i), Role: role}
+	}
+	return repo
+}
+
+func serveUsers(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUserHandler_List(t *testing.T) {
+	router := newUserRouter(newFakeUserRepo(), &revokingJWTService{})
+
+	list := func(t *testing.T, query string) ([]models.User, models.MetaData) {
+		w := serveUsers(router, http.MethodGet, "/admin/users"+query, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data []models.User   `json:"data"`
+			Meta models.MetaData `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data, resp.Meta
+	}
+	ids := func(users []models.User) []uint {
+		out := make([]uint, len(users))
+		for i, user := range users {
+			out[i] = user.ID
+		}
+		return out
+	}
+
+	t.Run("pages through users in ID order", func(t *testing.T) {
+		users, meta := list(t, "?page=2&per_page=5")
+
+		assert.Equal(t, []uint{6, 7, 8, 9, 10}, ids(users))
+		assert.Equal(t, int64(12), meta.Total)
+		assert.Equal(t, 3, meta.TotalPages)
+	})
+
+	t.Run("searches usernames and emails", func(t *testing.T) {
+		users, _ := list(t, "?q=user1")
+		assert.Equal(t, []uint{10, 11, 12}, ids(users))
+
+		users, _ = list(t, "?q=example.com")
+		assert.Equal(t, []uint{1}, ids(users))
+	})
+
+	t.Run("filters by role", func(t *testing.T) {
+		users, meta := list(t, "?role=editor")
+
+		assert.Equal(t, []uint{3, 6, 9, 12}, ids(users))
+		assert.Equal(t, int64(4), meta.Total)
+	})
+
+	t.Run("reports each user's post count", func(t *testing.T) {
+		users, _ := list(t, "?per_page=5")
+
+		counts := make(map[uint]int64)
+		for _, user := range users {
+			require.NotNil(t, user.PostCount)
+			counts[user.ID] = *user.PostCount
+		}
+		assert.Equal(t, map[uint]int64{1: 0, 2: 4, 3: 0, 4: 0, 5: 1}, counts)
+	})
+
+	t.Run("rejects unknown roles", func(t *testing.T) {
+		w := serveUsers(router, http.MethodGet, "/admin/users?role=owner", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUserHandler_UpdateRole(t *testing.T) {
+	repo := newFakeUserRepo()
+	sessions := &revokingJWTService{}
+	router := newUserRouter(repo, sessions)
+
+	w := serveUsers(router, http.MethodPut, "/admin/users/2/role", `{"role":"editor"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "editor", repo.users[2].Role)
+	assert.Equal(t, []uint{2}, sessions.revoked, "tokens carrying the old role are revoked")
+
+	w = serveUsers(router, http.MethodPut, "/admin/users/2/role", `{"role":"editor"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []uint{2}, sessions.revoked, "an unchanged role keeps the sessions")
+
+	w = serveUsers(router, http.MethodPut, "/admin/users/2/role", `{"role":"owner"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveUsers(router, http.MethodPut, "/admin/users/99/role", `{"role":"editor"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	t.Run("admins cannot demote themselves", func(t *testing.T) {
+		w := serveUsers(router, http.MethodPut, "/admin/users/1/role", `{"role":"author"}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "admin", repo.users[1].Role)
+		assert.NotContains(t, sessions.revoked, uint(1))
+	})
+}
+
+func TestUserHandler_Delete(t *testing.T) {
+	repo := newFakeUserRepo()
+	sessions := &revokingJWTService{}
+	router := newUserRouter(repo, sessions)
+
+	w := serveUsers(router, http.MethodDelete, "/admin/users/2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, repo.users, uint(2))
+	assert.Equal(t, []uint{2}, sessions.revoked)
+
+	w = serveUsers(router, http.MethodGet, "/admin/users/2", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	t.Run("admins cannot delete themselves", func(t *testing.T) {
+		w := serveUsers(router, http.MethodDelete, "/admin/users/1", "")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, repo.users, uint(1))
+	})
+}
+
+func TestUserHandler_NonAdminsAreForbidden(t *testing.T) {
+	repo := newFakeUserRepo()
+	router := newUserRouter(repo, &revokingJWTService{})
+
+	requests := []struct {
+		method, target, body string
+	}{
+		{http.MethodGet, "/admin/users", ""},
+		{http.MethodGet, "/admin/users/2", ""},
+		{http.MethodPut, "/admin/users/2/role", `{"role":"admin"}`},
+		{http.MethodDelete, "/admin/users/2", ""},
+	}
+	for _, role := range []string{"author", "editor"} {
+		for _, r := range requests {
+			t.Run(role+" "+r.method+" "+r.target, func(t *testing.T) {
+				target := r.target + "?as=" + role
+				w := serveUsers(router, r.method, target, r.body)
+				assert.Equal(t, http.StatusForbidden, w.Code)
+			})
+		}
+	}
+	assert.Equal(t, "author", repo.users[2].Role)
+	assert.Contains(t, repo.users, uint(2))
+}
+
+func TestUserHandler_GetAuthorProfile(t *testing.T) {
+	router := newUserRouter(newFakeUserRepo(), &revokingJWTService{})
+
+	t.Run("counts only published posts", func(t *testing.T) {
+		w := serveUsers(router, http.MethodGet, "/authors/2", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data models.AuthorProfile `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "user2", resp.Data.Username)
+		assert.Equal(t, int64(3), resp.Data.PostCount)
+		assert.NotContains(t, w.Body.String(), "user2@example.org")
+	})
+
+	t.Run("authors without published posts count zero", func(t *testing.T) {
+		w := serveUsers(router, http.MethodGet, "/authors/5", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"post_count":0`)
+	})
+
+	t.Run("unknown authors are not found", func(t *testing.T) {
+		w := serveUsers(router, http.MethodGet, "/authors/99", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRefreshTokenRepo struct {
+	repositories.RefreshTokenRepository
+}
+
+func (stubRefreshTokenRepo) Create(token *models.RefreshToken) error {
+	return nil
+}
+
+type stubRevokedTokenRepo struct {
+	repositories.RevokedAccessTokenRepository
+	revoked map[string]bool
+}
+
+func (r stubRevokedTokenRepo) Create(token *models.RevokedAccessToken) error {
+	r.revoked[token.JTI] = true
+	return nil
+}
+
+func (r stubRevokedTokenRepo) IsRevoked(jti string) (bool, error) {
+	return r.revoked[jti], nil
+}
+
+func TestAuthMiddleware_RejectsRevokedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtService := services.NewJWTService(stubRefreshTokenRepo{}, stubRevokedTokenRepo{revoked: map[string]bool{}}, &config.Config{})
+	router := gin.New()
+	router.GET("/me", AuthMiddleware(jwtService), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	get := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	pair, err := jwtService.GenerateTokenPair(&models.User{ID: 1, Role: "author"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, get(pair.AccessToken).Code)
+
+	claims, err := jwtService.ValidateAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, jwtService.RevokeAccessToken(claims))
+	w := get(pair.AccessToken)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	var body models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "ERR_AUTH_TOKEN_REVOKED", body.Code)
+}
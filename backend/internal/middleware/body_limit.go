@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodySize is the body limit BodyLimitMiddleware applies when given
+// none
+const DefaultMaxBodySize = 1 << 20
+
+// bodyLimitExemptPaths keep their own, larger limit: uploads are checked
+// against the storage file size limit instead
+var bodyLimitExemptPaths = []string{
+	"/api/v1/uploads/",
+}
+
+// BodyLimitMiddleware refuses request bodies larger than limit bytes with 413,
+// before a handler tries to decode them. Bodies that declare their length are
+// turned away without being read; others are read up to the limit and
+// replayed to the handler. A limit of 0 or less uses DefaultMaxBodySize.
+func BodyLimitMiddleware(limit int64) gin.HandlerFunc {
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		for _, prefix := range bodyLimitExemptPaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		if c.Request.ContentLength > limit {
+			abortBodyTooLarge(c, limit)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortBodyTooLarge(c, limit)
+				return
+			}
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Error:   "Failed to read request body",
+				Code:    "ERR_INVALID_REQUEST",
+				Details: err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+func abortBodyTooLarge(c *gin.Context, limit int64) {
+	c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+		Success: false,
+		Error:   "Request body too large",
+		Code:    "ERR_BODY_TOO_LARGE",
+		Details: fmt.Sprintf("request bodies may be at most %d bytes", limit),
+	})
+	c.Abort()
+}
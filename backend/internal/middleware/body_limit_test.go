@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bodyLimitTestLimit = 64
+
+// newBodyLimitRouter echoes the body it was given back as the response
+func newBodyLimitRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BodyLimitMiddleware(bodyLimitTestLimit))
+	echo := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Data(http.StatusOK, "application/json", body)
+	}
+	router.POST("/api/v1/posts", echo)
+	router.POST("/api/v1/uploads/images", echo)
+	return router
+}
+
+func sendBody(router *gin.Engine, path, body string, chunked bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if chunked {
+		req.ContentLength = -1
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	router := newBodyLimitRouter()
+	oversized := `{"content":"` + strings.Repeat("a", bodyLimitTestLimit) + `"}`
+
+	t.Run("bodies within the limit reach the handler", func(t *testing.T) {
+		body := `{"title":"Hello"}`
+		w := sendBody(router, "/api/v1/posts", body, false)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("oversized bodies are refused", func(t *testing.T) {
+		w := sendBody(router, "/api/v1/posts", oversized, false)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		var resp models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "ERR_BODY_TOO_LARGE", resp.Code)
+	})
+
+	t.Run("oversized bodies without a length are refused", func(t *testing.T) {
+		w := sendBody(router, "/api/v1/posts", oversized, true)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("chunked bodies within the limit are replayed", func(t *testing.T) {
+		body := `{"title":"Hello"}`
+		w := sendBody(router, "/api/v1/posts", body, true)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("uploads keep their own limit", func(t *testing.T) {
+		w := sendBody(router, "/api/v1/uploads/images", oversized, false)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, oversized, w.Body.String())
+	})
+}
@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCompressionMinSize is the smallest response CompressionMiddleware
+// compresses when given no minimum. Smaller bodies gain too little to be
+// worth the CPU.
+const DefaultCompressionMinSize = 1024
+
+// incompressibleTypes are media types whose content is already compressed.
+// Whole families are matched by their "type/" prefix.
+var incompressibleTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"font/woff2",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-bzip2",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
+
+// CompressionMiddleware compresses responses of at least minSize bytes with
+// gzip, or deflate for clients that only accept that, as the request's
+// Accept-Encoding allows. Responses that already have a Content-Encoding, or
+// whose Content-Type is already compressed, such as images, are sent as they
+// are. A minSize of 0 or less uses DefaultCompressionMinSize.
+func CompressionMiddleware(minSize int) gin.HandlerFunc {
+	if minSize <= 0 {
+		minSize = DefaultCompressionMinSize
+	}
+
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks gzip, then deflate, from an Accept-Encoding
+// header, skipping encodings the client refuses with q=0
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+
+	switch {
+	case accepted["gzip"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressWriter holds back the start of a response until it is known to be
+// at least minSize bytes and of a compressible type, then compresses the
+// rest. Responses that turn out smaller are written unchanged at the end.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf     bytes.Buffer
+	decided bool
+	encoder io.WriteCloser
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow is held back with the body, since compressing changes the
+// headers. Responses without a body are sent when the handler returns.
+func (w *compressWriter) WriteHeaderNow() {
+	if w.decided {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Written reports whether the handler has started the response, even though
+// it may not have reached the client yet
+func (w *compressWriter) Written() bool {
+	return w.decided || w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Size() int {
+	if !w.decided {
+		return w.buf.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+// Flush sends what has been written so far, uncompressed if the response
+// has not yet reached minSize
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return
+		}
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// decide chooses whether to compress, sends the headers and writes out the
+// buffered start of the body
+func (w *compressWriter) decide(largeEnough bool) error {
+	w.decided = true
+
+	header := w.Header()
+	if largeEnough && header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.encoder = w.newEncoder()
+	}
+
+	w.ResponseWriter.WriteHeaderNow()
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+func (w *compressWriter) newEncoder() io.WriteCloser {
+	if w.encoding == "deflate" {
+		return zlib.NewWriter(w.ResponseWriter)
+	}
+	gz := gzipWriters.Get().(*gzip.Writer)
+	gz.Reset(w.ResponseWriter)
+	return &pooledGzipWriter{Writer: gz}
+}
+
+// finish writes out a response that stayed below minSize and closes the
+// encoder of one that did not
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if w.buf.Len() == 0 {
+			return
+		}
+		w.decide(false)
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
+
+// pooledGzipWriter returns its gzip.Writer to the pool once closed
+type pooledGzipWriter struct {
+	*gzip.Writer
+}
+
+func (w *pooledGzipWriter) Close() error {
+	err := w.Writer.Close()
+	gzipWriters.Put(w.Writer)
+	return err
+}
+
+// compressible reports whether a response of contentType is worth
+// compressing. Unlabelled responses are left alone.
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if mediaType == "image/svg+xml" {
+		return true
+	}
+	for _, incompressible := range incompressibleTypes {
+		if mediaType == incompressible || (strings.HasSuffix(incompressible, "/") && strings.HasPrefix(mediaType, incompressible)) {
+			return false
+		}
+	}
+	return true
+}
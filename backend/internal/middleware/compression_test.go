@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const compressionTestMinSize = 256
+
+var largeBody = strings.Repeat("the quick brown fox jumps over the lazy dog ", 50)
+
+func newCompressionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CompressionMiddleware(compressionTestMinSize))
+	router.GET("/large", func(c *gin.Context) {
+		c.String(http.StatusOK, largeBody)
+	})
+	router.GET("/tiny", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(largeBody))
+	})
+	router.GET("/encoded", func(c *gin.Context) {
+		c.Header("Content-Encoding", "br")
+		c.Data(http.StatusOK, "text/plain", []byte(largeBody))
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func sendCompression(router *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	router := newCompressionRouter()
+
+	t.Run("large responses are gzipped", func(t *testing.T) {
+		w := sendCompression(router, "/large", "gzip, deflate, br")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Empty(t, w.Header().Get("Content-Length"))
+		assert.Less(t, w.Body.Len(), len(largeBody))
+
+		reader, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, largeBody, string(body))
+	})
+
+	t.Run("deflate is used when gzip is not accepted", func(t *testing.T) {
+		w := sendCompression(router, "/large", "gzip;q=0, deflate")
+
+		assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+		reader, err := zlib.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, largeBody, string(body))
+	})
+
+	t.Run("responses below the minimum size are sent as they are", func(t *testing.T) {
+		w := sendCompression(router, "/tiny", "gzip")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.JSONEq(t, `{"ok": true}`, w.Body.String())
+	})
+
+	t.Run("clients that do not accept compression get plain responses", func(t *testing.T) {
+		w := sendCompression(router, "/large", "")
+
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, largeBody, w.Body.String())
+	})
+
+	t.Run("images are not recompressed", func(t *testing.T) {
+		w := sendCompression(router, "/image", "gzip")
+
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, largeBody, w.Body.String())
+	})
+
+	t.Run("responses with their own encoding are left alone", func(t *testing.T) {
+		w := sendCompression(router, "/encoded", "gzip")
+
+		assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, largeBody, w.Body.String())
+	})
+
+	t.Run("responses without a body keep their status", func(t *testing.T) {
+		w := sendCompression(router, "/empty", "gzip")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Zero(t, w.Body.Len())
+	})
+}
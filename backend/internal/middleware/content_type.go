@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Media types accepted by the API's endpoints
+const (
+	MediaTypeJSON      = "application/json"
+	MediaTypeMultipart = "multipart/form-data"
+)
+
+// ContentTypeMiddleware rejects request bodies whose Content-Type is not one
+// of allowed with 415, before a handler tries to bind them. Parameters such
+// as charset are ignored. Requests without a body, such as most GETs and
+// DELETEs, are let through whatever their Content-Type.
+func ContentTypeMiddleware(allowed ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength == 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		if err == nil {
+			for _, allowedType := range allowed {
+				if strings.EqualFold(mediaType, allowedType) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{
+			Success: false,
+			Error:   "Unsupported media type",
+			Code:    "ERR_UNSUPPORTED_MEDIA_TYPE",
+			Details: "Content-Type must be " + strings.Join(allowed, " or "),
+		})
+		c.Abort()
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newContentTypeRouter(allowed ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	router.Use(ContentTypeMiddleware(allowed...))
+	router.POST("/items", handler)
+	router.GET("/items", handler)
+	router.DELETE("/items", handler)
+	return router
+}
+
+func sendWithContentType(router *gin.Engine, method, contentType, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "/items", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestContentTypeMiddleware(t *testing.T) {
+	router := newContentTypeRouter(MediaTypeJSON)
+
+	t.Run("a JSON body proceeds", func(t *testing.T) {
+		w := sendWithContentType(router, http.MethodPost, "application/json; charset=utf-8", `{"title":"x"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("a body of another type is rejected with 415", func(t *testing.T) {
+		w := sendWithContentType(router, http.MethodPost, "text/xml", `<title>x</title>`)
+
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		var body models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.False(t, body.Success)
+		assert.Equal(t, "ERR_UNSUPPORTED_MEDIA_TYPE", body.Code)
+		assert.Equal(t, "Content-Type must be application/json", body.Details)
+	})
+
+	t.Run("a body without a content type is rejected", func(t *testing.T) {
+		w := sendWithContentType(router, http.MethodPost, "", `{"title":"x"}`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("requests without a body are let through", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodDelete, http.MethodPost} {
+			w := sendWithContentType(router, method, "text/plain", "")
+			assert.Equal(t, http.StatusOK, w.Code, method)
+		}
+	})
+
+	t.Run("uploads accept multipart bodies only", func(t *testing.T) {
+		uploads := newContentTypeRouter(MediaTypeMultipart)
+
+		w := sendWithContentType(uploads, http.MethodPost, "multipart/form-data; boundary=xyz", "--xyz--")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = sendWithContentType(uploads, http.MethodPost, "application/json", `{}`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maintenanceOpenPaths stay writable during maintenance, so admins can sign
+// in and end or move the window
+var maintenanceOpenPaths = []string{
+	"/api/v1/auth/login",
+	"/api/v1/auth/refresh",
+	"/api/v1/auth/logout",
+	"/api/v1/admin/maintenance/",
+}
+
+// MaintenanceMiddleware refuses writes with 503 while a scheduled maintenance
+// window is under way, returning the window's message and a Retry-After
+// header counting down to its end. Reads are always served. If the windows
+// cannot be looked up, requests are let through.
+func MaintenanceMiddleware(maintenanceService services.MaintenanceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		for _, prefix := range maintenanceOpenPaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		window, err := maintenanceService.Active()
+		if err != nil {
+			logger.LogError(c.Request.Context(), "Failed to look up maintenance windows", err)
+			c.Next()
+			return
+		}
+		if window == nil {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(time.Until(window.EndsAt))))
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Success: false,
+			Error:   "The site is under maintenance",
+			Code:    "ERR_MAINTENANCE",
+			Details: window.Message,
+		})
+		c.Abort()
+	}
+}
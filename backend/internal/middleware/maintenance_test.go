@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMaintenanceService reports window as the active maintenance window
+type fakeMaintenanceService struct {
+	services.MaintenanceService
+	window *models.MaintenanceWindow
+	err    error
+}
+
+func (s *fakeMaintenanceService) Active() (*models.MaintenanceWindow, error) {
+	return s.window, s.err
+}
+
+func newMaintenanceRouter(service services.MaintenanceService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MaintenanceMiddleware(service))
+	handler := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	router.GET("/api/v1/posts", handler)
+	router.POST("/api/v1/posts", handler)
+	router.POST("/api/v1/auth/login", handler)
+	router.DELETE("/api/v1/admin/maintenance/windows/1", handler)
+	return router
+}
+
+func sendMaintenance(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	active := &models.MaintenanceWindow{
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(30 * time.Minute),
+		Message:  "Upgrading the database, back by noon",
+	}
+
+	t.Run("writes are refused during an active window", func(t *testing.T) {
+		router := newMaintenanceRouter(&fakeMaintenanceService{window: active})
+
+		w := sendMaintenance(router, http.MethodPost, "/api/v1/posts")
+
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		var resp models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "ERR_MAINTENANCE", resp.Code)
+		assert.Equal(t, "Upgrading the database, back by noon", resp.Details)
+
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, 30*60, retryAfter, 5, "counts down to the end of the window")
+	})
+
+	t.Run("reads, sign-in and the maintenance endpoints stay open", func(t *testing.T) {
+		router := newMaintenanceRouter(&fakeMaintenanceService{window: active})
+
+		assert.Equal(t, http.StatusOK, sendMaintenance(router, http.MethodGet, "/api/v1/posts").Code)
+		assert.Equal(t, http.StatusOK, sendMaintenance(router, http.MethodPost, "/api/v1/auth/login").Code)
+		assert.Equal(t, http.StatusOK, sendMaintenance(router, http.MethodDelete, "/api/v1/admin/maintenance/windows/1").Code)
+	})
+
+	t.Run("writes are allowed outside a window", func(t *testing.T) {
+		router := newMaintenanceRouter(&fakeMaintenanceService{})
+
+		w := sendMaintenance(router, http.MethodPost, "/api/v1/posts")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("writes are allowed when windows cannot be looked up", func(t *testing.T) {
+		router := newMaintenanceRouter(&fakeMaintenanceService{err: errors.New("database is down")})
+
+		assert.Equal(t, http.StatusOK, sendMaintenance(router, http.MethodPost, "/api/v1/posts").Code)
+	})
+}
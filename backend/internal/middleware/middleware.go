@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"backend/internal/models"
 	"backend/internal/services"
+	"backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -47,6 +49,9 @@ func AuthMiddleware(jwtService services.JWTService) gin.HandlerFunc {
 			if strings.Contains(err.Error(), "expired") {
 				errorCode = "ERR_AUTH_TOKEN_EXPIRED"
 				errorMessage = "Access token has expired"
+			} else if strings.Contains(err.Error(), "revoked") {
+				errorCode = "ERR_AUTH_TOKEN_REVOKED"
+				errorMessage = "Access token has been revoked"
 			} else if strings.Contains(err.Error(), "invalid") {
 				errorCode = "ERR_AUTH_TOKEN_INVALID"
 				errorMessage = "Invalid access token"
@@ -76,6 +81,32 @@ func AuthMiddleware(jwtService services.JWTService) gin.HandlerFunc {
 	}
 }
 
+// PermissionsForRole describes what a role may do. The route guards below
+// make the same decisions, so the result is safe to drive UI from.
+func PermissionsForRole(role string) models.Permissions {
+	switch role {
+	case "admin":
+		return models.Permissions{
+			CanPublish:          true,
+			CanModerate:         true,
+			CanManageUsers:      true,
+			CanManageCategories: true,
+		}
+	case "editor":
+		// Moderation routes are admin-only, so editors publish but do not
+		// moderate
+		return models.Permissions{
+			CanPublish: true,
+		}
+	case "author":
+		return models.Permissions{
+			CanPublish: true,
+		}
+	default:
+		return models.Permissions{}
+	}
+}
+
 // Admin-only middleware
 func AdminOnly() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -109,7 +140,8 @@ func AuthorOrAdminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if role != "admin" && role != "author" {
+		roleName, _ := role.(string)
+		if !PermissionsForRole(roleName).CanPublish {
 			c.JSON(http.StatusForbidden, models.ErrorResponse{
 				Success: false,
 				Error:   "Author or admin access required",
@@ -123,8 +155,56 @@ func AuthorOrAdminMiddleware() gin.HandlerFunc {
 	}
 }
 
+// Editor or admin middleware - allows access for editors and admins
+func EditorOrAdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get("user_role")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Success: false,
+				Error:   "Authentication required",
+				Code:    "ERR_AUTH_REQUIRED",
+				Details: "Please authenticate to access this endpoint",
+			})
+			c.Abort()
+			return
+		}
+
+		if role != "editor" && role != "admin" {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Success: false,
+				Error:   "Editor or admin access required",
+				Code:    "ERR_AUTH_INSUFFICIENT_PERMISSIONS",
+				Details: "This endpoint requires editor or administrator privileges",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Errors an owner lookup passed to OwnerOrAdminMiddleware returns when the
+// request does not name an existing resource
+var (
+	ErrInvalidResourceID = errors.New("invalid resource ID")
+	ErrResourceNotFound  = errors.New("resource not found")
+)
+
 // Owner or admin middleware - allows access if user owns the resource or is admin
 func OwnerOrAdminMiddleware(getResourceOwnerID func(*gin.Context) (uint, error)) gin.HandlerFunc {
+	return ownerOrRoles(getResourceOwnerID, "admin")
+}
+
+// Owner or editor middleware - like OwnerOrAdminMiddleware, but editors may
+// also access any resource
+func OwnerOrEditorMiddleware(getResourceOwnerID func(*gin.Context) (uint, error)) gin.HandlerFunc {
+	return ownerOrRoles(getResourceOwnerID, "admin", "editor")
+}
+
+// ownerOrRoles allows access if the user owns the resource or has one of the
+// roles, which skip the ownership check entirely
+func ownerOrRoles(getResourceOwnerID func(*gin.Context) (uint, error), roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := c.Get("user_id")
 		if !exists {
@@ -139,15 +219,35 @@ func OwnerOrAdminMiddleware(getResourceOwnerID func(*gin.Context) (uint, error))
 
 		userRole, _ := c.Get("user_role")
 
-		// Admin can access anything
-		if userRole == "admin" {
-			c.Next()
-			return
+		for _, role := range roles {
+			if userRole == role {
+				c.Next()
+				return
+			}
 		}
 
 		// Check if user owns the resource
 		resourceOwnerID, err := getResourceOwnerID(c)
-		if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidResourceID):
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Error:   "Invalid resource ID",
+				Code:    "ERR_INVALID_REQUEST",
+				Details: err.Error(),
+			})
+			c.Abort()
+			return
+		case errors.Is(err, ErrResourceNotFound):
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Success: false,
+				Error:   "Resource not found",
+				Code:    "ERR_NOT_FOUND",
+				Details: err.Error(),
+			})
+			c.Abort()
+			return
+		case err != nil:
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 				Success: false,
 				Error:   "Failed to verify resource ownership",
@@ -176,20 +276,8 @@ func OwnerOrAdminMiddleware(getResourceOwnerID func(*gin.Context) (uint, error))
 // Optional auth middleware - doesn't fail if no token provided
 func OptionalAuthMiddleware(jwtService services.JWTService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.Next()
-			return
-		}
-
-		token := services.ExtractTokenFromHeader(authHeader)
-		if token == "" {
-			c.Next()
-			return
-		}
-
-		claims, err := jwtService.ValidateAccessToken(token)
-		if err != nil {
+		claims, ok := bearerClaims(c, jwtService)
+		if !ok {
 			// Don't fail, just continue without auth
 			c.Next()
 			return
@@ -205,3 +293,45 @@ func OptionalAuthMiddleware(jwtService services.JWTService) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// bearerClaims returns the claims of the request's bearer token when
+// ValidateAccessToken accepts it, the same check AuthMiddleware makes: the
+// token must be well formed, unexpired and not on the revocation denylist
+func bearerClaims(c *gin.Context, jwtService services.JWTService) (*models.JWTClaims, bool) {
+	token := services.ExtractTokenFromHeader(c.GetHeader("Authorization"))
+	if token == "" {
+		return nil, false
+	}
+	claims, err := jwtService.ValidateAccessToken(token)
+	if err != nil {
+		return nil, false
+	}
+	return claims, true
+}
+
+// JSONDecoding sets the limits utils.BindJSON enforces on request bodies
+func JSONDecoding(strict bool, maxDepth int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(utils.JSONStrictKey, strict)
+		c.Set(utils.JSONMaxDepthKey, maxDepth)
+		c.Next()
+	}
+}
+
+// FieldSelection sets whether utils.SelectFields rejects ?fields= entries an
+// endpoint does not expose instead of ignoring them
+func FieldSelection(strict bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(utils.FieldsStrictKey, strict)
+		c.Next()
+	}
+}
+
+// PaginationShape sets the list response shape used when a request does not
+// pick one with the X-API-Pagination header
+func PaginationShape(defaultShape string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(utils.PaginationShapeKey, defaultShape)
+		c.Next()
+	}
+}
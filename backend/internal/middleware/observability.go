@@ -6,9 +6,11 @@ import (
 
 	"backend/pkg/logger"
 	"backend/pkg/metrics"
+	"backend/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -84,3 +86,9 @@ func LoggingMiddleware() gin.HandlerFunc {
 func MetricsMiddleware() gin.HandlerFunc {
 	return metrics.PrometheusMiddleware()
 }
+
+// TracingMiddleware starts an OpenTelemetry span for each request, tagged
+// with its X-Request-ID, so it must run after CorrelationIDMiddleware
+func TracingMiddleware(provider trace.TracerProvider) gin.HandlerFunc {
+	return tracing.Middleware(provider)
+}
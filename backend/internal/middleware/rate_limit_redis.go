@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// redisRateLimitTimeout bounds each Redis round trip, so a slow Redis
+	// delays requests by at most this much before they are let through
+	redisRateLimitTimeout = 250 * time.Millisecond
+	// redisRateLimitWarnEvery spaces out the warnings logged while Redis
+	// is unreachable
+	redisRateLimitWarnEvery = 30 * time.Second
+)
+
+// RedisRateLimiter is a RateLimiterStore shared by every replica using the
+// same Redis. Each key counts requests in a fixed window with INCR, expiring
+// when the window ends; the window is as long as r takes to refill b
+// requests. When Redis cannot be reached requests are allowed, with a
+// warning, rather than rejected.
+type RedisRateLimiter struct {
+	client *redis.Client
+	prefix string
+
+	mu       sync.Mutex
+	lastWarn time.Time
+}
+
+func NewRedisRateLimiter(client *redis.Client) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client: client,
+		prefix: "blogcms:ratelimit:",
+	}
+}
+
+func (l *RedisRateLimiter) Allow(key string, r rate.Limit, b int) RateLimitStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+
+	key = l.prefix + key
+	window := rateLimitWindow(r, b)
+	// Without Redis the limit is not enforced, so the whole allowance is left
+	unlimited := RateLimitStatus{Allowed: true, Remaining: b}
+
+	pipe := l.client.Pipeline()
+	incr := pipe.Incr(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		l.warn(ctx, err)
+		return unlimited
+	}
+
+	count, ttl := incr.Val(), pttl.Val()
+	if ttl < 0 {
+		// A new window, or one whose expiry was lost, e.g. because the
+		// first request failed after INCR; (re)start it rather than
+		// blocking the key for good
+		if err := l.client.PExpire(ctx, key, window).Err(); err != nil {
+			l.warn(ctx, err)
+			return unlimited
+		}
+		ttl = window
+	}
+
+	status := RateLimitStatus{Allowed: count <= int64(b), Reset: ttl}
+	if status.Allowed {
+		status.Remaining = b - int(count)
+	} else {
+		status.RetryAfter = ttl
+	}
+	return status
+}
+
+func (l *RedisRateLimiter) warn(ctx context.Context, err error) {
+	l.mu.Lock()
+	now := time.Now()
+	if now.Sub(l.lastWarn) < redisRateLimitWarnEvery {
+		l.mu.Unlock()
+		return
+	}
+	l.lastWarn = now
+	l.mu.Unlock()
+
+	logger.LogWarn(ctx, "Rate limiter Redis is unreachable, allowing requests", zap.Error(err))
+}
+
+// rateLimitWindow is how long r takes to refill b requests
+func rateLimitWindow(r rate.Limit, b int) time.Duration {
+	if r <= 0 {
+		return time.Minute
+	}
+	return time.Duration(float64(b) / float64(r) * float64(time.Second))
+}
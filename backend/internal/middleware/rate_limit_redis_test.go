@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisRateLimiter(t *testing.T, addr string) *RedisRateLimiter {
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisRateLimiter(client)
+}
+
+func TestRedisRateLimiter_SharedAcrossInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	path := "/api/v1/auth/register"
+	jwtService := newRateLimitJWTService()
+
+	// Two replicas, each with its own middleware and Redis client
+	first := newRateLimitedRouter(AdvancedRateLimitMiddleware(newRedisRateLimiter(t, server.Addr()), jwtService, &config.RateLimitConfig{}), path)
+	second := newRateLimitedRouter(AdvancedRateLimitMiddleware(newRedisRateLimiter(t, server.Addr()), jwtService, &config.RateLimitConfig{}), path)
+
+	for i := 1; i <= registerRequestsPerMinute; i++ {
+		router := first
+		if i%2 == 0 {
+			router = second
+		}
+		w := post(router, path)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, registerRequestsPerMinute-i, headerInt(t, w, "X-Rate-Limit-Remaining"), "remaining counts both replicas' requests")
+		assert.Equal(t, 60, headerInt(t, w, "X-Rate-Limit-Reset"))
+	}
+
+	w := post(first, path)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, 60, retryAfter(t, w), "the window is the minute the limit refills over")
+	assert.Equal(t, 0, headerInt(t, w, "X-Rate-Limit-Remaining"))
+	assert.Equal(t, http.StatusTooManyRequests, post(second, path).Code)
+
+	// Once the window expires both replicas allow requests again
+	server.FastForward(time.Minute)
+	assert.Equal(t, http.StatusOK, post(second, path).Code)
+	assert.Equal(t, http.StatusOK, post(first, path).Code)
+}
+
+func TestRedisRateLimiter_AllowsWhenRedisIsUnreachable(t *testing.T) {
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+
+	path := "/api/v1/auth/register"
+	router := newRateLimitedRouter(AdvancedRateLimitMiddleware(newRedisRateLimiter(t, addr), newRateLimitJWTService(), &config.RateLimitConfig{}), path)
+
+	for i := 0; i < registerRequestsPerMinute*2; i++ {
+		require.Equal(t, http.StatusOK, post(router, path).Code)
+	}
+}
@@ -1,100 +1,308 @@
 package middleware
 
 import (
+	"fmt"
+	"math"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/models"
+	"backend/internal/services"
+	"backend/pkg/utils"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/didip/tollbooth/v7"
-	"github.com/didip/tollbooth/v7/limiter"
 	"golang.org/x/time/rate"
 )
 
-// CORS middleware with strict configuration
-func CORSMiddleware() gin.HandlerFunc {
+// AllowedOrigins returns the origins CORS accepts: the local development
+// defaults plus any configured in ALLOWED_ORIGINS
+func AllowedOrigins(cfg *config.SecurityConfig) []string {
 	allowedOrigins := []string{
 		"http://localhost:3000",  // Default frontend dev
 		"http://localhost:5173",  // Vite dev server
 		"http://localhost:8080",  // Backend docs
 	}
 
-	// Add custom origins from environment
-	if envOrigins := os.Getenv("ALLOWED_ORIGINS"); envOrigins != "" {
-		customOrigins := strings.Split(envOrigins, ",")
-		for _, origin := range customOrigins {
-			origin = strings.TrimSpace(origin)
-			if origin != "" {
-				allowedOrigins = append(allowedOrigins, origin)
-			}
-		}
-	}
+	return append(allowedOrigins, cfg.AllowedOrigins...)
+}
 
+// CORS middleware with strict configuration. Unsafe combinations, such as
+// credentials with a "*" origin, are caught by config.ValidateSecurity at
+// startup.
+func CORSMiddleware(cfg *config.SecurityConfig) gin.HandlerFunc {
 	return cors.New(cors.Config{
-		AllowOrigins:     allowedOrigins,
+		AllowOrigins:     AllowedOrigins(cfg),
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length", "X-Rate-Limit-Remaining", "X-Rate-Limit-Reset"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", utils.PaginationShapeHeader},
+		ExposeHeaders:    []string{"Content-Length", "X-Rate-Limit-Remaining", "X-Rate-Limit-Reset", "Retry-After", utils.PaginationShapeHeader},
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
 	})
 }
 
-// Rate limiting middleware using tollbooth
-func RateLimitMiddleware(requestsPerMinute float64) gin.HandlerFunc {
-	lmt := tollbooth.NewLimiter(requestsPerMinute, &limiter.ExpirableOptions{
-		DefaultExpirationTTL: time.Hour,
-	})
+// rateLimitedMethods are the request methods RateLimitMiddleware counts
+var rateLimitedMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
 
-	// Customize limiter
-	lmt.SetIPLookups([]string{"X-Forwarded-For", "X-Real-IP", "RemoteAddr"})
-	lmt.SetMethods([]string{"GET", "POST", "PUT", "DELETE"})
+// RateLimitMiddleware limits each client IP to requestsPerMinute requests
+// per minute on every path it guards, allowing bursts of that size. The
+// requests are counted in store, which whoever created it stops on shutdown.
+func RateLimitMiddleware(store RateLimiterStore, requestsPerMinute float64) gin.HandlerFunc {
+	r := rate.Limit(requestsPerMinute / 60)
+	b := int(math.Max(1, requestsPerMinute))
 
 	return func(c *gin.Context) {
-		httpError := tollbooth.LimitByRequest(lmt, c.Writer, c.Request)
-		if httpError != nil {
-			c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
-				Success: false,
-				Error:   "Rate limit exceeded",
-				Code:    "ERR_RATE_LIMIT",
-				Details: "Too many requests. Please try again later.",
-			})
-			c.Abort()
+		// Preflight and other non-mutating extras pass freely
+		if !rateLimitedMethods[c.Request.Method] {
+			c.Next()
+			return
+		}
+
+		// Prefixed so a store shared with AdvancedRateLimitMiddleware keeps
+		// the two counts apart
+		key := "route:" + c.ClientIP() + ":" + c.Request.URL.Path
+		status := store.Allow(key, r, b)
+		setRateLimitHeaders(c, status)
+		if !status.Allowed {
+			abortRateLimited(c, "Rate limit exceeded", "ERR_RATE_LIMIT", b, status.RetryAfter)
 			return
 		}
 		c.Next()
 	}
 }
 
-// Advanced rate limiting with different tiers
+// setRateLimitHeaders tells the client how many requests it has left and in
+// how many seconds its allowance is full again
+func setRateLimitHeaders(c *gin.Context, status RateLimitStatus) {
+	c.Header("X-Rate-Limit-Remaining", strconv.Itoa(status.Remaining))
+	c.Header("X-Rate-Limit-Reset", strconv.Itoa(int(math.Ceil(status.Reset.Seconds()))))
+}
+
+// abortRateLimited rejects the request with 429, telling the client through
+// Retry-After and the response body how long to wait
+func abortRateLimited(c *gin.Context, message, code string, limit int, retryAfter time.Duration) {
+	seconds := retryAfterSeconds(retryAfter)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+
+	response := models.RateLimitResponse{
+		Success: false,
+		Error:   message,
+		Code:    code,
+	}
+	response.Details.Limit = limit
+	response.Details.Remaining = 0
+	response.Details.ResetTime = seconds
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, response)
+}
+
+// retryAfterSeconds rounds a wait up to whole seconds, as Retry-After
+// requires, and never below one second
+func retryAfterSeconds(wait time.Duration) int {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// Per-minute request limits applied by AdvancedRateLimitMiddleware
+const (
+	loginRequestsPerMinute    = 5
+	registerRequestsPerMinute = 3
+	refreshRequestsPerMinute  = 10
+	writeRequestsPerMinute    = 30
+	readRequestsPerMinute     = 60
+)
+
+// RateLimits reports the per-minute limits AdvancedRateLimitMiddleware
+// enforces for each class of request
+func RateLimits() map[string]int {
+	return map[string]int{
+		"login":    loginRequestsPerMinute,
+		"register": registerRequestsPerMinute,
+		"refresh":  refreshRequestsPerMinute,
+		"write":    writeRequestsPerMinute,
+		"read":     readRequestsPerMinute,
+	}
+}
+
+// RateLimiterStore keeps the request counts behind the rate limits. Allow
+// takes one request from key's allowance of b requests, refilled at rate r,
+// and reports what is left of it.
+type RateLimiterStore interface {
+	Allow(key string, r rate.Limit, b int) RateLimitStatus
+}
+
+// RateLimitStatus describes a key's allowance after counting a request
+type RateLimitStatus struct {
+	Allowed bool
+	// Remaining is how many more requests are allowed right now
+	Remaining int
+	// Reset is how long until the whole allowance is available again
+	Reset time.Duration
+	// RetryAfter is how long a rejected request should wait before the
+	// next one is allowed
+	RetryAfter time.Duration
+}
+
+// Rate limiter backends, see config.RateLimitConfig.Backend
+const (
+	RateLimitBackendMemory = "memory"
+	RateLimitBackendRedis  = "redis"
+)
+
+// RateLimiter is the in-memory RateLimiterStore. Its counts are lost on
+// restart and not shared between replicas.
 type RateLimiter struct {
-	limiters map[string]*rate.Limiter
+	mu       sync.Mutex
+	limiters map[string]*rateLimiterEntry
+	idleTTL  time.Duration
+	now      func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type rateLimiterEntry struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
 }
 
-func NewRateLimiter() *RateLimiter {
-	return &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
+// NewRateLimiter creates an empty RateLimiter. When idleTTL is positive a
+// background sweeper drops the limiters of keys unused for that long, until
+// Stop is called; zero keeps every limiter for the life of the process.
+func NewRateLimiter(idleTTL time.Duration) *RateLimiter {
+	rl := &RateLimiter{
+		limiters: make(map[string]*rateLimiterEntry),
+		idleTTL:  idleTTL,
+		now:      time.Now,
+		stop:     make(chan struct{}),
 	}
+	if idleTTL > 0 {
+		go rl.sweep(idleTTL)
+	}
+	return rl
 }
 
 func (rl *RateLimiter) GetLimiter(key string, r rate.Limit, b int) *rate.Limiter {
-	if limiter, exists := rl.limiters[key]; exists {
-		return limiter
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	return rl.getLimiter(key, r, b)
+}
+
+// getLimiter returns key's limiter, creating it if needed, and marks it used.
+// rl.mu must be held.
+func (rl *RateLimiter) getLimiter(key string, r rate.Limit, b int) *rate.Limiter {
+	now := rl.now()
+	if entry, exists := rl.limiters[key]; exists {
+		entry.lastUsed = now
+		return entry.limiter
 	}
 
 	newLimiter := rate.NewLimiter(r, b)
-	rl.limiters[key] = newLimiter
+	rl.limiters[key] = &rateLimiterEntry{limiter: newLimiter, lastUsed: now}
 	return newLimiter
 }
 
-// Advanced rate limiting middleware with different limits per endpoint
-func AdvancedRateLimitMiddleware() gin.HandlerFunc {
-	rateLimiter := NewRateLimiter()
+// Evict drops the limiters unused for longer than the idle TTL and returns
+// how many it dropped. A limiter that has not refilled yet is kept, so
+// evicting it never hands a client a fresh allowance early.
+func (rl *RateLimiter) Evict() int {
+	if rl.idleTTL <= 0 {
+		return 0
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	evicted := 0
+	for key, entry := range rl.limiters {
+		if now.Sub(entry.lastUsed) <= rl.idleTTL {
+			continue
+		}
+		if entry.limiter.TokensAt(now) < float64(entry.limiter.Burst()) {
+			continue
+		}
+		delete(rl.limiters, key)
+		evicted++
+	}
+	return evicted
+}
+
+// Stop ends the background sweeper. It is safe to call more than once.
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+}
+
+func (rl *RateLimiter) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rl.Evict()
+		case <-rl.stop:
+			return
+		}
+	}
+}
+
+// Allow takes a token from the limiter for key. When none is left the
+// request is rejected, with how long until the next token is available.
+func (rl *RateLimiter) Allow(key string, r rate.Limit, b int) RateLimitStatus {
+	// Held throughout so the sweeper cannot evict the limiter mid-request
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	limiter := rl.getLimiter(key, r, b)
+	now := rl.now()
+	if limiter.AllowN(now, 1) {
+		return tokenBucketStatus(limiter, now, true)
+	}
 
+	// Reserving shows when the next token arrives; cancelling hands it back
+	reservation := limiter.ReserveN(now, 1)
+	wait := reservation.DelayFrom(now)
+	reservation.CancelAt(now)
+
+	status := tokenBucketStatus(limiter, now, false)
+	status.RetryAfter = wait
+	return status
+}
+
+// tokenBucketStatus reads the tokens left in limiter: whole ones are
+// requests remaining, and the missing ones refill by Reset
+func tokenBucketStatus(limiter *rate.Limiter, now time.Time, allowed bool) RateLimitStatus {
+	tokens := limiter.TokensAt(now)
+	status := RateLimitStatus{Allowed: allowed}
+	if tokens > 0 {
+		status.Remaining = int(math.Floor(tokens))
+	}
+	if missing := float64(limiter.Burst()) - tokens; missing > 0 && limiter.Limit() > 0 {
+		status.Reset = time.Duration(missing / float64(limiter.Limit()) * float64(time.Second))
+	}
+	return status
+}
+
+// Advanced rate limiting middleware with different limits per endpoint,
+// counted in store. Reads and writes by a caller with a valid access token
+// are counted per user rather than per IP when cfg gives users a limit, so
+// users behind one NAT or proxy do not use up each other's requests and a
+// single abusive user is throttled on their own. Anonymous requests, and
+// every request to the auth endpoints, stay limited by IP.
+func AdvancedRateLimitMiddleware(store RateLimiterStore, jwtService services.JWTService, cfg *config.RateLimitConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clientIP := c.ClientIP()
 		path := c.Request.URL.Path
@@ -103,62 +311,87 @@ func AdvancedRateLimitMiddleware() gin.HandlerFunc {
 		// Define rate limits for different endpoints
 		var r rate.Limit
 		var b int
+		key := clientIP + ":" + path
+		message, code := "Rate limit exceeded for this endpoint", "ERR_RATE_LIMIT_ENDPOINT"
 
 		switch {
 		case strings.HasPrefix(path, "/api/v1/auth/login"):
 			// Login: 5 requests per minute
-			r = rate.Every(time.Minute / 5)
-			b = 5
+			r = rate.Every(time.Minute / loginRequestsPerMinute)
+			b = loginRequestsPerMinute
 		case strings.HasPrefix(path, "/api/v1/auth/register"):
 			// Register: 3 requests per minute
-			r = rate.Every(time.Minute / 3)
-			b = 3
+			r = rate.Every(time.Minute / registerRequestsPerMinute)
+			b = registerRequestsPerMinute
 		case strings.HasPrefix(path, "/api/v1/auth/refresh"):
 			// Refresh: 10 requests per minute
-			r = rate.Every(time.Minute / 10)
-			b = 10
+			r = rate.Every(time.Minute / refreshRequestsPerMinute)
+			b = refreshRequestsPerMinute
 		case method == "POST" || method == "PUT" || method == "DELETE":
+			if userID, ok := rateLimitedUser(c, jwtService, cfg.UserWriteRequestsPerMinute); ok {
+				// Writes by a user: their own per-minute budget
+				r = rate.Every(time.Minute / time.Duration(cfg.UserWriteRequestsPerMinute))
+				b = cfg.UserWriteRequestsPerMinute
+				key = fmt.Sprintf("user:%d:write", userID)
+				message, code = "Rate limit exceeded for this user", "ERR_RATE_LIMIT_USER"
+				break
+			}
 			// Write operations: 30 requests per minute
-			r = rate.Every(time.Minute / 30)
-			b = 30
+			r = rate.Every(time.Minute / writeRequestsPerMinute)
+			b = writeRequestsPerMinute
 		default:
+			if userID, ok := rateLimitedUser(c, jwtService, cfg.UserReadRequestsPerMinute); ok {
+				// Reads by a user: their own per-minute budget
+				r = rate.Every(time.Minute / time.Duration(cfg.UserReadRequestsPerMinute))
+				b = cfg.UserReadRequestsPerMinute
+				key = fmt.Sprintf("user:%d:read", userID)
+				message, code = "Rate limit exceeded for this user", "ERR_RATE_LIMIT_USER"
+				break
+			}
 			// Read operations: 60 requests per minute
-			r = rate.Every(time.Minute / 60)
-			b = 60
+			r = rate.Every(time.Minute / readRequestsPerMinute)
+			b = readRequestsPerMinute
 		}
 
-		key := clientIP + ":" + path
-		limiter := rateLimiter.GetLimiter(key, r, b)
-
-		if !limiter.Allow() {
-			c.Header("X-Rate-Limit-Remaining", "0")
-			c.Header("X-Rate-Limit-Reset", "60")
-			
-			c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
-				Success: false,
-				Error:   "Rate limit exceeded for this endpoint",
-				Code:    "ERR_RATE_LIMIT_ENDPOINT",
-				Details: "Too many requests to this endpoint. Please try again later.",
-			})
-			c.Abort()
+		status := store.Allow(key, r, b)
+		setRateLimitHeaders(c, status)
+		if !status.Allowed {
+			abortRateLimited(c, message, code, b, status.RetryAfter)
 			return
 		}
-
-		c.Header("X-Rate-Limit-Remaining", "1")
 		c.Next()
 	}
 }
 
+// rateLimitedUser returns the user whose access token the request carries,
+// checked by bearerClaims as AuthMiddleware would, revocation included.
+// Requests are only counted per user when requestsPerMinute gives users a
+// limit.
+func rateLimitedUser(c *gin.Context, jwtService services.JWTService, requestsPerMinute int) (uint, bool) {
+	if requestsPerMinute <= 0 {
+		return 0, false
+	}
+	claims, ok := bearerClaims(c, jwtService)
+	if !ok {
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
 // Security headers middleware
-func SecurityHeadersMiddleware() gin.HandlerFunc {
+func SecurityHeadersMiddleware(cfg *config.SecurityConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Security headers
 		c.Header("X-Content-Type-Options", "nosniff")
 		c.Header("X-Frame-Options", "DENY")
 		c.Header("X-XSS-Protection", "1; mode=block")
-		c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		if cfg.HSTS {
+			c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		}
 		c.Header("Referrer-Policy", "strict-origin-when-cross-origin")
-		c.Header("Content-Security-Policy", "default-src 'self'")
+		if cfg.ContentSecurityPolicy != "" {
+			c.Header("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
 		
 		// Remove server information
 		c.Header("Server", "")
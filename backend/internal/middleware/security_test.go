@@ -0,0 +1,346 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// newClockedRateLimiter returns a limiter whose clock only moves when the
+// test advances it
+func newClockedRateLimiter() (*RateLimiter, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	rateLimiter := NewRateLimiter(0)
+	rateLimiter.now = func() time.Time { return now }
+	return rateLimiter, &now
+}
+
+func newRateLimitedRouter(limit gin.HandlerFunc, path string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(limit)
+	router.POST(path, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func post(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	return postAs(router, path, "")
+}
+
+// postAs posts from the same address as post, with token as bearer token
+// unless it is empty
+func postAs(router *gin.Engine, path, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.RemoteAddr = "203.0.113.9:1234"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func newRateLimitJWTService() services.JWTService {
+	return services.NewJWTService(stubRefreshTokenRepo{}, stubRevokedTokenRepo{revoked: map[string]bool{}}, &config.Config{})
+}
+
+func accessToken(t *testing.T, jwtService services.JWTService, userID uint) string {
+	pair, err := jwtService.GenerateTokenPair(&models.User{ID: userID, Role: "author"})
+	require.NoError(t, err)
+	return pair.AccessToken
+}
+
+func retryAfter(t *testing.T, w *httptest.ResponseRecorder) int {
+	seconds, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+
+	var resp models.RateLimitResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, seconds, resp.Details.ResetTime, "body and header agree")
+	assert.Equal(t, 0, resp.Details.Remaining)
+	return seconds
+}
+
+func TestRateLimitMiddleware_RetryAfter(t *testing.T) {
+	rateLimiter, now := newClockedRateLimiter()
+	router := newRateLimitedRouter(RateLimitMiddleware(rateLimiter, 6), "/docs")
+
+	for i := 0; i < 6; i++ {
+		require.Equal(t, http.StatusOK, post(router, "/docs").Code)
+	}
+
+	w := post(router, "/docs")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	// 6 per minute refills one request every 10 seconds
+	assert.Equal(t, 10, retryAfter(t, w))
+
+	*now = now.Add(4 * time.Second)
+	w = post(router, "/docs")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, 6, retryAfter(t, w), "the wait shrinks as time passes")
+
+	*now = now.Add(6 * time.Second)
+	assert.Equal(t, http.StatusOK, post(router, "/docs").Code)
+}
+
+func TestRateLimitMiddleware_SharesTheStore(t *testing.T) {
+	rateLimiter, _ := newClockedRateLimiter()
+	path := "/api/v1/auth/login"
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AdvancedRateLimitMiddleware(rateLimiter, newRateLimitJWTService(), &config.RateLimitConfig{}))
+	router.POST(path, RateLimitMiddleware(rateLimiter, 10), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// Each limit counts the requests once, under its own key
+	for i := 1; i <= loginRequestsPerMinute; i++ {
+		w := post(router, path)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 10-i, headerInt(t, w, "X-Rate-Limit-Remaining"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, post(router, path).Code)
+}
+
+func TestAdvancedRateLimitMiddleware_RetryAfter(t *testing.T) {
+	rateLimiter, now := newClockedRateLimiter()
+	path := "/api/v1/auth/register"
+	router := newRateLimitedRouter(AdvancedRateLimitMiddleware(rateLimiter, newRateLimitJWTService(), &config.RateLimitConfig{}), path)
+
+	for i := 0; i < registerRequestsPerMinute; i++ {
+		require.Equal(t, http.StatusOK, post(router, path).Code)
+	}
+
+	w := post(router, path)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	first := retryAfter(t, w)
+	assert.Equal(t, 20, first)
+
+	var resp models.RateLimitResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "ERR_RATE_LIMIT_ENDPOINT", resp.Code)
+	assert.Equal(t, registerRequestsPerMinute, resp.Details.Limit)
+
+	*now = now.Add(15 * time.Second)
+	w = post(router, path)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	second := retryAfter(t, w)
+	assert.Positive(t, second)
+	assert.Less(t, second, first)
+}
+
+// headerInt reads an integer response header
+func headerInt(t *testing.T, w *httptest.ResponseRecorder, name string) int {
+	value, err := strconv.Atoi(w.Header().Get(name))
+	require.NoError(t, err, "%s header", name)
+	return value
+}
+
+func TestAdvancedRateLimitMiddleware_Headers(t *testing.T) {
+	rateLimiter, now := newClockedRateLimiter()
+	path := "/api/v1/auth/register"
+	router := newRateLimitedRouter(AdvancedRateLimitMiddleware(rateLimiter, newRateLimitJWTService(), &config.RateLimitConfig{}), path)
+
+	// 3 per minute: each request used takes 20 seconds to refill
+	for i := 1; i <= registerRequestsPerMinute; i++ {
+		w := post(router, path)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, registerRequestsPerMinute-i, headerInt(t, w, "X-Rate-Limit-Remaining"))
+		assert.Equal(t, 20*i, headerInt(t, w, "X-Rate-Limit-Reset"))
+		assert.Empty(t, w.Header().Get("Retry-After"))
+	}
+
+	w := post(router, path)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, 0, headerInt(t, w, "X-Rate-Limit-Remaining"))
+	assert.Equal(t, 60, headerInt(t, w, "X-Rate-Limit-Reset"))
+	assert.Equal(t, 20, headerInt(t, w, "Retry-After"))
+
+	// A refilled request shows up in the next response
+	*now = now.Add(20 * time.Second)
+	w = post(router, path)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, headerInt(t, w, "X-Rate-Limit-Remaining"))
+
+	*now = now.Add(time.Hour)
+	w = post(router, path)
+	assert.Equal(t, registerRequestsPerMinute-1, headerInt(t, w, "X-Rate-Limit-Remaining"))
+	assert.Equal(t, 20, headerInt(t, w, "X-Rate-Limit-Reset"))
+}
+
+func TestAdvancedRateLimitMiddleware_PerUser(t *testing.T) {
+	jwtService := newRateLimitJWTService()
+	path := "/api/v1/posts"
+	newRouter := func() *gin.Engine {
+		rateLimiter, _ := newClockedRateLimiter()
+		return newRateLimitedRouter(AdvancedRateLimitMiddleware(rateLimiter, jwtService, &config.RateLimitConfig{
+			UserReadRequestsPerMinute:  100,
+			UserWriteRequestsPerMinute: 40,
+		}), path)
+	}
+	alice := accessToken(t, jwtService, 1)
+	bob := accessToken(t, jwtService, 2)
+
+	t.Run("users sharing an IP have their own limits", func(t *testing.T) {
+		router := newRouter()
+
+		for i := 0; i < 40; i++ {
+			require.Equal(t, http.StatusOK, postAs(router, path, alice).Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, postAs(router, path, alice).Code)
+
+		for i := 0; i < 40; i++ {
+			require.Equal(t, http.StatusOK, postAs(router, path, bob).Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, postAs(router, path, bob).Code)
+	})
+
+	t.Run("a user over their limit is throttled on their own", func(t *testing.T) {
+		router := newRouter()
+
+		for i := 0; i < 40; i++ {
+			require.Equal(t, http.StatusOK, postAs(router, path, alice).Code)
+		}
+		w := postAs(router, path, alice)
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		var resp models.RateLimitResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "ERR_RATE_LIMIT_USER", resp.Code)
+		assert.Equal(t, 40, resp.Details.Limit)
+		// 40 per minute refills one request every 1.5 seconds
+		assert.Equal(t, 2, retryAfter(t, w))
+
+		// Anonymous requests from the same IP are unaffected
+		assert.Equal(t, http.StatusOK, post(router, path).Code)
+	})
+
+	t.Run("anonymous and invalid tokens are limited by IP", func(t *testing.T) {
+		router := newRouter()
+
+		for i := 0; i < writeRequestsPerMinute; i++ {
+			token := ""
+			if i%2 == 0 {
+				token = "not-a-token"
+			}
+			require.Equal(t, http.StatusOK, postAs(router, path, token).Code)
+		}
+		w := post(router, path)
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		var resp models.RateLimitResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "ERR_RATE_LIMIT_ENDPOINT", resp.Code)
+
+		// A signed-in user behind the same IP still gets through
+		assert.Equal(t, http.StatusOK, postAs(router, path, alice).Code)
+	})
+
+	t.Run("revoked tokens are limited by IP", func(t *testing.T) {
+		router := newRouter()
+		revoked := accessToken(t, jwtService, 3)
+		claims, err := jwtService.ValidateAccessToken(revoked)
+		require.NoError(t, err)
+		require.NoError(t, jwtService.RevokeAccessToken(claims))
+
+		for i := 0; i < writeRequestsPerMinute; i++ {
+			require.Equal(t, http.StatusOK, postAs(router, path, revoked).Code)
+		}
+		w := postAs(router, path, revoked)
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		var resp models.RateLimitResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "ERR_RATE_LIMIT_ENDPOINT", resp.Code)
+	})
+
+	t.Run("without per-user limits users count against their IP", func(t *testing.T) {
+		rateLimiter, _ := newClockedRateLimiter()
+		router := newRateLimitedRouter(AdvancedRateLimitMiddleware(rateLimiter, jwtService, &config.RateLimitConfig{}), path)
+
+		for i := 0; i < writeRequestsPerMinute; i++ {
+			token := alice
+			if i%2 == 0 {
+				token = bob
+			}
+			require.Equal(t, http.StatusOK, postAs(router, path, token).Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, postAs(router, path, alice).Code)
+	})
+}
+
+func TestRateLimiter_EvictsIdleLimiters(t *testing.T) {
+	rateLimiter, now := newClockedRateLimiter()
+	rateLimiter.idleTTL = time.Minute
+	perMinute := rate.Limit(1)
+
+	rateLimiter.Allow("idle", perMinute, 5)
+	rateLimiter.Allow("slow", rate.Every(time.Hour), 1)
+	*now = now.Add(90 * time.Second)
+	rateLimiter.Allow("active", perMinute, 5)
+
+	assert.Equal(t, 1, rateLimiter.Evict())
+	assert.NotContains(t, rateLimiter.limiters, "idle")
+	assert.Contains(t, rateLimiter.limiters, "slow", "limiters that have not refilled are kept")
+	assert.Contains(t, rateLimiter.limiters, "active")
+
+	// Using a limiter again keeps it
+	*now = now.Add(45 * time.Second)
+	rateLimiter.Allow("active", perMinute, 5)
+	*now = now.Add(45 * time.Second)
+	assert.Zero(t, rateLimiter.Evict())
+}
+
+func TestRateLimiter_ZeroTTLKeepsLimiters(t *testing.T) {
+	rateLimiter, now := newClockedRateLimiter()
+
+	rateLimiter.Allow("client", rate.Limit(1), 5)
+	*now = now.Add(24 * time.Hour)
+
+	assert.Zero(t, rateLimiter.Evict())
+	assert.Len(t, rateLimiter.limiters, 1)
+}
+
+// Run with -race: the sweeper evicts while many goroutines take limiters
+func TestRateLimiter_ConcurrentAccess(t *testing.T) {
+	rateLimiter := NewRateLimiter(time.Millisecond)
+	defer rateLimiter.Stop()
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 32; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("203.0.113.%d:/api/v1/posts/%d", worker, i%20)
+				if i%2 == 0 {
+					rateLimiter.GetLimiter(key, rate.Limit(100), 10)
+				} else {
+					rateLimiter.Allow(key, rate.Limit(100), 10)
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	// The sweeper empties the map once every limiter has refilled
+	assert.Eventually(t, func() bool {
+		rateLimiter.mu.Lock()
+		defer rateLimiter.mu.Unlock()
+		return len(rateLimiter.limiters) == 0
+	}, 2*time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultRequestTimeout is the deadline TimeoutMiddleware gives requests when
+// given none
+const DefaultRequestTimeout = 30 * time.Second
+
+// TimeoutMiddleware gives each request a context that expires after timeout,
+// so queries run with the request context are cancelled once it passes. If
+// the handler has not started its response by then, the client gets 503 with
+// code ERR_REQUEST_TIMEOUT straight away and anything the handler writes
+// afterwards is discarded. A timeout of 0 or less uses DefaultRequestTimeout.
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		writer := newTimeoutWriter(ctx, c.Writer)
+		c.Writer = writer
+
+		handlerDone := make(chan struct{})
+		watcherDone := make(chan struct{})
+		go func() {
+			defer close(watcherDone)
+			select {
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					writer.timeout()
+				}
+			case <-handlerDone:
+			}
+		}()
+
+		c.Next()
+		close(handlerDone)
+		<-watcherDone
+
+		writer.finish()
+		c.Writer = writer.ResponseWriter
+	}
+}
+
+// timeoutWriter lets the deadline watcher answer a request while its handler
+// is still running. The handler's headers are kept apart until it starts
+// writing, so the two never touch the response at the same time.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+
+	mu       sync.Mutex
+	header   http.Header
+	started  bool
+	timedOut bool
+}
+
+func newTimeoutWriter(ctx context.Context, w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{ResponseWriter: w, ctx: ctx, header: w.Header().Clone()}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.start() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.start() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.start() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.Status()
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.Size()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.Written()
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.start() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// start hands the handler's headers over the first time it writes, and
+// reports whether the handler may still write. A handler that only gets
+// round to writing after the deadline, typically to report the cancelled
+// query, is answered with the timeout instead. Callers hold mu.
+func (w *timeoutWriter) start() bool {
+	if w.timedOut {
+		return false
+	}
+	if !w.started {
+		if errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+			w.writeTimeout()
+			return false
+		}
+		w.started = true
+		w.syncHeader()
+	}
+	return true
+}
+
+// syncHeader makes the response headers match the handler's
+func (w *timeoutWriter) syncHeader() {
+	dst := w.ResponseWriter.Header()
+	for key := range dst {
+		if _, ok := w.header[key]; !ok {
+			dst.Del(key)
+		}
+	}
+	for key, values := range w.header {
+		dst[key] = values
+	}
+}
+
+// timeout answers with 503 unless the handler has already started writing
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.started && !w.timedOut {
+		w.writeTimeout()
+	}
+}
+
+// writeTimeout sends the 503 response. Callers hold mu.
+func (w *timeoutWriter) writeTimeout() {
+	w.timedOut = true
+
+	body, _ := json.Marshal(models.ErrorResponse{
+		Success: false,
+		Error:   "Request timed out",
+		Code:    "ERR_REQUEST_TIMEOUT",
+		Details: "the server did not finish the request in time",
+	})
+	w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(http.StatusServiceUnavailable)
+	w.ResponseWriter.Write(body)
+}
+
+// finish hands over the headers of a handler that set some but wrote
+// nothing, such as a bare c.Status(http.StatusNoContent)
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.started && !w.timedOut {
+		w.syncHeader()
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const timeoutTestLimit = 50 * time.Millisecond
+
+func newTimeoutRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TimeoutMiddleware(timeoutTestLimit))
+	router.GET("/slow", func(c *gin.Context) {
+		// Stands in for a query run with the request context
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": c.Request.Context().Err().Error()})
+	})
+	router.GET("/fast", func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		c.Header("X-Has-Deadline", map[bool]string{true: "yes", false: "no"}[hasDeadline])
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/started", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Writer.WriteString("partial")
+		<-c.Request.Context().Done()
+		c.Writer.WriteString(" and the rest")
+	})
+	router.DELETE("/empty", func(c *gin.Context) {
+		c.Header("X-Deleted", "1")
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	router := newTimeoutRouter()
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	t.Run("slow handlers time out with 503", func(t *testing.T) {
+		start := time.Now()
+		w := serve(http.MethodGet, "/slow")
+
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		var resp models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "ERR_REQUEST_TIMEOUT", resp.Code)
+	})
+
+	t.Run("fast handlers answer normally with a deadline set", func(t *testing.T) {
+		w := serve(http.MethodGet, "/fast")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "yes", w.Header().Get("X-Has-Deadline"))
+		assert.JSONEq(t, `{"ok": true}`, w.Body.String())
+	})
+
+	t.Run("responses already started are left to the handler", func(t *testing.T) {
+		w := serve(http.MethodGet, "/started")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "partial and the rest", w.Body.String())
+	})
+
+	t.Run("headers of responses without a body are kept", func(t *testing.T) {
+		w := serve(http.MethodDelete, "/empty")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-Deleted"))
+	})
+}
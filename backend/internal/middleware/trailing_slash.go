@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ways of routing a path that ends in a slash, set with API_TRAILING_SLASH
+const (
+	// TrailingSlashStrip serves the path as if it had no trailing slash
+	TrailingSlashStrip = "strip"
+	// TrailingSlashRedirect redirects to the path without the slash: 301 for
+	// GET, 307 for other methods so clients resend the body
+	TrailingSlashRedirect = "redirect"
+	// TrailingSlashStrict answers 404 unless the route has the slash
+	TrailingSlashStrict = "strict"
+)
+
+// TrailingSlash configures engine for the given trailing slash mode and
+// returns the handler to serve it with. Unknown modes strip. It must be
+// called after every route is registered: routes that are registered with a
+// trailing slash, or end in a catch-all, keep their slash in strip mode.
+func TrailingSlash(engine *gin.Engine, mode string) http.Handler {
+	switch mode {
+	case TrailingSlashRedirect:
+		engine.RedirectTrailingSlash = true
+		return engine
+	case TrailingSlashStrict:
+		engine.RedirectTrailingSlash = false
+		return engine
+	}
+
+	engine.RedirectTrailingSlash = false
+	exact := make(map[string]bool)
+	var prefixes []string
+	for _, route := range engine.Routes() {
+		if i := strings.Index(route.Path, "*"); i >= 0 {
+			prefixes = append(prefixes, route.Path[:i])
+		} else if strings.HasSuffix(route.Path, "/") {
+			exact[route.Path] = true
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path := r.URL.Path; len(path) > 1 && strings.HasSuffix(path, "/") && !keepsSlash(path, exact, prefixes) {
+			r.URL.Path = strings.TrimRight(path, "/")
+			if r.URL.Path == "" {
+				r.URL.Path = "/"
+			}
+			r.URL.RawPath = ""
+		}
+		engine.ServeHTTP(w, r)
+	})
+}
+
+// keepsSlash reports whether path is routed with its trailing slash
+func keepsSlash(path string, exact map[string]bool, prefixes []string) bool {
+	if exact[path] {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
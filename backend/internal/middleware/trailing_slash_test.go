@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTrailingSlashHandler(mode string) http.Handler {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/posts", func(c *gin.Context) {
+		c.String(http.StatusOK, "list")
+	})
+	router.POST("/posts", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, string(body))
+	})
+	router.GET("/docs/", func(c *gin.Context) {
+		c.String(http.StatusOK, "docs")
+	})
+	router.GET("/files/*any", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Param("any"))
+	})
+	return TrailingSlash(router, mode)
+}
+
+func serveTrailingSlash(handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+func TestTrailingSlash(t *testing.T) {
+	t.Run("strip serves both forms the same", func(t *testing.T) {
+		handler := newTrailingSlashHandler(TrailingSlashStrip)
+
+		for _, path := range []string{"/posts", "/posts/"} {
+			w := serveTrailingSlash(handler, http.MethodGet, path, "")
+			assert.Equal(t, http.StatusOK, w.Code, path)
+			assert.Equal(t, "list", w.Body.String(), path)
+		}
+	})
+
+	t.Run("strip keeps POST bodies", func(t *testing.T) {
+		handler := newTrailingSlashHandler(TrailingSlashStrip)
+
+		w := serveTrailingSlash(handler, http.MethodPost, "/posts/", `{"title":"Hello"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, `{"title":"Hello"}`, w.Body.String())
+	})
+
+	t.Run("strip leaves routes registered with a slash alone", func(t *testing.T) {
+		handler := newTrailingSlashHandler(TrailingSlashStrip)
+
+		assert.Equal(t, "docs", serveTrailingSlash(handler, http.MethodGet, "/docs/", "").Body.String())
+		assert.Equal(t, "/", serveTrailingSlash(handler, http.MethodGet, "/files/", "").Body.String())
+		assert.Equal(t, "/a/", serveTrailingSlash(handler, http.MethodGet, "/files/a/", "").Body.String())
+	})
+
+	t.Run("redirect keeps the method for bodies", func(t *testing.T) {
+		handler := newTrailingSlashHandler(TrailingSlashRedirect)
+
+		get := serveTrailingSlash(handler, http.MethodGet, "/posts/", "")
+		post := serveTrailingSlash(handler, http.MethodPost, "/posts/", "{}")
+
+		assert.Equal(t, http.StatusMovedPermanently, get.Code)
+		assert.Equal(t, "/posts", get.Header().Get("Location"))
+		assert.Equal(t, http.StatusTemporaryRedirect, post.Code)
+		assert.Equal(t, "/posts", post.Header().Get("Location"))
+	})
+
+	t.Run("strict rejects the slash", func(t *testing.T) {
+		handler := newTrailingSlashHandler(TrailingSlashStrict)
+
+		assert.Equal(t, http.StatusNotFound, serveTrailingSlash(handler, http.MethodGet, "/posts/", "").Code)
+		assert.Equal(t, http.StatusOK, serveTrailingSlash(handler, http.MethodGet, "/posts", "").Code)
+	})
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // Request/Response DTOs with comprehensive validation
 
@@ -9,12 +12,17 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,min=6" binding:"required,min=6"`
 }
 
+// RegisterRequest requires a username; the display name is optional and
+// defaults to the username when omitted
 type RegisterRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50,alphanum" binding:"required,min=3,max=50"`
 	Email    string `json:"email" validate:"required,email" binding:"required,email"`
 	Password string `json:"password" validate:"required,min=8,max=128" binding:"required,min=8,max=128"`
-	Name     string `json:"name" validate:"required,min=2,max=100" binding:"required,min=2,max=100"`
-	Role     string `json:"role" validate:"omitempty,oneof=admin author" binding:"omitempty,oneof=admin author"`
+	Name     string `json:"name" validate:"omitempty,min=2,max=100" binding:"omitempty,min=2,max=100"`
+	Role     string `json:"role" validate:"omitempty,oneof=author editor" binding:"omitempty,oneof=author editor"`
+	// ClientIP is the address the registration came from, used for the
+	// per-IP registration cap. The handler sets it; it is never bound from a request.
+	ClientIP string `json:"-"`
 }
 
 type RefreshTokenRequest struct {
@@ -29,6 +37,20 @@ type AuthResponse struct {
 	User         User   `json:"user"`
 }
 
+// Permissions lists what the current user's role allows, so clients don't
+// have to hardcode role logic
+type Permissions struct {
+	CanPublish          bool `json:"can_publish"`
+	CanModerate         bool `json:"can_moderate"`
+	CanManageUsers      bool `json:"can_manage_users"`
+	CanManageCategories bool `json:"can_manage_categories"`
+}
+
+type MeResponse struct {
+	User        User        `json:"user"`
+	Permissions Permissions `json:"permissions"`
+}
+
 type RefreshTokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
@@ -40,44 +62,398 @@ type CreatePostRequest struct {
 	Title        string `json:"title" validate:"required,min=5,max=255" binding:"required,min=5,max=255"`
 	Content      string `json:"content" validate:"required,min=50" binding:"required,min=50"`
 	Excerpt      string `json:"excerpt" validate:"omitempty,max=500" binding:"omitempty,max=500"`
-	ThumbnailURL string `json:"thumbnail_url" validate:"omitempty,url" binding:"omitempty,url"`
+	ThumbnailURL string `json:"thumbnail_url" validate:"omitempty,max=500" binding:"omitempty,max=500"`
 	CategoryID   uint   `json:"category_id" validate:"required,gt=0" binding:"required,gt=0"`
 	Status       string `json:"status" validate:"omitempty,oneof=draft published archived" binding:"omitempty,oneof=draft published archived"`
+	// PublishAt schedules the post; a post due in the future is kept as a
+	// draft until then
+	PublishAt *time.Time `json:"publish_at"`
+	// Tags are matched to existing tags by slug and created when new
+	Tags []string `json:"tags" validate:"omitempty,max=10,dive,max=50" binding:"omitempty,max=10,dive,max=50"`
 }
 
 type UpdatePostRequest struct {
-	Title        *string `json:"title" validate:"omitempty,min=5,max=255" binding:"omitempty,min=5,max=255"`
-	Content      *string `json:"content" validate:"omitempty,min=50" binding:"omitempty,min=50"`
-	Excerpt      *string `json:"excerpt" validate:"omitempty,max=500" binding:"omitempty,max=500"`
-	ThumbnailURL *string `json:"thumbnail_url" validate:"omitempty,url" binding:"omitempty,url"`
-	CategoryID   *uint   `json:"category_id" validate:"omitempty,gt=0" binding:"omitempty,gt=0"`
-	Status       *string `json:"status" validate:"omitempty,oneof=draft published archived" binding:"omitempty,oneof=draft published archived"`
+	Title           *string `json:"title" validate:"omitempty,min=5,max=255" binding:"omitempty,min=5,max=255"`
+	Content         *string `json:"content" validate:"omitempty,min=50" binding:"omitempty,min=50"`
+	Excerpt         *string `json:"excerpt" validate:"omitempty,max=500" binding:"omitempty,max=500"`
+	ThumbnailURL    *string `json:"thumbnail_url" validate:"omitempty,max=500" binding:"omitempty,max=500"`
+	CategoryID      *uint   `json:"category_id" validate:"omitempty,gt=0" binding:"omitempty,gt=0"`
+	Status          *string `json:"status" validate:"omitempty,oneof=draft published archived" binding:"omitempty,oneof=draft published archived"`
+	CommentsEnabled *bool   `json:"comments_enabled"`
+	PublishAt       *time.Time `json:"publish_at"`
+	// Tags replaces the post's tags when present; an empty list removes them
+	Tags *[]string `json:"tags" validate:"omitempty,max=10,dive,max=50" binding:"omitempty,max=10,dive,max=50"`
 }
 
 type CreateCategoryRequest struct {
 	Name        string `json:"name" validate:"required,min=2,max=100" binding:"required,min=2,max=100"`
 	Description string `json:"description" validate:"omitempty,max=500" binding:"omitempty,max=500"`
+	ParentID    *uint  `json:"parent_id" validate:"omitempty,gt=0" binding:"omitempty,gt=0"`
+}
+
+// BulkCategoryItem is one category of a bulk create. Parent is the name or
+// slug of an existing category or of one earlier in the same request.
+type BulkCategoryItem struct {
+	Name        string `json:"name" validate:"required,min=2,max=100" binding:"required,min=2,max=100"`
+	Description string `json:"description" validate:"omitempty,max=500" binding:"omitempty,max=500"`
+	Parent      string `json:"parent" validate:"omitempty,max=100" binding:"omitempty,max=100"`
+}
+
+// Outcomes of one item of a bulk category create
+const (
+	BulkItemCreated = "created"
+	BulkItemFailed  = "failed"
+)
+
+// BulkCategoryResult reports what happened to one item of a bulk create
+type BulkCategoryResult struct {
+	Index    int       `json:"index"`
+	Name     string    `json:"name"`
+	Status   string    `json:"status"`
+	Category *Category `json:"category,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// BulkCategoryResponse lists the outcome of every item of a bulk create in
+// request order
+type BulkCategoryResponse struct {
+	Results []BulkCategoryResult `json:"results"`
+	Created int                  `json:"created"`
+	Failed  int                  `json:"failed"`
+}
+
+// Bulk post actions
+const (
+	BulkPostPublish = "publish"
+	BulkPostArchive = "archive"
+	BulkPostDelete  = "delete"
+)
+
+// BulkPostRequest applies one action to many posts at once
+type BulkPostRequest struct {
+	Action string `json:"action" validate:"required,oneof=publish archive delete" binding:"required,oneof=publish archive delete"`
+	IDs    []uint `json:"ids" validate:"required,min=1,max=100" binding:"required,min=1,max=100"`
+}
+
+// Outcomes of one post of a bulk action. When any post fails the whole batch
+// is rolled back, and the others are reported as rolled back.
+const (
+	BulkItemApplied    = "applied"
+	BulkItemRolledBack = "rolled_back"
+)
+
+// BulkPostResult reports what happened to one post of a bulk action
+type BulkPostResult struct {
+	ID     uint   `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
 }
 
+// BulkPostResponse lists the outcome for every distinct ID of a bulk action
+// in request order
+type BulkPostResponse struct {
+	Action  string           `json:"action"`
+	Results []BulkPostResult `json:"results"`
+	Applied int              `json:"applied"`
+	Failed  int              `json:"failed"`
+}
+
+// TransferPostRequest names the author a post is handed to
+type TransferPostRequest struct {
+	AuthorID uint `json:"author_id" binding:"required,gt=0"`
+}
+
+// TransferPostsRequest hands every post of one author to another
+type TransferPostsRequest struct {
+	FromAuthorID uint `json:"from_author_id" binding:"required,gt=0"`
+	ToAuthorID   uint `json:"to_author_id" binding:"required,gt=0,nefield=FromAuthorID"`
+}
+
+// TransferPostsResponse reports how many posts a transfer moved
+type TransferPostsResponse struct {
+	FromAuthorID uint  `json:"from_author_id"`
+	ToAuthorID   uint  `json:"to_author_id"`
+	Transferred  int64 `json:"transferred"`
+}
+
+// AuditLogFilter narrows the audit log. Zero fields match every entry; From
+// is inclusive and To exclusive.
+type AuditLogFilter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditLogPage is one page of the audit log, newest first. NextCursor is sent
+// back as ?cursor= for the following page and is empty on the last one.
+type AuditLogPage struct {
+	Entries    []AuditLog `json:"entries"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// UpdateCategoryRequest changes only the fields it carries. A ParentID of 0
+// moves the category to the top level.
 type UpdateCategoryRequest struct {
 	Name        *string `json:"name" validate:"omitempty,min=2,max=100" binding:"omitempty,min=2,max=100"`
 	Description *string `json:"description" validate:"omitempty,max=500" binding:"omitempty,max=500"`
+	ParentID    *uint   `json:"parent_id"`
 }
 
+// CreateCommentRequest caps Content at a hard ceiling; the comment service
+// applies the tighter per-role length and link limits
 type CreateCommentRequest struct {
-	PostID  uint   `json:"post_id" validate:"required,gt=0" binding:"required,gt=0"`
-	Content string `json:"content" validate:"required,min=5,max=1000" binding:"required,min=5,max=1000"`
+	PostID   uint   `json:"post_id" validate:"required,gt=0" binding:"required,gt=0"`
+	ParentID *uint  `json:"parent_id" validate:"omitempty,gt=0" binding:"omitempty,gt=0"`
+	Content  string `json:"content" validate:"required,min=5,max=10000" binding:"required,min=5,max=10000"`
 }
 
 type UpdateCommentRequest struct {
-	Content *string `json:"content" validate:"omitempty,min=5,max=1000" binding:"omitempty,min=5,max=1000"`
+	Content *string `json:"content" validate:"omitempty,min=5,max=10000" binding:"omitempty,min=5,max=10000"`
 	Status  *string `json:"status" validate:"omitempty,oneof=pending approved rejected" binding:"omitempty,oneof=pending approved rejected"`
 }
 
+// CommentPreviewRequest is comment markdown to render without posting it.
+// Content is bound like CreateCommentRequest's.
+type CommentPreviewRequest struct {
+	Content string `json:"content" validate:"required,min=5,max=10000" binding:"required,min=5,max=10000"`
+}
+
+// CommentPreviewResponse is previewed comment markdown rendered to
+// sanitized HTML
+type CommentPreviewResponse struct {
+	HTML string `json:"html"`
+}
+
+// CategoryNode is a category with its subcategories nested beneath it
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// CommentNode is a comment with its replies nested beneath it
+type CommentNode struct {
+	Comment
+	Replies []*CommentNode `json:"replies"`
+}
+
+// BuildCommentTree nests comments under their parents. A reply whose parent
+// is missing from the set is left out, along with its own replies, since the
+// parent was filtered out. A pinned top-level comment is moved to the front;
+// a pinned reply stays within its thread.
+func BuildCommentTree(comments []Comment) []*CommentNode {
+	nodes := make(map[uint]*CommentNode, len(comments))
+	for _, comment := range comments {
+		nodes[comment.ID] = &CommentNode{Comment: comment, Replies: []*CommentNode{}}
+	}
+
+	roots := []*CommentNode{}
+	for _, comment := range comments {
+		node := nodes[comment.ID]
+		if comment.ParentID == nil {
+			roots = append(roots, node)
+		} else if parent, ok := nodes[*comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+
+	sort.SliceStable(roots, func(i, j int) bool { return roots[i].Pinned && !roots[j].Pinned })
+	return roots
+}
+
+// FlattenCommentTree walks a comment tree depth-first, returning every comment
+// in display order with its parent_id intact
+func FlattenCommentTree(nodes []*CommentNode) []Comment {
+	flat := []Comment{}
+	for _, node := range nodes {
+		flat = append(flat, node.Comment)
+		flat = append(flat, FlattenCommentTree(node.Replies)...)
+	}
+	return flat
+}
+
+// RecentComment is an approved comment with just enough post and author
+// context for a "recent comments" sidebar
+type RecentComment struct {
+	ID        uint              `json:"id"`
+	Content   string            `json:"content"`
+	CreatedAt time.Time         `json:"created_at"`
+	Post      RecentCommentPost `json:"post"`
+	Author    CommentAuthor     `json:"author"`
+}
+
+type RecentCommentPost struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+// CommentSummaryRequest names the posts to summarize comments for
+type CommentSummaryRequest struct {
+	PostIDs []uint `json:"post_ids" binding:"required,min=1,dive,gt=0"`
+}
+
+// PostCommentSummary is a post's approved comment count and its newest
+// approved comment. Latest is nil when the post has no visible comments.
+type PostCommentSummary struct {
+	PostID uint           `json:"post_id"`
+	Count  int64          `json:"count"`
+	Latest *LatestComment `json:"latest"`
+}
+
+type LatestComment struct {
+	ID        uint          `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	Author    CommentAuthor `json:"author"`
+}
+
+// CommentAuthor is the public part of a commenter's profile
+type CommentAuthor struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// CommentTree is a post's complete comment thread. Total counts every
+// comment the viewer may see, including replies below MaxDepth that are
+// left out of the tree.
+type CommentTree struct {
+	Total    int                `json:"total"`
+	MaxDepth int                `json:"max_depth"`
+	Comments []*CommentTreeNode `json:"comments"`
+}
+
+// CommentTreeNode is one comment in a CommentTree. ReplyCount counts its
+// direct replies, including any cut off by the depth limit.
+type CommentTreeNode struct {
+	ID         uint               `json:"id"`
+	ParentID   *uint              `json:"parent_id"`
+	Depth      int                `json:"depth"`
+	Content    string             `json:"content"`
+	Status     string             `json:"status"`
+	Pinned     bool               `json:"pinned"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Author     CommentAuthor      `json:"author"`
+	ReplyCount int                `json:"reply_count"`
+	Replies    []*CommentTreeNode `json:"replies"`
+}
+
+// PostUsage is how many posts an author owns. Limit is zero when the author's
+// role has no limit.
+type PostUsage struct {
+	Count int64 `json:"count"`
+	Limit int   `json:"limit"`
+}
+
+// PostSummary is the list representation of a post. It leaves out the full
+// content, which detail endpoints still return.
+type PostSummary struct {
+	ID              uint       `json:"id"`
+	Title           string     `json:"title"`
+	Slug            string     `json:"slug"`
+	Excerpt         string     `json:"excerpt"`
+	ThumbnailURL    string     `json:"thumbnail_url"`
+	CategoryID      uint       `json:"category_id"`
+	AuthorID        uint       `json:"author_id"`
+	Status          string     `json:"status"`
+	PublishedAt     *time.Time `json:"published_at"`
+	CommentsEnabled bool       `json:"comments_enabled"`
+	ReadingTime     int        `json:"reading_time"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Category        *Category  `json:"category,omitempty"`
+	Author          *User      `json:"author,omitempty"`
+}
+
+// SummarizePosts converts posts to their list representation
+func SummarizePosts(posts []Post) []PostSummary {
+	summaries := make([]PostSummary, len(posts))
+	for i, post := range posts {
+		summaries[i] = PostSummary{
+			ID:              post.ID,
+			Title:           post.Title,
+			Slug:            post.Slug,
+			Excerpt:         post.Excerpt,
+			ThumbnailURL:    post.ThumbnailURL,
+			CategoryID:      post.CategoryID,
+			AuthorID:        post.AuthorID,
+			Status:          post.Status,
+			PublishedAt:     post.PublishedAt,
+			CommentsEnabled: post.CommentsEnabled,
+			ReadingTime:     post.ReadingTime,
+			CreatedAt:       post.CreatedAt,
+			UpdatedAt:       post.UpdatedAt,
+			Category:        post.Category,
+			Author:          post.Author,
+		}
+	}
+	return summaries
+}
+
+// TrashedPost is the trash listing's representation of a deleted post
+type TrashedPost struct {
+	PostSummary
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SummarizeTrashedPosts converts deleted posts to their trash representation
+func SummarizeTrashedPosts(posts []Post) []TrashedPost {
+	summaries := SummarizePosts(posts)
+	trashed := make([]TrashedPost, len(posts))
+	for i, post := range posts {
+		trashed[i] = TrashedPost{PostSummary: summaries[i], DeletedAt: post.DeletedAt.Time}
+	}
+	return trashed
+}
+
 type UpdateProfileRequest struct {
-	Name     *string `json:"name" validate:"omitempty,min=2,max=100" binding:"omitempty,min=2,max=100"`
-	Username *string `json:"username" validate:"omitempty,min=3,max=50,alphanum" binding:"omitempty,min=3,max=50"`
-	Email    *string `json:"email" validate:"omitempty,email" binding:"omitempty,email"`
+	Name            *string `json:"name" validate:"omitempty,min=2,max=100" binding:"omitempty,min=2,max=100"`
+	Username        *string `json:"username" validate:"omitempty,min=3,max=50,alphanum" binding:"omitempty,min=3,max=50"`
+	Email           *string `json:"email" validate:"omitempty,email" binding:"omitempty,email"`
+	NotifyOnComment *bool   `json:"notify_on_comment"`
+}
+
+// NotificationChannels says where one kind of notification is delivered
+type NotificationChannels struct {
+	Email bool `json:"email"`
+	InApp bool `json:"in_app"`
+}
+
+type NotificationPreferencesResponse struct {
+	CommentOnPost NotificationChannels `json:"comment_on_post"`
+	CommentReply  NotificationChannels `json:"comment_reply"`
+	Announcements NotificationChannels `json:"announcements"`
+}
+
+type UpdateNotificationChannelsRequest struct {
+	Email *bool `json:"email"`
+	InApp *bool `json:"in_app"`
+}
+
+// UpdateNotificationPreferencesRequest changes only the channels it names
+type UpdateNotificationPreferencesRequest struct {
+	CommentOnPost *UpdateNotificationChannelsRequest `json:"comment_on_post"`
+	CommentReply  *UpdateNotificationChannelsRequest `json:"comment_reply"`
+	Announcements *UpdateNotificationChannelsRequest `json:"announcements"`
+}
+
+type CreateMaintenanceWindowRequest struct {
+	StartsAt time.Time `json:"starts_at" validate:"required" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required" binding:"required"`
+	Message  string    `json:"message" validate:"required,max=500" binding:"required,max=500"`
+}
+
+type UpdateMaintenanceWindowRequest struct {
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Message  *string    `json:"message" validate:"omitempty,min=1,max=500" binding:"omitempty,min=1,max=500"`
+}
+
+// AnnouncementsResponse lists what the site wants every visitor to know,
+// currently the maintenance windows that are under way or coming up
+type AnnouncementsResponse struct {
+	Maintenance []MaintenanceWindow `json:"maintenance"`
 }
 
 type ChangePasswordRequest struct {
@@ -86,13 +462,66 @@ type ChangePasswordRequest struct {
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword" binding:"required,eqfield=NewPassword"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token           string `json:"token" validate:"required" binding:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=128" binding:"required,min=8,max=128"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword" binding:"required,eqfield=NewPassword"`
+}
+
+// UserListFilter narrows the admin user listing. Search matches part of the
+// username or email.
+type UserListFilter struct {
+	Search string
+	Role   string
+}
+
+// AuthorProfile is the public view of a user, counting only their published
+// posts
+type AuthorProfile struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	PostCount int64     `json:"post_count"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin editor author" binding:"required,oneof=admin editor author"`
+}
+
 // Standard API Response structure
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Code    string      `json:"code,omitempty"`
+	Success  bool        `json:"success"`
+	Message  string      `json:"message,omitempty"`
+	Data     interface{} `json:"data,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Code     string      `json:"code,omitempty"`
+	Warnings []Warning   `json:"warnings,omitempty"`
+}
+
+// Warning codes
+const (
+	WarningDuplicateTitle = "duplicate_title"
+)
+
+// Warning flags something worth the client's attention about a request that
+// still succeeded. Details depend on the code.
+type Warning struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// PostRef identifies a post without its content
+type PostRef struct {
+	ID     uint   `json:"id"`
+	Title  string `json:"title"`
+	Slug   string `json:"slug"`
+	Status string `json:"status"`
 }
 
 // Standard Error Response structure
@@ -122,6 +551,29 @@ type PaginationResponse struct {
 	Page       int         `json:"page"`
 	PerPage    int         `json:"per_page"`
 	TotalPages int         `json:"total_pages"`
+	// SearchMode is set on search responses only
+	SearchMode string `json:"search_mode,omitempty"`
+}
+
+// NotificationListResponse is a page of notifications together with the
+// user's unread total for badges
+type NotificationListResponse struct {
+	PaginationResponse
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// EmailFailure is an email that could not be delivered after every retry,
+// or was dropped because the send queue was full
+type EmailFailure struct {
+	To       string    `json:"to"`
+	Subject  string    `json:"subject"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
 }
 
 // Enhanced pagination response with meta structure
@@ -139,8 +591,17 @@ type MetaData struct {
 	Limit      int `json:"limit"`
 	Total      int64 `json:"total"`
 	TotalPages int `json:"total_pages"`
+	// SearchMode is set on search responses only
+	SearchMode string `json:"search_mode,omitempty"`
 }
 
+// Search modes reported by post search
+const (
+	SearchModeNone     = "none"
+	SearchModeFullText = "fulltext"
+	SearchModeLike     = "like"
+)
+
 // Search and Filter DTOs
 type PostSearchRequest struct {
 	Query      string `form:"q" validate:"omitempty,min=2,max=100" binding:"omitempty,min=2,max=100"`
@@ -151,6 +612,9 @@ type PostSearchRequest struct {
 	Limit      int    `form:"limit" validate:"omitempty,min=1,max=100" binding:"omitempty,min=1,max=100"`
 	Sort       string `form:"sort" validate:"omitempty,oneof=created_at updated_at title id" binding:"omitempty,oneof=created_at updated_at title id"`
 	Order      string `form:"order" validate:"omitempty,oneof=asc desc" binding:"omitempty,oneof=asc desc"`
+	// OwnUnpublishedOf limits non-published results to this user's own posts.
+	// The post service sets it from the viewer; it is never bound from a request.
+	OwnUnpublishedOf uint `form:"-" json:"-"`
 }
 
 // Category search request
@@ -171,6 +635,8 @@ type JWTClaims struct {
 	Type     string `json:"type"` // "access" or "refresh"
 	IssuedAt int64  `json:"iat"`
 	ExpiresAt int64 `json:"exp"`
+	// ID is the access token's jti, used to revoke it before it expires
+	ID string `json:"jti"`
 }
 
 // Refresh Token Model
@@ -182,11 +648,37 @@ type RefreshToken struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	IsRevoked bool      `json:"is_revoked" gorm:"default:false"`
+	// AccessJTI and AccessExpiresAt name the access token issued with this
+	// refresh token, so it can be denied when the user's sessions are revoked
+	AccessJTI       string     `json:"-" gorm:"size:64"`
+	AccessExpiresAt *time.Time `json:"-" gorm:"index"`
 
 	// Relationships
 	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// PasswordResetToken lets a user who forgot their password set a new one.
+// Only a SHA-256 hash of the token is stored, so a leaked table cannot be
+// used to take over accounts.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null;size:64"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RevokedAccessToken denies an access token before it expires. Rows can be
+// removed once ExpiresAt passes, as the token is rejected as expired anyway.
+type RevokedAccessToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	JTI       string    `json:"jti" gorm:"uniqueIndex;not null;size:64"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // Health Check Response
 type HealthResponse struct {
 	Status    string            `json:"status"`
@@ -213,8 +705,11 @@ type UploadResponse struct {
 	Message  string `json:"message"`
 	Filename string `json:"filename"`
 	URL      string `json:"url"`
-	Size     int64  `json:"size"`
-	MimeType string `json:"mime_type"`
+	// ThumbnailURL points at a scaled-down copy for list views. It is the
+	// same as URL when the image needed no thumbnail, e.g. a GIF.
+	ThumbnailURL string `json:"thumbnail_url"`
+	Size         int64  `json:"size"`
+	MimeType     string `json:"mime_type"`
 }
 
 type FileUpload struct {
@@ -232,3 +727,55 @@ type FileUpload struct {
 	// Relationships
 	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
+
+// PostStats aggregates a post's activity for its author
+type PostStats struct {
+	PostID   uint              `json:"post_id"`
+	Views    int64             `json:"views"`
+	Comments PostCommentCounts `json:"comments"`
+	// RecentViews counts views over the last VelocityDays days, including
+	// today, and ViewsPerDay averages them
+	RecentViews  int64   `json:"recent_views"`
+	ViewsPerDay  float64 `json:"views_per_day"`
+	VelocityDays int     `json:"velocity_days"`
+}
+
+// PostCommentCounts breaks a post's comments down by moderation status
+type PostCommentCounts struct {
+	Approved int64 `json:"approved"`
+	Pending  int64 `json:"pending"`
+	Rejected int64 `json:"rejected"`
+}
+
+// StatsResponse summarises the whole site for the admin dashboard
+type StatsResponse struct {
+	Users    UserCounts    `json:"users"`
+	Posts    PostCounts    `json:"posts"`
+	Comments CommentCounts `json:"comments"`
+	// RecentPosts are the ten most recently created posts in any status
+	RecentPosts []Post `json:"recent_posts"`
+}
+
+// UserCounts breaks users down by role
+type UserCounts struct {
+	Total  int64 `json:"total"`
+	Admin  int64 `json:"admin"`
+	Editor int64 `json:"editor"`
+	Author int64 `json:"author"`
+}
+
+// PostCounts breaks posts down by status
+type PostCounts struct {
+	Total     int64 `json:"total"`
+	Draft     int64 `json:"draft"`
+	Published int64 `json:"published"`
+	Archived  int64 `json:"archived"`
+}
+
+// CommentCounts breaks comments down by moderation status
+type CommentCounts struct {
+	Total    int64 `json:"total"`
+	Approved int64 `json:"approved"`
+	Pending  int64 `json:"pending"`
+	Rejected int64 `json:"rejected"`
+}
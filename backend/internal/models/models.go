@@ -7,15 +7,28 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Username  string         `json:"username" gorm:"uniqueIndex;not null;size:50"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null;size:100"`
-	Name      string         `json:"name" gorm:"not null;size:100"`
-	Password  string         `json:"-" gorm:"not null;size:255"`
-	Role      string         `json:"role" gorm:"not null;type:enum('admin','author');default:'author'"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                    uint           `json:"id" gorm:"primaryKey"`
+	Username              string         `json:"username" gorm:"uniqueIndex;not null;size:50"`
+	Email                 string         `json:"email" gorm:"uniqueIndex;not null;size:100"`
+	Name                  string         `json:"name" gorm:"not null;size:100"`
+	Password              string         `json:"-" gorm:"not null;size:255"`
+	Role                  string         `json:"role" gorm:"not null;type:enum('admin','editor','author');default:'author'"`
+	NotifyOnComment       bool           `json:"notify_on_comment" gorm:"not null;default:true"`
+	EmailVerified         bool           `json:"email_verified" gorm:"not null;default:false"`
+	VerificationToken     string         `json:"-" gorm:"size:64;index"`
+	VerificationExpiresAt *time.Time     `json:"-"`
+	FailedLoginCount      int            `json:"-" gorm:"not null;default:0"`
+	LastFailedLoginAt     *time.Time     `json:"-"`
+	LockedUntil           *time.Time     `json:"-"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `json:"-" gorm:"index"`
+
+	NotificationPreferences NotificationPreferences `json:"-" gorm:"embedded;embeddedPrefix:notify_"`
+
+	// PostCount is filled in by listings that show how many posts each user
+	// has written. It is not stored.
+	PostCount *int64 `json:"post_count,omitempty" gorm:"-"`
 
 	// Relationships
 	Posts         []Post         `json:"posts,omitempty" gorm:"foreignKey:AuthorID"`
@@ -23,11 +36,25 @@ type User struct {
 	RefreshTokens []RefreshToken `json:"-" gorm:"foreignKey:UserID"`
 }
 
+// NotificationPreferences records where a user wants each kind of
+// notification delivered. A nil field has never been set, so the default
+// applies.
+type NotificationPreferences struct {
+	CommentOnPostEmail *bool
+	CommentOnPostInApp *bool
+	CommentReplyEmail  *bool
+	CommentReplyInApp  *bool
+	AnnouncementsEmail *bool
+	AnnouncementsInApp *bool
+}
+
 type Category struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	Name        string         `json:"name" gorm:"not null;size:100;index:idx_categories_name"`
 	Slug        string         `json:"slug" gorm:"uniqueIndex;not null;size:100"`
 	Description string         `json:"description" gorm:"type:text"`
+	ParentID    *uint          `json:"parent_id,omitempty" gorm:"index"`
+	PostCount   int64          `json:"post_count" gorm:"not null;default:0"`
 	CreatedAt   time.Time      `json:"created_at" gorm:"index:idx_categories_created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -36,37 +63,147 @@ type Category struct {
 	Posts []Post `json:"posts,omitempty" gorm:"foreignKey:CategoryID"`
 }
 
+// Tag is a free-form label; a post can carry several
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null;size:50"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null;size:50"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Post struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	Title        string         `json:"title" gorm:"not null;size:255;index:idx_posts_title"`
-	Slug         string         `json:"slug" gorm:"uniqueIndex;not null;size:255"`
-	Content      string         `json:"content" gorm:"not null;type:text"`
-	Excerpt      string         `json:"excerpt" gorm:"type:text"`
-	ThumbnailURL string         `json:"thumbnail_url" gorm:"size:500"`
-	CategoryID   uint           `json:"category_id" gorm:"not null;index:idx_posts_category_id,idx_posts_category_status"`
-	AuthorID     uint           `json:"author_id" gorm:"not null;index:idx_posts_author_id,idx_posts_author_status"`
-	Status       string         `json:"status" gorm:"not null;type:enum('draft','published','archived');default:'draft';index:idx_posts_status,idx_posts_status_created_at,idx_posts_category_status,idx_posts_author_status"`
-	CreatedAt    time.Time      `json:"created_at" gorm:"index:idx_posts_created_at,idx_posts_status_created_at"`
-	UpdatedAt    time.Time      `json:"updated_at" gorm:"index:idx_posts_updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Title           string         `json:"title" gorm:"not null;size:255;index:idx_posts_title"`
+	Slug            string         `json:"slug" gorm:"uniqueIndex;not null;size:255"`
+	Content         string         `json:"content" gorm:"not null;type:text"`
+	Excerpt         string         `json:"excerpt" gorm:"type:text"`
+	ThumbnailURL    string         `json:"thumbnail_url" gorm:"size:500"`
+	CategoryID      uint           `json:"category_id" gorm:"not null;index:idx_posts_category_id,idx_posts_category_status"`
+	AuthorID        uint           `json:"author_id" gorm:"not null;index:idx_posts_author_id,idx_posts_author_status"`
+	Status          string         `json:"status" gorm:"not null;type:enum('draft','published','archived');default:'draft';index:idx_posts_status,idx_posts_status_created_at,idx_posts_category_status,idx_posts_author_status"`
+	PublishedAt     *time.Time     `json:"published_at"`
+	PublishAt       *time.Time     `json:"publish_at" gorm:"index:idx_posts_publish_at"`
+	CommentsEnabled bool           `json:"comments_enabled" gorm:"not null;default:true"`
+	ViewCount       uint           `json:"view_count" gorm:"not null;default:0"`
+	WordCount       int            `json:"word_count" gorm:"not null;default:0"`
+	ReadingTime     int            `json:"reading_time" gorm:"not null;default:0"` // minutes
+	UpdatedBy       *uint          `json:"updated_by,omitempty"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"index:idx_posts_created_at,idx_posts_status_created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"index:idx_posts_updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
 	Author   *User     `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
 	Comments []Comment `json:"comments,omitempty" gorm:"foreignKey:PostID"`
+	Tags     []Tag     `json:"tags,omitempty" gorm:"many2many:post_tags"`
+}
+
+// DeletedName is the name of the placeholder standing in for a post's
+// deleted author or category
+const DeletedName = "(deleted)"
+
+// FillDeletedRelations gives a post loaded with its author and category a
+// placeholder for either one whose row is gone, so neither is ever nil. With
+// placeholders set, soft-deleted ones are replaced as well instead of being
+// shown as stored. A placeholder keeps only the ID and DeletedName.
+func (p *Post) FillDeletedRelations(placeholders bool) {
+	if p.Author == nil || (placeholders && p.Author.DeletedAt.Valid) {
+		p.Author = &User{ID: p.AuthorID, Username: DeletedName, Name: DeletedName}
+	}
+	if p.Category == nil || (placeholders && p.Category.DeletedAt.Valid) {
+		p.Category = &Category{ID: p.CategoryID, Name: DeletedName}
+	}
 }
 
 type Comment struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	PostID    uint           `json:"post_id" gorm:"not null"`
 	UserID    uint           `json:"user_id" gorm:"not null"`
+	ParentID  *uint          `json:"parent_id" gorm:"index"`
+	Depth     int            `json:"depth" gorm:"not null;default:0"`
 	Content   string         `json:"content" gorm:"not null;type:text"`
 	Status    string         `json:"status" gorm:"not null;type:enum('pending','approved','rejected');default:'pending'"`
+	Pinned    bool           `json:"pinned" gorm:"not null;default:false"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// The admin who last approved or rejected the comment, and when
+	ModeratedBy *uint      `json:"moderated_by,omitempty"`
+	ModeratedAt *time.Time `json:"moderated_at,omitempty"`
+
 	// Relationships
 	Post *Post `json:"post,omitempty" gorm:"foreignKey:PostID"`
 	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
+
+// PostViewDay counts a post's views on one day. Day is a UTC date
+// (YYYY-MM-DD) kept as text so MySQL and SQLite compare it the same way.
+type PostViewDay struct {
+	PostID uint   `json:"post_id" gorm:"primaryKey"`
+	Day    string `json:"day" gorm:"primaryKey;size:10"`
+	Views  int64  `json:"views" gorm:"not null;default:0"`
+}
+
+// RecentView records when a user last read a post. Each user keeps only
+// their most recent views.
+type RecentView struct {
+	UserID   uint      `json:"user_id" gorm:"primaryKey;index:idx_recent_views_user_viewed"`
+	PostID   uint      `json:"post_id" gorm:"primaryKey"`
+	ViewedAt time.Time `json:"viewed_at" gorm:"not null;index:idx_recent_views_user_viewed"`
+}
+
+// Notification is an in-app message for a single user
+type Notification struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index:idx_notifications_user_read"`
+	Type      string    `json:"type" gorm:"not null;size:50"`
+	Message   string    `json:"message" gorm:"not null;type:text"`
+	RefType   string    `json:"ref_type" gorm:"size:50"`
+	RefID     uint      `json:"ref_id"`
+	Read      bool      `json:"read" gorm:"not null;default:false;index:idx_notifications_user_read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notification types
+const (
+	NotificationCommentOnPost   = "comment_on_post"
+	NotificationCommentApproved = "comment_approved"
+	NotificationDraftArchived   = "draft_archived"
+	NotificationCommentReply    = "comment_reply"
+	NotificationAnnouncement    = "announcement"
+	// Account emails, sent straight to the address rather than the inbox
+	NotificationEmailVerification = "email_verification"
+	NotificationPasswordReset     = "password_reset"
+)
+
+// MaintenanceWindow is a period, scheduled in advance, during which the API
+// refuses writes and shows Message instead
+type MaintenanceWindow struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	StartsAt  time.Time `json:"starts_at" gorm:"not null;index"`
+	EndsAt    time.Time `json:"ends_at" gorm:"not null;index"`
+	Message   string    `json:"message" gorm:"not null;type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Audited actions, see AuditLog.Action
+const (
+	AuditPostTransfer  = "post.transfer"
+	AuditPostsTransfer = "posts.transfer"
+)
+
+// AuditLog records a change made by an admin: who made it, what it did and
+// to which record
+type AuditLog struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	ActorID    uint   `json:"actor_id" gorm:"not null;index"`
+	Action     string `json:"action" gorm:"not null;size:50;index"`
+	TargetType string `json:"target_type" gorm:"not null;size:50;index:idx_audit_logs_target"`
+	TargetID   uint   `json:"target_id" gorm:"not null;index:idx_audit_logs_target"`
+	// Details describes the change, e.g. the values before and after it
+	Details   map[string]interface{} `json:"details,omitempty" gorm:"serializer:json;type:text"`
+	CreatedAt time.Time              `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository interface {
+	// WithContext returns a copy of the repository whose queries run with
+	// ctx, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) AuditLogRepository
+	Create(entry *models.AuditLog) error
+	// List returns up to limit entries matching filter, newest first. With a
+	// non-zero beforeID it continues below that entry, so pages stay stable
+	// while new entries are written.
+	List(filter models.AuditLogFilter, beforeID uint, limit int) ([]models.AuditLog, error)
+	// PurgeBefore deletes the entries created before olderThan
+	PurgeBefore(olderThan time.Time) (int64, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) WithContext(ctx context.Context) AuditLogRepository {
+	return &auditLogRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *auditLogRepository) Create(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *auditLogRepository) List(filter models.AuditLogFilter, beforeID uint, limit int) ([]models.AuditLog, error) {
+	query := r.db.Model(&models.AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var entries []models.AuditLog
+	err := query.Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+func (r *auditLogRepository) PurgeBefore(olderThan time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", olderThan).Delete(&models.AuditLog{})
+	return result.RowsAffected, result.Error
+}
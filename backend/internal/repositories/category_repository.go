@@ -1,19 +1,44 @@
 package repositories
 
 import (
+	"context"
+
 	"backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
 type CategoryRepository interface {
+	// WithContext returns a copy of the repository whose queries run with
+	// ctx, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) CategoryRepository
 	Create(category *models.Category) error
 	GetByID(id uint) (*models.Category, error)
+	GetByIDs(ids []uint) (map[uint]*models.Category, error)
 	GetBySlug(slug string) (*models.Category, error)
 	Update(category *models.Category) error
 	Delete(id uint) error
 	List(page, perPage int) ([]models.Category, int64, error)
+	// ListWithCounts is List with each category's PostCount counted live
+	// from its published posts, leaving out drafts and deleted posts
+	ListWithCounts(page, perPage int) ([]models.Category, int64, error)
+	// Search filters and sorts categories, counting their posts the same way
+	// as ListWithCounts
 	Search(req *models.CategorySearchRequest) ([]models.Category, int64, error)
+	AdjustPostCount(ctx context.Context, id uint, delta int64) error
+	SetPostCount(ctx context.Context, id uint, count int64) error
+	ListPostCounts(ctx context.Context) (map[uint]int64, error)
+	// WithTransaction runs fn with a repository whose queries share one
+	// transaction, committed if fn returns nil and rolled back otherwise
+	WithTransaction(fn func(repo CategoryRepository) error) error
+	// GetTree returns every category nested under its parent, each level
+	// ordered by name
+	GetTree() ([]*models.CategoryNode, error)
+	// CountChildren returns how many categories have id as their parent
+	CountChildren(id uint) (int64, error)
+	// ReparentChildren moves the children of id under parentID, or to the
+	// top level when parentID is nil
+	ReparentChildren(id uint, parentID *uint) error
 }
 
 type categoryRepository struct {
@@ -24,8 +49,20 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
+func (r *categoryRepository) WithContext(ctx context.Context) CategoryRepository {
+	return &categoryRepository{db: r.db.WithContext(ctx)}
+}
+
+// Create inserts the category. A slug that is already taken is reported as
+// gorm.ErrDuplicatedKey.
 func (r *categoryRepository) Create(category *models.Category) error {
-	return r.db.Create(category).Error
+	return translateError(r.db, r.db.Create(category).Error)
+}
+
+func (r *categoryRepository) WithTransaction(fn func(repo CategoryRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&categoryRepository{db: tx})
+	})
 }
 
 func (r *categoryRepository) GetByID(id uint) (*models.Category, error) {
@@ -37,6 +74,24 @@ func (r *categoryRepository) GetByID(id uint) (*models.Category, error) {
 	return &category, nil
 }
 
+// GetByIDs loads several categories in one query, keyed by ID. Missing and
+// deleted IDs are absent from the map.
+func (r *categoryRepository) GetByIDs(ids []uint) (map[uint]*models.Category, error) {
+	byID := make(map[uint]*models.Category, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	var categories []models.Category
+	if err := r.db.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+	return byID, nil
+}
+
 func (r *categoryRepository) GetBySlug(slug string) (*models.Category, error) {
 	var category models.Category
 	err := r.db.Where("slug = ?", slug).First(&category).Error
@@ -47,13 +102,55 @@ func (r *categoryRepository) GetBySlug(slug string) (*models.Category, error) {
 }
 
 func (r *categoryRepository) Update(category *models.Category) error {
-	return r.db.Save(category).Error
+	// post_count is maintained separately; never overwrite it with a stale copy
+	return r.db.Omit("post_count").Save(category).Error
 }
 
 func (r *categoryRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Category{}, id).Error
 }
 
+func (r *categoryRepository) GetTree() ([]*models.CategoryNode, error) {
+	var categories []models.Category
+	if err := r.db.Order("name ASC, id ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return BuildCategoryTree(categories), nil
+}
+
+func (r *categoryRepository) CountChildren(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+func (r *categoryRepository) ReparentChildren(id uint, parentID *uint) error {
+	return r.db.Model(&models.Category{}).Where("parent_id = ?", id).Update("parent_id", parentID).Error
+}
+
+// BuildCategoryTree nests categories under their parents, keeping the order
+// they were given in at each level. Categories whose parent is missing from
+// the set, such as a deleted one, are treated as top-level.
+func BuildCategoryTree(categories []models.Category) []*models.CategoryNode {
+	nodes := make(map[uint]*models.CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &models.CategoryNode{Category: category, Children: []*models.CategoryNode{}}
+	}
+
+	roots := []*models.CategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID != nil {
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
 func (r *categoryRepository) List(page, perPage int) ([]models.Category, int64, error) {
 	var categories []models.Category
 	var total int64
@@ -68,6 +165,25 @@ func (r *categoryRepository) List(page, perPage int) ([]models.Category, int64,
 	return categories, total, err
 }
 
+// publishedPostCount selects a category's published, non-deleted posts as
+// post_count, taking the place of the cached column
+const publishedPostCount = "(SELECT COUNT(*) FROM posts WHERE posts.category_id = categories.id AND posts.status = 'published' AND posts.deleted_at IS NULL) AS post_count"
+
+func (r *categoryRepository) ListWithCounts(page, perPage int) ([]models.Category, int64, error) {
+	var categories []models.Category
+	var total int64
+
+	offset := (page - 1) * perPage
+
+	if err := r.db.Model(&models.Category{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := r.db.Select("categories.*, " + publishedPostCount).
+		Order("categories.created_at DESC").Offset(offset).Limit(perPage).Find(&categories).Error
+	return categories, total, err
+}
+
 // Search categories with filtering and sorting
 func (r *categoryRepository) Search(req *models.CategorySearchRequest) ([]models.Category, int64, error) {
 	var categories []models.Category
@@ -105,6 +221,38 @@ func (r *categoryRepository) Search(req *models.CategorySearchRequest) ([]models
 
 	// Apply sorting and pagination
 	orderClause := req.Sort + " " + req.Order
-	err := query.Order(orderClause).Offset(offset).Limit(req.Limit).Find(&categories).Error
+	err := query.Select("categories.*, " + publishedPostCount).
+		Order(orderClause).Offset(offset).Limit(req.Limit).Find(&categories).Error
 	return categories, total, err
 }
+
+// AdjustPostCount atomically adds delta to a category's cached post count,
+// never letting it drop below zero
+func (r *categoryRepository) AdjustPostCount(ctx context.Context, id uint, delta int64) error {
+	return r.db.WithContext(ctx).Model(&models.Category{}).
+		Where("id = ?", id).
+		UpdateColumn("post_count", gorm.Expr("CASE WHEN post_count + ? < 0 THEN 0 ELSE post_count + ? END", delta, delta)).
+		Error
+}
+
+// SetPostCount overwrites a category's cached post count
+func (r *categoryRepository) SetPostCount(ctx context.Context, id uint, count int64) error {
+	return r.db.WithContext(ctx).Model(&models.Category{}).
+		Where("id = ?", id).
+		UpdateColumn("post_count", count).
+		Error
+}
+
+// ListPostCounts returns the cached post count of every category keyed by id
+func (r *categoryRepository) ListPostCounts(ctx context.Context) (map[uint]int64, error) {
+	var categories []models.Category
+	if err := r.db.WithContext(ctx).Select("id, post_count").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(categories))
+	for _, category := range categories {
+		counts[category.ID] = category.PostCount
+	}
+	return counts, nil
+}
@@ -1,19 +1,53 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
 type CommentRepository interface {
+	// WithContext returns a copy of the repository whose queries run with
+	// ctx, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) CommentRepository
 	Create(comment *models.Comment) error
 	GetByID(id uint) (*models.Comment, error)
 	Update(comment *models.Comment) error
 	Delete(id uint) error
 	List(page, perPage int, filters map[string]interface{}) ([]models.Comment, int64, error)
-	GetByPost(postID uint, page, perPage int) ([]models.Comment, int64, error)
-	GetByUser(userID uint, page, perPage int) ([]models.Comment, int64, error)
+	// GetByPost lists a post's comments with the given status, or every
+	// comment when status is empty, pinned first and then oldest first
+	GetByPost(postID uint, status string, page, perPage int) ([]models.Comment, int64, error)
+	// GetApprovedByPost is GetByPost limited to approved comments, the ones
+	// readers may see
+	GetApprovedByPost(postID uint, page, perPage int) ([]models.Comment, int64, error)
+	// GetByUser lists a user's comments with the given status, or every
+	// comment when status is empty
+	GetByUser(userID uint, status string, page, perPage int) ([]models.Comment, int64, error)
+	// GetThreaded returns a post's comments with the given status, or all of
+	// them when status is empty, nested under their parents oldest first.
+	// Replies to a comment left out by status are left out with it.
+	GetThreaded(postID uint, status string) ([]*models.CommentNode, error)
+	// GetTree returns every comment on a post with its author in one query,
+	// ordered by depth so parents always come before their replies
+	GetTree(postID uint) ([]models.Comment, error)
+	// Pin marks a comment as its post's pinned comment, unpinning any other
+	Pin(postID, id uint) error
+	Unpin(id uint) error
+	// GetRecent returns the newest approved comments on published posts
+	GetRecent(limit int) ([]models.RecentComment, error)
+	// GetSummaries counts the approved comments on each of the given
+	// published posts and finds the newest one. Posts without any are
+	// absent from the map.
+	GetSummaries(postIDs []uint) (map[uint]models.PostCommentSummary, error)
+	// ListPending returns the comments awaiting moderation, oldest first,
+	// with their post and author
+	ListPending(page, perPage int) ([]models.Comment, int64, error)
+	// Moderate sets a comment's status and records who moderated it and when
+	Moderate(id uint, status string, moderatorID uint) error
 }
 
 type commentRepository struct {
@@ -24,6 +58,10 @@ func NewCommentRepository(db *gorm.DB) CommentRepository {
 	return &commentRepository{db: db}
 }
 
+func (r *commentRepository) WithContext(ctx context.Context) CommentRepository {
+	return &commentRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *commentRepository) Create(comment *models.Comment) error {
 	return r.db.Create(comment).Error
 }
@@ -65,32 +103,214 @@ func (r *commentRepository) List(page, perPage int, filters map[string]interface
 	return comments, total, err
 }
 
-func (r *commentRepository) GetByPost(postID uint, page, perPage int) ([]models.Comment, int64, error) {
+func (r *commentRepository) GetByPost(postID uint, status string, page, perPage int) ([]models.Comment, int64, error) {
 	var comments []models.Comment
 	var total int64
 
 	offset := (page - 1) * perPage
+	query := r.db.Model(&models.Comment{}).Preload("User").Where("post_id = ?", postID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
 
-	if err := r.db.Model(&models.Comment{}).Where("post_id = ?", postID).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := r.db.Preload("User").Where("post_id = ?", postID).
+	err := query.Order("pinned DESC, created_at ASC, id ASC").
 		Offset(offset).Limit(perPage).Find(&comments).Error
 	return comments, total, err
 }
 
-func (r *commentRepository) GetByUser(userID uint, page, perPage int) ([]models.Comment, int64, error) {
+func (r *commentRepository) GetApprovedByPost(postID uint, page, perPage int) ([]models.Comment, int64, error) {
+	return r.GetByPost(postID, "approved", page, perPage)
+}
+
+func (r *commentRepository) ListPending(page, perPage int) ([]models.Comment, int64, error) {
 	var comments []models.Comment
 	var total int64
 
 	offset := (page - 1) * perPage
 
-	if err := r.db.Model(&models.Comment{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := r.db.Model(&models.Comment{}).Where("status = ?", "pending").Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := r.db.Preload("Post").Where("user_id = ?", userID).
+	err := r.db.Preload("Post").Preload("User").Where("status = ?", "pending").
+		Order("created_at ASC, id ASC").
 		Offset(offset).Limit(perPage).Find(&comments).Error
 	return comments, total, err
 }
+
+// Moderate reports gorm.ErrRecordNotFound when there is no such comment
+func (r *commentRepository) Moderate(id uint, status string, moderatorID uint) error {
+	result := r.db.Model(&models.Comment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       status,
+		"moderated_by": moderatorID,
+		"moderated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *commentRepository) GetByUser(userID uint, status string, page, perPage int) ([]models.Comment, int64, error) {
+	var comments []models.Comment
+	var total int64
+
+	offset := (page - 1) * perPage
+	query := r.db.Model(&models.Comment{}).Preload("Post").Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Offset(offset).Limit(perPage).Find(&comments).Error
+	return comments, total, err
+}
+
+func (r *commentRepository) GetThreaded(postID uint, status string) ([]*models.CommentNode, error) {
+	var comments []models.Comment
+	query := r.db.Preload("User").Where("post_id = ?", postID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("created_at ASC, id ASC").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return models.BuildCommentTree(comments), nil
+}
+
+func (r *commentRepository) GetTree(postID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.Joins("User").Where("comments.post_id = ?", postID).
+		Order("comments.depth ASC, comments.created_at ASC, comments.id ASC").
+		Find(&comments).Error
+	return comments, err
+}
+
+func (r *commentRepository) Pin(postID, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Comment{}).
+			Where("post_id = ? AND pinned = ? AND id <> ?", postID, true, id).
+			Update("pinned", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Comment{}).Where("id = ?", id).Update("pinned", true).Error
+	})
+}
+
+func (r *commentRepository) Unpin(id uint) error {
+	return r.db.Model(&models.Comment{}).Where("id = ?", id).Update("pinned", false).Error
+}
+
+func (r *commentRepository) GetRecent(limit int) ([]models.RecentComment, error) {
+	var rows []struct {
+		ID             uint
+		Content        string
+		CreatedAt      time.Time
+		PostID         uint
+		PostTitle      string
+		PostSlug       string
+		AuthorID       uint
+		AuthorUsername string
+		AuthorName     string
+	}
+
+	err := r.db.Model(&models.Comment{}).
+		Select("comments.id, comments.content, comments.created_at, "+
+			"posts.id AS post_id, posts.title AS post_title, posts.slug AS post_slug, "+
+			"users.id AS author_id, users.username AS author_username, users.name AS author_name").
+		Joins("JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = comments.user_id AND users.deleted_at IS NULL").
+		Where("comments.status = ? AND posts.status = ?", "approved", "published").
+		Order("comments.created_at DESC, comments.id DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]models.RecentComment, len(rows))
+	for i, row := range rows {
+		comments[i] = models.RecentComment{
+			ID:        row.ID,
+			Content:   row.Content,
+			CreatedAt: row.CreatedAt,
+			Post:      models.RecentCommentPost{ID: row.PostID, Title: row.PostTitle, Slug: row.PostSlug},
+			Author:    models.CommentAuthor{ID: row.AuthorID, Username: row.AuthorUsername, Name: row.AuthorName},
+		}
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) GetSummaries(postIDs []uint) (map[uint]models.PostCommentSummary, error) {
+	summaries := make(map[uint]models.PostCommentSummary, len(postIDs))
+	if len(postIDs) == 0 {
+		return summaries, nil
+	}
+
+	var counts []struct {
+		PostID uint
+		Count  int64
+	}
+	err := r.visibleOn(postIDs).
+		Select("comments.post_id, COUNT(*) AS count").
+		Group("comments.post_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range counts {
+		summaries[row.PostID] = models.PostCommentSummary{PostID: row.PostID, Count: row.Count}
+	}
+
+	// The newest comment per post, found with a correlated subquery so the
+	// same SQL runs on MySQL and SQLite
+	var latest []struct {
+		ID             uint
+		PostID         uint
+		CreatedAt      time.Time
+		AuthorID       uint
+		AuthorUsername string
+		AuthorName     string
+	}
+	err = r.visibleOn(postIDs).
+		Select("comments.id, comments.post_id, comments.created_at, "+
+			"users.id AS author_id, users.username AS author_username, users.name AS author_name").
+		Joins("JOIN users ON users.id = comments.user_id").
+		Where("comments.id = (?)", r.db.Table("comments AS newer").
+			Select("newer.id").
+			Where("newer.post_id = comments.post_id AND newer.status = ? AND newer.deleted_at IS NULL", "approved").
+			Order("newer.created_at DESC, newer.id DESC").
+			Limit(1)).
+		Scan(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range latest {
+		summary := summaries[row.PostID]
+		summary.Latest = &models.LatestComment{
+			ID:        row.ID,
+			CreatedAt: row.CreatedAt,
+			Author:    models.CommentAuthor{ID: row.AuthorID, Username: row.AuthorUsername, Name: row.AuthorName},
+		}
+		summaries[row.PostID] = summary
+	}
+	return summaries, nil
+}
+
+// visibleOn scopes a query to the approved comments on the given published
+// posts
+func (r *commentRepository) visibleOn(postIDs []uint) *gorm.DB {
+	return r.db.Model(&models.Comment{}).
+		Joins("JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Where("comments.post_id IN ? AND comments.status = ? AND posts.status = ?", postIDs, "approved", "published")
+}
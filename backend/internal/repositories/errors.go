@@ -0,0 +1,16 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// translateError maps driver-specific errors to gorm's portable ones, e.g. a
+// unique index violation to gorm.ErrDuplicatedKey, so services can react to
+// them without knowing which database is in use
+func translateError(db *gorm.DB, err error) error {
+	if err == nil {
+		return nil
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		return translator.Translate(err)
+	}
+	return err
+}
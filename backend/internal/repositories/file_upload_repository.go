@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type FileUploadRepository interface {
+	Create(upload *models.FileUpload) error
+	GetByID(id uint) (*models.FileUpload, error)
+	GetByFilename(filename string) (*models.FileUpload, error)
+	// GetByUser returns a user's uploads, newest first
+	GetByUser(userID uint, page, perPage int) ([]models.FileUpload, int64, error)
+	Delete(id uint) error
+	// ListOrphaned returns uploads created before the cutoff that no post
+	// uses as its thumbnail or mentions in its content
+	ListOrphaned(createdBefore time.Time) ([]models.FileUpload, error)
+}
+
+type fileUploadRepository struct {
+	db *gorm.DB
+}
+
+func NewFileUploadRepository(db *gorm.DB) FileUploadRepository {
+	return &fileUploadRepository{db: db}
+}
+
+func (r *fileUploadRepository) Create(upload *models.FileUpload) error {
+	return r.db.Create(upload).Error
+}
+
+func (r *fileUploadRepository) GetByID(id uint) (*models.FileUpload, error) {
+	var upload models.FileUpload
+	if err := r.db.First(&upload, id).Error; err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *fileUploadRepository) GetByFilename(filename string) (*models.FileUpload, error) {
+	var upload models.FileUpload
+	if err := r.db.Where("filename = ?", filename).First(&upload).Error; err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *fileUploadRepository) GetByUser(userID uint, page, perPage int) ([]models.FileUpload, int64, error) {
+	var uploads []models.FileUpload
+	var total int64
+
+	offset := (page - 1) * perPage
+
+	query := r.db.Model(&models.FileUpload{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(perPage).Find(&uploads).Error
+	return uploads, total, err
+}
+
+func (r *fileUploadRepository) Delete(id uint) error {
+	return r.db.Delete(&models.FileUpload{}, id).Error
+}
+
+func (r *fileUploadRepository) ListOrphaned(createdBefore time.Time) ([]models.FileUpload, error) {
+	var candidates []models.FileUpload
+	if err := r.db.Where("created_at < ?", createdBefore).Order("id").Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	// Posts may reference an upload through the storage URL or a CDN, so
+	// match on the stored filename rather than the full URL
+	orphaned := make([]models.FileUpload, 0, len(candidates))
+	for _, upload := range candidates {
+		pattern := "%" + upload.Filename + "%"
+		var uses int64
+		err := r.db.Model(&models.Post{}).
+			Where("thumbnail_url LIKE ? OR content LIKE ?", pattern, pattern).
+			Count(&uses).Error
+		if err != nil {
+			return nil, err
+		}
+		if uses == 0 {
+			orphaned = append(orphaned, upload)
+		}
+	}
+	return orphaned, nil
+}
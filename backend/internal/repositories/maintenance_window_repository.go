@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type MaintenanceWindowRepository interface {
+	Create(window *models.MaintenanceWindow) error
+	GetByID(id uint) (*models.MaintenanceWindow, error)
+	Update(window *models.MaintenanceWindow) error
+	Delete(id uint) error
+	// List pages through every window, latest start first
+	List(page, perPage int) ([]models.MaintenanceWindow, int64, error)
+	// ListNotEnded returns the windows still under way or yet to come at
+	// now, soonest first
+	ListNotEnded(now time.Time) ([]models.MaintenanceWindow, error)
+}
+
+type maintenanceWindowRepository struct {
+	db *gorm.DB
+}
+
+func NewMaintenanceWindowRepository(db *gorm.DB) MaintenanceWindowRepository {
+	return &maintenanceWindowRepository{db: db}
+}
+
+func (r *maintenanceWindowRepository) Create(window *models.MaintenanceWindow) error {
+	return r.db.Create(window).Error
+}
+
+func (r *maintenanceWindowRepository) GetByID(id uint) (*models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	if err := r.db.First(&window, id).Error; err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+func (r *maintenanceWindowRepository) Update(window *models.MaintenanceWindow) error {
+	return r.db.Save(window).Error
+}
+
+func (r *maintenanceWindowRepository) Delete(id uint) error {
+	return r.db.Delete(&models.MaintenanceWindow{}, id).Error
+}
+
+func (r *maintenanceWindowRepository) List(page, perPage int) ([]models.MaintenanceWindow, int64, error) {
+	var windows []models.MaintenanceWindow
+	var total int64
+
+	query := r.db.Model(&models.MaintenanceWindow{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Order("starts_at DESC, id DESC").Offset(offset).Limit(perPage).Find(&windows).Error
+	return windows, total, err
+}
+
+func (r *maintenanceWindowRepository) ListNotEnded(now time.Time) ([]models.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	err := r.db.Where("ends_at > ?", now).Order("starts_at ASC, id ASC").Find(&windows).Error
+	return windows, err
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	Create(notification *models.Notification) error
+	// ListByUser returns a user's notifications, newest first. A non-nil read
+	// filter limits the results to read or unread notifications.
+	ListByUser(userID uint, read *bool, page, perPage int) ([]models.Notification, int64, error)
+	CountUnread(userID uint) (int64, error)
+	// MarkRead marks one of the user's notifications as read. It returns
+	// gorm.ErrRecordNotFound if the notification does not belong to the user.
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(notification *models.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+func (r *notificationRepository) ListByUser(userID uint, read *bool, page, perPage int) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	offset := (page - 1) * perPage
+
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if read != nil {
+		query = query.Where("`read` = ?", *read)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(perPage).Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND `read` = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(userID, id uint) error {
+	var notification models.Notification
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return err
+	}
+	if notification.Read {
+		return nil
+	}
+	return r.db.Model(&notification).Update("read", true).Error
+}
+
+func (r *notificationRepository) MarkAllRead(userID uint) error {
+	return r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND `read` = ?", userID, false).
+		Update("read", true).Error
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetTokenRepository interface {
+	Create(token *models.PasswordResetToken) error
+	// GetByTokenHash returns the unused, unexpired token with the given hash
+	GetByTokenHash(tokenHash string) (*models.PasswordResetToken, error)
+	// MarkUsed uses up a token unless it was used already, and reports
+	// whether it did. Only one of several concurrent resets can succeed.
+	MarkUsed(id uint) (bool, error)
+	// DeleteByUser removes every reset token issued to a user
+	DeleteByUser(userID uint) error
+}
+
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+func (r *passwordResetTokenRepository) Create(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *passwordResetTokenRepository) GetByTokenHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *passwordResetTokenRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *passwordResetTokenRepository) DeleteByUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.PasswordResetToken{}).Error
+}
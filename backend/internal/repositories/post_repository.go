@@ -1,21 +1,95 @@
 package repositories
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"backend/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PostRepository interface {
+	// WithContext returns a copy of the repository whose queries run with
+	// ctx, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) PostRepository
+	// WithTransaction runs fn with a repository whose queries share one
+	// transaction, committed if fn returns nil and rolled back otherwise
+	WithTransaction(fn func(repo PostRepository) error) error
 	Create(post *models.Post) error
 	GetByID(id uint) (*models.Post, error)
 	GetBySlug(slug string) (*models.Post, error)
+	GetBySlugs(slugs []string) ([]models.Post, error)
+	GetByIDs(ids []uint) ([]models.Post, error)
 	Update(post *models.Post) error
 	Delete(id uint) error
+	// BulkUpdateStatus sets the status of every post in ids with one
+	// statement and returns how many it updated. Publishing stamps
+	// PublishedAt on posts that were never published and drops any
+	// schedule, so the posts go live straight away.
+	BulkUpdateStatus(ids []uint, status string) (int64, error)
+	// BulkDelete deletes every post in ids with one statement and returns
+	// how many it deleted
+	BulkDelete(ids []uint) (int64, error)
+	// SetAuthor hands a post to another author, recording who made the change
+	SetAuthor(id, authorID, updatedBy uint) error
+	// TransferAuthor hands every post of one author, including those in the
+	// trash, to another and returns how many it moved
+	TransferAuthor(fromAuthorID, toAuthorID, updatedBy uint) (int64, error)
+	// ListTrashed pages through deleted posts, most recently deleted first
+	ListTrashed(page, perPage int) ([]models.Post, int64, error)
+	// Restore undeletes a post. Posts that are not deleted yield
+	// gorm.ErrRecordNotFound.
+	Restore(id uint) error
+	// PurgeTrashed permanently removes posts deleted before olderThan, along
+	// with their comments, tags and views, and returns how many it removed
+	PurgeTrashed(olderThan time.Time) (int64, error)
 	List(page, perPage int, filters map[string]interface{}) ([]models.Post, int64, error)
-	Search(req *models.PostSearchRequest) ([]models.Post, int64, error)
-	GetByAuthor(authorID uint, page, perPage int) ([]models.Post, int64, error)
-	GetByCategory(categoryID uint, page, perPage int) ([]models.Post, int64, error)
+	Search(req *models.PostSearchRequest) ([]models.Post, int64, string, error)
+	// GetByAuthor and GetByCategory return posts newest first. A non-empty
+	// status limits both the page and the total to posts in that status.
+	GetByAuthor(authorID uint, status string, page, perPage int) ([]models.Post, int64, error)
+	GetByCategory(categoryID uint, status string, page, perPage int) ([]models.Post, int64, error)
+	// GetByTag is GetByCategory for the posts carrying a tag
+	GetByTag(tagID uint, status string, page, perPage int) ([]models.Post, int64, error)
+	// ReplaceTags sets the post's tags to exactly tags, which must be stored
+	ReplaceTags(post *models.Post, tags []models.Tag) error
+	CountPublishedByCategory(ctx context.Context) (map[uint]int64, error)
+	// CountByAuthor returns the number of posts the author owns, only
+	// counting published ones if publishedOnly is set
+	CountByAuthor(authorID uint, publishedOnly bool) (int64, error)
+	// CountByAuthors counts the posts of each author in one query. Authors
+	// without posts are left out of the map.
+	CountByAuthors(authorIDs []uint) (map[uint]int64, error)
+	// CountPublished returns the number of posts readers can see
+	CountPublished() (int64, error)
+	// GetPublishedByOffset returns the published post at offset, counting
+	// from 0 in ID order, with its category, author and tags
+	GetPublishedByOffset(offset int64) (*models.Post, error)
+	// LatestCreatedAt returns when the author last created a post, counting
+	// deleted posts, or the zero time if they never have
+	LatestCreatedAt(authorID uint) (time.Time, error)
+	// FindByTitle returns a post titled title, ignoring case, other than
+	// excludeID
+	FindByTitle(title string, excludeID uint) (*models.Post, error)
+	// ListStaleDrafts returns drafts last updated before the cutoff, oldest first
+	ListStaleDrafts(ctx context.Context, before time.Time) ([]models.Post, error)
+	// ArchiveDraft archives a post only if it is still a draft last updated
+	// before the cutoff, so a draft edited since it was listed is left alone.
+	// It reports whether the post was archived.
+	ArchiveDraft(ctx context.Context, id uint, before time.Time) (bool, error)
+	// ListDueScheduled returns drafts whose PublishAt is at or before now,
+	// earliest first
+	ListDueScheduled(ctx context.Context, now time.Time) ([]models.Post, error)
+	// PublishScheduled publishes a post only if it is still a draft due at
+	// now, and reports whether it was published
+	PublishScheduled(ctx context.Context, id uint, now time.Time) (bool, error)
+	// IncrementViews adds one to a published post's view count in a single
+	// UPDATE, so concurrent views are never lost, and returns the new count.
+	// Other posts yield gorm.ErrRecordNotFound.
+	IncrementViews(id uint) (uint, error)
 }
 
 type postRepository struct {
@@ -26,13 +100,49 @@ func NewPostRepository(db *gorm.DB) PostRepository {
 	return &postRepository{db: db}
 }
 
+func (r *postRepository) WithContext(ctx context.Context) PostRepository {
+	return &postRepository{db: r.db.WithContext(ctx)}
+}
+
+// unscoped lets a preload load soft-deleted rows too, so a post keeps its
+// author and category after they are deleted
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+func (r *postRepository) WithTransaction(fn func(repo PostRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&postRepository{db: tx})
+	})
+}
+
 func (r *postRepository) Create(post *models.Post) error {
 	return r.db.Create(post).Error
 }
 
+func (r *postRepository) SetAuthor(id, authorID, updatedBy uint) error {
+	result := r.db.Model(&models.Post{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"author_id": authorID, "updated_by": updatedBy})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *postRepository) TransferAuthor(fromAuthorID, toAuthorID, updatedBy uint) (int64, error) {
+	// Trashed posts move too, so restoring one later does not bring back the
+	// old author
+	result := r.db.Unscoped().Model(&models.Post{}).Where("author_id = ?", fromAuthorID).
+		Updates(map[string]interface{}{"author_id": toAuthorID, "updated_by": updatedBy})
+	return result.RowsAffected, result.Error
+}
+
 func (r *postRepository) GetByID(id uint) (*models.Post, error) {
 	var post models.Post
-	err := r.db.Preload("Category").Preload("Author").Preload("Comments").First(&post, id).Error
+	err := r.db.Preload("Category", unscoped).Preload("Author", unscoped).Preload("Comments").Preload("Tags").First(&post, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,33 +151,140 @@ func (r *postRepository) GetByID(id uint) (*models.Post, error) {
 
 func (r *postRepository) GetBySlug(slug string) (*models.Post, error) {
 	var post models.Post
-	err := r.db.Preload("Category").Preload("Author").Preload("Comments").Where("slug = ?", slug).First(&post).Error
+	err := r.db.Preload("Category", unscoped).Preload("Author", unscoped).Preload("Comments").Preload("Tags").Where("slug = ?", slug).First(&post).Error
 	if err != nil {
 		return nil, err
 	}
 	return &post, nil
 }
 
+// GetBySlugs fetches every post whose slug is in slugs with a single query.
+// Results are in no particular order and missing slugs are simply absent.
+func (r *postRepository) GetBySlugs(slugs []string) ([]models.Post, error) {
+	var posts []models.Post
+	if len(slugs) == 0 {
+		return posts, nil
+	}
+	err := r.db.Preload("Category", unscoped).Preload("Author", unscoped).Where("slug IN ?", slugs).Find(&posts).Error
+	return posts, err
+}
+
+// GetByIDs fetches every post whose ID is in ids with a single query.
+// Results are in no particular order and missing IDs are simply absent.
+func (r *postRepository) GetByIDs(ids []uint) ([]models.Post, error) {
+	var posts []models.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	err := r.db.Preload("Category", unscoped).Preload("Author", unscoped).Where("id IN ?", ids).Find(&posts).Error
+	return posts, err
+}
+
 func (r *postRepository) Update(post *models.Post) error {
-	return r.db.Save(post).Error
+	// Posts are usually loaded with their category and author preloaded;
+	// saving those back would reset CategoryID to the old category
+	return r.db.Omit(clause.Associations).Save(post).Error
 }
 
 func (r *postRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Post{}, id).Error
 }
 
+func (r *postRepository) BulkUpdateStatus(ids []uint, status string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	updates := map[string]interface{}{"status": status}
+	if status == "published" {
+		updates["published_at"] = gorm.Expr("COALESCE(published_at, ?)", time.Now())
+		updates["publish_at"] = nil
+	}
+	result := r.db.Model(&models.Post{}).Where("id IN ?", ids).Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+func (r *postRepository) BulkDelete(ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Where("id IN ?", ids).Delete(&models.Post{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *postRepository) ListTrashed(page, perPage int) ([]models.Post, int64, error) {
+	var posts []models.Post
+	var total int64
+
+	query := r.db.Unscoped().Model(&models.Post{}).Where("deleted_at IS NOT NULL")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Preload("Category", unscoped).Preload("Author", unscoped).
+		Order("deleted_at DESC, id DESC").
+		Offset(offset).Limit(perPage).Find(&posts).Error
+	return posts, total, err
+}
+
+func (r *postRepository) Restore(id uint) error {
+	result := r.db.Unscoped().Model(&models.Post{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeTrashed clears the rows pointing at the posts first, in the same
+// transaction, so foreign keys never see a post vanish from under them
+func (r *postRepository) PurgeTrashed(olderThan time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		err := tx.Unscoped().Model(&models.Post{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", olderThan).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("post_id IN ?", ids).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM post_tags WHERE post_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", ids).Delete(&models.PostViewDay{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", ids).Delete(&models.RecentView{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Delete(&models.Post{}, ids)
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
 func (r *postRepository) List(page, perPage int, filters map[string]interface{}) ([]models.Post, int64, error) {
 	var posts []models.Post
 	var total int64
 
 	offset := (page - 1) * perPage
-	query := r.db.Model(&models.Post{}).Preload("Category").Preload("Author")
+	query := r.db.Model(&models.Post{}).Preload("Category", unscoped).Preload("Author", unscoped)
 
 	// Apply filters
 	for key, value := range filters {
 		switch key {
 		case "status":
-			query = query.Where("status = ?", value)
+			query = whereStatus(query, value)
 		case "category_id":
 			query = query.Where("category_id = ?", value)
 		case "author_id":
@@ -85,8 +302,11 @@ func (r *postRepository) List(page, perPage int, filters map[string]interface{})
 	return posts, total, err
 }
 
-// Search posts with full-text search and advanced filtering
-func (r *postRepository) Search(req *models.PostSearchRequest) ([]models.Post, int64, error) {
+// Search posts with full-text search and advanced filtering. The returned
+// search mode reports how the text query was matched: MySQL FULLTEXT where
+// available, otherwise a LIKE scan over title and content that ranks title
+// matches before content-only matches.
+func (r *postRepository) Search(req *models.PostSearchRequest) ([]models.Post, int64, string, error) {
 	var posts []models.Post
 	var total int64
 
@@ -108,35 +328,35 @@ func (r *postRepository) Search(req *models.PostSearchRequest) ([]models.Post, i
 	}
 
 	offset := (req.Page - 1) * req.Limit
-	query := r.db.Model(&models.Post{}).Preload("Category").Preload("Author")
 
-	// Apply full-text search if query is provided
+	mode := models.SearchModeNone
 	if req.Query != "" {
-		// Use MySQL FULLTEXT search for better relevance
-		query = query.Where("MATCH(title, content) AGAINST(? IN NATURAL LANGUAGE MODE)", req.Query)
+		mode = models.SearchModeLike
+		if r.db.Dialector.Name() == "mysql" {
+			mode = models.SearchModeFullText
+		}
 	}
 
-	// Apply filters
-	if req.CategoryID > 0 {
-		query = query.Where("category_id = ?", req.CategoryID)
-	}
-	if req.AuthorID > 0 {
-		query = query.Where("author_id = ?", req.AuthorID)
-	}
-	if req.Status != "" {
-		query = query.Where("status = ?", req.Status)
-	}
+	query := r.searchQuery(req, mode)
 
 	// Count total records
 	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+		if mode != models.SearchModeFullText {
+			return nil, 0, mode, err
+		}
+		// No usable FULLTEXT index; fall back to a LIKE scan
+		mode = models.SearchModeLike
+		query = r.searchQuery(req, mode)
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, mode, err
+		}
 	}
 
 	// Apply sorting
 	orderClause := req.Sort + " " + req.Order
-	
+
 	// If we're doing full-text search, we might want to order by relevance first
-	if req.Query != "" {
+	if mode == models.SearchModeFullText {
 		// For full-text search, we can order by relevance score
 		query = query.Select("*, MATCH(title, content) AGAINST(? IN NATURAL LANGUAGE MODE) as relevance_score", req.Query)
 		if req.Sort == "created_at" && req.Order == "desc" {
@@ -146,38 +366,254 @@ func (r *postRepository) Search(req *models.PostSearchRequest) ([]models.Post, i
 			orderClause = "relevance_score DESC, " + orderClause
 		}
 	}
+	if mode == models.SearchModeLike {
+		// LIKE has no relevance score, so rank title matches first
+		query = query.Select("*, CASE WHEN title LIKE ? THEN 1 ELSE 0 END as title_match", "%"+req.Query+"%")
+		orderClause = "title_match DESC, " + orderClause
+	}
 
 	// Apply pagination and get results
 	err := query.Order(orderClause).Offset(offset).Limit(req.Limit).Find(&posts).Error
-	return posts, total, err
+	return posts, total, mode, err
 }
 
-func (r *postRepository) GetByAuthor(authorID uint, page, perPage int) ([]models.Post, int64, error) {
-	var posts []models.Post
-	var total int64
+// searchQuery builds the filtered (but unpaginated) query for Search
+func (r *postRepository) searchQuery(req *models.PostSearchRequest, mode string) *gorm.DB {
+	query := r.db.Model(&models.Post{}).Preload("Category", unscoped).Preload("Author", unscoped)
 
-	offset := (page - 1) * perPage
+	switch mode {
+	case models.SearchModeFullText:
+		// Use MySQL FULLTEXT search for better relevance
+		query = query.Where("MATCH(title, content) AGAINST(? IN NATURAL LANGUAGE MODE)", req.Query)
+	case models.SearchModeLike:
+		pattern := "%" + req.Query + "%"
+		query = query.Where("title LIKE ? OR content LIKE ?", pattern, pattern)
+	}
 
-	if err := r.db.Model(&models.Post{}).Where("author_id = ?", authorID).Count(&total).Error; err != nil {
-		return nil, 0, err
+	// Apply filters
+	if req.CategoryID > 0 {
+		query = query.Where("category_id = ?", req.CategoryID)
+	}
+	if req.AuthorID > 0 {
+		query = query.Where("author_id = ?", req.AuthorID)
+	}
+	if req.Status != "" {
+		query = whereStatus(query, req.Status)
+	}
+	if req.OwnUnpublishedOf > 0 {
+		query = query.Where("(status = ? AND (publish_at IS NULL OR publish_at <= ?)) OR author_id = ?", "published", time.Now(), req.OwnUnpublishedOf)
 	}
 
-	err := r.db.Preload("Category").Preload("Author").Where("author_id = ?", authorID).
-		Offset(offset).Limit(perPage).Find(&posts).Error
-	return posts, total, err
+	return query
+}
+
+// whereStatus limits a query to posts in status. Published posts still
+// scheduled for later are left out, so they stay hidden until they are due.
+func whereStatus(query *gorm.DB, status interface{}) *gorm.DB {
+	if status == "published" {
+		return query.Where("status = ? AND (publish_at IS NULL OR publish_at <= ?)", status, time.Now())
+	}
+	return query.Where("status = ?", status)
 }
 
-func (r *postRepository) GetByCategory(categoryID uint, page, perPage int) ([]models.Post, int64, error) {
+func (r *postRepository) GetByAuthor(authorID uint, status string, page, perPage int) ([]models.Post, int64, error) {
+	return r.listBy("author_id", authorID, status, page, perPage)
+}
+
+func (r *postRepository) GetByCategory(categoryID uint, status string, page, perPage int) ([]models.Post, int64, error) {
+	return r.listBy("category_id", categoryID, status, page, perPage)
+}
+
+func (r *postRepository) GetByTag(tagID uint, status string, page, perPage int) ([]models.Post, int64, error) {
+	query := r.db.Model(&models.Post{}).
+		Joins("JOIN post_tags ON post_tags.post_id = posts.id").
+		Where("post_tags.tag_id = ?", tagID)
+	return r.listPage(query, status, page, perPage)
+}
+
+func (r *postRepository) ReplaceTags(post *models.Post, tags []models.Tag) error {
+	if err := r.db.Model(post).Association("Tags").Replace(tags); err != nil {
+		return err
+	}
+	post.Tags = tags
+	return nil
+}
+
+// listBy pages through the posts whose column equals id
+func (r *postRepository) listBy(column string, id uint, status string, page, perPage int) ([]models.Post, int64, error) {
+	return r.listPage(r.db.Model(&models.Post{}).Where(column+" = ?", id), status, page, perPage)
+}
+
+// listPage pages through the posts query selects, counting the same set it
+// returns so the total always agrees with the pages
+func (r *postRepository) listPage(query *gorm.DB, status string, page, perPage int) ([]models.Post, int64, error) {
 	var posts []models.Post
 	var total int64
 
 	offset := (page - 1) * perPage
 
-	if err := r.db.Model(&models.Post{}).Where("category_id = ?", categoryID).Count(&total).Error; err != nil {
+	if status != "" {
+		query = whereStatus(query, status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := r.db.Preload("Category").Preload("Author").Where("category_id = ?", categoryID).
+	err := query.Preload("Category", unscoped).Preload("Author", unscoped).
+		Order("posts.created_at DESC, posts.id DESC").
 		Offset(offset).Limit(perPage).Find(&posts).Error
 	return posts, total, err
 }
+
+// CountPublishedByCategory returns the number of published posts in each category
+func (r *postRepository) CountPublishedByCategory(ctx context.Context) (map[uint]int64, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int64
+	}
+
+	err := r.db.WithContext(ctx).Model(&models.Post{}).
+		Select("category_id, COUNT(*) AS count").
+		Where("status = ?", "published").
+		Group("category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
+
+// CountByAuthor returns the number of non-deleted posts the author owns, in
+// any status unless publishedOnly is set
+func (r *postRepository) CountByAuthor(authorID uint, publishedOnly bool) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.Post{}).Where("author_id = ?", authorID)
+	if publishedOnly {
+		query = query.Where("status = ?", "published")
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// CountByAuthors returns the number of non-deleted posts, in any status, each
+// of the given authors owns
+func (r *postRepository) CountByAuthors(authorIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(authorIDs))
+	if len(authorIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		AuthorID uint
+		Count    int64
+	}
+	err := r.db.Model(&models.Post{}).
+		Select("author_id, COUNT(*) AS count").
+		Where("author_id IN ?", authorIDs).
+		Group("author_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.AuthorID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *postRepository) CountPublished() (int64, error) {
+	var count int64
+	err := whereStatus(r.db.Model(&models.Post{}), "published").Count(&count).Error
+	return count, err
+}
+
+// GetPublishedByOffset steps along the primary key to offset, so it never
+// sorts or reads whole rows beyond the one it returns
+func (r *postRepository) GetPublishedByOffset(offset int64) (*models.Post, error) {
+	var post models.Post
+	err := whereStatus(r.db.Preload("Category", unscoped).Preload("Author", unscoped).Preload("Tags"), "published").
+		Order("id ASC").
+		Offset(int(offset)).
+		Take(&post).Error
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (r *postRepository) LatestCreatedAt(authorID uint) (time.Time, error) {
+	var post models.Post
+	err := r.db.Unscoped().Select("created_at").
+		Where("author_id = ?", authorID).
+		Order("created_at DESC").
+		Take(&post).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	return post.CreatedAt, err
+}
+
+func (r *postRepository) FindByTitle(title string, excludeID uint) (*models.Post, error) {
+	var post models.Post
+	err := r.db.Where("LOWER(title) = LOWER(?) AND id <> ?", title, excludeID).Order("id").First(&post).Error
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (r *postRepository) ListStaleDrafts(ctx context.Context, before time.Time) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND updated_at < ?", "draft", before).
+		Order("updated_at ASC, id ASC").
+		Find(&posts).Error
+	return posts, err
+}
+
+func (r *postRepository) ArchiveDraft(ctx context.Context, id uint, before time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Post{}).
+		Where("id = ? AND status = ? AND updated_at < ?", id, "draft", before).
+		Update("status", "archived")
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *postRepository) ListDueScheduled(ctx context.Context, now time.Time) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND publish_at IS NOT NULL AND publish_at <= ?", "draft", now).
+		Order("publish_at ASC, id ASC").
+		Find(&posts).Error
+	return posts, err
+}
+
+func (r *postRepository) PublishScheduled(ctx context.Context, id uint, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Post{}).
+		Where("id = ? AND status = ? AND publish_at IS NOT NULL AND publish_at <= ?", id, "draft", now).
+		Updates(map[string]interface{}{"status": "published", "published_at": now})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *postRepository) IncrementViews(id uint) (uint, error) {
+	var post models.Post
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := whereStatus(tx.Model(&models.Post{}).Where("id = ?", id), "published").
+			UpdateColumn("view_count", gorm.Expr("view_count + ?", 1))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		// The update holds the row until commit, so this reads our own count
+		return tx.Select("view_count").First(&post, id).Error
+	})
+	return post.ViewCount, err
+}
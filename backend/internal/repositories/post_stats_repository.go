@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostStatsRepository interface {
+	// RecordView adds one view to the post's count for day (YYYY-MM-DD)
+	RecordView(ctx context.Context, postID uint, day string) error
+	// CountViews returns the post's total views and those on or after since
+	// (YYYY-MM-DD)
+	CountViews(ctx context.Context, postID uint, since string) (total, recent int64, err error)
+	// CountCommentsByStatus returns the number of the post's comments in each
+	// moderation status
+	CountCommentsByStatus(ctx context.Context, postID uint) (map[string]int64, error)
+	// RecordRecentView marks the post as the one the user read last, then
+	// forgets all but the user's keep most recent views
+	RecordRecentView(ctx context.Context, userID, postID uint, viewedAt time.Time, keep int) error
+	// ListRecentViews returns the IDs of the posts the user read, most
+	// recent first
+	ListRecentViews(ctx context.Context, userID uint) ([]uint, error)
+}
+
+type postStatsRepository struct {
+	db *gorm.DB
+}
+
+func NewPostStatsRepository(db *gorm.DB) PostStatsRepository {
+	return &postStatsRepository{db: db}
+}
+
+func (r *postStatsRepository) RecordView(ctx context.Context, postID uint, day string) error {
+	// One row per post and day; concurrent views bump it atomically
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "post_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("views + ?", 1)}),
+	}).Create(&models.PostViewDay{PostID: postID, Day: day, Views: 1}).Error
+}
+
+func (r *postStatsRepository) CountViews(ctx context.Context, postID uint, since string) (int64, int64, error) {
+	var totals struct {
+		Total  int64
+		Recent int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.PostViewDay{}).
+		Select("COALESCE(SUM(views), 0) AS total, COALESCE(SUM(CASE WHEN day >= ? THEN views ELSE 0 END), 0) AS recent", since).
+		Where("post_id = ?", postID).
+		Scan(&totals).Error
+	return totals.Total, totals.Recent, err
+}
+
+func (r *postStatsRepository) CountCommentsByStatus(ctx context.Context, postID uint) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.Comment{}).
+		Select("status, COUNT(*) AS count").
+		Where("post_id = ?", postID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *postStatsRepository) RecordRecentView(ctx context.Context, userID, postID uint, viewedAt time.Time, keep int) error {
+	db := r.db.WithContext(ctx)
+
+	// Reading a post again moves it to the front rather than adding a row
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"viewed_at"}),
+	}).Create(&models.RecentView{UserID: userID, PostID: postID, ViewedAt: viewedAt}).Error
+	if err != nil {
+		return err
+	}
+
+	// A user's history stays short, so trimming it in Go is cheap and avoids
+	// MySQL's lack of LIMIT in IN subqueries
+	postIDs, err := r.ListRecentViews(ctx, userID)
+	if err != nil || len(postIDs) <= keep {
+		return err
+	}
+	return db.Where("user_id = ? AND post_id IN ?", userID, postIDs[keep:]).Delete(&models.RecentView{}).Error
+}
+
+func (r *postStatsRepository) ListRecentViews(ctx context.Context, userID uint) ([]uint, error) {
+	var postIDs []uint
+	err := r.db.WithContext(ctx).Model(&models.RecentView{}).
+		Where("user_id = ?", userID).
+		Order("viewed_at DESC, post_id DESC").
+		Pluck("post_id", &postIDs).Error
+	return postIDs, err
+}
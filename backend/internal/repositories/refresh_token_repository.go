@@ -13,6 +13,9 @@ type RefreshTokenRepository interface {
 	GetByUserID(userID uint) ([]*models.RefreshToken, error)
 	RevokeToken(token string) error
 	RevokeAllUserTokens(userID uint) error
+	// GetWithLiveAccessTokens lists the user's refresh tokens, revoked or not,
+	// whose access token has not expired at now
+	GetWithLiveAccessTokens(userID uint, now time.Time) ([]*models.RefreshToken, error)
 	DeleteExpiredTokens() error
 	Update(token *models.RefreshToken) error
 	Delete(id uint) error
@@ -61,6 +64,13 @@ func (r *refreshTokenRepository) RevokeAllUserTokens(userID uint) error {
 		Update("is_revoked", true).Error
 }
 
+func (r *refreshTokenRepository) GetWithLiveAccessTokens(userID uint, now time.Time) ([]*models.RefreshToken, error) {
+	var tokens []*models.RefreshToken
+	err := r.db.Where("user_id = ? AND access_jti <> '' AND access_expires_at > ?", userID, now).
+		Find(&tokens).Error
+	return tokens, err
+}
+
 func (r *refreshTokenRepository) DeleteExpiredTokens() error {
 	return r.db.Where("expires_at < ? OR is_revoked = ?", time.Now(), true).
 		Delete(&models.RefreshToken{}).Error
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedAccessTokenRepository interface {
+	// Create denies the token; revoking a token twice is not an error
+	Create(token *models.RevokedAccessToken) error
+	// IsRevoked reports whether the token with the given jti was revoked
+	IsRevoked(jti string) (bool, error)
+	// DeleteExpired removes entries for tokens that expired before the
+	// cutoff and returns how many were removed
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type revokedAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedAccessTokenRepository(db *gorm.DB) RevokedAccessTokenRepository {
+	return &revokedAccessTokenRepository{db: db}
+}
+
+func (r *revokedAccessTokenRepository) Create(token *models.RevokedAccessToken) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *revokedAccessTokenRepository) IsRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RevokedAccessToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *revokedAccessTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&models.RevokedAccessToken{})
+	return result.RowsAffected, result.Error
+}
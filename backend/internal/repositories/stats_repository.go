@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// StatsRepository aggregates site-wide counts with one GROUP BY query each,
+// so the admin dashboard never loads whole tables
+type StatsRepository interface {
+	// CountUsersByRole returns the number of users with each role
+	CountUsersByRole(ctx context.Context) (map[string]int64, error)
+	// CountPostsByStatus returns the number of posts in each status
+	CountPostsByStatus(ctx context.Context) (map[string]int64, error)
+	// CountCommentsByStatus returns the number of comments in each
+	// moderation status
+	CountCommentsByStatus(ctx context.Context) (map[string]int64, error)
+	// RecentPosts returns the limit most recently created posts in any
+	// status, newest first
+	RecentPosts(ctx context.Context, limit int) ([]models.Post, error)
+}
+
+type statsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+func (r *statsRepository) CountUsersByRole(ctx context.Context) (map[string]int64, error) {
+	return r.countBy(ctx, &models.User{}, "role")
+}
+
+func (r *statsRepository) CountPostsByStatus(ctx context.Context) (map[string]int64, error) {
+	return r.countBy(ctx, &models.Post{}, "status")
+}
+
+func (r *statsRepository) CountCommentsByStatus(ctx context.Context) (map[string]int64, error) {
+	return r.countBy(ctx, &models.Comment{}, "status")
+}
+
+// countBy counts the model's rows for each value of column. Soft-deleted
+// rows are left out.
+func (r *statsRepository) countBy(ctx context.Context, model interface{}, column string) (map[string]int64, error) {
+	var rows []struct {
+		Value string
+		Count int64
+	}
+	err := r.db.WithContext(ctx).Model(model).
+		Select(column + " AS value, COUNT(*) AS count").
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Value] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *statsRepository) RecentPosts(ctx context.Context, limit int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.WithContext(ctx).Preload("Category", unscoped).Preload("Author", unscoped).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
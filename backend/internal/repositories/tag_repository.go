@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository interface {
+	// WithContext returns a copy of the repository whose queries run with
+	// ctx, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) TagRepository
+	// FindOrCreate returns the stored tag for each of tags, matched by slug,
+	// creating those that do not exist yet. The result follows the order of
+	// tags, which must not repeat a slug.
+	FindOrCreate(tags []models.Tag) ([]models.Tag, error)
+	GetBySlug(slug string) (*models.Tag, error)
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) WithContext(ctx context.Context) TagRepository {
+	return &tagRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *tagRepository) FindOrCreate(tags []models.Tag) ([]models.Tag, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	// Inserting and then reading back by slug keeps concurrent saves of the
+	// same new tag from failing on the unique index
+	toCreate := make([]models.Tag, len(tags))
+	copy(toCreate, tags)
+	err := r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).
+		Create(&toCreate).Error
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, len(tags))
+	for i, tag := range tags {
+		slugs[i] = tag.Slug
+	}
+	var stored []models.Tag
+	if err := r.db.Where("slug IN ?", slugs).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+
+	bySlug := make(map[string]models.Tag, len(stored))
+	for _, tag := range stored {
+		bySlug[tag.Slug] = tag
+	}
+	found := make([]models.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if storedTag, ok := bySlug[tag.Slug]; ok {
+			found = append(found, storedTag)
+		}
+	}
+	return found, nil
+}
+
+func (r *tagRepository) GetBySlug(slug string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.Where("slug = ?", slug).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
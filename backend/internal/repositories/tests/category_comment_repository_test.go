@@ -185,7 +185,7 @@ func TestCommentRepository(t *testing.T) {
 		}
 
 		// Get comments for the post
-		comments, total, err := commentRepo.GetByPost(testData.PublishedPost.ID, 1, 10)
+		comments, total, err := commentRepo.GetByPost(testData.PublishedPost.ID, "", 1, 10)
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, len(comments), 3)
 		assert.GreaterOrEqual(t, total, int64(3))
@@ -208,7 +208,7 @@ func TestCommentRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Get comments by author
-		comments, total, err := commentRepo.GetByUser(testData.Author.ID, 1, 10)
+		comments, total, err := commentRepo.GetByUser(testData.Author.ID, "", 1, 10)
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, len(comments), 1)
 		assert.GreaterOrEqual(t, total, int64(1))
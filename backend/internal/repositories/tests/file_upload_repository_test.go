@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileUploadRepository(t *testing.T) {
+	testDB := testutils.SetupTestDatabase(t)
+	defer testDB.TeardownTestDatabase(t)
+	testData := testDB.SeedTestData(t)
+
+	uploadRepo := repositories.NewFileUploadRepository(testDB.DB)
+
+	newUpload := func(filename string, userID uint, createdAt time.Time) *models.FileUpload {
+		upload := &models.FileUpload{
+			OriginalName: "photo.png",
+			Filename:     filename,
+			FilePath:     "uploads/" + filename,
+			FileSize:     1024,
+			MimeType:     "image/png",
+			URL:          "http://localhost:8080/uploads/" + filename,
+			UserID:       userID,
+			CreatedAt:    createdAt,
+		}
+		require.NoError(t, uploadRepo.Create(upload))
+		return upload
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	thumbnail := newUpload("thumbnail.png", testData.Author.ID, old)
+	inline := newUpload("inline.png", testData.Author.ID, old)
+	unused := newUpload("unused.png", testData.Author.ID, old)
+	recent := newUpload("recent.png", testData.Author.ID, time.Now())
+	adminUpload := newUpload("admin.png", testData.Admin.ID, old)
+
+	testData.PublishedPost.ThumbnailURL = thumbnail.URL
+	testData.PublishedPost.Content = `<p><img src="https://cdn.example.com/uploads/inline.png"></p>`
+	require.NoError(t, testDB.DB.Save(testData.PublishedPost).Error)
+
+	t.Run("GetByID and GetByFilename", func(t *testing.T) {
+		byID, err := uploadRepo.GetByID(unused.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "unused.png", byID.Filename)
+
+		byName, err := uploadRepo.GetByFilename("inline.png")
+		require.NoError(t, err)
+		assert.Equal(t, inline.ID, byName.ID)
+	})
+
+	t.Run("GetByUser pages a user's uploads, newest first", func(t *testing.T) {
+		uploads, total, err := uploadRepo.GetByUser(testData.Author.ID, 1, 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(4), total)
+		require.Len(t, uploads, 2)
+		assert.Equal(t, recent.ID, uploads[0].ID)
+	})
+
+	t.Run("ListOrphaned skips referenced and recent uploads", func(t *testing.T) {
+		orphaned, err := uploadRepo.ListOrphaned(time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		var ids []uint
+		for _, upload := range orphaned {
+			ids = append(ids, upload.ID)
+		}
+		assert.ElementsMatch(t, []uint{unused.ID, adminUpload.ID}, ids)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, uploadRepo.Delete(unused.ID))
+
+		_, err := uploadRepo.GetByID(unused.ID)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentRepository_GetRecent(t *testing.T) {
+	testDB := testutils.SetupTestDatabase(t)
+	defer testDB.TeardownTestDatabase(t)
+	testData := testDB.SeedTestData(t)
+
+	commentRepo := repositories.NewCommentRepository(testDB.DB)
+
+	// Seeded: one approved comment by the admin on the published post
+	newer := &models.Comment{
+		PostID:    testData.PublishedPost.ID,
+		UserID:    testData.Author.ID,
+		Content:   "A newer approved comment",
+		Status:    "approved",
+		CreatedAt: time.Now().Add(time.Hour),
+	}
+	pending := &models.Comment{
+		PostID:  testData.PublishedPost.ID,
+		UserID:  testData.Author.ID,
+		Content: "Still waiting for moderation",
+		Status:  "pending",
+	}
+	onDraft := &models.Comment{
+		PostID:  testData.DraftPost.ID,
+		UserID:  testData.Author.ID,
+		Content: "Approved, but on a draft",
+		Status:  "approved",
+	}
+	for _, comment := range []*models.Comment{newer, pending, onDraft} {
+		require.NoError(t, commentRepo.Create(comment))
+	}
+
+	t.Run("only approved comments on published posts, newest first", func(t *testing.T) {
+		comments, err := commentRepo.GetRecent(10)
+		require.NoError(t, err)
+
+		require.Len(t, comments, 2)
+		assert.Equal(t, newer.ID, comments[0].ID)
+		assert.Equal(t, testData.Comment.ID, comments[1].ID)
+	})
+
+	t.Run("includes trimmed post and author context", func(t *testing.T) {
+		comments, err := commentRepo.GetRecent(1)
+		require.NoError(t, err)
+
+		require.Len(t, comments, 1)
+		assert.Equal(t, "A newer approved comment", comments[0].Content)
+		assert.Equal(t, models.RecentCommentPost{
+			ID:    testData.PublishedPost.ID,
+			Title: testData.PublishedPost.Title,
+			Slug:  testData.PublishedPost.Slug,
+		}, comments[0].Post)
+		assert.Equal(t, testData.Author.ID, comments[0].Author.ID)
+		assert.Equal(t, testData.Author.Name, comments[0].Author.Name)
+	})
+
+	t.Run("skips comments on deleted posts", func(t *testing.T) {
+		require.NoError(t, testDB.DB.Delete(&models.Post{}, testData.PublishedPost.ID).Error)
+
+		comments, err := commentRepo.GetRecent(10)
+		require.NoError(t, err)
+		assert.Empty(t, comments)
+	})
+}
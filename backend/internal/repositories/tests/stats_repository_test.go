@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/internal/testutils"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gaugeValue returns the current value of the named gauge
+func gaugeValue(t *testing.T, name string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("gauge %s is not registered", name)
+	return 0
+}
+
+func TestStatsService_GetStats(t *testing.T) {
+	testDB := testutils.SetupTestDatabase(t)
+	defer testDB.TeardownTestDatabase(t)
+	testData := testDB.SeedTestData(t)
+
+	// Seeded: an author and an admin, one published and one draft post, and
+	// one approved comment
+	editor := &models.User{Username: "testeditor", Name: "Test Editor", Email: "editor@test.com", Password: "hashed_password", Role: "editor"}
+	require.NoError(t, testDB.DB.Create(editor).Error)
+
+	postRepo := repositories.NewPostRepository(testDB.DB)
+	base := time.Now().Add(time.Hour)
+	var newest []*models.Post
+	for i := 1; i <= 11; i++ {
+		post := &models.Post{
+			Title:      fmt.Sprintf("Archived %d", i),
+			Slug:       fmt.Sprintf("archived-%d", i),
+			Content:    "Content",
+			AuthorID:   testData.Author.ID,
+			CategoryID: testData.Category.ID,
+			Status:     "archived",
+			CreatedAt:  base.Add(time.Duration(i) * time.Minute),
+		}
+		require.NoError(t, postRepo.Create(post))
+		newest = append([]*models.Post{post}, newest...)
+	}
+
+	deleted := &models.Post{Title: "Deleted", Slug: "deleted", Content: "Content", AuthorID: testData.Author.ID, CategoryID: testData.Category.ID, Status: "published"}
+	require.NoError(t, postRepo.Create(deleted))
+	require.NoError(t, postRepo.Delete(deleted.ID))
+
+	commentRepo := repositories.NewCommentRepository(testDB.DB)
+	for _, status := range []string{"pending", "pending", "rejected"} {
+		require.NoError(t, commentRepo.Create(&models.Comment{
+			PostID:  testData.PublishedPost.ID,
+			UserID:  testData.Author.ID,
+			Content: "A " + status + " comment",
+			Status:  status,
+		}))
+	}
+
+	statsService := services.NewStatsService(repositories.NewStatsRepository(testDB.DB), &config.Config{})
+	stats, err := statsService.GetStats(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, models.UserCounts{Total: 3, Admin: 1, Editor: 1, Author: 1}, stats.Users)
+	assert.Equal(t, models.PostCounts{Total: 13, Draft: 1, Published: 1, Archived: 11}, stats.Posts, "soft-deleted posts are not counted")
+	assert.Equal(t, models.CommentCounts{Total: 4, Approved: 1, Pending: 2, Rejected: 1}, stats.Comments)
+
+	require.Len(t, stats.RecentPosts, 10)
+	for i, post := range stats.RecentPosts {
+		assert.Equal(t, newest[i].ID, post.ID)
+	}
+	assert.Equal(t, testData.Author.ID, stats.RecentPosts[0].Author.ID)
+
+	t.Run("gauges follow the same counts", func(t *testing.T) {
+		assert.Equal(t, float64(3), gaugeValue(t, "blogcms_active_users"))
+		assert.Equal(t, float64(13), gaugeValue(t, "blogcms_posts_total"))
+		assert.Equal(t, float64(4), gaugeValue(t, "blogcms_comments_total"))
+	})
+}
@@ -150,7 +150,7 @@ func TestUserRepository(t *testing.T) {
 		}
 
 		// Get all users - using List method instead of GetAll
-		users, total, err := userRepo.List(1, 100)
+		users, total, err := userRepo.List(models.UserListFilter{}, 1, 100)
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, len(users), 3)
 		assert.GreaterOrEqual(t, total, int64(3))
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repositories are the repositories a unit of work hands to its function,
+// all bound to the same transaction
+type Repositories struct {
+	Posts      PostRepository
+	Tags       TagRepository
+	Categories CategoryRepository
+	Comments   CommentRepository
+	Users      UserRepository
+	AuditLogs  AuditLogRepository
+}
+
+// UnitOfWork runs operations spanning several repositories atomically, so a
+// failure part way through leaves no rows behind. Operations on a single
+// repository can use that repository's WithTransaction instead.
+type UnitOfWork interface {
+	// WithContext returns a copy of the unit of work whose transactions run
+	// with ctx, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) UnitOfWork
+	// Do runs fn with repositories whose queries share one transaction,
+	// committed if fn returns nil and rolled back otherwise
+	Do(fn func(repos Repositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) WithContext(ctx context.Context) UnitOfWork {
+	return &unitOfWork{db: u.db.WithContext(ctx)}
+}
+
+func (u *unitOfWork) Do(fn func(repos Repositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(Repositories{
+			Posts:      &postRepository{db: tx},
+			Tags:       &tagRepository{db: tx},
+			Categories: &categoryRepository{db: tx},
+			Comments:   &commentRepository{db: tx},
+			Users:      &userRepository{db: tx},
+			AuditLogs:  &auditLogRepository{db: tx},
+		})
+	})
+}
@@ -1,19 +1,38 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
+	// WithContext returns a copy of the repository whose queries run with
+	// ctx, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) UserRepository
 	Create(user *models.User) error
 	GetByID(id uint) (*models.User, error)
+	GetByIDs(ids []uint) (map[uint]*models.User, error)
 	GetByUsername(username string) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
+	GetByVerificationToken(token string) (*models.User, error)
 	Update(user *models.User) error
+	// IncrementFailedLogins counts a wrong password against the user in a
+	// single UPDATE, so concurrent attempts are never lost, and returns the
+	// new count. Failures made more than window before now are forgotten
+	// first.
+	IncrementFailedLogins(id uint, now time.Time, window time.Duration) (int, error)
+	// LockAccount locks the user out until until and forgets the failures
+	// that led to it
+	LockAccount(id uint, until time.Time) error
+	// ClearFailedLogins forgets the user's wrong passwords and any lock
+	ClearFailedLogins(id uint) error
 	Delete(id uint) error
-	List(page, perPage int) ([]models.User, int64, error)
+	// List pages through users in ID order, narrowed by filter
+	List(filter models.UserListFilter, page, perPage int) ([]models.User, int64, error)
 }
 
 type userRepository struct {
@@ -24,6 +43,10 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+func (r *userRepository) WithContext(ctx context.Context) UserRepository {
+	return &userRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *userRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
@@ -37,6 +60,24 @@ func (r *userRepository) GetByID(id uint) (*models.User, error) {
 	return &user, nil
 }
 
+// GetByIDs loads several users in one query, keyed by ID. Missing and
+// deleted IDs are absent from the map.
+func (r *userRepository) GetByIDs(ids []uint) (map[uint]*models.User, error) {
+	byID := make(map[uint]*models.User, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	var users []models.User
+	if err := r.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+	return byID, nil
+}
+
 func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	var user models.User
 	err := r.db.Where("username = ?", username).First(&user).Error
@@ -55,24 +96,82 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) GetByVerificationToken(token string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("verification_token = ?", token).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+func (r *userRepository) IncrementFailedLogins(id uint, now time.Time, window time.Duration) (int, error) {
+	var user models.User
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// MySQL assigns columns left to right, so the count has to be set
+		// before last_failed_login_at changes; map keys are applied sorted
+		result := tx.Model(&models.User{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+			"failed_login_count": gorm.Expr(
+				"CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < ? THEN 1 ELSE failed_login_count + 1 END",
+				now.Add(-window),
+			),
+			"last_failed_login_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		// The update holds the row until commit, so this reads our own count
+		return tx.Select("failed_login_count").First(&user, id).Error
+	})
+	return user.FailedLoginCount, err
+}
+
+func (r *userRepository) LockAccount(id uint, until time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"locked_until":         until,
+		"failed_login_count":   0,
+		"last_failed_login_at": nil,
+	}).Error
+}
+
+func (r *userRepository) ClearFailedLogins(id uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"locked_until":         nil,
+		"failed_login_count":   0,
+		"last_failed_login_at": nil,
+	}).Error
+}
+
 func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&models.User{}, id).Error
 }
 
-func (r *userRepository) List(page, perPage int) ([]models.User, int64, error) {
+func (r *userRepository) List(filter models.UserListFilter, page, perPage int) ([]models.User, int64, error) {
 	var users []models.User
 	var total int64
 
 	offset := (page - 1) * perPage
 
-	if err := r.db.Model(&models.User{}).Count(&total).Error; err != nil {
+	query := r.db.Model(&models.User{})
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("username LIKE ? OR email LIKE ?", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := r.db.Offset(offset).Limit(perPage).Find(&users).Error
+	err := query.Order("id ASC").Offset(offset).Limit(perPage).Find(&users).Error
 	return users, total, err
 }
@@ -0,0 +1,158 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type fakePostRepo struct {
+	repositories.PostRepository
+	posts map[uint]*models.Post
+	err   error
+}
+
+func (r *fakePostRepo) WithContext(ctx context.Context) repositories.PostRepository {
+	return r
+}
+
+func (r *fakePostRepo) GetByID(id uint) (*models.Post, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return post, nil
+}
+
+type fakeCommentRepo struct {
+	repositories.CommentRepository
+	comments map[uint]*models.Comment
+}
+
+func (r *fakeCommentRepo) WithContext(ctx context.Context) repositories.CommentRepository {
+	return r
+}
+
+func (r *fakeCommentRepo) GetByID(id uint) (*models.Comment, error) {
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return comment, nil
+}
+
+// guardedRouter guards PUT /:id with guard. The caller's ID and role come
+// from the query string in place of AuthMiddleware.
+func guardedRouter(guard gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/:id", func(c *gin.Context) {
+		c.Set("user_id", uint(7))
+		if c.Query("as") == "other" {
+			c.Set("user_id", uint(8))
+		}
+		c.Set("user_role", c.DefaultQuery("role", "author"))
+	}, guard, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// ownershipRouter guards PUT /:id with the owner lookup
+func ownershipRouter(getOwnerID func(*gin.Context) (uint, error)) *gin.Engine {
+	return guardedRouter(middleware.OwnerOrAdminMiddleware(getOwnerID))
+}
+
+func put(router *gin.Engine, path string) int {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, path, nil))
+	return w.Code
+}
+
+func TestPostOwnerID(t *testing.T) {
+	router := ownershipRouter(postOwnerID(&fakePostRepo{posts: map[uint]*models.Post{1: {ID: 1, AuthorID: 7}}}))
+
+	t.Run("the author may edit their post", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, put(router, "/1"))
+	})
+
+	t.Run("other authors are forbidden", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, put(router, "/1?as=other"))
+	})
+
+	t.Run("admins may edit any post", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, put(router, "/1?as=other&role=admin"))
+	})
+
+	t.Run("editors may not pass the owner or admin check", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, put(router, "/1?as=other&role=editor"))
+	})
+
+	t.Run("missing posts are not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, put(router, "/2"))
+	})
+
+	t.Run("invalid ids are rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, put(router, "/abc"))
+	})
+
+	t.Run("lookup failures are server errors", func(t *testing.T) {
+		broken := ownershipRouter(postOwnerID(&fakePostRepo{err: errors.New("connection refused")}))
+		assert.Equal(t, http.StatusInternalServerError, put(broken, "/1"))
+	})
+}
+
+func TestCommentOwnerID(t *testing.T) {
+	router := ownershipRouter(commentOwnerID(&fakeCommentRepo{comments: map[uint]*models.Comment{3: {ID: 3, UserID: 7}}}))
+
+	t.Run("the commenter may edit their comment", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, put(router, "/3"))
+	})
+
+	t.Run("other authors are forbidden", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, put(router, "/3?as=other"))
+	})
+
+	t.Run("missing comments are not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, put(router, "/4"))
+	})
+}
+
+func TestEditorGuards(t *testing.T) {
+	posts := &fakePostRepo{posts: map[uint]*models.Post{1: {ID: 1, AuthorID: 7}}}
+
+	t.Run("editors may update another author's post", func(t *testing.T) {
+		router := guardedRouter(middleware.OwnerOrEditorMiddleware(postOwnerID(posts)))
+
+		assert.Equal(t, http.StatusNoContent, put(router, "/1?as=other&role=editor"))
+		assert.Equal(t, http.StatusForbidden, put(router, "/1?as=other"))
+	})
+
+	t.Run("editors may not create categories", func(t *testing.T) {
+		router := guardedRouter(middleware.AdminOnly())
+
+		assert.Equal(t, http.StatusForbidden, put(router, "/1?role=editor"))
+		assert.Equal(t, http.StatusNoContent, put(router, "/1?role=admin"))
+	})
+
+	t.Run("bulk post actions turn authors away", func(t *testing.T) {
+		router := guardedRouter(middleware.EditorOrAdminMiddleware())
+
+		assert.Equal(t, http.StatusNoContent, put(router, "/1?role=editor"))
+		assert.Equal(t, http.StatusNoContent, put(router, "/1?role=admin"))
+		assert.Equal(t, http.StatusForbidden, put(router, "/1?role=author"))
+	})
+}
@@ -4,10 +4,15 @@ import (
 	"backend/internal/handlers"
 	"backend/internal/middleware"
 	"backend/internal/models"
+	"backend/internal/repositories"
 	"backend/internal/services"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func SetupRoutes(
@@ -20,38 +25,65 @@ func SetupRoutes(
 	docsHandler *handlers.DocsHandler,
 	healthHandler *handlers.HealthHandler,
 	metricsHandler *handlers.MetricsHandler,
+	graphqlHandler *handlers.GraphQLHandler,
+	notificationHandler *handlers.NotificationHandler,
+	emailHandler *handlers.EmailHandler,
+	maintenanceHandler *handlers.MaintenanceHandler,
+	userHandler *handlers.UserHandler,
+	routesHandler *handlers.RoutesHandler,
+	statsHandler *handlers.StatsHandler,
+	auditLogHandler *handlers.AuditLogHandler,
+	postRepo repositories.PostRepository,
+	commentRepo repositories.CommentRepository,
 	jwtService services.JWTService,
+	rateLimitStore middleware.RateLimiterStore,
 ) {
-	// Kubernetes health check endpoints (without middleware for reliability)
-	r.GET("/healthz", healthHandler.LivenessCheck) // Liveness probe
-	r.GET("/readyz", healthHandler.ReadinessCheck) // Readiness probe
+	// Health endpoints only report status publicly; an admin token or an
+	// internal network unlocks system details
+	healthAuth := middleware.OptionalAuthMiddleware(jwtService)
 
-	// General health check with full details
-	r.GET("/health", healthHandler.HealthCheck)
+	// Kubernetes health check endpoints
+	r.GET("/healthz", healthAuth, healthHandler.LivenessCheck) // Liveness probe
+	r.GET("/readyz", healthAuth, healthHandler.ReadinessCheck) // Readiness probe
 
-	// Prometheus metrics endpoint (optional - can be disabled in production)
-	r.GET("/metrics", metricsHandler.Metrics)
+	// General health check, and the readiness probe under the same prefix
+	r.GET("/health", healthAuth, healthHandler.HealthCheck)
+	r.GET("/health/ready", healthAuth, healthHandler.ReadinessCheck)
+
+	// Prometheus metrics endpoint, guarded as METRICS_GUARD says. The
+	// listener guard serves it on its own address instead.
+	if metricsHandler.OnAPIPort() {
+		r.GET("/metrics", metricsHandler.Metrics)
+	}
 
 	// API v1 routes
 	v1 := r.Group("/api/v1")
 
+	// Everything but uploads takes JSON bodies
+	jsonOnly := middleware.ContentTypeMiddleware(middleware.MediaTypeJSON)
+
 	// Documentation routes (public, with light rate limiting)
 	docs := v1.Group("/docs")
-	docs.Use(middleware.RateLimitMiddleware(30)) // 30 requests per minute for docs
+	docs.Use(middleware.RateLimitMiddleware(rateLimitStore, 30)) // 30 requests per minute for docs
 	docsHandler.SetupRoutes(docs)
 
 	// Auth routes (public, with strict rate limiting)
 	auth := v1.Group("/auth")
-	auth.Use(middleware.RateLimitMiddleware(10)) // 10 requests per minute for auth
+	auth.Use(middleware.RateLimitMiddleware(rateLimitStore, 10)) // 10 requests per minute for auth
+	auth.Use(jsonOnly)
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
 		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/forgot-password", authHandler.ForgotPassword)
+		auth.POST("/reset-password", authHandler.ResetPassword)
+		auth.GET("/verify", authHandler.VerifyEmail)
 
 		// Protected auth routes
 		authProtected := auth.Group("")
 		authProtected.Use(middleware.AuthMiddleware(jwtService))
 		{
+			authProtected.GET("/me", authHandler.Me)
 			authProtected.GET("/profile", authHandler.GetProfile)
 			authProtected.PUT("/profile", authHandler.UpdateProfile)
 			authProtected.POST("/change-password", authHandler.ChangePassword)
@@ -62,9 +94,11 @@ func SetupRoutes(
 
 	// Categories routes
 	categories := v1.Group("/categories")
+	categories.Use(jsonOnly)
 	{
 		// Public routes (read-only)
 		categories.GET("", categoryHandler.List)
+		categories.GET("/tree", categoryHandler.GetTree)
 		categories.GET("/:id", categoryHandler.GetByID)
 		categories.GET("/slug/:slug", categoryHandler.GetBySlug)
 
@@ -81,88 +115,162 @@ func SetupRoutes(
 
 	// Posts routes
 	posts := v1.Group("/posts")
+	posts.Use(jsonOnly)
 	{
 		// Public routes (read-only)
-		posts.GET("", postHandler.List)
-		posts.GET("/:id", postHandler.GetByID)
-		posts.GET("/slug/:slug", postHandler.GetBySlug)
-		posts.GET("/author/:author_id", postHandler.GetByAuthor)
-		posts.GET("/category/:category_id", postHandler.GetByCategory)
+		posts.GET("", middleware.OptionalAuthMiddleware(jwtService), postHandler.List)
+		posts.GET("/by-slug", middleware.OptionalAuthMiddleware(jwtService), postHandler.GetBySlugs)
+		posts.GET("/random", postHandler.Random)
+		posts.POST("/comment-summary", middleware.OptionalAuthMiddleware(jwtService), commentHandler.GetSummaries)
+		// Accepts a numeric ID or a slug; see PostHandler.GetByID for precedence
+		posts.GET("/:id", middleware.OptionalAuthMiddleware(jwtService), postHandler.GetByID)
+		posts.GET("/:id/comment-tree", middleware.OptionalAuthMiddleware(jwtService), commentHandler.GetCommentTree)
+		posts.POST("/:id/view", middleware.RateLimitMiddleware(rateLimitStore, 10), middleware.OptionalAuthMiddleware(jwtService), postHandler.View) // 10 views per post per minute
+		posts.GET("/slug/:slug", middleware.OptionalAuthMiddleware(jwtService), postHandler.GetBySlug)
+		posts.GET("/author/:author_id", middleware.OptionalAuthMiddleware(jwtService), postHandler.GetByAuthor)
+		posts.GET("/category/:category_id", middleware.OptionalAuthMiddleware(jwtService), postHandler.GetByCategory)
+		posts.GET("/tag/:slug", middleware.OptionalAuthMiddleware(jwtService), postHandler.GetByTag)
 
 		// Protected routes (authenticated users)
 		postsProtected := posts.Group("")
 		postsProtected.Use(middleware.AuthMiddleware(jwtService))
 		{
 			postsProtected.POST("", postHandler.Create)
+			postsProtected.GET("/:id/stats", postHandler.Stats)
 
-			// Owner or admin can update/delete
-			postsProtected.PUT("/:id", middleware.OwnerOrAdminMiddleware(getPostOwnerID), postHandler.Update)
-			postsProtected.DELETE("/:id", middleware.OwnerOrAdminMiddleware(getPostOwnerID), postHandler.Delete)
+			// Publish, archive or delete many posts in one transaction;
+			// editors or admins, and only admins may delete
+			postsProtected.POST("/bulk", middleware.EditorOrAdminMiddleware(), postHandler.Bulk)
+
+			// Owner, editor or admin can update; owner or admin can delete
+			postsProtected.PUT("/:id", middleware.OwnerOrEditorMiddleware(postOwnerID(postRepo)), postHandler.Update)
+			postsProtected.DELETE("/:id", middleware.OwnerOrAdminMiddleware(postOwnerID(postRepo)), postHandler.Delete)
 		}
 	}
 
+	// Public author profiles
+	v1.GET("/authors/:id", userHandler.GetAuthorProfile)
+
 	// Comments routes
 	comments := v1.Group("/comments")
+	comments.Use(jsonOnly)
 	{
-		// Public routes (read-only)
-		comments.GET("", commentHandler.List)
-		comments.GET("/:id", commentHandler.GetByID)
-		comments.GET("/post/:post_id", commentHandler.GetByPost)
-		comments.GET("/user/:user_id", commentHandler.GetByUser)
+		// Public routes (read-only); readers see approved comments, admins
+		// may ask for others with ?status=
+		comments.GET("", middleware.OptionalAuthMiddleware(jwtService), commentHandler.List)
+		comments.GET("/recent", commentHandler.GetRecent)
+		comments.GET("/:id", middleware.OptionalAuthMiddleware(jwtService), commentHandler.GetByID)
+		comments.GET("/post/:post_id", middleware.OptionalAuthMiddleware(jwtService), commentHandler.GetByPost)
+		comments.GET("/post/:post_id/thread", middleware.OptionalAuthMiddleware(jwtService), commentHandler.GetThread)
+		comments.GET("/user/:user_id", middleware.OptionalAuthMiddleware(jwtService), commentHandler.GetByUser)
 
 		// Protected routes (authenticated users)
 		commentsProtected := comments.Group("")
 		commentsProtected.Use(middleware.AuthMiddleware(jwtService))
 		{
 			commentsProtected.POST("", commentHandler.Create)
+			commentsProtected.POST("/preview", middleware.RateLimitMiddleware(rateLimitStore, 30), commentHandler.Preview) // 30 previews per minute
+
+			// Post author or admin can pin one comment per post
+			commentsProtected.POST("/:id/pin", commentHandler.Pin)
+			commentsProtected.DELETE("/:id/pin", commentHandler.Unpin)
 
 			// Owner or admin can update/delete
-			commentsProtected.PUT("/:id", middleware.OwnerOrAdminMiddleware(getCommentOwnerID), commentHandler.Update)
-			commentsProtected.DELETE("/:id", middleware.OwnerOrAdminMiddleware(getCommentOwnerID), commentHandler.Delete)
+			commentsProtected.PUT("/:id", middleware.OwnerOrAdminMiddleware(commentOwnerID(commentRepo)), commentHandler.Update)
+			commentsProtected.DELETE("/:id", middleware.OwnerOrAdminMiddleware(commentOwnerID(commentRepo)), commentHandler.Delete)
 		}
 	}
 
+	// Current user's resources (authenticated)
+	me := v1.Group("/me")
+	me.Use(jsonOnly)
+	me.Use(middleware.AuthMiddleware(jwtService))
+	{
+		me.GET("/posts/usage", postHandler.Usage)
+		me.GET("/recent", postHandler.RecentlyViewed)
+		me.GET("/notifications", notificationHandler.List)
+		me.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+		me.POST("/notifications/:id/read", notificationHandler.MarkRead)
+		me.GET("/preferences", notificationHandler.GetPreferences)
+		me.PUT("/preferences", notificationHandler.UpdatePreferences)
+	}
+
+	// Site-wide announcements, such as scheduled maintenance (public)
+	v1.GET("/announcements", maintenanceHandler.Announcements)
+
+	// GraphQL (read-only; visibility follows the caller's token when present)
+	v1.POST("/graphql", jsonOnly, middleware.OptionalAuthMiddleware(jwtService), graphqlHandler.Query)
+
 	// Upload routes (protected, author/admin only)
 	uploads := v1.Group("/uploads")
 	{
 		// Public routes
 		uploads.GET("/info", uploadHandler.GetUploadInfo)
 		uploads.GET("/:filename", uploadHandler.ServeLocalImage)
+		uploads.GET("/mine", middleware.AuthMiddleware(jwtService), uploadHandler.ListMine)
 
 		// Protected routes (author/admin only)
 		uploadsProtected := uploads.Group("")
 		uploadsProtected.Use(middleware.AuthMiddleware(jwtService))
 		uploadsProtected.Use(middleware.AuthorOrAdminMiddleware())
 		{
-			uploadsProtected.POST("/images", uploadHandler.UploadImage)
+			uploadsProtected.POST("/images", middleware.ContentTypeMiddleware(middleware.MediaTypeMultipart), uploadHandler.UploadImage)
 			uploadsProtected.DELETE("/images/:filename", uploadHandler.DeleteImage)
 		}
 	}
 
 	// Admin routes (admin only)
 	admin := v1.Group("/admin")
+	admin.Use(jsonOnly)
 	admin.Use(middleware.AuthMiddleware(jwtService))
 	admin.Use(middleware.AdminOnly())
 	{
 		// User management
-		admin.GET("/users", func(c *gin.Context) {
-			// TODO: Implement user list endpoint
-			c.JSON(http.StatusOK, models.APIResponse{
-				Success: true,
-				Message: "Admin endpoint - user list",
-				Data:    []string{"Coming soon"},
-			})
-		})
+		admin.GET("/users", userHandler.List)
+		admin.GET("/users/:id", userHandler.GetByID)
+		admin.PUT("/users/:id/role", userHandler.UpdateRole)
+		admin.DELETE("/users/:id", userHandler.Delete)
+
+		// Emails that could not be delivered
+		admin.GET("/email-failures", emailHandler.RecentFailures)
+
+		// Create many categories at once, e.g. when setting up a new blog
+		admin.POST("/categories/bulk", categoryHandler.CreateBulk)
+
+		// Deleted posts, restorable until the purge job removes them
+		admin.GET("/posts/trash", postHandler.ListTrash)
+		admin.POST("/posts/:id/restore", postHandler.Restore)
+
+		// Hand posts to another author, one at a time or all of an author's
+		admin.POST("/posts/:id/transfer", postHandler.Transfer)
+		admin.POST("/posts/transfer", postHandler.TransferAll)
+
+		// Comment moderation queue
+		admin.GET("/comments/pending", commentHandler.ListPending)
+		admin.POST("/comments/:id/approve", commentHandler.Approve)
+		admin.POST("/comments/:id/reject", commentHandler.Reject)
+
+		// Clear in-process caches, e.g. after editing the database by hand
+		admin.POST("/maintenance/flush-cache", maintenanceHandler.FlushCache)
+
+		// Scheduled maintenance windows, during which writes are refused
+		admin.GET("/maintenance/windows", maintenanceHandler.ListWindows)
+		admin.POST("/maintenance/windows", maintenanceHandler.CreateWindow)
+		admin.GET("/maintenance/windows/:id", maintenanceHandler.GetWindow)
+		admin.PUT("/maintenance/windows/:id", maintenanceHandler.UpdateWindow)
+		admin.DELETE("/maintenance/windows/:id", maintenanceHandler.DeleteWindow)
+
+		// Registered routes, for checking what is actually served
+		if routesHandler.Enabled() {
+			admin.GET("/routes", routesHandler.List)
+		}
 
 		// System statistics
-		admin.GET("/stats", func(c *gin.Context) {
-			// TODO: Implement system statistics
-			c.JSON(http.StatusOK, models.APIResponse{
-				Success: true,
-				Message: "Admin endpoint - system statistics",
-				Data:    map[string]interface{}{"status": "Coming soon"},
-			})
-		})
+		admin.GET("/stats", statsHandler.Get)
+
+		// Audit log of admin actions, and a download of the filtered entries
+		admin.GET("/audit", auditLogHandler.List)
+		admin.GET("/audit/export", auditLogHandler.Export)
 	}
 
 	// 404 handler
@@ -176,17 +284,48 @@ func SetupRoutes(
 	})
 }
 
-// Helper functions to get resource owner IDs
-func getPostOwnerID(c *gin.Context) (uint, error) {
-	// TODO: Implement logic to get post owner ID from database
-	// This should query the post by ID and return the author_id
-	// For now, return a dummy implementation
-	return 1, nil
+// postOwnerID resolves the author of the post named by the :id parameter
+func postOwnerID(postRepo repositories.PostRepository) func(*gin.Context) (uint, error) {
+	return func(c *gin.Context) (uint, error) {
+		id, err := resourceID(c)
+		if err != nil {
+			return 0, err
+		}
+		post, err := postRepo.WithContext(c.Request.Context()).GetByID(id)
+		if err != nil {
+			return 0, ownerLookupError(err)
+		}
+		return post.AuthorID, nil
+	}
+}
+
+// commentOwnerID resolves the author of the comment named by the :id
+// parameter
+func commentOwnerID(commentRepo repositories.CommentRepository) func(*gin.Context) (uint, error) {
+	return func(c *gin.Context) (uint, error) {
+		id, err := resourceID(c)
+		if err != nil {
+			return 0, err
+		}
+		comment, err := commentRepo.WithContext(c.Request.Context()).GetByID(id)
+		if err != nil {
+			return 0, ownerLookupError(err)
+		}
+		return comment.UserID, nil
+	}
 }
 
-func getCommentOwnerID(c *gin.Context) (uint, error) {
-	// TODO: Implement logic to get comment owner ID from database
-	// This should query the comment by ID and return the user_id
-	// For now, return a dummy implementation
-	return 1, nil
+func resourceID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", middleware.ErrInvalidResourceID, c.Param("id"))
+	}
+	return uint(id), nil
+}
+
+func ownerLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return middleware.ErrResourceNotFound
+	}
+	return err
 }
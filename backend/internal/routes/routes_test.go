@@ -0,0 +1,162 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/handlers"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJWTService accepts any token and takes the role from it
+type fakeJWTService struct {
+	services.JWTService
+}
+
+func (s *fakeJWTService) ValidateAccessToken(token string) (*models.JWTClaims, error) {
+	return &models.JWTClaims{UserID: 1, Role: token, Type: "access"}, nil
+}
+
+func newRouter(exposeRoutes bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	SetupRoutes(r,
+		&handlers.AuthHandler{},
+		&handlers.PostHandler{},
+		&handlers.CategoryHandler{},
+		&handlers.CommentHandler{},
+		&handlers.UploadHandler{},
+		handlers.NewDocsHandler(),
+		&handlers.HealthHandler{},
+		handlers.NewMetricsHandler(&config.MetricsConfig{}),
+		&handlers.GraphQLHandler{},
+		&handlers.NotificationHandler{},
+		&handlers.EmailHandler{},
+		&handlers.MaintenanceHandler{},
+		&handlers.UserHandler{},
+		handlers.NewRoutesHandler(r, exposeRoutes),
+		&handlers.StatsHandler{},
+		&handlers.AuditLogHandler{},
+		&fakePostRepo{},
+		&fakeCommentRepo{},
+		&fakeJWTService{},
+		middleware.NewRateLimiter(0),
+	)
+	return r
+}
+
+func getRoutes(router *gin.Engine, role string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/routes", nil)
+	req.Header.Set("Content-Type", "application/json")
+	if role != "" {
+		req.Header.Set("Authorization", "Bearer "+role)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminRoutes(t *testing.T) {
+	router := newRouter(true)
+
+	t.Run("lists the registered routes", func(t *testing.T) {
+		w := getRoutes(router, "admin")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data []handlers.RouteInfo `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		routes := make(map[string]bool, len(resp.Data))
+		for _, route := range resp.Data {
+			routes[route.Method+" "+route.Path] = true
+		}
+		assert.True(t, routes["GET /api/v1/posts"])
+		assert.True(t, routes["POST /api/v1/auth/login"])
+		assert.True(t, routes["GET /api/v1/admin/routes"])
+	})
+
+	t.Run("requires an admin", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, getRoutes(router, "").Code)
+		assert.Equal(t, http.StatusForbidden, getRoutes(router, "editor").Code)
+		assert.Equal(t, http.StatusForbidden, getRoutes(router, "author").Code)
+	})
+
+	t.Run("is not served when disabled", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, getRoutes(newRouter(false), "admin").Code)
+	})
+}
+
+func TestPostTransferRoutes(t *testing.T) {
+	router := newRouter(true)
+	transfer := func(path, role string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"author_id":2,"from_author_id":2,"to_author_id":3}`))
+		req.Header.Set("Content-Type", "application/json")
+		if role != "" {
+			req.Header.Set("Authorization", "Bearer "+role)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, path := range []string{"/api/v1/admin/posts/1/transfer", "/api/v1/admin/posts/transfer"} {
+		assert.Equal(t, http.StatusUnauthorized, transfer(path, ""), path)
+		assert.Equal(t, http.StatusForbidden, transfer(path, "editor"), path)
+		assert.Equal(t, http.StatusForbidden, transfer(path, "author"), path)
+	}
+}
+
+func TestCommentModerationRoutes(t *testing.T) {
+	router := newRouter(true)
+	request := func(method, path, role string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Content-Type", "application/json")
+		if role != "" {
+			req.Header.Set("Authorization", "Bearer "+role)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, route := range [][2]string{
+		{http.MethodGet, "/api/v1/admin/comments/pending"},
+		{http.MethodPost, "/api/v1/admin/comments/1/approve"},
+		{http.MethodPost, "/api/v1/admin/comments/1/reject"},
+	} {
+		assert.Equal(t, http.StatusUnauthorized, request(route[0], route[1], ""), route[1])
+		assert.Equal(t, http.StatusForbidden, request(route[0], route[1], "editor"), route[1])
+		assert.Equal(t, http.StatusForbidden, request(route[0], route[1], "author"), route[1])
+	}
+}
+
+func TestAuditLogRoutes(t *testing.T) {
+	router := newRouter(true)
+	get := func(path, role string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if role != "" {
+			req.Header.Set("Authorization", "Bearer "+role)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, path := range []string{"/api/v1/admin/audit", "/api/v1/admin/audit/export"} {
+		assert.Equal(t, http.StatusUnauthorized, get(path, ""), path)
+		assert.Equal(t, http.StatusForbidden, get(path, "editor"), path)
+		assert.Equal(t, http.StatusForbidden, get(path, "author"), path)
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRevokedTokenRepo keeps revoked access tokens by jti
+type fakeRevokedTokenRepo struct {
+	repositories.RevokedAccessTokenRepository
+	tokens map[string]models.RevokedAccessToken
+}
+
+func newFakeRevokedTokenRepo() *fakeRevokedTokenRepo {
+	return &fakeRevokedTokenRepo{tokens: make(map[string]models.RevokedAccessToken)}
+}
+
+func (r *fakeRevokedTokenRepo) Create(token *models.RevokedAccessToken) error {
+	if _, ok := r.tokens[token.JTI]; !ok {
+		r.tokens[token.JTI] = *token
+	}
+	return nil
+}
+
+func (r *fakeRevokedTokenRepo) IsRevoked(jti string) (bool, error) {
+	_, ok := r.tokens[jti]
+	return ok, nil
+}
+
+func (r *fakeRevokedTokenRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	for jti, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, jti)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// fakeRevokingRefreshTokenRepo keeps refresh tokens and their revocations
+type fakeRevokingRefreshTokenRepo struct {
+	fakeRefreshTokenRepo
+	tokens []*models.RefreshToken
+}
+
+func (r *fakeRevokingRefreshTokenRepo) Create(token *models.RefreshToken) error {
+	stored := *token
+	r.tokens = append(r.tokens, &stored)
+	return nil
+}
+
+func (r *fakeRevokingRefreshTokenRepo) RevokeAllUserTokens(userID uint) error {
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			token.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeRevokingRefreshTokenRepo) GetWithLiveAccessTokens(userID uint, now time.Time) ([]*models.RefreshToken, error) {
+	var live []*models.RefreshToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.AccessJTI != "" && token.AccessExpiresAt.After(now) {
+			live = append(live, token)
+		}
+	}
+	return live, nil
+}
+
+func newRevocableJWTService() (JWTService, *fakeRevokedTokenRepo) {
+	revoked := newFakeRevokedTokenRepo()
+	return NewJWTService(&fakeRevokingRefreshTokenRepo{}, revoked, &config.Config{}), revoked
+}
+
+func signIn(t *testing.T, jwtService JWTService) (string, *models.JWTClaims) {
+	t.Helper()
+	pair, err := jwtService.GenerateTokenPair(&models.User{ID: 7, Email: "reader@example.com", Role: "author"})
+	require.NoError(t, err)
+	claims, err := jwtService.ValidateAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+	return pair.AccessToken, claims
+}
+
+func TestJWTService_RevokeAccessToken(t *testing.T) {
+	t.Run("access tokens carry a unique jti", func(t *testing.T) {
+		jwtService, _ := newRevocableJWTService()
+
+		_, first := signIn(t, jwtService)
+		_, second := signIn(t, jwtService)
+
+		assert.NotEmpty(t, first.ID)
+		assert.NotEqual(t, first.ID, second.ID)
+	})
+
+	t.Run("a revoked token is rejected before it expires", func(t *testing.T) {
+		jwtService, revoked := newRevocableJWTService()
+		token, claims := signIn(t, jwtService)
+		require.Greater(t, claims.ExpiresAt, time.Now().Unix())
+
+		require.NoError(t, jwtService.RevokeAccessToken(claims))
+		_, err := jwtService.ValidateAccessToken(token)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "revoked")
+		assert.Equal(t, time.Unix(claims.ExpiresAt, 0), revoked.tokens[claims.ID].ExpiresAt)
+	})
+
+	t.Run("other tokens of the user stay valid", func(t *testing.T) {
+		jwtService, _ := newRevocableJWTService()
+		_, claims := signIn(t, jwtService)
+		other, _ := signIn(t, jwtService)
+
+		require.NoError(t, jwtService.RevokeAccessToken(claims))
+		_, err := jwtService.ValidateAccessToken(other)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired entries are purged", func(t *testing.T) {
+		jwtService, revoked := newRevocableJWTService()
+		revoked.tokens["old"] = models.RevokedAccessToken{JTI: "old", ExpiresAt: time.Now().Add(-time.Minute)}
+		revoked.tokens["live"] = models.RevokedAccessToken{JTI: "live", ExpiresAt: time.Now().Add(time.Minute)}
+
+		require.NoError(t, jwtService.PurgeRevokedAccessTokens(context.Background()))
+
+		assert.NotContains(t, revoked.tokens, "old")
+		assert.Contains(t, revoked.tokens, "live")
+	})
+}
+
+func TestJWTService_RevokeAllUserTokens(t *testing.T) {
+	jwtService, _ := newRevocableJWTService()
+	first, _ := signIn(t, jwtService)
+	second, _ := signIn(t, jwtService)
+	other, err := jwtService.GenerateTokenPair(&models.User{ID: 8, Email: "writer@example.com", Role: "author"})
+	require.NoError(t, err)
+
+	require.NoError(t, jwtService.RevokeAllUserTokens(7))
+
+	for _, token := range []string{first, second} {
+		_, err := jwtService.ValidateAccessToken(token)
+		assert.Error(t, err, "every live access token of the user is denied")
+	}
+	_, err = jwtService.ValidateAccessToken(other.AccessToken)
+	assert.NoError(t, err, "other users stay signed in")
+}
+
+func TestAuthService_LogoutAllRevokesAccessToken(t *testing.T) {
+	jwtService, _ := newRevocableJWTService()
+	authService := NewAuthService(newFakeUserRepo(), nil, jwtService, &config.Config{})
+	token, claims := signIn(t, jwtService)
+
+	require.NoError(t, authService.LogoutAll(claims.UserID, claims))
+	_, err := jwtService.ValidateAccessToken(token)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Page sizes for the audit log listing
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 200
+)
+
+// auditExportBatch is how many entries Export loads at a time
+const auditExportBatch = 500
+
+// ErrInvalidAuditCursor is returned for a cursor List did not hand out
+var ErrInvalidAuditCursor = errors.New("invalid audit log cursor")
+
+type AuditLogService interface {
+	// WithContext returns a copy of the service whose queries run with ctx,
+	// typically the request's, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) AuditLogService
+	// List returns a page of the entries matching filter, newest first,
+	// starting after cursor, or at the newest entry when cursor is empty. The
+	// limit is clamped to MaxAuditPageSize.
+	List(filter models.AuditLogFilter, cursor string, limit int) (*models.AuditLogPage, error)
+	// Export calls write with every entry matching filter, newest first,
+	// loading them in batches so the whole log is never held in memory. It
+	// stops at the first error write returns.
+	Export(filter models.AuditLogFilter, write func(entry models.AuditLog) error) error
+	// Purge deletes entries older than the configured retention
+	Purge(ctx context.Context) error
+}
+
+type auditLogService struct {
+	auditRepo repositories.AuditLogRepository
+	maxAge    time.Duration
+}
+
+func NewAuditLogService(auditRepo repositories.AuditLogRepository, cfg *config.Config) AuditLogService {
+	return &auditLogService{
+		auditRepo: auditRepo,
+		maxAge:    time.Duration(cfg.Jobs.AuditRetentionDays) * 24 * time.Hour,
+	}
+}
+
+func (s *auditLogService) WithContext(ctx context.Context) AuditLogService {
+	scoped := *s
+	scoped.auditRepo = s.auditRepo.WithContext(ctx)
+	return &scoped
+}
+
+func (s *auditLogService) List(filter models.AuditLogFilter, cursor string, limit int) (*models.AuditLogPage, error) {
+	var beforeID uint
+	if cursor != "" {
+		id, err := strconv.ParseUint(cursor, 10, 32)
+		if err != nil || id == 0 {
+			return nil, ErrInvalidAuditCursor
+		}
+		beforeID = uint(id)
+	}
+	if limit <= 0 {
+		limit = DefaultAuditPageSize
+	}
+	if limit > MaxAuditPageSize {
+		limit = MaxAuditPageSize
+	}
+
+	// One extra entry tells whether there is a next page
+	entries, err := s.auditRepo.List(filter, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.AuditLogPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = strconv.FormatUint(uint64(page.Entries[limit-1].ID), 10)
+	}
+	return page, nil
+}
+
+func (s *auditLogService) Export(filter models.AuditLogFilter, write func(entry models.AuditLog) error) error {
+	var beforeID uint
+	for {
+		entries, err := s.auditRepo.List(filter, beforeID, auditExportBatch)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := write(entry); err != nil {
+				return err
+			}
+		}
+		if len(entries) < auditExportBatch {
+			return nil
+		}
+		beforeID = entries[len(entries)-1].ID
+	}
+}
+
+func (s *auditLogService) Purge(ctx context.Context) error {
+	// Without a retention period entries are kept for good
+	if s.maxAge <= 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-s.maxAge)
+	purged, err := s.auditRepo.WithContext(ctx).PurgeBefore(before)
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		logger.LogInfo(ctx, "Purged old audit log entries",
+			zap.Int64("purged", purged),
+			zap.Time("created_before", before),
+		)
+	}
+	return nil
+}
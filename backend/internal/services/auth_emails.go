@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"backend/internal/models"
+	"backend/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// sendEmail hands an account email to the notifiers. A failed send is only
+// logged, so it never fails the registration or reset that triggered it;
+// with email configured the notifier is an EmailQueue, which sends in the
+// background and retries.
+func (s *authService) sendEmail(notification Notification) {
+	ctx := context.Background()
+	for _, notifier := range s.notifiers {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			logger.LogError(ctx, "Failed to send account email", err,
+				zap.Uint("user_id", notification.UserID),
+				zap.String("type", notification.Type),
+			)
+		}
+	}
+}
+
+// sendVerificationEmail asks user to confirm their email address with the
+// token issued to them
+func (s *authService) sendVerificationEmail(user *models.User) {
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.siteURL, url.QueryEscape(user.VerificationToken))
+	s.sendEmail(Notification{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Type:    models.NotificationEmailVerification,
+		Subject: "Confirm your email address",
+		Body:    fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening this link within %d hours:\n\n%s\n\nIf you did not create an account or change your email address, you can ignore this email.", user.Name, int(emailVerificationTTL.Hours()), link),
+		Link:    link,
+	})
+}
+
+// sendPasswordResetEmail sends user the link to choose a new password with
+// token
+func (s *authService) sendPasswordResetEmail(user *models.User, token string) {
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.siteURL, url.QueryEscape(token))
+	s.sendEmail(Notification{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Type:    models.NotificationPasswordReset,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. To choose a new password, open this link within %d minutes:\n\n%s\n\nIf it was not you, you can ignore this email; your password has not been changed.", user.Name, int(passwordResetTTL.Minutes()), link),
+		Link:    link,
+	})
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRegistrationService(defaultRole string) (AuthService, *fakeUserRepo) {
+	userRepo := newFakeUserRepo()
+	cfg := &config.Config{Auth: config.AuthConfig{DefaultRole: defaultRole}}
+	return NewAuthService(userRepo, nil, fakeJWTService{}, cfg), userRepo
+}
+
+func registration(role string) *models.RegisterRequest {
+	return &models.RegisterRequest{
+		Username: "newuser",
+		Email:    "newuser@example.com",
+		Password: "password123",
+		Name:     "New User",
+		Role:     role,
+	}
+}
+
+func TestAuthService_RegisterRoles(t *testing.T) {
+	t.Run("new users get the default role", func(t *testing.T) {
+		authService, _ := newRegistrationService("author")
+
+		user, err := authService.Register(registration(""))
+
+		require.NoError(t, err)
+		assert.Equal(t, "author", user.Role)
+	})
+
+	t.Run("requesting admin is rejected", func(t *testing.T) {
+		authService, userRepo := newRegistrationService("author")
+
+		_, err := authService.Register(registration("admin"))
+
+		assert.ErrorIs(t, err, ErrRoleNotSelfAssignable)
+		_, lookupErr := userRepo.GetByEmail("newuser@example.com")
+		assert.Error(t, lookupErr, "no user should have been created")
+	})
+
+	t.Run("an admin default role is ignored", func(t *testing.T) {
+		authService, _ := newRegistrationService("admin")
+
+		user, err := authService.Register(registration(""))
+
+		require.NoError(t, err)
+		assert.Equal(t, "author", user.Role)
+	})
+}
+
+func TestAuthService_RegisterNames(t *testing.T) {
+	t.Run("username and name are both stored", func(t *testing.T) {
+		authService, userRepo := newRegistrationService("author")
+
+		_, err := authService.Register(registration(""))
+		require.NoError(t, err)
+
+		stored, err := userRepo.GetByEmail("newuser@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "newuser", stored.Username)
+		assert.Equal(t, "New User", stored.Name)
+	})
+
+	t.Run("name defaults to the username", func(t *testing.T) {
+		authService, userRepo := newRegistrationService("author")
+		req := registration("")
+		req.Name = "  "
+
+		user, err := authService.Register(req)
+		require.NoError(t, err)
+		assert.Equal(t, "newuser", user.Name)
+
+		stored, err := userRepo.GetByEmail("newuser@example.com")
+		require.NoError(t, err)
+		assert.NotEmpty(t, stored.Username)
+		assert.Equal(t, "newuser", stored.Name)
+	})
+
+	t.Run("username is required", func(t *testing.T) {
+		authService, userRepo := newRegistrationService("author")
+		req := registration("")
+		req.Username = ""
+
+		_, err := authService.Register(req)
+
+		assert.Error(t, err)
+		assert.Empty(t, userRepo.users)
+	})
+}
+
+func TestAuthService_BootstrapAdmin(t *testing.T) {
+	authService, userRepo := newRegistrationService("author")
+
+	admin, err := authService.BootstrapAdmin(&models.RegisterRequest{
+		Username: "admin",
+		Email:    "admin@example.com",
+		Password: "bootstrap-secret",
+		Name:     "Administrator",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", admin.Role)
+	assert.Empty(t, admin.Password)
+
+	stored, err := userRepo.GetByEmail("admin@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", stored.Role)
+
+	t.Run("running again leaves the existing account alone", func(t *testing.T) {
+		again, err := authService.BootstrapAdmin(&models.RegisterRequest{
+			Username: "admin",
+			Email:    "admin@example.com",
+			Password: "another-secret",
+			Name:     "Administrator",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, admin.ID, again.ID)
+		assert.Len(t, userRepo.users, 1)
+	})
+
+	t.Run("an existing non-admin account is reported, not promoted", func(t *testing.T) {
+		authService, userRepo := newRegistrationService("author")
+		_, err := authService.Register(&models.RegisterRequest{
+			Username: "writer",
+			Email:    "writer@example.com",
+			Password: "writer-secret",
+		})
+		require.NoError(t, err)
+
+		user, err := authService.BootstrapAdmin(&models.RegisterRequest{
+			Username: "admin",
+			Email:    "writer@example.com",
+			Password: "bootstrap-secret",
+		})
+
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, ErrBootstrapEmailTaken)
+		stored, err := userRepo.GetByEmail("writer@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "author", stored.Role)
+	})
+}
@@ -1,12 +1,18 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/repositories"
+	"backend/pkg/logger"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -14,30 +20,127 @@ type AuthService interface {
 	Register(req *models.RegisterRequest) (*models.User, error)
 	Login(req *models.LoginRequest) (*models.AuthResponse, error)
 	RefreshToken(req *models.RefreshTokenRequest) (*models.RefreshTokenResponse, error)
-	Logout(userID uint, refreshToken string) error
-	LogoutAll(userID uint) error
+	// Logout revokes the given refresh token and the access token the
+	// request was made with
+	Logout(userID uint, refreshToken string, accessClaims *models.JWTClaims) error
+	// LogoutAll revokes every refresh token of the user and the access
+	// token the request was made with
+	LogoutAll(userID uint, accessClaims *models.JWTClaims) error
 	ChangePassword(userID uint, req *models.ChangePasswordRequest) error
 	GetProfile(userID uint) (*models.User, error)
 	UpdateProfile(userID uint, req *models.UpdateProfileRequest) (*models.User, error)
+	BootstrapAdmin(req *models.RegisterRequest) (*models.User, error)
+	// RequestPasswordReset issues a single-use reset token for the account
+	// with the given email, valid for an hour, emails the user a link with
+	// it and returns it.
+	// An unknown email yields an empty token and no error, so callers cannot
+	// tell whether the account exists.
+	RequestPasswordReset(email string) (string, error)
+	// ResetPassword sets a new password using a token from
+	// RequestPasswordReset and signs the user out everywhere
+	ResetPassword(token, newPassword string) error
+	// VerifyEmail confirms the email address of the user the token was
+	// issued to at registration
+	VerifyEmail(token string) (*models.User, error)
 }
 
+// ErrRoleNotSelfAssignable is returned when a registration asks for a role
+// other than the configured default for new users
+var ErrRoleNotSelfAssignable = errors.New("role cannot be self-assigned")
+
+// ErrBootstrapEmailTaken is returned by BootstrapAdmin when its email already
+// belongs to an account that is not an admin
+var ErrBootstrapEmailTaken = errors.New("bootstrap admin email belongs to a non-admin account")
+
 type authService struct {
 	userRepo repositories.UserRepository
+	resetRepo repositories.PasswordResetTokenRepository
 	jwtService JWTService
 	cfg      *config.Config
+	registrations *registrationLimiter
+	maxLoginFailures int
+	lockout  time.Duration
+	now      func() time.Time
+	notifiers []Notifier
+	siteURL  string
 }
 
-func NewAuthService(userRepo repositories.UserRepository, jwtService JWTService, cfg *config.Config) AuthService {
-	return &authService{
+// NewAuthService creates the auth service. Account emails, such as the
+// address verification sent on registration, go out through notifiers.
+func NewAuthService(userRepo repositories.UserRepository, resetRepo repositories.PasswordResetTokenRepository, jwtService JWTService, cfg *config.Config, notifiers ...Notifier) AuthService {
+	s := &authService{
 		userRepo: userRepo,
+		resetRepo: resetRepo,
 		jwtService: jwtService,
 		cfg:      cfg,
+		now:      time.Now,
+		notifiers: notifiers,
+	}
+	if cfg != nil {
+		s.siteURL = strings.TrimRight(cfg.Notify.SiteURL, "/")
+		s.registrations = newRegistrationLimiter(cfg.Auth.RegistrationsPerIP, cfg.Auth.RegistrationWindow)
+		s.maxLoginFailures = cfg.Auth.LoginMaxFailures
+		s.lockout = cfg.Auth.LoginLockout
 	}
+	return s
 }
 
 func (s *authService) Register(req *models.RegisterRequest) (*models.User, error) {
+	// Public registration only ever grants the default role; elevated roles
+	// are handed out by the bootstrap flow or an admin
+	role := s.defaultRole()
+	if req.Role != "" && req.Role != role {
+		return nil, ErrRoleNotSelfAssignable
+	}
+
+	if !s.registrations.Allow(req.ClientIP) {
+		return nil, ErrRegistrationLimitReached
+	}
+
+	user, err := s.createUser(req, role, false)
+	if err != nil {
+		return nil, err
+	}
+	s.registrations.Record(req.ClientIP)
+	return user, nil
+}
+
+// BootstrapAdmin creates the initial admin account. It is idempotent: if an
+// admin with the given email already exists it is returned unchanged. An
+// existing account with another role is left alone and reported with
+// ErrBootstrapEmailTaken rather than promoted.
+func (s *authService) BootstrapAdmin(req *models.RegisterRequest) (*models.User, error) {
+	if existing, err := s.userRepo.GetByEmail(req.Email); err == nil {
+		if existing.Role != "admin" {
+			return nil, fmt.Errorf("%w: %s is an %s", ErrBootstrapEmailTaken, req.Email, existing.Role)
+		}
+		existing.Password = ""
+		return existing, nil
+	}
+
+	if req.Password == "" {
+		return nil, errors.New("bootstrap admin password is required")
+	}
+
+	return s.createUser(req, "admin", true)
+}
+
+// createUser creates an account. Unless emailVerified is set, the account
+// starts unverified with a verification token for its email address.
+func (s *authService) createUser(req *models.RegisterRequest, role string, emailVerified bool) (*models.User, error) {
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		return nil, errors.New("username is required")
+	}
+
+	// The display name falls back to the username
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = username
+	}
+
 	// Check if username already exists
-	if _, err := s.userRepo.GetByUsername(req.Username); err == nil {
+	if _, err := s.userRepo.GetByUsername(username); err == nil {
 		return nil, errors.New("username already exists")
 	}
 
@@ -52,29 +155,42 @@ func (s *authService) Register(req *models.RegisterRequest) (*models.User, error
 		return nil, errors.New("failed to process password")
 	}
 
-	// Set default role if not provided
-	role := req.Role
-	if role == "" {
-		role = "author"
-	}
-
 	user := &models.User{
-		Username: req.Username,
-		Email:    req.Email,
-		Name:     req.Name,
-		Password: hashedPassword,
-		Role:     role,
+		Username:        username,
+		Email:           req.Email,
+		Name:            name,
+		Password:        hashedPassword,
+		Role:            role,
+		NotifyOnComment: true,
+		EmailVerified:   emailVerified,
+	}
+	if !emailVerified {
+		if err := issueVerificationToken(user); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, errors.New("failed to create user")
 	}
+	if !emailVerified {
+		s.sendVerificationEmail(user)
+	}
 
 	// Remove password from response
 	user.Password = ""
 	return user, nil
 }
 
+// defaultRole returns the role given to self-registered users. Admin is never
+// allowed here, whatever the configuration says.
+func (s *authService) defaultRole() string {
+	if s.cfg == nil || s.cfg.Auth.DefaultRole == "" || s.cfg.Auth.DefaultRole == "admin" {
+		return "author"
+	}
+	return s.cfg.Auth.DefaultRole
+}
+
 func (s *authService) Login(req *models.LoginRequest) (*models.AuthResponse, error) {
 	// Get user by email (changed from username to email)
 	user, err := s.userRepo.GetByEmail(req.Email)
@@ -85,10 +201,26 @@ func (s *authService) Login(req *models.LoginRequest) (*models.AuthResponse, err
 		return nil, errors.New("authentication failed")
 	}
 
+	// A locked account refuses even the right password until the lock ends
+	if s.isLocked(user) {
+		return nil, ErrAccountLocked
+	}
+
 	// Verify password using JWT service
-	if !s.jwtService.CheckPassword(req.Password, user.Password) {
+	ok, needsRehash := s.jwtService.VerifyPassword(req.Password, user.Password)
+	if !ok {
+		if s.recordFailedLogin(user) {
+			return nil, ErrAccountLocked
+		}
 		return nil, errors.New("invalid email or password")
 	}
+	s.clearFailedLogins(user)
+
+	// Move hashes made with a retired pepper onto the current one. Failing
+	// to do so is not fatal; the old hash keeps working until the next login.
+	if needsRehash {
+		s.rehashPassword(user, req.Password)
+	}
 
 	// Generate token pair
 	authResponse, err := s.jwtService.GenerateTokenPair(user)
@@ -101,6 +233,21 @@ func (s *authService) Login(req *models.LoginRequest) (*models.AuthResponse, err
 	return authResponse, nil
 }
 
+// rehashPassword replaces the user's stored hash with one made with the
+// current pepper
+func (s *authService) rehashPassword(user *models.User, password string) {
+	hashedPassword, err := s.jwtService.HashPassword(password)
+	if err == nil {
+		user.Password = hashedPassword
+		err = s.userRepo.Update(user)
+	}
+	if err != nil {
+		logger.LogError(context.Background(), "Failed to rehash password", err,
+			zap.Uint("user_id", user.ID),
+		)
+	}
+}
+
 func (s *authService) RefreshToken(req *models.RefreshTokenRequest) (*models.RefreshTokenResponse, error) {
 	refreshResponse, err := s.jwtService.RefreshAccessToken(req.RefreshToken)
 	if err != nil {
@@ -110,18 +257,21 @@ func (s *authService) RefreshToken(req *models.RefreshTokenRequest) (*models.Ref
 	return refreshResponse, nil
 }
 
-func (s *authService) Logout(userID uint, refreshToken string) error {
+func (s *authService) Logout(userID uint, refreshToken string, accessClaims *models.JWTClaims) error {
 	if refreshToken != "" {
 		err := s.jwtService.RevokeRefreshToken(refreshToken)
 		if err != nil {
 			// Log error but don't fail logout
 		}
 	}
-	return nil
+	return s.jwtService.RevokeAccessToken(accessClaims)
 }
 
-func (s *authService) LogoutAll(userID uint) error {
-	return s.jwtService.RevokeAllUserTokens(userID)
+func (s *authService) LogoutAll(userID uint, accessClaims *models.JWTClaims) error {
+	if err := s.jwtService.RevokeAllUserTokens(userID); err != nil {
+		return err
+	}
+	return s.jwtService.RevokeAccessToken(accessClaims)
 }
 
 func (s *authService) ChangePassword(userID uint, req *models.ChangePasswordRequest) error {
@@ -189,18 +339,31 @@ func (s *authService) UpdateProfile(userID uint, req *models.UpdateProfileReques
 		}
 		user.Username = *req.Username
 	}
-	if req.Email != nil {
+	emailChanged := false
+	if req.Email != nil && *req.Email != user.Email {
 		// Check if email is already taken by another user
 		existingUser, err := s.userRepo.GetByEmail(*req.Email)
 		if err == nil && existingUser.ID != userID {
 			return nil, errors.New("email already exists")
 		}
+		// The new address has to be confirmed like the one registered with
 		user.Email = *req.Email
+		user.EmailVerified = false
+		if err := issueVerificationToken(user); err != nil {
+			return nil, err
+		}
+		emailChanged = true
+	}
+	if req.NotifyOnComment != nil {
+		user.NotifyOnComment = *req.NotifyOnComment
 	}
 
 	if err := s.userRepo.Update(user); err != nil {
 		return nil, errors.New("failed to update profile")
 	}
+	if emailChanged {
+		s.sendVerificationEmail(user)
+	}
 
 	// Remove password from response
 	user.Password = ""
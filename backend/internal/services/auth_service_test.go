@@ -104,11 +104,12 @@ func TestAuthService_Register(t *testing.T) {
 	cfg := &config.Config{
 		Environment: "test",
 	}
-	authService := NewAuthService(mockUserRepo, mockJWTService, cfg)
+	authService := NewAuthService(mockUserRepo, nil, mockJWTService, cfg)
 
 	t.Run("successful registration", func(t *testing.T) {
 		// Given
 		registerData := &models.RegisterRequest{
+			Username: "testuser",
 			Name:     "Test User",
 			Email:    "test@example.com",
 			Password: "password123",
@@ -153,6 +154,7 @@ func TestAuthService_Register(t *testing.T) {
 	t.Run("email already exists", func(t *testing.T) {
 		// Given
 		registerData := &models.RegisterRequest{
+			Username: "existinguser",
 			Name:     "Test User",
 			Email:    "existing@example.com",
 			Password: "password123",
@@ -186,7 +188,7 @@ func TestAuthService_Login(t *testing.T) {
 	cfg := &config.Config{
 		Environment: "test",
 	}
-	authService := NewAuthService(mockUserRepo, mockJWTService, cfg)
+	authService := NewAuthService(mockUserRepo, nil, mockJWTService, cfg)
 
 	t.Run("successful login", func(t *testing.T) {
 		// Given
@@ -280,7 +282,7 @@ func TestAuthService_ChangePassword(t *testing.T) {
 	cfg := &config.Config{
 		Environment: "test",
 	}
-	authService := NewAuthService(mockUserRepo, mockJWTService, cfg)
+	authService := NewAuthService(mockUserRepo, nil, mockJWTService, cfg)
 
 	t.Run("successful password change", func(t *testing.T) {
 		// Given
@@ -345,16 +347,17 @@ func TestAuthService_Integration(t *testing.T) {
 
 	// Create real services with test database
 	userRepo := NewUserRepository(db)
-	jwtService := NewJWTService(NewRefreshTokenRepository(db))
+	jwtService := NewJWTService(NewRefreshTokenRepository(db), nil, &config.Config{})
 	cfg := &config.Config{
 		Environment: "test",
 		JWTSecret:   "test-secret",
 	}
-	authService := NewAuthService(userRepo, jwtService, cfg)
+	authService := NewAuthService(userRepo, nil, jwtService, cfg)
 
 	t.Run("full registration and login flow", func(t *testing.T) {
 		// Register a user
 		registerData := &models.RegisterRequest{
+			Username: "integrationuser",
 			Name:     "Integration Test User",
 			Email:    "integration@test.com",
 			Password: "password123",
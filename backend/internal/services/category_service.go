@@ -1,25 +1,63 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
 	"backend/internal/models"
 	"backend/internal/repositories"
+	"backend/pkg/content"
 	"backend/pkg/utils"
 
 	"gorm.io/gorm"
 )
 
 type CategoryService interface {
+	// WithContext returns a copy of the service whose queries run with ctx,
+	// typically the request's, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) CategoryService
 	Create(req *models.CreateCategoryRequest) (*models.Category, error)
+	// CreateBulk creates several categories in one transaction. An item whose
+	// name is taken or whose parent does not exist fails on its own; the
+	// others are still created.
+	CreateBulk(items []models.BulkCategoryItem) (*models.BulkCategoryResponse, error)
 	GetByID(id uint) (*models.Category, error)
 	GetBySlug(slug string) (*models.Category, error)
+	// Update changes the fields set in req. A category cannot be moved under
+	// itself or any of its descendants.
 	Update(id uint, req *models.UpdateCategoryRequest) (*models.Category, error)
-	Delete(id uint) error
+	// Delete removes a category. One with subcategories is only deleted when
+	// reparent is set, which moves them up to the deleted category's parent.
+	Delete(id uint, reparent bool) error
 	List(page, perPage int) ([]models.Category, int64, error)
+	// ListWithCounts is List with each category's live count of published
+	// posts
+	ListWithCounts(page, perPage int) ([]models.Category, int64, error)
 	Search(req *models.CategorySearchRequest) ([]models.Category, int64, error)
+	// GetTree returns every category nested under its parent
+	GetTree() ([]*models.CategoryNode, error)
 }
 
+// maxSlugAttempts bounds how many suffixed slugs Create tries for one name
+const maxSlugAttempts = 10
+
+// ErrCategorySlugConflict is returned when Create cannot find a free slug for
+// the category name
+var ErrCategorySlugConflict = errors.New("a category with this name already exists")
+
+// ErrCategoryParentNotFound is returned for a bulk create item whose parent
+// is neither an existing category nor created earlier in the same request
+var ErrCategoryParentNotFound = errors.New("parent category not found")
+
+// ErrCategoryCycle is returned when an update would move a category under
+// itself or one of its descendants
+var ErrCategoryCycle = errors.New("a category cannot be moved under itself or its subcategories")
+
+// ErrCategoryHasChildren is returned when deleting a category that still has
+// subcategories without asking for them to be reparented
+var ErrCategoryHasChildren = errors.New("category has subcategories")
+
 type categoryService struct {
 	categoryRepo repositories.CategoryRepository
 }
@@ -30,20 +68,134 @@ func NewCategoryService(categoryRepo repositories.CategoryRepository) CategorySe
 	}
 }
 
+func (s *categoryService) WithContext(ctx context.Context) CategoryService {
+	return &categoryService{categoryRepo: s.categoryRepo.WithContext(ctx)}
+}
+
+// Create stores a category under the slug generated from its name. When that
+// slug is taken it tries "slug-2", "slug-3" and so on. The unique index is the
+// final arbiter: if a concurrent create claims a slug between the lookup and
+// the insert, the insert fails as a duplicate and the next suffix is tried.
 func (s *categoryService) Create(req *models.CreateCategoryRequest) (*models.Category, error) {
-	// Generate slug from name
-	slug := utils.GenerateSlug(req.Name)
+	if req.ParentID != nil {
+		if _, err := s.categoryRepo.GetByID(*req.ParentID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrCategoryParentNotFound
+			}
+			return nil, err
+		}
+	}
+
+	baseSlug := content.Slug(req.Name)
+
+	for attempt := 1; attempt <= maxSlugAttempts; attempt++ {
+		slug := baseSlug
+		if attempt > 1 {
+			slug = fmt.Sprintf("%s-%d", baseSlug, attempt)
+		}
+
+		if _, err := s.categoryRepo.GetBySlug(slug); err == nil {
+			continue
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		category := &models.Category{
+			Name:        req.Name,
+			Slug:        slug,
+			Description: req.Description,
+			ParentID:    req.ParentID,
+		}
+
+		err := s.categoryRepo.Create(category)
+		if err == nil {
+			return category, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, err
+		}
+	}
+
+	return nil, ErrCategorySlugConflict
+}
+
+func (s *categoryService) CreateBulk(items []models.BulkCategoryItem) (*models.BulkCategoryResponse, error) {
+	response := &models.BulkCategoryResponse{Results: make([]models.BulkCategoryResult, 0, len(items))}
+
+	err := s.categoryRepo.WithTransaction(func(repo repositories.CategoryRepository) error {
+		// Slugs of the categories created so far, so later items can name
+		// them as parents and cannot create them twice
+		created := make(map[string]uint, len(items))
+
+		for i, item := range items {
+			result := models.BulkCategoryResult{Index: i, Name: item.Name}
+			category, err := createBulkCategory(repo, item, created)
+			switch {
+			case err == nil:
+				created[category.Slug] = category.ID
+				result.Status = models.BulkItemCreated
+				result.Category = category
+				response.Created++
+			case errors.Is(err, ErrCategorySlugConflict), errors.Is(err, ErrCategoryParentNotFound):
+				result.Status = models.BulkItemFailed
+				result.Error = err.Error()
+				response.Failed++
+			default:
+				return err
+			}
+			response.Results = append(response.Results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// createBulkCategory creates one item of a bulk create. Unlike Create it does
+// not suffix a taken slug: a name that is already in use is reported as
+// ErrCategorySlugConflict, as it is most likely the same category.
+func createBulkCategory(repo repositories.CategoryRepository, item models.BulkCategoryItem, created map[string]uint) (*models.Category, error) {
+	slug := content.Slug(item.Name)
+	if _, ok := created[slug]; ok {
+		return nil, ErrCategorySlugConflict
+	}
+	if _, err := repo.GetBySlug(slug); err == nil {
+		return nil, ErrCategorySlugConflict
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
 
 	category := &models.Category{
-		Name:        req.Name,
+		Name:        item.Name,
 		Slug:        slug,
-		Description: req.Description,
+		Description: item.Description,
 	}
 
-	if err := s.categoryRepo.Create(category); err != nil {
-		return nil, err
+	if item.Parent != "" {
+		parentSlug := content.Slug(item.Parent)
+		if parentID, ok := created[parentSlug]; ok {
+			category.ParentID = &parentID
+		} else {
+			parent, err := repo.GetBySlug(parentSlug)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrCategoryParentNotFound
+			}
+			if err != nil {
+				return nil, err
+			}
+			category.ParentID = &parent.ID
+		}
 	}
 
+	if err := repo.Create(category); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrCategorySlugConflict
+		}
+		return nil, err
+	}
 	return category, nil
 }
 
@@ -52,7 +204,7 @@ func (s *categoryService) GetByID(id uint) (*models.Category, error) {
 }
 
 func (s *categoryService) GetBySlug(slug string) (*models.Category, error) {
-	return s.categoryRepo.GetBySlug(slug)
+	return s.categoryRepo.GetBySlug(utils.NormalizeSlug(slug))
 }
 
 func (s *categoryService) Update(id uint, req *models.UpdateCategoryRequest) (*models.Category, error) {
@@ -68,11 +220,16 @@ func (s *categoryService) Update(id uint, req *models.UpdateCategoryRequest) (*m
 	// Update fields if provided
 	if req.Name != "" {
 		category.Name = req.Name
-		category.Slug = utils.GenerateSlug(req.Name)
+		category.Slug = content.Slug(req.Name)
 	}
 	if req.Description != "" {
 		category.Description = req.Description
 	}
+	if req.ParentID != nil {
+		if err := s.setParent(category, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.categoryRepo.Update(category); err != nil {
 		return nil, err
@@ -81,22 +238,82 @@ func (s *categoryService) Update(id uint, req *models.UpdateCategoryRequest) (*m
 	return category, nil
 }
 
-func (s *categoryService) Delete(id uint) error {
+// setParent moves category under parentID, or to the top level for 0. The
+// new parent's ancestors are walked to make sure category is not among them.
+func (s *categoryService) setParent(category *models.Category, parentID uint) error {
+	if parentID == 0 {
+		category.ParentID = nil
+		return nil
+	}
+
+	// visited stops the walk should the stored parents already loop
+	visited := map[uint]bool{}
+	for ancestorID := parentID; !visited[ancestorID]; {
+		if ancestorID == category.ID {
+			return ErrCategoryCycle
+		}
+		visited[ancestorID] = true
+
+		ancestor, err := s.categoryRepo.GetByID(ancestorID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if ancestorID == parentID {
+				return ErrCategoryParentNotFound
+			}
+			// A deleted ancestor ends the chain
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if ancestor.ParentID == nil {
+			break
+		}
+		ancestorID = *ancestor.ParentID
+	}
+
+	category.ParentID = &parentID
+	return nil
+}
+
+func (s *categoryService) Delete(id uint, reparent bool) error {
 	// Check if category exists
-	if _, err := s.categoryRepo.GetByID(id); err != nil {
+	category, err := s.categoryRepo.GetByID(id)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("category not found")
 		}
 		return err
 	}
 
-	return s.categoryRepo.Delete(id)
+	return s.categoryRepo.WithTransaction(func(repo repositories.CategoryRepository) error {
+		children, err := repo.CountChildren(id)
+		if err != nil {
+			return err
+		}
+		if children > 0 {
+			if !reparent {
+				return ErrCategoryHasChildren
+			}
+			if err := repo.ReparentChildren(id, category.ParentID); err != nil {
+				return err
+			}
+		}
+		return repo.Delete(id)
+	})
 }
 
 func (s *categoryService) List(page, perPage int) ([]models.Category, int64, error) {
 	return s.categoryRepo.List(page, perPage)
 }
 
+func (s *categoryService) ListWithCounts(page, perPage int) ([]models.Category, int64, error) {
+	return s.categoryRepo.ListWithCounts(page, perPage)
+}
+
 func (s *categoryService) Search(req *models.CategorySearchRequest) ([]models.Category, int64, error) {
 	return s.categoryRepo.Search(req)
 }
+
+func (s *categoryService) GetTree() ([]*models.CategoryNode, error) {
+	return s.categoryRepo.GetTree()
+}
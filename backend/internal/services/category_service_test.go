@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryService_CreateSlug(t *testing.T) {
+	request := &models.CreateCategoryRequest{Name: "Go Tips"}
+
+	t.Run("uses the slug generated from the name", func(t *testing.T) {
+		service := NewCategoryService(newFakeCategoryRepo())
+
+		category, err := service.Create(request)
+
+		require.NoError(t, err)
+		assert.Equal(t, "go-tips", category.Slug)
+	})
+
+	t.Run("suffixes a slug that is already taken", func(t *testing.T) {
+		service := NewCategoryService(newFakeCategoryRepo(
+			&models.Category{ID: 1, Name: "Go Tips", Slug: "go-tips"},
+			&models.Category{ID: 2, Name: "Go Tips", Slug: "go-tips-2"},
+		))
+
+		category, err := service.Create(request)
+
+		require.NoError(t, err)
+		assert.Equal(t, "go-tips-3", category.Slug)
+	})
+
+	t.Run("retries when a concurrent create wins the slug", func(t *testing.T) {
+		repo := newFakeCategoryRepo()
+		// The competing request inserts after our lookup but before our insert,
+		// so the first insert hits the unique index
+		raced := false
+		repo.beforeCreate = func() {
+			if !raced {
+				raced = true
+				repo.categories[100] = &models.Category{ID: 100, Name: "Go Tips", Slug: "go-tips"}
+			}
+		}
+		service := NewCategoryService(repo)
+
+		category, err := service.Create(request)
+
+		require.NoError(t, err)
+		assert.Equal(t, "go-tips-2", category.Slug)
+		assert.Len(t, repo.categories, 2)
+	})
+
+	t.Run("reports a conflict once every suffix is taken", func(t *testing.T) {
+		repo := newFakeCategoryRepo()
+		for i := 1; i <= maxSlugAttempts; i++ {
+			slug := "go-tips"
+			if i > 1 {
+				slug = fmt.Sprintf("go-tips-%d", i)
+			}
+			repo.categories[uint(i)] = &models.Category{ID: uint(i), Name: "Go Tips", Slug: slug}
+		}
+		service := NewCategoryService(repo)
+
+		category, err := service.Create(request)
+
+		assert.Nil(t, category)
+		assert.ErrorIs(t, err, ErrCategorySlugConflict)
+	})
+}
+
+func TestCategoryService_CreateBulk(t *testing.T) {
+	t.Run("creates valid items and reports duplicates", func(t *testing.T) {
+		repo := newFakeCategoryRepo(&models.Category{ID: 1, Name: "Go", Slug: "go"})
+		service := NewCategoryService(repo)
+
+		response, err := service.CreateBulk([]models.BulkCategoryItem{
+			{Name: "Rust", Description: "Systems programming"},
+			{Name: "Go"},
+			{Name: "Python"},
+			{Name: "rust"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.Created)
+		assert.Equal(t, 2, response.Failed)
+		require.Len(t, response.Results, 4)
+
+		statuses := make([]string, len(response.Results))
+		for i, result := range response.Results {
+			assert.Equal(t, i, result.Index)
+			statuses[i] = result.Status
+		}
+		assert.Equal(t, []string{models.BulkItemCreated, models.BulkItemFailed, models.BulkItemCreated, models.BulkItemFailed}, statuses)
+		assert.Equal(t, ErrCategorySlugConflict.Error(), response.Results[1].Error)
+		assert.Equal(t, ErrCategorySlugConflict.Error(), response.Results[3].Error)
+
+		rust, err := repo.GetBySlug("rust")
+		require.NoError(t, err)
+		assert.Equal(t, "Systems programming", rust.Description)
+		assert.Equal(t, response.Results[0].Category.ID, rust.ID)
+		_, err = repo.GetBySlug("python")
+		assert.NoError(t, err)
+		assert.Len(t, repo.categories, 3)
+	})
+
+	t.Run("resolves parents from the store and the same request", func(t *testing.T) {
+		repo := newFakeCategoryRepo(&models.Category{ID: 1, Name: "Programming", Slug: "programming"})
+		service := NewCategoryService(repo)
+
+		response, err := service.CreateBulk([]models.BulkCategoryItem{
+			{Name: "Go", Parent: "Programming"},
+			{Name: "Go Tips", Parent: "go"},
+			{Name: "Cooking", Parent: "Recipes"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, response.Results, 3)
+		goCategory := response.Results[0].Category
+		require.NotNil(t, goCategory)
+		assert.Equal(t, uintPtr(1), goCategory.ParentID)
+		require.NotNil(t, response.Results[1].Category)
+		assert.Equal(t, uintPtr(goCategory.ID), response.Results[1].Category.ParentID)
+		assert.Equal(t, models.BulkItemFailed, response.Results[2].Status)
+		assert.Equal(t, ErrCategoryParentNotFound.Error(), response.Results[2].Error)
+	})
+}
+
+func TestCategoryService_GetBySlugIgnoresCase(t *testing.T) {
+	service := NewCategoryService(newFakeCategoryRepo(&models.Category{ID: 1, Name: "Go Tips", Slug: "go-tips"}))
+
+	category, err := service.GetBySlug("Go-Tips")
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), category.ID)
+}
+
+// newCategoryHierarchy stores Programming > Go > Generics alongside a
+// top-level Design category
+func newCategoryHierarchy() (CategoryService, *fakeCategoryRepo) {
+	programming, golang := uint(1), uint(2)
+	repo := newFakeCategoryRepo(
+		&models.Category{ID: 1, Name: "Programming", Slug: "programming"},
+		&models.Category{ID: 2, Name: "Go", Slug: "go", ParentID: &programming},
+		&models.Category{ID: 3, Name: "Generics", Slug: "generics", ParentID: &golang},
+		&models.Category{ID: 4, Name: "Design", Slug: "design"},
+	)
+	return NewCategoryService(repo), repo
+}
+
+func TestCategoryService_Hierarchy(t *testing.T) {
+	moveUnder := func(service CategoryService, id, parentID uint) (*models.Category, error) {
+		return service.Update(id, &models.UpdateCategoryRequest{ParentID: &parentID})
+	}
+
+	t.Run("a category cannot be its own parent", func(t *testing.T) {
+		service, _ := newCategoryHierarchy()
+
+		_, err := moveUnder(service, 2, 2)
+
+		assert.ErrorIs(t, err, ErrCategoryCycle)
+	})
+
+	t.Run("a category cannot move under one of its descendants", func(t *testing.T) {
+		service, repo := newCategoryHierarchy()
+
+		_, err := moveUnder(service, 1, 3)
+
+		assert.ErrorIs(t, err, ErrCategoryCycle)
+		assert.Nil(t, repo.categories[1].ParentID)
+	})
+
+	t.Run("a category can move to another branch or the top level", func(t *testing.T) {
+		service, repo := newCategoryHierarchy()
+
+		category, err := moveUnder(service, 2, 4)
+		require.NoError(t, err)
+		require.NotNil(t, category.ParentID)
+		assert.Equal(t, uint(4), *repo.categories[2].ParentID)
+
+		_, err = moveUnder(service, 2, 0)
+		require.NoError(t, err)
+		assert.Nil(t, repo.categories[2].ParentID)
+	})
+
+	t.Run("the parent must exist", func(t *testing.T) {
+		service, _ := newCategoryHierarchy()
+
+		_, err := moveUnder(service, 2, 99)
+		assert.ErrorIs(t, err, ErrCategoryParentNotFound)
+
+		missing := uint(99)
+		_, err = service.Create(&models.CreateCategoryRequest{Name: "Rust", ParentID: &missing})
+		assert.ErrorIs(t, err, ErrCategoryParentNotFound)
+	})
+
+	t.Run("the tree nests categories under their parents by name", func(t *testing.T) {
+		service, _ := newCategoryHierarchy()
+		programming := uint(1)
+		_, err := service.Create(&models.CreateCategoryRequest{Name: "Databases", ParentID: &programming})
+		require.NoError(t, err)
+
+		tree, err := service.GetTree()
+
+		require.NoError(t, err)
+		require.Len(t, tree, 2)
+		assert.Equal(t, "Design", tree[0].Name)
+		assert.Empty(t, tree[0].Children)
+		assert.Equal(t, "Programming", tree[1].Name)
+		require.Len(t, tree[1].Children, 2)
+		assert.Equal(t, "Databases", tree[1].Children[0].Name)
+		assert.Equal(t, "Go", tree[1].Children[1].Name)
+		require.Len(t, tree[1].Children[1].Children, 1)
+		assert.Equal(t, "Generics", tree[1].Children[1].Children[0].Name)
+	})
+
+	t.Run("deleting a parent is blocked unless its children are reparented", func(t *testing.T) {
+		service, repo := newCategoryHierarchy()
+
+		assert.ErrorIs(t, service.Delete(2, false), ErrCategoryHasChildren)
+		require.Contains(t, repo.categories, uint(2))
+
+		require.NoError(t, service.Delete(2, true))
+		assert.NotContains(t, repo.categories, uint(2))
+		require.NotNil(t, repo.categories[3].ParentID)
+		assert.Equal(t, uint(1), *repo.categories[3].ParentID)
+	})
+
+	t.Run("a category without children is deleted", func(t *testing.T) {
+		service, repo := newCategoryHierarchy()
+
+		require.NoError(t, service.Delete(4, false))
+		assert.NotContains(t, repo.categories, uint(4))
+	})
+}
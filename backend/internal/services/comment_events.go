@@ -0,0 +1,19 @@
+package services
+
+import "backend/internal/models"
+
+// Comment lifecycle events published on the event bus
+const (
+	EventCommentApproved = "comment.approved"
+)
+
+// CommentEvent describes a change to a comment
+type CommentEvent struct {
+	Type    string
+	Comment models.Comment
+}
+
+// Name implements events.Event
+func (e CommentEvent) Name() string {
+	return e.Type
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLimitedCommentService() (CommentService, *fakeCommentRepo) {
+	postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Limited post", Status: "published"})
+	commentRepo := newFakeCommentRepo()
+	cfg := &config.Config{Comment: config.CommentConfig{
+		MaxLength:       100,
+		MaxLengthByRole: map[string]int{"editor": 500},
+		MaxLinks:        2,
+	}}
+	return NewCommentService(commentRepo, postRepo, nil, cfg, nil), commentRepo
+}
+
+func TestCommentService_ContentLimits(t *testing.T) {
+	create := func(service CommentService, content, role string) error {
+		_, err := service.Create(&models.CreateCommentRequest{PostID: 1, Content: content}, 2, role)
+		return err
+	}
+
+	t.Run("editors may write longer comments than authors", func(t *testing.T) {
+		service, commentRepo := newLimitedCommentService()
+		long := strings.Repeat("a", 300)
+
+		err := create(service, long, "author")
+		assert.ErrorIs(t, err, ErrCommentTooLong)
+		assert.Contains(t, err.Error(), "the limit is 100")
+		assert.Empty(t, commentRepo.comments)
+
+		require.NoError(t, create(service, long, "editor"))
+		assert.ErrorIs(t, create(service, strings.Repeat("a", 501), "editor"), ErrCommentTooLong)
+	})
+
+	t.Run("length is counted in characters, not bytes", func(t *testing.T) {
+		service, _ := newLimitedCommentService()
+
+		assert.NoError(t, create(service, strings.Repeat("é", 100), "author"))
+	})
+
+	t.Run("comments over the link cap are rejected", func(t *testing.T) {
+		service, commentRepo := newLimitedCommentService()
+
+		err := create(service, "Deals at https://a.example and http://b.example and www.c.example", "author")
+
+		assert.ErrorIs(t, err, ErrCommentTooManyLinks)
+		assert.Empty(t, commentRepo.comments)
+	})
+
+	t.Run("links up to the cap are allowed and counted once each", func(t *testing.T) {
+		service, _ := newLimitedCommentService()
+
+		assert.NoError(t, create(service, "See https://www.example.com and www.example.org", "author"))
+	})
+
+	t.Run("edits are held to the same limits", func(t *testing.T) {
+		service, _ := newLimitedCommentService()
+		comment, err := service.Create(&models.CreateCommentRequest{PostID: 1, Content: "Short and sweet"}, 2, "author")
+		require.NoError(t, err)
+
+		_, err = service.Update(comment.ID, &models.UpdateCommentRequest{Content: stringPtr(strings.Repeat("a", 101))}, 2, "author")
+		assert.ErrorIs(t, err, ErrCommentTooLong)
+
+		stored, err := service.GetByID(comment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Short and sweet", stored.Content)
+	})
+}
+
+func TestCommentService_Preview(t *testing.T) {
+	t.Run("markdown renders to HTML and nothing is stored", func(t *testing.T) {
+		service, commentRepo := newLimitedCommentService()
+
+		html, err := service.Preview("Nice **post**, see [docs](https://example.com)", "author")
+
+		require.NoError(t, err)
+		assert.Contains(t, html, "<strong>post</strong>")
+		assert.Contains(t, html, `<a href="https://example.com" rel="nofollow">docs</a>`)
+		assert.Empty(t, commentRepo.comments)
+	})
+
+	t.Run("scripts are stripped", func(t *testing.T) {
+		service, _ := newLimitedCommentService()
+
+		html, err := service.Preview(`Hello <script>alert("x")</script><a href="javascript:alert(1)">click</a>`, "author")
+
+		require.NoError(t, err)
+		assert.NotContains(t, html, "<script")
+		assert.NotContains(t, html, "javascript:")
+		assert.Contains(t, html, "Hello")
+	})
+
+	t.Run("over-length comments are rejected before rendering", func(t *testing.T) {
+		service, _ := newLimitedCommentService()
+
+		html, err := service.Preview("**"+strings.Repeat("a", 101)+"**", "author")
+
+		assert.ErrorIs(t, err, ErrCommentTooLong)
+		assert.Empty(t, html)
+	})
+
+	t.Run("the link cap applies", func(t *testing.T) {
+		service, _ := newLimitedCommentService()
+
+		_, err := service.Preview("https://a.example http://b.example www.c.example", "author")
+
+		assert.ErrorIs(t, err, ErrCommentTooManyLinks)
+	})
+}
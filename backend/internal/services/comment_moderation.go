@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func (s *commentService) ListPending(page, perPage int) ([]models.Comment, int64, error) {
+	return s.commentRepo.ListPending(page, perPage)
+}
+
+func (s *commentService) Approve(id, moderatorID uint) (*models.Comment, error) {
+	return s.moderate(id, "approved", moderatorID)
+}
+
+func (s *commentService) Reject(id, moderatorID uint) (*models.Comment, error) {
+	return s.moderate(id, "rejected", moderatorID)
+}
+
+func (s *commentService) moderate(id uint, status string, moderatorID uint) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	previousStatus := comment.Status
+
+	if err := s.commentRepo.Moderate(id, status, moderatorID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	moderated, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if status == "approved" && previousStatus != "approved" {
+		s.bus.Publish(context.Background(), CommentEvent{Type: EventCommentApproved, Comment: *moderated})
+	}
+	return moderated, nil
+}
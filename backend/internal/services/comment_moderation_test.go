@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/pkg/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newModerationService seeds post 1 with an approved comment and two pending
+// ones, the older of them created second
+func newModerationService(bus *events.Bus) (CommentService, *fakeCommentRepo) {
+	now := time.Now()
+	commentRepo := newFakeCommentRepo(
+		&models.Comment{ID: 1, PostID: 1, UserID: 2, Content: "Approved", Status: "approved", CreatedAt: now.Add(-3 * time.Hour)},
+		&models.Comment{ID: 2, PostID: 1, UserID: 3, Content: "Newer", Status: "pending", CreatedAt: now.Add(-time.Hour)},
+		&models.Comment{ID: 3, PostID: 1, UserID: 3, Content: "Older", Status: "pending", CreatedAt: now.Add(-2 * time.Hour)},
+	)
+	postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Moderated post", Status: "published"})
+	return NewCommentService(commentRepo, postRepo, nil, &config.Config{}, bus), commentRepo
+}
+
+func TestCommentService_ListPending(t *testing.T) {
+	service, _ := newModerationService(nil)
+
+	comments, total, err := service.ListPending(1, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []uint{3, 2}, commentIDs(comments))
+}
+
+func TestCommentService_Moderate(t *testing.T) {
+	t.Run("approving records the moderator and leaves the queue", func(t *testing.T) {
+		bus := events.NewBus()
+		var approved []CommentEvent
+		bus.Subscribe(EventCommentApproved, func(ctx context.Context, event events.Event) {
+			approved = append(approved, event.(CommentEvent))
+		})
+		service, _ := newModerationService(bus)
+
+		comment, err := service.Approve(3, 9)
+
+		require.NoError(t, err)
+		assert.Equal(t, "approved", comment.Status)
+		require.NotNil(t, comment.ModeratedBy)
+		assert.Equal(t, uint(9), *comment.ModeratedBy)
+		assert.NotNil(t, comment.ModeratedAt)
+		require.Len(t, approved, 1)
+		assert.Equal(t, uint(3), approved[0].Comment.ID)
+
+		pending, _, err := service.ListPending(1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{2}, commentIDs(pending))
+	})
+
+	t.Run("rejecting hides the comment from the post", func(t *testing.T) {
+		service, _ := newModerationService(nil)
+
+		comment, err := service.Reject(1, 9)
+		require.NoError(t, err)
+		assert.Equal(t, "rejected", comment.Status)
+		require.NotNil(t, comment.ModeratedBy)
+
+		comments, total, err := service.GetByPost(1, 1, 10, 0, "")
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, comments)
+
+		comments, _, err = service.GetByPostWithStatus(1, "rejected", 1, 10, 0, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, []uint{1}, commentIDs(comments), "admins still see it")
+	})
+
+	t.Run("an unknown comment is not found", func(t *testing.T) {
+		service, _ := newModerationService(nil)
+
+		_, err := service.Approve(99, 9)
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+		_, err = service.Reject(99, 9)
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+}
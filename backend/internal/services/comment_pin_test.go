@@ -0,0 +1,114 @@
+package services
+
+import (
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPinningService seeds post 1 by author 1 with three comments from user 2,
+// and post 2 by author 3 with one comment
+func newPinningService() (CommentService, *fakeCommentRepo) {
+	postRepo := newFakePostRepo(
+		&models.Post{ID: 1, Title: "First", AuthorID: 1, Status: "published"},
+		&models.Post{ID: 2, Title: "Second", AuthorID: 3, Status: "published"},
+	)
+	commentRepo := newFakeCommentRepo(
+		&models.Comment{ID: 1, PostID: 1, UserID: 2, Content: "First!", Status: "approved"},
+		&models.Comment{ID: 2, PostID: 1, UserID: 2, Content: "Great question", Status: "approved"},
+		&models.Comment{ID: 3, PostID: 1, UserID: 2, Content: "The best answer", Status: "approved"},
+		&models.Comment{ID: 4, PostID: 2, UserID: 2, Content: "Elsewhere", Status: "approved"},
+	)
+	return NewCommentService(commentRepo, postRepo, nil, &config.Config{}, nil), commentRepo
+}
+
+func commentIDs(comments []models.Comment) []uint {
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	return ids
+}
+
+func TestCommentService_Pin(t *testing.T) {
+	t.Run("pinning surfaces the comment to the top", func(t *testing.T) {
+		service, _ := newPinningService()
+
+		pinned, err := service.Pin(3, 1, "author")
+		require.NoError(t, err)
+		assert.True(t, pinned.Pinned)
+
+		comments, _, err := service.GetByPost(1, 1, 10, 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, []uint{3, 1, 2}, commentIDs(comments))
+
+		thread, err := service.GetThread(1, "approved", 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, uint(3), thread[0].ID)
+	})
+
+	t.Run("only one comment per post stays pinned", func(t *testing.T) {
+		service, repo := newPinningService()
+		_, err := service.Pin(4, 3, "author")
+		require.NoError(t, err)
+
+		_, err = service.Pin(2, 1, "author")
+		require.NoError(t, err)
+		_, err = service.Pin(3, 1, "author")
+		require.NoError(t, err)
+
+		assert.False(t, repo.comments[2].Pinned)
+		assert.True(t, repo.comments[3].Pinned)
+		// Pins on other posts are untouched
+		assert.True(t, repo.comments[4].Pinned)
+	})
+
+	t.Run("unpinning restores the normal order", func(t *testing.T) {
+		service, _ := newPinningService()
+		_, err := service.Pin(3, 1, "author")
+		require.NoError(t, err)
+
+		unpinned, err := service.Unpin(3, 1, "author")
+		require.NoError(t, err)
+		assert.False(t, unpinned.Pinned)
+
+		comments, _, err := service.GetByPost(1, 1, 10, 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, []uint{1, 2, 3}, commentIDs(comments))
+	})
+
+	t.Run("admins can pin on any post", func(t *testing.T) {
+		service, _ := newPinningService()
+
+		_, err := service.Pin(4, 99, "admin")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("non-owners cannot pin or unpin", func(t *testing.T) {
+		service, repo := newPinningService()
+
+		// The commenter is not the post's author
+		_, err := service.Pin(3, 2, "author")
+		assert.ErrorIs(t, err, ErrCommentPinForbidden)
+
+		_, err = service.Pin(4, 1, "author")
+		assert.ErrorIs(t, err, ErrCommentPinForbidden)
+		assert.False(t, repo.comments[4].Pinned)
+
+		_, err = service.Unpin(3, 2, "author")
+		assert.ErrorIs(t, err, ErrCommentPinForbidden)
+	})
+
+	t.Run("missing comments are reported", func(t *testing.T) {
+		service, _ := newPinningService()
+
+		_, err := service.Pin(42, 1, "author")
+
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+}
@@ -1,54 +1,308 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/repositories"
+	"backend/pkg/content"
+	"backend/pkg/events"
 
 	"gorm.io/gorm"
 )
 
 type CommentService interface {
-	Create(req *models.CreateCommentRequest, userID uint) (*models.Comment, error)
+	// WithContext returns a copy of the service whose queries run with ctx,
+	// typically the request's, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) CommentService
+	Create(req *models.CreateCommentRequest, userID uint, userRole string) (*models.Comment, error)
 	GetByID(id uint) (*models.Comment, error)
 	Update(id uint, req *models.UpdateCommentRequest, userID uint, userRole string) (*models.Comment, error)
 	Delete(id uint, userID uint, userRole string) error
-	List(page, perPage int, filters map[string]interface{}) ([]models.Comment, int64, error)
-	GetByPost(postID uint, page, perPage int) ([]models.Comment, int64, error)
-	GetByUser(userID uint, page, perPage int) ([]models.Comment, int64, error)
+	// List returns a page of comments matching filters. When they name a
+	// post_id it reports ErrCommentPostNotFound for posts the viewer may not
+	// see, like GetByPost.
+	List(page, perPage int, filters map[string]interface{}, viewerID uint, viewerRole string) ([]models.Comment, int64, error)
+	// GetByPost lists a post's approved comments, the ones readers may see.
+	// It reports ErrCommentPostNotFound for posts the viewer may not see.
+	GetByPost(postID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Comment, int64, error)
+	// GetByPostWithStatus lists a post's comments with the given status, or
+	// all of them when status is empty. It is meant for admins.
+	GetByPostWithStatus(postID uint, status string, page, perPage int, viewerID uint, viewerRole string) ([]models.Comment, int64, error)
+	// GetByUser lists a user's comments with the given status, or all of
+	// them when status is empty
+	GetByUser(userID uint, status string, page, perPage int) ([]models.Comment, int64, error)
+	// GetThread returns a post's comments with the given status, or all of
+	// them when status is empty, nested into reply trees. Replies to comments
+	// left out are left out with them. Like GetCommentTree it reports
+	// ErrCommentPostNotFound for posts the viewer may not see.
+	GetThread(postID uint, status string, viewerID uint, viewerRole string) ([]*models.CommentNode, error)
+	// GetCommentTree returns a post's comments nested up to maxDepth levels
+	// below the top-level comments. Anonymous viewers see approved comments
+	// only; signed-in users also see their own, and admins see everything.
+	GetCommentTree(postID uint, maxDepth int, viewerID uint, viewerRole string) (*models.CommentTree, error)
+	// GetRecent returns the newest approved comments on published posts
+	// across the site. The limit is clamped to MaxRecentComments.
+	GetRecent(limit int) ([]models.RecentComment, error)
+	// GetSummaries returns each post's approved comment count and newest
+	// approved comment, in the order the posts were given. Posts the viewer
+	// may not see, and unknown ones, are left out; unpublished posts the
+	// viewer may see report no comments.
+	GetSummaries(postIDs []uint, viewerID uint, viewerRole string) ([]models.PostCommentSummary, error)
+	// Pin shows a comment above the rest of its post's comments. Each post has
+	// at most one pinned comment, so pinning replaces any earlier pin. Only
+	// the post's author and admins may pin or unpin.
+	Pin(id uint, userID uint, userRole string) (*models.Comment, error)
+	Unpin(id uint, userID uint, userRole string) (*models.Comment, error)
+	// Preview renders comment markdown to sanitized HTML without storing
+	// anything. The length and link limits of Create apply, so a comment
+	// that previews can also be posted.
+	Preview(content string, userRole string) (string, error)
+	// ListPending returns the moderation queue: pending comments, oldest
+	// first, with their post and author
+	ListPending(page, perPage int) ([]models.Comment, int64, error)
+	// Approve and Reject set a comment's status, recording moderatorID as the
+	// admin who moderated it. Only approved comments are listed by GetByPost.
+	Approve(id, moderatorID uint) (*models.Comment, error)
+	Reject(id, moderatorID uint) (*models.Comment, error)
 }
 
+// Bounds for the sitewide recent comments feed
+const (
+	DefaultRecentComments = 5
+	MaxRecentComments     = 20
+)
+
+// MaxCommentSummaryPosts caps how many posts GetSummaries covers at once
+const MaxCommentSummaryPosts = 100
+
+// Depth bounds for the comment tree endpoint
+const (
+	DefaultCommentTreeDepth = 5
+	MaxCommentTreeDepth     = 10
+)
+
+// Reply depth policies
+const (
+	CommentDepthReject = "reject"
+	CommentDepthClamp  = "clamp"
+)
+
+var (
+	ErrCommentTooDeep      = errors.New("maximum reply depth exceeded")
+	ErrCommentsClosed      = errors.New("comments closed")
+	ErrCommentNotFound     = errors.New("comment not found")
+	ErrCommentPinForbidden = errors.New("only the post's author or an admin can pin comments")
+	ErrCommentPostNotFound = errors.New("post not found")
+	ErrCommentTooLong      = errors.New("comment is too long")
+	ErrCommentTooManyLinks = errors.New("comment contains too many links")
+)
+
+// commentLinkPattern matches a whole URL, so a www host after a scheme is
+// not counted twice
+var commentLinkPattern = regexp.MustCompile(`(?i)(?:https?://|\bwww\.)\S+`)
+
 type commentService struct {
 	commentRepo repositories.CommentRepository
 	postRepo    repositories.PostRepository
+	userRepo    repositories.UserRepository
+	cfg         *config.Config
+	bus         *events.Bus
+	screener    CommentModerationService
 }
 
-func NewCommentService(commentRepo repositories.CommentRepository, postRepo repositories.PostRepository) CommentService {
+func NewCommentService(commentRepo repositories.CommentRepository, postRepo repositories.PostRepository, userRepo repositories.UserRepository, cfg *config.Config, bus *events.Bus) CommentService {
 	return &commentService{
 		commentRepo: commentRepo,
 		postRepo:    postRepo,
+		userRepo:    userRepo,
+		cfg:         cfg,
+		bus:         bus,
+		screener:    NewCommentModerationService(cfg.Comment),
 	}
 }
 
-func (s *commentService) Create(req *models.CreateCommentRequest, userID uint) (*models.Comment, error) {
+func (s *commentService) WithContext(ctx context.Context) CommentService {
+	scoped := *s
+	scoped.commentRepo = s.commentRepo.WithContext(ctx)
+	if s.postRepo != nil {
+		scoped.postRepo = s.postRepo.WithContext(ctx)
+	}
+	if s.userRepo != nil {
+		scoped.userRepo = s.userRepo.WithContext(ctx)
+	}
+	return &scoped
+}
+
+func (s *commentService) Create(req *models.CreateCommentRequest, userID uint, userRole string) (*models.Comment, error) {
+	if err := s.checkContent(req.Content, userRole); err != nil {
+		return nil, err
+	}
+
 	// Verify post exists
-	if _, err := s.postRepo.GetByID(req.PostID); err != nil {
+	post, err := s.postRepo.GetByID(req.PostID)
+	if err != nil {
 		return nil, errors.New("post not found")
 	}
 
+	// Admins may comment even where comments are closed
+	if userRole != "admin" && s.commentsClosed(post) {
+		return nil, ErrCommentsClosed
+	}
+
 	comment := &models.Comment{
 		PostID:  req.PostID,
 		UserID:  userID,
 		Content: req.Content,
 		Status:  "pending",
 	}
+	if s.trustedAuthor(userID) {
+		comment.Status = "approved"
+	}
+	// Spam is rejected and doubtful content held, trusted domains or not
+	if status := s.screener.Screen(req.Content); status != "" {
+		comment.Status = status
+	}
+
+	// Attach replies to their parent, respecting the configured depth limit
+	if req.ParentID != nil {
+		parent, err := s.commentRepo.GetByID(*req.ParentID)
+		if err != nil {
+			return nil, errors.New("parent comment not found")
+		}
+		if parent.PostID != req.PostID {
+			return nil, errors.New("parent comment belongs to a different post")
+		}
+
+		parent, err = s.resolveParent(parent)
+		if err != nil {
+			return nil, err
+		}
+
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
 
 	if err := s.commentRepo.Create(comment); err != nil {
 		return nil, err
 	}
 
-	return s.commentRepo.GetByID(comment.ID)
+	created, err := s.commentRepo.GetByID(comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Comments approved on arrival skip the moderation queue, so announce
+	// them here as moderate would
+	if created.Status == "approved" {
+		s.bus.Publish(context.Background(), CommentEvent{Type: EventCommentApproved, Comment: *created})
+	}
+	return created, nil
+}
+
+func (s *commentService) Preview(text string, userRole string) (string, error) {
+	if err := s.checkContent(text, userRole); err != nil {
+		return "", err
+	}
+	return content.RenderHTML(text)
+}
+
+// checkContent applies the length limit for the writer's role and the link
+// limit. Both are cheap, so they run before anything is looked up or stored.
+func (s *commentService) checkContent(content, userRole string) error {
+	maxLength := s.cfg.Comment.MaxLength
+	if limit, ok := s.cfg.Comment.MaxLengthByRole[userRole]; ok && limit > 0 {
+		maxLength = limit
+	}
+	if maxLength > 0 {
+		if length := utf8.RuneCountInString(content); length > maxLength {
+			return fmt.Errorf("%w: %d characters, the limit is %d", ErrCommentTooLong, length, maxLength)
+		}
+	}
+
+	if maxLinks := s.cfg.Comment.MaxLinks; maxLinks > 0 {
+		if links := len(commentLinkPattern.FindAllStringIndex(content, -1)); links > maxLinks {
+			return fmt.Errorf("%w: %d links, the limit is %d", ErrCommentTooManyLinks, links, maxLinks)
+		}
+	}
+	return nil
+}
+
+// trustedAuthor reports whether the user's email belongs to one of the
+// configured trusted domains, whose users' comments skip moderation. Anyone
+// can sign up with such an address, so it only counts once verified.
+func (s *commentService) trustedAuthor(userID uint) bool {
+	if len(s.cfg.Comment.TrustedDomains) == 0 {
+		return false
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || !user.EmailVerified {
+		return false
+	}
+
+	email := user.Email
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, trusted := range s.cfg.Comment.TrustedDomains {
+		if strings.EqualFold(domain, trusted) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentsClosed reports whether a post no longer accepts comments, either
+// because its author turned them off or because it was published longer ago
+// than the configured window
+func (s *commentService) commentsClosed(post *models.Post) bool {
+	if !post.CommentsEnabled {
+		return true
+	}
+
+	days := s.cfg.Comment.CloseAfterDays
+	if days <= 0 {
+		return false
+	}
+
+	published := post.CreatedAt
+	if post.PublishedAt != nil {
+		published = *post.PublishedAt
+	}
+	return time.Since(published) > time.Duration(days)*24*time.Hour
+}
+
+// resolveParent returns the comment a reply should attach to. Replies that
+// would exceed the max depth are rejected, or re-parented to the deepest
+// allowed ancestor when the clamp policy is configured.
+func (s *commentService) resolveParent(parent *models.Comment) (*models.Comment, error) {
+	maxDepth := s.cfg.Comment.MaxDepth
+	if maxDepth <= 0 || parent.Depth < maxDepth {
+		return parent, nil
+	}
+
+	if s.cfg.Comment.DepthPolicy != CommentDepthClamp {
+		return nil, ErrCommentTooDeep
+	}
+
+	for parent.Depth >= maxDepth && parent.ParentID != nil {
+		ancestor, err := s.commentRepo.GetByID(*parent.ParentID)
+		if err != nil {
+			return nil, errors.New("parent comment not found")
+		}
+		parent = ancestor
+	}
+
+	return parent, nil
 }
 
 func (s *commentService) GetByID(id uint) (*models.Comment, error) {
@@ -70,20 +324,29 @@ func (s *commentService) Update(id uint, req *models.UpdateCommentRequest, userI
 		return nil, errors.New("you don't have permission to update this comment")
 	}
 
+	previousStatus := comment.Status
+
 	// Update fields if provided
-	if req.Content != "" {
-		comment.Content = req.Content
+	if req.Content != nil {
+		if err := s.checkContent(*req.Content, userRole); err != nil {
+			return nil, err
+		}
+		comment.Content = *req.Content
 	}
 	
 	// Only admins can change status
-	if req.Status != "" && userRole == "admin" {
-		comment.Status = req.Status
+	if req.Status != nil && userRole == "admin" {
+		comment.Status = *req.Status
 	}
 
 	if err := s.commentRepo.Update(comment); err != nil {
 		return nil, err
 	}
 
+	if comment.Status == "approved" && previousStatus != "approved" {
+		s.bus.Publish(context.Background(), CommentEvent{Type: EventCommentApproved, Comment: *comment})
+	}
+
 	return s.commentRepo.GetByID(comment.ID)
 }
 
@@ -105,14 +368,242 @@ func (s *commentService) Delete(id uint, userID uint, userRole string) error {
 	return s.commentRepo.Delete(id)
 }
 
-func (s *commentService) List(page, perPage int, filters map[string]interface{}) ([]models.Comment, int64, error) {
+func (s *commentService) List(page, perPage int, filters map[string]interface{}, viewerID uint, viewerRole string) ([]models.Comment, int64, error) {
+	if postID, ok := filters["post_id"].(uint); ok {
+		if err := s.checkPostVisible(postID, viewerID, viewerRole); err != nil {
+			return nil, 0, err
+		}
+	}
 	return s.commentRepo.List(page, perPage, filters)
 }
 
-func (s *commentService) GetByPost(postID uint, page, perPage int) ([]models.Comment, int64, error) {
-	return s.commentRepo.GetByPost(postID, page, perPage)
+func (s *commentService) GetByPost(postID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Comment, int64, error) {
+	if err := s.checkPostVisible(postID, viewerID, viewerRole); err != nil {
+		return nil, 0, err
+	}
+	return s.commentRepo.GetApprovedByPost(postID, page, perPage)
+}
+
+func (s *commentService) GetByPostWithStatus(postID uint, status string, page, perPage int, viewerID uint, viewerRole string) ([]models.Comment, int64, error) {
+	if err := s.checkPostVisible(postID, viewerID, viewerRole); err != nil {
+		return nil, 0, err
+	}
+	return s.commentRepo.GetByPost(postID, status, page, perPage)
+}
+
+func (s *commentService) GetByUser(userID uint, status string, page, perPage int) ([]models.Comment, int64, error) {
+	return s.commentRepo.GetByUser(userID, status, page, perPage)
+}
+
+func (s *commentService) GetRecent(limit int) ([]models.RecentComment, error) {
+	if limit <= 0 {
+		limit = DefaultRecentComments
+	}
+	if limit > MaxRecentComments {
+		limit = MaxRecentComments
+	}
+	return s.commentRepo.GetRecent(limit)
+}
+
+func (s *commentService) GetSummaries(postIDs []uint, viewerID uint, viewerRole string) ([]models.PostCommentSummary, error) {
+	// Each post is reported once, where it first appears
+	seen := make(map[uint]bool, len(postIDs))
+	unique := make([]uint, 0, len(postIDs))
+	for _, id := range postIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxCommentSummaryPosts {
+		return nil, fmt.Errorf("too many posts requested (max %d)", MaxCommentSummaryPosts)
+	}
+
+	posts, err := s.postRepo.GetByIDs(unique)
+	if err != nil {
+		return nil, err
+	}
+	viewable := make(map[uint]bool, len(posts))
+	for i := range posts {
+		if CanViewPost(&posts[i], viewerID, viewerRole) {
+			viewable[posts[i].ID] = true
+		}
+	}
+	visible := make([]uint, 0, len(viewable))
+	for _, id := range unique {
+		if viewable[id] {
+			visible = append(visible, id)
+		}
+	}
+	if len(visible) == 0 {
+		return []models.PostCommentSummary{}, nil
+	}
+
+	found, err := s.commentRepo.GetSummaries(visible)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]models.PostCommentSummary, len(visible))
+	for i, id := range visible {
+		summary, ok := found[id]
+		if !ok {
+			summary = models.PostCommentSummary{PostID: id}
+		}
+		summaries[i] = summary
+	}
+	return summaries, nil
+}
+
+func (s *commentService) Pin(id uint, userID uint, userRole string) (*models.Comment, error) {
+	comment, err := s.pinnableComment(id, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.Pin(comment.PostID, comment.ID); err != nil {
+		return nil, err
+	}
+
+	return s.commentRepo.GetByID(comment.ID)
+}
+
+func (s *commentService) Unpin(id uint, userID uint, userRole string) (*models.Comment, error) {
+	comment, err := s.pinnableComment(id, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.Unpin(comment.ID); err != nil {
+		return nil, err
+	}
+
+	return s.commentRepo.GetByID(comment.ID)
+}
+
+// pinnableComment loads a comment and checks the user may change its pin:
+// they must be an admin or the author of the post it was left on
+func (s *commentService) pinnableComment(id uint, userID uint, userRole string) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	if userRole == "admin" {
+		return comment, nil
+	}
+
+	post, err := s.postRepo.GetByID(comment.PostID)
+	if err != nil {
+		return nil, err
+	}
+	if post.AuthorID != userID {
+		return nil, ErrCommentPinForbidden
+	}
+
+	return comment, nil
+}
+
+func (s *commentService) GetThread(postID uint, status string, viewerID uint, viewerRole string) ([]*models.CommentNode, error) {
+	if err := s.checkPostVisible(postID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	return s.commentRepo.GetThreaded(postID, status)
 }
 
-func (s *commentService) GetByUser(userID uint, page, perPage int) ([]models.Comment, int64, error) {
-	return s.commentRepo.GetByUser(userID, page, perPage)
+// checkPostVisible returns ErrCommentPostNotFound unless the post exists and
+// the viewer may see it. Unpublished posts and their comments stay hidden
+// from everyone but the author and admins.
+func (s *commentService) checkPostVisible(postID uint, viewerID uint, viewerRole string) error {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCommentPostNotFound
+		}
+		return err
+	}
+	if post.Status != "published" && viewerRole != "admin" && post.AuthorID != viewerID {
+		return ErrCommentPostNotFound
+	}
+	return nil
+}
+
+func (s *commentService) GetCommentTree(postID uint, maxDepth int, viewerID uint, viewerRole string) (*models.CommentTree, error) {
+	if err := s.checkPostVisible(postID, viewerID, viewerRole); err != nil {
+		return nil, err
+	}
+
+	if maxDepth < 0 {
+		maxDepth = 0
+	} else if maxDepth > MaxCommentTreeDepth {
+		maxDepth = MaxCommentTreeDepth
+	}
+
+	comments, err := s.commentRepo.GetTree(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := func(comment *models.Comment) bool {
+		return viewerRole == "admin" ||
+			comment.Status == "approved" ||
+			(viewerID != 0 && comment.UserID == viewerID)
+	}
+	return buildCommentTreeView(comments, maxDepth, visible), nil
+}
+
+// buildCommentTreeView assembles a comment tree in a single pass over
+// comments, which must list parents before their replies. Replies to hidden
+// comments are hidden with them.
+func buildCommentTreeView(comments []models.Comment, maxDepth int, visible func(*models.Comment) bool) *models.CommentTree {
+	tree := &models.CommentTree{MaxDepth: maxDepth, Comments: []*models.CommentTreeNode{}}
+	nodes := make(map[uint]*models.CommentTreeNode, len(comments))
+
+	for i := range comments {
+		comment := &comments[i]
+		if !visible(comment) {
+			continue
+		}
+
+		var parent *models.CommentTreeNode
+		if comment.ParentID != nil {
+			var ok bool
+			if parent, ok = nodes[*comment.ParentID]; !ok {
+				continue
+			}
+		}
+
+		node := &models.CommentTreeNode{
+			ID:        comment.ID,
+			ParentID:  comment.ParentID,
+			Content:   comment.Content,
+			Status:    comment.Status,
+			Pinned:    comment.Pinned,
+			CreatedAt: comment.CreatedAt,
+			Replies:   []*models.CommentTreeNode{},
+		}
+		if comment.User != nil {
+			node.Author = models.CommentAuthor{ID: comment.User.ID, Username: comment.User.Username, Name: comment.User.Name}
+		}
+		nodes[comment.ID] = node
+		tree.Total++
+
+		if parent == nil {
+			tree.Comments = append(tree.Comments, node)
+			continue
+		}
+
+		node.Depth = parent.Depth + 1
+		parent.ReplyCount++
+		if node.Depth <= maxDepth {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+
+	sort.SliceStable(tree.Comments, func(i, j int) bool { return tree.Comments[i].Pinned && !tree.Comments[j].Pinned })
+	return tree
 }
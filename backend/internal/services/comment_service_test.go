@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/pkg/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newThreadedCommentService(maxDepth int, policy string) (CommentService, *fakeCommentRepo) {
+	postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Threaded post", Status: "published"})
+	commentRepo := newFakeCommentRepo()
+	cfg := &config.Config{Comment: config.CommentConfig{MaxDepth: maxDepth, DepthPolicy: policy}}
+	return NewCommentService(commentRepo, postRepo, nil, cfg, nil), commentRepo
+}
+
+// seedChain creates a top-level comment followed by replies, each nested under
+// the previous one, and returns them in creation order
+func seedChain(t *testing.T, service CommentService, length int) []*models.Comment {
+	var chain []*models.Comment
+	var parentID *uint
+	for i := 0; i < length; i++ {
+		comment, err := service.Create(&models.CreateCommentRequest{
+			PostID:   1,
+			ParentID: parentID,
+			Content:  "A reply in the chain",
+		}, 2, "author")
+		require.NoError(t, err)
+		chain = append(chain, comment)
+		parentID = uintPtr(comment.ID)
+	}
+	return chain
+}
+
+func TestCommentService_CreateReplyDepth(t *testing.T) {
+	t.Run("replies within the limit record their depth", func(t *testing.T) {
+		service, _ := newThreadedCommentService(2, CommentDepthReject)
+
+		chain := seedChain(t, service, 3)
+
+		assert.Nil(t, chain[0].ParentID)
+		assert.Equal(t, 0, chain[0].Depth)
+		assert.Equal(t, chain[0].ID, *chain[1].ParentID)
+		assert.Equal(t, 1, chain[1].Depth)
+		assert.Equal(t, 2, chain[2].Depth)
+	})
+
+	t.Run("reject policy refuses replies beyond the max depth", func(t *testing.T) {
+		service, _ := newThreadedCommentService(2, CommentDepthReject)
+		chain := seedChain(t, service, 3)
+
+		_, err := service.Create(&models.CreateCommentRequest{
+			PostID:   1,
+			ParentID: uintPtr(chain[2].ID),
+			Content:  "One level too deep",
+		}, 2, "author")
+
+		assert.ErrorIs(t, err, ErrCommentTooDeep)
+	})
+
+	t.Run("clamp policy attaches deep replies at the max depth", func(t *testing.T) {
+		service, _ := newThreadedCommentService(2, CommentDepthClamp)
+		chain := seedChain(t, service, 3)
+
+		reply, err := service.Create(&models.CreateCommentRequest{
+			PostID:   1,
+			ParentID: uintPtr(chain[2].ID),
+			Content:  "One level too deep",
+		}, 2, "author")
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, reply.Depth)
+		require.NotNil(t, reply.ParentID)
+		assert.Equal(t, chain[1].ID, *reply.ParentID)
+	})
+
+	t.Run("a parent on another post is rejected", func(t *testing.T) {
+		service, commentRepo := newThreadedCommentService(2, CommentDepthReject)
+		foreign := &models.Comment{PostID: 99, UserID: 2, Content: "Elsewhere", Status: "approved"}
+		commentRepo.Create(foreign)
+
+		_, err := service.Create(&models.CreateCommentRequest{
+			PostID:   1,
+			ParentID: uintPtr(foreign.ID),
+			Content:  "Cross-post reply",
+		}, 2, "author")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCommentService_GetThread(t *testing.T) {
+	service, commentRepo := newThreadedCommentService(5, CommentDepthReject)
+	first := seedChain(t, service, 3)
+	second := seedChain(t, service, 1)
+	sibling, err := service.Create(&models.CreateCommentRequest{
+		PostID:   1,
+		ParentID: uintPtr(first[0].ID),
+		Content:  "A sibling reply",
+	}, 3, "author")
+	require.NoError(t, err)
+
+	tree, err := service.GetThread(1, "", 0, "admin")
+	require.NoError(t, err)
+
+	require.Len(t, tree, 2)
+	assert.Equal(t, first[0].ID, tree[0].ID)
+	assert.Equal(t, second[0].ID, tree[1].ID)
+	require.Len(t, tree[0].Replies, 2)
+	assert.Equal(t, first[1].ID, tree[0].Replies[0].ID)
+	assert.Equal(t, sibling.ID, tree[0].Replies[1].ID)
+	require.Len(t, tree[0].Replies[0].Replies, 1)
+	assert.Equal(t, first[2].ID, tree[0].Replies[0].Replies[0].ID)
+
+	t.Run("flattened thread keeps display order and parent references", func(t *testing.T) {
+		flat := models.FlattenCommentTree(tree)
+
+		var ids []uint
+		for _, comment := range flat {
+			ids = append(ids, comment.ID)
+		}
+		assert.Equal(t, []uint{first[0].ID, first[1].ID, first[2].ID, sibling.ID, second[0].ID}, ids)
+
+		assert.Nil(t, flat[0].ParentID)
+		assert.Equal(t, first[0].ID, *flat[1].ParentID)
+		assert.Equal(t, first[1].ID, *flat[2].ParentID)
+		assert.Equal(t, first[0].ID, *flat[3].ParentID)
+		assert.Nil(t, flat[4].ParentID)
+	})
+
+	t.Run("a status leaves out other comments and the replies to them", func(t *testing.T) {
+		for _, comment := range []*models.Comment{first[0], first[2], second[0]} {
+			commentRepo.comments[comment.ID].Status = "approved"
+		}
+
+		approved, err := service.GetThread(1, "approved", 0, "")
+		require.NoError(t, err)
+
+		require.Len(t, approved, 2)
+		assert.Equal(t, first[0].ID, approved[0].ID)
+		assert.Empty(t, approved[0].Replies)
+		assert.Equal(t, second[0].ID, approved[1].ID)
+	})
+
+	t.Run("unpublished posts are hidden from all but their author and admins", func(t *testing.T) {
+		postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Draft", AuthorID: 4, Status: "draft"})
+		service := NewCommentService(newFakeCommentRepo(), postRepo, nil, &config.Config{}, nil)
+
+		_, err := service.GetThread(1, "approved", 0, "")
+		assert.ErrorIs(t, err, ErrCommentPostNotFound)
+		_, err = service.GetThread(1, "approved", 5, "author")
+		assert.ErrorIs(t, err, ErrCommentPostNotFound)
+		_, err = service.GetThread(2, "approved", 0, "admin")
+		assert.ErrorIs(t, err, ErrCommentPostNotFound)
+
+		_, err = service.GetThread(1, "", 4, "author")
+		assert.NoError(t, err)
+		_, err = service.GetThread(1, "", 0, "admin")
+		assert.NoError(t, err)
+	})
+}
+
+func TestCommentService_FlatListsHideUnpublishedPosts(t *testing.T) {
+	postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Draft", AuthorID: 4, Status: "draft"})
+	commentRepo := newFakeCommentRepo(&models.Comment{ID: 1, PostID: 1, UserID: 2, Content: "Early", Status: "approved"})
+	service := NewCommentService(commentRepo, postRepo, nil, &config.Config{}, nil)
+
+	_, _, err := service.GetByPost(1, 1, 10, 0, "")
+	assert.ErrorIs(t, err, ErrCommentPostNotFound)
+	_, _, err = service.GetByPostWithStatus(1, "", 1, 10, 5, "author")
+	assert.ErrorIs(t, err, ErrCommentPostNotFound)
+	_, _, err = service.List(1, 10, map[string]interface{}{"post_id": uint(1), "status": "approved"}, 0, "")
+	assert.ErrorIs(t, err, ErrCommentPostNotFound)
+
+	comments, _, err := service.GetByPost(1, 1, 10, 4, "author")
+	require.NoError(t, err)
+	assert.Len(t, comments, 1)
+	comments, _, err = service.GetByPostWithStatus(1, "", 1, 10, 0, "admin")
+	require.NoError(t, err)
+	assert.Len(t, comments, 1)
+}
+
+func TestCommentService_CommentsClosed(t *testing.T) {
+	const window = 30
+
+	newService := func(post *models.Post) (CommentService, *fakePostRepo) {
+		postRepo := newFakePostRepo(post)
+		cfg := &config.Config{Comment: config.CommentConfig{CloseAfterDays: window}}
+		return NewCommentService(newFakeCommentRepo(), postRepo, nil, cfg, nil), postRepo
+	}
+	publishedDaysAgo := func(days int) *models.Post {
+		publishedAt := time.Now().AddDate(0, 0, -days)
+		return &models.Post{ID: 1, Title: "A post", Status: "published", PublishedAt: &publishedAt}
+	}
+	comment := &models.CreateCommentRequest{PostID: 1, Content: "Nice post"}
+
+	t.Run("fresh posts accept comments", func(t *testing.T) {
+		service, _ := newService(publishedDaysAgo(1))
+
+		_, err := service.Create(comment, 2, "author")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("posts older than the window reject comments", func(t *testing.T) {
+		service, _ := newService(publishedDaysAgo(window + 1))
+
+		_, err := service.Create(comment, 2, "author")
+
+		assert.ErrorIs(t, err, ErrCommentsClosed)
+	})
+
+	t.Run("admins can still comment on old posts", func(t *testing.T) {
+		service, _ := newService(publishedDaysAgo(window + 1))
+
+		_, err := service.Create(comment, 1, "admin")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("disabling comments on a post closes them regardless of age", func(t *testing.T) {
+		service, postRepo := newService(publishedDaysAgo(1))
+		post, err := postRepo.GetByID(1)
+		require.NoError(t, err)
+		post.CommentsEnabled = false
+		require.NoError(t, postRepo.Update(post))
+
+		_, err = service.Create(comment, 2, "author")
+
+		assert.ErrorIs(t, err, ErrCommentsClosed)
+	})
+
+	t.Run("a zero window keeps comments open", func(t *testing.T) {
+		postRepo := newFakePostRepo(publishedDaysAgo(365))
+		service := NewCommentService(newFakeCommentRepo(), postRepo, nil, &config.Config{}, nil)
+
+		_, err := service.Create(comment, 2, "author")
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestCommentService_GetRecent(t *testing.T) {
+	commentRepo := newFakeCommentRepo()
+	for i := 0; i < MaxRecentComments+5; i++ {
+		commentRepo.Create(&models.Comment{PostID: 1, UserID: 2, Content: "Nice post", Status: "approved"})
+	}
+	service := NewCommentService(commentRepo, newFakePostRepo(), nil, &config.Config{}, nil)
+
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{"zero uses the default", 0, DefaultRecentComments},
+		{"within bounds", 3, 3},
+		{"clamped to the maximum", 100, MaxRecentComments},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments, err := service.GetRecent(tt.limit)
+
+			require.NoError(t, err)
+			assert.Len(t, comments, tt.want)
+		})
+	}
+}
+
+func TestCommentService_CreateTrustedDomains(t *testing.T) {
+	// create has user 2, with the given email, comment and returns the status
+	// the comment was given
+	create := func(t *testing.T, email string, verified bool, trusted ...string) string {
+		postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Moderated post", Status: "published"})
+		userRepo := newFakeUserRepo(&models.User{ID: 2, Email: email, EmailVerified: verified})
+		cfg := &config.Config{Comment: config.CommentConfig{TrustedDomains: trusted}}
+		service := NewCommentService(newFakeCommentRepo(), postRepo, userRepo, cfg, nil)
+
+		comment, err := service.Create(&models.CreateCommentRequest{PostID: 1, Content: "Thanks for writing this"}, 2, "author")
+		require.NoError(t, err)
+		return comment.Status
+	}
+
+	t.Run("comments from a trusted domain are approved", func(t *testing.T) {
+		assert.Equal(t, "approved", create(t, "jane@example.com", true, "example.com", "staff.example.org"))
+		assert.Equal(t, "approved", create(t, "Sam@Staff.Example.org", true, "example.com", "staff.example.org"))
+	})
+
+	t.Run("comments from other domains wait for moderation", func(t *testing.T) {
+		assert.Equal(t, "pending", create(t, "jane@example.net", true, "example.com"))
+		assert.Equal(t, "pending", create(t, "jane@mail.example.com", true, "example.com"))
+		assert.Equal(t, "pending", create(t, "", true, "example.com"))
+	})
+
+	t.Run("an unverified address on a trusted domain waits for moderation", func(t *testing.T) {
+		assert.Equal(t, "pending", create(t, "jane@example.com", false, "example.com"))
+	})
+
+	t.Run("every comment is moderated by default", func(t *testing.T) {
+		assert.Equal(t, "pending", create(t, "jane@example.com", true))
+	})
+}
+
+func TestCommentService_CreatePublishesApproval(t *testing.T) {
+	// create has user 2 comment and returns the approval events published
+	create := func(t *testing.T, trusted ...string) []CommentEvent {
+		bus := events.NewBus()
+		var approved []CommentEvent
+		bus.Subscribe(EventCommentApproved, func(ctx context.Context, event events.Event) {
+			approved = append(approved, event.(CommentEvent))
+		})
+		postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Moderated post", Status: "published"})
+		userRepo := newFakeUserRepo(&models.User{ID: 2, Email: "jane@example.com", EmailVerified: true})
+		cfg := &config.Config{Comment: config.CommentConfig{TrustedDomains: trusted}}
+		service := NewCommentService(newFakeCommentRepo(), postRepo, userRepo, cfg, bus)
+
+		_, err := service.Create(&models.CreateCommentRequest{PostID: 1, Content: "Thanks for writing this"}, 2, "author")
+		require.NoError(t, err)
+		return approved
+	}
+
+	t.Run("a comment approved on arrival is announced", func(t *testing.T) {
+		approved := create(t, "example.com")
+
+		require.Len(t, approved, 1)
+		assert.Equal(t, "approved", approved[0].Comment.Status)
+		assert.Equal(t, uint(1), approved[0].Comment.PostID)
+	})
+
+	t.Run("a comment waiting for moderation is not", func(t *testing.T) {
+		assert.Empty(t, create(t))
+	})
+}
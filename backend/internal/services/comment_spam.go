@@ -0,0 +1,81 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"backend/internal/config"
+)
+
+// CommentModerationService screens the content of new comments before they
+// are stored
+type CommentModerationService interface {
+	// Screen returns the status a new comment should get: "rejected" for
+	// spam, "pending" for content a moderator should see first, or an empty
+	// string when it has no objection
+	Screen(content string) string
+}
+
+type commentModerationService struct {
+	blockedWords *regexp.Regexp
+	heldWords    *regexp.Regexp
+	blockedLinks []*regexp.Regexp
+	maxLinks     int
+}
+
+// NewCommentModerationService builds a screener from the comment spam
+// rules. Empty lists and a SpamMaxLinks of 0 or less let everything through.
+func NewCommentModerationService(cfg config.CommentConfig) CommentModerationService {
+	service := &commentModerationService{
+		blockedWords: wordPattern(cfg.BlockedWords),
+		heldWords:    wordPattern(cfg.HeldWords),
+		maxLinks:     cfg.SpamMaxLinks,
+	}
+	for _, pattern := range cfg.BlockedLinks {
+		service.blockedLinks = append(service.blockedLinks, linkPattern(pattern))
+	}
+	return service
+}
+
+func (s *commentModerationService) Screen(content string) string {
+	if s.blockedWords != nil && s.blockedWords.MatchString(content) {
+		return "rejected"
+	}
+
+	links := commentLinkPattern.FindAllString(content, -1)
+	if s.maxLinks > 0 && len(links) > s.maxLinks {
+		return "rejected"
+	}
+	for _, link := range links {
+		for _, pattern := range s.blockedLinks {
+			if pattern.MatchString(link) {
+				return "rejected"
+			}
+		}
+	}
+
+	if s.heldWords != nil && s.heldWords.MatchString(content) {
+		return "pending"
+	}
+	return ""
+}
+
+// wordPattern matches any of words as a whole word, ignoring case, or is
+// nil when there are no words
+func wordPattern(words []string) *regexp.Regexp {
+	if len(words) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// linkPattern matches a whole URL against pattern, ignoring case, with *
+// standing for any run of characters
+func linkPattern(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	return regexp.MustCompile(`(?i)^` + strings.ReplaceAll(quoted, `\*`, `.*`) + `$`)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Commenters of the screened service: a reader from an ordinary domain and
+// staff from the trusted one
+const (
+	screenedReader = uint(2)
+	screenedStaff  = uint(3)
+)
+
+func newScreenedCommentService() CommentService {
+	postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Screened post", Status: "published", CommentsEnabled: true})
+	cfg := &config.Config{Comment: config.CommentConfig{
+		MaxLinks:       5,
+		TrustedDomains: []string{"example.com"},
+		BlockedWords:   []string{"casino", "cheap pills"},
+		BlockedLinks:   []string{"*bit.ly/*"},
+		HeldWords:      []string{"giveaway"},
+		SpamMaxLinks:   2,
+	}}
+	userRepo := newFakeUserRepo(
+		&models.User{ID: screenedReader, Email: "reader@mail.test", EmailVerified: true},
+		&models.User{ID: screenedStaff, Email: "staff@example.com", EmailVerified: true},
+	)
+	return NewCommentService(newFakeCommentRepo(), postRepo, userRepo, cfg, nil)
+}
+
+func TestCommentService_SpamScreening(t *testing.T) {
+	create := func(content string, userID uint) *models.Comment {
+		comment, err := newScreenedCommentService().Create(&models.CreateCommentRequest{PostID: 1, Content: content}, userID, "author")
+		require.NoError(t, err)
+		return comment
+	}
+
+	t.Run("a clean comment keeps the usual status", func(t *testing.T) {
+		assert.Equal(t, "pending", create("Great write-up, see https://go.dev for more", screenedReader).Status)
+		assert.Equal(t, "approved", create("Thanks for the casinos of Monte Carlo photos", screenedStaff).Status)
+	})
+
+	t.Run("too many links is rejected", func(t *testing.T) {
+		comment := create("Try https://a.test and https://b.test and www.c.test", screenedStaff)
+
+		assert.Equal(t, "rejected", comment.Status)
+	})
+
+	t.Run("a blocked keyword is rejected whatever its case", func(t *testing.T) {
+		assert.Equal(t, "rejected", create("Best CASINO bonuses here", screenedStaff).Status)
+		assert.Equal(t, "rejected", create("Buy cheap pills today", screenedReader).Status)
+	})
+
+	t.Run("a blocked link pattern is rejected", func(t *testing.T) {
+		assert.Equal(t, "rejected", create("Read this: https://bit.ly/x1y2", screenedReader).Status)
+	})
+
+	t.Run("a held keyword waits for moderation", func(t *testing.T) {
+		assert.Equal(t, "pending", create("Is the giveaway still on?", screenedStaff).Status)
+	})
+}
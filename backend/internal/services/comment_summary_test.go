@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentService_GetSummaries(t *testing.T) {
+	newService := func() (CommentService, *fakeCommentRepo) {
+		commentRepo := newFakeCommentRepo(
+			&models.Comment{PostID: 1, UserID: 2, Status: "approved"},
+			&models.Comment{PostID: 1, UserID: 3, Status: "approved"},
+			&models.Comment{PostID: 1, UserID: 4, Status: "pending"},
+			&models.Comment{PostID: 2, UserID: 2, Status: "approved"},
+			&models.Comment{PostID: 3, UserID: 2, Status: "rejected"},
+		)
+		postRepo := newFakePostRepo(
+			&models.Post{ID: 1, Title: "First", AuthorID: 5, Status: "published"},
+			&models.Post{ID: 2, Title: "Second", AuthorID: 5, Status: "published"},
+			&models.Post{ID: 3, Title: "Third", AuthorID: 5, Status: "published"},
+			&models.Post{ID: 4, Title: "Draft", AuthorID: 5, Status: "draft"},
+		)
+		return NewCommentService(commentRepo, postRepo, nil, &config.Config{}, nil), commentRepo
+	}
+
+	t.Run("summaries follow the requested order with one fetch", func(t *testing.T) {
+		commentService, commentRepo := newService()
+
+		summaries, err := commentService.GetSummaries([]uint{2, 1, 2, 3}, 0, "")
+
+		require.NoError(t, err)
+		require.Len(t, summaries, 3)
+		assert.Equal(t, [][]uint{{2, 1, 3}}, commentRepo.summaryCalls)
+
+		assert.Equal(t, uint(2), summaries[0].PostID)
+		assert.Equal(t, int64(1), summaries[0].Count)
+
+		assert.Equal(t, uint(1), summaries[1].PostID)
+		assert.Equal(t, int64(2), summaries[1].Count)
+		require.NotNil(t, summaries[1].Latest)
+		assert.Equal(t, uint(3), summaries[1].Latest.Author.ID)
+	})
+
+	t.Run("posts without visible comments report none", func(t *testing.T) {
+		commentService, _ := newService()
+
+		summaries, err := commentService.GetSummaries([]uint{3}, 0, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.PostCommentSummary{{PostID: 3}}, summaries)
+	})
+
+	t.Run("posts the viewer may not see are left out", func(t *testing.T) {
+		commentService, commentRepo := newService()
+
+		summaries, err := commentService.GetSummaries([]uint{4, 3, 9}, 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, []models.PostCommentSummary{{PostID: 3}}, summaries)
+
+		summaries, err = commentService.GetSummaries([]uint{4, 9}, 6, "author")
+		require.NoError(t, err)
+		assert.Empty(t, summaries)
+
+		summaries, err = commentService.GetSummaries([]uint{4, 9}, 5, "author")
+		require.NoError(t, err)
+		assert.Equal(t, []models.PostCommentSummary{{PostID: 4}}, summaries)
+		assert.Equal(t, [][]uint{{3}, {4}}, commentRepo.summaryCalls)
+	})
+
+	t.Run("too many posts are refused", func(t *testing.T) {
+		commentService, commentRepo := newService()
+		ids := make([]uint, MaxCommentSummaryPosts+1)
+		for i := range ids {
+			ids[i] = uint(i + 1)
+		}
+
+		_, err := commentService.GetSummaries(ids, 0, "")
+
+		assert.Error(t, err)
+		assert.Empty(t, commentRepo.summaryCalls)
+	})
+}
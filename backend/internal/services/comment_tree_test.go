@@ -0,0 +1,119 @@
+package services
+
+import (
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCommentTreeService seeds published post 1 by author 1 with a four level
+// approved thread under comment 1, a pinned top-level comment, a rejected
+// reply, and a pending comment by user 3 with an approved reply. Draft post
+// 2 by author 1 has a single approved comment.
+func newCommentTreeService() CommentService {
+	reader := &models.User{ID: 2, Username: "reader", Name: "Reader"}
+	postRepo := newFakePostRepo(
+		&models.Post{ID: 1, Title: "Published", AuthorID: 1, Status: "published"},
+		&models.Post{ID: 2, Title: "Draft", AuthorID: 1, Status: "draft"},
+	)
+	commentRepo := newFakeCommentRepo(
+		&models.Comment{ID: 1, PostID: 1, UserID: 2, User: reader, Content: "Top", Status: "approved"},
+		&models.Comment{ID: 2, PostID: 1, UserID: 2, ParentID: uintPtr(1), Depth: 1, Content: "Reply", Status: "approved"},
+		&models.Comment{ID: 3, PostID: 1, UserID: 2, ParentID: uintPtr(2), Depth: 2, Content: "Reply to reply", Status: "approved"},
+		&models.Comment{ID: 4, PostID: 1, UserID: 2, ParentID: uintPtr(3), Depth: 3, Content: "Deepest", Status: "approved"},
+		&models.Comment{ID: 5, PostID: 1, UserID: 3, Content: "Awaiting moderation", Status: "pending"},
+		&models.Comment{ID: 6, PostID: 1, UserID: 2, ParentID: uintPtr(5), Depth: 1, Content: "Reply to pending", Status: "approved"},
+		&models.Comment{ID: 7, PostID: 1, UserID: 2, Content: "Pinned", Status: "approved", Pinned: true},
+		&models.Comment{ID: 8, PostID: 1, UserID: 2, ParentID: uintPtr(1), Depth: 1, Content: "Spam", Status: "rejected"},
+		&models.Comment{ID: 9, PostID: 2, UserID: 2, Content: "On a draft", Status: "approved"},
+	)
+	return NewCommentService(commentRepo, postRepo, nil, &config.Config{}, nil)
+}
+
+func treeIDs(nodes []*models.CommentTreeNode) []uint {
+	ids := make([]uint, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	return ids
+}
+
+func TestCommentService_GetCommentTree(t *testing.T) {
+	service := newCommentTreeService()
+
+	t.Run("nests approved replies with counts for anonymous viewers", func(t *testing.T) {
+		tree, err := service.GetCommentTree(1, DefaultCommentTreeDepth, 0, "")
+		require.NoError(t, err)
+
+		assert.Equal(t, 5, tree.Total)
+		require.Equal(t, []uint{7, 1}, treeIDs(tree.Comments))
+
+		top := tree.Comments[1]
+		assert.Equal(t, models.CommentAuthor{ID: 2, Username: "reader", Name: "Reader"}, top.Author)
+		assert.Equal(t, 1, top.ReplyCount)
+		require.Equal(t, []uint{2}, treeIDs(top.Replies))
+		require.Equal(t, []uint{3}, treeIDs(top.Replies[0].Replies))
+		deepest := top.Replies[0].Replies[0].Replies
+		require.Equal(t, []uint{4}, treeIDs(deepest))
+		assert.Equal(t, 3, deepest[0].Depth)
+		assert.Zero(t, deepest[0].ReplyCount)
+	})
+
+	t.Run("signed-in users also see their own comments", func(t *testing.T) {
+		tree, err := service.GetCommentTree(1, DefaultCommentTreeDepth, 3, "author")
+		require.NoError(t, err)
+
+		assert.Equal(t, 7, tree.Total)
+		require.Equal(t, []uint{7, 1, 5}, treeIDs(tree.Comments))
+		assert.Equal(t, []uint{6}, treeIDs(tree.Comments[2].Replies))
+	})
+
+	t.Run("admins see every comment", func(t *testing.T) {
+		tree, err := service.GetCommentTree(1, DefaultCommentTreeDepth, 99, "admin")
+		require.NoError(t, err)
+
+		assert.Equal(t, 8, tree.Total)
+		assert.Equal(t, 2, tree.Comments[1].ReplyCount)
+	})
+
+	t.Run("replies below the max depth are counted but not nested", func(t *testing.T) {
+		tree, err := service.GetCommentTree(1, 1, 0, "")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, tree.MaxDepth)
+		assert.Equal(t, 5, tree.Total)
+		reply := tree.Comments[1].Replies[0]
+		assert.Equal(t, 1, reply.ReplyCount)
+		assert.Empty(t, reply.Replies)
+
+		tree, err = service.GetCommentTree(1, 0, 0, "")
+		require.NoError(t, err)
+		assert.Empty(t, tree.Comments[1].Replies)
+		assert.Equal(t, 1, tree.Comments[1].ReplyCount)
+	})
+
+	t.Run("max depth is clamped", func(t *testing.T) {
+		tree, err := service.GetCommentTree(1, 1000, 0, "")
+		require.NoError(t, err)
+
+		assert.Equal(t, MaxCommentTreeDepth, tree.MaxDepth)
+	})
+
+	t.Run("draft posts are only visible to their author and admins", func(t *testing.T) {
+		_, err := service.GetCommentTree(2, DefaultCommentTreeDepth, 0, "")
+		assert.ErrorIs(t, err, ErrCommentPostNotFound)
+
+		tree, err := service.GetCommentTree(2, DefaultCommentTreeDepth, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, 1, tree.Total)
+	})
+
+	t.Run("unknown posts are not found", func(t *testing.T) {
+		_, err := service.GetCommentTree(42, DefaultCommentTreeDepth, 0, "")
+		assert.ErrorIs(t, err, ErrCommentPostNotFound)
+	})
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/repositories"
+	"backend/pkg/events"
+	"backend/pkg/logger"
+	"backend/pkg/scheduler"
+
+	"go.uber.org/zap"
+)
+
+// DraftArchiveService archives drafts their authors appear to have abandoned
+type DraftArchiveService interface {
+	// Archive moves every draft not updated for the configured number of
+	// days to archived. Published posts are never touched. If ctx is
+	// cancelled it stops between drafts with a *scheduler.InterruptedError.
+	Archive(ctx context.Context) error
+}
+
+type draftArchiveService struct {
+	postRepo repositories.PostRepository
+	maxAge   time.Duration
+	bus      *events.Bus
+}
+
+func NewDraftArchiveService(postRepo repositories.PostRepository, cfg *config.Config, bus *events.Bus) DraftArchiveService {
+	return &draftArchiveService{
+		postRepo: postRepo,
+		maxAge:   time.Duration(cfg.Jobs.DraftArchiveAfterDays) * 24 * time.Hour,
+		bus:      bus,
+	}
+}
+
+func (s *draftArchiveService) Archive(ctx context.Context) error {
+	// Without an age threshold every draft would count as stale
+	if s.maxAge <= 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-s.maxAge)
+	drafts, err := s.postRepo.ListStaleDrafts(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	archived := 0
+	for i, draft := range drafts {
+		if err := scheduler.Checkpoint(ctx, i, len(drafts)); err != nil {
+			return err
+		}
+		ok, err := s.postRepo.ArchiveDraft(ctx, draft.ID, before)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		archived++
+
+		previous := draft
+		draft.Status = "archived"
+		s.bus.Publish(ctx, PostEvent{Type: EventPostUpdated, Post: draft, Previous: &previous})
+		s.bus.Publish(ctx, PostEvent{Type: EventPostStatusChanged, Post: draft, Previous: &previous})
+		s.bus.Publish(ctx, PostEvent{Type: EventDraftArchived, Post: draft, Previous: &previous})
+	}
+
+	if archived > 0 {
+		logger.LogInfo(ctx, "Archived stale drafts",
+			zap.Int("archived", archived),
+			zap.Time("updated_before", before),
+		)
+	}
+	return nil
+}
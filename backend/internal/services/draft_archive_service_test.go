@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/pkg/events"
+	"backend/pkg/scheduler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type draftArchiveFixture struct {
+	archiver      DraftArchiveService
+	notifications NotificationService
+	notifier      *recordingNotifier
+	postRepo      *fakePostRepo
+}
+
+// newDraftArchiveFixture seeds author 1 with a stale draft, a recent draft,
+// and a published post and an archived post that are just as old
+func newDraftArchiveFixture(afterDays int) *draftArchiveFixture {
+	postRepo := newFakePostRepo(
+		&models.Post{ID: 1, Title: "Abandoned", AuthorID: 1, Status: "draft"},
+		&models.Post{ID: 2, Title: "In progress", AuthorID: 1, Status: "draft"},
+		&models.Post{ID: 3, Title: "Old but published", AuthorID: 1, Status: "published"},
+		&models.Post{ID: 4, Title: "Already archived", AuthorID: 1, Status: "archived"},
+	)
+	longAgo := time.Now().AddDate(0, 0, -100)
+	for _, id := range []uint{1, 3, 4} {
+		postRepo.posts[id].UpdatedAt = longAgo
+	}
+	postRepo.posts[2].UpdatedAt = time.Now().AddDate(0, 0, -10)
+
+	cfg := &config.Config{
+		Jobs:   config.JobsConfig{DraftArchiveAfterDays: afterDays},
+		Notify: config.NotificationConfig{SiteURL: "https://blog.example.com"},
+	}
+	userRepo := newFakeUserRepo(&models.User{ID: 1, Username: "author", Email: "author@example.com"})
+	bus := events.NewBus()
+	notifier := &recordingNotifier{}
+	notifications := NewNotificationService(newFakeNotificationRepo(), userRepo, postRepo, newFakeCommentRepo(), cfg, notifier)
+	notifications.Subscribe(bus)
+
+	return &draftArchiveFixture{
+		archiver:      NewDraftArchiveService(postRepo, cfg, bus),
+		notifications: notifications,
+		notifier:      notifier,
+		postRepo:      postRepo,
+	}
+}
+
+func (f *draftArchiveFixture) statuses() map[uint]string {
+	statuses := make(map[uint]string)
+	for id, post := range f.postRepo.posts {
+		statuses[id] = post.Status
+	}
+	return statuses
+}
+
+func TestDraftArchiveService_Archive(t *testing.T) {
+	t.Run("only stale drafts are archived and their authors notified", func(t *testing.T) {
+		fixture := newDraftArchiveFixture(90)
+
+		require.NoError(t, fixture.archiver.Archive(context.Background()))
+		fixture.notifications.Wait()
+
+		assert.Equal(t, map[uint]string{1: "archived", 2: "draft", 3: "published", 4: "archived"}, fixture.statuses())
+
+		sent := fixture.notifier.sentTo(1, models.NotificationDraftArchived)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "author@example.com", sent[0].Email)
+		assert.Equal(t, uint(1), sent[0].RefID)
+		assert.Equal(t, "https://blog.example.com/dashboard/posts/1/edit", sent[0].Link)
+
+		unread, err := fixture.notifications.UnreadCount(1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), unread)
+	})
+
+	t.Run("a second run has nothing left to archive", func(t *testing.T) {
+		fixture := newDraftArchiveFixture(90)
+
+		require.NoError(t, fixture.archiver.Archive(context.Background()))
+		require.NoError(t, fixture.archiver.Archive(context.Background()))
+		fixture.notifications.Wait()
+
+		assert.Len(t, fixture.notifier.sentTo(1, models.NotificationDraftArchived), 1)
+	})
+
+	t.Run("no age threshold archives nothing", func(t *testing.T) {
+		fixture := newDraftArchiveFixture(0)
+
+		require.NoError(t, fixture.archiver.Archive(context.Background()))
+		fixture.notifications.Wait()
+
+		assert.Equal(t, "draft", fixture.statuses()[1])
+		assert.Empty(t, fixture.notifier.sent)
+	})
+}
+
+func TestDraftArchiveService_ArchiveStopsWhenCancelled(t *testing.T) {
+	postRepo := newFakePostRepo()
+	longAgo := time.Now().AddDate(0, 0, -100)
+	for i := 0; i < 4; i++ {
+		post := &models.Post{Title: "Abandoned", AuthorID: 1, Status: "draft"}
+		require.NoError(t, postRepo.Create(post))
+		postRepo.posts[post.ID].UpdatedAt = longAgo
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := &config.Config{Jobs: config.JobsConfig{DraftArchiveAfterDays: 90}}
+	archiver := NewDraftArchiveService(&cancellingPostRepo{fakePostRepo: postRepo, cancel: cancel, after: 1}, cfg, events.NewBus())
+
+	err := archiver.Archive(ctx)
+
+	var interrupted *scheduler.InterruptedError
+	require.ErrorAs(t, err, &interrupted)
+	assert.Equal(t, 1, interrupted.Done)
+	assert.Equal(t, 4, interrupted.Total)
+	assert.Equal(t, "archived", postRepo.posts[1].Status)
+	for _, id := range []uint{2, 3, 4} {
+		assert.Equal(t, "draft", postRepo.posts[id].Status)
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/pkg/logger"
+	"backend/pkg/metrics"
+
+	"go.uber.org/zap"
+)
+
+// emailFailureHistory caps how many recent email failures are kept for
+// admins to review
+const emailFailureHistory = 50
+
+// ErrEmailQueueFull is returned when an email is dropped because too many
+// are already waiting to be sent
+var ErrEmailQueueFull = errors.New("email queue is full")
+
+// EmailQueue sends email in the background so a slow or unreachable mail
+// server never fails the operation that triggered the message. Failed sends
+// are retried with exponential backoff; emails that still fail are logged,
+// counted in blogcms_email_send_total and kept for RecentFailures.
+//
+// A nil *EmailQueue is valid and reports no failures, so callers need not
+// check whether email is configured.
+type EmailQueue struct {
+	sender      Notifier
+	jobs        chan Notification
+	maxAttempts int
+	backoff     time.Duration
+
+	mu       sync.Mutex
+	closed   bool
+	failures []models.EmailFailure
+	abort    chan struct{}
+	aborted  sync.Once
+	done     chan struct{}
+}
+
+// NewEmailQueue wraps sender, normally an EmailNotifier, in a queue. Emails
+// are only sent once Start has been called.
+func NewEmailQueue(sender Notifier, cfg *config.NotificationConfig) *EmailQueue {
+	size := cfg.EmailQueueSize
+	if size < 1 {
+		size = 1
+	}
+	attempts := cfg.EmailMaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return &EmailQueue{
+		sender:      sender,
+		jobs:        make(chan Notification, size),
+		maxAttempts: attempts,
+		backoff:     cfg.EmailRetryBackoff,
+		abort:       make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Notify queues the notification's email without waiting for it to be sent.
+// It only fails when the queue is full or stopped, in which case the email
+// is dropped.
+func (q *EmailQueue) Notify(ctx context.Context, notification Notification) error {
+	if notification.Email == "" {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.closed {
+		select {
+		case q.jobs <- notification:
+			return nil
+		default:
+		}
+	}
+
+	metrics.RecordEmailSend(metrics.EmailDropped)
+	q.recordFailureLocked(notification, ErrEmailQueueFull, 0)
+	return ErrEmailQueueFull
+}
+
+// Start begins sending queued emails
+func (q *EmailQueue) Start(ctx context.Context) error {
+	go q.run()
+	return nil
+}
+
+// Stop stops accepting emails and waits for the queued ones to be sent,
+// retries included. Once ctx expires, emails still waiting for a retry are
+// given up on.
+func (q *EmailQueue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		q.aborted.Do(func() { close(q.abort) })
+		return ctx.Err()
+	}
+}
+
+// RecentFailures returns the most recent emails that could not be sent,
+// newest first
+func (q *EmailQueue) RecentFailures() []models.EmailFailure {
+	if q == nil {
+		return []models.EmailFailure{}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	failures := make([]models.EmailFailure, len(q.failures))
+	for i, failure := range q.failures {
+		failures[len(q.failures)-1-i] = failure
+	}
+	return failures
+}
+
+func (q *EmailQueue) run() {
+	defer close(q.done)
+	for notification := range q.jobs {
+		q.send(notification)
+	}
+}
+
+// send tries the email until it succeeds, runs out of attempts, or shutdown
+// runs out of time
+func (q *EmailQueue) send(notification Notification) {
+	ctx := context.Background()
+	wait := q.backoff
+
+	for attempt := 1; ; attempt++ {
+		err := q.sender.Notify(ctx, notification)
+		if err == nil {
+			metrics.RecordEmailSend(metrics.EmailSent)
+			return
+		}
+
+		if attempt >= q.maxAttempts || !q.sleep(wait) {
+			metrics.RecordEmailSend(metrics.EmailFailed)
+			logger.LogError(ctx, "Failed to send email", err,
+				zap.Uint("user_id", notification.UserID),
+				zap.String("subject", notification.Subject),
+				zap.Int("attempts", attempt),
+			)
+			q.recordFailure(notification, err, attempt)
+			return
+		}
+
+		metrics.RecordEmailSend(metrics.EmailRetried)
+		wait *= 2
+	}
+}
+
+// sleep waits before a retry. It reports false when shutdown has run out of
+// time, so the email is given up on instead.
+func (q *EmailQueue) sleep(wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-q.abort:
+		return false
+	}
+}
+
+func (q *EmailQueue) recordFailure(notification Notification, err error, attempts int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.recordFailureLocked(notification, err, attempts)
+}
+
+func (q *EmailQueue) recordFailureLocked(notification Notification, err error, attempts int) {
+	q.failures = append(q.failures, models.EmailFailure{
+		To:       notification.Email,
+		Subject:  notification.Subject,
+		Error:    err.Error(),
+		Attempts: attempts,
+		FailedAt: time.Now(),
+	})
+	if len(q.failures) > emailFailureHistory {
+		q.failures = q.failures[len(q.failures)-emailFailureHistory:]
+	}
+}
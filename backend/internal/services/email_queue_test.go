@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/pkg/events"
+	"backend/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySender fails the first failures sends, or every send when failures
+// is negative
+type flakySender struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+}
+
+func (s *flakySender) Notify(ctx context.Context, notification Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	if s.failures < 0 || s.attempts <= s.failures {
+		return errors.New("smtp: connection refused")
+	}
+	return nil
+}
+
+func (s *flakySender) attemptCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+// emailSends returns the current blogcms_email_send_total count for result
+func emailSends(t *testing.T, result string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "blogcms_email_send_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "result" && pair.GetValue() == result {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func newTestEmailQueue(t *testing.T, sender Notifier, size int) *EmailQueue {
+	queue := NewEmailQueue(sender, &config.NotificationConfig{
+		EmailQueueSize:    size,
+		EmailMaxAttempts:  3,
+		EmailRetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, queue.Start(context.Background()))
+	t.Cleanup(func() { queue.Stop(context.Background()) })
+	return queue
+}
+
+func stopEmailQueue(t *testing.T, queue *EmailQueue) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, queue.Stop(ctx))
+}
+
+func TestEmailQueue_RetriesThenRecordsFailure(t *testing.T) {
+	failedBefore := emailSends(t, metrics.EmailFailed)
+	retriedBefore := emailSends(t, metrics.EmailRetried)
+
+	sender := &flakySender{failures: -1}
+	queue := newTestEmailQueue(t, sender, 10)
+
+	require.NoError(t, queue.Notify(context.Background(), Notification{UserID: 1, Email: "author@example.com", Subject: "New comment"}))
+	stopEmailQueue(t, queue)
+
+	assert.Equal(t, 3, sender.attemptCount())
+	assert.Equal(t, failedBefore+1, emailSends(t, metrics.EmailFailed))
+	assert.Equal(t, retriedBefore+2, emailSends(t, metrics.EmailRetried))
+
+	failures := queue.RecentFailures()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "author@example.com", failures[0].To)
+	assert.Equal(t, "New comment", failures[0].Subject)
+	assert.Equal(t, 3, failures[0].Attempts)
+	assert.Contains(t, failures[0].Error, "connection refused")
+}
+
+func TestEmailQueue_TransientFailureIsRetried(t *testing.T) {
+	sentBefore := emailSends(t, metrics.EmailSent)
+
+	sender := &flakySender{failures: 1}
+	queue := newTestEmailQueue(t, sender, 10)
+
+	require.NoError(t, queue.Notify(context.Background(), Notification{UserID: 1, Email: "author@example.com"}))
+	stopEmailQueue(t, queue)
+
+	assert.Equal(t, 2, sender.attemptCount())
+	assert.Equal(t, sentBefore+1, emailSends(t, metrics.EmailSent))
+	assert.Empty(t, queue.RecentFailures())
+}
+
+func TestEmailQueue_FullQueueDropsEmail(t *testing.T) {
+	droppedBefore := emailSends(t, metrics.EmailDropped)
+
+	// Not started, so nothing drains the queue
+	queue := NewEmailQueue(&flakySender{}, &config.NotificationConfig{EmailQueueSize: 1, EmailMaxAttempts: 1})
+
+	require.NoError(t, queue.Notify(context.Background(), Notification{Email: "first@example.com"}))
+	err := queue.Notify(context.Background(), Notification{Email: "second@example.com"})
+
+	assert.ErrorIs(t, err, ErrEmailQueueFull)
+	assert.Equal(t, droppedBefore+1, emailSends(t, metrics.EmailDropped))
+	failures := queue.RecentFailures()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "second@example.com", failures[0].To)
+}
+
+func TestEmailQueue_KeepsOnlyRecentFailures(t *testing.T) {
+	queue := NewEmailQueue(&flakySender{}, &config.NotificationConfig{EmailQueueSize: 1})
+	for i := 0; i < emailFailureHistory+5; i++ {
+		queue.recordFailure(Notification{Subject: string(rune('a' + i%26))}, errors.New("boom"), 1)
+	}
+
+	failures := queue.RecentFailures()
+	assert.Len(t, failures, emailFailureHistory)
+	assert.Equal(t, string(rune('a'+(emailFailureHistory+4)%26)), failures[0].Subject, "newest first")
+}
+
+func TestEmailQueue_NilReportsNoFailures(t *testing.T) {
+	var queue *EmailQueue
+	assert.Empty(t, queue.RecentFailures())
+}
+
+func TestEmailQueue_FailingEmailDoesNotFailTheOperation(t *testing.T) {
+	failedBefore := emailSends(t, metrics.EmailFailed)
+
+	author := &models.User{ID: 1, Username: "author", Email: "author@example.com", NotifyOnComment: true}
+	reader := &models.User{ID: 2, Username: "reader", Email: "reader@example.com"}
+	postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Hello", Slug: "hello", AuthorID: 1, Status: "published"})
+	notificationRepo := newFakeNotificationRepo()
+
+	sender := &flakySender{failures: -1}
+	queue := newTestEmailQueue(t, sender, 10)
+
+	cfg := &config.Config{}
+	bus := events.NewBus()
+	commentRepo := newFakeCommentRepo()
+	notifications := NewNotificationService(notificationRepo, newFakeUserRepo(author, reader), postRepo, commentRepo, cfg, queue)
+	notifications.Subscribe(bus)
+	comments := NewCommentService(commentRepo, postRepo, nil, cfg, bus)
+
+	comment, err := comments.Create(&models.CreateCommentRequest{PostID: 1, Content: "Nice"}, 2, "author")
+	require.NoError(t, err)
+	approved, err := comments.Update(comment.ID, &models.UpdateCommentRequest{Status: stringPtr("approved")}, 99, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "approved", approved.Status)
+
+	notifications.Wait()
+	stopEmailQueue(t, queue)
+
+	// The in-app notification is still delivered
+	unread, err := notifications.UnreadCount(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), unread)
+
+	assert.Equal(t, 3, sender.attemptCount())
+	assert.Equal(t, failedBefore+1, emailSends(t, metrics.EmailFailed))
+	require.Len(t, queue.RecentFailures(), 1)
+}
+
+func TestEmailQueue_FailingEmailDoesNotFailRegistration(t *testing.T) {
+	failedBefore := emailSends(t, metrics.EmailFailed)
+
+	sender := &flakySender{failures: -1}
+	queue := newTestEmailQueue(t, sender, 10)
+	userRepo := newFakeUserRepo()
+	authService := NewAuthService(userRepo, nil, fakeJWTService{}, &config.Config{}, queue)
+
+	user, err := authService.Register(registration(""))
+	require.NoError(t, err)
+	stored, err := userRepo.GetByEmail("newuser@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, stored.ID)
+
+	stopEmailQueue(t, queue)
+
+	assert.Equal(t, 3, sender.attemptCount())
+	assert.Equal(t, failedBefore+1, emailSends(t, metrics.EmailFailed))
+	failures := queue.RecentFailures()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "newuser@example.com", failures[0].To)
+	assert.Equal(t, "Confirm your email address", failures[0].Subject)
+}
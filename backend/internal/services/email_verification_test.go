@@ -0,0 +1,131 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerUnverified registers a new account and returns it as stored
+func registerUnverified(t *testing.T, authService AuthService, userRepo *fakeUserRepo) *models.User {
+	t.Helper()
+	user, err := authService.Register(registration(""))
+	require.NoError(t, err)
+	return userRepo.users[user.ID]
+}
+
+func TestAuthService_RegisterStartsUnverified(t *testing.T) {
+	authService, userRepo := newRegistrationService("author")
+
+	stored := registerUnverified(t, authService, userRepo)
+
+	assert.False(t, stored.EmailVerified)
+	assert.Len(t, stored.VerificationToken, 64)
+	require.NotNil(t, stored.VerificationExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *stored.VerificationExpiresAt, time.Minute)
+}
+
+func TestAuthService_VerifyEmail(t *testing.T) {
+	t.Run("a valid token verifies the email", func(t *testing.T) {
+		authService, userRepo := newRegistrationService("author")
+		stored := registerUnverified(t, authService, userRepo)
+
+		user, err := authService.VerifyEmail(stored.VerificationToken)
+
+		require.NoError(t, err)
+		assert.True(t, user.EmailVerified)
+		assert.Empty(t, user.Password)
+		assert.True(t, userRepo.users[stored.ID].EmailVerified)
+
+		profile, err := authService.GetProfile(stored.ID)
+		require.NoError(t, err)
+		assert.True(t, profile.EmailVerified)
+	})
+
+	t.Run("a token cannot be used twice", func(t *testing.T) {
+		authService, userRepo := newRegistrationService("author")
+		stored := registerUnverified(t, authService, userRepo)
+		_, err := authService.VerifyEmail(stored.VerificationToken)
+		require.NoError(t, err)
+
+		_, err = authService.VerifyEmail(stored.VerificationToken)
+
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+	})
+
+	t.Run("an expired token is rejected", func(t *testing.T) {
+		authService, userRepo := newRegistrationService("author")
+		stored := registerUnverified(t, authService, userRepo)
+		expired := time.Now().Add(-time.Minute)
+		stored.VerificationExpiresAt = &expired
+
+		_, err := authService.VerifyEmail(stored.VerificationToken)
+
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+		assert.False(t, userRepo.users[stored.ID].EmailVerified)
+	})
+
+	t.Run("an empty token is rejected", func(t *testing.T) {
+		authService, _ := newRegistrationService("author")
+
+		_, err := authService.VerifyEmail("")
+
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+	})
+}
+
+func TestAuthService_EmailsVerificationLink(t *testing.T) {
+	newService := func(users ...*models.User) (AuthService, *fakeUserRepo, *recordingNotifier) {
+		userRepo := newFakeUserRepo(users...)
+		emails := &recordingNotifier{}
+		cfg := &config.Config{Notify: config.NotificationConfig{SiteURL: "https://blog.example.com"}}
+		return NewAuthService(userRepo, nil, fakeJWTService{}, cfg, emails), userRepo, emails
+	}
+
+	t.Run("registration emails the verification token", func(t *testing.T) {
+		authService, userRepo, emails := newService()
+		stored := registerUnverified(t, authService, userRepo)
+
+		sent := emails.sentTo(stored.ID, models.NotificationEmailVerification)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "newuser@example.com", sent[0].Email)
+		assert.Equal(t, "https://blog.example.com/verify-email?token="+stored.VerificationToken, sent[0].Link)
+	})
+
+	t.Run("changing the email address asks for it to be verified again", func(t *testing.T) {
+		authService, userRepo, emails := newService(&models.User{ID: 1, Email: "old@example.com", EmailVerified: true})
+		email := "new@example.com"
+
+		user, err := authService.UpdateProfile(1, &models.UpdateProfileRequest{Email: &email})
+
+		require.NoError(t, err)
+		assert.False(t, user.EmailVerified)
+		stored := userRepo.users[1]
+		assert.False(t, stored.EmailVerified)
+		assert.Len(t, stored.VerificationToken, 64)
+		sent := emails.sentTo(1, models.NotificationEmailVerification)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "new@example.com", sent[0].Email)
+		assert.Contains(t, sent[0].Link, stored.VerificationToken)
+
+		_, err = authService.VerifyEmail(stored.VerificationToken)
+		require.NoError(t, err)
+		assert.True(t, userRepo.users[1].EmailVerified)
+	})
+
+	t.Run("resubmitting the same email address keeps it verified", func(t *testing.T) {
+		authService, userRepo, emails := newService(&models.User{ID: 1, Email: "old@example.com", EmailVerified: true})
+		email := "old@example.com"
+
+		_, err := authService.UpdateProfile(1, &models.UpdateProfileRequest{Email: &email})
+
+		require.NoError(t, err)
+		assert.True(t, userRepo.users[1].EmailVerified)
+		assert.Empty(t, emails.sent)
+	})
+}
@@ -0,0 +1,1134 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/events"
+
+	"gorm.io/gorm"
+)
+
+// In-memory repository fakes shared by the service tests. Each fake embeds its
+// repository interface so only the methods a test exercises need an
+// implementation; calling anything else panics loudly.
+
+type fakePostRepo struct {
+	repositories.PostRepository
+	posts map[uint]*models.Post
+	// trashed holds deleted posts until they are restored
+	trashed map[uint]*models.Post
+	nextID  uint
+}
+
+func newFakePostRepo(posts ...*models.Post) *fakePostRepo {
+	repo := &fakePostRepo{posts: make(map[uint]*models.Post), trashed: make(map[uint]*models.Post)}
+	for _, post := range posts {
+		repo.Create(post)
+	}
+	return repo
+}
+
+func (r *fakePostRepo) WithContext(ctx context.Context) repositories.PostRepository {
+	return r
+}
+
+// WithTransaction undoes every change fn made when it fails
+func (r *fakePostRepo) WithTransaction(fn func(repo repositories.PostRepository) error) error {
+	posts := make(map[uint]*models.Post, len(r.posts))
+	for id, post := range r.posts {
+		copied := *post
+		posts[id] = &copied
+	}
+	trashed := make(map[uint]*models.Post, len(r.trashed))
+	for id, post := range r.trashed {
+		copied := *post
+		trashed[id] = &copied
+	}
+	nextID := r.nextID
+
+	if err := fn(r); err != nil {
+		r.posts, r.trashed, r.nextID = posts, trashed, nextID
+		return err
+	}
+	return nil
+}
+
+func (r *fakePostRepo) Create(post *models.Post) error {
+	if post.ID == 0 {
+		r.nextID++
+		post.ID = r.nextID
+	} else if post.ID > r.nextID {
+		r.nextID = post.ID
+	}
+	if post.CreatedAt.IsZero() {
+		post.CreatedAt = time.Now()
+	}
+	post.UpdatedAt = time.Now()
+	// Like the database default, a zero CommentsEnabled is stored as true
+	post.CommentsEnabled = true
+	stored := *post
+	r.posts[post.ID] = &stored
+	return nil
+}
+
+func (r *fakePostRepo) GetByID(id uint) (*models.Post, error) {
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *post
+	return &copied, nil
+}
+
+// GetBySlug matches slugs exactly, like a case-sensitive collation
+func (r *fakePostRepo) GetBySlug(slug string) (*models.Post, error) {
+	for _, post := range r.posts {
+		if post.Slug == slug {
+			copied := *post
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePostRepo) GetBySlugs(slugs []string) ([]models.Post, error) {
+	wanted := make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		wanted[slug] = true
+	}
+
+	var posts []models.Post
+	for _, post := range r.posts {
+		if wanted[post.Slug] {
+			posts = append(posts, *post)
+		}
+	}
+	return posts, nil
+}
+
+func (r *fakePostRepo) Update(post *models.Post) error {
+	if _, ok := r.posts[post.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored := *post
+	r.posts[post.ID] = &stored
+	return nil
+}
+
+func (r *fakePostRepo) Delete(id uint) error {
+	post, ok := r.posts[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	post.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.trashed[id] = post
+	delete(r.posts, id)
+	return nil
+}
+
+func (r *fakePostRepo) BulkUpdateStatus(ids []uint, status string) (int64, error) {
+	var updated int64
+	for _, id := range ids {
+		post, ok := r.posts[id]
+		if !ok {
+			continue
+		}
+		post.Status = status
+		if status == "published" {
+			post.PublishAt = nil
+			if post.PublishedAt == nil {
+				now := time.Now()
+				post.PublishedAt = &now
+			}
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+func (r *fakePostRepo) BulkDelete(ids []uint) (int64, error) {
+	var deleted int64
+	for _, id := range ids {
+		if r.Delete(id) == nil {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *fakePostRepo) SetAuthor(id, authorID, updatedBy uint) error {
+	post, ok := r.posts[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	post.AuthorID = authorID
+	post.UpdatedBy = &updatedBy
+	return nil
+}
+
+func (r *fakePostRepo) TransferAuthor(fromAuthorID, toAuthorID, updatedBy uint) (int64, error) {
+	var transferred int64
+	for _, posts := range []map[uint]*models.Post{r.posts, r.trashed} {
+		for _, post := range posts {
+			if post.AuthorID == fromAuthorID {
+				post.AuthorID = toAuthorID
+				post.UpdatedBy = &updatedBy
+				transferred++
+			}
+		}
+	}
+	return transferred, nil
+}
+
+func (r *fakePostRepo) ListTrashed(page, perPage int) ([]models.Post, int64, error) {
+	var posts []models.Post
+	for _, post := range r.trashed {
+		posts = append(posts, *post)
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].DeletedAt.Time.After(posts[j].DeletedAt.Time) })
+
+	total := int64(len(posts))
+	start := (page - 1) * perPage
+	if start > len(posts) {
+		start = len(posts)
+	}
+	end := start + perPage
+	if end > len(posts) {
+		end = len(posts)
+	}
+	return posts[start:end], total, nil
+}
+
+func (r *fakePostRepo) Restore(id uint) error {
+	post, ok := r.trashed[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	post.DeletedAt = gorm.DeletedAt{}
+	r.posts[id] = post
+	delete(r.trashed, id)
+	return nil
+}
+
+func (r *fakePostRepo) CountPublishedByCategory(ctx context.Context) (map[uint]int64, error) {
+	counts := make(map[uint]int64)
+	for _, post := range r.posts {
+		if post.Status == "published" {
+			counts[post.CategoryID]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *fakePostRepo) GetByAuthor(authorID uint, status string, page, perPage int) ([]models.Post, int64, error) {
+	return r.listBy(func(post *models.Post) bool { return post.AuthorID == authorID }, status, page, perPage)
+}
+
+func (r *fakePostRepo) GetByCategory(categoryID uint, status string, page, perPage int) ([]models.Post, int64, error) {
+	return r.listBy(func(post *models.Post) bool { return post.CategoryID == categoryID }, status, page, perPage)
+}
+
+func (r *fakePostRepo) GetByTag(tagID uint, status string, page, perPage int) ([]models.Post, int64, error) {
+	return r.listBy(func(post *models.Post) bool {
+		for _, tag := range post.Tags {
+			if tag.ID == tagID {
+				return true
+			}
+		}
+		return false
+	}, status, page, perPage)
+}
+
+func (r *fakePostRepo) ReplaceTags(post *models.Post, tags []models.Tag) error {
+	stored, ok := r.posts[post.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored.Tags = append([]models.Tag(nil), tags...)
+	post.Tags = tags
+	return nil
+}
+
+// Search matches the query against titles and applies the author, status
+// and visibility filters, returning a single page
+func (r *fakePostRepo) Search(req *models.PostSearchRequest) ([]models.Post, int64, string, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	posts, total, err := r.listBy(func(post *models.Post) bool {
+		if req.Query != "" && !strings.Contains(strings.ToLower(post.Title), strings.ToLower(req.Query)) {
+			return false
+		}
+		if req.AuthorID > 0 && post.AuthorID != req.AuthorID {
+			return false
+		}
+		return req.OwnUnpublishedOf == 0 || hasStatus(post, "published") || post.AuthorID == req.OwnUnpublishedOf
+	}, req.Status, req.Page, req.Limit)
+	return posts, total, models.SearchModeLike, err
+}
+
+// listBy pages through matching posts newest first, using ID as a stand-in
+// for creation time
+func (r *fakePostRepo) listBy(match func(*models.Post) bool, status string, page, perPage int) ([]models.Post, int64, error) {
+	var posts []models.Post
+	for _, post := range r.posts {
+		if match(post) && (status == "" || hasStatus(post, status)) {
+			posts = append(posts, *post)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].ID > posts[j].ID })
+
+	total := int64(len(posts))
+	start := (page - 1) * perPage
+	if start > len(posts) {
+		start = len(posts)
+	}
+	end := start + perPage
+	if end > len(posts) {
+		end = len(posts)
+	}
+	return posts[start:end], total, nil
+}
+
+// hasStatus mirrors the repository's status filter, which leaves published
+// posts scheduled for later out
+func hasStatus(post *models.Post, status string) bool {
+	if status == "published" && isScheduled(post.PublishAt) {
+		return false
+	}
+	return post.Status == status
+}
+
+func (r *fakePostRepo) GetByIDs(ids []uint) ([]models.Post, error) {
+	var posts []models.Post
+	for _, id := range ids {
+		if post, ok := r.posts[id]; ok {
+			posts = append(posts, *post)
+		}
+	}
+	return posts, nil
+}
+
+func (r *fakePostRepo) CountByAuthor(authorID uint, publishedOnly bool) (int64, error) {
+	var count int64
+	for _, post := range r.posts {
+		if post.AuthorID == authorID && (!publishedOnly || post.Status == "published") {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakePostRepo) CountPublished() (int64, error) {
+	var count int64
+	for _, post := range r.posts {
+		if hasStatus(post, "published") {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakePostRepo) GetPublishedByOffset(offset int64) (*models.Post, error) {
+	var published []*models.Post
+	for _, post := range r.posts {
+		if hasStatus(post, "published") {
+			published = append(published, post)
+		}
+	}
+	sort.Slice(published, func(i, j int) bool { return published[i].ID < published[j].ID })
+	if offset < 0 || offset >= int64(len(published)) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	post := *published[offset]
+	return &post, nil
+}
+
+func (r *fakePostRepo) CountByAuthors(authorIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64)
+	for _, id := range authorIDs {
+		count, _ := r.CountByAuthor(id, false)
+		if count > 0 {
+			counts[id] = count
+		}
+	}
+	return counts, nil
+}
+
+func (r *fakePostRepo) FindByTitle(title string, excludeID uint) (*models.Post, error) {
+	var found *models.Post
+	for _, post := range r.posts {
+		if post.ID != excludeID && strings.EqualFold(post.Title, title) && (found == nil || post.ID < found.ID) {
+			found = post
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *found
+	return &copied, nil
+}
+
+func (r *fakePostRepo) ListStaleDrafts(ctx context.Context, before time.Time) ([]models.Post, error) {
+	var posts []models.Post
+	for _, post := range r.posts {
+		if post.Status == "draft" && post.UpdatedAt.Before(before) {
+			posts = append(posts, *post)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].ID < posts[j].ID })
+	return posts, nil
+}
+
+func (r *fakePostRepo) ArchiveDraft(ctx context.Context, id uint, before time.Time) (bool, error) {
+	post, ok := r.posts[id]
+	if !ok || post.Status != "draft" || !post.UpdatedAt.Before(before) {
+		return false, nil
+	}
+	post.Status = "archived"
+	post.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (r *fakePostRepo) ListDueScheduled(ctx context.Context, now time.Time) ([]models.Post, error) {
+	var posts []models.Post
+	for _, post := range r.posts {
+		if post.Status == "draft" && post.PublishAt != nil && !post.PublishAt.After(now) {
+			posts = append(posts, *post)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].ID < posts[j].ID })
+	return posts, nil
+}
+
+func (r *fakePostRepo) PublishScheduled(ctx context.Context, id uint, now time.Time) (bool, error) {
+	post, ok := r.posts[id]
+	if !ok || post.Status != "draft" || post.PublishAt == nil || post.PublishAt.After(now) {
+		return false, nil
+	}
+	post.Status = "published"
+	post.PublishedAt = &now
+	return true, nil
+}
+
+func (r *fakePostRepo) LatestCreatedAt(authorID uint) (time.Time, error) {
+	var latest time.Time
+	for _, post := range r.posts {
+		if post.AuthorID == authorID && post.CreatedAt.After(latest) {
+			latest = post.CreatedAt
+		}
+	}
+	return latest, nil
+}
+
+// cancellingPostRepo cancels a batch job's context once the job has
+// published or archived after posts, as a shutdown arriving mid-run would
+type cancellingPostRepo struct {
+	*fakePostRepo
+	cancel context.CancelFunc
+	after  int
+	calls  int
+}
+
+func (r *cancellingPostRepo) PublishScheduled(ctx context.Context, id uint, now time.Time) (bool, error) {
+	defer r.count()
+	return r.fakePostRepo.PublishScheduled(ctx, id, now)
+}
+
+func (r *cancellingPostRepo) ArchiveDraft(ctx context.Context, id uint, before time.Time) (bool, error) {
+	defer r.count()
+	return r.fakePostRepo.ArchiveDraft(ctx, id, before)
+}
+
+func (r *cancellingPostRepo) count() {
+	r.calls++
+	if r.calls == r.after {
+		r.cancel()
+	}
+}
+
+func (r *fakePostRepo) IncrementViews(id uint) (uint, error) {
+	post, ok := r.posts[id]
+	if !ok || !hasStatus(post, "published") {
+		return 0, gorm.ErrRecordNotFound
+	}
+	post.ViewCount++
+	return post.ViewCount, nil
+}
+
+type fakeCategoryRepo struct {
+	repositories.CategoryRepository
+	categories map[uint]*models.Category
+	nextID     uint
+	// beforeCreate, when set, runs at the start of every Create. Tests use it
+	// to slip in a competing insert.
+	beforeCreate func()
+}
+
+func newFakeCategoryRepo(categories ...*models.Category) *fakeCategoryRepo {
+	repo := &fakeCategoryRepo{categories: make(map[uint]*models.Category)}
+	for _, category := range categories {
+		stored := *category
+		repo.categories[category.ID] = &stored
+	}
+	return repo
+}
+
+func (r *fakeCategoryRepo) WithContext(ctx context.Context) repositories.CategoryRepository {
+	return r
+}
+
+// Create enforces the unique slug index like the database does
+func (r *fakeCategoryRepo) Create(category *models.Category) error {
+	if r.beforeCreate != nil {
+		r.beforeCreate()
+	}
+	for _, existing := range r.categories {
+		if existing.Slug == category.Slug {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	for id := range r.categories {
+		if id > r.nextID {
+			r.nextID = id
+		}
+	}
+	r.nextID++
+	category.ID = r.nextID
+	stored := *category
+	r.categories[category.ID] = &stored
+	return nil
+}
+
+func (r *fakeCategoryRepo) GetBySlug(slug string) (*models.Category, error) {
+	for _, category := range r.categories {
+		if category.Slug == slug {
+			copied := *category
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// WithTransaction restores the stored categories when fn fails
+func (r *fakeCategoryRepo) WithTransaction(fn func(repo repositories.CategoryRepository) error) error {
+	snapshot := make(map[uint]*models.Category, len(r.categories))
+	for id, category := range r.categories {
+		snapshot[id] = category
+	}
+	nextID := r.nextID
+	if err := fn(r); err != nil {
+		r.categories, r.nextID = snapshot, nextID
+		return err
+	}
+	return nil
+}
+
+func (r *fakeCategoryRepo) GetByID(id uint) (*models.Category, error) {
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *category
+	return &copied, nil
+}
+
+func (r *fakeCategoryRepo) Update(category *models.Category) error {
+	stored := *category
+	r.categories[category.ID] = &stored
+	return nil
+}
+
+func (r *fakeCategoryRepo) Delete(id uint) error {
+	delete(r.categories, id)
+	return nil
+}
+
+// GetTree orders the categories by name, as the database does
+func (r *fakeCategoryRepo) GetTree() ([]*models.CategoryNode, error) {
+	categories := make([]models.Category, 0, len(r.categories))
+	for _, category := range r.categories {
+		categories = append(categories, *category)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	return repositories.BuildCategoryTree(categories), nil
+}
+
+func (r *fakeCategoryRepo) CountChildren(id uint) (int64, error) {
+	var count int64
+	for _, category := range r.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeCategoryRepo) ReparentChildren(id uint, parentID *uint) error {
+	for _, category := range r.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			category.ParentID = parentID
+		}
+	}
+	return nil
+}
+
+func (r *fakeCategoryRepo) AdjustPostCount(ctx context.Context, id uint, delta int64) error {
+	if category, ok := r.categories[id]; ok {
+		category.PostCount += delta
+		if category.PostCount < 0 {
+			category.PostCount = 0
+		}
+	}
+	return nil
+}
+
+func (r *fakeCategoryRepo) SetPostCount(ctx context.Context, id uint, count int64) error {
+	if category, ok := r.categories[id]; ok {
+		category.PostCount = count
+	}
+	return nil
+}
+
+func (r *fakeCategoryRepo) ListPostCounts(ctx context.Context) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(r.categories))
+	for id, category := range r.categories {
+		counts[id] = category.PostCount
+	}
+	return counts, nil
+}
+
+// fakeUnitOfWork runs its function in the post repository's transaction, so
+// posts created by a failing unit are rolled back
+type fakeUnitOfWork struct {
+	posts repositories.PostRepository
+	tags  repositories.TagRepository
+	audit *fakeAuditLogRepo
+}
+
+func newFakeUnitOfWork(posts repositories.PostRepository, tags repositories.TagRepository) *fakeUnitOfWork {
+	return &fakeUnitOfWork{posts: posts, tags: tags, audit: &fakeAuditLogRepo{}}
+}
+
+func (u *fakeUnitOfWork) WithContext(ctx context.Context) repositories.UnitOfWork {
+	return u
+}
+
+func (u *fakeUnitOfWork) Do(fn func(repos repositories.Repositories) error) error {
+	return u.posts.WithTransaction(func(posts repositories.PostRepository) error {
+		return fn(repositories.Repositories{Posts: posts, Tags: u.tags, AuditLogs: u.audit})
+	})
+}
+
+type fakeTagRepo struct {
+	repositories.TagRepository
+	tags   map[string]*models.Tag
+	nextID uint
+	// err, when set, fails FindOrCreate
+	err error
+}
+
+func newFakeTagRepo(tags ...*models.Tag) *fakeTagRepo {
+	repo := &fakeTagRepo{tags: make(map[string]*models.Tag)}
+	for _, tag := range tags {
+		stored := *tag
+		if stored.ID > repo.nextID {
+			repo.nextID = stored.ID
+		}
+		repo.tags[tag.Slug] = &stored
+	}
+	return repo
+}
+
+func (r *fakeTagRepo) WithContext(ctx context.Context) repositories.TagRepository {
+	return r
+}
+
+func (r *fakeTagRepo) FindOrCreate(tags []models.Tag) ([]models.Tag, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	found := make([]models.Tag, 0, len(tags))
+	for _, tag := range tags {
+		stored, ok := r.tags[tag.Slug]
+		if !ok {
+			r.nextID++
+			created := tag
+			created.ID = r.nextID
+			stored = &created
+			r.tags[tag.Slug] = stored
+		}
+		found = append(found, *stored)
+	}
+	return found, nil
+}
+
+func (r *fakeTagRepo) GetBySlug(slug string) (*models.Tag, error) {
+	tag, ok := r.tags[slug]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *tag
+	return &copied, nil
+}
+
+// postServiceSetup is what newTestPostService builds a post service from
+type postServiceSetup struct {
+	repos repositories.Repositories
+	uow   repositories.UnitOfWork
+	cfg   *config.Config
+	bus   *events.Bus
+}
+
+// postServiceOption changes one part of a postServiceSetup
+type postServiceOption func(*postServiceSetup)
+
+func withPostRepo(repo repositories.PostRepository) postServiceOption {
+	return func(setup *postServiceSetup) { setup.repos.Posts = repo }
+}
+
+func withUserRepo(repo repositories.UserRepository) postServiceOption {
+	return func(setup *postServiceSetup) { setup.repos.Users = repo }
+}
+
+func withCategoryRepo(repo repositories.CategoryRepository) postServiceOption {
+	return func(setup *postServiceSetup) { setup.repos.Categories = repo }
+}
+
+func withTagRepo(repo repositories.TagRepository) postServiceOption {
+	return func(setup *postServiceSetup) { setup.repos.Tags = repo }
+}
+
+func withUnitOfWork(uow repositories.UnitOfWork) postServiceOption {
+	return func(setup *postServiceSetup) { setup.uow = uow }
+}
+
+func withConfig(cfg *config.Config) postServiceOption {
+	return func(setup *postServiceSetup) { setup.cfg = cfg }
+}
+
+func withBus(bus *events.Bus) postServiceOption {
+	return func(setup *postServiceSetup) { setup.bus = bus }
+}
+
+// newTestPostService builds a post service for a test. The post repository
+// defaults to an empty fake, the unit of work to a fake over the post and tag
+// repositories and the config to the zero one; other repositories stay nil
+// unless given.
+func newTestPostService(options ...postServiceOption) PostService {
+	setup := postServiceSetup{cfg: &config.Config{}}
+	for _, option := range options {
+		option(&setup)
+	}
+	if setup.repos.Posts == nil {
+		setup.repos.Posts = newFakePostRepo()
+	}
+	if setup.uow == nil {
+		setup.uow = newFakeUnitOfWork(setup.repos.Posts, setup.repos.Tags)
+	}
+	return NewPostService(setup.repos, setup.uow, setup.cfg, setup.bus)
+}
+
+type fakeAuditLogRepo struct {
+	repositories.AuditLogRepository
+	entries []models.AuditLog
+}
+
+func (r *fakeAuditLogRepo) Create(entry *models.AuditLog) error {
+	entry.ID = uint(len(r.entries) + 1)
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+type fakeCommentRepo struct {
+	repositories.CommentRepository
+	comments     map[uint]*models.Comment
+	nextID       uint
+	summaryCalls [][]uint
+}
+
+func newFakeCommentRepo(comments ...*models.Comment) *fakeCommentRepo {
+	repo := &fakeCommentRepo{comments: make(map[uint]*models.Comment)}
+	for _, comment := range comments {
+		repo.Create(comment)
+	}
+	return repo
+}
+
+func (r *fakeCommentRepo) WithContext(ctx context.Context) repositories.CommentRepository {
+	return r
+}
+
+func (r *fakeCommentRepo) Create(comment *models.Comment) error {
+	if comment.ID == 0 {
+		r.nextID++
+		comment.ID = r.nextID
+	} else if comment.ID > r.nextID {
+		r.nextID = comment.ID
+	}
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+	stored := *comment
+	r.comments[comment.ID] = &stored
+	return nil
+}
+
+func (r *fakeCommentRepo) GetByID(id uint) (*models.Comment, error) {
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *comment
+	return &copied, nil
+}
+
+func (r *fakeCommentRepo) Update(comment *models.Comment) error {
+	if _, ok := r.comments[comment.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored := *comment
+	r.comments[comment.ID] = &stored
+	return nil
+}
+
+// postComments lists a post's comments with the status, or all of them,
+// oldest first
+func (r *fakeCommentRepo) postComments(postID uint, status string) []models.Comment {
+	var comments []models.Comment
+	for _, comment := range r.comments {
+		if comment.PostID == postID && (status == "" || comment.Status == status) {
+			comments = append(comments, *comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+	return comments
+}
+
+func (r *fakeCommentRepo) GetThreaded(postID uint, status string) ([]*models.CommentNode, error) {
+	return models.BuildCommentTree(r.postComments(postID, status)), nil
+}
+
+// GetTree lists a post's comments with parents before their replies
+func (r *fakeCommentRepo) GetTree(postID uint) ([]models.Comment, error) {
+	comments := r.postComments(postID, "")
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].Depth < comments[j].Depth })
+	return comments, nil
+}
+
+// GetByPost lists a post's comments with the status, or all of them,
+// pinned first, then oldest first
+func (r *fakeCommentRepo) GetByPost(postID uint, status string, page, perPage int) ([]models.Comment, int64, error) {
+	thread := r.postComments(postID, "")
+	var comments []models.Comment
+	for _, comment := range thread {
+		if status == "" || comment.Status == status {
+			comments = append(comments, comment)
+		}
+	}
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].Pinned && !comments[j].Pinned })
+
+	total := int64(len(comments))
+	start := (page - 1) * perPage
+	if start > len(comments) {
+		start = len(comments)
+	}
+	end := start + perPage
+	if end > len(comments) {
+		end = len(comments)
+	}
+	return comments[start:end], total, nil
+}
+
+func (r *fakeCommentRepo) GetApprovedByPost(postID uint, page, perPage int) ([]models.Comment, int64, error) {
+	return r.GetByPost(postID, "approved", page, perPage)
+}
+
+// ListPending returns every pending comment oldest first, ignoring paging
+func (r *fakeCommentRepo) ListPending(page, perPage int) ([]models.Comment, int64, error) {
+	var comments []models.Comment
+	for _, comment := range r.comments {
+		if comment.Status == "pending" {
+			comments = append(comments, *comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].CreatedAt.Before(comments[j].CreatedAt) })
+	return comments, int64(len(comments)), nil
+}
+
+func (r *fakeCommentRepo) Moderate(id uint, status string, moderatorID uint) error {
+	comment, ok := r.comments[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	comment.Status = status
+	comment.ModeratedBy = &moderatorID
+	comment.ModeratedAt = &now
+	return nil
+}
+
+func (r *fakeCommentRepo) Pin(postID, id uint) error {
+	if _, ok := r.comments[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for _, comment := range r.comments {
+		if comment.PostID == postID {
+			comment.Pinned = comment.ID == id
+		}
+	}
+	return nil
+}
+
+func (r *fakeCommentRepo) Unpin(id uint) error {
+	comment, ok := r.comments[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	comment.Pinned = false
+	return nil
+}
+
+// GetRecent returns approved comments newest first. It has no post data, so
+// unlike the real query it does not check the post is published.
+func (r *fakeCommentRepo) GetRecent(limit int) ([]models.RecentComment, error) {
+	var comments []models.RecentComment
+	for _, comment := range r.comments {
+		if comment.Status == "approved" {
+			comments = append(comments, models.RecentComment{ID: comment.ID, Content: comment.Content})
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID > comments[j].ID })
+	if len(comments) > limit {
+		comments = comments[:limit]
+	}
+	return comments, nil
+}
+
+// GetSummaries counts approved comments per post, taking the highest ID as
+// the newest. Like GetRecent it does not check the post is published.
+func (r *fakeCommentRepo) GetSummaries(postIDs []uint) (map[uint]models.PostCommentSummary, error) {
+	r.summaryCalls = append(r.summaryCalls, postIDs)
+	summaries := make(map[uint]models.PostCommentSummary)
+	for _, postID := range postIDs {
+		for _, comment := range r.comments {
+			if comment.PostID != postID || comment.Status != "approved" {
+				continue
+			}
+			summary := summaries[postID]
+			summary.PostID = postID
+			summary.Count++
+			if summary.Latest == nil || comment.ID > summary.Latest.ID {
+				summary.Latest = &models.LatestComment{ID: comment.ID, CreatedAt: comment.CreatedAt, Author: models.CommentAuthor{ID: comment.UserID}}
+			}
+			summaries[postID] = summary
+		}
+	}
+	return summaries, nil
+}
+
+type fakeUserRepo struct {
+	repositories.UserRepository
+	users  map[uint]*models.User
+	nextID uint
+}
+
+func newFakeUserRepo(users ...*models.User) *fakeUserRepo {
+	repo := &fakeUserRepo{users: make(map[uint]*models.User)}
+	for _, user := range users {
+		repo.Create(user)
+	}
+	return repo
+}
+
+func (r *fakeUserRepo) WithContext(ctx context.Context) repositories.UserRepository {
+	return r
+}
+
+func (r *fakeUserRepo) Create(user *models.User) error {
+	if user.ID == 0 {
+		r.nextID++
+		user.ID = r.nextID
+	} else if user.ID > r.nextID {
+		r.nextID = user.ID
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(id uint) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) GetByUsername(username string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) GetByVerificationToken(token string) (*models.User, error) {
+	for _, user := range r.users {
+		if token != "" && user.VerificationToken == token {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) Update(user *models.User) error {
+	if _, ok := r.users[user.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+// fakeJWTService hashes passwords reversibly so tests stay fast
+type fakeJWTService struct {
+	JWTService
+}
+
+func (fakeJWTService) HashPassword(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (fakeJWTService) CheckPassword(password, hash string) bool {
+	return hash == "hashed:"+password
+}
+
+func (fakeJWTService) VerifyPassword(password, hash string) (bool, bool) {
+	return hash == "hashed:"+password, false
+}
+
+// fakeRefreshTokenRepo accepts refresh tokens without storing them
+type fakeRefreshTokenRepo struct {
+	repositories.RefreshTokenRepository
+}
+
+func (fakeRefreshTokenRepo) Create(token *models.RefreshToken) error {
+	return nil
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func stringPtr(v string) *string {
+	return &v
+}
+
+type fakeNotificationRepo struct {
+	repositories.NotificationRepository
+	mu            sync.Mutex
+	notifications []*models.Notification
+}
+
+func newFakeNotificationRepo() *fakeNotificationRepo {
+	return &fakeNotificationRepo{}
+}
+
+func (r *fakeNotificationRepo) Create(notification *models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notification.ID = uint(len(r.notifications) + 1)
+	notification.CreatedAt = time.Now()
+	stored := *notification
+	r.notifications = append(r.notifications, &stored)
+	return nil
+}
+
+func (r *fakeNotificationRepo) ListByUser(userID uint, read *bool, page, perPage int) ([]models.Notification, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matches []models.Notification
+	for i := len(r.notifications) - 1; i >= 0; i-- {
+		notification := r.notifications[i]
+		if notification.UserID == userID && (read == nil || notification.Read == *read) {
+			matches = append(matches, *notification)
+		}
+	}
+	return matches, int64(len(matches)), nil
+}
+
+func (r *fakeNotificationRepo) CountUnread(userID uint) (int64, error) {
+	unread := false
+	_, count, err := r.ListByUser(userID, &unread, 1, 0)
+	return count, err
+}
+
+func (r *fakeNotificationRepo) MarkRead(userID, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, notification := range r.notifications {
+		if notification.ID == id && notification.UserID == userID {
+			notification.Read = true
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeNotificationRepo) MarkAllRead(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, notification := range r.notifications {
+		if notification.UserID == userID {
+			notification.Read = true
+		}
+	}
+	return nil
+}
+
+// fakeFileUploadRepo records uploads in memory, failing Create with err when
+// it is set
+type fakeFileUploadRepo struct {
+	repositories.FileUploadRepository
+	uploads []*models.FileUpload
+	err     error
+}
+
+func (r *fakeFileUploadRepo) Create(upload *models.FileUpload) error {
+	if r.err != nil {
+		return r.err
+	}
+	upload.ID = uint(len(r.uploads) + 1)
+	r.uploads = append(r.uploads, upload)
+	return nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/google/uuid"
+	"google.golang.org/api/option"
+)
+
+// gcsAPI is the subset of bucket operations the storage service uses, so
+// tests can substitute a fake for a real bucket
+type gcsAPI interface {
+	Upload(ctx context.Context, object, contentType string, body io.Reader) error
+	// Delete removes an object, succeeding when it does not exist
+	Delete(ctx context.Context, object string) error
+	Attrs(ctx context.Context) error
+}
+
+// gcsBucket implements gcsAPI on a bucket of the GCS client
+type gcsBucket struct {
+	bucket *gcs.BucketHandle
+}
+
+func (b *gcsBucket) Upload(ctx context.Context, object, contentType string, body io.Reader) error {
+	w := b.bucket.Object(object).NewWriter(ctx)
+	w.ContentType = contentType
+	if _, err := io.Copy(w, body); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (b *gcsBucket) Delete(ctx context.Context, object string) error {
+	err := b.bucket.Object(object).Delete(ctx)
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (b *gcsBucket) Attrs(ctx context.Context) error {
+	_, err := b.bucket.Attrs(ctx)
+	return err
+}
+
+// GCSStorageService stores uploads in a Google Cloud Storage bucket. Objects
+// are served from PublicBaseURL, so the bucket must grant public read access.
+type GCSStorageService struct {
+	client     gcsAPI
+	config     *config.StorageConfig
+	uploadRepo repositories.FileUploadRepository
+}
+
+// NewGCSStorageService connects to the configured bucket. STORAGE_EMULATOR_HOST
+// points the client at an emulator instead, as with other GCS tools.
+func NewGCSStorageService(cfg *config.StorageConfig, uploadRepo repositories.FileUploadRepository) (*GCSStorageService, error) {
+	if cfg.GCSBucket == "" {
+		return nil, errors.New("GCS_BUCKET_NAME is required when using GCS storage")
+	}
+
+	var opts []option.ClientOption
+	if cfg.GCSCredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(cfg.GCSCredentialsFile))
+	}
+
+	client, err := gcs.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+
+	return newGCSStorageService(&gcsBucket{bucket: client.Bucket(cfg.GCSBucket)}, cfg, uploadRepo), nil
+}
+
+func newGCSStorageService(client gcsAPI, cfg *config.StorageConfig, uploadRepo repositories.FileUploadRepository) *GCSStorageService {
+	return &GCSStorageService{
+		client:     client,
+		config:     cfg,
+		uploadRepo: uploadRepo,
+	}
+}
+
+func (s *GCSStorageService) UploadFile(fileHeader *multipart.FileHeader, userID uint) (*models.UploadResponse, error) {
+	// Validate file
+	if err := s.ValidateImageFile(fileHeader); err != nil {
+		return nil, err
+	}
+
+	// Generate unique filename
+	ext := filepath.Ext(fileHeader.Filename)
+	filename := fmt.Sprintf("images/%d/%s_%d%s", userID, uuid.New().String(), time.Now().Unix(), ext)
+
+	// Open uploaded file
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	if err := s.client.Upload(context.Background(), filename, fileHeader.Header.Get("Content-Type"), src); err != nil {
+		return nil, fmt.Errorf("failed to upload file to GCS: %w", err)
+	}
+
+	// Generate public URL
+	url := s.GetFileURL(filename)
+
+	thumbnailURL, err := s.storeThumbnail(fileHeader, filename)
+	if err != nil {
+		s.DeleteFile(filename)
+		return nil, err
+	}
+
+	// Don't keep objects the database doesn't know about
+	if err := recordUpload(s.uploadRepo, fileHeader, filename, filename, url, userID); err != nil {
+		s.DeleteFile(filename)
+		return nil, err
+	}
+
+	return &models.UploadResponse{
+		Success:      true,
+		Message:      "File uploaded successfully",
+		Filename:     filename,
+		URL:          url,
+		ThumbnailURL: thumbnailURL,
+		Size:         fileHeader.Size,
+		MimeType:     fileHeader.Header.Get("Content-Type"),
+	}, nil
+}
+
+// storeThumbnail uploads a thumbnail of the upload next to it and returns
+// its URL, or the upload's own URL when no thumbnail is needed
+func (s *GCSStorageService) storeThumbnail(fileHeader *multipart.FileHeader, filename string) (string, error) {
+	name := thumbnailName(filename)
+	data, err := makeThumbnail(fileHeader, name, s.config.ThumbnailWidth)
+	if err != nil || data == nil {
+		return s.GetFileURL(filename), err
+	}
+
+	if err := s.client.Upload(context.Background(), name, thumbnailMimeType(name), bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("failed to upload thumbnail to GCS: %w", err)
+	}
+	return s.GetFileURL(name), nil
+}
+
+// DeleteFile removes an upload along with its thumbnail, if it has one
+func (s *GCSStorageService) DeleteFile(filename string) error {
+	for _, object := range []string{thumbnailName(filename), filename} {
+		if err := s.client.Delete(context.Background(), object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *GCSStorageService) GetFileURL(filename string) string {
+	return s.config.PublicBaseURL() + "/" + filename
+}
+
+func (s *GCSStorageService) ValidateImageFile(fileHeader *multipart.FileHeader) error {
+	// Reuse the same validation logic as local storage
+	localStorage := &LocalStorageService{config: s.config}
+	return localStorage.ValidateImageFile(fileHeader)
+}
+
+func (s *GCSStorageService) HealthCheck(ctx context.Context) error {
+	if err := s.client.Attrs(ctx); err != nil {
+		return fmt.Errorf("bucket %s is not reachable: %w", s.config.GCSBucket, err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGCSBucket keeps objects in memory, like a bucket would
+type fakeGCSBucket struct {
+	objects      map[string][]byte
+	contentTypes map[string]string
+	deleted      []string
+	err          error
+}
+
+func newFakeGCSBucket() *fakeGCSBucket {
+	return &fakeGCSBucket{objects: make(map[string][]byte), contentTypes: make(map[string]string)}
+}
+
+func (b *fakeGCSBucket) Upload(ctx context.Context, object, contentType string, body io.Reader) error {
+	if b.err != nil {
+		return b.err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	b.objects[object] = data
+	b.contentTypes[object] = contentType
+	return nil
+}
+
+func (b *fakeGCSBucket) Delete(ctx context.Context, object string) error {
+	b.deleted = append(b.deleted, object)
+	delete(b.objects, object)
+	return nil
+}
+
+func (b *fakeGCSBucket) Attrs(ctx context.Context) error {
+	return b.err
+}
+
+func testGCSConfig() *config.StorageConfig {
+	return &config.StorageConfig{
+		Driver:      "gcs",
+		GCSBucket:   "blog-media",
+		MaxFileSize: 1024 * 1024,
+	}
+}
+
+func TestGCSStorageService_UploadFile(t *testing.T) {
+	bucket := newFakeGCSBucket()
+	storage := newGCSStorageService(bucket, testGCSConfig(), nil)
+
+	resp, err := storage.UploadFile(newImageHeader(t, "photo.png", "image/png", []byte("png-bytes")), 7)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(resp.Filename, "images/7/"))
+	assert.True(t, strings.HasSuffix(resp.Filename, ".png"))
+	assert.Equal(t, []byte("png-bytes"), bucket.objects[resp.Filename])
+	assert.Equal(t, "image/png", bucket.contentTypes[resp.Filename])
+	assert.Equal(t, "https://storage.googleapis.com/blog-media/"+resp.Filename, resp.URL)
+
+	t.Run("a configured base URL is used instead", func(t *testing.T) {
+		cfg := testGCSConfig()
+		cfg.GCSBaseURL = "https://media.example.com"
+		storage := newGCSStorageService(newFakeGCSBucket(), cfg, nil)
+
+		resp, err := storage.UploadFile(newImageHeader(t, "photo.png", "image/png", []byte("png-bytes")), 7)
+		require.NoError(t, err)
+		assert.Equal(t, "https://media.example.com/"+resp.Filename, resp.URL)
+	})
+
+	t.Run("files that are not images are refused before uploading", func(t *testing.T) {
+		bucket := newFakeGCSBucket()
+		storage := newGCSStorageService(bucket, testGCSConfig(), nil)
+
+		_, err := storage.UploadFile(newImageHeader(t, "notes.txt", "text/plain", []byte("hello")), 7)
+
+		assert.ErrorIs(t, err, ErrInvalidImage)
+		assert.Empty(t, bucket.objects)
+	})
+
+	t.Run("the object is removed when the upload cannot be recorded", func(t *testing.T) {
+		bucket := newFakeGCSBucket()
+		storage := newGCSStorageService(bucket, testGCSConfig(), &fakeFileUploadRepo{err: errors.New("database is down")})
+
+		resp, err := storage.UploadFile(newImageHeader(t, "photo.png", "image/png", []byte("png-bytes")), 7)
+
+		assert.Nil(t, resp)
+		assert.Error(t, err)
+		assert.Empty(t, bucket.objects)
+	})
+}
+
+func TestGCSStorageService_UploadFileError(t *testing.T) {
+	bucket := newFakeGCSBucket()
+	bucket.err = errors.New("permission denied")
+	storage := newGCSStorageService(bucket, testGCSConfig(), nil)
+
+	resp, err := storage.UploadFile(newImageHeader(t, "photo.jpg", "image/jpeg", []byte("jpeg-bytes")), 7)
+
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, bucket.err)
+}
+
+func TestGCSStorageService_DeleteFile(t *testing.T) {
+	bucket := newFakeGCSBucket()
+	bucket.objects["images/7/photo.png"] = []byte("png-bytes")
+	bucket.objects["images/7/photo_thumb.png"] = []byte("thumb-bytes")
+	storage := newGCSStorageService(bucket, testGCSConfig(), nil)
+
+	require.NoError(t, storage.DeleteFile("images/7/photo.png"))
+
+	assert.Empty(t, bucket.objects)
+	assert.Equal(t, []string{"images/7/photo_thumb.png", "images/7/photo.png"}, bucket.deleted)
+}
+
+func TestNewGCSStorageService_RequiresBucket(t *testing.T) {
+	cfg := testGCSConfig()
+	cfg.GCSBucket = ""
+
+	storage, err := NewGCSStorageService(cfg, nil)
+
+	assert.Nil(t, storage)
+	assert.Error(t, err)
+}
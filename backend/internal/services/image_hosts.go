@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrImageURLNotAllowed is returned when a post's thumbnail URL is neither a
+// path to an uploaded file nor an http(s) URL on an allowed host
+var ErrImageURLNotAllowed = errors.New("image URL must be an uploaded file or on an allowed host")
+
+// uploadPaths are the routes uploaded files are served from. Relative image
+// URLs must point under one of them.
+var uploadPaths = []string{"/uploads/", "/api/v1/uploads/"}
+
+// ImageHosts decides which image URLs posts may reference
+type ImageHosts struct {
+	hosts map[string]bool
+}
+
+// NewImageHosts allows images on the given hosts, with or without a port,
+// and on the hosts of the given base URLs. Empty entries are ignored.
+func NewImageHosts(hosts []string, baseURLs ...string) *ImageHosts {
+	allowed := make(map[string]bool)
+	for _, host := range hosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			allowed[host] = true
+		}
+	}
+	for _, baseURL := range baseURLs {
+		if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+			allowed[strings.ToLower(u.Host)] = true
+		}
+	}
+	return &ImageHosts{hosts: allowed}
+}
+
+// Check returns ErrImageURLNotAllowed unless rawURL is empty, a clean path
+// under an upload route, or an http(s) URL on an allowed host
+func (h *ImageHosts) Check(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrImageURLNotAllowed
+	}
+
+	if u.Scheme == "" && u.Host == "" {
+		// Reject traversal such as /uploads/../admin
+		if path.Clean(u.Path) != u.Path {
+			return ErrImageURLNotAllowed
+		}
+		for _, prefix := range uploadPaths {
+			if strings.HasPrefix(u.Path, prefix) {
+				return nil
+			}
+		}
+		return ErrImageURLNotAllowed
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrImageURLNotAllowed
+	}
+	host := strings.ToLower(u.Host)
+	if h.hosts[host] || h.hosts[strings.ToLower(u.Hostname())] {
+		return nil
+	}
+	return ErrImageURLNotAllowed
+}
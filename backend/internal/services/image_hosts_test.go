@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageHosts(t *testing.T) {
+	hosts := NewImageHosts([]string{"images.example.org"}, "http://localhost:8080/uploads", "https://cdn.example.com")
+
+	allowed := []string{
+		"",
+		"http://localhost:8080/uploads/a.png",
+		"https://cdn.example.com/a.png",
+		"https://IMAGES.example.org:8443/a.png",
+		"/uploads/a.png",
+		"/api/v1/uploads/a.png",
+	}
+	for _, url := range allowed {
+		assert.NoError(t, hosts.Check(url), url)
+	}
+
+	rejected := []string{
+		"https://evil.example.net/a.png",
+		"https://cdn.example.com@evil.example.net/a.png",
+		"//evil.example.net/uploads/a.png",
+		"http://localhost:9090/uploads/a.png",
+		"javascript:alert(1)",
+		"data:image/png;base64,AAAA",
+		"ftp://cdn.example.com/a.png",
+		"/admin/a.png",
+		"/uploads/../admin",
+		"uploads/a.png",
+	}
+	for _, url := range rejected {
+		assert.ErrorIs(t, hosts.Check(url), ErrImageURLNotAllowed, url)
+	}
+}
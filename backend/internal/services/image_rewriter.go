@@ -0,0 +1,73 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"backend/internal/models"
+)
+
+var (
+	// markdownImagePattern matches ![alt](url "title"), capturing everything
+	// up to the URL and the URL itself
+	markdownImagePattern = regexp.MustCompile(`(!\[[^\]]*\]\(\s*<?)([^\s)>]+)`)
+	// htmlImagePattern matches the src attribute of an <img> tag, quoted or
+	// not, capturing everything up to the URL and the URL itself
+	htmlImagePattern = regexp.MustCompile(`(?i)(<img\b[^>]*?\ssrc\s*=\s*["']?)([^"'\s>]+)`)
+)
+
+// ImageRewriter points image references at uploaded files to a CDN. Only
+// URLs under the storage origin are touched, so external images are left
+// alone, and rewriting already rewritten content changes nothing.
+//
+// A nil *ImageRewriter leaves content unchanged, which is how the rewriter
+// is disabled.
+type ImageRewriter struct {
+	origin string
+	cdn    string
+}
+
+// NewImageRewriter rewrites URLs under origin to the same path under cdn.
+// It returns nil, disabling rewriting, when cdn is empty or equal to origin.
+func NewImageRewriter(origin, cdn string) *ImageRewriter {
+	origin = strings.TrimRight(origin, "/")
+	cdn = strings.TrimRight(cdn, "/")
+	if cdn == "" || origin == "" || cdn == origin {
+		return nil
+	}
+	return &ImageRewriter{origin: origin + "/", cdn: cdn + "/"}
+}
+
+// Rewrite returns content with markdown images and <img src> attributes
+// that point at the origin pointed at the CDN instead
+func (r *ImageRewriter) Rewrite(content string) string {
+	if r == nil || !strings.Contains(content, r.origin) {
+		return content
+	}
+
+	replace := func(pattern *regexp.Regexp, content string) string {
+		return pattern.ReplaceAllStringFunc(content, func(match string) string {
+			parts := pattern.FindStringSubmatch(match)
+			return parts[1] + r.RewriteURL(parts[2])
+		})
+	}
+	return replace(htmlImagePattern, replace(markdownImagePattern, content))
+}
+
+// RewriteURL rewrites a single URL if it points at the origin
+func (r *ImageRewriter) RewriteURL(url string) string {
+	// The CDN may itself live under the origin
+	if r == nil || !strings.HasPrefix(url, r.origin) || strings.HasPrefix(url, r.cdn) {
+		return url
+	}
+	return r.cdn + strings.TrimPrefix(url, r.origin)
+}
+
+// RewritePost rewrites the images in a post's content and its thumbnail
+func (r *ImageRewriter) RewritePost(post *models.Post) {
+	if r == nil || post == nil {
+		return
+	}
+	post.Content = r.Rewrite(post.Content)
+	post.ThumbnailURL = r.RewriteURL(post.ThumbnailURL)
+}
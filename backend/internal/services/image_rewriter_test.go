@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageRewriter(t *testing.T) {
+	rewriter := NewImageRewriter("https://blog.example.com/uploads", "https://cdn.example.com/")
+
+	t.Run("markdown images at the origin move to the CDN", func(t *testing.T) {
+		content := `Intro ![diagram](https://blog.example.com/uploads/a.png "Diagram") and ![](<https://blog.example.com/uploads/b.jpg>)`
+
+		assert.Equal(t,
+			`Intro ![diagram](https://cdn.example.com/a.png "Diagram") and ![](<https://cdn.example.com/b.jpg>)`,
+			rewriter.Rewrite(content))
+	})
+
+	t.Run("html images at the origin move to the CDN", func(t *testing.T) {
+		content := `<p><img alt="x" src="https://blog.example.com/uploads/a.png"> <IMG SRC='https://blog.example.com/uploads/b.png' /></p>`
+
+		assert.Equal(t,
+			`<p><img alt="x" src="https://cdn.example.com/a.png"> <IMG SRC='https://cdn.example.com/b.png' /></p>`,
+			rewriter.Rewrite(content))
+	})
+
+	t.Run("external images and plain links are untouched", func(t *testing.T) {
+		content := `![cat](https://images.example.org/cat.png) <img src="https://blog.example.com/uploads-old/a.png"> ` +
+			`[download](https://blog.example.com/uploads/a.png)`
+
+		assert.Equal(t, content, rewriter.Rewrite(content))
+	})
+
+	t.Run("rewriting is idempotent", func(t *testing.T) {
+		once := rewriter.Rewrite(`![a](https://blog.example.com/uploads/a.png) <img src="https://blog.example.com/uploads/b.png">`)
+
+		assert.Equal(t, once, rewriter.Rewrite(once))
+	})
+
+	t.Run("a CDN path under the origin is not rewritten twice", func(t *testing.T) {
+		nested := NewImageRewriter("https://blog.example.com", "https://blog.example.com/cdn")
+		once := nested.Rewrite(`![a](https://blog.example.com/a.png)`)
+
+		assert.Equal(t, `![a](https://blog.example.com/cdn/a.png)`, once)
+		assert.Equal(t, once, nested.Rewrite(once))
+	})
+
+	t.Run("no CDN disables rewriting", func(t *testing.T) {
+		disabled := NewImageRewriter("https://blog.example.com/uploads", "")
+		content := `![a](https://blog.example.com/uploads/a.png)`
+
+		assert.Nil(t, disabled)
+		assert.Equal(t, content, disabled.Rewrite(content))
+	})
+}
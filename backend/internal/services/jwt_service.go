@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
@@ -8,10 +9,14 @@ import (
 	"strconv"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/repositories"
+	"backend/pkg/logger"
+	"backend/pkg/utils"
 
 	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -21,9 +26,21 @@ type JWTService interface {
 	ValidateRefreshToken(tokenString string) (*models.JWTClaims, error)
 	RefreshAccessToken(refreshToken string) (*models.RefreshTokenResponse, error)
 	RevokeRefreshToken(tokenString string) error
+	// RevokeAllUserTokens revokes every refresh token of the user and denies
+	// the access tokens issued with them that have not expired yet
 	RevokeAllUserTokens(userID uint) error
+	// RevokeAccessToken denies the access token with the given claims until
+	// it expires, so it is rejected before its natural expiry
+	RevokeAccessToken(claims *models.JWTClaims) error
+	// PurgeRevokedAccessTokens forgets revoked access tokens that have
+	// expired since
+	PurgeRevokedAccessTokens(ctx context.Context) error
 	HashPassword(password string) (string, error)
 	CheckPassword(password, hash string) bool
+	// VerifyPassword checks a password like CheckPassword and also reports
+	// whether the hash was made with the previous pepper and should be
+	// replaced with a fresh one from HashPassword
+	VerifyPassword(password, hash string) (ok bool, needsRehash bool)
 }
 
 type jwtService struct {
@@ -31,9 +48,14 @@ type jwtService struct {
 	accessTokenDuration  time.Duration
 	refreshTokenDuration time.Duration
 	refreshTokenRepo     repositories.RefreshTokenRepository
+	revokedTokenRepo     repositories.RevokedAccessTokenRepository
+	pepper               string
+	previousPepper       string
 }
 
-func NewJWTService(refreshTokenRepo repositories.RefreshTokenRepository) JWTService {
+// NewJWTService creates the token service. revokedTokenRepo may be nil, in
+// which case access tokens cannot be revoked and stay valid until they expire.
+func NewJWTService(refreshTokenRepo repositories.RefreshTokenRepository, revokedTokenRepo repositories.RevokedAccessTokenRepository, cfg *config.Config) JWTService {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "your-super-secret-jwt-key-change-this-in-production"
@@ -58,12 +80,21 @@ func NewJWTService(refreshTokenRepo repositories.RefreshTokenRepository) JWTServ
 		accessTokenDuration:  accessDuration,
 		refreshTokenDuration: refreshDuration,
 		refreshTokenRepo:     refreshTokenRepo,
+		revokedTokenRepo:     revokedTokenRepo,
+		pepper:               cfg.Auth.PasswordPepper,
+		previousPepper:       cfg.Auth.PreviousPasswordPepper,
 	}
 }
 
 func (s *jwtService) GenerateTokenPair(user *models.User) (*models.AuthResponse, error) {
 	now := time.Now()
-	
+
+	// A unique jti lets this access token be revoked on its own
+	jti, err := s.generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate access token
 	accessClaims := &models.JWTClaims{
 		UserID:   user.ID,
@@ -73,6 +104,7 @@ func (s *jwtService) GenerateTokenPair(user *models.User) (*models.AuthResponse,
 		Type:     "access",
 		IssuedAt: now.Unix(),
 		ExpiresAt: now.Add(s.accessTokenDuration).Unix(),
+		ID:       jti,
 	}
 
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
@@ -83,6 +115,7 @@ func (s *jwtService) GenerateTokenPair(user *models.User) (*models.AuthResponse,
 		"type":     accessClaims.Type,
 		"iat":      accessClaims.IssuedAt,
 		"exp":      accessClaims.ExpiresAt,
+		"jti":      accessClaims.ID,
 	})
 
 	accessTokenString, err := accessToken.SignedString(s.secretKey)
@@ -97,13 +130,16 @@ func (s *jwtService) GenerateTokenPair(user *models.User) (*models.AuthResponse,
 	}
 
 	// Store refresh token in database
+	accessExpiresAt := time.Unix(accessClaims.ExpiresAt, 0)
 	refreshToken := &models.RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshTokenString,
-		ExpiresAt: now.Add(s.refreshTokenDuration),
-		CreatedAt: now,
-		UpdatedAt: now,
-		IsRevoked: false,
+		UserID:          user.ID,
+		Token:           refreshTokenString,
+		ExpiresAt:       now.Add(s.refreshTokenDuration),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		IsRevoked:       false,
+		AccessJTI:       jti,
+		AccessExpiresAt: &accessExpiresAt,
 	}
 
 	if err := s.refreshTokenRepo.Create(refreshToken); err != nil {
@@ -174,6 +210,20 @@ func (s *jwtService) ValidateAccessToken(tokenString string) (*models.JWTClaims,
 	if exp, ok := claims["exp"].(float64); ok {
 		jwtClaims.ExpiresAt = int64(exp)
 	}
+	if jti, ok := claims["jti"].(string); ok {
+		jwtClaims.ID = jti
+	}
+
+	// Tokens issued before jti was added cannot have been revoked
+	if s.revokedTokenRepo != nil && jwtClaims.ID != "" {
+		revoked, err := s.revokedTokenRepo.IsRevoked(jwtClaims.ID)
+		if err != nil {
+			return nil, errors.New("failed to check token revocation")
+		}
+		if revoked {
+			return nil, errors.New("token has been revoked")
+		}
+	}
 
 	return jwtClaims, nil
 }
@@ -240,15 +290,76 @@ func (s *jwtService) RevokeRefreshToken(tokenString string) error {
 }
 
 func (s *jwtService) RevokeAllUserTokens(userID uint) error {
-	return s.refreshTokenRepo.RevokeAllUserTokens(userID)
+	if err := s.refreshTokenRepo.RevokeAllUserTokens(userID); err != nil {
+		return err
+	}
+	if s.revokedTokenRepo == nil {
+		return nil
+	}
+
+	// Refresh tokens rotated out earlier are already revoked, but the access
+	// tokens issued with them may still be live
+	tokens, err := s.refreshTokenRepo.GetWithLiveAccessTokens(userID, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if err := s.revokedTokenRepo.Create(&models.RevokedAccessToken{
+			JTI:       token.AccessJTI,
+			UserID:    userID,
+			ExpiresAt: *token.AccessExpiresAt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jwtService) RevokeAccessToken(claims *models.JWTClaims) error {
+	if s.revokedTokenRepo == nil || claims == nil || claims.ID == "" {
+		return nil
+	}
+	return s.revokedTokenRepo.Create(&models.RevokedAccessToken{
+		JTI:       claims.ID,
+		UserID:    claims.UserID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	})
+}
+
+func (s *jwtService) PurgeRevokedAccessTokens(ctx context.Context) error {
+	if s.revokedTokenRepo == nil {
+		return nil
+	}
+	purged, err := s.revokedTokenRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		logger.LogInfo(ctx, "Purged expired revoked access tokens", zap.Int64("count", purged))
+	}
+	return nil
 }
 
 func (s *jwtService) HashPassword(password string) (string, error) {
-	return utils.HashPassword(password)
+	return utils.HashPasswordWithPepper(password, s.pepper)
 }
 
 func (s *jwtService) CheckPassword(password, hash string) bool {
-	return utils.VerifyPassword(password, hash)
+	ok, _ := s.VerifyPassword(password, hash)
+	return ok
+}
+
+func (s *jwtService) VerifyPassword(password, hash string) (bool, bool) {
+	if utils.VerifyPasswordWithPepper(password, hash, s.pepper) {
+		return true, false
+	}
+
+	// Fall back to the previous pepper only while a rotation is in progress
+	if s.previousPepper == s.pepper {
+		return false, false
+	}
+	ok := utils.VerifyPasswordWithPepper(password, hash, s.previousPepper)
+	return ok, ok
 }
 
 func (s *jwtService) generateSecureToken() (string, error) {
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/models"
+	"backend/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ErrAccountLocked is returned by Login while an account is locked after too
+// many wrong passwords
+var ErrAccountLocked = errors.New("account temporarily locked")
+
+// isLocked reports whether the user's account is locked right now
+func (s *authService) isLocked(user *models.User) bool {
+	return user.LockedUntil != nil && user.LockedUntil.After(s.now())
+}
+
+// recordFailedLogin counts a wrong password against the user and locks the
+// account once maxLoginFailures have been made within the lockout window.
+// It reports whether the account is now locked. The count is kept by the
+// database, so concurrent attempts cannot undercount. Failing to record it
+// is logged rather than returned; it must not change the login's outcome.
+func (s *authService) recordFailedLogin(user *models.User) bool {
+	if s.maxLoginFailures <= 0 || s.lockout <= 0 {
+		return false
+	}
+
+	now := s.now()
+	failures, err := s.userRepo.IncrementFailedLogins(user.ID, now, s.lockout)
+	if err != nil {
+		logger.LogError(context.Background(), "Failed to record failed login", err,
+			zap.Uint("user_id", user.ID),
+		)
+		return false
+	}
+	if failures < s.maxLoginFailures {
+		return false
+	}
+
+	lockedUntil := now.Add(s.lockout)
+	if err := s.userRepo.LockAccount(user.ID, lockedUntil); err != nil {
+		logger.LogError(context.Background(), "Failed to lock account", err,
+			zap.Uint("user_id", user.ID),
+		)
+		return false
+	}
+	logger.LogInfo(context.Background(), "Account locked after failed logins",
+		zap.Uint("user_id", user.ID),
+		zap.Time("locked_until", lockedUntil),
+	)
+	return true
+}
+
+// clearFailedLogins forgets earlier wrong passwords after a successful login
+func (s *authService) clearFailedLogins(user *models.User) {
+	if user.FailedLoginCount == 0 && user.LastFailedLoginAt == nil && user.LockedUntil == nil {
+		return
+	}
+	// Reset the loaded copy too, so a later save of it does not bring the
+	// failures back
+	user.FailedLoginCount = 0
+	user.LastFailedLoginAt = nil
+	user.LockedUntil = nil
+	if err := s.userRepo.ClearFailedLogins(user.ID); err != nil {
+		logger.LogError(context.Background(), "Failed to clear failed logins", err,
+			zap.Uint("user_id", user.ID),
+		)
+	}
+}
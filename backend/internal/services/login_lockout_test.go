@@ -0,0 +1,193 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// tokenIssuingJWTService hands back the user it was asked to sign in
+type tokenIssuingJWTService struct {
+	fakeJWTService
+}
+
+func (tokenIssuingJWTService) GenerateTokenPair(user *models.User) (*models.AuthResponse, error) {
+	return &models.AuthResponse{User: *user}, nil
+}
+
+func (r *fakeUserRepo) IncrementFailedLogins(id uint, now time.Time, window time.Duration) (int, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if user.LastFailedLoginAt == nil || user.LastFailedLoginAt.Before(now.Add(-window)) {
+		user.FailedLoginCount = 0
+	}
+	user.FailedLoginCount++
+	user.LastFailedLoginAt = &now
+	return user.FailedLoginCount, nil
+}
+
+func (r *fakeUserRepo) LockAccount(id uint, until time.Time) error {
+	user, ok := r.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	user.LockedUntil = &until
+	user.FailedLoginCount = 0
+	user.LastFailedLoginAt = nil
+	return nil
+}
+
+func (r *fakeUserRepo) ClearFailedLogins(id uint) error {
+	user, ok := r.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	user.LockedUntil = nil
+	user.FailedLoginCount = 0
+	user.LastFailedLoginAt = nil
+	return nil
+}
+
+// newLockoutService returns an auth service locking accounts after five
+// failures for fifteen minutes, with a user whose password is password123,
+// and a pointer to the service's clock
+func newLockoutService(t *testing.T) (AuthService, *fakeUserRepo, *time.Time) {
+	t.Helper()
+	userRepo := newFakeUserRepo()
+	require.NoError(t, userRepo.Create(&models.User{
+		Username: "reader",
+		Email:    "reader@example.com",
+		Password: "hashed:password123",
+		Role:     "author",
+	}))
+
+	cfg := &config.Config{Auth: config.AuthConfig{LoginMaxFailures: 5, LoginLockout: 15 * time.Minute}}
+	service := NewAuthService(userRepo, nil, tokenIssuingJWTService{}, cfg)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.(*authService).now = func() time.Time { return now }
+	return service, userRepo, &now
+}
+
+func loginAs(authService AuthService, password string) error {
+	_, err := authService.Login(&models.LoginRequest{Email: "reader@example.com", Password: password})
+	return err
+}
+
+func TestAuthService_LoginLockout(t *testing.T) {
+	t.Run("five failures lock the account", func(t *testing.T) {
+		authService, userRepo, _ := newLockoutService(t)
+
+		for i := 0; i < 4; i++ {
+			err := loginAs(authService, "wrong")
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, ErrAccountLocked)
+		}
+		err := loginAs(authService, "wrong")
+
+		assert.ErrorIs(t, err, ErrAccountLocked)
+		require.NotNil(t, userRepo.users[1].LockedUntil)
+	})
+
+	t.Run("valid credentials are refused while locked", func(t *testing.T) {
+		authService, _, now := newLockoutService(t)
+		for i := 0; i < 5; i++ {
+			_ = loginAs(authService, "wrong")
+		}
+
+		*now = now.Add(14 * time.Minute)
+		err := loginAs(authService, "password123")
+
+		assert.ErrorIs(t, err, ErrAccountLocked)
+	})
+
+	t.Run("the lock expires on its own", func(t *testing.T) {
+		authService, userRepo, now := newLockoutService(t)
+		for i := 0; i < 5; i++ {
+			_ = loginAs(authService, "wrong")
+		}
+
+		*now = now.Add(16 * time.Minute)
+		err := loginAs(authService, "password123")
+
+		require.NoError(t, err)
+		assert.Nil(t, userRepo.users[1].LockedUntil)
+		assert.Zero(t, userRepo.users[1].FailedLoginCount)
+	})
+
+	t.Run("failures outside the window do not add up", func(t *testing.T) {
+		authService, _, now := newLockoutService(t)
+		for i := 0; i < 4; i++ {
+			_ = loginAs(authService, "wrong")
+		}
+
+		*now = now.Add(20 * time.Minute)
+		err := loginAs(authService, "wrong")
+
+		assert.NotErrorIs(t, err, ErrAccountLocked)
+	})
+
+	t.Run("a successful login resets the count", func(t *testing.T) {
+		authService, _, _ := newLockoutService(t)
+		for i := 0; i < 4; i++ {
+			_ = loginAs(authService, "wrong")
+		}
+		require.NoError(t, loginAs(authService, "password123"))
+
+		err := loginAs(authService, "wrong")
+
+		assert.NotErrorIs(t, err, ErrAccountLocked)
+	})
+}
+
+func TestUserRepository_IncrementFailedLogins(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	// The role enum does not migrate on SQLite, so only the columns the
+	// lockout touches are created
+	require.NoError(t, db.Exec(`CREATE TABLE users (
+		id integer PRIMARY KEY,
+		failed_login_count integer NOT NULL DEFAULT 0,
+		last_failed_login_at datetime,
+		locked_until datetime,
+		deleted_at datetime
+	)`).Error)
+	require.NoError(t, db.Exec("INSERT INTO users (id) VALUES (1)").Error)
+	userRepo := repositories.NewUserRepository(db)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	increment := func() int {
+		count, err := userRepo.IncrementFailedLogins(1, now, 15*time.Minute)
+		require.NoError(t, err)
+		return count
+	}
+
+	assert.Equal(t, 1, increment())
+	assert.Equal(t, 2, increment())
+
+	now = now.Add(20 * time.Minute)
+	assert.Equal(t, 1, increment(), "failures outside the window are forgotten")
+
+	require.NoError(t, userRepo.LockAccount(1, now.Add(15*time.Minute)))
+	user, err := userRepo.GetByID(1)
+	require.NoError(t, err)
+	require.NotNil(t, user.LockedUntil)
+	assert.Zero(t, user.FailedLoginCount)
+
+	require.NoError(t, userRepo.ClearFailedLogins(1))
+	user, err = userRepo.GetByID(1)
+	require.NoError(t, err)
+	assert.Nil(t, user.LockedUntil)
+
+	_, err = userRepo.IncrementFailedLogins(2, now, 15*time.Minute)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
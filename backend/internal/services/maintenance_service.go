@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/cache"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+	// ErrInvalidMaintenanceWindow is returned for a window that does not end
+	// after it starts
+	ErrInvalidMaintenanceWindow = errors.New("maintenance window must end after it starts")
+)
+
+// MaintenanceService schedules maintenance windows, during which writes are
+// refused
+type MaintenanceService interface {
+	List(page, perPage int) ([]models.MaintenanceWindow, int64, error)
+	GetByID(id uint) (*models.MaintenanceWindow, error)
+	Create(req *models.CreateMaintenanceWindowRequest) (*models.MaintenanceWindow, error)
+	Update(id uint, req *models.UpdateMaintenanceWindowRequest) (*models.MaintenanceWindow, error)
+	Delete(id uint) error
+
+	// Active returns the window under way now, or nil. When windows overlap
+	// it is the one that ends last.
+	Active() (*models.MaintenanceWindow, error)
+	// Upcoming returns the windows under way now or yet to come, soonest
+	// first
+	Upcoming() ([]models.MaintenanceWindow, error)
+}
+
+type maintenanceService struct {
+	windowRepo repositories.MaintenanceWindowRepository
+	// announcements holds the windows not yet ended, read on every write
+	// request by MaintenanceMiddleware. Windows only change through this
+	// service, which drops the cached list when they do.
+	announcements *cache.Map[struct{}, []models.MaintenanceWindow]
+	now           func() time.Time
+}
+
+// NewMaintenanceService registers its announcements cache with caches when
+// caches is not nil
+func NewMaintenanceService(windowRepo repositories.MaintenanceWindowRepository, caches *cache.Registry) MaintenanceService {
+	return &maintenanceService{
+		windowRepo:    windowRepo,
+		announcements: cache.NewMap[struct{}, []models.MaintenanceWindow](caches, "announcements"),
+		now:           time.Now,
+	}
+}
+
+func (s *maintenanceService) List(page, perPage int) ([]models.MaintenanceWindow, int64, error) {
+	return s.windowRepo.List(page, perPage)
+}
+
+func (s *maintenanceService) GetByID(id uint) (*models.MaintenanceWindow, error) {
+	window, err := s.windowRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMaintenanceWindowNotFound
+		}
+		return nil, err
+	}
+	return window, nil
+}
+
+func (s *maintenanceService) Create(req *models.CreateMaintenanceWindowRequest) (*models.MaintenanceWindow, error) {
+	window := &models.MaintenanceWindow{
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Message:  req.Message,
+	}
+	if !window.EndsAt.After(window.StartsAt) {
+		return nil, ErrInvalidMaintenanceWindow
+	}
+
+	if err := s.windowRepo.Create(window); err != nil {
+		return nil, err
+	}
+	s.announcements.Flush()
+	return window, nil
+}
+
+func (s *maintenanceService) Update(id uint, req *models.UpdateMaintenanceWindowRequest) (*models.MaintenanceWindow, error) {
+	window, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.StartsAt != nil {
+		window.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		window.EndsAt = *req.EndsAt
+	}
+	if req.Message != nil {
+		window.Message = *req.Message
+	}
+	if !window.EndsAt.After(window.StartsAt) {
+		return nil, ErrInvalidMaintenanceWindow
+	}
+
+	if err := s.windowRepo.Update(window); err != nil {
+		return nil, err
+	}
+	s.announcements.Flush()
+	return window, nil
+}
+
+func (s *maintenanceService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	if err := s.windowRepo.Delete(id); err != nil {
+		return err
+	}
+	s.announcements.Flush()
+	return nil
+}
+
+func (s *maintenanceService) Active() (*models.MaintenanceWindow, error) {
+	now := s.now()
+	windows, err := s.notEnded(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var active *models.MaintenanceWindow
+	for i := range windows {
+		window := &windows[i]
+		if window.StartsAt.After(now) {
+			continue
+		}
+		if active == nil || window.EndsAt.After(active.EndsAt) {
+			active = window
+		}
+	}
+	return active, nil
+}
+
+func (s *maintenanceService) Upcoming() ([]models.MaintenanceWindow, error) {
+	return s.notEnded(s.now())
+}
+
+// notEnded returns the windows under way or yet to come at now, soonest
+// first. The cached list may hold windows that have ended since it was
+// loaded, so they are filtered out here.
+func (s *maintenanceService) notEnded(now time.Time) ([]models.MaintenanceWindow, error) {
+	cached, err := s.announcements.GetOrLoad(struct{}{}, func() ([]models.MaintenanceWindow, error) {
+		return s.windowRepo.ListNotEnded(now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make([]models.MaintenanceWindow, 0, len(cached))
+	for _, window := range cached {
+		if window.EndsAt.After(now) {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
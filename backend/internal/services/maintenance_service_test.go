@@ -0,0 +1,174 @@
+package services
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeMaintenanceWindowRepo keeps maintenance windows in memory
+type fakeMaintenanceWindowRepo struct {
+	repositories.MaintenanceWindowRepository
+	windows []*models.MaintenanceWindow
+}
+
+func newFakeMaintenanceWindowRepo() *fakeMaintenanceWindowRepo {
+	return &fakeMaintenanceWindowRepo{}
+}
+
+func (r *fakeMaintenanceWindowRepo) Create(window *models.MaintenanceWindow) error {
+	window.ID = uint(len(r.windows) + 1)
+	stored := *window
+	r.windows = append(r.windows, &stored)
+	return nil
+}
+
+func (r *fakeMaintenanceWindowRepo) GetByID(id uint) (*models.MaintenanceWindow, error) {
+	for _, window := range r.windows {
+		if window.ID == id {
+			copied := *window
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeMaintenanceWindowRepo) Update(window *models.MaintenanceWindow) error {
+	for i, stored := range r.windows {
+		if stored.ID == window.ID {
+			updated := *window
+			r.windows[i] = &updated
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeMaintenanceWindowRepo) ListNotEnded(now time.Time) ([]models.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	for _, window := range r.windows {
+		if window.EndsAt.After(now) {
+			windows = append(windows, *window)
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].StartsAt.Before(windows[j].StartsAt) })
+	return windows, nil
+}
+
+func newClockedMaintenanceService(now time.Time) MaintenanceService {
+	service := NewMaintenanceService(newFakeMaintenanceWindowRepo(), nil).(*maintenanceService)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestMaintenanceService_Active(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := newClockedMaintenanceService(now)
+
+	schedule := func(startsIn, endsIn time.Duration, message string) *models.MaintenanceWindow {
+		window, err := service.Create(&models.CreateMaintenanceWindowRequest{
+			StartsAt: now.Add(startsIn),
+			EndsAt:   now.Add(endsIn),
+			Message:  message,
+		})
+		require.NoError(t, err)
+		return window
+	}
+
+	t.Run("no window is active before one starts", func(t *testing.T) {
+		schedule(time.Hour, 2*time.Hour, "Database upgrade")
+		schedule(-2*time.Hour, -time.Hour, "Already over")
+
+		active, err := service.Active()
+		require.NoError(t, err)
+		assert.Nil(t, active)
+
+		upcoming, err := service.Upcoming()
+		require.NoError(t, err)
+		require.Len(t, upcoming, 1)
+		assert.Equal(t, "Database upgrade", upcoming[0].Message)
+	})
+
+	t.Run("overlapping windows report the one ending last", func(t *testing.T) {
+		schedule(-time.Minute, 10*time.Minute, "Short restart")
+		long := schedule(-time.Hour, 30*time.Minute, "Storage migration")
+
+		active, err := service.Active()
+		require.NoError(t, err)
+		require.NotNil(t, active)
+		assert.Equal(t, long.ID, active.ID)
+	})
+}
+
+func TestMaintenanceService_Validation(t *testing.T) {
+	now := time.Now()
+	service := newClockedMaintenanceService(now)
+
+	_, err := service.Create(&models.CreateMaintenanceWindowRequest{StartsAt: now, EndsAt: now, Message: "Nothing"})
+	assert.ErrorIs(t, err, ErrInvalidMaintenanceWindow)
+
+	window, err := service.Create(&models.CreateMaintenanceWindowRequest{StartsAt: now, EndsAt: now.Add(time.Hour), Message: "Upgrade"})
+	require.NoError(t, err)
+
+	earlier := now.Add(-time.Hour)
+	_, err = service.Update(window.ID, &models.UpdateMaintenanceWindowRequest{EndsAt: &earlier})
+	assert.ErrorIs(t, err, ErrInvalidMaintenanceWindow)
+
+	_, err = service.Update(window.ID+1, &models.UpdateMaintenanceWindowRequest{EndsAt: &earlier})
+	assert.ErrorIs(t, err, ErrMaintenanceWindowNotFound)
+}
+
+func TestMaintenanceService_AnnouncementsCache(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeMaintenanceWindowRepo()
+	registry := cache.NewRegistry()
+	service := NewMaintenanceService(repo, registry).(*maintenanceService)
+	service.now = func() time.Time { return now }
+
+	_, err := service.Create(&models.CreateMaintenanceWindowRequest{StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour), Message: "Upgrade"})
+	require.NoError(t, err)
+	upcoming, err := service.Upcoming()
+	require.NoError(t, err)
+	require.Len(t, upcoming, 1)
+
+	t.Run("windows changed through the service are seen at once", func(t *testing.T) {
+		_, err := service.Create(&models.CreateMaintenanceWindowRequest{StartsAt: now.Add(3 * time.Hour), EndsAt: now.Add(4 * time.Hour), Message: "Reindex"})
+		require.NoError(t, err)
+
+		upcoming, err := service.Upcoming()
+		require.NoError(t, err)
+		assert.Len(t, upcoming, 2)
+	})
+
+	t.Run("windows edited in the database are seen after a flush", func(t *testing.T) {
+		require.NoError(t, repo.Create(&models.MaintenanceWindow{StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), Message: "Hotfix"}))
+
+		active, err := service.Active()
+		require.NoError(t, err)
+		assert.Nil(t, active, "the cached list is served until the flush")
+
+		assert.Equal(t, []string{"announcements"}, registry.FlushAll())
+
+		active, err = service.Active()
+		require.NoError(t, err)
+		require.NotNil(t, active)
+		assert.Equal(t, "Hotfix", active.Message)
+	})
+
+	t.Run("cached windows drop out once they end", func(t *testing.T) {
+		now = now.Add(150 * time.Minute)
+
+		upcoming, err := service.Upcoming()
+		require.NoError(t, err)
+		require.Len(t, upcoming, 1)
+		assert.Equal(t, "Reindex", upcoming[0].Message)
+	})
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// defaultNotificationPreferences applies to every channel a user has not set.
+// Announcements are only shown in the app unless the user asks for email.
+var defaultNotificationPreferences = models.NotificationPreferencesResponse{
+	CommentOnPost: models.NotificationChannels{Email: true, InApp: true},
+	CommentReply:  models.NotificationChannels{Email: true, InApp: true},
+	Announcements: models.NotificationChannels{Email: false, InApp: true},
+}
+
+func (s *notificationService) GetPreferences(userID uint) (*models.NotificationPreferencesResponse, error) {
+	user, err := s.preferencesUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := resolvePreferences(user.NotificationPreferences)
+	return &preferences, nil
+}
+
+func (s *notificationService) UpdatePreferences(userID uint, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferencesResponse, error) {
+	user, err := s.preferencesUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := &user.NotificationPreferences
+	applyChannels(req.CommentOnPost, &prefs.CommentOnPostEmail, &prefs.CommentOnPostInApp)
+	applyChannels(req.CommentReply, &prefs.CommentReplyEmail, &prefs.CommentReplyInApp)
+	applyChannels(req.Announcements, &prefs.AnnouncementsEmail, &prefs.AnnouncementsInApp)
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	preferences := resolvePreferences(user.NotificationPreferences)
+	return &preferences, nil
+}
+
+func (s *notificationService) preferencesUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// applyChannels copies the channels a request sets onto the stored
+// preferences, leaving the others as they were
+func applyChannels(req *models.UpdateNotificationChannelsRequest, email, inApp **bool) {
+	if req == nil {
+		return
+	}
+	if req.Email != nil {
+		value := *req.Email
+		*email = &value
+	}
+	if req.InApp != nil {
+		value := *req.InApp
+		*inApp = &value
+	}
+}
+
+// resolvePreferences fills in the defaults for channels the user never set
+func resolvePreferences(prefs models.NotificationPreferences) models.NotificationPreferencesResponse {
+	resolved := defaultNotificationPreferences
+	resolveChannel(&resolved.CommentOnPost.Email, prefs.CommentOnPostEmail)
+	resolveChannel(&resolved.CommentOnPost.InApp, prefs.CommentOnPostInApp)
+	resolveChannel(&resolved.CommentReply.Email, prefs.CommentReplyEmail)
+	resolveChannel(&resolved.CommentReply.InApp, prefs.CommentReplyInApp)
+	resolveChannel(&resolved.Announcements.Email, prefs.AnnouncementsEmail)
+	resolveChannel(&resolved.Announcements.InApp, prefs.AnnouncementsInApp)
+	return resolved
+}
+
+func resolveChannel(resolved *bool, value *bool) {
+	if value != nil {
+		*resolved = *value
+	}
+}
+
+// notificationChannels returns where the user wants notifications of the
+// given type delivered. Types users cannot turn off go everywhere.
+func notificationChannels(user *models.User, notificationType string) models.NotificationChannels {
+	prefs := resolvePreferences(user.NotificationPreferences)
+	switch notificationType {
+	case models.NotificationCommentOnPost:
+		return prefs.CommentOnPost
+	case models.NotificationCommentReply:
+		return prefs.CommentReply
+	case models.NotificationAnnouncement:
+		return prefs.Announcements
+	default:
+		return allNotificationChannels
+	}
+}
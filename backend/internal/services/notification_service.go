@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"sync"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/events"
+	"backend/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// commentSnippetLength caps how much of a comment is quoted in a notification
+const commentSnippetLength = 200
+
+// Notification is a message addressed to a single user. Email is left empty
+// for notifications that should only appear in the app.
+type Notification struct {
+	UserID  uint
+	Email   string
+	Type    string
+	Subject string
+	Body    string
+	Link    string
+	RefType string
+	RefID   uint
+}
+
+// Notifier delivers notifications over one channel, e.g. email. Several
+// notifiers can be registered and each receives every notification.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NotificationService tells users about activity that concerns them. Every
+// notification is stored in the user's in-app inbox and also handed to the
+// configured delivery channels.
+type NotificationService interface {
+	// Subscribe registers notifications for comment and draft archive events
+	Subscribe(bus *events.Bus)
+	// Wait blocks until every notification dispatched so far has been sent
+	Wait()
+
+	// List returns a page of the user's in-app notifications, optionally
+	// filtered to read or unread ones
+	List(userID uint, read *bool, page, perPage int) ([]models.Notification, int64, error)
+	UnreadCount(userID uint) (int64, error)
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) error
+
+	// GetPreferences returns where the user wants each kind of notification
+	// delivered, with defaults filled in
+	GetPreferences(userID uint) (*models.NotificationPreferencesResponse, error)
+	UpdatePreferences(userID uint, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferencesResponse, error)
+}
+
+// ErrNotificationNotFound is returned when a notification does not exist or
+// belongs to another user
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type notificationService struct {
+	notificationRepo repositories.NotificationRepository
+	userRepo         repositories.UserRepository
+	postRepo         repositories.PostRepository
+	commentRepo      repositories.CommentRepository
+	notifiers        []Notifier
+	siteURL          string
+	wg               sync.WaitGroup
+}
+
+func NewNotificationService(notificationRepo repositories.NotificationRepository, userRepo repositories.UserRepository, postRepo repositories.PostRepository, commentRepo repositories.CommentRepository, cfg *config.Config, notifiers ...Notifier) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		postRepo:         postRepo,
+		commentRepo:      commentRepo,
+		notifiers:        notifiers,
+		siteURL:          strings.TrimRight(cfg.Notify.SiteURL, "/"),
+	}
+}
+
+func (s *notificationService) Subscribe(bus *events.Bus) {
+	bus.Subscribe(EventCommentApproved, s.handleCommentApproved)
+	bus.Subscribe(EventDraftArchived, s.handleDraftArchived)
+}
+
+func (s *notificationService) Wait() {
+	s.wg.Wait()
+}
+
+func (s *notificationService) List(userID uint, read *bool, page, perPage int) ([]models.Notification, int64, error) {
+	return s.notificationRepo.ListByUser(userID, read, page, perPage)
+}
+
+func (s *notificationService) UnreadCount(userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+func (s *notificationService) MarkRead(userID, id uint) error {
+	if err := s.notificationRepo.MarkRead(userID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotificationNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(userID uint) error {
+	return s.notificationRepo.MarkAllRead(userID)
+}
+
+func (s *notificationService) handleCommentApproved(ctx context.Context, event events.Event) {
+	commentEvent, ok := event.(CommentEvent)
+	if !ok {
+		return
+	}
+
+	// Look up the recipient and send off the request path so a slow mail
+	// server never holds up the moderator's response
+	s.wg.Add(1)
+	go func(comment models.Comment) {
+		defer s.wg.Done()
+
+		ctx := context.Background()
+		post, err := s.postRepo.GetByID(comment.PostID)
+		if err != nil {
+			logger.LogError(ctx, "Failed to prepare comment notification", err,
+				zap.Uint("comment_id", comment.ID),
+			)
+			return
+		}
+
+		notification, channels, err := s.commentNotification(&comment, post)
+		if err != nil {
+			logger.LogError(ctx, "Failed to prepare comment notification", err,
+				zap.Uint("comment_id", comment.ID),
+			)
+		} else {
+			s.dispatch(ctx, notification, channels)
+		}
+
+		notification, channels, err = s.replyNotification(&comment, post)
+		if err != nil {
+			logger.LogError(ctx, "Failed to prepare reply notification", err,
+				zap.Uint("comment_id", comment.ID),
+			)
+		} else {
+			s.dispatch(ctx, notification, channels)
+		}
+
+		s.dispatch(ctx, s.approvalNotification(&comment, post), allNotificationChannels)
+	}(commentEvent.Comment)
+}
+
+func (s *notificationService) handleDraftArchived(ctx context.Context, event events.Event) {
+	postEvent, ok := event.(PostEvent)
+	if !ok {
+		return
+	}
+
+	s.wg.Add(1)
+	go func(post models.Post) {
+		defer s.wg.Done()
+
+		ctx := context.Background()
+		author, err := s.userRepo.GetByID(post.AuthorID)
+		if err != nil {
+			logger.LogError(ctx, "Failed to prepare draft archive notification", err,
+				zap.Uint("post_id", post.ID),
+			)
+			return
+		}
+
+		link := fmt.Sprintf("%s/dashboard/posts/%d/edit", s.siteURL, post.ID)
+		notification := Notification{
+			UserID:  author.ID,
+			Email:   author.Email,
+			Type:    models.NotificationDraftArchived,
+			Subject: fmt.Sprintf("Your draft \"%s\" was archived", post.Title),
+			Body:    fmt.Sprintf("Your draft \"%s\" had not been edited for a while, so it was archived. You can restore it by setting its status back to draft:\n\n%s", post.Title, link),
+			Link:    link,
+			RefType: "post",
+			RefID:   post.ID,
+		}
+		s.dispatch(ctx, notification, notificationChannels(author, notification.Type))
+	}(postEvent.Post)
+}
+
+// commentNotification builds the notification for the author of the post a
+// comment was left on, and the channels it goes to. There are none when the
+// author should not be told: they opted out, or they wrote the comment
+// themselves.
+func (s *notificationService) commentNotification(comment *models.Comment, post *models.Post) (Notification, models.NotificationChannels, error) {
+	if post.AuthorID == comment.UserID {
+		return Notification{}, models.NotificationChannels{}, nil
+	}
+
+	author, err := s.userRepo.GetByID(post.AuthorID)
+	if err != nil {
+		return Notification{}, models.NotificationChannels{}, err
+	}
+	if !author.NotifyOnComment {
+		return Notification{}, models.NotificationChannels{}, nil
+	}
+
+	commenter := "Someone"
+	if comment.User != nil && comment.User.Name != "" {
+		commenter = comment.User.Name
+	}
+
+	link := fmt.Sprintf("%s/posts/%s", s.siteURL, post.Slug)
+	return Notification{
+		UserID:  author.ID,
+		Email:   author.Email,
+		Type:    models.NotificationCommentOnPost,
+		Subject: fmt.Sprintf("New comment on \"%s\"", post.Title),
+		Body:    fmt.Sprintf("%s commented on your post \"%s\":\n\n%s\n\n%s", commenter, post.Title, snippet(comment.Content, commentSnippetLength), link),
+		Link:    link,
+		RefType: "comment",
+		RefID:   comment.ID,
+	}, notificationChannels(author, models.NotificationCommentOnPost), nil
+}
+
+// replyNotification builds the notification for the writer of the comment a
+// reply answers, and the channels it goes to. There are none for top-level
+// comments, replies to oneself, and replies to the post author, who already
+// hears about every comment.
+func (s *notificationService) replyNotification(comment *models.Comment, post *models.Post) (Notification, models.NotificationChannels, error) {
+	if comment.ParentID == nil {
+		return Notification{}, models.NotificationChannels{}, nil
+	}
+
+	parent, err := s.commentRepo.GetByID(*comment.ParentID)
+	if err != nil {
+		return Notification{}, models.NotificationChannels{}, err
+	}
+	if parent.UserID == comment.UserID || parent.UserID == post.AuthorID {
+		return Notification{}, models.NotificationChannels{}, nil
+	}
+
+	recipient, err := s.userRepo.GetByID(parent.UserID)
+	if err != nil {
+		return Notification{}, models.NotificationChannels{}, err
+	}
+
+	replier := "Someone"
+	if comment.User != nil && comment.User.Name != "" {
+		replier = comment.User.Name
+	}
+
+	link := fmt.Sprintf("%s/posts/%s", s.siteURL, post.Slug)
+	return Notification{
+		UserID:  recipient.ID,
+		Email:   recipient.Email,
+		Type:    models.NotificationCommentReply,
+		Subject: fmt.Sprintf("New reply to your comment on \"%s\"", post.Title),
+		Body:    fmt.Sprintf("%s replied to your comment on \"%s\":\n\n%s\n\n%s", replier, post.Title, snippet(comment.Content, commentSnippetLength), link),
+		Link:    link,
+		RefType: "comment",
+		RefID:   comment.ID,
+	}, notificationChannels(recipient, models.NotificationCommentReply), nil
+}
+
+// approvalNotification tells a commenter their comment is now public. It is
+// only shown in the app.
+func (s *notificationService) approvalNotification(comment *models.Comment, post *models.Post) Notification {
+	return Notification{
+		UserID:  comment.UserID,
+		Type:    models.NotificationCommentApproved,
+		Subject: fmt.Sprintf("Your comment on \"%s\" was approved", post.Title),
+		Link:    fmt.Sprintf("%s/posts/%s", s.siteURL, post.Slug),
+		RefType: "comment",
+		RefID:   comment.ID,
+	}
+}
+
+// allNotificationChannels is used for notifications users cannot turn off
+var allNotificationChannels = models.NotificationChannels{Email: true, InApp: true}
+
+// dispatch stores a notification in the user's inbox and hands it to every
+// delivery channel, skipping whichever of the two the user turned off
+func (s *notificationService) dispatch(ctx context.Context, notification Notification, channels models.NotificationChannels) {
+	if !channels.Email {
+		notification.Email = ""
+	}
+	if !channels.InApp && notification.Email == "" {
+		return
+	}
+
+	if channels.InApp {
+		if err := s.notificationRepo.Create(&models.Notification{
+			UserID:  notification.UserID,
+			Type:    notification.Type,
+			Message: notification.Subject,
+			RefType: notification.RefType,
+			RefID:   notification.RefID,
+		}); err != nil {
+			logger.LogError(ctx, "Failed to store notification", err,
+				zap.Uint("user_id", notification.UserID),
+			)
+		}
+	}
+
+	for _, notifier := range s.notifiers {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			logger.LogError(ctx, "Failed to send notification", err,
+				zap.Uint("user_id", notification.UserID),
+				zap.String("notifier", fmt.Sprintf("%T", notifier)),
+			)
+		}
+	}
+}
+
+// snippet shortens text to at most max runes, marking the cut with an ellipsis
+func snippet(text string, max int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= max {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:max])) + "…"
+}
+
+// LogNotifier records notifications in the application log. It is the
+// fallback when no delivery channel is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	logger.LogInfo(ctx, "Notification",
+		zap.Uint("user_id", notification.UserID),
+		zap.String("subject", notification.Subject),
+		zap.String("link", notification.Link),
+	)
+	return nil
+}
+
+// headerSanitizer keeps user-supplied text such as post titles from
+// injecting extra mail headers
+var headerSanitizer = strings.NewReplacer("\r", " ", "\n", " ")
+
+// EmailNotifier sends notifications as plain-text email over SMTP
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewEmailNotifier(cfg *config.NotificationConfig) *EmailNotifier {
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &EmailNotifier{
+		addr: cfg.SMTPHost + ":" + cfg.SMTPPort,
+		auth: auth,
+		from: cfg.SMTPFrom,
+	}
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Email == "" {
+		return nil
+	}
+
+	message := strings.Join([]string{
+		"From: " + n.from,
+		"To: " + notification.Email,
+		"Subject: " + mime.QEncoding.Encode("UTF-8", headerSanitizer.Replace(notification.Subject)),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		notification.Body,
+	}, "\r\n")
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{notification.Email}, []byte(message))
+}
+
+// NewNotifiers returns the notifiers enabled by configuration: queued email
+// when an SMTP host is set, otherwise the log notifier. The email queue is
+// returned separately so it can be started and stopped; it is nil when email
+// is disabled.
+func NewNotifiers(cfg *config.NotificationConfig) ([]Notifier, *EmailQueue) {
+	if cfg.SMTPHost != "" {
+		queue := NewEmailQueue(NewEmailNotifier(cfg), cfg)
+		return []Notifier{queue}, queue
+	}
+	return []Notifier{LogNotifier{}}, nil
+}
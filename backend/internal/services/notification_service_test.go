@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/pkg/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier keeps every notification it is asked to send
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// sentTo returns the notifications of the given type sent to userID
+func (n *recordingNotifier) sentTo(userID uint, notificationType string) []Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var sent []Notification
+	for _, notification := range n.sent {
+		if notification.UserID == userID && notification.Type == notificationType {
+			sent = append(sent, notification)
+		}
+	}
+	return sent
+}
+
+type notificationFixture struct {
+	comments      CommentService
+	notifications NotificationService
+	notifier      *recordingNotifier
+	commentRepo   *fakeCommentRepo
+}
+
+func newNotificationFixture(authorOptedIn bool) *notificationFixture {
+	author := &models.User{ID: 1, Username: "author", Email: "author@example.com", NotifyOnComment: authorOptedIn}
+	reader := &models.User{ID: 2, Username: "reader", Name: "Reader", Email: "reader@example.com", NotifyOnComment: true}
+	replier := &models.User{ID: 3, Username: "replier", Name: "Replier", Email: "replier@example.com", NotifyOnComment: true}
+	userRepo := newFakeUserRepo(author, reader, replier)
+	postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Hello", Slug: "hello", AuthorID: 1, Status: "published"})
+	commentRepo := newFakeCommentRepo()
+
+	cfg := &config.Config{Notify: config.NotificationConfig{SiteURL: "https://blog.example.com/"}}
+	bus := events.NewBus()
+	notifier := &recordingNotifier{}
+	notifications := NewNotificationService(newFakeNotificationRepo(), userRepo, postRepo, commentRepo, cfg, notifier)
+	notifications.Subscribe(bus)
+
+	return &notificationFixture{
+		comments:      NewCommentService(commentRepo, postRepo, userRepo, cfg, bus),
+		notifications: notifications,
+		notifier:      notifier,
+		commentRepo:   commentRepo,
+	}
+}
+
+func (f *notificationFixture) comment(t *testing.T, userID uint) *models.Comment {
+	comment, err := f.comments.Create(&models.CreateCommentRequest{PostID: 1, Content: "Great post, thanks!"}, userID, "author")
+	require.NoError(t, err)
+	return comment
+}
+
+func (f *notificationFixture) reply(t *testing.T, userID, parentID uint) *models.Comment {
+	comment, err := f.comments.Create(&models.CreateCommentRequest{PostID: 1, ParentID: &parentID, Content: "I agree with this"}, userID, "author")
+	require.NoError(t, err)
+	return comment
+}
+
+func (f *notificationFixture) approve(t *testing.T, commentID uint) {
+	_, err := f.comments.Update(commentID, &models.UpdateCommentRequest{Status: stringPtr("approved")}, 99, "admin")
+	require.NoError(t, err)
+	f.notifications.Wait()
+}
+
+func TestNotificationService_CommentApproved(t *testing.T) {
+	t.Run("approving a comment notifies the post author", func(t *testing.T) {
+		fixture := newNotificationFixture(true)
+		comment := fixture.comment(t, 2)
+
+		// Pending comments do not notify anyone yet
+		fixture.notifications.Wait()
+		assert.Empty(t, fixture.notifier.sent)
+
+		fixture.approve(t, comment.ID)
+
+		sentToAuthor := fixture.notifier.sentTo(1, models.NotificationCommentOnPost)
+		require.Len(t, sentToAuthor, 1)
+		sent := sentToAuthor[0]
+		assert.Equal(t, uint(1), sent.UserID)
+		assert.Equal(t, "author@example.com", sent.Email)
+		assert.Equal(t, "https://blog.example.com/posts/hello", sent.Link)
+		assert.True(t, strings.Contains(sent.Body, "Great post, thanks!"))
+	})
+
+	t.Run("re-saving an approved comment does not notify again", func(t *testing.T) {
+		fixture := newNotificationFixture(true)
+		comment := fixture.comment(t, 2)
+		fixture.approve(t, comment.ID)
+		fixture.approve(t, comment.ID)
+
+		assert.Len(t, fixture.notifier.sentTo(1, models.NotificationCommentOnPost), 1)
+		assert.Len(t, fixture.notifier.sentTo(2, models.NotificationCommentApproved), 1)
+	})
+
+	t.Run("authors who opted out are not notified", func(t *testing.T) {
+		fixture := newNotificationFixture(false)
+		comment := fixture.comment(t, 2)
+
+		fixture.approve(t, comment.ID)
+
+		assert.Empty(t, fixture.notifier.sentTo(1, models.NotificationCommentOnPost))
+	})
+
+	t.Run("authors are not notified about their own comments", func(t *testing.T) {
+		fixture := newNotificationFixture(true)
+		comment := fixture.comment(t, 1)
+
+		fixture.approve(t, comment.ID)
+
+		assert.Empty(t, fixture.notifier.sentTo(1, models.NotificationCommentOnPost))
+	})
+}
+
+func TestNotificationService_Replies(t *testing.T) {
+	t.Run("approving a reply notifies the parent comment's writer", func(t *testing.T) {
+		fixture := newNotificationFixture(true)
+		parent := fixture.comment(t, 2)
+		fixture.approve(t, parent.ID)
+
+		fixture.approve(t, fixture.reply(t, 3, parent.ID).ID)
+
+		replies := fixture.notifier.sentTo(2, models.NotificationCommentReply)
+		require.Len(t, replies, 1)
+		assert.Equal(t, "reader@example.com", replies[0].Email)
+		assert.True(t, strings.Contains(replies[0].Body, "I agree with this"))
+	})
+
+	t.Run("replies to oneself or to the post author are not reply notifications", func(t *testing.T) {
+		fixture := newNotificationFixture(true)
+		own := fixture.comment(t, 2)
+		byAuthor := fixture.comment(t, 1)
+
+		fixture.approve(t, fixture.reply(t, 2, own.ID).ID)
+		fixture.approve(t, fixture.reply(t, 3, byAuthor.ID).ID)
+
+		assert.Empty(t, fixture.notifier.sentTo(2, models.NotificationCommentReply))
+		assert.Empty(t, fixture.notifier.sentTo(1, models.NotificationCommentReply))
+		assert.Len(t, fixture.notifier.sentTo(1, models.NotificationCommentOnPost), 2)
+	})
+}
+
+func TestNotificationService_Preferences(t *testing.T) {
+	off := false
+
+	t.Run("defaults apply until the user changes them", func(t *testing.T) {
+		fixture := newNotificationFixture(true)
+
+		preferences, err := fixture.notifications.GetPreferences(1)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.NotificationChannels{Email: true, InApp: true}, preferences.CommentOnPost)
+		assert.Equal(t, models.NotificationChannels{Email: true, InApp: true}, preferences.CommentReply)
+		assert.Equal(t, models.NotificationChannels{Email: false, InApp: true}, preferences.Announcements)
+	})
+
+	t.Run("updates persist and leave other channels alone", func(t *testing.T) {
+		fixture := newNotificationFixture(true)
+
+		updated, err := fixture.notifications.UpdatePreferences(1, &models.UpdateNotificationPreferencesRequest{
+			CommentOnPost: &models.UpdateNotificationChannelsRequest{Email: &off},
+		})
+		require.NoError(t, err)
+
+		stored, err := fixture.notifications.GetPreferences(1)
+		require.NoError(t, err)
+		assert.Equal(t, updated, stored)
+		assert.Equal(t, models.NotificationChannels{Email: false, InApp: true}, stored.CommentOnPost)
+		assert.Equal(t, models.NotificationChannels{Email: true, InApp: true}, stored.CommentReply)
+	})
+
+	t.Run("unknown users are reported", func(t *testing.T) {
+		fixture := newNotificationFixture(true)
+
+		_, err := fixture.notifications.GetPreferences(42)
+
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("a disabled channel suppresses that delivery only", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			channels  models.UpdateNotificationChannelsRequest
+			wantSent  bool
+			wantEmail string
+			wantInbox int
+		}{
+			{"email off", models.UpdateNotificationChannelsRequest{Email: &off}, true, "", 1},
+			{"in-app off", models.UpdateNotificationChannelsRequest{InApp: &off}, true, "author@example.com", 0},
+			{"both off", models.UpdateNotificationChannelsRequest{Email: &off, InApp: &off}, false, "", 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				fixture := newNotificationFixture(true)
+				_, err := fixture.notifications.UpdatePreferences(1, &models.UpdateNotificationPreferencesRequest{CommentOnPost: &tt.channels})
+				require.NoError(t, err)
+
+				fixture.approve(t, fixture.comment(t, 2).ID)
+
+				sent := fixture.notifier.sentTo(1, models.NotificationCommentOnPost)
+				if tt.wantSent {
+					require.Len(t, sent, 1)
+					assert.Equal(t, tt.wantEmail, sent[0].Email)
+				} else {
+					assert.Empty(t, sent)
+				}
+				_, inbox, err := fixture.notifications.List(1, nil, 1, 10)
+				require.NoError(t, err)
+				assert.Equal(t, int64(tt.wantInbox), inbox)
+			})
+		}
+	})
+
+	t.Run("turning off replies suppresses reply notifications", func(t *testing.T) {
+		fixture := newNotificationFixture(true)
+		_, err := fixture.notifications.UpdatePreferences(2, &models.UpdateNotificationPreferencesRequest{
+			CommentReply: &models.UpdateNotificationChannelsRequest{Email: &off, InApp: &off},
+		})
+		require.NoError(t, err)
+		parent := fixture.comment(t, 2)
+		fixture.approve(t, parent.ID)
+
+		fixture.approve(t, fixture.reply(t, 3, parent.ID).ID)
+
+		assert.Empty(t, fixture.notifier.sentTo(2, models.NotificationCommentReply))
+		// Approvals cannot be turned off
+		assert.Len(t, fixture.notifier.sentTo(2, models.NotificationCommentApproved), 1)
+	})
+}
+
+func TestNotificationService_Inbox(t *testing.T) {
+	fixture := newNotificationFixture(true)
+	for i := 0; i < 2; i++ {
+		fixture.approve(t, fixture.comment(t, 2).ID)
+	}
+
+	t.Run("approved comments land in the author's inbox", func(t *testing.T) {
+		notifications, total, err := fixture.notifications.List(1, nil, 1, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, models.NotificationCommentOnPost, notifications[0].Type)
+		assert.Equal(t, "comment", notifications[0].RefType)
+		assert.False(t, notifications[0].Read)
+
+		// The commenter hears that their comments were approved
+		approved, _, err := fixture.notifications.List(2, nil, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, approved, 2)
+		assert.Equal(t, models.NotificationCommentApproved, approved[0].Type)
+	})
+
+	t.Run("marking one read flips its state and decrements the unread count", func(t *testing.T) {
+		unread, err := fixture.notifications.UnreadCount(1)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), unread)
+
+		notifications, _, err := fixture.notifications.List(1, nil, 1, 10)
+		require.NoError(t, err)
+		require.NoError(t, fixture.notifications.MarkRead(1, notifications[0].ID))
+
+		unread, err = fixture.notifications.UnreadCount(1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), unread)
+
+		read := true
+		readOnly, _, err := fixture.notifications.List(1, &read, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, readOnly, 1)
+		assert.Equal(t, notifications[0].ID, readOnly[0].ID)
+	})
+
+	t.Run("users cannot mark someone else's notification", func(t *testing.T) {
+		notifications, _, err := fixture.notifications.List(1, nil, 1, 10)
+		require.NoError(t, err)
+
+		err = fixture.notifications.MarkRead(2, notifications[0].ID)
+
+		assert.ErrorIs(t, err, ErrNotificationNotFound)
+	})
+
+	t.Run("mark all read clears the unread count", func(t *testing.T) {
+		require.NoError(t, fixture.notifications.MarkAllRead(1))
+
+		unread, err := fixture.notifications.UnreadCount(1)
+		require.NoError(t, err)
+		assert.Zero(t, unread)
+
+		// Other users' notifications are untouched
+		unread, err = fixture.notifications.UnreadCount(2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), unread)
+	})
+}
+
+func TestSnippet(t *testing.T) {
+	assert.Equal(t, "short", snippet("  short  ", 10))
+	assert.Equal(t, "abcde…", snippet("abcdefghij", 5))
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPepperedJWTService(pepper, previous string) JWTService {
+	cfg := &config.Config{Auth: config.AuthConfig{PasswordPepper: pepper, PreviousPasswordPepper: previous}}
+	return NewJWTService(fakeRefreshTokenRepo{}, nil, cfg)
+}
+
+func TestJWTService_PasswordPepper(t *testing.T) {
+	t.Run("a peppered hash only verifies with the pepper", func(t *testing.T) {
+		peppered := newPepperedJWTService("pepper", "")
+		hash, err := peppered.HashPassword("password123")
+		require.NoError(t, err)
+
+		assert.True(t, peppered.CheckPassword("password123", hash))
+		assert.False(t, peppered.CheckPassword("wrongpassword", hash))
+		assert.False(t, newPepperedJWTService("", "").CheckPassword("password123", hash))
+		assert.False(t, newPepperedJWTService("other", "").CheckPassword("password123", hash))
+	})
+
+	t.Run("no pepper keeps existing hashes valid", func(t *testing.T) {
+		hash, err := utils.HashPassword("password123")
+		require.NoError(t, err)
+
+		ok, needsRehash := newPepperedJWTService("", "").VerifyPassword("password123", hash)
+
+		assert.True(t, ok)
+		assert.False(t, needsRehash)
+	})
+
+	t.Run("the pepper is not lost on long passwords", func(t *testing.T) {
+		long := string(make([]byte, 100))
+		hash, err := newPepperedJWTService("pepper", "").HashPassword(long)
+		require.NoError(t, err)
+
+		assert.False(t, newPepperedJWTService("other", "").CheckPassword(long, hash))
+	})
+
+	t.Run("hashes made with the previous pepper verify and need a rehash", func(t *testing.T) {
+		hash, err := newPepperedJWTService("old", "").HashPassword("password123")
+		require.NoError(t, err)
+
+		ok, needsRehash := newPepperedJWTService("new", "old").VerifyPassword("password123", hash)
+		assert.True(t, ok)
+		assert.True(t, needsRehash)
+
+		ok, needsRehash = newPepperedJWTService("new", "old").VerifyPassword("wrongpassword", hash)
+		assert.False(t, ok)
+		assert.False(t, needsRehash)
+	})
+}
+
+func TestAuthService_LoginRehashesPassword(t *testing.T) {
+	legacyHash, err := utils.HashPassword("password123")
+	require.NoError(t, err)
+
+	userRepo := newFakeUserRepo(&models.User{ID: 1, Email: "author@example.com", Password: legacyHash, Role: "author"})
+	jwtService := newPepperedJWTService("pepper", "")
+	authService := NewAuthService(userRepo, nil, jwtService, &config.Config{})
+
+	_, err = authService.Login(&models.LoginRequest{Email: "author@example.com", Password: "password123"})
+	require.NoError(t, err)
+
+	stored, err := userRepo.GetByID(1)
+	require.NoError(t, err)
+	assert.NotEqual(t, legacyHash, stored.Password)
+	ok, needsRehash := jwtService.VerifyPassword("password123", stored.Password)
+	assert.True(t, ok)
+	assert.False(t, needsRehash)
+	assert.False(t, utils.VerifyPassword("password123", stored.Password))
+}
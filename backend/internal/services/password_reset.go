@@ -0,0 +1,96 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// passwordResetTTL is how long a password reset token stays valid
+const passwordResetTTL = time.Hour
+
+// ErrInvalidResetToken is returned when a password reset token is unknown,
+// expired or already used
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+func (s *authService) RequestPasswordReset(email string) (string, error) {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", errors.New("failed to request password reset")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("failed to generate reset token")
+	}
+	token := hex.EncodeToString(raw)
+
+	resetToken := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	if err := s.resetRepo.Create(resetToken); err != nil {
+		return "", errors.New("failed to request password reset")
+	}
+	s.sendPasswordResetEmail(user, token)
+	return token, nil
+}
+
+func (s *authService) ResetPassword(token, newPassword string) error {
+	resetToken, err := s.resetRepo.GetByTokenHash(hashResetToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return errors.New("failed to reset password")
+	}
+	if !resetToken.ExpiresAt.After(time.Now()) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetByID(resetToken.UserID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	hashedPassword, err := s.jwtService.HashPassword(newPassword)
+	if err != nil {
+		return errors.New("failed to process new password")
+	}
+
+	// Use the token up before changing anything, so a token submitted twice
+	// at once only resets the password once
+	used, err := s.resetRepo.MarkUsed(resetToken.ID)
+	if err != nil {
+		return errors.New("failed to reset password")
+	}
+	if !used {
+		return ErrInvalidResetToken
+	}
+
+	user.Password = hashedPassword
+	if err := s.userRepo.Update(user); err != nil {
+		return errors.New("failed to reset password")
+	}
+
+	// Other outstanding reset links and every session are no longer valid
+	s.resetRepo.DeleteByUser(user.ID)
+	s.jwtService.RevokeAllUserTokens(user.ID)
+	return nil
+}
+
+// hashResetToken returns the form a reset token is stored in
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakePasswordResetRepo keeps reset tokens in memory
+type fakePasswordResetRepo struct {
+	repositories.PasswordResetTokenRepository
+	tokens map[uint]*models.PasswordResetToken
+	nextID uint
+}
+
+func newFakePasswordResetRepo() *fakePasswordResetRepo {
+	return &fakePasswordResetRepo{tokens: make(map[uint]*models.PasswordResetToken)}
+}
+
+func (r *fakePasswordResetRepo) Create(token *models.PasswordResetToken) error {
+	r.nextID++
+	token.ID = r.nextID
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+func (r *fakePasswordResetRepo) GetByTokenHash(tokenHash string) (*models.PasswordResetToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash && token.UsedAt == nil && token.ExpiresAt.After(time.Now()) {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePasswordResetRepo) MarkUsed(id uint) (bool, error) {
+	token, ok := r.tokens[id]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return true, nil
+}
+
+func (r *fakePasswordResetRepo) DeleteByUser(userID uint) error {
+	for id, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+// revokingJWTService records whose refresh tokens were revoked
+type revokingJWTService struct {
+	fakeJWTService
+	revoked []uint
+}
+
+func (s *revokingJWTService) RevokeAllUserTokens(userID uint) error {
+	s.revoked = append(s.revoked, userID)
+	return nil
+}
+
+type passwordResetFixture struct {
+	authService AuthService
+	userRepo    *fakeUserRepo
+	resetRepo   *fakePasswordResetRepo
+	jwtService  *revokingJWTService
+	emails      *recordingNotifier
+}
+
+func newPasswordResetFixture() *passwordResetFixture {
+	userRepo := newFakeUserRepo(&models.User{ID: 1, Email: "user@example.com", Password: "hashed:oldpassword"})
+	resetRepo := newFakePasswordResetRepo()
+	jwtService := &revokingJWTService{}
+	emails := &recordingNotifier{}
+	return &passwordResetFixture{
+		authService: NewAuthService(userRepo, resetRepo, jwtService, nil, emails),
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		jwtService:  jwtService,
+		emails:      emails,
+	}
+}
+
+func TestAuthService_RequestPasswordReset(t *testing.T) {
+	t.Run("issues a token stored only as a hash", func(t *testing.T) {
+		fixture := newPasswordResetFixture()
+
+		token, err := fixture.authService.RequestPasswordReset("user@example.com")
+
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+		require.Len(t, fixture.resetRepo.tokens, 1)
+		stored := fixture.resetRepo.tokens[1]
+		assert.Equal(t, uint(1), stored.UserID)
+		assert.NotEqual(t, token, stored.TokenHash)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+	})
+
+	t.Run("emails the token to the user", func(t *testing.T) {
+		fixture := newPasswordResetFixture()
+
+		token, err := fixture.authService.RequestPasswordReset("user@example.com")
+
+		require.NoError(t, err)
+		sent := fixture.emails.sentTo(1, models.NotificationPasswordReset)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "user@example.com", sent[0].Email)
+		assert.Equal(t, "/reset-password?token="+token, sent[0].Link)
+		assert.Contains(t, sent[0].Body, sent[0].Link)
+	})
+
+	t.Run("an unknown email gets no token and no error", func(t *testing.T) {
+		fixture := newPasswordResetFixture()
+
+		token, err := fixture.authService.RequestPasswordReset("nobody@example.com")
+
+		require.NoError(t, err)
+		assert.Empty(t, token)
+		assert.Empty(t, fixture.resetRepo.tokens)
+		assert.Empty(t, fixture.emails.sent)
+	})
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	t.Run("sets the new password and signs the user out", func(t *testing.T) {
+		fixture := newPasswordResetFixture()
+		token, err := fixture.authService.RequestPasswordReset("user@example.com")
+		require.NoError(t, err)
+
+		require.NoError(t, fixture.authService.ResetPassword(token, "newpassword"))
+
+		assert.Equal(t, "hashed:newpassword", fixture.userRepo.users[1].Password)
+		assert.Equal(t, []uint{1}, fixture.jwtService.revoked)
+	})
+
+	t.Run("a token can only be used once", func(t *testing.T) {
+		fixture := newPasswordResetFixture()
+		token, err := fixture.authService.RequestPasswordReset("user@example.com")
+		require.NoError(t, err)
+		require.NoError(t, fixture.authService.ResetPassword(token, "newpassword"))
+
+		err = fixture.authService.ResetPassword(token, "anotherpassword")
+
+		assert.ErrorIs(t, err, ErrInvalidResetToken)
+		assert.Equal(t, "hashed:newpassword", fixture.userRepo.users[1].Password)
+	})
+
+	t.Run("an expired token is rejected", func(t *testing.T) {
+		fixture := newPasswordResetFixture()
+		token, err := fixture.authService.RequestPasswordReset("user@example.com")
+		require.NoError(t, err)
+		fixture.resetRepo.tokens[1].ExpiresAt = time.Now().Add(-time.Minute)
+
+		err = fixture.authService.ResetPassword(token, "newpassword")
+
+		assert.ErrorIs(t, err, ErrInvalidResetToken)
+		assert.Equal(t, "hashed:oldpassword", fixture.userRepo.users[1].Password)
+		assert.Empty(t, fixture.jwtService.revoked)
+	})
+
+	t.Run("an unknown token is rejected", func(t *testing.T) {
+		fixture := newPasswordResetFixture()
+
+		err := fixture.authService.ResetPassword("not-a-token", "newpassword")
+
+		assert.ErrorIs(t, err, ErrInvalidResetToken)
+	})
+}
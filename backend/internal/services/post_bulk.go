@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+// ErrBulkRolledBack is returned by Bulk when some of the posts failed, so
+// none of them were changed
+var ErrBulkRolledBack = errors.New("bulk action rolled back")
+
+// bulkPostStatuses maps the status-changing bulk actions to the status they set
+var bulkPostStatuses = map[string]string{
+	models.BulkPostPublish: "published",
+	models.BulkPostArchive: "archived",
+}
+
+func (s *postService) Bulk(req *models.BulkPostRequest) (*models.BulkPostResponse, error) {
+	status, changesStatus := bulkPostStatuses[req.Action]
+	if !changesStatus && req.Action != models.BulkPostDelete {
+		return nil, errors.New("unknown bulk action")
+	}
+
+	ids := make([]uint, 0, len(req.IDs))
+	seen := make(map[uint]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	var found map[uint]models.Post
+	err := s.postRepo.WithTransaction(func(repo repositories.PostRepository) error {
+		posts, err := repo.GetByIDs(ids)
+		if err != nil {
+			return err
+		}
+		found = make(map[uint]models.Post, len(posts))
+		for _, post := range posts {
+			found[post.ID] = post
+		}
+		if len(found) < len(ids) {
+			return ErrBulkRolledBack
+		}
+
+		if changesStatus {
+			_, err = repo.BulkUpdateStatus(ids, status)
+		} else {
+			_, err = repo.BulkDelete(ids)
+		}
+		return err
+	})
+
+	response := &models.BulkPostResponse{Action: req.Action, Results: make([]models.BulkPostResult, 0, len(ids))}
+	if errors.Is(err, ErrBulkRolledBack) {
+		for _, id := range ids {
+			result := models.BulkPostResult{ID: id, Status: models.BulkItemRolledBack}
+			if _, ok := found[id]; !ok {
+				result.Status = models.BulkItemFailed
+				result.Error = "post not found"
+				response.Failed++
+			}
+			response.Results = append(response.Results, result)
+		}
+		return response, ErrBulkRolledBack
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		s.publishBulkEvents(found[id], req.Action, status, now)
+		response.Results = append(response.Results, models.BulkPostResult{ID: id, Status: models.BulkItemApplied})
+		response.Applied++
+	}
+	return response, nil
+}
+
+// publishBulkEvents announces what a committed bulk action did to one post,
+// as the single-post flows would have
+func (s *postService) publishBulkEvents(post models.Post, action, status string, now time.Time) {
+	ctx := context.Background()
+	if action == models.BulkPostDelete {
+		s.bus.Publish(ctx, PostEvent{Type: EventPostDeleted, Post: post})
+		return
+	}
+	if post.Status == status && !(status == "published" && isScheduled(post.PublishAt)) {
+		return
+	}
+
+	previous := post
+	post.Status = status
+	if status == "published" {
+		post.PublishAt = nil
+		if post.PublishedAt == nil {
+			post.PublishedAt = &now
+		}
+	}
+	s.bus.Publish(ctx, PostEvent{Type: EventPostUpdated, Post: post, Previous: &previous})
+	if post.Status != previous.Status {
+		s.bus.Publish(ctx, PostEvent{Type: EventPostStatusChanged, Post: post, Previous: &previous})
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/events"
+	"backend/pkg/logger"
+	"backend/pkg/scheduler"
+
+	"go.uber.org/zap"
+)
+
+// PostCountService keeps the cached Category.PostCount column in step with
+// the number of published posts in each category
+type PostCountService interface {
+	// Subscribe registers incremental count updates for post events
+	Subscribe(bus *events.Bus)
+	// Reconcile recomputes every category's count and repairs any drift. If
+	// ctx is cancelled it stops between categories with a
+	// *scheduler.InterruptedError.
+	Reconcile(ctx context.Context) error
+}
+
+type postCountService struct {
+	postRepo     repositories.PostRepository
+	categoryRepo repositories.CategoryRepository
+}
+
+func NewPostCountService(postRepo repositories.PostRepository, categoryRepo repositories.CategoryRepository) PostCountService {
+	return &postCountService{
+		postRepo:     postRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (s *postCountService) Subscribe(bus *events.Bus) {
+	bus.Subscribe(EventPostCreated, s.handlePostEvent)
+	bus.Subscribe(EventPostUpdated, s.handlePostEvent)
+	bus.Subscribe(EventPostDeleted, s.handlePostEvent)
+	bus.Subscribe(EventPostRestored, s.handlePostEvent)
+}
+
+func (s *postCountService) handlePostEvent(ctx context.Context, event events.Event) {
+	postEvent, ok := event.(PostEvent)
+	if !ok {
+		return
+	}
+
+	deltas := make(map[uint]int64)
+	if postEvent.Previous != nil && countsTowardCategory(postEvent.Previous) {
+		deltas[postEvent.Previous.CategoryID]--
+	}
+	if countsTowardCategory(&postEvent.Post) {
+		if postEvent.Type == EventPostDeleted {
+			deltas[postEvent.Post.CategoryID]--
+		} else {
+			deltas[postEvent.Post.CategoryID]++
+		}
+	}
+
+	for categoryID, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		// A failed adjustment is left for the reconciliation job to repair
+		if err := s.categoryRepo.AdjustPostCount(ctx, categoryID, delta); err != nil {
+			logger.LogError(ctx, "Failed to adjust category post count", err,
+				zap.Uint("category_id", categoryID),
+				zap.Int64("delta", delta),
+			)
+		}
+	}
+}
+
+func (s *postCountService) Reconcile(ctx context.Context) error {
+	actual, err := s.postRepo.CountPublishedByCategory(ctx)
+	if err != nil {
+		return err
+	}
+
+	cached, err := s.categoryRepo.ListPostCounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	checked, fixed := 0, 0
+	for categoryID, count := range cached {
+		if err := scheduler.Checkpoint(ctx, checked, len(cached)); err != nil {
+			return err
+		}
+		checked++
+		if count == actual[categoryID] {
+			continue
+		}
+		if err := s.categoryRepo.SetPostCount(ctx, categoryID, actual[categoryID]); err != nil {
+			return err
+		}
+		fixed++
+	}
+
+	if fixed > 0 {
+		logger.LogInfo(ctx, "Reconciled category post counts", zap.Int("fixed", fixed))
+	}
+	return nil
+}
+
+// countsTowardCategory reports whether a post is included in its category's count
+func countsTowardCategory(post *models.Post) bool {
+	return post.Status == "published"
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"backend/internal/models"
+	"backend/pkg/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCountedPostService() (PostService, PostCountService, *fakePostRepo, *fakeCategoryRepo) {
+	postRepo := newFakePostRepo()
+	categoryRepo := newFakeCategoryRepo(
+		&models.Category{ID: 1, Name: "Go", Slug: "go"},
+		&models.Category{ID: 2, Name: "Vue", Slug: "vue"},
+	)
+
+	bus := events.NewBus()
+	countService := NewPostCountService(postRepo, categoryRepo)
+	countService.Subscribe(bus)
+
+	return newTestPostService(withPostRepo(postRepo), withCategoryRepo(categoryRepo), withBus(bus)), countService, postRepo, categoryRepo
+}
+
+func postCount(t *testing.T, repo *fakeCategoryRepo, categoryID uint) int64 {
+	category, err := repo.GetByID(categoryID)
+	require.NoError(t, err)
+	return category.PostCount
+}
+
+func TestPostCountService_IncrementalUpdates(t *testing.T) {
+	t.Run("creating a published post increments its category", func(t *testing.T) {
+		postService, _, _, categoryRepo := newCountedPostService()
+
+		_, _, err := postService.Create(&models.CreatePostRequest{
+			Title:      "Published right away",
+			Content:    "Content",
+			CategoryID: 1,
+			Status:     "published",
+		}, 1, "author")
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1), postCount(t, categoryRepo, 1))
+		assert.Equal(t, int64(0), postCount(t, categoryRepo, 2))
+	})
+
+	t.Run("drafts only count once published", func(t *testing.T) {
+		postService, _, _, categoryRepo := newCountedPostService()
+
+		post, _, err := postService.Create(&models.CreatePostRequest{
+			Title:      "Work in progress",
+			Content:    "Content",
+			CategoryID: 1,
+		}, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), postCount(t, categoryRepo, 1))
+
+		_, _, err = postService.Update(post.ID, &models.UpdatePostRequest{Status: stringPtr("published")}, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), postCount(t, categoryRepo, 1))
+
+		_, _, err = postService.Update(post.ID, &models.UpdatePostRequest{Status: stringPtr("archived")}, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), postCount(t, categoryRepo, 1))
+	})
+
+	t.Run("moving a post between categories adjusts both", func(t *testing.T) {
+		postService, _, _, categoryRepo := newCountedPostService()
+
+		post, _, err := postService.Create(&models.CreatePostRequest{
+			Title:      "On the move",
+			Content:    "Content",
+			CategoryID: 1,
+			Status:     "published",
+		}, 1, "author")
+		require.NoError(t, err)
+
+		_, _, err = postService.Update(post.ID, &models.UpdatePostRequest{CategoryID: uintPtr(2)}, 1, "author")
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(0), postCount(t, categoryRepo, 1))
+		assert.Equal(t, int64(1), postCount(t, categoryRepo, 2))
+	})
+
+	t.Run("deleting a published post decrements its category", func(t *testing.T) {
+		postService, _, _, categoryRepo := newCountedPostService()
+
+		post, _, err := postService.Create(&models.CreatePostRequest{
+			Title:      "Short lived",
+			Content:    "Content",
+			CategoryID: 2,
+			Status:     "published",
+		}, 1, "author")
+		require.NoError(t, err)
+
+		require.NoError(t, postService.Delete(post.ID, 1, "author"))
+		assert.Equal(t, int64(0), postCount(t, categoryRepo, 2))
+	})
+
+	t.Run("restoring a published post counts it again", func(t *testing.T) {
+		postService, _, _, categoryRepo := newCountedPostService()
+
+		post, _, err := postService.Create(&models.CreatePostRequest{
+			Title:      "Back from the trash",
+			Content:    "Content",
+			CategoryID: 2,
+			Status:     "published",
+		}, 1, "author")
+		require.NoError(t, err)
+		require.NoError(t, postService.Delete(post.ID, 1, "author"))
+
+		_, err = postService.Restore(post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), postCount(t, categoryRepo, 2))
+	})
+}
+
+func TestPostCountService_Reconcile(t *testing.T) {
+	postService, countService, _, categoryRepo := newCountedPostService()
+
+	for _, title := range []string{"First", "Second"} {
+		_, _, err := postService.Create(&models.CreatePostRequest{
+			Title:      title,
+			Content:    "Content",
+			CategoryID: 1,
+			Status:     "published",
+		}, 1, "author")
+		require.NoError(t, err)
+	}
+
+	// Simulate drift from a missed event
+	require.NoError(t, categoryRepo.SetPostCount(context.Background(), 1, 42))
+	require.NoError(t, categoryRepo.SetPostCount(context.Background(), 2, 7))
+
+	require.NoError(t, countService.Reconcile(context.Background()))
+
+	assert.Equal(t, int64(2), postCount(t, categoryRepo, 1))
+	assert.Equal(t, int64(0), postCount(t, categoryRepo, 2))
+}
@@ -0,0 +1,34 @@
+package services
+
+import "backend/internal/models"
+
+// Post lifecycle events published on the event bus
+const (
+	EventPostCreated       = "post.created"
+	EventPostUpdated       = "post.updated"
+	EventPostDeleted       = "post.deleted"
+	EventPostStatusChanged = "post.status_changed"
+	// EventPostRestored is published when an admin brings a deleted post
+	// back from the trash
+	EventPostRestored = "post.restored"
+	// EventDraftArchived is published when the draft archive job archives a
+	// draft its author abandoned, in addition to the update events
+	EventDraftArchived = "post.draft_archived"
+	// EventScheduledPublished is published when the scheduled publish job
+	// publishes a post whose PublishAt has passed, in addition to the update
+	// events
+	EventScheduledPublished = "post.scheduled_published"
+)
+
+// PostEvent describes a change to a post. Previous holds the post as it was
+// before an update or status change and is nil for creates and deletes.
+type PostEvent struct {
+	Type     string
+	Post     models.Post
+	Previous *models.Post
+}
+
+// Name implements events.Event
+func (e PostEvent) Name() string {
+	return e.Type
+}
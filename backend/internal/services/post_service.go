@@ -1,123 +1,556 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math/rand"
+	"time"
 
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/repositories"
+	"backend/pkg/content"
+	"backend/pkg/events"
 	"backend/pkg/utils"
 
 	"gorm.io/gorm"
 )
 
 type PostService interface {
-	Create(req *models.CreatePostRequest, authorID uint) (*models.Post, error)
+	// WithContext returns a copy of the service whose queries run with ctx,
+	// typically the request's, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) PostService
+	// Create and Update return warnings about a post that was saved but may
+	// need attention, such as a title shared with another post
+	Create(req *models.CreatePostRequest, authorID uint, authorRole string) (*models.Post, []models.Warning, error)
 	GetByID(id uint) (*models.Post, error)
 	GetBySlug(slug string) (*models.Post, error)
-	Update(id uint, req *models.UpdatePostRequest, userID uint, userRole string) (*models.Post, error)
+	GetBySlugs(slugs []string, viewerID uint, viewerRole string) ([]models.Post, error)
+	// GetByIDs is GetBySlugs for IDs
+	GetByIDs(ids []uint, viewerID uint, viewerRole string) ([]models.Post, error)
+	Update(id uint, req *models.UpdatePostRequest, userID uint, userRole string) (*models.Post, []models.Warning, error)
 	Delete(id uint, userID uint, userRole string) error
+	// Bulk applies one action to many posts in a single transaction. If any
+	// post cannot be found nothing changes, and the response, returned
+	// along with ErrBulkRolledBack, says which posts failed.
+	Bulk(req *models.BulkPostRequest) (*models.BulkPostResponse, error)
+	// Transfer hands a post to another author or editor on behalf of the
+	// admin actorID, recording the change in the audit log
+	Transfer(id, authorID, actorID uint) (*models.Post, error)
+	// TransferAll is Transfer for every post of one author, e.g. when they
+	// leave. It returns how many posts were moved.
+	TransferAll(fromAuthorID, toAuthorID, actorID uint) (int64, error)
+	// ListTrashed pages through deleted posts, most recently deleted first
+	ListTrashed(page, perPage int) ([]models.Post, int64, error)
+	// Restore brings a deleted post back as it was. Posts that are not in
+	// the trash yield ErrPostNotTrashed.
+	Restore(id uint) (*models.Post, error)
 	List(page, perPage int, filters map[string]interface{}) ([]models.Post, int64, error)
-	Search(req *models.PostSearchRequest) ([]models.Post, int64, error)
-	GetByAuthor(authorID uint, page, perPage int) ([]models.Post, int64, error)
-	GetByCategory(categoryID uint, page, perPage int) ([]models.Post, int64, error)
+	// Search applies the same visibility rules as the listings: anonymous
+	// callers only find published posts, signed-in users also find their own
+	// unpublished posts, and admins find everything.
+	Search(req *models.PostSearchRequest, viewerID uint, viewerRole string) ([]models.Post, int64, string, error)
+	// GetByAuthor and GetByCategory list the posts the viewer may see, with a
+	// total over that same set. Anonymous callers pass a zero viewerID and an
+	// empty role.
+	GetByAuthor(authorID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Post, int64, error)
+	GetByCategory(categoryID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Post, int64, error)
+	// GetByTag lists the posts carrying the tag with the given slug, like
+	// GetByCategory. An unknown tag yields ErrTagNotFound.
+	GetByTag(slug string, page, perPage int, viewerID uint, viewerRole string) ([]models.Post, int64, error)
+	// Random returns a published post picked at random, whoever is asking.
+	// It yields ErrNoPublishedPosts when there are none.
+	Random() (*models.Post, error)
+	// Usage reports how many posts the author owns against their role's limit
+	Usage(authorID uint, authorRole string) (*models.PostUsage, error)
 }
 
+// MaxSlugsPerRequest caps how many posts GetBySlugs fetches at once
+const MaxSlugsPerRequest = 50
+
+// ErrPostLimitReached is returned when an author already owns as many posts as
+// their role allows
+var ErrPostLimitReached = errors.New("post limit reached")
+
+// ErrPostingTooFrequently is returned when an author creates a post sooner
+// than the configured minimum interval after their previous one
+var ErrPostingTooFrequently = errors.New("posting too frequently")
+
+// ErrNoPublishedPosts is returned by Random when there is nothing to pick
+var ErrNoPublishedPosts = errors.New("no published posts")
+
+// ErrPostNotTrashed is returned by Restore for posts that were never deleted,
+// or were purged since
+var ErrPostNotTrashed = errors.New("post is not in the trash")
+
+// ErrDuplicateTitle is returned in strict mode when another post already has
+// the title
+var ErrDuplicateTitle = errors.New("a post with this title already exists")
+
+// Duplicate title modes, see config.PostConfig.DuplicateTitles
+const (
+	DuplicateTitlesOff    = "off"
+	DuplicateTitlesWarn   = "warn"
+	DuplicateTitlesStrict = "strict"
+)
+
+// Ways of showing a deleted author or category, see
+// config.PostConfig.DeletedRelations
+const (
+	DeletedRelationsUnscoped    = "unscoped"
+	DeletedRelationsPlaceholder = "placeholder"
+)
+
 type postService struct {
-	postRepo     repositories.PostRepository
-	userRepo     repositories.UserRepository
-	categoryRepo repositories.CategoryRepository
+	postRepo        repositories.PostRepository
+	userRepo        repositories.UserRepository
+	categoryRepo    repositories.CategoryRepository
+	tagRepo         repositories.TagRepository
+	uow             repositories.UnitOfWork // saves a post together with its tags
+	postLimits      map[string]int
+	minInterval     time.Duration
+	duplicateTitles string
+	images          *ImageRewriter
+	imageHosts      *ImageHosts
+	placeholders    bool // hide deleted authors and categories behind placeholders
+	bus             *events.Bus
+	// requireVerified stops authors without a verified email from posting
+	requireVerified bool
+	now             func() time.Time
+	// randN picks a number in [0, n)
+	randN func(n int64) int64
 }
 
-func NewPostService(postRepo repositories.PostRepository, userRepo repositories.UserRepository, categoryRepo repositories.CategoryRepository) PostService {
+// NewPostService builds the post service from the Posts, Users, Categories
+// and Tags repositories in repos; the others are ignored
+func NewPostService(repos repositories.Repositories, uow repositories.UnitOfWork, cfg *config.Config, bus *events.Bus) PostService {
 	return &postService{
-		postRepo:     postRepo,
-		userRepo:     userRepo,
-		categoryRepo: categoryRepo,
+		postRepo:        repos.Posts,
+		userRepo:        repos.Users,
+		categoryRepo:    repos.Categories,
+		tagRepo:         repos.Tags,
+		uow:             uow,
+		postLimits:      cfg.Post.LimitByRole,
+		minInterval:     cfg.Post.MinInterval,
+		duplicateTitles: cfg.Post.DuplicateTitles,
+		images:          NewImageRewriter(cfg.Storage.PublicBaseURL(), cfg.Storage.CDNBaseURL),
+		imageHosts:      NewImageHosts(cfg.Post.ImageHosts, cfg.Storage.PublicBaseURL(), cfg.Storage.CDNBaseURL),
+		placeholders:    cfg.Post.DeletedRelations == DeletedRelationsPlaceholder,
+		bus:             bus,
+		requireVerified: cfg.Auth.RequireEmailVerification,
+		now:             time.Now,
+		randN:           rand.Int63n,
+	}
+}
+
+func (s *postService) WithContext(ctx context.Context) PostService {
+	scoped := *s
+	scoped.postRepo = s.postRepo.WithContext(ctx)
+	if s.userRepo != nil {
+		scoped.userRepo = s.userRepo.WithContext(ctx)
+	}
+	if s.categoryRepo != nil {
+		scoped.categoryRepo = s.categoryRepo.WithContext(ctx)
 	}
+	if s.tagRepo != nil {
+		scoped.tagRepo = s.tagRepo.WithContext(ctx)
+	}
+	if s.uow != nil {
+		scoped.uow = s.uow.WithContext(ctx)
+	}
+	return &scoped
 }
 
-func (s *postService) Create(req *models.CreatePostRequest, authorID uint) (*models.Post, error) {
+func (s *postService) Create(req *models.CreatePostRequest, authorID uint, authorRole string) (*models.Post, []models.Warning, error) {
+	if s.requireVerified {
+		author, err := s.userRepo.GetByID(authorID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !author.EmailVerified {
+			return nil, nil, ErrEmailNotVerified
+		}
+	}
+
+	if limit := s.postLimit(authorRole); limit > 0 {
+		count, err := s.postRepo.CountByAuthor(authorID, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if count >= int64(limit) {
+			return nil, nil, ErrPostLimitReached
+		}
+	}
+	if err := s.checkInterval(authorID, authorRole); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.imageHosts.Check(req.ThumbnailURL); err != nil {
+		return nil, nil, err
+	}
+
 	// Verify category exists
 	if _, err := s.categoryRepo.GetByID(req.CategoryID); err != nil {
-		return nil, errors.New("category not found")
+		return nil, nil, errors.New("category not found")
 	}
 
-	// Generate slug from title
-	slug := utils.GenerateSlug(req.Title)
+	warnings, err := s.checkTitle(req.Title, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tags, err := tagsFromNames(req.Tags)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Set default status if not provided
 	status := req.Status
 	if status == "" {
 		status = "draft"
 	}
+	// A post scheduled for later waits as a draft until it is due
+	if status == "published" && isScheduled(req.PublishAt) {
+		status = "draft"
+	}
 
 	post := &models.Post{
-		Title:      req.Title,
-		Slug:       slug,
-		Content:    req.Content,
-		Excerpt:    req.Excerpt,
-		CategoryID: req.CategoryID,
-		AuthorID:   authorID,
-		Status:     status,
+		Title:        req.Title,
+		Content:      req.Content,
+		Excerpt:      req.Excerpt,
+		ThumbnailURL: req.ThumbnailURL,
+		CategoryID:   req.CategoryID,
+		AuthorID:     authorID,
+		Status:       status,
+		PublishAt:    req.PublishAt,
+	}
+	if status == "published" {
+		now := time.Now()
+		post.PublishedAt = &now
 	}
+	content.Process(post)
+
+	// The post and its tags are saved together, so failing to tag it leaves
+	// no untagged post behind
+	err = s.uow.Do(func(repos repositories.Repositories) error {
+		if err := repos.Posts.Create(post); err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		stored, err := repos.Tags.FindOrCreate(tags)
+		if err != nil {
+			return err
+		}
+		return repos.Posts.ReplaceTags(post, stored)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.bus.Publish(context.Background(), PostEvent{Type: EventPostCreated, Post: *post})
+
+	created, err := s.getByID(post.ID)
+	return created, warnings, err
+}
 
-	if err := s.postRepo.Create(post); err != nil {
+func (s *postService) Usage(authorID uint, authorRole string) (*models.PostUsage, error) {
+	count, err := s.postRepo.CountByAuthor(authorID, false)
+	if err != nil {
 		return nil, err
 	}
+	return &models.PostUsage{Count: count, Limit: s.postLimit(authorRole)}, nil
+}
+
+// checkTitle looks for another post with the same title, ignoring case. In
+// warn mode it returns a warning naming that post; in strict mode the save is
+// refused with ErrDuplicateTitle.
+func (s *postService) checkTitle(title string, excludeID uint) ([]models.Warning, error) {
+	if s.duplicateTitles != DuplicateTitlesWarn && s.duplicateTitles != DuplicateTitlesStrict {
+		return nil, nil
+	}
+
+	existing, err := s.postRepo.FindByTitle(title, excludeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if s.duplicateTitles == DuplicateTitlesStrict {
+		return nil, fmt.Errorf("%w (post %d)", ErrDuplicateTitle, existing.ID)
+	}
+	return []models.Warning{{
+		Code:    models.WarningDuplicateTitle,
+		Message: fmt.Sprintf("Another post is already titled \"%s\"", existing.Title),
+		Details: models.PostRef{
+			ID:     existing.ID,
+			Title:  existing.Title,
+			Slug:   existing.Slug,
+			Status: existing.Status,
+		},
+	}}, nil
+}
 
-	return s.postRepo.GetByID(post.ID)
+// postLimit returns the most posts a user with the role may own, or zero when
+// the role is unlimited
+func (s *postService) postLimit(role string) int {
+	if role == "admin" || role == "editor" {
+		return 0
+	}
+	if limit := s.postLimits[role]; limit > 0 {
+		return limit
+	}
+	return 0
+}
+
+// checkInterval rejects a post the author creates within the minimum interval
+// of their previous one. Admins and editors are exempt.
+func (s *postService) checkInterval(authorID uint, role string) error {
+	if s.minInterval <= 0 || role == "admin" || role == "editor" {
+		return nil
+	}
+
+	latest, err := s.postRepo.LatestCreatedAt(authorID)
+	if err != nil {
+		return err
+	}
+	if !latest.IsZero() && s.now().Sub(latest) < s.minInterval {
+		return ErrPostingTooFrequently
+	}
+	return nil
 }
 
 func (s *postService) GetByID(id uint) (*models.Post, error) {
-	return s.postRepo.GetByID(id)
+	return s.getByID(id)
 }
 
 func (s *postService) GetBySlug(slug string) (*models.Post, error) {
-	return s.postRepo.GetBySlug(slug)
+	return s.withImages(s.postRepo.GetBySlug(utils.NormalizeSlug(slug)))
+}
+
+// Random counts the published posts and fetches one at a random offset, two
+// queries however many posts there are, instead of shuffling the table with
+// ORDER BY RAND()
+func (s *postService) Random() (*models.Post, error) {
+	total, err := s.postRepo.CountPublished()
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, ErrNoPublishedPosts
+	}
+	return s.withImages(s.postRepo.GetPublishedByOffset(s.randN(total)))
+}
+
+// getByID loads a post as readers see it. Posts about to be saved are loaded
+// straight from the repository instead, so stored content keeps its
+// original image URLs.
+func (s *postService) getByID(id uint) (*models.Post, error) {
+	return s.withImages(s.postRepo.GetByID(id))
+}
+
+// present readies a loaded post for callers: its images point at the CDN
+// when one is configured, and a deleted author or category is filled in
+func (s *postService) present(post *models.Post) {
+	s.images.RewritePost(post)
+	post.FillDeletedRelations(s.placeholders)
+}
+
+// withImages presents a loaded post
+func (s *postService) withImages(post *models.Post, err error) (*models.Post, error) {
+	if err != nil {
+		return nil, err
+	}
+	s.present(post)
+	return post, nil
+}
+
+// withListImages is withImages for a page of posts
+func (s *postService) withListImages(posts []models.Post, total int64, err error) ([]models.Post, int64, error) {
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range posts {
+		s.present(&posts[i])
+	}
+	return posts, total, nil
 }
 
-func (s *postService) Update(id uint, req *models.UpdatePostRequest, userID uint, userRole string) (*models.Post, error) {
+// GetBySlugs returns the posts for the given slugs in the order requested.
+// Missing slugs, duplicates and posts the viewer may not see are skipped.
+// Anonymous callers pass a zero viewerID and an empty role.
+func (s *postService) GetBySlugs(slugs []string, viewerID uint, viewerRole string) ([]models.Post, error) {
+	if len(slugs) > MaxSlugsPerRequest {
+		return nil, fmt.Errorf("too many slugs requested (max %d)", MaxSlugsPerRequest)
+	}
+
+	normalized := make([]string, len(slugs))
+	for i, slug := range slugs {
+		normalized[i] = utils.NormalizeSlug(slug)
+	}
+	slugs = normalized
+
+	found, err := s.postRepo.GetBySlugs(slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	bySlug := make(map[string]models.Post, len(found))
+	for _, post := range found {
+		bySlug[post.Slug] = post
+	}
+
+	posts := make([]models.Post, 0, len(slugs))
+	for _, slug := range slugs {
+		post, ok := bySlug[slug]
+		if !ok || !CanViewPost(&post, viewerID, viewerRole) {
+			continue
+		}
+		s.present(&post)
+		posts = append(posts, post)
+		delete(bySlug, slug)
+	}
+
+	return posts, nil
+}
+
+// GetByIDs returns the posts for the given IDs in the order requested,
+// skipping missing IDs and posts the viewer may not see
+func (s *postService) GetByIDs(ids []uint, viewerID uint, viewerRole string) ([]models.Post, error) {
+	found, err := s.postRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Post, len(found))
+	for _, post := range found {
+		byID[post.ID] = post
+	}
+
+	posts := make([]models.Post, 0, len(ids))
+	for _, id := range ids {
+		post, ok := byID[id]
+		if !ok || !CanViewPost(&post, viewerID, viewerRole) {
+			continue
+		}
+		s.present(&post)
+		posts = append(posts, post)
+		delete(byID, id)
+	}
+
+	return posts, nil
+}
+
+func (s *postService) Update(id uint, req *models.UpdatePostRequest, userID uint, userRole string) (*models.Post, []models.Warning, error) {
 	// Get existing post
 	post, err := s.postRepo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
+			return nil, nil, errors.New("post not found")
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Check permission - authors can only edit their own posts, admins can edit any
-	if userRole != "admin" && post.AuthorID != userID {
-		return nil, errors.New("you don't have permission to update this post")
+	// Check permission - authors can only edit their own posts, editors and
+	// admins can edit any
+	if !CanEditAnyPost(userRole) && post.AuthorID != userID {
+		return nil, nil, errors.New("you don't have permission to update this post")
 	}
 
-	// Update fields if provided
-	if req.Title != "" {
-		post.Title = req.Title
-		post.Slug = utils.GenerateSlug(req.Title)
+	previous := *post
+	post.UpdatedBy = &userID
+
+	// Only fields present in the request change. Title and content are
+	// required, so they cannot be cleared; the other text fields can.
+	if req.Title != nil {
+		if *req.Title == "" {
+			return nil, nil, errors.New("title cannot be empty")
+		}
+		post.Title = *req.Title
+		// Cleared so Process derives the slug from the new title
+		post.Slug = ""
+	}
+	if req.Content != nil {
+		if *req.Content == "" {
+			return nil, nil, errors.New("content cannot be empty")
+		}
+		// Without a new excerpt, one derived from the old content, or none
+		// at all, is derived again from the new content
+		if req.Excerpt == nil && (post.Excerpt == "" || post.Excerpt == content.Excerpt(content.PlainText(post.Content))) {
+			post.Excerpt = content.Excerpt(content.PlainText(*req.Content))
+		}
+		post.Content = *req.Content
 	}
-	if req.Content != "" {
-		post.Content = req.Content
+	if req.Excerpt != nil {
+		post.Excerpt = *req.Excerpt
 	}
-	if req.Excerpt != "" {
-		post.Excerpt = req.Excerpt
+	if req.ThumbnailURL != nil && *req.ThumbnailURL != post.ThumbnailURL {
+		if err := s.imageHosts.Check(*req.ThumbnailURL); err != nil {
+			return nil, nil, err
+		}
+		post.ThumbnailURL = *req.ThumbnailURL
 	}
-	if req.CategoryID != 0 {
+	if req.CategoryID != nil {
 		// Verify new category exists
-		if _, err := s.categoryRepo.GetByID(req.CategoryID); err != nil {
-			return nil, errors.New("category not found")
+		if _, err := s.categoryRepo.GetByID(*req.CategoryID); err != nil {
+			return nil, nil, errors.New("category not found")
 		}
-		post.CategoryID = req.CategoryID
+		post.CategoryID = *req.CategoryID
+	}
+	if req.Status != nil {
+		post.Status = *req.Status
+	}
+	if req.CommentsEnabled != nil {
+		post.CommentsEnabled = *req.CommentsEnabled
 	}
-	if req.Status != "" {
-		post.Status = req.Status
+	if req.PublishAt != nil {
+		post.PublishAt = req.PublishAt
+	}
+	if post.Status == "published" && isScheduled(post.PublishAt) && previous.Status != "published" {
+		post.Status = "draft"
+	}
+	if post.Status == "published" && post.PublishedAt == nil {
+		now := time.Now()
+		post.PublishedAt = &now
+	}
+
+	content.Refresh(post)
+
+	var warnings []models.Warning
+	if post.Title != previous.Title {
+		if warnings, err = s.checkTitle(post.Title, post.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var tags []models.Tag
+	if req.Tags != nil {
+		if tags, err = s.resolveTags(*req.Tags); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	if err := s.postRepo.Update(post); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if req.Tags != nil {
+		if err := s.postRepo.ReplaceTags(post, tags); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ctx := context.Background()
+	s.bus.Publish(ctx, PostEvent{Type: EventPostUpdated, Post: *post, Previous: &previous})
+	if post.Status != previous.Status {
+		s.bus.Publish(ctx, PostEvent{Type: EventPostStatusChanged, Post: *post, Previous: &previous})
 	}
 
-	return s.postRepo.GetByID(post.ID)
+	updated, err := s.getByID(post.ID)
+	return updated, warnings, err
 }
 
 func (s *postService) Delete(id uint, userID uint, userRole string) error {
@@ -135,21 +568,106 @@ func (s *postService) Delete(id uint, userID uint, userRole string) error {
 		return errors.New("you don't have permission to delete this post")
 	}
 
-	return s.postRepo.Delete(id)
+	if err := s.postRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.bus.Publish(context.Background(), PostEvent{Type: EventPostDeleted, Post: *post})
+	return nil
+}
+
+func (s *postService) ListTrashed(page, perPage int) ([]models.Post, int64, error) {
+	return s.withListImages(s.postRepo.ListTrashed(page, perPage))
+}
+
+func (s *postService) Restore(id uint) (*models.Post, error) {
+	if err := s.postRepo.Restore(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotTrashed
+		}
+		return nil, err
+	}
+
+	post, err := s.postRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.bus.Publish(context.Background(), PostEvent{Type: EventPostRestored, Post: *post})
+	return s.withImages(post, nil)
 }
 
 func (s *postService) List(page, perPage int, filters map[string]interface{}) ([]models.Post, int64, error) {
-	return s.postRepo.List(page, perPage, filters)
+	return s.withListImages(s.postRepo.List(page, perPage, filters))
 }
 
-func (s *postService) Search(req *models.PostSearchRequest) ([]models.Post, int64, error) {
-	return s.postRepo.Search(req)
+func (s *postService) Search(req *models.PostSearchRequest, viewerID uint, viewerRole string) ([]models.Post, int64, string, error) {
+	switch {
+	case CanEditAnyPost(viewerRole):
+	case viewerID == 0:
+		// Asking for drafts must not fall back to listing published posts
+		if req.Status != "" && req.Status != "published" {
+			return []models.Post{}, 0, models.SearchModeNone, nil
+		}
+		req.Status = "published"
+	default:
+		req.OwnUnpublishedOf = viewerID
+	}
+
+	posts, total, mode, err := s.postRepo.Search(req)
+	posts, total, err = s.withListImages(posts, total, err)
+	return posts, total, mode, err
 }
 
-func (s *postService) GetByAuthor(authorID uint, page, perPage int) ([]models.Post, int64, error) {
-	return s.postRepo.GetByAuthor(authorID, page, perPage)
+func (s *postService) GetByAuthor(authorID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Post, int64, error) {
+	status := "published"
+	if CanEditAnyPost(viewerRole) || (viewerID != 0 && viewerID == authorID) {
+		status = ""
+	}
+	return s.withListImages(s.postRepo.GetByAuthor(authorID, status, page, perPage))
+}
+
+func (s *postService) GetByCategory(categoryID uint, page, perPage int, viewerID uint, viewerRole string) ([]models.Post, int64, error) {
+	status := "published"
+	if CanEditAnyPost(viewerRole) {
+		status = ""
+	}
+	return s.withListImages(s.postRepo.GetByCategory(categoryID, status, page, perPage))
+}
+
+func (s *postService) GetByTag(slug string, page, perPage int, viewerID uint, viewerRole string) ([]models.Post, int64, error) {
+	tag, err := s.tagRepo.GetBySlug(utils.NormalizeSlug(slug))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, ErrTagNotFound
+		}
+		return nil, 0, err
+	}
+
+	status := "published"
+	if CanEditAnyPost(viewerRole) {
+		status = ""
+	}
+	return s.withListImages(s.postRepo.GetByTag(tag.ID, status, page, perPage))
+}
+
+// isScheduled reports whether publishAt is still in the future
+func isScheduled(publishAt *time.Time) bool {
+	return publishAt != nil && publishAt.After(time.Now())
+}
+
+// CanViewPost reports whether a viewer may read a post: published posts are
+// public once any PublishAt has passed, anything else is visible only to its
+// author, editors and admins
+func CanViewPost(post *models.Post, viewerID uint, viewerRole string) bool {
+	if (post.Status == "published" && !isScheduled(post.PublishAt)) || CanEditAnyPost(viewerRole) {
+		return true
+	}
+	return viewerID != 0 && post.AuthorID == viewerID
 }
 
-func (s *postService) GetByCategory(categoryID uint, page, perPage int) ([]models.Post, int64, error) {
-	return s.postRepo.GetByCategory(categoryID, page, perPage)
+// CanEditAnyPost reports whether the role may edit, and so also see, posts
+// written by others
+func CanEditAnyPost(role string) bool {
+	return role == "admin" || role == "editor"
 }
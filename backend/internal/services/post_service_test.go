@@ -1,8 +1,12 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/testutils"
 
@@ -114,7 +118,7 @@ func TestPostService_CreatePost(t *testing.T) {
 	mockPostRepo := new(MockPostRepository)
 	mockUserRepo := new(MockUserRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
-	postService := NewPostService(mockPostRepo, mockUserRepo, mockCategoryRepo)
+	postService := newTestPostService(withPostRepo(mockPostRepo), withUserRepo(mockUserRepo), withCategoryRepo(mockCategoryRepo))
 
 	t.Run("successful post creation", func(t *testing.T) {
 		// Given
@@ -222,7 +226,7 @@ func TestPostService_GetPostByID(t *testing.T) {
 	mockPostRepo := new(MockPostRepository)
 	mockUserRepo := new(MockUserRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
-	postService := NewPostService(mockPostRepo, mockUserRepo, mockCategoryRepo)
+	postService := newTestPostService(withPostRepo(mockPostRepo), withUserRepo(mockUserRepo), withCategoryRepo(mockCategoryRepo))
 
 	t.Run("successful get post", func(t *testing.T) {
 		// Given
@@ -275,7 +279,7 @@ func TestPostService_UpdatePost(t *testing.T) {
 	mockPostRepo := new(MockPostRepository)
 	mockUserRepo := new(MockUserRepository)
 	mockCategoryRepo := new(MockCategoryRepository)
-	postService := NewPostService(mockPostRepo, mockUserRepo, mockCategoryRepo)
+	postService := newTestPostService(withPostRepo(mockPostRepo), withUserRepo(mockUserRepo), withCategoryRepo(mockCategoryRepo))
 
 	t.Run("successful post update by author", func(t *testing.T) {
 		// Given
@@ -397,7 +401,7 @@ func TestPostService_Integration(t *testing.T) {
 	categoryRepo := NewCategoryRepository(db)
 
 	// Create real service
-	postService := NewPostService(postRepo, userRepo, categoryRepo)
+	postService := newTestPostService(withPostRepo(postRepo), withUserRepo(userRepo), withCategoryRepo(categoryRepo))
 
 	t.Run("full post lifecycle", func(t *testing.T) {
 		// Create test user
@@ -460,3 +464,1043 @@ func TestPostService_Integration(t *testing.T) {
 		assert.Equal(t, "post not found", err.Error())
 	})
 }
+
+func TestPostService_CreateRequiresVerifiedEmail(t *testing.T) {
+	create := func(t *testing.T, requireVerified, verified bool) error {
+		userRepo := newFakeUserRepo(&models.User{ID: 1, Username: "author", EmailVerified: verified})
+		cfg := &config.Config{Auth: config.AuthConfig{RequireEmailVerification: requireVerified}}
+		postRepo := newFakePostRepo()
+		postService := newTestPostService(withPostRepo(postRepo), withUserRepo(userRepo), withCategoryRepo(newFakeCategoryRepo(&models.Category{ID: 1})), withConfig(cfg))
+		_, _, err := postService.Create(&models.CreatePostRequest{Title: "Hello", Content: "Body", CategoryID: 1}, 1, "author")
+		return err
+	}
+
+	t.Run("unverified authors may post when verification is not required", func(t *testing.T) {
+		assert.NoError(t, create(t, false, false))
+	})
+
+	t.Run("unverified authors are refused when verification is required", func(t *testing.T) {
+		assert.ErrorIs(t, create(t, true, false), ErrEmailNotVerified)
+	})
+
+	t.Run("verified authors may post when verification is required", func(t *testing.T) {
+		assert.NoError(t, create(t, true, true))
+	})
+}
+
+func TestPostService_ThumbnailHosts(t *testing.T) {
+	newService := func() (PostService, *fakePostRepo) {
+		postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Hello", Content: "Content", AuthorID: 1, CategoryID: 1, Status: "draft", ThumbnailURL: "https://old.example.net/a.png"})
+		categoryRepo := newFakeCategoryRepo(&models.Category{ID: 1, Name: "Go", Slug: "go"})
+		cfg := &config.Config{Storage: config.StorageConfig{
+			Driver:     "local",
+			BaseURL:    "http://localhost:8080",
+			CDNBaseURL: "https://cdn.example.com",
+		}}
+		return newTestPostService(withPostRepo(postRepo), withCategoryRepo(categoryRepo), withConfig(cfg)), postRepo
+	}
+	create := func(postService PostService, thumbnail string) (*models.Post, error) {
+		post, _, err := postService.Create(&models.CreatePostRequest{Title: "A new post", Content: "Content", CategoryID: 1, ThumbnailURL: thumbnail}, 1, "author")
+		return post, err
+	}
+
+	t.Run("a thumbnail on the CDN is accepted", func(t *testing.T) {
+		postService, postRepo := newService()
+
+		post, err := create(postService, "https://cdn.example.com/thumb.png")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://cdn.example.com/thumb.png", postRepo.posts[post.ID].ThumbnailURL)
+	})
+
+	t.Run("a thumbnail on an external host is rejected", func(t *testing.T) {
+		postService, postRepo := newService()
+
+		_, err := create(postService, "https://evil.example.net/thumb.png")
+
+		assert.ErrorIs(t, err, ErrImageURLNotAllowed)
+		assert.Len(t, postRepo.posts, 1)
+	})
+
+	t.Run("a non-http thumbnail is rejected", func(t *testing.T) {
+		postService, _ := newService()
+
+		_, _, err := postService.Update(1, &models.UpdatePostRequest{ThumbnailURL: stringPtr("javascript:alert(1)")}, 1, "author")
+
+		assert.ErrorIs(t, err, ErrImageURLNotAllowed)
+	})
+
+	t.Run("an unchanged thumbnail is not rechecked", func(t *testing.T) {
+		postService, _ := newService()
+
+		_, _, err := postService.Update(1, &models.UpdatePostRequest{ThumbnailURL: stringPtr("https://old.example.net/a.png"), Title: stringPtr("Hello again")}, 1, "author")
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestPostService_RewritesImagesOnRead(t *testing.T) {
+	stored := "![a](http://localhost:8080/uploads/a.png)\n\n![b](https://images.example.org/b.png)"
+	postRepo := newFakePostRepo(&models.Post{
+		ID:           1,
+		Slug:         "hello",
+		Status:       "published",
+		Content:      stored,
+		ThumbnailURL: "http://localhost:8080/uploads/thumb.png",
+	})
+	cfg := &config.Config{Storage: config.StorageConfig{
+		Driver:     "local",
+		BaseURL:    "http://localhost:8080",
+		CDNBaseURL: "https://cdn.example.com",
+	}}
+	postService := newTestPostService(withPostRepo(postRepo), withConfig(cfg))
+
+	post, err := postService.GetByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, "![a](https://cdn.example.com/a.png)\n\n![b](https://images.example.org/b.png)", post.Content)
+	assert.Equal(t, "https://cdn.example.com/thumb.png", post.ThumbnailURL)
+
+	posts, _, err := postService.GetByAuthor(0, 1, 10, 0, "")
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	assert.Equal(t, post.Content, posts[0].Content)
+
+	// The stored content keeps the origin URLs
+	assert.Equal(t, stored, postRepo.posts[1].Content)
+}
+
+func newUpdatablePostService() (PostService, *fakePostRepo) {
+	postRepo := newFakePostRepo(&models.Post{
+		ID:         1,
+		Title:      "Original title",
+		Slug:       "original-title",
+		Content:    "Original content",
+		Excerpt:    "Original excerpt",
+		CategoryID: 1,
+		AuthorID:   1,
+		Status:     "draft",
+	})
+	categoryRepo := newFakeCategoryRepo(
+		&models.Category{ID: 1, Name: "Go", Slug: "go"},
+		&models.Category{ID: 2, Name: "Rust", Slug: "rust"},
+	)
+	return newTestPostService(withPostRepo(postRepo), withCategoryRepo(categoryRepo)), postRepo
+}
+
+func TestPostService_UpdateOnlyChangesGivenFields(t *testing.T) {
+	t.Run("updating only the status leaves the rest alone", func(t *testing.T) {
+		postService, postRepo := newUpdatablePostService()
+
+		post, _, err := postService.Update(1, &models.UpdatePostRequest{Status: stringPtr("published")}, 1, "author")
+
+		require.NoError(t, err)
+		assert.Equal(t, "published", post.Status)
+		assert.NotNil(t, post.PublishedAt)
+		stored := postRepo.posts[1]
+		assert.Equal(t, "Original title", stored.Title)
+		assert.Equal(t, "original-title", stored.Slug)
+		assert.Equal(t, "Original content", stored.Content)
+		assert.Equal(t, "Original excerpt", stored.Excerpt)
+		assert.Equal(t, uint(1), stored.CategoryID)
+	})
+
+	t.Run("an empty excerpt clears it", func(t *testing.T) {
+		postService, postRepo := newUpdatablePostService()
+
+		_, _, err := postService.Update(1, &models.UpdatePostRequest{Excerpt: stringPtr("")}, 1, "author")
+
+		require.NoError(t, err)
+		assert.Empty(t, postRepo.posts[1].Excerpt)
+		assert.Equal(t, "Original content", postRepo.posts[1].Content)
+	})
+
+	t.Run("new content refreshes a derived excerpt but not a written one", func(t *testing.T) {
+		postService, postRepo := newUpdatablePostService()
+
+		_, _, err := postService.Update(1, &models.UpdatePostRequest{Content: stringPtr("**Rewritten** content")}, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, "Original excerpt", postRepo.posts[1].Excerpt)
+		assert.Equal(t, 2, postRepo.posts[1].WordCount)
+
+		_, _, err = postService.Update(1, &models.UpdatePostRequest{Excerpt: stringPtr("")}, 1, "author")
+		require.NoError(t, err)
+		_, _, err = postService.Update(1, &models.UpdatePostRequest{Content: stringPtr("Rewritten once more")}, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, "Rewritten once more", postRepo.posts[1].Excerpt)
+		assert.Equal(t, 3, postRepo.posts[1].WordCount)
+		assert.Equal(t, 1, postRepo.posts[1].ReadingTime)
+		assert.Equal(t, "original-title", postRepo.posts[1].Slug)
+	})
+
+	t.Run("a new title regenerates the slug", func(t *testing.T) {
+		postService, postRepo := newUpdatablePostService()
+
+		_, _, err := postService.Update(1, &models.UpdatePostRequest{Title: stringPtr("Brand new title")}, 1, "author")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Brand new title", postRepo.posts[1].Title)
+		assert.Equal(t, "brand-new-title", postRepo.posts[1].Slug)
+	})
+
+	t.Run("title and content cannot be cleared", func(t *testing.T) {
+		postService, postRepo := newUpdatablePostService()
+
+		_, _, err := postService.Update(1, &models.UpdatePostRequest{Title: stringPtr("")}, 1, "author")
+		assert.Error(t, err)
+		_, _, err = postService.Update(1, &models.UpdatePostRequest{Content: stringPtr("")}, 1, "author")
+		assert.Error(t, err)
+
+		assert.Equal(t, "Original title", postRepo.posts[1].Title)
+		assert.Equal(t, "Original content", postRepo.posts[1].Content)
+	})
+
+	t.Run("the category must exist", func(t *testing.T) {
+		postService, postRepo := newUpdatablePostService()
+
+		_, _, err := postService.Update(1, &models.UpdatePostRequest{CategoryID: uintPtr(9)}, 1, "author")
+		assert.Error(t, err)
+
+		_, _, err = postService.Update(1, &models.UpdatePostRequest{CategoryID: uintPtr(2)}, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), postRepo.posts[1].CategoryID)
+	})
+}
+
+func TestPostService_UpdatePermissions(t *testing.T) {
+	t.Run("editors may update and publish another author's post", func(t *testing.T) {
+		postService, postRepo := newUpdatablePostService()
+
+		post, _, err := postService.Update(1, &models.UpdatePostRequest{Status: stringPtr("published")}, 2, "editor")
+
+		require.NoError(t, err)
+		assert.Equal(t, "published", post.Status)
+		assert.Equal(t, uint(1), postRepo.posts[1].AuthorID)
+	})
+
+	t.Run("authors may not update another author's post", func(t *testing.T) {
+		postService, postRepo := newUpdatablePostService()
+
+		_, _, err := postService.Update(1, &models.UpdatePostRequest{Title: stringPtr("Taken over")}, 2, "author")
+
+		assert.Error(t, err)
+		assert.Equal(t, "Original title", postRepo.posts[1].Title)
+	})
+
+	t.Run("editors may not delete another author's post", func(t *testing.T) {
+		postService, postRepo := newUpdatablePostService()
+
+		err := postService.Delete(1, 2, "editor")
+
+		assert.Error(t, err)
+		assert.Contains(t, postRepo.posts, uint(1))
+	})
+}
+
+// newListingService seeds author 1 with three published posts and two drafts
+// in category 1, and author 2 with one published post in category 1
+func newListingService() PostService {
+	postRepo := newFakePostRepo(
+		&models.Post{Slug: "one", AuthorID: 1, CategoryID: 1, Status: "published"},
+		&models.Post{Slug: "two", AuthorID: 1, CategoryID: 1, Status: "published"},
+		&models.Post{Slug: "three", AuthorID: 1, CategoryID: 1, Status: "published"},
+		&models.Post{Slug: "draft-one", AuthorID: 1, CategoryID: 1, Status: "draft"},
+		&models.Post{Slug: "draft-two", AuthorID: 1, CategoryID: 1, Status: "draft"},
+		&models.Post{Slug: "theirs", AuthorID: 2, CategoryID: 1, Status: "published"},
+	)
+	return newTestPostService(withPostRepo(postRepo), withCategoryRepo(newFakeCategoryRepo()))
+}
+
+func TestPostService_GetByAuthorVisibility(t *testing.T) {
+	service := newListingService()
+
+	tests := []struct {
+		name       string
+		viewerID   uint
+		viewerRole string
+		wantTotal  int64
+	}{
+		{"anonymous callers see published posts", 0, "", 3},
+		{"other authors see published posts", 2, "author", 3},
+		{"authors see their own drafts", 1, "author", 5},
+		{"admins see everything", 99, "admin", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, total, err := service.GetByAuthor(1, 1, 2, tt.viewerID, tt.viewerRole)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.Len(t, posts, 2)
+		})
+	}
+
+	t.Run("the total counts the visible set across pages", func(t *testing.T) {
+		var seen int
+		for page := 1; page <= 3; page++ {
+			posts, total, err := service.GetByAuthor(1, page, 2, 0, "")
+			require.NoError(t, err)
+			require.Equal(t, int64(3), total)
+			for _, post := range posts {
+				assert.Equal(t, "published", post.Status)
+			}
+			seen += len(posts)
+		}
+		assert.Equal(t, 3, seen)
+	})
+}
+
+func TestPostService_GetByCategoryVisibility(t *testing.T) {
+	service := newListingService()
+
+	t.Run("non-admins see published posts only", func(t *testing.T) {
+		for _, role := range []string{"", "author"} {
+			posts, total, err := service.GetByCategory(1, 1, 10, 1, role)
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), total)
+			assert.Len(t, posts, 4)
+		}
+	})
+
+	t.Run("admins see drafts too", func(t *testing.T) {
+		_, total, err := service.GetByCategory(1, 1, 10, 99, "admin")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), total)
+	})
+}
+
+func TestPostService_SearchVisibility(t *testing.T) {
+	service := newListingService()
+
+	search := func(t *testing.T, req models.PostSearchRequest, viewerID uint, viewerRole string) []models.Post {
+		posts, _, _, err := service.Search(&req, viewerID, viewerRole)
+		require.NoError(t, err)
+		return posts
+	}
+
+	t.Run("anonymous callers cannot search for drafts", func(t *testing.T) {
+		assert.Empty(t, search(t, models.PostSearchRequest{Status: "draft"}, 0, ""))
+		assert.Empty(t, search(t, models.PostSearchRequest{Status: "draft", AuthorID: 1}, 0, ""))
+	})
+
+	t.Run("anonymous callers only find published posts", func(t *testing.T) {
+		posts := search(t, models.PostSearchRequest{}, 0, "")
+
+		assert.Len(t, posts, 4)
+		for _, post := range posts {
+			assert.Equal(t, "published", post.Status)
+		}
+	})
+
+	t.Run("authors find their own drafts", func(t *testing.T) {
+		assert.Len(t, search(t, models.PostSearchRequest{Status: "draft"}, 1, "author"), 2)
+		assert.Len(t, search(t, models.PostSearchRequest{}, 1, "author"), 6)
+	})
+
+	t.Run("authors do not find other authors' drafts", func(t *testing.T) {
+		assert.Empty(t, search(t, models.PostSearchRequest{Status: "draft"}, 2, "author"))
+		assert.Len(t, search(t, models.PostSearchRequest{}, 2, "author"), 4)
+	})
+
+	t.Run("admins find everything", func(t *testing.T) {
+		assert.Len(t, search(t, models.PostSearchRequest{}, 99, "admin"), 6)
+		assert.Len(t, search(t, models.PostSearchRequest{Status: "draft"}, 99, "admin"), 2)
+	})
+}
+
+func newSlugLookupService() PostService {
+	postRepo := newFakePostRepo(
+		&models.Post{Slug: "first", AuthorID: 1, Status: "published"},
+		&models.Post{Slug: "second", AuthorID: 1, Status: "published"},
+		&models.Post{Slug: "third", AuthorID: 2, Status: "published"},
+		&models.Post{Slug: "my-draft", AuthorID: 1, Status: "draft"},
+		&models.Post{Slug: "their-draft", AuthorID: 2, Status: "draft"},
+	)
+	return newTestPostService(withPostRepo(postRepo), withCategoryRepo(newFakeCategoryRepo()))
+}
+
+func slugsOf(posts []models.Post) []string {
+	var slugs []string
+	for _, post := range posts {
+		slugs = append(slugs, post.Slug)
+	}
+	return slugs
+}
+
+func TestPostService_GetBySlugs(t *testing.T) {
+	postService := newSlugLookupService()
+
+	t.Run("preserves the requested order", func(t *testing.T) {
+		posts, err := postService.GetBySlugs([]string{"third", "first", "second"}, 0, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"third", "first", "second"}, slugsOf(posts))
+	})
+
+	t.Run("skips missing and duplicate slugs", func(t *testing.T) {
+		posts, err := postService.GetBySlugs([]string{"second", "nope", "first", "second"}, 0, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"second", "first"}, slugsOf(posts))
+	})
+
+	t.Run("anonymous callers only see published posts", func(t *testing.T) {
+		posts, err := postService.GetBySlugs([]string{"my-draft", "first", "their-draft"}, 0, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first"}, slugsOf(posts))
+	})
+
+	t.Run("authors see their own drafts", func(t *testing.T) {
+		posts, err := postService.GetBySlugs([]string{"my-draft", "first", "their-draft"}, 1, "author")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"my-draft", "first"}, slugsOf(posts))
+	})
+
+	t.Run("admins see everything", func(t *testing.T) {
+		posts, err := postService.GetBySlugs([]string{"my-draft", "their-draft"}, 99, "admin")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"my-draft", "their-draft"}, slugsOf(posts))
+	})
+
+	t.Run("ignores the case of requested slugs", func(t *testing.T) {
+		posts, err := postService.GetBySlugs([]string{"Third", "FIRST"}, 0, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"third", "first"}, slugsOf(posts))
+	})
+
+	t.Run("rejects more slugs than the cap", func(t *testing.T) {
+		slugs := make([]string, MaxSlugsPerRequest+1)
+		for i := range slugs {
+			slugs[i] = fmt.Sprintf("slug-%d", i)
+		}
+
+		_, err := postService.GetBySlugs(slugs, 0, "")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestPostService_GetBySlugIgnoresCase(t *testing.T) {
+	postService := newSlugLookupService()
+
+	for _, slug := range []string{"second", "Second", "SECOND", " second "} {
+		post, err := postService.GetBySlug(slug)
+
+		require.NoError(t, err, slug)
+		assert.Equal(t, "second", post.Slug, slug)
+	}
+}
+
+func newDuplicateTitlePostService(mode string) (PostService, *fakePostRepo) {
+	postRepo := newFakePostRepo(&models.Post{ID: 1, Title: "Hello World", Slug: "hello-world", AuthorID: 2, CategoryID: 1, Status: "published"})
+	categoryRepo := newFakeCategoryRepo(&models.Category{ID: 1, Name: "Go", Slug: "go"})
+	cfg := &config.Config{Post: config.PostConfig{DuplicateTitles: mode}}
+
+	return newTestPostService(withPostRepo(postRepo), withCategoryRepo(categoryRepo), withConfig(cfg)), postRepo
+}
+
+func TestPostService_DuplicateTitles(t *testing.T) {
+	create := func(postService PostService, title string) (*models.Post, []models.Warning, error) {
+		return postService.Create(&models.CreatePostRequest{Title: title, Content: "Content", CategoryID: 1}, 1, "author")
+	}
+
+	t.Run("warn mode creates the post with a warning", func(t *testing.T) {
+		postService, postRepo := newDuplicateTitlePostService(DuplicateTitlesWarn)
+
+		post, warnings, err := create(postService, "hello world")
+
+		require.NoError(t, err)
+		require.NotNil(t, post)
+		assert.Len(t, postRepo.posts, 2)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, models.WarningDuplicateTitle, warnings[0].Code)
+		assert.Equal(t, models.PostRef{ID: 1, Title: "Hello World", Slug: "hello-world", Status: "published"}, warnings[0].Details)
+	})
+
+	t.Run("warn mode stays quiet for unique titles", func(t *testing.T) {
+		postService, _ := newDuplicateTitlePostService(DuplicateTitlesWarn)
+
+		_, warnings, err := create(postService, "Something else")
+
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("strict mode rejects a duplicate title", func(t *testing.T) {
+		postService, postRepo := newDuplicateTitlePostService(DuplicateTitlesStrict)
+
+		post, _, err := create(postService, "HELLO WORLD")
+
+		assert.ErrorIs(t, err, ErrDuplicateTitle)
+		assert.Nil(t, post)
+		assert.Len(t, postRepo.posts, 1)
+	})
+
+	t.Run("the check is off by default", func(t *testing.T) {
+		postService, _ := newDuplicateTitlePostService("")
+
+		_, warnings, err := create(postService, "Hello World")
+
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("renaming a post to another post's title is checked", func(t *testing.T) {
+		postService, _ := newDuplicateTitlePostService(DuplicateTitlesStrict)
+		post, _, err := create(postService, "Draft title")
+		require.NoError(t, err)
+
+		_, _, err = postService.Update(post.ID, &models.UpdatePostRequest{Title: stringPtr("Hello world")}, 1, "author")
+
+		assert.ErrorIs(t, err, ErrDuplicateTitle)
+	})
+
+	t.Run("a post does not clash with its own title", func(t *testing.T) {
+		postService, _ := newDuplicateTitlePostService(DuplicateTitlesStrict)
+
+		_, warnings, err := postService.Update(1, &models.UpdatePostRequest{Title: stringPtr("HELLO WORLD")}, 2, "author")
+
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+}
+
+func newLimitedPostService(limit int) (PostService, *fakePostRepo) {
+	postRepo := newFakePostRepo()
+	categoryRepo := newFakeCategoryRepo(&models.Category{ID: 1, Name: "Go", Slug: "go"})
+	cfg := &config.Config{Post: config.PostConfig{LimitByRole: map[string]int{"author": limit}}}
+
+	return newTestPostService(withPostRepo(postRepo), withCategoryRepo(categoryRepo), withConfig(cfg)), postRepo
+}
+
+func createPosts(t *testing.T, postService PostService, authorID uint, role string, n int) {
+	for i := 0; i < n; i++ {
+		_, _, err := postService.Create(&models.CreatePostRequest{
+			Title:      fmt.Sprintf("Post %d", i),
+			Content:    "Content",
+			CategoryID: 1,
+		}, authorID, role)
+		require.NoError(t, err)
+	}
+}
+
+func TestPostService_PostLimit(t *testing.T) {
+	t.Run("authors can create posts up to the limit", func(t *testing.T) {
+		postService, _ := newLimitedPostService(3)
+
+		createPosts(t, postService, 1, "author", 3)
+
+		usage, err := postService.Usage(1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, &models.PostUsage{Count: 3, Limit: 3}, usage)
+	})
+
+	t.Run("the next post is rejected", func(t *testing.T) {
+		postService, postRepo := newLimitedPostService(2)
+		createPosts(t, postService, 1, "author", 2)
+
+		post, _, err := postService.Create(&models.CreatePostRequest{Title: "One too many", Content: "Content", CategoryID: 1}, 1, "author")
+
+		assert.Nil(t, post)
+		assert.ErrorIs(t, err, ErrPostLimitReached)
+		count, _ := postRepo.CountByAuthor(1, false)
+		assert.Equal(t, int64(2), count)
+
+		// Other authors have their own allowance
+		createPosts(t, postService, 2, "author", 1)
+	})
+
+	t.Run("deleting a post frees up room", func(t *testing.T) {
+		postService, postRepo := newLimitedPostService(1)
+		createPosts(t, postService, 1, "author", 1)
+
+		require.NoError(t, postService.Delete(1, 1, "author"))
+
+		createPosts(t, postService, 1, "author", 1)
+		count, _ := postRepo.CountByAuthor(1, false)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("admins and editors are exempt", func(t *testing.T) {
+		postService, _ := newLimitedPostService(1)
+
+		createPosts(t, postService, 1, "admin", 3)
+		createPosts(t, postService, 2, "editor", 3)
+
+		usage, err := postService.Usage(1, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, &models.PostUsage{Count: 3, Limit: 0}, usage)
+	})
+
+	t.Run("a zero limit means unlimited", func(t *testing.T) {
+		postService, _ := newLimitedPostService(0)
+
+		createPosts(t, postService, 1, "author", 5)
+	})
+}
+
+func TestPostService_MinInterval(t *testing.T) {
+	newService := func() (PostService, *postService) {
+		categoryRepo := newFakeCategoryRepo(&models.Category{ID: 1, Name: "Go", Slug: "go"})
+		cfg := &config.Config{Post: config.PostConfig{MinInterval: 30 * time.Second}}
+		postRepo := newFakePostRepo()
+		service := newTestPostService(withPostRepo(postRepo), withCategoryRepo(categoryRepo), withConfig(cfg))
+		return service, service.(*postService)
+	}
+	create := func(postService PostService, title, role string) error {
+		_, _, err := postService.Create(&models.CreatePostRequest{Title: title, Content: "Content", CategoryID: 1}, 1, role)
+		return err
+	}
+
+	t.Run("a second post within the interval is rejected", func(t *testing.T) {
+		postService, _ := newService()
+		require.NoError(t, create(postService, "First", "author"))
+
+		assert.ErrorIs(t, create(postService, "Second", "author"), ErrPostingTooFrequently)
+	})
+
+	t.Run("a post after the interval is accepted", func(t *testing.T) {
+		postService, concrete := newService()
+		require.NoError(t, create(postService, "First", "author"))
+
+		concrete.now = func() time.Time { return time.Now().Add(31 * time.Second) }
+		assert.NoError(t, create(postService, "Second", "author"))
+	})
+
+	t.Run("other authors are not held up", func(t *testing.T) {
+		postService, _ := newService()
+		require.NoError(t, create(postService, "First", "author"))
+
+		_, _, err := postService.Create(&models.CreatePostRequest{Title: "Elsewhere", Content: "Content", CategoryID: 1}, 2, "author")
+		assert.NoError(t, err)
+	})
+
+	t.Run("admins and editors are exempt", func(t *testing.T) {
+		postService, _ := newService()
+		require.NoError(t, create(postService, "First", "admin"))
+		assert.NoError(t, create(postService, "Second", "admin"))
+		assert.NoError(t, create(postService, "Third", "editor"))
+	})
+}
+
+func TestPostService_Random(t *testing.T) {
+	later := time.Now().Add(time.Hour)
+	postRepo := newFakePostRepo(
+		&models.Post{Slug: "first", AuthorID: 1, Status: "published"},
+		&models.Post{Slug: "draft", AuthorID: 1, Status: "draft"},
+		&models.Post{Slug: "second", AuthorID: 2, Status: "published"},
+		&models.Post{Slug: "scheduled", AuthorID: 2, Status: "published", PublishAt: &later},
+		&models.Post{Slug: "archived", AuthorID: 2, Status: "archived"},
+		&models.Post{Slug: "third", AuthorID: 3, Status: "published"},
+	)
+	service := newTestPostService(withPostRepo(postRepo))
+
+	t.Run("repeated picks only return published posts", func(t *testing.T) {
+		seen := make(map[string]int)
+		for i := 0; i < 100; i++ {
+			post, err := service.Random()
+			require.NoError(t, err)
+			seen[post.Slug]++
+		}
+
+		assert.ElementsMatch(t, []string{"first", "second", "third"}, keysOf(seen))
+	})
+
+	t.Run("picks an offset within the published count", func(t *testing.T) {
+		var asked []int64
+		picker := service.(*postService)
+		original := picker.randN
+		picker.randN = func(n int64) int64 {
+			asked = append(asked, n)
+			return n - 1
+		}
+		defer func() { picker.randN = original }()
+
+		post, err := service.Random()
+		require.NoError(t, err)
+		assert.Equal(t, "third", post.Slug)
+		assert.Equal(t, []int64{3}, asked)
+	})
+
+	t.Run("nothing published", func(t *testing.T) {
+		drafts := newFakePostRepo(&models.Post{Slug: "draft", Status: "draft"})
+		empty := newTestPostService(withPostRepo(drafts))
+
+		_, err := empty.Random()
+		assert.ErrorIs(t, err, ErrNoPublishedPosts)
+	})
+}
+
+func keysOf(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestPostService_DeletedRelations(t *testing.T) {
+	deletedAt := gorm.DeletedAt{Valid: true}
+	newService := func(mode string) PostService {
+		postRepo := newFakePostRepo(
+			// The author's and category's rows are gone entirely
+			&models.Post{ID: 1, Slug: "orphaned", AuthorID: 7, CategoryID: 3, Status: "published"},
+			&models.Post{ID: 2, Slug: "soft-deleted", AuthorID: 8, CategoryID: 4, Status: "published",
+				Author:   &models.User{ID: 8, Name: "Former Author", DeletedAt: deletedAt},
+				Category: &models.Category{ID: 4, Name: "Retired", DeletedAt: deletedAt},
+			},
+		)
+		cfg := &config.Config{Post: config.PostConfig{DeletedRelations: mode}}
+		return newTestPostService(withPostRepo(postRepo), withConfig(cfg))
+	}
+
+	t.Run("missing relations get a placeholder in either mode", func(t *testing.T) {
+		for _, mode := range []string{DeletedRelationsUnscoped, DeletedRelationsPlaceholder} {
+			post, err := newService(mode).GetByID(1)
+
+			require.NoError(t, err)
+			require.NotNil(t, post.Author, mode)
+			require.NotNil(t, post.Category, mode)
+			assert.Equal(t, uint(7), post.Author.ID)
+			assert.Equal(t, models.DeletedName, post.Author.Name)
+			assert.Equal(t, uint(3), post.Category.ID)
+			assert.Equal(t, models.DeletedName, post.Category.Name)
+		}
+	})
+
+	t.Run("soft-deleted relations are shown as stored when unscoped", func(t *testing.T) {
+		post, err := newService(DeletedRelationsUnscoped).GetBySlug("soft-deleted")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Former Author", post.Author.Name)
+		assert.Equal(t, "Retired", post.Category.Name)
+	})
+
+	t.Run("soft-deleted relations are replaced in placeholder mode", func(t *testing.T) {
+		post, err := newService(DeletedRelationsPlaceholder).GetBySlug("soft-deleted")
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(8), post.Author.ID)
+		assert.Equal(t, models.DeletedName, post.Author.Name)
+		assert.Equal(t, models.DeletedName, post.Category.Name)
+	})
+}
+
+func newTaggedPostService(tags ...*models.Tag) (PostService, *fakePostRepo, *fakeTagRepo) {
+	postRepo := newFakePostRepo()
+	categoryRepo := newFakeCategoryRepo(&models.Category{ID: 1, Name: "Go", Slug: "go"})
+	tagRepo := newFakeTagRepo(tags...)
+	return newTestPostService(withPostRepo(postRepo), withCategoryRepo(categoryRepo), withTagRepo(tagRepo)), postRepo, tagRepo
+}
+
+func tagSlugs(tags []models.Tag) []string {
+	slugs := make([]string, len(tags))
+	for i, tag := range tags {
+		slugs[i] = tag.Slug
+	}
+	return slugs
+}
+
+func TestPostService_Tags(t *testing.T) {
+	create := func(t *testing.T, postService PostService, title string, tags ...string) *models.Post {
+		post, _, err := postService.Create(&models.CreatePostRequest{Title: title, Content: "Content", CategoryID: 1, Status: "published", Tags: tags}, 1, "author")
+		require.NoError(t, err)
+		return post
+	}
+
+	t.Run("tag slugs are generated from their names", func(t *testing.T) {
+		postService, postRepo, tagRepo := newTaggedPostService()
+
+		post := create(t, postService, "Tagged", "Web Development", "C++ & Go!")
+
+		assert.Equal(t, []string{"web-development", "c-go"}, tagSlugs(postRepo.posts[post.ID].Tags))
+		assert.Equal(t, "Web Development", tagRepo.tags["web-development"].Name)
+	})
+
+	t.Run("names that differ only in case or spacing are one tag", func(t *testing.T) {
+		postService, postRepo, tagRepo := newTaggedPostService()
+
+		post := create(t, postService, "Tagged", "Go", " go ", "GO", "Testing")
+
+		assert.Equal(t, []string{"go", "testing"}, tagSlugs(postRepo.posts[post.ID].Tags))
+		assert.Len(t, tagRepo.tags, 2)
+		assert.Equal(t, "Go", tagRepo.tags["go"].Name)
+	})
+
+	t.Run("existing tags are reused", func(t *testing.T) {
+		postService, postRepo, tagRepo := newTaggedPostService(&models.Tag{ID: 7, Name: "Golang", Slug: "golang"})
+
+		post := create(t, postService, "Tagged", "golang")
+
+		require.Len(t, postRepo.posts[post.ID].Tags, 1)
+		assert.Equal(t, uint(7), postRepo.posts[post.ID].Tags[0].ID)
+		assert.Len(t, tagRepo.tags, 1)
+	})
+
+	t.Run("names without slug characters are skipped", func(t *testing.T) {
+		postService, postRepo, tagRepo := newTaggedPostService()
+
+		post := create(t, postService, "Tagged", "   ", "!!!")
+
+		assert.Empty(t, postRepo.posts[post.ID].Tags)
+		assert.Empty(t, tagRepo.tags)
+	})
+
+	t.Run("too many distinct tags are rejected", func(t *testing.T) {
+		postService, _, _ := newTaggedPostService()
+		names := make([]string, MaxTagsPerPost+1)
+		for i := range names {
+			names[i] = fmt.Sprintf("tag %d", i)
+		}
+
+		_, _, err := postService.Create(&models.CreatePostRequest{Title: "Tagged", Content: "Content", CategoryID: 1, Tags: names}, 1, "author")
+
+		assert.ErrorIs(t, err, ErrTooManyTags)
+	})
+
+	t.Run("a post whose tags fail to save is not stored", func(t *testing.T) {
+		postService, postRepo, tagRepo := newTaggedPostService()
+		tagRepo.err = errors.New("tags table is locked")
+
+		_, _, err := postService.Create(&models.CreatePostRequest{Title: "Tagged", Content: "Content", CategoryID: 1, Tags: []string{"go"}}, 1, "author")
+
+		assert.ErrorIs(t, err, tagRepo.err)
+		assert.Empty(t, postRepo.posts)
+	})
+
+	t.Run("updating tags replaces them and omitting them keeps them", func(t *testing.T) {
+		postService, postRepo, _ := newTaggedPostService()
+		post := create(t, postService, "Tagged", "go", "testing")
+
+		_, _, err := postService.Update(post.ID, &models.UpdatePostRequest{Title: stringPtr("Retitled")}, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "testing"}, tagSlugs(postRepo.posts[post.ID].Tags))
+
+		_, _, err = postService.Update(post.ID, &models.UpdatePostRequest{Tags: &[]string{"Rust"}}, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"rust"}, tagSlugs(postRepo.posts[post.ID].Tags))
+
+		_, _, err = postService.Update(post.ID, &models.UpdatePostRequest{Tags: &[]string{}}, 1, "author")
+		require.NoError(t, err)
+		assert.Empty(t, postRepo.posts[post.ID].Tags)
+	})
+
+	t.Run("listing by tag", func(t *testing.T) {
+		postService, _, _ := newTaggedPostService()
+		create(t, postService, "First", "go")
+		create(t, postService, "Second", "rust")
+		create(t, postService, "Third", "Go", "rust")
+
+		posts, total, err := postService.GetByTag("GO", 1, 10, 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, []string{"Third", "First"}, []string{posts[0].Title, posts[1].Title})
+
+		_, _, err = postService.GetByTag("missing", 1, 10, 0, "")
+		assert.ErrorIs(t, err, ErrTagNotFound)
+	})
+}
+
+// newTransferService stores two posts by the leaving author 2, one of them
+// in the trash, and one post by author 3
+func newTransferService() (PostService, *fakePostRepo, *fakeAuditLogRepo) {
+	postRepo := newFakePostRepo(
+		&models.Post{ID: 1, Slug: "first", AuthorID: 2, Status: "published"},
+		&models.Post{ID: 2, Slug: "second", AuthorID: 2, Status: "draft"},
+		&models.Post{ID: 3, Slug: "other", AuthorID: 3, Status: "published"},
+	)
+	postRepo.Delete(2)
+	userRepo := newFakeUserRepo(
+		&models.User{ID: 1, Username: "admin", Role: "admin"},
+		&models.User{ID: 2, Username: "leaving", Role: "author"},
+		&models.User{ID: 3, Username: "staying", Role: "author"},
+		&models.User{ID: 4, Username: "editor", Role: "editor"},
+	)
+	uow := newFakeUnitOfWork(postRepo, nil)
+	return newTestPostService(withPostRepo(postRepo), withUserRepo(userRepo), withUnitOfWork(uow)), postRepo, uow.audit
+}
+
+func TestPostService_Transfer(t *testing.T) {
+	t.Run("changes the author and audits it", func(t *testing.T) {
+		service, postRepo, audit := newTransferService()
+
+		post, err := service.Transfer(1, 4, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(4), post.AuthorID)
+		require.NotNil(t, postRepo.posts[1].UpdatedBy)
+		assert.Equal(t, uint(1), *postRepo.posts[1].UpdatedBy)
+		require.Len(t, audit.entries, 1)
+		entry := audit.entries[0]
+		assert.Equal(t, uint(1), entry.ActorID)
+		assert.Equal(t, models.AuditPostTransfer, entry.Action)
+		assert.Equal(t, "post", entry.TargetType)
+		assert.Equal(t, uint(1), entry.TargetID)
+		assert.Equal(t, map[string]interface{}{"from_author_id": uint(2), "to_author_id": uint(4)}, entry.Details)
+	})
+
+	t.Run("an invalid target author is rejected", func(t *testing.T) {
+		for name, authorID := range map[string]uint{"missing": 99, "admin": 1} {
+			service, postRepo, audit := newTransferService()
+
+			_, err := service.Transfer(1, authorID, 1)
+
+			assert.ErrorIs(t, err, ErrInvalidTransferTarget, name)
+			assert.Equal(t, uint(2), postRepo.posts[1].AuthorID, name)
+			assert.Empty(t, audit.entries, name)
+		}
+	})
+
+	t.Run("an unknown post is not found", func(t *testing.T) {
+		service, _, _ := newTransferService()
+
+		_, err := service.Transfer(99, 3, 1)
+
+		assert.ErrorIs(t, err, ErrPostNotFound)
+	})
+}
+
+func TestPostService_TransferAll(t *testing.T) {
+	t.Run("moves every post of the author, trashed ones included", func(t *testing.T) {
+		service, postRepo, audit := newTransferService()
+
+		transferred, err := service.TransferAll(2, 3, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), transferred)
+		assert.Equal(t, uint(3), postRepo.posts[1].AuthorID)
+		assert.Equal(t, uint(3), postRepo.trashed[2].AuthorID)
+		assert.Equal(t, uint(3), postRepo.posts[3].AuthorID)
+		require.Len(t, audit.entries, 1)
+		assert.Equal(t, models.AuditPostsTransfer, audit.entries[0].Action)
+		assert.Equal(t, "user", audit.entries[0].TargetType)
+		assert.Equal(t, uint(2), audit.entries[0].TargetID)
+		assert.Equal(t, int64(2), audit.entries[0].Details["posts"])
+	})
+
+	t.Run("an invalid target author is rejected", func(t *testing.T) {
+		service, postRepo, audit := newTransferService()
+
+		_, err := service.TransferAll(2, 1, 1)
+		assert.ErrorIs(t, err, ErrInvalidTransferTarget)
+		_, err = service.TransferAll(2, 2, 1)
+		assert.ErrorIs(t, err, ErrInvalidTransferTarget)
+
+		assert.Equal(t, uint(2), postRepo.posts[1].AuthorID)
+		assert.Empty(t, audit.entries)
+	})
+}
+
+func TestPostService_Trash(t *testing.T) {
+	postRepo := newFakePostRepo(
+		&models.Post{Slug: "kept", AuthorID: 1, Status: "published"},
+		&models.Post{Slug: "binned", AuthorID: 1, Status: "published"},
+	)
+	service := newTestPostService(withPostRepo(postRepo))
+
+	require.NoError(t, service.Delete(2, 1, "author"))
+
+	t.Run("deleted posts are listed in the trash", func(t *testing.T) {
+		posts, total, err := service.ListTrashed(1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, posts, 1)
+		assert.Equal(t, "binned", posts[0].Slug)
+		assert.True(t, posts[0].DeletedAt.Valid)
+	})
+
+	t.Run("posts not in the trash cannot be restored", func(t *testing.T) {
+		_, err := service.Restore(1)
+		assert.ErrorIs(t, err, ErrPostNotTrashed)
+
+		_, err = service.Restore(99)
+		assert.ErrorIs(t, err, ErrPostNotTrashed)
+	})
+
+	t.Run("restoring makes the post visible again", func(t *testing.T) {
+		restored, err := service.Restore(2)
+		require.NoError(t, err)
+		assert.Equal(t, "binned", restored.Slug)
+
+		post, err := service.GetByID(2)
+		require.NoError(t, err)
+		assert.Equal(t, "binned", post.Slug)
+
+		_, total, err := service.ListTrashed(1, 10)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+}
+
+func TestPostService_Bulk(t *testing.T) {
+	seed := func(t *testing.T) (PostService, *fakePostRepo, *fakeCategoryRepo) {
+		postService, _, postRepo, categoryRepo := newCountedPostService()
+		for _, req := range []models.CreatePostRequest{
+			{Title: "First draft", Content: "Content", CategoryID: 1},
+			{Title: "Second draft", Content: "Content", CategoryID: 1},
+			{Title: "Already live", Content: "Content", CategoryID: 2, Status: "published"},
+		} {
+			req := req
+			_, _, err := postService.Create(&req, 1, "author")
+			require.NoError(t, err)
+		}
+		return postService, postRepo, categoryRepo
+	}
+
+	t.Run("publishing a mixed batch applies to every post", func(t *testing.T) {
+		postService, postRepo, categoryRepo := seed(t)
+
+		response, err := postService.Bulk(&models.BulkPostRequest{Action: models.BulkPostPublish, IDs: []uint{2, 1, 3, 2}})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, response.Applied)
+		assert.Zero(t, response.Failed)
+		assert.Equal(t, []models.BulkPostResult{
+			{ID: 2, Status: models.BulkItemApplied},
+			{ID: 1, Status: models.BulkItemApplied},
+			{ID: 3, Status: models.BulkItemApplied},
+		}, response.Results)
+		for _, id := range []uint{1, 2, 3} {
+			assert.Equal(t, "published", postRepo.posts[id].Status)
+			assert.NotNil(t, postRepo.posts[id].PublishedAt)
+		}
+		// Only the drafts were newly counted
+		assert.Equal(t, int64(2), postCount(t, categoryRepo, 1))
+		assert.Equal(t, int64(1), postCount(t, categoryRepo, 2))
+	})
+
+	t.Run("deleting moves posts to the trash", func(t *testing.T) {
+		postService, postRepo, categoryRepo := seed(t)
+
+		response, err := postService.Bulk(&models.BulkPostRequest{Action: models.BulkPostDelete, IDs: []uint{1, 3}})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.Applied)
+		assert.Len(t, postRepo.posts, 1)
+		assert.Len(t, postRepo.trashed, 2)
+		assert.Equal(t, int64(0), postCount(t, categoryRepo, 2))
+	})
+
+	t.Run("an unknown post rolls the whole batch back", func(t *testing.T) {
+		postService, postRepo, categoryRepo := seed(t)
+
+		response, err := postService.Bulk(&models.BulkPostRequest{Action: models.BulkPostArchive, IDs: []uint{1, 99, 3}})
+
+		require.ErrorIs(t, err, ErrBulkRolledBack)
+		assert.Zero(t, response.Applied)
+		assert.Equal(t, 1, response.Failed)
+		assert.Equal(t, []models.BulkPostResult{
+			{ID: 1, Status: models.BulkItemRolledBack},
+			{ID: 99, Status: models.BulkItemFailed, Error: "post not found"},
+			{ID: 3, Status: models.BulkItemRolledBack},
+		}, response.Results)
+		assert.Equal(t, "draft", postRepo.posts[1].Status)
+		assert.Equal(t, "published", postRepo.posts[3].Status)
+		assert.Equal(t, int64(1), postCount(t, categoryRepo, 2))
+	})
+}
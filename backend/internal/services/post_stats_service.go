@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PostStatsVelocityDays is the window, in days including today, that recent
+// view velocity is measured over
+const PostStatsVelocityDays = 7
+
+// RecentViewHistory caps how many recently read posts are kept per user
+const RecentViewHistory = 50
+
+var (
+	ErrPostStatsNotFound  = errors.New("post not found")
+	ErrPostStatsForbidden = errors.New("only the post's author or an admin can view its stats")
+)
+
+// PostStatsService tracks post views and reports per-post activity
+type PostStatsService interface {
+	// RecordView counts a view of a published post, in both its view count
+	// and the day's views, and when viewerID is not zero adds it to the
+	// viewer's recently read posts. It returns the post's view count after
+	// the view. Failures are logged rather than returned, since a lost view
+	// must not fail the read.
+	RecordView(ctx context.Context, post *models.Post, viewerID uint) uint
+	// RecentlyViewed returns the IDs of the posts the user read last, most
+	// recent first
+	RecentlyViewed(ctx context.Context, userID uint) ([]uint, error)
+	// GetStats returns a post's aggregates to its author or an admin
+	GetStats(ctx context.Context, postID, viewerID uint, viewerRole string) (*models.PostStats, error)
+}
+
+type postStatsService struct {
+	postRepo  repositories.PostRepository
+	statsRepo repositories.PostStatsRepository
+}
+
+func NewPostStatsService(postRepo repositories.PostRepository, statsRepo repositories.PostStatsRepository) PostStatsService {
+	return &postStatsService{
+		postRepo:  postRepo,
+		statsRepo: statsRepo,
+	}
+}
+
+func (s *postStatsService) RecordView(ctx context.Context, post *models.Post, viewerID uint) uint {
+	// Authors previewing drafts are not readers
+	if post.Status != "published" {
+		return post.ViewCount
+	}
+
+	viewCount := post.ViewCount
+	if count, err := s.postRepo.IncrementViews(post.ID); err != nil {
+		logger.LogError(ctx, "Failed to count post view", err, zap.Uint("post_id", post.ID))
+	} else {
+		viewCount = count
+	}
+	if err := s.statsRepo.RecordView(ctx, post.ID, statsDay(time.Now())); err != nil {
+		logger.LogError(ctx, "Failed to record post view", err, zap.Uint("post_id", post.ID))
+	}
+
+	if viewerID == 0 {
+		return viewCount
+	}
+	if err := s.statsRepo.RecordRecentView(ctx, viewerID, post.ID, time.Now(), RecentViewHistory); err != nil {
+		logger.LogError(ctx, "Failed to record recent view", err,
+			zap.Uint("post_id", post.ID),
+			zap.Uint("user_id", viewerID),
+		)
+	}
+	return viewCount
+}
+
+func (s *postStatsService) RecentlyViewed(ctx context.Context, userID uint) ([]uint, error) {
+	return s.statsRepo.ListRecentViews(ctx, userID)
+}
+
+func (s *postStatsService) GetStats(ctx context.Context, postID, viewerID uint, viewerRole string) (*models.PostStats, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostStatsNotFound
+		}
+		return nil, err
+	}
+	if viewerRole != "admin" && post.AuthorID != viewerID {
+		return nil, ErrPostStatsForbidden
+	}
+
+	since := statsDay(time.Now().AddDate(0, 0, -(PostStatsVelocityDays - 1)))
+	views, recent, err := s.statsRepo.CountViews(ctx, postID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.statsRepo.CountCommentsByStatus(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PostStats{
+		PostID: postID,
+		Views:  views,
+		Comments: models.PostCommentCounts{
+			Approved: comments["approved"],
+			Pending:  comments["pending"],
+			Rejected: comments["rejected"],
+		},
+		RecentViews:  recent,
+		ViewsPerDay:  float64(recent) / PostStatsVelocityDays,
+		VelocityDays: PostStatsVelocityDays,
+	}, nil
+}
+
+// statsDay returns the UTC date views on t are counted under
+func statsDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
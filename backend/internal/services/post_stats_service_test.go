@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePostStatsRepo keeps daily view counts in memory and counts comments
+// from a fake comment repository
+type fakePostStatsRepo struct {
+	repositories.PostStatsRepository
+	views       map[uint]map[string]int64
+	commentRepo *fakeCommentRepo
+	// recent holds each user's recently read post IDs, most recent first
+	recent map[uint][]uint
+}
+
+func newFakePostStatsRepo(commentRepo *fakeCommentRepo) *fakePostStatsRepo {
+	return &fakePostStatsRepo{views: make(map[uint]map[string]int64), commentRepo: commentRepo, recent: make(map[uint][]uint)}
+}
+
+func (r *fakePostStatsRepo) RecordView(ctx context.Context, postID uint, day string) error {
+	if r.views[postID] == nil {
+		r.views[postID] = make(map[string]int64)
+	}
+	r.views[postID][day]++
+	return nil
+}
+
+func (r *fakePostStatsRepo) CountViews(ctx context.Context, postID uint, since string) (int64, int64, error) {
+	var total, recent int64
+	for day, views := range r.views[postID] {
+		total += views
+		if day >= since {
+			recent += views
+		}
+	}
+	return total, recent, nil
+}
+
+func (r *fakePostStatsRepo) RecordRecentView(ctx context.Context, userID, postID uint, viewedAt time.Time, keep int) error {
+	postIDs := []uint{postID}
+	for _, id := range r.recent[userID] {
+		if id != postID {
+			postIDs = append(postIDs, id)
+		}
+	}
+	if len(postIDs) > keep {
+		postIDs = postIDs[:keep]
+	}
+	r.recent[userID] = postIDs
+	return nil
+}
+
+func (r *fakePostStatsRepo) ListRecentViews(ctx context.Context, userID uint) ([]uint, error) {
+	return append([]uint(nil), r.recent[userID]...), nil
+}
+
+func (r *fakePostStatsRepo) CountCommentsByStatus(ctx context.Context, postID uint) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, comment := range r.commentRepo.comments {
+		if comment.PostID == postID {
+			counts[comment.Status]++
+		}
+	}
+	return counts, nil
+}
+
+// newPostStatsFixture seeds published post 1 and draft post 2 by author 1,
+// with two approved, one pending and one rejected comment on post 1
+func newPostStatsFixture() (PostStatsService, *fakePostStatsRepo) {
+	postRepo := newFakePostRepo(
+		&models.Post{ID: 1, Title: "Published", AuthorID: 1, Status: "published"},
+		&models.Post{ID: 2, Title: "Draft", AuthorID: 1, Status: "draft"},
+	)
+	commentRepo := newFakeCommentRepo(
+		&models.Comment{ID: 1, PostID: 1, UserID: 2, Status: "approved"},
+		&models.Comment{ID: 2, PostID: 1, UserID: 2, Status: "approved"},
+		&models.Comment{ID: 3, PostID: 1, UserID: 3, Status: "pending"},
+		&models.Comment{ID: 4, PostID: 1, UserID: 3, Status: "rejected"},
+		&models.Comment{ID: 5, PostID: 2, UserID: 2, Status: "approved"},
+	)
+	statsRepo := newFakePostStatsRepo(commentRepo)
+	return NewPostStatsService(postRepo, statsRepo), statsRepo
+}
+
+func TestPostStatsService_GetStats(t *testing.T) {
+	ctx := context.Background()
+	service, statsRepo := newPostStatsFixture()
+
+	// Three views today through the service, plus older views that fall
+	// outside the velocity window
+	published := &models.Post{ID: 1, AuthorID: 1, Status: "published"}
+	for i := 0; i < 3; i++ {
+		service.RecordView(ctx, published, 0)
+	}
+	statsRepo.views[1][statsDay(time.Now().AddDate(0, 0, -3))] = 4
+	statsRepo.views[1][statsDay(time.Now().AddDate(0, 0, -30))] = 50
+
+	t.Run("the author sees the post's aggregates", func(t *testing.T) {
+		stats, err := service.GetStats(ctx, 1, 1, "author")
+		require.NoError(t, err)
+
+		assert.Equal(t, &models.PostStats{
+			PostID:       1,
+			Views:        57,
+			Comments:     models.PostCommentCounts{Approved: 2, Pending: 1, Rejected: 1},
+			RecentViews:  7,
+			ViewsPerDay:  1,
+			VelocityDays: PostStatsVelocityDays,
+		}, stats)
+	})
+
+	t.Run("admins see any post's stats", func(t *testing.T) {
+		stats, err := service.GetStats(ctx, 1, 99, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, int64(57), stats.Views)
+	})
+
+	t.Run("other users are forbidden", func(t *testing.T) {
+		_, err := service.GetStats(ctx, 1, 2, "author")
+		assert.ErrorIs(t, err, ErrPostStatsForbidden)
+	})
+
+	t.Run("unknown posts are not found", func(t *testing.T) {
+		_, err := service.GetStats(ctx, 42, 1, "admin")
+		assert.ErrorIs(t, err, ErrPostStatsNotFound)
+	})
+
+	t.Run("views of unpublished posts are not counted", func(t *testing.T) {
+		service.RecordView(ctx, &models.Post{ID: 2, AuthorID: 1, Status: "draft"}, 0)
+
+		stats, err := service.GetStats(ctx, 2, 1, "author")
+		require.NoError(t, err)
+		assert.Zero(t, stats.Views)
+		assert.Equal(t, int64(1), stats.Comments.Approved)
+	})
+}
+
+func TestPostStatsService_RecentlyViewed(t *testing.T) {
+	ctx := context.Background()
+	read := func(service PostStatsService, viewerID uint, ids ...uint) {
+		for _, id := range ids {
+			service.RecordView(ctx, &models.Post{ID: id, Status: "published"}, viewerID)
+		}
+	}
+
+	t.Run("posts read by a signed-in user are listed most recent first", func(t *testing.T) {
+		service, _ := newPostStatsFixture()
+		read(service, 5, 1, 2, 3)
+
+		ids, err := service.RecentlyViewed(ctx, 5)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{3, 2, 1}, ids)
+	})
+
+	t.Run("reading a post again moves it to the front", func(t *testing.T) {
+		service, _ := newPostStatsFixture()
+		read(service, 5, 1, 2, 3, 1)
+
+		ids, err := service.RecentlyViewed(ctx, 5)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{1, 3, 2}, ids)
+	})
+
+	t.Run("the history is capped", func(t *testing.T) {
+		service, _ := newPostStatsFixture()
+		for id := uint(1); id <= RecentViewHistory+5; id++ {
+			read(service, 5, id)
+		}
+
+		ids, err := service.RecentlyViewed(ctx, 5)
+		require.NoError(t, err)
+		require.Len(t, ids, RecentViewHistory)
+		assert.Equal(t, uint(RecentViewHistory+5), ids[0])
+		assert.Equal(t, uint(6), ids[len(ids)-1])
+	})
+
+	t.Run("anonymous reads and drafts are not recorded", func(t *testing.T) {
+		service, statsRepo := newPostStatsFixture()
+		read(service, 0, 1)
+		service.RecordView(ctx, &models.Post{ID: 2, Status: "draft"}, 5)
+
+		assert.Empty(t, statsRepo.recent)
+	})
+}
+
+func TestPostStatsService_RecordViewCountsOnce(t *testing.T) {
+	ctx := context.Background()
+	service, statsRepo := newPostStatsFixture()
+
+	// The view count and the day's views move together
+	for want := uint(1); want <= 3; want++ {
+		assert.Equal(t, want, service.RecordView(ctx, &models.Post{ID: 1, Status: "published"}, 0))
+	}
+	assert.Equal(t, int64(3), statsRepo.views[1][statsDay(time.Now())])
+
+	assert.Zero(t, service.RecordView(ctx, &models.Post{ID: 2, Status: "draft"}, 0), "drafts are not counted")
+	assert.Empty(t, statsRepo.views[2])
+}
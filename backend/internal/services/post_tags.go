@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"backend/internal/models"
+	"backend/pkg/content"
+)
+
+// MaxTagsPerPost caps how many tags one post may carry
+const MaxTagsPerPost = 10
+
+// ErrTagNotFound is returned when listing posts for a tag that does not exist
+var ErrTagNotFound = errors.New("tag not found")
+
+// ErrTooManyTags is returned when a post is saved with more than
+// MaxTagsPerPost distinct tags
+var ErrTooManyTags = errors.New("too many tags")
+
+// resolveTags turns the tag names of a request into stored tags, see
+// tagsFromNames
+func (s *postService) resolveTags(names []string) ([]models.Tag, error) {
+	tags, err := tagsFromNames(names)
+	if err != nil || len(tags) == 0 {
+		return nil, err
+	}
+	return s.tagRepo.FindOrCreate(tags)
+}
+
+// tagsFromNames turns the tag names of a request into unsaved tags. Names
+// are trimmed and deduplicated by slug, so "Go", "go" and " GO " are one tag
+// named after its first spelling. Names without any slug characters are
+// skipped.
+func tagsFromNames(names []string) ([]models.Tag, error) {
+	seen := make(map[string]bool, len(names))
+	var tags []models.Tag
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		slug := content.Slug(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		tags = append(tags, models.Tag{Name: name, Slug: slug})
+	}
+
+	if len(tags) > MaxTagsPerPost {
+		return nil, ErrTooManyTags
+	}
+	return tags, nil
+}
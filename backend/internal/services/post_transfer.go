@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// ErrPostNotFound is returned when transferring a post that does not exist
+var ErrPostNotFound = errors.New("post not found")
+
+// ErrInvalidTransferTarget is returned when posts would be handed to a user
+// who does not exist or cannot write posts of their own
+var ErrInvalidTransferTarget = errors.New("posts can only be transferred to an existing author or editor")
+
+// transferTargetRoles are the roles that can take over someone's posts
+var transferTargetRoles = map[string]bool{"author": true, "editor": true}
+
+func (s *postService) Transfer(id, authorID, actorID uint) (*models.Post, error) {
+	post, err := s.postRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTransferTarget(authorID); err != nil {
+		return nil, err
+	}
+
+	fromAuthorID := post.AuthorID
+	err = s.uow.Do(func(repos repositories.Repositories) error {
+		if err := repos.Posts.SetAuthor(id, authorID, actorID); err != nil {
+			return err
+		}
+		return repos.AuditLogs.Create(&models.AuditLog{
+			ActorID:    actorID,
+			Action:     models.AuditPostTransfer,
+			TargetType: "post",
+			TargetID:   id,
+			Details:    map[string]interface{}{"from_author_id": fromAuthorID, "to_author_id": authorID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transferred, err := s.getByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(context.Background(), PostEvent{Type: EventPostUpdated, Post: *transferred})
+	return transferred, nil
+}
+
+func (s *postService) TransferAll(fromAuthorID, toAuthorID, actorID uint) (int64, error) {
+	if fromAuthorID == toAuthorID {
+		return 0, ErrInvalidTransferTarget
+	}
+	if err := s.checkTransferTarget(toAuthorID); err != nil {
+		return 0, err
+	}
+
+	var transferred int64
+	err := s.uow.Do(func(repos repositories.Repositories) error {
+		var err error
+		if transferred, err = repos.Posts.TransferAuthor(fromAuthorID, toAuthorID, actorID); err != nil {
+			return err
+		}
+		return repos.AuditLogs.Create(&models.AuditLog{
+			ActorID:    actorID,
+			Action:     models.AuditPostsTransfer,
+			TargetType: "user",
+			TargetID:   fromAuthorID,
+			Details:    map[string]interface{}{"to_author_id": toAuthorID, "posts": transferred},
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return transferred, nil
+}
+
+// checkTransferTarget makes sure posts can be handed to the user authorID
+func (s *postService) checkTransferTarget(authorID uint) error {
+	author, err := s.userRepo.GetByID(authorID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidTransferTarget
+	}
+	if err != nil {
+		return err
+	}
+	if !transferTargetRoles[author.Role] {
+		return ErrInvalidTransferTarget
+	}
+	return nil
+}
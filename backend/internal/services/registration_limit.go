@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRegistrationLimitReached is returned when a client IP has already
+// registered the configured number of accounts within the window
+var ErrRegistrationLimitReached = errors.New("registration limit reached")
+
+// registrationLimiter counts successful registrations per client IP over a
+// rolling window. It is kept in memory, so counts reset on restart and are
+// not shared between instances.
+type registrationLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	now    func() time.Time
+	byIP   map[string][]time.Time
+}
+
+// newRegistrationLimiter returns nil when limit or window is not positive,
+// which disables the cap
+func newRegistrationLimiter(limit int, window time.Duration) *registrationLimiter {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+	return &registrationLimiter{
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+		byIP:   make(map[string][]time.Time),
+	}
+}
+
+// Allow reports whether ip may register another account
+func (l *registrationLimiter) Allow(ip string) bool {
+	if l == nil || ip == "" {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.recent(ip)) < l.limit
+}
+
+// Record counts a successful registration from ip
+func (l *registrationLimiter) Record(ip string) {
+	if l == nil || ip == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.byIP[ip] = append(l.recent(ip), l.now())
+}
+
+// recent drops registrations from ip that have left the window and returns
+// the rest. Callers hold mu.
+func (l *registrationLimiter) recent(ip string) []time.Time {
+	cutoff := l.now().Add(-l.window)
+	times := l.byIP[ip]
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	if i == len(times) {
+		delete(l.byIP, ip)
+		return nil
+	}
+	times = times[i:]
+	l.byIP[ip] = times
+	return times
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registrationFrom(ip string, n int) *models.RegisterRequest {
+	return &models.RegisterRequest{
+		Username: fmt.Sprintf("user%s%d", ip[len(ip)-1:], n),
+		Email:    fmt.Sprintf("user%d@%s.example.com", n, ip),
+		Password: "password123",
+		ClientIP: ip,
+	}
+}
+
+func TestAuthService_RegistrationLimitPerIP(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{
+		DefaultRole:        "author",
+		RegistrationsPerIP: 3,
+		RegistrationWindow: 24 * time.Hour,
+	}}
+	authService := NewAuthService(newFakeUserRepo(), nil, fakeJWTService{}, cfg)
+
+	for i := 0; i < 3; i++ {
+		_, err := authService.Register(registrationFrom("203.0.113.1", i))
+		require.NoError(t, err, "registration %d should be within the cap", i+1)
+	}
+
+	_, err := authService.Register(registrationFrom("203.0.113.1", 3))
+	assert.ErrorIs(t, err, ErrRegistrationLimitReached)
+
+	_, err = authService.Register(registrationFrom("203.0.113.2", 0))
+	assert.NoError(t, err, "another IP has its own allowance")
+}
+
+func TestAuthService_RegistrationLimitCountsOnlySuccesses(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{
+		RegistrationsPerIP: 1,
+		RegistrationWindow: time.Hour,
+	}}
+	authService := NewAuthService(newFakeUserRepo(), nil, fakeJWTService{}, cfg)
+
+	invalid := registrationFrom("203.0.113.1", 0)
+	invalid.Username = ""
+	_, err := authService.Register(invalid)
+	require.Error(t, err)
+
+	_, err = authService.Register(registrationFrom("203.0.113.1", 1))
+	assert.NoError(t, err)
+}
+
+func TestRegistrationLimiter_Window(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRegistrationLimiter(2, 24*time.Hour)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Record("198.51.100.7")
+	now = now.Add(time.Hour)
+	limiter.Record("198.51.100.7")
+	assert.False(t, limiter.Allow("198.51.100.7"))
+
+	now = now.Add(23 * time.Hour)
+	assert.True(t, limiter.Allow("198.51.100.7"), "the first registration has left the window")
+
+	assert.Nil(t, newRegistrationLimiter(0, time.Hour), "a zero limit disables the cap")
+	assert.True(t, (*registrationLimiter)(nil).Allow("198.51.100.7"))
+}
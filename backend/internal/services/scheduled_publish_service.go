@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/repositories"
+	"backend/pkg/events"
+	"backend/pkg/logger"
+	"backend/pkg/scheduler"
+
+	"go.uber.org/zap"
+)
+
+// ScheduledPublishService publishes drafts once the time their authors
+// scheduled them for has passed
+type ScheduledPublishService interface {
+	// Publish moves every draft whose PublishAt is due to published. If ctx
+	// is cancelled it stops between posts with a *scheduler.InterruptedError.
+	Publish(ctx context.Context) error
+}
+
+type scheduledPublishService struct {
+	postRepo repositories.PostRepository
+	bus      *events.Bus
+	now      func() time.Time
+}
+
+func NewScheduledPublishService(postRepo repositories.PostRepository, bus *events.Bus) ScheduledPublishService {
+	return &scheduledPublishService{
+		postRepo: postRepo,
+		bus:      bus,
+		now:      time.Now,
+	}
+}
+
+func (s *scheduledPublishService) Publish(ctx context.Context) error {
+	now := s.now()
+	due, err := s.postRepo.ListDueScheduled(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	published := 0
+	for i, post := range due {
+		if err := scheduler.Checkpoint(ctx, i, len(due)); err != nil {
+			return err
+		}
+		ok, err := s.postRepo.PublishScheduled(ctx, post.ID, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		published++
+
+		previous := post
+		post.Status = "published"
+		post.PublishedAt = &now
+		s.bus.Publish(ctx, PostEvent{Type: EventPostUpdated, Post: post, Previous: &previous})
+		s.bus.Publish(ctx, PostEvent{Type: EventPostStatusChanged, Post: post, Previous: &previous})
+		s.bus.Publish(ctx, PostEvent{Type: EventScheduledPublished, Post: post, Previous: &previous})
+	}
+
+	if published > 0 {
+		logger.LogInfo(ctx, "Published scheduled posts", zap.Int("published", published))
+	}
+	return nil
+}
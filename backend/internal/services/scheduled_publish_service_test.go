@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/pkg/events"
+	"backend/pkg/scheduler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScheduledPublishFixture() (PostService, ScheduledPublishService, *fakePostRepo) {
+	postRepo := newFakePostRepo()
+	categoryRepo := newFakeCategoryRepo(&models.Category{ID: 1, Name: "News"})
+	bus := events.NewBus()
+	return newTestPostService(withPostRepo(postRepo), withCategoryRepo(categoryRepo), withBus(bus)), NewScheduledPublishService(postRepo, bus), postRepo
+}
+
+func publicTitles(t *testing.T, postService PostService) []string {
+	t.Helper()
+	posts, _, _, err := postService.Search(&models.PostSearchRequest{}, 0, "")
+	require.NoError(t, err)
+	var titles []string
+	for _, post := range posts {
+		titles = append(titles, post.Title)
+	}
+	return titles
+}
+
+func TestScheduledPublishService_Publish(t *testing.T) {
+	t.Run("a post scheduled in the past becomes visible", func(t *testing.T) {
+		postService, publisher, postRepo := newScheduledPublishFixture()
+		post, _, err := postService.Create(&models.CreatePostRequest{
+			Title: "Due", Content: "Body", CategoryID: 1, Status: "draft",
+		}, 1, "author")
+		require.NoError(t, err)
+		// Scheduled for the near future, then the time passes
+		publishAt := time.Now().Add(-time.Minute)
+		postRepo.posts[post.ID].PublishAt = &publishAt
+		assert.Empty(t, publicTitles(t, postService))
+
+		require.NoError(t, publisher.Publish(context.Background()))
+
+		stored := postRepo.posts[post.ID]
+		assert.Equal(t, "published", stored.Status)
+		require.NotNil(t, stored.PublishedAt)
+		assert.Equal(t, []string{"Due"}, publicTitles(t, postService))
+		assert.True(t, CanViewPost(stored, 0, ""))
+	})
+
+	t.Run("a post scheduled in the future stays hidden", func(t *testing.T) {
+		postService, publisher, postRepo := newScheduledPublishFixture()
+		publishAt := time.Now().Add(time.Hour)
+		post, _, err := postService.Create(&models.CreatePostRequest{
+			Title: "Later", Content: "Body", CategoryID: 1, Status: "published", PublishAt: &publishAt,
+		}, 1, "author")
+		require.NoError(t, err)
+		assert.Equal(t, "draft", post.Status)
+
+		require.NoError(t, publisher.Publish(context.Background()))
+
+		stored := postRepo.posts[post.ID]
+		assert.Equal(t, "draft", stored.Status)
+		assert.Nil(t, stored.PublishedAt)
+		assert.Empty(t, publicTitles(t, postService))
+		assert.False(t, CanViewPost(stored, 0, ""))
+		assert.True(t, CanViewPost(stored, 1, "author"))
+	})
+
+	t.Run("a published post with a future publish time is not public", func(t *testing.T) {
+		postService, _, postRepo := newScheduledPublishFixture()
+		publishAt := time.Now().Add(time.Hour)
+		postRepo.Create(&models.Post{Title: "Early", AuthorID: 1, Status: "published", PublishAt: &publishAt})
+
+		assert.Empty(t, publicTitles(t, postService))
+		assert.False(t, CanViewPost(postRepo.posts[1], 0, ""))
+	})
+}
+
+func TestScheduledPublishService_PublishStopsWhenCancelled(t *testing.T) {
+	publishAt := time.Now().Add(-time.Minute)
+	postRepo := newFakePostRepo()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, postRepo.Create(&models.Post{Title: "Due", AuthorID: 1, Status: "draft", PublishAt: &publishAt}))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	publisher := NewScheduledPublishService(&cancellingPostRepo{fakePostRepo: postRepo, cancel: cancel, after: 2}, events.NewBus())
+
+	err := publisher.Publish(ctx)
+
+	var interrupted *scheduler.InterruptedError
+	require.ErrorAs(t, err, &interrupted)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, interrupted.Done)
+	assert.Equal(t, 5, interrupted.Total)
+	statuses := make(map[uint]string)
+	for id, post := range postRepo.posts {
+		statuses[id] = post.Status
+	}
+	assert.Equal(t, map[uint]string{1: "published", 2: "published", 3: "draft", 4: "draft", 5: "draft"}, statuses)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/pkg/metrics"
+)
+
+// recentPostsInStats is how many of the newest posts StatsResponse lists
+const recentPostsInStats = 10
+
+// StatsService reports site-wide statistics for admins
+type StatsService interface {
+	// GetStats aggregates users, posts and comments and refreshes the
+	// matching Prometheus gauges from the same counts
+	GetStats(ctx context.Context) (*models.StatsResponse, error)
+}
+
+type statsService struct {
+	statsRepo    repositories.StatsRepository
+	placeholders bool
+}
+
+func NewStatsService(statsRepo repositories.StatsRepository, cfg *config.Config) StatsService {
+	return &statsService{
+		statsRepo:    statsRepo,
+		placeholders: cfg.Post.DeletedRelations == DeletedRelationsPlaceholder,
+	}
+}
+
+func (s *statsService) GetStats(ctx context.Context) (*models.StatsResponse, error) {
+	users, err := s.statsRepo.CountUsersByRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.statsRepo.CountPostsByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.statsRepo.CountCommentsByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := s.statsRepo.RecentPosts(ctx, recentPostsInStats)
+	if err != nil {
+		return nil, err
+	}
+	for i := range recent {
+		recent[i].FillDeletedRelations(s.placeholders)
+	}
+
+	stats := &models.StatsResponse{
+		Users: models.UserCounts{
+			Total:  sumCounts(users),
+			Admin:  users["admin"],
+			Editor: users["editor"],
+			Author: users["author"],
+		},
+		Posts: models.PostCounts{
+			Total:     sumCounts(posts),
+			Draft:     posts["draft"],
+			Published: posts["published"],
+			Archived:  posts["archived"],
+		},
+		Comments: models.CommentCounts{
+			Total:    sumCounts(comments),
+			Approved: comments["approved"],
+			Pending:  comments["pending"],
+			Rejected: comments["rejected"],
+		},
+		RecentPosts: recent,
+	}
+
+	metrics.UpdateActiveUsers(int(stats.Users.Total))
+	metrics.UpdatePostsTotal(int(stats.Posts.Total))
+	metrics.UpdateCommentsTotal(int(stats.Comments.Total))
+
+	return stats, nil
+}
+
+func sumCounts(counts map[string]int64) int64 {
+	var total int64
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
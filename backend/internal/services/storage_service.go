@@ -1,6 +1,9 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -11,9 +14,11 @@ import (
 
 	"backend/internal/config"
 	"backend/internal/models"
+	"backend/internal/repositories"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/google/uuid"
@@ -24,40 +29,79 @@ type StorageService interface {
 	DeleteFile(filename string) error
 	GetFileURL(filename string) string
 	ValidateImageFile(file *multipart.FileHeader) error
+	// HealthCheck verifies the storage backend is reachable and writable
+	HealthCheck(ctx context.Context) error
+}
+
+var (
+	// ErrFileTooLarge is returned when an upload exceeds the configured size limit
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrInvalidImage is returned when an upload is not an allowed image type
+	ErrInvalidImage = errors.New("invalid image")
+)
+
+// validationError keeps the message shown to clients while letting callers
+// tell the kind of rejection apart with errors.Is
+type validationError struct {
+	kind    error
+	message string
+}
+
+func (e *validationError) Error() string {
+	return e.message
+}
+
+func (e *validationError) Unwrap() error {
+	return e.kind
 }
 
 type LocalStorageService struct {
-	config *config.StorageConfig
+	config     *config.StorageConfig
+	uploadRepo repositories.FileUploadRepository
+}
+
+// s3API is the subset of the S3 client the storage service uses, so tests can
+// substitute a fake for *s3.S3
+type s3API interface {
+	PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error)
+	DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error)
+	HeadBucketWithContext(ctx aws.Context, input *s3.HeadBucketInput, opts ...request.Option) (*s3.HeadBucketOutput, error)
 }
 
 type S3StorageService struct {
-	client *s3.S3
-	config *config.StorageConfig
+	client     s3API
+	config     *config.StorageConfig
+	uploadRepo repositories.FileUploadRepository
 }
 
-func NewStorageService(cfg *config.Config) StorageService {
+// NewStorageService returns the storage backend selected by the config.
+// Successful uploads are recorded in uploadRepo when it is not nil.
+func NewStorageService(cfg *config.Config, uploadRepo repositories.FileUploadRepository) (StorageService, error) {
 	switch cfg.Storage.Driver {
 	case "s3":
-		return NewS3StorageService(&cfg.Storage)
+		return NewS3StorageService(&cfg.Storage, uploadRepo)
+	case "gcs":
+		return NewGCSStorageService(&cfg.Storage, uploadRepo)
 	default:
-		return NewLocalStorageService(&cfg.Storage)
+		return NewLocalStorageService(&cfg.Storage, uploadRepo)
 	}
 }
 
-func NewLocalStorageService(cfg *config.StorageConfig) *LocalStorageService {
+func NewLocalStorageService(cfg *config.StorageConfig, uploadRepo repositories.FileUploadRepository) (*LocalStorageService, error) {
 	// Create upload directory if it doesn't exist
 	if err := os.MkdirAll(cfg.UploadDir, 0755); err != nil {
-		panic(fmt.Sprintf("Failed to create upload directory: %v", err))
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
 	}
 
 	return &LocalStorageService{
-		config: cfg,
-	}
+		config:     cfg,
+		uploadRepo: uploadRepo,
+	}, nil
 }
 
-func NewS3StorageService(cfg *config.StorageConfig) *S3StorageService {
+func NewS3StorageService(cfg *config.StorageConfig, uploadRepo repositories.FileUploadRepository) (*S3StorageService, error) {
 	if cfg.S3Bucket == "" {
-		panic("S3_BUCKET_NAME is required when using S3 storage")
+		return nil, errors.New("S3_BUCKET_NAME is required when using S3 storage")
 	}
 
 	awsConfig := &aws.Config{
@@ -75,13 +119,39 @@ func NewS3StorageService(cfg *config.StorageConfig) *S3StorageService {
 
 	sess, err := session.NewSession(awsConfig)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to create S3 session: %v", err))
+		return nil, fmt.Errorf("failed to create S3 session: %w", err)
 	}
 
+	return newS3StorageService(s3.New(sess), cfg, uploadRepo), nil
+}
+
+func newS3StorageService(client s3API, cfg *config.StorageConfig, uploadRepo repositories.FileUploadRepository) *S3StorageService {
 	return &S3StorageService{
-		client: s3.New(sess),
-		config: cfg,
+		client:     client,
+		config:     cfg,
+		uploadRepo: uploadRepo,
+	}
+}
+
+// recordUpload stores the metadata of a file that was just saved under
+// filename. A nil repository records nothing.
+func recordUpload(uploadRepo repositories.FileUploadRepository, fileHeader *multipart.FileHeader, filename, filePath, url string, userID uint) error {
+	if uploadRepo == nil {
+		return nil
 	}
+	err := uploadRepo.Create(&models.FileUpload{
+		OriginalName: filepath.Base(fileHeader.Filename),
+		Filename:     filename,
+		FilePath:     filePath,
+		FileSize:     fileHeader.Size,
+		MimeType:     fileHeader.Header.Get("Content-Type"),
+		URL:          url,
+		UserID:       userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record upload: %w", err)
+	}
+	return nil
 }
 
 // Local Storage Implementation
@@ -117,32 +187,66 @@ func (s *LocalStorageService) UploadFile(fileHeader *multipart.FileHeader, userI
 		return nil, fmt.Errorf("failed to copy file: %w", err)
 	}
 
+	dst.Close()
+
 	// Generate public URL
-	url := fmt.Sprintf("%s/uploads/%s", s.config.BaseURL, filename)
+	url := s.GetFileURL(filename)
+
+	thumbnailURL, err := s.storeThumbnail(fileHeader, filename)
+	if err != nil {
+		s.DeleteFile(filename)
+		return nil, err
+	}
+
+	// Don't keep files the database doesn't know about
+	if err := recordUpload(s.uploadRepo, fileHeader, filename, filePath, url, userID); err != nil {
+		s.DeleteFile(filename)
+		return nil, err
+	}
 
 	return &models.UploadResponse{
-		Success:  true,
-		Message:  "File uploaded successfully",
-		Filename: filename,
-		URL:      url,
-		Size:     fileHeader.Size,
-		MimeType: fileHeader.Header.Get("Content-Type"),
+		Success:      true,
+		Message:      "File uploaded successfully",
+		Filename:     filename,
+		URL:          url,
+		ThumbnailURL: thumbnailURL,
+		Size:         fileHeader.Size,
+		MimeType:     fileHeader.Header.Get("Content-Type"),
 	}, nil
 }
 
+// storeThumbnail saves a thumbnail of the upload next to it and returns its
+// URL, or the upload's own URL when no thumbnail is needed
+func (s *LocalStorageService) storeThumbnail(fileHeader *multipart.FileHeader, filename string) (string, error) {
+	name := thumbnailName(filename)
+	data, err := makeThumbnail(fileHeader, name, s.config.ThumbnailWidth)
+	if err != nil || data == nil {
+		return s.GetFileURL(filename), err
+	}
+
+	if err := os.WriteFile(filepath.Join(s.config.UploadDir, name), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+	return s.GetFileURL(name), nil
+}
+
+// DeleteFile removes an upload along with its thumbnail, if it has one
 func (s *LocalStorageService) DeleteFile(filename string) error {
 	filePath := filepath.Join(s.config.UploadDir, filename)
+	if err := os.Remove(filepath.Join(s.config.UploadDir, thumbnailName(filename))); err != nil && !os.IsNotExist(err) {
+		return err
+	}
 	return os.Remove(filePath)
 }
 
 func (s *LocalStorageService) GetFileURL(filename string) string {
-	return fmt.Sprintf("%s/uploads/%s", s.config.BaseURL, filename)
+	return s.config.PublicBaseURL() + "/" + filename
 }
 
 func (s *LocalStorageService) ValidateImageFile(fileHeader *multipart.FileHeader) error {
 	// Check file size
 	if fileHeader.Size > s.config.MaxFileSize {
-		return fmt.Errorf("file size exceeds maximum allowed size of %d bytes", s.config.MaxFileSize)
+		return &validationError{ErrFileTooLarge, fmt.Sprintf("file size exceeds maximum allowed size of %d bytes", s.config.MaxFileSize)}
 	}
 
 	// Check file extension
@@ -158,7 +262,7 @@ func (s *LocalStorageService) ValidateImageFile(fileHeader *multipart.FileHeader
 	}
 	
 	if !allowed {
-		return fmt.Errorf("file type not allowed. Allowed types: JPG, JPEG, PNG, GIF, WebP")
+		return &validationError{ErrInvalidImage, "file type not allowed. Allowed types: JPG, JPEG, PNG, GIF, WebP"}
 	}
 
 	// Check MIME type
@@ -180,12 +284,21 @@ func (s *LocalStorageService) ValidateImageFile(fileHeader *multipart.FileHeader
 	}
 	
 	if !allowed {
-		return fmt.Errorf("invalid MIME type. Expected image type, got: %s", mimeType)
+		return &validationError{ErrInvalidImage, fmt.Sprintf("invalid MIME type. Expected image type, got: %s", mimeType)}
 	}
 
 	return nil
 }
 
+func (s *LocalStorageService) HealthCheck(ctx context.Context) error {
+	probe, err := os.CreateTemp(s.config.UploadDir, ".healthcheck-*")
+	if err != nil {
+		return fmt.Errorf("upload directory is not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
 // S3 Storage Implementation
 func (s *S3StorageService) UploadFile(fileHeader *multipart.FileHeader, userID uint) (*models.UploadResponse, error) {
 	// Validate file
@@ -218,41 +331,70 @@ func (s *S3StorageService) UploadFile(fileHeader *multipart.FileHeader, userID u
 	}
 
 	// Generate public URL
-	var url string
-	if s.config.S3BaseURL != "" {
-		url = fmt.Sprintf("%s/%s", s.config.S3BaseURL, filename)
-	} else if s.config.S3Endpoint != "" {
-		url = fmt.Sprintf("%s/%s/%s", s.config.S3Endpoint, s.config.S3Bucket, filename)
-	} else {
-		url = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.config.S3Bucket, s.config.S3Region, filename)
+	url := s.GetFileURL(filename)
+
+	thumbnailURL, err := s.storeThumbnail(fileHeader, filename)
+	if err != nil {
+		s.DeleteFile(filename)
+		return nil, err
+	}
+
+	// Don't keep objects the database doesn't know about
+	if err := recordUpload(s.uploadRepo, fileHeader, filename, filename, url, userID); err != nil {
+		s.DeleteFile(filename)
+		return nil, err
 	}
 
 	return &models.UploadResponse{
-		Success:  true,
-		Message:  "File uploaded successfully",
-		Filename: filename,
-		URL:      url,
-		Size:     fileHeader.Size,
-		MimeType: fileHeader.Header.Get("Content-Type"),
+		Success:      true,
+		Message:      "File uploaded successfully",
+		Filename:     filename,
+		URL:          url,
+		ThumbnailURL: thumbnailURL,
+		Size:         fileHeader.Size,
+		MimeType:     fileHeader.Header.Get("Content-Type"),
 	}, nil
 }
 
-func (s *S3StorageService) DeleteFile(filename string) error {
-	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
-		Bucket: aws.String(s.config.S3Bucket),
-		Key:    aws.String(filename),
+// storeThumbnail uploads a thumbnail of the upload next to it and returns
+// its URL, or the upload's own URL when no thumbnail is needed
+func (s *S3StorageService) storeThumbnail(fileHeader *multipart.FileHeader, filename string) (string, error) {
+	name := thumbnailName(filename)
+	data, err := makeThumbnail(fileHeader, name, s.config.ThumbnailWidth)
+	if err != nil || data == nil {
+		return s.GetFileURL(filename), err
+	}
+
+	_, err = s.client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(s.config.S3Bucket),
+		Key:         aws.String(name),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(thumbnailMimeType(name)),
+		ACL:         aws.String("public-read"),
 	})
-	return err
+	if err != nil {
+		return "", fmt.Errorf("failed to upload thumbnail to S3: %w", err)
+	}
+	return s.GetFileURL(name), nil
 }
 
-func (s *S3StorageService) GetFileURL(filename string) string {
-	if s.config.S3BaseURL != "" {
-		return fmt.Sprintf("%s/%s", s.config.S3BaseURL, filename)
-	} else if s.config.S3Endpoint != "" {
-		return fmt.Sprintf("%s/%s/%s", s.config.S3Endpoint, s.config.S3Bucket, filename)
-	} else {
-		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.config.S3Bucket, s.config.S3Region, filename)
+// DeleteFile removes an upload along with its thumbnail, if it has one.
+// Deleting a missing key succeeds on S3.
+func (s *S3StorageService) DeleteFile(filename string) error {
+	for _, key := range []string{thumbnailName(filename), filename} {
+		_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+			Bucket: aws.String(s.config.S3Bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+func (s *S3StorageService) GetFileURL(filename string) string {
+	return s.config.PublicBaseURL() + "/" + filename
 }
 
 func (s *S3StorageService) ValidateImageFile(fileHeader *multipart.FileHeader) error {
@@ -261,6 +403,16 @@ func (s *S3StorageService) ValidateImageFile(fileHeader *multipart.FileHeader) e
 	return localStorage.ValidateImageFile(fileHeader)
 }
 
+func (s *S3StorageService) HealthCheck(ctx context.Context) error {
+	_, err := s.client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.config.S3Bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("bucket %s is not reachable: %w", s.config.S3Bucket, err)
+	}
+	return nil
+}
+
 // Utility functions
 func GetImageSizeLimit(cfg *config.StorageConfig) int64 {
 	if cfg != nil {
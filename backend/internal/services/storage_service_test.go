@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3Client records the requests the storage service makes and returns
+// the configured error
+type fakeS3Client struct {
+	s3API
+	putInputs    []*s3.PutObjectInput
+	putBodies    [][]byte
+	deleteInputs []*s3.DeleteObjectInput
+	err          error
+}
+
+func (c *fakeS3Client) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	body, _ := io.ReadAll(input.Body)
+	c.putInputs = append(c.putInputs, input)
+	c.putBodies = append(c.putBodies, body)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (c *fakeS3Client) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	c.deleteInputs = append(c.deleteInputs, input)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func testS3Config() *config.StorageConfig {
+	return &config.StorageConfig{
+		Driver:      "s3",
+		S3Bucket:    "blog-media",
+		S3Region:    "ap-southeast-1",
+		S3BaseURL:   "https://cdn.example.com",
+		MaxFileSize: 1024 * 1024,
+	}
+}
+
+// newImageHeader builds a multipart file header the way gin hands it to the
+// upload handler
+func newImageHeader(t *testing.T, filename, contentType string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="image"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "/", &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(int64(body.Len())+1024))
+
+	return req.MultipartForm.File["image"][0]
+}
+
+func TestS3StorageService_UploadFile(t *testing.T) {
+	client := &fakeS3Client{}
+	storage := newS3StorageService(client, testS3Config(), nil)
+
+	resp, err := storage.UploadFile(newImageHeader(t, "photo.png", "image/png", []byte("png-bytes")), 7)
+	require.NoError(t, err)
+
+	require.Len(t, client.putInputs, 1)
+	input := client.putInputs[0]
+	assert.Equal(t, "blog-media", aws.StringValue(input.Bucket))
+	assert.True(t, strings.HasPrefix(aws.StringValue(input.Key), "images/7/"))
+	assert.True(t, strings.HasSuffix(aws.StringValue(input.Key), ".png"))
+	assert.Equal(t, "public-read", aws.StringValue(input.ACL))
+	assert.Equal(t, "image/png", aws.StringValue(input.ContentType))
+	assert.Equal(t, []byte("png-bytes"), client.putBodies[0])
+
+	assert.Equal(t, aws.StringValue(input.Key), resp.Filename)
+	assert.Equal(t, "https://cdn.example.com/"+resp.Filename, resp.URL)
+}
+
+func TestS3StorageService_UploadFileRecordsUpload(t *testing.T) {
+	client := &fakeS3Client{}
+	uploadRepo := &fakeFileUploadRepo{}
+	storage := newS3StorageService(client, testS3Config(), uploadRepo)
+
+	resp, err := storage.UploadFile(newImageHeader(t, "photo.png", "image/png", []byte("png-bytes")), 7)
+	require.NoError(t, err)
+
+	require.Len(t, uploadRepo.uploads, 1)
+	upload := uploadRepo.uploads[0]
+	assert.Equal(t, "photo.png", upload.OriginalName)
+	assert.Equal(t, resp.Filename, upload.Filename)
+	assert.Equal(t, resp.URL, upload.URL)
+	assert.Equal(t, int64(len("png-bytes")), upload.FileSize)
+	assert.Equal(t, "image/png", upload.MimeType)
+	assert.Equal(t, uint(7), upload.UserID)
+
+	t.Run("the object is removed when the upload cannot be recorded", func(t *testing.T) {
+		client := &fakeS3Client{}
+		storage := newS3StorageService(client, testS3Config(), &fakeFileUploadRepo{err: errors.New("database is down")})
+
+		resp, err := storage.UploadFile(newImageHeader(t, "photo.png", "image/png", []byte("png-bytes")), 7)
+
+		assert.Nil(t, resp)
+		assert.Error(t, err)
+		require.NotEmpty(t, client.deleteInputs)
+		assert.Equal(t, aws.StringValue(client.putInputs[0].Key), aws.StringValue(client.deleteInputs[len(client.deleteInputs)-1].Key))
+	})
+}
+
+func TestLocalStorageService_UploadFileRecordsUpload(t *testing.T) {
+	cfg := &config.StorageConfig{
+		Driver:      "local",
+		UploadDir:   t.TempDir(),
+		BaseURL:     "http://localhost:8080",
+		MaxFileSize: 1024 * 1024,
+	}
+	uploadRepo := &fakeFileUploadRepo{}
+	storage, err := NewLocalStorageService(cfg, uploadRepo)
+	require.NoError(t, err)
+
+	resp, err := storage.UploadFile(newImageHeader(t, "photo.jpg", "image/jpeg", []byte("jpeg-bytes")), 3)
+	require.NoError(t, err)
+
+	require.Len(t, uploadRepo.uploads, 1)
+	upload := uploadRepo.uploads[0]
+	assert.Equal(t, "photo.jpg", upload.OriginalName)
+	assert.Equal(t, resp.Filename, upload.Filename)
+	assert.Equal(t, filepath.Join(cfg.UploadDir, resp.Filename), upload.FilePath)
+	assert.Equal(t, resp.URL, upload.URL)
+	assert.Equal(t, uint(3), upload.UserID)
+
+	t.Run("the file is removed when the upload cannot be recorded", func(t *testing.T) {
+		cfg := *cfg
+		cfg.UploadDir = t.TempDir()
+		storage, err := NewLocalStorageService(&cfg, &fakeFileUploadRepo{err: errors.New("database is down")})
+		require.NoError(t, err)
+
+		_, err = storage.UploadFile(newImageHeader(t, "photo.jpg", "image/jpeg", []byte("jpeg-bytes")), 3)
+		assert.Error(t, err)
+
+		entries, err := os.ReadDir(cfg.UploadDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestS3StorageService_UploadFileError(t *testing.T) {
+	client := &fakeS3Client{err: errors.New("access denied")}
+	storage := newS3StorageService(client, testS3Config(), nil)
+
+	resp, err := storage.UploadFile(newImageHeader(t, "photo.jpg", "image/jpeg", []byte("jpeg-bytes")), 7)
+
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, client.err)
+}
+
+func TestS3StorageService_DeleteFile(t *testing.T) {
+	client := &fakeS3Client{}
+	storage := newS3StorageService(client, testS3Config(), nil)
+
+	require.NoError(t, storage.DeleteFile("images/7/photo.png"))
+
+	require.Len(t, client.deleteInputs, 2)
+	assert.Equal(t, "blog-media", aws.StringValue(client.deleteInputs[0].Bucket))
+	assert.Equal(t, "images/7/photo_thumb.png", aws.StringValue(client.deleteInputs[0].Key))
+	assert.Equal(t, "images/7/photo.png", aws.StringValue(client.deleteInputs[1].Key))
+}
+
+func TestNewS3StorageService_RequiresBucket(t *testing.T) {
+	cfg := testS3Config()
+	cfg.S3Bucket = ""
+
+	storage, err := NewS3StorageService(cfg, nil)
+
+	assert.Nil(t, storage)
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// thumbnailSuffix is added to an upload's name, before the extension, to
+// name its thumbnail
+const thumbnailSuffix = "_thumb"
+
+// thumbnailName returns the name a thumbnail of filename is stored under.
+// WebP can be decoded but not encoded, so its thumbnails are PNGs.
+func thumbnailName(filename string) string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	if strings.EqualFold(ext, ".webp") {
+		ext = ".png"
+	}
+	return base + thumbnailSuffix + ext
+}
+
+// thumbnailMimeType returns the content type of a thumbnail stored under name
+func thumbnailMimeType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	default:
+		return "image/png"
+	}
+}
+
+// makeThumbnail scales the uploaded image down to width pixels wide, keeping
+// its aspect ratio, and encodes it for storage under name. It returns nil
+// when no thumbnail is needed: thumbnails are disabled, the image is a GIF,
+// or it is already no wider than width. Content that cannot be decoded is
+// left to be served as uploaded.
+func makeThumbnail(fileHeader *multipart.FileHeader, name string, width int) ([]byte, error) {
+	if width <= 0 || strings.EqualFold(filepath.Ext(fileHeader.Filename), ".gif") {
+		return nil, nil
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	img, _, err := image.Decode(src)
+	if err != nil {
+		return nil, nil
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() <= width {
+		return nil, nil
+	}
+
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(thumb, thumb.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if thumbnailMimeType(name) == "image/jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
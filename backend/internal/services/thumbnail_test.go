@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeTestImage(t *testing.T, format string, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, height/2, color.RGBA{R: 200, A: 255})
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	case "gif":
+		require.NoError(t, gif.Encode(&buf, img, nil))
+	default:
+		require.NoError(t, png.Encode(&buf, img))
+	}
+	return buf.Bytes()
+}
+
+func newThumbnailStorage(t *testing.T, width int) *LocalStorageService {
+	storage, err := NewLocalStorageService(&config.StorageConfig{
+		Driver:         "local",
+		UploadDir:      t.TempDir(),
+		BaseURL:        "http://localhost:8080",
+		MaxFileSize:    5 * 1024 * 1024,
+		ThumbnailWidth: width,
+	}, nil)
+	require.NoError(t, err)
+	return storage
+}
+
+func decodeStoredImage(t *testing.T, storage *LocalStorageService, url string) image.Config {
+	name := strings.TrimPrefix(url, storage.config.PublicBaseURL()+"/")
+	file, err := os.Open(filepath.Join(storage.config.UploadDir, name))
+	require.NoError(t, err)
+	defer file.Close()
+
+	cfg, _, err := image.DecodeConfig(file)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestLocalStorageService_UploadFileThumbnails(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		format      string
+	}{
+		{"png", "photo.png", "image/png", "png"},
+		{"jpeg", "photo.jpg", "image/jpeg", "jpeg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newThumbnailStorage(t, 400)
+
+			resp, err := storage.UploadFile(newImageHeader(t, tt.filename, tt.contentType, encodeTestImage(t, tt.format, 1200, 900)), 1)
+			require.NoError(t, err)
+
+			assert.NotEqual(t, resp.URL, resp.ThumbnailURL)
+			assert.Equal(t, thumbnailName(resp.Filename), strings.TrimPrefix(resp.ThumbnailURL, storage.config.PublicBaseURL()+"/"))
+
+			thumb := decodeStoredImage(t, storage, resp.ThumbnailURL)
+			assert.Equal(t, 400, thumb.Width)
+			assert.Equal(t, 300, thumb.Height)
+
+			original := decodeStoredImage(t, storage, resp.URL)
+			assert.Equal(t, 1200, original.Width)
+		})
+	}
+
+	t.Run("the width is configurable", func(t *testing.T) {
+		storage := newThumbnailStorage(t, 150)
+
+		resp, err := storage.UploadFile(newImageHeader(t, "wide.png", "image/png", encodeTestImage(t, "png", 600, 200)), 1)
+		require.NoError(t, err)
+
+		thumb := decodeStoredImage(t, storage, resp.ThumbnailURL)
+		assert.Equal(t, 150, thumb.Width)
+		assert.Equal(t, 50, thumb.Height)
+	})
+
+	t.Run("GIFs and small images are served as uploaded", func(t *testing.T) {
+		storage := newThumbnailStorage(t, 400)
+
+		animated, err := storage.UploadFile(newImageHeader(t, "anim.gif", "image/gif", encodeTestImage(t, "gif", 1200, 900)), 1)
+		require.NoError(t, err)
+		assert.Equal(t, animated.URL, animated.ThumbnailURL)
+
+		small, err := storage.UploadFile(newImageHeader(t, "icon.png", "image/png", encodeTestImage(t, "png", 64, 64)), 1)
+		require.NoError(t, err)
+		assert.Equal(t, small.URL, small.ThumbnailURL)
+	})
+
+	t.Run("deleting an upload removes its thumbnail", func(t *testing.T) {
+		storage := newThumbnailStorage(t, 400)
+		resp, err := storage.UploadFile(newImageHeader(t, "photo.png", "image/png", encodeTestImage(t, "png", 800, 600)), 1)
+		require.NoError(t, err)
+
+		require.NoError(t, storage.DeleteFile(resp.Filename))
+
+		entries, err := os.ReadDir(storage.config.UploadDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestS3StorageService_UploadFileThumbnail(t *testing.T) {
+	client := &fakeS3Client{}
+	cfg := testS3Config()
+	cfg.ThumbnailWidth = 400
+	storage := newS3StorageService(client, cfg, nil)
+
+	resp, err := storage.UploadFile(newImageHeader(t, "photo.png", "image/png", encodeTestImage(t, "png", 800, 400)), 7)
+	require.NoError(t, err)
+
+	require.Len(t, client.putInputs, 2)
+	thumbKey := aws.StringValue(client.putInputs[1].Key)
+	assert.Equal(t, thumbnailName(resp.Filename), thumbKey)
+	assert.Equal(t, "image/png", aws.StringValue(client.putInputs[1].ContentType))
+	assert.Equal(t, "https://cdn.example.com/"+thumbKey, resp.ThumbnailURL)
+
+	thumb, _, err := image.DecodeConfig(bytes.NewReader(client.putBodies[1]))
+	require.NoError(t, err)
+	assert.Equal(t, 400, thumb.Width)
+	assert.Equal(t, 200, thumb.Height)
+}
+
+func TestThumbnailName(t *testing.T) {
+	assert.Equal(t, "images/7/abc_thumb.jpg", thumbnailName("images/7/abc.jpg"))
+	assert.Equal(t, "abc_thumb.png", thumbnailName("abc.webp"), "WebP thumbnails are stored as PNG")
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/repositories"
+	"backend/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// TrashPurgeService empties the trash of posts deleted long enough ago
+type TrashPurgeService interface {
+	// Purge permanently removes posts deleted more than the configured
+	// number of days ago, along with their comments
+	Purge(ctx context.Context) error
+}
+
+type trashPurgeService struct {
+	postRepo repositories.PostRepository
+	maxAge   time.Duration
+}
+
+func NewTrashPurgeService(postRepo repositories.PostRepository, cfg *config.Config) TrashPurgeService {
+	return &trashPurgeService{
+		postRepo: postRepo,
+		maxAge:   time.Duration(cfg.Jobs.TrashRetentionDays) * 24 * time.Hour,
+	}
+}
+
+func (s *trashPurgeService) Purge(ctx context.Context) error {
+	// Without a retention period posts could not be restored at all
+	if s.maxAge <= 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-s.maxAge)
+	purged, err := s.postRepo.WithContext(ctx).PurgeTrashed(before)
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		logger.LogInfo(ctx, "Purged deleted posts",
+			zap.Int64("purged", purged),
+			zap.Time("deleted_before", before),
+		)
+	}
+	return nil
+}
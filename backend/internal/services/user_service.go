@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	// ErrOwnAccount is returned when an admin tries to demote or delete
+	// themselves, which could leave the site without an admin
+	ErrOwnAccount = errors.New("admins cannot demote or delete their own account")
+)
+
+// UserService backs the admin user management endpoints and public author
+// profiles
+type UserService interface {
+	// WithContext returns a copy of the service whose queries run with ctx,
+	// typically the request's, so they are abandoned once it is cancelled
+	WithContext(ctx context.Context) UserService
+	// List pages through users, each with the number of posts they own
+	List(filter models.UserListFilter, page, perPage int) ([]models.User, int64, error)
+	GetByID(id uint) (*models.User, error)
+	// UpdateRole changes a user's role on behalf of the admin actorID and
+	// signs them out everywhere, since their tokens carry the old role
+	UpdateRole(id uint, role string, actorID uint) (*models.User, error)
+	// Delete soft-deletes a user on behalf of the admin actorID and signs
+	// them out everywhere
+	Delete(id uint, actorID uint) error
+	// AuthorProfile returns the public profile of a user
+	AuthorProfile(id uint) (*models.AuthorProfile, error)
+}
+
+type userService struct {
+	userRepo   repositories.UserRepository
+	postRepo   repositories.PostRepository
+	jwtService JWTService
+}
+
+func NewUserService(userRepo repositories.UserRepository, postRepo repositories.PostRepository, jwtService JWTService) UserService {
+	return &userService{userRepo: userRepo, postRepo: postRepo, jwtService: jwtService}
+}
+
+func (s *userService) WithContext(ctx context.Context) UserService {
+	return &userService{
+		userRepo:   s.userRepo.WithContext(ctx),
+		postRepo:   s.postRepo.WithContext(ctx),
+		jwtService: s.jwtService,
+	}
+}
+
+func (s *userService) List(filter models.UserListFilter, page, perPage int) ([]models.User, int64, error) {
+	users, total, err := s.userRepo.List(filter, page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, len(users))
+	for i := range users {
+		ids[i] = users[i].ID
+	}
+	counts, err := s.postRepo.CountByAuthors(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range users {
+		count := counts[users[i].ID]
+		users[i].PostCount = &count
+	}
+	return users, total, nil
+}
+
+func (s *userService) GetByID(id uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) UpdateRole(id uint, role string, actorID uint) (*models.User, error) {
+	if id == actorID && role != "admin" {
+		return nil, ErrOwnAccount
+	}
+
+	user, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	user.Role = role
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	if err := s.jwtService.RevokeAllUserTokens(user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) Delete(id uint, actorID uint) error {
+	if id == actorID {
+		return ErrOwnAccount
+	}
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.jwtService.RevokeAllUserTokens(id)
+}
+
+func (s *userService) AuthorProfile(id uint) (*models.AuthorProfile, error) {
+	user, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.postRepo.CountByAuthor(user.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthorProfile{
+		ID:        user.ID,
+		Username:  user.Username,
+		Name:      user.Name,
+		PostCount: count,
+		JoinedAt:  user.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/pkg/events"
+	"backend/pkg/logger"
+	"backend/pkg/metrics"
+
+	"go.uber.org/zap"
+)
+
+// Webhook queue policies, see config.WebhookConfig.QueuePolicy
+const (
+	WebhookQueueDrop  = "drop"
+	WebhookQueueBlock = "block"
+)
+
+// ErrWebhookQueueFull is returned when a delivery is dropped because too many
+// are already waiting for a worker
+var ErrWebhookQueueFull = errors.New("webhook queue is full")
+
+// webhookEvents are the bus events forwarded to webhook endpoints
+var webhookEvents = []string{
+	EventPostCreated,
+	EventPostUpdated,
+	EventPostDeleted,
+	EventPostRestored,
+	EventPostStatusChanged,
+	EventCommentApproved,
+}
+
+// WebhookPayload is the JSON body POSTed to webhook endpoints
+type WebhookPayload struct {
+	Event      string      `json:"event"`
+	Data       interface{} `json:"data"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// WebhookDispatcher forwards post and comment events to the configured
+// endpoints. Deliveries wait in a bounded queue for a pool of workers, so a
+// burst of events never holds up the request that caused it for longer than
+// the queue policy allows. An endpoint that keeps failing is suspended for a
+// while instead of tying up the workers; queue depth and delivery outcomes
+// are recorded in blogcms_webhook_queue_depth and
+// blogcms_webhook_deliveries_total.
+type WebhookDispatcher struct {
+	client       *http.Client
+	endpoints    []*webhookEndpoint
+	jobs         chan webhookDelivery
+	workers      int
+	policy       string
+	blockTimeout time.Duration
+	threshold    int
+	suspendFor   time.Duration
+	now          func() time.Time
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// webhookEndpoint tracks consecutive failures of one URL
+type webhookEndpoint struct {
+	url string
+
+	mu             sync.Mutex
+	failures       int
+	suspendedUntil time.Time
+}
+
+type webhookDelivery struct {
+	endpoint *webhookEndpoint
+	event    string
+	body     []byte
+}
+
+// NewWebhookDispatcher creates a dispatcher for cfg.URLs. Events are only
+// delivered once Start has been called.
+func NewWebhookDispatcher(cfg *config.WebhookConfig) *WebhookDispatcher {
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	size := cfg.QueueSize
+	if size < 1 {
+		size = 1
+	}
+
+	endpoints := make([]*webhookEndpoint, len(cfg.URLs))
+	for i, url := range cfg.URLs {
+		endpoints[i] = &webhookEndpoint{url: url}
+	}
+
+	return &WebhookDispatcher{
+		client:       &http.Client{Timeout: cfg.Timeout},
+		endpoints:    endpoints,
+		jobs:         make(chan webhookDelivery, size),
+		workers:      workers,
+		policy:       cfg.QueuePolicy,
+		blockTimeout: cfg.QueueBlockTimeout,
+		threshold:    cfg.FailureThreshold,
+		suspendFor:   cfg.SuspendFor,
+		now:          time.Now,
+	}
+}
+
+// Subscribe registers the dispatcher for the events it forwards
+func (d *WebhookDispatcher) Subscribe(bus *events.Bus) {
+	for _, name := range webhookEvents {
+		bus.Subscribe(name, func(ctx context.Context, event events.Event) {
+			if err := d.Dispatch(ctx, event); err != nil {
+				logger.LogError(ctx, "Failed to queue webhook", err, zap.String("event", event.Name()))
+			}
+		})
+	}
+}
+
+// Dispatch queues the event for every endpoint. When the queue is full the
+// delivery is dropped, or with the block policy once no room frees up in
+// time, and ErrWebhookQueueFull is returned.
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, event events.Event) error {
+	body, err := json.Marshal(WebhookPayload{
+		Event:      event.Name(),
+		Data:       webhookData(event),
+		OccurredAt: d.now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	var dropped error
+	for _, endpoint := range d.endpoints {
+		if err := d.enqueue(ctx, webhookDelivery{endpoint: endpoint, event: event.Name(), body: body}); err != nil {
+			dropped = err
+		}
+	}
+	return dropped
+}
+
+func (d *WebhookDispatcher) enqueue(ctx context.Context, delivery webhookDelivery) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if !d.closed {
+		select {
+		case d.jobs <- delivery:
+			metrics.SetWebhookQueueDepth(len(d.jobs))
+			return nil
+		default:
+		}
+
+		if d.policy == WebhookQueueBlock {
+			timer := time.NewTimer(d.blockTimeout)
+			defer timer.Stop()
+
+			select {
+			case d.jobs <- delivery:
+				metrics.SetWebhookQueueDepth(len(d.jobs))
+				return nil
+			case <-timer.C:
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	metrics.RecordWebhookDelivery(metrics.WebhookDropped)
+	return ErrWebhookQueueFull
+}
+
+// Start launches the worker pool
+func (d *WebhookDispatcher) Start(ctx context.Context) error {
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.run()
+	}
+	return nil
+}
+
+// Stop stops accepting events and waits for the queued ones to be
+// delivered, or for ctx to expire
+func (d *WebhookDispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.jobs)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *WebhookDispatcher) run() {
+	defer d.wg.Done()
+	for delivery := range d.jobs {
+		metrics.SetWebhookQueueDepth(len(d.jobs))
+		d.deliver(delivery)
+	}
+}
+
+// deliver POSTs the delivery unless its endpoint is suspended. Once the
+// suspension has passed the endpoint is tried again; a further failure
+// suspends it straight away, a success clears its failures.
+func (d *WebhookDispatcher) deliver(delivery webhookDelivery) {
+	endpoint := delivery.endpoint
+	if endpoint.suspended(d.now()) {
+		metrics.RecordWebhookDelivery(metrics.WebhookSuspended)
+		return
+	}
+
+	err := d.post(endpoint.url, delivery)
+	if err == nil {
+		endpoint.succeeded()
+		metrics.RecordWebhookDelivery(metrics.WebhookDelivered)
+		return
+	}
+
+	metrics.RecordWebhookDelivery(metrics.WebhookFailed)
+	ctx := context.Background()
+	logger.LogError(ctx, "Webhook delivery failed", err,
+		zap.String("url", endpoint.url),
+		zap.String("event", delivery.event),
+	)
+	if until, suspended := endpoint.failed(d.now(), d.threshold, d.suspendFor); suspended {
+		logger.LogWarn(ctx, "Suspending failing webhook endpoint",
+			zap.String("url", endpoint.url),
+			zap.Time("until", until),
+		)
+	}
+}
+
+func (d *WebhookDispatcher) post(url string, delivery webhookDelivery) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(delivery.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.event)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *webhookEndpoint) suspended(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return now.Before(e.suspendedUntil)
+}
+
+func (e *webhookEndpoint) succeeded() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures = 0
+	e.suspendedUntil = time.Time{}
+}
+
+// failed counts a failure and reports whether, and until when, it suspended
+// the endpoint
+func (e *webhookEndpoint) failed(now time.Time, threshold int, suspendFor time.Duration) (time.Time, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.failures++
+	if threshold <= 0 || e.failures < threshold {
+		return time.Time{}, false
+	}
+	e.suspendedUntil = now.Add(suspendFor)
+	return e.suspendedUntil, true
+}
+
+// webhookData is the data sent for an event
+func webhookData(event events.Event) interface{} {
+	switch e := event.(type) {
+	case PostEvent:
+		return map[string]interface{}{"post": e.Post, "previous": e.Previous}
+	case CommentEvent:
+		return map[string]interface{}{"comment": e.Comment}
+	default:
+		return event
+	}
+}
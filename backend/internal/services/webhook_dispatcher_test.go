@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookEndpointServer counts deliveries and answers with status
+type webhookEndpointServer struct {
+	*httptest.Server
+	hits   atomic.Int64
+	status atomic.Int64
+}
+
+func newWebhookEndpointServer(t *testing.T, status int) *webhookEndpointServer {
+	endpoint := &webhookEndpointServer{}
+	endpoint.status.Store(int64(status))
+	endpoint.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint.hits.Add(1)
+		w.WriteHeader(int(endpoint.status.Load()))
+	}))
+	t.Cleanup(endpoint.Close)
+	return endpoint
+}
+
+// fakeClock is a settable clock safe to read from the workers
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// webhookDeliveries returns the current blogcms_webhook_deliveries_total
+// count for result
+func webhookDeliveries(t *testing.T, result string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "blogcms_webhook_deliveries_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "result" && pair.GetValue() == result {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func postCreated(id uint) PostEvent {
+	return PostEvent{Type: EventPostCreated, Post: models.Post{ID: id, Title: "A post"}}
+}
+
+func stopWebhookDispatcher(t *testing.T, dispatcher *WebhookDispatcher) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, dispatcher.Stop(ctx))
+}
+
+func TestWebhookDispatcher_BurstIsDeliveredByThePool(t *testing.T) {
+	endpoint := newWebhookEndpointServer(t, http.StatusOK)
+	dispatcher := NewWebhookDispatcher(&config.WebhookConfig{
+		URLs:      []string{endpoint.URL},
+		Workers:   4,
+		QueueSize: 100,
+		Timeout:   time.Second,
+	})
+	require.NoError(t, dispatcher.Start(context.Background()))
+
+	for i := 1; i <= 50; i++ {
+		require.NoError(t, dispatcher.Dispatch(context.Background(), postCreated(uint(i))))
+	}
+	stopWebhookDispatcher(t, dispatcher)
+
+	assert.Equal(t, int64(50), endpoint.hits.Load())
+}
+
+func TestWebhookDispatcher_FullQueue(t *testing.T) {
+	t.Run("drop discards deliveries beyond the queue", func(t *testing.T) {
+		endpoint := newWebhookEndpointServer(t, http.StatusOK)
+		dispatcher := NewWebhookDispatcher(&config.WebhookConfig{
+			URLs:        []string{endpoint.URL},
+			QueueSize:   2,
+			QueuePolicy: WebhookQueueDrop,
+			Timeout:     time.Second,
+		})
+		dropped := webhookDeliveries(t, "dropped")
+
+		// Nothing is delivered before Start, so the queue fills up
+		var errs []error
+		for i := 1; i <= 5; i++ {
+			errs = append(errs, dispatcher.Dispatch(context.Background(), postCreated(uint(i))))
+		}
+		assert.NoError(t, errs[0])
+		assert.NoError(t, errs[1])
+		for _, err := range errs[2:] {
+			assert.ErrorIs(t, err, ErrWebhookQueueFull)
+		}
+		assert.Equal(t, dropped+3, webhookDeliveries(t, "dropped"))
+
+		require.NoError(t, dispatcher.Start(context.Background()))
+		stopWebhookDispatcher(t, dispatcher)
+		assert.Equal(t, int64(2), endpoint.hits.Load())
+	})
+
+	t.Run("block waits for room", func(t *testing.T) {
+		endpoint := newWebhookEndpointServer(t, http.StatusOK)
+		dispatcher := NewWebhookDispatcher(&config.WebhookConfig{
+			URLs:              []string{endpoint.URL},
+			Workers:           1,
+			QueueSize:         1,
+			QueuePolicy:       WebhookQueueBlock,
+			QueueBlockTimeout: 5 * time.Second,
+			Timeout:           time.Second,
+		})
+		require.NoError(t, dispatcher.Start(context.Background()))
+
+		for i := 1; i <= 10; i++ {
+			require.NoError(t, dispatcher.Dispatch(context.Background(), postCreated(uint(i))))
+		}
+		stopWebhookDispatcher(t, dispatcher)
+
+		assert.Equal(t, int64(10), endpoint.hits.Load())
+	})
+
+	t.Run("block gives up once the timeout passes", func(t *testing.T) {
+		endpoint := newWebhookEndpointServer(t, http.StatusOK)
+		dispatcher := NewWebhookDispatcher(&config.WebhookConfig{
+			URLs:              []string{endpoint.URL},
+			QueueSize:         1,
+			QueuePolicy:       WebhookQueueBlock,
+			QueueBlockTimeout: 20 * time.Millisecond,
+			Timeout:           time.Second,
+		})
+
+		require.NoError(t, dispatcher.Dispatch(context.Background(), postCreated(1)))
+		started := time.Now()
+		err := dispatcher.Dispatch(context.Background(), postCreated(2))
+
+		assert.ErrorIs(t, err, ErrWebhookQueueFull)
+		assert.GreaterOrEqual(t, time.Since(started), 20*time.Millisecond)
+		require.NoError(t, dispatcher.Start(context.Background()))
+		stopWebhookDispatcher(t, dispatcher)
+	})
+}
+
+func TestWebhookDispatcher_SuspendsFailingEndpoint(t *testing.T) {
+	failing := newWebhookEndpointServer(t, http.StatusInternalServerError)
+	healthy := newWebhookEndpointServer(t, http.StatusOK)
+	clock := &fakeClock{now: time.Now()}
+	dispatcher := NewWebhookDispatcher(&config.WebhookConfig{
+		URLs:             []string{failing.URL, healthy.URL},
+		Workers:          1,
+		QueueSize:        10,
+		Timeout:          time.Second,
+		FailureThreshold: 2,
+		SuspendFor:       time.Minute,
+	})
+	dispatcher.now = clock.Now
+	require.NoError(t, dispatcher.Start(context.Background()))
+	t.Cleanup(func() { stopWebhookDispatcher(t, dispatcher) })
+
+	// dispatch sends one event and waits until the healthy endpoint, queued
+	// after the failing one, has it
+	dispatch := func(id uint) {
+		want := healthy.hits.Load() + 1
+		require.NoError(t, dispatcher.Dispatch(context.Background(), postCreated(id)))
+		require.Eventually(t, func() bool { return healthy.hits.Load() == want }, 5*time.Second, time.Millisecond)
+	}
+
+	dispatch(1)
+	dispatch(2)
+	assert.Equal(t, int64(2), failing.hits.Load())
+
+	// The threshold was reached, so deliveries are skipped for a while
+	suspended := webhookDeliveries(t, "suspended")
+	dispatch(3)
+	dispatch(4)
+	assert.Equal(t, int64(2), failing.hits.Load())
+	assert.Equal(t, suspended+2, webhookDeliveries(t, "suspended"))
+
+	// Once the suspension passes the endpoint is tried again, and one more
+	// failure suspends it straight away
+	clock.Advance(time.Minute)
+	dispatch(5)
+	dispatch(6)
+	assert.Equal(t, int64(3), failing.hits.Load())
+
+	// A success after the next suspension clears the failures
+	clock.Advance(time.Minute)
+	failing.status.Store(http.StatusOK)
+	dispatch(7)
+	failing.status.Store(http.StatusInternalServerError)
+	dispatch(8)
+	dispatch(9)
+	assert.Equal(t, int64(6), failing.hits.Load())
+}
@@ -0,0 +1,87 @@
+package startup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/pkg/health"
+	"backend/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ReadinessGate is a lifecycle worker that marks the server ready once its
+// checks pass and every warmup step has run, and not ready again when it is
+// stopped. Register it after the other workers so it starts last.
+type ReadinessGate struct {
+	readiness *health.Readiness
+	checker   *health.HealthChecker
+	interval  time.Duration
+	warmups   []func(ctx context.Context) error
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// NewReadinessGate creates a gate that runs checker, and then warmups in
+// order, every interval until they all succeed
+func NewReadinessGate(readiness *health.Readiness, checker *health.HealthChecker, interval time.Duration, warmups ...func(ctx context.Context) error) *ReadinessGate {
+	return &ReadinessGate{
+		readiness: readiness,
+		checker:   checker,
+		interval:  interval,
+		warmups:   warmups,
+	}
+}
+
+// Start begins waiting for the checks in the background and returns at once
+func (g *ReadinessGate) Start(ctx context.Context) error {
+	ctx, g.cancel = context.WithCancel(ctx)
+	g.done.Add(1)
+	go func() {
+		defer g.done.Done()
+		g.wait(ctx)
+	}()
+	return nil
+}
+
+// Stop marks the server not ready and stops waiting if it still is
+func (g *ReadinessGate) Stop(ctx context.Context) error {
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.done.Wait()
+	g.readiness.MarkNotReady()
+	return nil
+}
+
+func (g *ReadinessGate) wait(ctx context.Context) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	// Warmup steps that succeeded are not repeated on later attempts
+	warmed := 0
+	for {
+		if g.checker.CheckHealth(ctx).Status != health.StatusUnhealthy {
+			for warmed < len(g.warmups) {
+				if err := g.warmups[warmed](ctx); err != nil {
+					logger.LogError(ctx, "Warmup failed, retrying", err, zap.Int("step", warmed+1))
+					break
+				}
+				warmed++
+			}
+			if warmed == len(g.warmups) {
+				g.readiness.MarkReady()
+				logger.LogInfo(ctx, "Server is ready for traffic")
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/pkg/health"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGatedChecker returns a checker whose only check fails until healthy is
+// set
+func newGatedChecker(healthy *atomic.Bool) *health.HealthChecker {
+	checker := health.NewHealthChecker()
+	checker.AddChecker("migrations", health.NewFuncChecker("migrations", func(ctx context.Context) error {
+		if !healthy.Load() {
+			return errors.New("migrations pending")
+		}
+		return nil
+	}))
+	return checker
+}
+
+func TestReadinessGate(t *testing.T) {
+	t.Run("not ready until the checks pass and warmup finishes", func(t *testing.T) {
+		var healthy atomic.Bool
+		var warmups atomic.Int32
+		readiness := health.NewReadiness()
+		gate := NewReadinessGate(readiness, newGatedChecker(&healthy), time.Millisecond, func(ctx context.Context) error {
+			warmups.Add(1)
+			return nil
+		})
+
+		require.NoError(t, gate.Start(context.Background()))
+		defer gate.Stop(context.Background())
+		time.Sleep(20 * time.Millisecond)
+		assert.False(t, readiness.Ready(), "starting up")
+		assert.Zero(t, warmups.Load(), "warmup waits for the checks")
+
+		healthy.Store(true)
+		assert.Eventually(t, readiness.Ready, time.Second, time.Millisecond, "serving")
+		assert.Equal(t, int32(1), warmups.Load())
+	})
+
+	t.Run("a failed warmup is retried before going ready", func(t *testing.T) {
+		var healthy atomic.Bool
+		healthy.Store(true)
+		var attempts atomic.Int32
+		readiness := health.NewReadiness()
+		gate := NewReadinessGate(readiness, newGatedChecker(&healthy), time.Millisecond, func(ctx context.Context) error {
+			if attempts.Add(1) < 3 {
+				return errors.New("cache unavailable")
+			}
+			return nil
+		})
+
+		require.NoError(t, gate.Start(context.Background()))
+		defer gate.Stop(context.Background())
+
+		assert.Eventually(t, readiness.Ready, time.Second, time.Millisecond)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("stopping marks the server not ready", func(t *testing.T) {
+		var healthy atomic.Bool
+		healthy.Store(true)
+		readiness := health.NewReadiness()
+		gate := NewReadinessGate(readiness, newGatedChecker(&healthy), time.Millisecond)
+
+		require.NoError(t, gate.Start(context.Background()))
+		require.Eventually(t, readiness.Ready, time.Second, time.Millisecond)
+
+		require.NoError(t, gate.Stop(context.Background()))
+		assert.False(t, readiness.Ready(), "shutting down")
+	})
+
+	t.Run("stopping before the checks pass leaves it not ready", func(t *testing.T) {
+		var healthy atomic.Bool
+		readiness := health.NewReadiness()
+		gate := NewReadinessGate(readiness, newGatedChecker(&healthy), time.Millisecond)
+
+		require.NoError(t, gate.Start(context.Background()))
+		require.NoError(t, gate.Stop(context.Background()))
+
+		healthy.Store(true)
+		time.Sleep(10 * time.Millisecond)
+		assert.False(t, readiness.Ready())
+	})
+}
@@ -0,0 +1,76 @@
+package startup
+
+import (
+	"context"
+	"sort"
+
+	"backend/internal/config"
+	"backend/internal/middleware"
+	"backend/pkg/health"
+
+	"go.uber.org/zap"
+)
+
+// Result is the outcome of the startup self-check
+type Result struct {
+	Health health.HealthResponse
+	// Failed lists the critical checks that were not healthy
+	Failed []string
+}
+
+// Passed reports whether every critical check was healthy
+func (r Result) Passed() bool {
+	return len(r.Failed) == 0
+}
+
+// SelfCheck runs every registered health check once. A critical check that
+// comes back unhealthy fails the self-check; degraded results and failures of
+// non-critical checks are only reported.
+func SelfCheck(ctx context.Context, checker *health.HealthChecker, critical ...string) Result {
+	result := Result{Health: checker.CheckHealth(ctx)}
+
+	for _, name := range critical {
+		check, ok := result.Health.Checks[name]
+		if !ok || check.Status == health.StatusUnhealthy {
+			result.Failed = append(result.Failed, name)
+		}
+	}
+	sort.Strings(result.Failed)
+
+	return result
+}
+
+// LogSummary logs the effective configuration together with the self-check
+// outcome so operators can confirm what the server is about to run with
+func LogSummary(log *zap.Logger, cfg *config.Config, result Result) {
+	fields := []zap.Field{
+		zap.String("environment", cfg.App.Environment),
+		zap.String("database_host", cfg.Database.Host),
+		zap.String("database_name", cfg.Database.Name),
+		zap.String("storage_driver", cfg.Storage.Driver),
+		zap.Strings("cors_origins", middleware.AllowedOrigins(&cfg.Security)),
+		zap.Any("rate_limits_per_minute", middleware.RateLimits()),
+	}
+
+	names := make([]string, 0, len(result.Health.Checks))
+	for name := range result.Health.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		check := result.Health.Checks[name]
+		fields = append(fields, zap.String("check_"+name, string(check.Status)))
+		if check.Error != "" {
+			fields = append(fields, zap.String("check_"+name+"_error", check.Error))
+		}
+	}
+
+	if result.Passed() {
+		log.Info("Startup self-check passed", fields...)
+		return
+	}
+
+	fields = append(fields, zap.Strings("failed_checks", result.Failed))
+	log.Error("Startup self-check failed", fields...)
+}
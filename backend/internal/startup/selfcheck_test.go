@@ -0,0 +1,74 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/pkg/health"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func newStartupChecker(db *gorm.DB, storageErr error) *health.HealthChecker {
+	checker := health.NewHealthChecker()
+	checker.AddChecker("database", health.NewDatabaseChecker(db))
+	checker.AddChecker("storage", health.NewFuncChecker("storage", func(ctx context.Context) error {
+		return storageErr
+	}))
+	return checker
+}
+
+func TestSelfCheck(t *testing.T) {
+	t.Run("passes when dependencies are healthy", func(t *testing.T) {
+		checker := newStartupChecker(openTestDB(t), nil)
+
+		result := SelfCheck(context.Background(), checker, "database", "storage")
+
+		assert.True(t, result.Passed())
+		assert.Empty(t, result.Failed)
+		assert.Equal(t, health.StatusHealthy, result.Health.Checks["database"].Status)
+	})
+
+	t.Run("fails when the database is down", func(t *testing.T) {
+		db := openTestDB(t)
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+
+		result := SelfCheck(context.Background(), newStartupChecker(db, nil), "database", "storage")
+
+		assert.False(t, result.Passed())
+		assert.Equal(t, []string{"database"}, result.Failed)
+		assert.NotEmpty(t, result.Health.Checks["database"].Error)
+	})
+
+	t.Run("non-critical failures are reported but do not fail", func(t *testing.T) {
+		checker := newStartupChecker(openTestDB(t), errors.New("bucket unreachable"))
+
+		result := SelfCheck(context.Background(), checker, "database")
+
+		assert.True(t, result.Passed())
+		assert.Equal(t, health.StatusUnhealthy, result.Health.Checks["storage"].Status)
+	})
+
+	t.Run("a missing critical check counts as failed", func(t *testing.T) {
+		checker := newStartupChecker(openTestDB(t), nil)
+
+		result := SelfCheck(context.Background(), checker, "database", "migrations")
+
+		assert.Equal(t, []string{"migrations"}, result.Failed)
+	})
+}
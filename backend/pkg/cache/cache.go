@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+)
+
+// Flusher is an in-process cache that can drop everything it holds
+type Flusher interface {
+	Flush()
+}
+
+// Registry tracks the application's caches so they can be cleared together,
+// e.g. after the database was changed by hand
+type Registry struct {
+	mu     sync.Mutex
+	caches map[string]Flusher
+}
+
+func NewRegistry() *Registry {
+	return &Registry{caches: make(map[string]Flusher)}
+}
+
+// Register adds a cache under name, replacing any cache already registered
+// under it
+func (r *Registry) Register(name string, cache Flusher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.caches[name] = cache
+}
+
+// FlushAll clears every registered cache and returns their names, sorted
+func (r *Registry) FlushAll() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.caches))
+	for name, cache := range r.caches {
+		cache.Flush()
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Map is a concurrency-safe cache of loaded values. Errors are not cached.
+type Map[K comparable, V any] struct {
+	mu    sync.RWMutex
+	items map[K]V
+}
+
+// NewMap creates an empty cache and registers it under name when registry is
+// not nil, so it is cleared along with the others
+func NewMap[K comparable, V any](registry *Registry, name string) *Map[K, V] {
+	m := &Map[K, V]{items: make(map[K]V)}
+	if registry != nil {
+		registry.Register(name, m)
+	}
+	return m
+}
+
+// GetOrLoad returns the cached value for key, calling load and caching its
+// result on a miss
+func (m *Map[K, V]) GetOrLoad(key K, load func() (V, error)) (V, error) {
+	m.mu.RLock()
+	value, ok := m.items[key]
+	m.mu.RUnlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	m.mu.Lock()
+	m.items[key] = value
+	m.mu.Unlock()
+	return value, nil
+}
+
+// Delete drops one cached value
+func (m *Map[K, V]) Delete(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+}
+
+func (m *Map[K, V]) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = make(map[K]V)
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap_GetOrLoad(t *testing.T) {
+	m := NewMap[uint, string](nil, "posts")
+	loads := 0
+	load := func() (string, error) {
+		loads++
+		return "hello", nil
+	}
+
+	for i := 0; i < 2; i++ {
+		value, err := m.GetOrLoad(1, load)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", value)
+	}
+	assert.Equal(t, 1, loads, "the second read is served from the cache")
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		_, err := m.GetOrLoad(2, func() (string, error) { return "", errors.New("not found") })
+		require.Error(t, err)
+
+		value, err := m.GetOrLoad(2, func() (string, error) { return "found", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "found", value)
+	})
+}
+
+func TestRegistry_FlushAll(t *testing.T) {
+	registry := NewRegistry()
+	posts := NewMap[uint, string](registry, "posts")
+	settings := NewMap[string, string](registry, "settings")
+
+	source := "v1"
+	load := func() (string, error) { return source, nil }
+
+	value, _ := posts.GetOrLoad(1, load)
+	require.Equal(t, "v1", value)
+	_, _ = settings.GetOrLoad("site_name", load)
+
+	source = "v2"
+	value, _ = posts.GetOrLoad(1, load)
+	assert.Equal(t, "v1", value, "cached value is served until the flush")
+
+	flushed := registry.FlushAll()
+	assert.Equal(t, []string{"posts", "settings"}, flushed)
+
+	value, _ = posts.GetOrLoad(1, load)
+	assert.Equal(t, "v2", value, "the value is re-fetched from the source after the flush")
+	value, _ = settings.GetOrLoad("site_name", load)
+	assert.Equal(t, "v2", value)
+}
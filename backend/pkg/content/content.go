@@ -0,0 +1,124 @@
+// Package content derives the fields of a post that are computed from its
+// title and markdown content, so every way of saving a post agrees on them
+package content
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"backend/internal/models"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// ExcerptLength is the most characters a derived excerpt keeps, not
+	// counting the trailing ellipsis
+	ExcerptLength = 160
+	// WordsPerMinute is the reading speed reading times are based on
+	WordsPerMinute = 200
+)
+
+var (
+	// fencedCodePattern matches a fenced code block up to its closing fence,
+	// or to the end of the text if it is never closed
+	fencedCodePattern = regexp.MustCompile("(?ms)^ {0,3}```.*?(?:^ {0,3}```[^\\n]*$|\\z)|^ {0,3}~~~.*?(?:^ {0,3}~~~[^\\n]*$|\\z)")
+	imagePattern      = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	linkPattern       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	htmlTagPattern    = regexp.MustCompile(`<[^>]+>`)
+	// blockMarkerPattern matches headings, quotes and list bullets at the
+	// start of a line
+	blockMarkerPattern = regexp.MustCompile(`(?m)^[ \t]*(?:#{1,6}[ \t]+|>[ \t]?|[-*+][ \t]+|\d+[.)][ \t]+)`)
+	emphasisPattern    = regexp.MustCompile("[*_`~]+")
+)
+
+// Process fills in the derived fields of a new post. The excerpt is only
+// derived when the author has not written one.
+func Process(post *models.Post) {
+	if strings.TrimSpace(post.Excerpt) == "" {
+		post.Excerpt = Excerpt(PlainText(post.Content))
+	}
+	Refresh(post)
+}
+
+// Refresh brings the derived fields of an edited post up to date. The slug
+// is only derived when empty, so a post keeps its address until its title
+// changes and the caller clears it. The excerpt is left alone, since an edit
+// may clear it on purpose.
+func Refresh(post *models.Post) {
+	if post.Slug == "" {
+		post.Slug = Slug(post.Title)
+	}
+
+	post.WordCount = WordCount(PlainText(post.Content))
+	post.ReadingTime = ReadingTime(post.WordCount)
+}
+
+// Slug turns a title into a URL path segment: lowercase ASCII letters and
+// digits separated by single hyphens. Accented letters keep their base
+// letter, so "Café Crème" becomes "cafe-creme"; other characters are
+// dropped.
+func Slug(title string) string {
+	var slug strings.Builder
+	pendingHyphen := false
+	for _, r := range norm.NFKD.String(strings.ToLower(title)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if pendingHyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			pendingHyphen = false
+			slug.WriteRune(r)
+		case unicode.IsSpace(r), r == '-':
+			pendingHyphen = true
+		}
+	}
+	return slug.String()
+}
+
+// PlainText reduces markdown to the prose a reader reads: code blocks and
+// images are dropped, links keep their text, and formatting marks and HTML
+// tags are removed
+func PlainText(markdown string) string {
+	text := fencedCodePattern.ReplaceAllString(markdown, "")
+	text = imagePattern.ReplaceAllString(text, "")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = blockMarkerPattern.ReplaceAllString(text, "")
+	text = emphasisPattern.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Excerpt shortens plain text to at most ExcerptLength characters, cutting
+// at the last word that fits and marking the cut with "...". Characters are
+// counted as runes, so multi-byte letters are never split.
+func Excerpt(text string) string {
+	runes := []rune(text)
+	if len(runes) <= ExcerptLength {
+		return text
+	}
+
+	cut := ExcerptLength
+	for i := cut; i > 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "..."
+}
+
+// WordCount counts the words in plain text
+func WordCount(text string) int {
+	return len(strings.Fields(text))
+}
+
+// ReadingTime is how many minutes words take to read, rounded up. Only
+// empty posts take no time.
+func ReadingTime(words int) int {
+	return int(math.Ceil(float64(words) / WordsPerMinute))
+}
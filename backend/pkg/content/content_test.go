@@ -0,0 +1,109 @@
+package content
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const markdownPost = "# Crème brûlée à la café\n\n" +
+	"Señor José shares his **favourite** dessert, with a [recipe](https://example.com/recipe) " +
+	"and `go run` instructions.\n\n" +
+	"![A golden crust](/uploads/creme.jpg \"Crust\")\n\n" +
+	"```go\nfunc main() { fmt.Println(\"not prose\") }\n```\n\n" +
+	"- Caramelise the sugar\n" +
+	"- Serve straight away\n\n" +
+	"> Naïve cooks burn it.\n"
+
+func TestProcess_MarkdownPost(t *testing.T) {
+	post := &models.Post{Title: "Crème Brûlée: à la Café!", Content: markdownPost}
+
+	Process(post)
+
+	assert.Equal(t, "creme-brulee-a-la-cafe", post.Slug)
+	assert.Equal(t, "Crème brûlée à la café Señor José shares his favourite dessert, with a recipe and go run instructions. "+
+		"Caramelise the sugar Serve straight away Naïve cooks burn...", post.Excerpt)
+	assert.Equal(t, 28, post.WordCount)
+	assert.Equal(t, 1, post.ReadingTime)
+	// The stored content is left as written
+	assert.Equal(t, markdownPost, post.Content)
+}
+
+func TestProcess_KeepsWhatTheAuthorSet(t *testing.T) {
+	post := &models.Post{Title: "New title", Slug: "old-title", Excerpt: "Hand written", Content: "Two words"}
+
+	Process(post)
+
+	assert.Equal(t, "old-title", post.Slug)
+	assert.Equal(t, "Hand written", post.Excerpt)
+	assert.Equal(t, 2, post.WordCount)
+}
+
+func TestRefresh_LeavesTheExcerptAlone(t *testing.T) {
+	post := &models.Post{Title: "New title", Content: "Three more words"}
+
+	Refresh(post)
+
+	assert.Equal(t, "new-title", post.Slug)
+	assert.Empty(t, post.Excerpt)
+	assert.Equal(t, 3, post.WordCount)
+}
+
+func TestSlug(t *testing.T) {
+	cases := map[string]string{
+		"Hello World":           "hello-world",
+		"  Go's  --  tips  ":    "gos-tips",
+		"Ünïcödé Straße":        "unicode-strae",
+		"C++ & Rust in 2024":    "c-rust-in-2024",
+		"日本語":                   "",
+		"Already-a-slug":        "already-a-slug",
+		"Tabs\tand\nnewlines":   "tabs-and-newlines",
+		"FInal ligature résumé": "final-ligature-resume",
+	}
+	for title, want := range cases {
+		assert.Equal(t, want, Slug(title), title)
+	}
+}
+
+func TestExcerpt_CutsLongTextAtAWord(t *testing.T) {
+	text := strings.Repeat("déjà vu ", 40)
+
+	excerpt := Excerpt(PlainText(text))
+
+	assert.True(t, strings.HasSuffix(excerpt, "vu..."), excerpt)
+	assert.LessOrEqual(t, utf8.RuneCountInString(excerpt), ExcerptLength+3)
+	assert.True(t, utf8.ValidString(excerpt))
+}
+
+func TestReadingTime(t *testing.T) {
+	assert.Equal(t, 0, ReadingTime(0))
+	assert.Equal(t, 1, ReadingTime(1))
+	assert.Equal(t, 1, ReadingTime(WordsPerMinute))
+	assert.Equal(t, 2, ReadingTime(WordsPerMinute+1))
+}
+
+func TestPlainText_UnclosedCodeFenceRunsToTheEnd(t *testing.T) {
+	assert.Equal(t, "Intro", PlainText("Intro\n\n~~~\nstill code"))
+}
+
+func TestRenderHTML_Markdown(t *testing.T) {
+	html, err := RenderHTML("Some **bold** text and a [link](https://example.com)")
+	require.NoError(t, err)
+
+	assert.Contains(t, html, "<strong>bold</strong>")
+	assert.Contains(t, html, `<a href="https://example.com" rel="nofollow">link</a>`)
+}
+
+func TestRenderHTML_StripsScripts(t *testing.T) {
+	html, err := RenderHTML("Hi <script>alert(1)</script> [x](javascript:alert(2)) <img src=x onerror=alert(3)>")
+	require.NoError(t, err)
+
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, "javascript:")
+	assert.NotContains(t, html, "onerror")
+}
@@ -0,0 +1,27 @@
+package content
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	// htmlPolicy keeps the formatting markdown produces and drops scripts,
+	// event handlers and javascript: links, including any raw HTML the
+	// author wrote
+	htmlPolicy = bluemonday.UGCPolicy()
+)
+
+// RenderHTML renders markdown written by users to HTML that is safe to put
+// on a page. Posts and comments share it, so both are shown the same way.
+func RenderHTML(source string) (string, error) {
+	var rendered bytes.Buffer
+	if err := markdown.Convert([]byte(source), &rendered); err != nil {
+		return "", err
+	}
+	return string(htmlPolicy.SanitizeBytes(rendered.Bytes())), nil
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Event is anything published on the bus
+type Event interface {
+	// Name identifies the event type subscribers register for
+	Name() string
+}
+
+// Handler reacts to a published event
+type Handler func(ctx context.Context, event Event)
+
+// Bus is a simple in-process publish/subscribe event bus.
+// Handlers run synchronously in the publisher's goroutine, in the order
+// they subscribed, so a publish returns once every handler has finished.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Subscribe registers a handler for the named event
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish delivers an event to every handler subscribed to its name.
+// Publishing on a nil bus is a no-op.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, len(b.handlers[event.Name()]))
+	copy(handlers, b.handlers[event.Name()])
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+}
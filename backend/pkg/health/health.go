@@ -45,6 +45,16 @@ type HealthResponse struct {
 	System    map[string]interface{} `json:"system"`
 }
 
+// SummaryResponse is the health payload for callers who may not see system
+// details
+type SummaryResponse struct {
+	Status Status `json:"status"`
+}
+
+// DetailPolicy reports whether a request may see system information and
+// per-check details
+type DetailPolicy func(c *gin.Context) bool
+
 // Checker interface for health checks
 type Checker interface {
 	Check(ctx context.Context) CheckResult
@@ -53,9 +63,11 @@ type Checker interface {
 
 // HealthChecker manages health checks
 type HealthChecker struct {
-	checkers  map[string]Checker
-	mu        sync.RWMutex
-	startTime time.Time
+	checkers     map[string]Checker
+	mu           sync.RWMutex
+	startTime    time.Time
+	detailPolicy DetailPolicy
+	readiness    *Readiness
 }
 
 // NewHealthChecker creates a new health checker
@@ -73,6 +85,38 @@ func (h *HealthChecker) AddChecker(name string, checker Checker) {
 	h.checkers[name] = checker
 }
 
+// SetDetailPolicy limits who sees the full health payload. Requests the
+// policy rejects get only the overall status. Without a policy every request
+// gets the full payload.
+func (h *HealthChecker) SetDetailPolicy(policy DetailPolicy) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.detailPolicy = policy
+}
+
+// SetReadiness gates the readiness probe: until readiness is marked ready it
+// answers 503 without running the checks. Without one the probe depends on
+// the checks alone.
+func (h *HealthChecker) SetReadiness(readiness *Readiness) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = readiness
+}
+
+// respond writes the full health response, or just its status when the
+// detail policy rejects the request
+func (h *HealthChecker) respond(c *gin.Context, code int, response HealthResponse) {
+	h.mu.RLock()
+	policy := h.detailPolicy
+	h.mu.RUnlock()
+
+	if policy != nil && !policy(c) {
+		c.JSON(code, SummaryResponse{Status: response.Status})
+		return
+	}
+	c.JSON(code, response)
+}
+
 // RemoveChecker removes a health checker
 func (h *HealthChecker) RemoveChecker(name string) {
 	h.mu.Lock()
@@ -135,13 +179,35 @@ func (h *HealthChecker) LivenessHandler(c *gin.Context) {
 		zap.Duration("uptime", response.Uptime),
 	)
 
-	c.JSON(http.StatusOK, response)
+	h.respond(c, http.StatusOK, response)
 }
 
 // ReadinessHandler handles readiness probe (Kubernetes)
 func (h *HealthChecker) ReadinessHandler(c *gin.Context) {
 	ctx := c.Request.Context()
 
+	h.mu.RLock()
+	readiness := h.readiness
+	h.mu.RUnlock()
+	if readiness != nil && !readiness.Ready() {
+		h.respond(c, http.StatusServiceUnavailable, HealthResponse{
+			Status:    StatusUnhealthy,
+			Timestamp: time.Now(),
+			Service:   "blogcms-api",
+			Version:   "1.0.0",
+			Uptime:    time.Since(h.startTime),
+			Checks: map[string]CheckResult{
+				"readiness": {
+					Status:    StatusUnhealthy,
+					Timestamp: time.Now(),
+					Error:     "server is starting up or shutting down",
+				},
+			},
+			System: getSystemInfo(),
+		})
+		return
+	}
+
 	// Full readiness check - verify all dependencies
 	health := h.CheckHealth(ctx)
 
@@ -153,11 +219,11 @@ func (h *HealthChecker) ReadinessHandler(c *gin.Context) {
 
 	// Return 503 if unhealthy for load balancer
 	if health.Status == StatusUnhealthy {
-		c.JSON(http.StatusServiceUnavailable, health)
+		h.respond(c, http.StatusServiceUnavailable, health)
 		return
 	}
 
-	c.JSON(http.StatusOK, health)
+	h.respond(c, http.StatusOK, health)
 }
 
 // HealthHandler handles general health endpoint
@@ -174,7 +240,7 @@ func (h *HealthChecker) HealthHandler(c *gin.Context) {
 	}
 
 	// Always return 200 for general health endpoint
-	c.JSON(http.StatusOK, health)
+	h.respond(c, http.StatusOK, health)
 }
 
 // getSystemInfo returns system information
@@ -318,3 +384,40 @@ func (m *MemoryChecker) Check(ctx context.Context) CheckResult {
 func (m *MemoryChecker) Name() string {
 	return "memory"
 }
+
+// FuncChecker adapts a plain function into a Checker. The check is healthy
+// when the function returns nil and unhealthy otherwise.
+type FuncChecker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// NewFuncChecker creates a checker that runs check
+func NewFuncChecker(name string, check func(ctx context.Context) error) *FuncChecker {
+	return &FuncChecker{name: name, check: check}
+}
+
+// Check runs the wrapped function
+func (f *FuncChecker) Check(ctx context.Context) CheckResult {
+	start := time.Now()
+
+	if err := f.check(ctx); err != nil {
+		return CheckResult{
+			Status:    StatusUnhealthy,
+			Timestamp: time.Now(),
+			Duration:  time.Since(start),
+			Error:     err.Error(),
+		}
+	}
+
+	return CheckResult{
+		Status:    StatusHealthy,
+		Timestamp: time.Now(),
+		Duration:  time.Since(start),
+	}
+}
+
+// Name returns the checker name
+func (f *FuncChecker) Name() string {
+	return f.name
+}
@@ -0,0 +1,31 @@
+package health
+
+import "sync/atomic"
+
+// Readiness records whether the server should be sent traffic. It starts not
+// ready; the server marks it ready once startup has finished and not ready
+// again as soon as shutdown begins, so load balancers stop routing to it
+// before in-flight requests are drained.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+// NewReadiness creates a readiness state that is not ready
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// MarkReady reports the server as ready for traffic
+func (r *Readiness) MarkReady() {
+	r.ready.Store(true)
+}
+
+// MarkNotReady reports the server as starting up or shutting down
+func (r *Readiness) MarkNotReady() {
+	r.ready.Store(false)
+}
+
+// Ready reports whether the server should be sent traffic
+func (r *Readiness) Ready() bool {
+	return r.ready.Load()
+}
@@ -0,0 +1,133 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"backend/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Worker is a background component with explicit start and stop steps.
+// Stop should return once the worker has finished, or when ctx expires.
+type Worker interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Hooks adapts a pair of functions to Worker. Either may be nil.
+type Hooks struct {
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+func (h Hooks) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+func (h Hooks) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+type entry struct {
+	name   string
+	worker Worker
+}
+
+// Manager starts registered workers in order and stops them in reverse, so a
+// worker can rely on anything registered before it while it shuts down
+type Manager struct {
+	mu          sync.Mutex
+	workers     []entry
+	started     []entry
+	stopTimeout time.Duration
+}
+
+// NewManager creates a manager whose Stop gives up on workers still running
+// after stopTimeout. A timeout of zero leaves Stop bounded only by its ctx.
+func NewManager(stopTimeout time.Duration) *Manager {
+	return &Manager{stopTimeout: stopTimeout}
+}
+
+// Register adds a worker to start after those already registered
+func (m *Manager) Register(name string, worker Worker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers = append(m.workers, entry{name: name, worker: worker})
+}
+
+// Start starts every registered worker in order. If one fails, the workers
+// already started are stopped again and the error is returned. ctx is handed
+// to each worker and should live as long as the workers are meant to run.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	workers := m.workers
+	m.mu.Unlock()
+
+	for _, w := range workers {
+		if err := w.worker.Start(ctx); err != nil {
+			stopErr := m.Stop(context.Background())
+			return errors.Join(fmt.Errorf("start %s: %w", w.name, err), stopErr)
+		}
+
+		m.mu.Lock()
+		m.started = append(m.started, w)
+		m.mu.Unlock()
+		logger.LogInfo(ctx, "Background worker started", zap.String("worker", w.name))
+	}
+	return nil
+}
+
+// Stop stops the started workers in reverse order. Once the stop timeout
+// passes, a worker that has not returned is abandoned and reported; any
+// workers left are still asked to stop but are not waited for.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	if m.stopTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.stopTimeout)
+		defer cancel()
+	}
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		w := started[i]
+		if err := stopWorker(ctx, w.worker); err != nil {
+			logger.LogError(ctx, "Background worker did not stop cleanly", err, zap.String("worker", w.name))
+			errs = append(errs, fmt.Errorf("stop %s: %w", w.name, err))
+			continue
+		}
+		logger.LogInfo(ctx, "Background worker stopped", zap.String("worker", w.name))
+	}
+	return errors.Join(errs...)
+}
+
+// stopWorker runs a worker's Stop, returning early if ctx expires first so a
+// worker that ignores its context cannot hold up shutdown
+func stopWorker(ctx context.Context, worker Worker) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,97 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects start and stop calls across workers in the order they
+// happen
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) worker(name string) Worker {
+	return Hooks{
+		OnStart: func(ctx context.Context) error {
+			r.record("start " + name)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			r.record("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestManager_StartsInOrderAndStopsInReverse(t *testing.T) {
+	calls := &recorder{}
+	m := NewManager(time.Second)
+	m.Register("scheduler", calls.worker("scheduler"))
+	m.Register("metrics", calls.worker("metrics"))
+	m.Register("notifications", calls.worker("notifications"))
+
+	require.NoError(t, m.Start(context.Background()))
+	require.NoError(t, m.Stop(context.Background()))
+
+	assert.Equal(t, []string{
+		"start scheduler", "start metrics", "start notifications",
+		"stop notifications", "stop metrics", "stop scheduler",
+	}, calls.calls)
+
+	// Stopping again does nothing
+	require.NoError(t, m.Stop(context.Background()))
+	assert.Len(t, calls.calls, 6)
+}
+
+func TestManager_FailedStartStopsStartedWorkers(t *testing.T) {
+	calls := &recorder{}
+	m := NewManager(time.Second)
+	m.Register("first", calls.worker("first"))
+	m.Register("broken", Hooks{OnStart: func(ctx context.Context) error {
+		return errors.New("port in use")
+	}})
+	m.Register("never", calls.worker("never"))
+
+	err := m.Start(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start broken: port in use")
+	assert.Equal(t, []string{"start first", "stop first"}, calls.calls)
+}
+
+func TestManager_SlowStopperIsBoundedByTimeout(t *testing.T) {
+	calls := &recorder{}
+	release := make(chan struct{})
+	defer close(release)
+
+	m := NewManager(50 * time.Millisecond)
+	m.Register("stuck", Hooks{OnStop: func(ctx context.Context) error {
+		// Ignores ctx, as a misbehaving worker might
+		<-release
+		return nil
+	}})
+	m.Register("last", calls.worker("last"))
+	require.NoError(t, m.Start(context.Background()))
+
+	start := time.Now()
+	err := m.Stop(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "stop stuck")
+	assert.Equal(t, []string{"start last", "stop last"}, calls.calls)
+}
@@ -107,6 +107,57 @@ var (
 		},
 	)
 
+	// Upload metrics
+	uploadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blogcms_uploads_total",
+			Help: "Total number of file uploads",
+		},
+		[]string{"driver", "result"},
+	)
+
+	uploadBytesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blogcms_upload_bytes_total",
+			Help: "Total number of bytes successfully uploaded",
+		},
+		[]string{"driver"},
+	)
+
+	uploadDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "blogcms_upload_duration_seconds",
+			Help:    "File upload duration in seconds",
+			Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"driver", "result"},
+	)
+
+	// Email metrics
+	emailSendTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blogcms_email_send_total",
+			Help: "Total number of email send attempts by result",
+		},
+		[]string{"result"},
+	)
+
+	// Webhook metrics
+	webhookQueueDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "blogcms_webhook_queue_depth",
+			Help: "Number of webhook deliveries waiting for a worker",
+		},
+	)
+
+	webhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blogcms_webhook_deliveries_total",
+			Help: "Total number of webhook deliveries by result",
+		},
+		[]string{"result"},
+	)
+
 	// System metrics
 	systemInfo = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -164,6 +215,64 @@ func RecordAuthAttempt(authType, status string) {
 	}).Inc()
 }
 
+// Upload results recorded by RecordUpload
+const (
+	UploadSuccess         = "success"
+	UploadValidationError = "validation_error"
+	UploadTooLarge        = "too_large"
+	UploadStorageError    = "storage_error"
+)
+
+// RecordUpload records an upload attempt. Bytes are only counted for
+// successful uploads.
+func RecordUpload(driver, result string, bytes int64, duration time.Duration) {
+	labels := prometheus.Labels{
+		"driver": driver,
+		"result": result,
+	}
+
+	uploadsTotal.With(labels).Inc()
+	uploadDuration.With(labels).Observe(duration.Seconds())
+	if result == UploadSuccess {
+		uploadBytesTotal.WithLabelValues(driver).Add(float64(bytes))
+	}
+}
+
+// Email results recorded by RecordEmailSend
+const (
+	EmailSent    = "sent"
+	EmailRetried = "retried"
+	EmailFailed  = "failed"
+	EmailDropped = "dropped"
+)
+
+// RecordEmailSend records the outcome of an email send attempt. A failed
+// attempt that will be tried again counts as retried; failed means the email
+// was given up on.
+func RecordEmailSend(result string) {
+	emailSendTotal.WithLabelValues(result).Inc()
+}
+
+// Webhook results recorded by RecordWebhookDelivery
+const (
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed"
+	WebhookDropped   = "dropped"
+	WebhookSuspended = "suspended"
+)
+
+// RecordWebhookDelivery records the outcome of a webhook delivery. Dropped
+// deliveries found the queue full; suspended ones were skipped because their
+// endpoint kept failing.
+func RecordWebhookDelivery(result string) {
+	webhookDeliveriesTotal.WithLabelValues(result).Inc()
+}
+
+// SetWebhookQueueDepth records how many webhook deliveries are queued
+func SetWebhookQueueDepth(depth int) {
+	webhookQueueDepth.Set(float64(depth))
+}
+
 // UpdateActiveUsers updates active users count
 func UpdateActiveUsers(count int) {
 	activeUsers.Set(float64(count))
@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+)
+
+// InterruptedError reports that a job stopped early because its context was
+// cancelled, and how far through its work it got
+type InterruptedError struct {
+	// Done is how many items the job finished before stopping, out of Total
+	Done  int
+	Total int
+	Err   error
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted after %d of %d items: %v", e.Done, e.Total, e.Err)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// Checkpoint lets a job working through total items stop between them. It
+// returns an *InterruptedError recording that done items were finished if ctx
+// has been cancelled or has expired, and nil otherwise.
+func Checkpoint(ctx context.Context, done, total int) error {
+	if err := ctx.Err(); err != nil {
+		return &InterruptedError{Done: done, Total: total, Err: err}
+	}
+	return nil
+}
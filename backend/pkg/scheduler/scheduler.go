@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"backend/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// JobFunc is the work a scheduled job performs on each run
+type JobFunc func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler runs registered jobs at fixed intervals in background goroutines
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []job
+	startup []job
+	timeout time.Duration
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+// NewScheduler creates a scheduler with no jobs. Each job run gets a context
+// that expires after timeout, so a hung query cannot stall a job forever;
+// a timeout of zero leaves runs unbounded.
+func NewScheduler(timeout time.Duration) *Scheduler {
+	return &Scheduler{timeout: timeout}
+}
+
+// Every registers a job to run once per interval after the scheduler starts.
+// Jobs with a non-positive interval are ignored, which lets callers disable a
+// job through configuration.
+func (s *Scheduler) Every(name string, interval time.Duration, run JobFunc) {
+	if interval <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// AtStart registers a job to run once as soon as the scheduler starts. Like
+// periodic jobs, it is cancelled and waited for by Stop.
+func (s *Scheduler) AtStart(name string, run JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startup = append(s.startup, job{name: name, run: run})
+}
+
+// Start launches every registered job. It is a no-op if already started.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, j := range s.startup {
+		s.wg.Add(1)
+		go func(j job) {
+			defer s.wg.Done()
+			s.RunOnce(ctx, j.name, j.run)
+		}(j)
+	}
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop cancels all running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx, j.name, j.run)
+		}
+	}
+}
+
+// RunOnce executes a job immediately within the scheduler's timeout,
+// logging its outcome and duration. It returns the job's error. A job that
+// stops early with an *InterruptedError is logged with how far it got.
+func (s *Scheduler) RunOnce(ctx context.Context, name string, run JobFunc) error {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	if err := run(ctx); err != nil {
+		var interrupted *InterruptedError
+		if errors.As(err, &interrupted) {
+			logger.LogWarn(ctx, "Scheduled job interrupted",
+				zap.String("job", name),
+				zap.Int("done", interrupted.Done),
+				zap.Int("total", interrupted.Total),
+				zap.Duration("duration", time.Since(start)),
+				zap.Error(interrupted.Err),
+			)
+			return err
+		}
+		logger.LogError(ctx, "Scheduled job failed", err,
+			zap.String("job", name),
+			zap.Duration("duration", time.Since(start)),
+		)
+		return err
+	}
+
+	logger.LogDebug(ctx, "Scheduled job completed",
+		zap.String("job", name),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
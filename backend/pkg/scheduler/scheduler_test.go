@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestScheduler_RunOnceCancelsSlowQuery(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	s := NewScheduler(100 * time.Millisecond)
+
+	start := time.Now()
+	err = s.RunOnce(context.Background(), "slow-query", func(ctx context.Context) error {
+		// Counts an endless sequence; only cancellation can stop it
+		var count int64
+		return db.WithContext(ctx).
+			Raw("WITH RECURSIVE seq(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM seq) SELECT COUNT(*) FROM seq").
+			Scan(&count).Error
+	})
+
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second, "query should be interrupted by the job timeout")
+}
+
+func TestScheduler_RunOnceWithoutTimeout(t *testing.T) {
+	s := NewScheduler(0)
+
+	err := s.RunOnce(context.Background(), "no-deadline", func(ctx context.Context) error {
+		_, hasDeadline := ctx.Deadline()
+		assert.False(t, hasDeadline)
+		return nil
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestScheduler_EveryRunsUntilStopped(t *testing.T) {
+	s := NewScheduler(time.Second)
+
+	var runs int32
+	s.Every("tick", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	s.Every("disabled", 0, func(ctx context.Context) error {
+		t.Error("a job with a zero interval should never run")
+		return nil
+	})
+
+	s.Start(context.Background())
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 2 }, time.Second, 5*time.Millisecond)
+	s.Stop()
+
+	stopped := atomic.LoadInt32(&runs)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&runs), "no runs after Stop")
+}
+
+func TestScheduler_AtStartRunsOnceAndIsCancelledByStop(t *testing.T) {
+	s := NewScheduler(0)
+
+	started := make(chan struct{})
+	var runs int32
+	var stopped error
+	s.AtStart("backfill", func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		close(started)
+		<-ctx.Done()
+		stopped = ctx.Err()
+		return Checkpoint(ctx, 3, 10)
+	})
+
+	s.Start(context.Background())
+	<-started
+	s.Stop()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+	assert.ErrorIs(t, stopped, context.Canceled, "Stop should cancel and wait for startup jobs")
+}
+
+func TestCheckpoint(t *testing.T) {
+	assert.NoError(t, Checkpoint(context.Background(), 3, 10))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Checkpoint(ctx, 3, 10)
+
+	var interrupted *InterruptedError
+	require.True(t, errors.As(err, &interrupted))
+	assert.Equal(t, 3, interrupted.Done)
+	assert.Equal(t, 10, interrupted.Total)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "interrupted after 3 of 10 items: context canceled", err.Error())
+}
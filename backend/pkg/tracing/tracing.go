@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName names the tracers this package hands out
+const instrumentationName = "backend/pkg/tracing"
+
+// requestIDKey is the span attribute carrying the request's X-Request-ID, so
+// a trace can be found from a log line and the other way round
+const requestIDKey = attribute.Key("request.id")
+
+// Provider is the tracer provider for the server's spans, along with what
+// it takes to flush them on shutdown
+type Provider struct {
+	trace.TracerProvider
+	shutdown func(context.Context) error
+}
+
+// NewProvider exports spans over OTLP/HTTP when cfg.Enabled is set. The
+// exporter reads its endpoint, headers and TLS settings from the standard
+// OTEL_EXPORTER_OTLP_* variables. Otherwise the provider is a no-op, and
+// requests and queries cost no more than a function call.
+func NewProvider(ctx context.Context, cfg *config.TracingConfig) (*Provider, error) {
+	if !cfg.Enabled {
+		return &Provider{
+			TracerProvider: noop.NewTracerProvider(),
+			shutdown:       func(context.Context) error { return nil },
+		}, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	return &Provider{TracerProvider: provider, shutdown: provider.Shutdown}, nil
+}
+
+// Shutdown exports the spans still buffered and stops the exporter
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.shutdown(ctx)
+}
+
+// Middleware starts a server span for each request, continuing the trace
+// of an incoming traceparent header, and puts it in the request context so
+// queries run with that context become its children. The span is named
+// after the matched route rather than the path, to keep names few.
+func Middleware(provider trace.TracerProvider) gin.HandlerFunc {
+	tracer := provider.Tracer(instrumentationName)
+	propagator := propagation.TraceContext{}
+
+	return func(c *gin.Context) {
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				requestIDKey.String(c.GetString("request_id")),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		for _, ginErr := range c.Errors {
+			span.RecordError(ginErr.Err)
+		}
+	}
+}
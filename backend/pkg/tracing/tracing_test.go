@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+// newTracedRouter serves GET /widgets/:id from a traced in-memory database,
+// recording every finished span
+func newTracedRouter(t *testing.T) (*gin.Engine, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&widget{}))
+	require.NoError(t, db.Create(&widget{ID: 1, Name: "sprocket"}).Error)
+	require.NoError(t, db.Use(NewGormPlugin(provider)))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("request_id", c.GetHeader("X-Request-ID"))
+	})
+	router.Use(Middleware(provider))
+	router.GET("/widgets/:id", func(c *gin.Context) {
+		var found widget
+		if err := db.WithContext(c.Request.Context()).First(&found, c.Param("id")).Error; err != nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.JSON(http.StatusOK, found)
+	})
+	return router, recorder
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware_NestsQuerySpansUnderRequestSpan(t *testing.T) {
+	router, recorder := newTracedRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets/1", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	query, request := spans[0], spans[1]
+
+	assert.Equal(t, "GET /widgets/:id", request.Name())
+	assert.Equal(t, trace.SpanKindServer, request.SpanKind())
+	assert.False(t, request.Parent().IsValid())
+	assert.Equal(t, "req-123", spanAttribute(request, requestIDKey).AsString())
+	assert.Equal(t, int64(http.StatusOK), spanAttribute(request, "http.response.status_code").AsInt64())
+
+	assert.Equal(t, "query widgets", query.Name())
+	assert.Equal(t, trace.SpanKindClient, query.SpanKind())
+	assert.Equal(t, request.SpanContext().TraceID(), query.SpanContext().TraceID())
+	assert.Equal(t, request.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Equal(t, "sqlite", spanAttribute(query, "db.system").AsString())
+	assert.Equal(t, "widgets", spanAttribute(query, "db.sql.table").AsString())
+	assert.Contains(t, spanAttribute(query, "db.statement").AsString(), "SELECT")
+}
+
+func TestMiddleware_ContinuesIncomingTrace(t *testing.T) {
+	router, recorder := newTracedRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	request := spans[1]
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", request.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", request.Parent().SpanID().String())
+}
+
+func TestGormPlugin_MissingRowsAreNotErrors(t *testing.T) {
+	router, recorder := newTracedRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/widgets/99", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Empty(t, spans[0].Events())
+	assert.Equal(t, int64(http.StatusNotFound), spanAttribute(spans[1], "http.response.status_code").AsInt64())
+}
+
+func TestNewProvider_NoopWithoutExporter(t *testing.T) {
+	provider, err := NewProvider(context.Background(), &config.TracingConfig{ServiceName: "test"})
+	require.NoError(t, err)
+
+	_, span := provider.Tracer("test").Start(context.Background(), "work")
+	assert.False(t, span.IsRecording())
+	span.End()
+	assert.NoError(t, provider.Shutdown(context.Background()))
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldsStrictKey is true when ?fields= may only name fields the endpoint
+// exposes. Otherwise other names are ignored.
+const FieldsStrictKey = "fields_strict"
+
+// FieldsFull is the ?fields= value asking list endpoints for whole records
+// instead of their summaries
+const FieldsFull = "full"
+
+// ErrUnknownField is returned in strict mode when ?fields= names a field the
+// endpoint does not expose
+var ErrUnknownField = errors.New("unknown field")
+
+// fieldTree maps field names to the fields picked inside them. A nil subtree
+// stands for the whole value.
+type fieldTree map[string]fieldTree
+
+// SelectFields projects data, one record or a list of them, to the fields
+// named by the request's comma-separated ?fields= parameter. Fields of a
+// relation are written as author.name, and naming the relation alone picks
+// all of its fields in allowed. Only the fields in allowed can be picked;
+// others are ignored, or rejected with ErrUnknownField when
+// middleware.FieldSelection is strict. Without ?fields=, or with
+// ?fields=full, data is returned unchanged.
+func SelectFields(c *gin.Context, data interface{}, allowed []string) (interface{}, error) {
+	raw := c.Query("fields")
+	if raw == "" || raw == FieldsFull {
+		return data, nil
+	}
+
+	exposed := newFieldTree(allowed)
+	selected := fieldTree{}
+	var unknown []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		path := strings.Split(name, ".")
+		if !exposed.allows(path) {
+			unknown = append(unknown, name)
+			continue
+		}
+		selected.add(path, exposed)
+	}
+	if len(unknown) > 0 && c.GetBool(FieldsStrictKey) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownField, strings.Join(unknown, ", "))
+	}
+
+	// Work on the JSON form, so the names match what clients see
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return selected.project(decoded), nil
+}
+
+func newFieldTree(names []string) fieldTree {
+	tree := fieldTree{}
+	for _, name := range names {
+		node := tree
+		parts := strings.Split(name, ".")
+		for i, part := range parts {
+			if i == len(parts)-1 {
+				if _, ok := node[part]; !ok {
+					node[part] = nil
+				}
+				break
+			}
+			if node[part] == nil {
+				node[part] = fieldTree{}
+			}
+			node = node[part]
+		}
+	}
+	return tree
+}
+
+// allows reports whether path names an exposed field or a field inside one
+func (t fieldTree) allows(path []string) bool {
+	node := t
+	for _, part := range path {
+		child, ok := node[part]
+		if !ok {
+			return false
+		}
+		if child == nil {
+			return true
+		}
+		node = child
+	}
+	return true
+}
+
+// subtree returns what path picks in the exposed tree t: nil for a whole
+// value, or the exposed fields of a relation
+func (t fieldTree) subtree(path []string) fieldTree {
+	node := t
+	for _, part := range path {
+		node = node[part]
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// add picks path, along with what it stands for in exposed
+func (t fieldTree) add(path []string, exposed fieldTree) {
+	node := t
+	for i, part := range path[:len(path)-1] {
+		child, ok := node[part]
+		if ok && child == nil {
+			return
+		}
+		if !ok {
+			child = fieldTree{}
+			node[part] = child
+		}
+		node = child
+		exposed = exposed.subtree(path[i : i+1])
+	}
+
+	last := path[len(path)-1]
+	picked := exposed.subtree([]string{last})
+	existing, ok := node[last]
+	switch {
+	case !ok:
+		node[last] = picked.copy()
+	case existing == nil:
+	case picked == nil:
+		node[last] = nil
+	default:
+		for name := range picked {
+			existing.add([]string{name}, picked)
+		}
+	}
+}
+
+func (t fieldTree) copy() fieldTree {
+	if t == nil {
+		return nil
+	}
+	copied := make(fieldTree, len(t))
+	for name, child := range t {
+		copied[name] = child.copy()
+	}
+	return copied
+}
+
+// project keeps the picked fields of a decoded JSON object, or of each object
+// in a list. Missing fields stay missing.
+func (t fieldTree) project(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		projected := make(map[string]interface{}, len(t))
+		for name, child := range t {
+			field, ok := v[name]
+			if !ok {
+				continue
+			}
+			if child == nil {
+				projected[name] = field
+			} else {
+				projected[name] = child.project(field)
+			}
+		}
+		return projected
+	case []interface{}:
+		projected := make([]interface{}, len(v))
+		for i, item := range v {
+			projected[i] = t.project(item)
+		}
+		return projected
+	default:
+		return v
+	}
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"math"
-	"regexp"
 	"strconv"
 	"strings"
 
@@ -11,25 +10,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func GenerateSlug(title string) string {
-	// Convert to lowercase
-	slug := strings.ToLower(title)
-	
-	// Replace spaces with hyphens
-	slug = strings.ReplaceAll(slug, " ", "-")
-	
-	// Remove special characters except hyphens
-	reg := regexp.MustCompile(`[^a-z0-9\-]`)
-	slug = reg.ReplaceAllString(slug, "")
-	
-	// Remove multiple consecutive hyphens
-	reg = regexp.MustCompile(`-+`)
-	slug = reg.ReplaceAllString(slug, "-")
-	
-	// Trim hyphens from start and end
-	slug = strings.Trim(slug, "-")
-	
-	return slug
+// NormalizeSlug prepares a slug from a request for lookup. Slugs are
+// generated lowercase, so lookups ignore case.
+func NormalizeSlug(slug string) string {
+	return strings.ToLower(strings.TrimSpace(slug))
 }
 
 func SuccessResponse(message string, data interface{}) models.APIResponse {
@@ -102,3 +86,51 @@ func PaginatedAPIResponse(data interface{}, total int64, page, limit int, messag
 		},
 	}
 }
+
+// Pagination response shapes. The meta shape returns the page of items in
+// data and the counts in meta. The legacy shape nests both inside data, as
+// list endpoints originally did.
+const (
+	PaginationShapeMeta   = "meta"
+	PaginationShapeLegacy = "legacy"
+
+	// PaginationShapeHeader lets a client pick the shape for one request
+	PaginationShapeHeader = "X-API-Pagination"
+	// PaginationShapeKey is the context key holding the configured default
+	PaginationShapeKey = "pagination_shape"
+)
+
+// PaginationShape returns the shape a request asked for in its
+// X-API-Pagination header, falling back to the configured default and then
+// to the meta shape
+func PaginationShape(c *gin.Context) string {
+	for _, shape := range []string{strings.ToLower(c.GetHeader(PaginationShapeHeader)), c.GetString(PaginationShapeKey)} {
+		if shape == PaginationShapeMeta || shape == PaginationShapeLegacy {
+			return shape
+		}
+	}
+	return PaginationShapeMeta
+}
+
+// NegotiatePagination converts a paginated response to the shape the request
+// asked for. The shape used is echoed in the X-API-Pagination header.
+func NegotiatePagination(c *gin.Context, response models.PaginatedAPIResponse) interface{} {
+	shape := PaginationShape(c)
+	c.Header(PaginationShapeHeader, shape)
+	if shape == PaginationShapeMeta {
+		return response
+	}
+
+	return models.APIResponse{
+		Success: response.Success,
+		Message: response.Message,
+		Data: models.PaginationResponse{
+			Data:       response.Data,
+			Total:      response.Meta.Total,
+			Page:       response.Meta.Page,
+			PerPage:    response.Meta.Limit,
+			TotalPages: response.Meta.TotalPages,
+			SearchMode: response.Meta.SearchMode,
+		},
+	}
+}
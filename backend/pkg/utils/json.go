@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Context keys holding the configured JSON decoding limits
+const (
+	// JSONStrictKey is true when request bodies may not carry fields the
+	// target struct does not declare
+	JSONStrictKey = "json_strict"
+	// JSONMaxDepthKey is the deepest nesting of objects and arrays a request
+	// body may have. Zero or less means unlimited.
+	JSONMaxDepthKey = "json_max_depth"
+)
+
+// ErrJSONTooDeep is returned when a request body nests objects and arrays
+// deeper than the configured limit
+var ErrJSONTooDeep = errors.New("json body is nested too deeply")
+
+// BindJSON decodes the request body into obj like ShouldBindJSON, enforcing
+// the limits set by middleware.JSONDecoding: the maximum nesting depth and,
+// in strict mode, rejecting unknown fields. The binding tags on obj are
+// validated as usual.
+func BindJSON(c *gin.Context, obj interface{}) error {
+	if c.Request == nil || c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	if maxDepth := c.GetInt(JSONMaxDepthKey); maxDepth > 0 {
+		if err := checkJSONDepth(body, maxDepth); err != nil {
+			return err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if c.GetBool(JSONStrictKey) {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// checkJSONDepth walks body's tokens without decoding them and fails as soon
+// as the nesting goes past maxDepth. Malformed JSON is left to the decoder.
+func checkJSONDepth(body []byte, maxDepth int) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("%w: more than %d levels", ErrJSONTooDeep, maxDepth)
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
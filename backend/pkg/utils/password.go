@@ -1,15 +1,44 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	return HashPasswordWithPepper(password, "")
 }
 
 func VerifyPassword(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	return VerifyPasswordWithPepper(password, hash, "")
+}
+
+// HashPasswordWithPepper hashes a password mixed with a server-side pepper.
+// An empty pepper hashes the password as is.
+func HashPasswordWithPepper(password, pepper string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword(pepperPassword(password, pepper), bcrypt.DefaultCost)
+	return string(bytes), err
+}
+
+// VerifyPasswordWithPepper reports whether hash was made from password with
+// the given pepper
+func VerifyPasswordWithPepper(password, hash, pepper string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), pepperPassword(password, pepper))
 	return err == nil
 }
+
+// pepperPassword keys an HMAC of the password with the pepper. Hashing
+// rather than appending keeps the input well under bcrypt's 72 byte limit,
+// so a long password can never push the pepper out of what bcrypt reads.
+func pepperPassword(password, pepper string) []byte {
+	if pepper == "" {
+		return []byte(password)
+	}
+
+	mac := hmac.New(sha256.New, []byte(pepper))
+	mac.Write([]byte(password))
+	return []byte(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
@@ -1,10 +1,12 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/services"
 
@@ -91,6 +93,11 @@ func (m *MockRefreshTokenRepository) RevokeAllUserTokens(userID uint) error {
 	return args.Error(0)
 }
 
+func (m *MockRefreshTokenRepository) GetWithLiveAccessTokens(userID uint, now time.Time) ([]*models.RefreshToken, error) {
+	args := m.Called(userID, now)
+	return args.Get(0).([]*models.RefreshToken), args.Error(1)
+}
+
 func (m *MockRefreshTokenRepository) DeleteExpiredTokens() error {
 	args := m.Called()
 	return args.Error(0)
@@ -153,6 +160,16 @@ func (m *MockJWTService) RevokeAllUserTokens(userID uint) error {
 	return args.Error(0)
 }
 
+func (m *MockJWTService) RevokeAccessToken(claims *models.JWTClaims) error {
+	args := m.Called(claims)
+	return args.Error(0)
+}
+
+func (m *MockJWTService) PurgeRevokedAccessTokens(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *MockJWTService) HashPassword(password string) (string, error) {
 	args := m.Called(password)
 	return args.String(0), args.Error(1)
@@ -163,12 +180,17 @@ func (m *MockJWTService) CheckPassword(password, hash string) bool {
 	return args.Bool(0)
 }
 
+func (m *MockJWTService) VerifyPassword(password, hash string) (bool, bool) {
+	args := m.Called(password, hash)
+	return args.Bool(0), args.Bool(1)
+}
+
 // Test cases for AuthService
 func TestAuthService_Login_Success(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockJWTService := new(MockJWTService)
 	
-	authService := services.NewAuthService(mockUserRepo, mockJWTService, nil)
+	authService := services.NewAuthService(mockUserRepo, nil, mockJWTService, nil)
 
 	user := &models.User{
 		ID:       1,
@@ -192,7 +214,7 @@ func TestAuthService_Login_Success(t *testing.T) {
 	}
 
 	mockUserRepo.On("GetByEmail", "test@example.com").Return(user, nil)
-	mockJWTService.On("CheckPassword", "password123", "hashedpassword").Return(true)
+	mockJWTService.On("VerifyPassword", "password123", "hashedpassword").Return(true, false)
 	mockJWTService.On("GenerateTokenPair", user).Return(authResponse, nil)
 
 	result, err := authService.Login(loginReq)
@@ -209,7 +231,7 @@ func TestAuthService_Login_InvalidPassword(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockJWTService := new(MockJWTService)
 	
-	authService := services.NewAuthService(mockUserRepo, mockJWTService, nil)
+	authService := services.NewAuthService(mockUserRepo, nil, mockJWTService, nil)
 
 	user := &models.User{
 		ID:       1,
@@ -225,7 +247,7 @@ func TestAuthService_Login_InvalidPassword(t *testing.T) {
 	}
 
 	mockUserRepo.On("GetByEmail", "test@example.com").Return(user, nil)
-	mockJWTService.On("CheckPassword", "wrongpassword", "hashedpassword").Return(false)
+	mockJWTService.On("VerifyPassword", "wrongpassword", "hashedpassword").Return(false, false)
 
 	result, err := authService.Login(loginReq)
 
@@ -240,7 +262,7 @@ func TestAuthService_Login_UserNotFound(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockJWTService := new(MockJWTService)
 	
-	authService := services.NewAuthService(mockUserRepo, mockJWTService, nil)
+	authService := services.NewAuthService(mockUserRepo, nil, mockJWTService, nil)
 
 	loginReq := &models.LoginRequest{
 		Email:    "nonexistent@example.com",
@@ -261,7 +283,7 @@ func TestAuthService_RefreshToken_Success(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockJWTService := new(MockJWTService)
 	
-	authService := services.NewAuthService(mockUserRepo, mockJWTService, nil)
+	authService := services.NewAuthService(mockUserRepo, nil, mockJWTService, nil)
 
 	refreshResponse := &models.RefreshTokenResponse{
 		AccessToken:  "new_access_token",
@@ -289,7 +311,7 @@ func TestAuthService_RefreshToken_Invalid(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockJWTService := new(MockJWTService)
 	
-	authService := services.NewAuthService(mockUserRepo, mockJWTService, nil)
+	authService := services.NewAuthService(mockUserRepo, nil, mockJWTService, nil)
 
 	refreshReq := &models.RefreshTokenRequest{
 		RefreshToken: "invalid_refresh_token",
@@ -308,7 +330,7 @@ func TestAuthService_RefreshToken_Invalid(t *testing.T) {
 // Test JWT Service
 func TestJWTService_HashPassword(t *testing.T) {
 	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
-	jwtService := services.NewJWTService(mockRefreshTokenRepo)
+	jwtService := services.NewJWTService(mockRefreshTokenRepo, nil, &config.Config{})
 
 	password := "testpassword123"
 	
@@ -321,7 +343,7 @@ func TestJWTService_HashPassword(t *testing.T) {
 
 func TestJWTService_CheckPassword(t *testing.T) {
 	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
-	jwtService := services.NewJWTService(mockRefreshTokenRepo)
+	jwtService := services.NewJWTService(mockRefreshTokenRepo, nil, &config.Config{})
 
 	password := "testpassword123"
 	hash, _ := jwtService.HashPassword(password)
@@ -337,7 +359,7 @@ func TestJWTService_CheckPassword(t *testing.T) {
 
 func TestJWTService_GenerateTokenPair(t *testing.T) {
 	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
-	jwtService := services.NewJWTService(mockRefreshTokenRepo)
+	jwtService := services.NewJWTService(mockRefreshTokenRepo, nil, &config.Config{})
 
 	user := &models.User{
 		ID:       1,
@@ -361,7 +383,7 @@ func TestJWTService_GenerateTokenPair(t *testing.T) {
 
 func TestJWTService_ValidateAccessToken(t *testing.T) {
 	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
-	jwtService := services.NewJWTService(mockRefreshTokenRepo)
+	jwtService := services.NewJWTService(mockRefreshTokenRepo, nil, &config.Config{})
 
 	user := &models.User{
 		ID:       1,
@@ -389,7 +411,7 @@ func TestJWTService_ValidateAccessToken(t *testing.T) {
 
 func TestJWTService_ValidateAccessToken_Invalid(t *testing.T) {
 	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
-	jwtService := services.NewJWTService(mockRefreshTokenRepo)
+	jwtService := services.NewJWTService(mockRefreshTokenRepo, nil, &config.Config{})
 
 	// Test with invalid token
 	claims, err := jwtService.ValidateAccessToken("invalid_token")
@@ -400,7 +422,7 @@ func TestJWTService_ValidateAccessToken_Invalid(t *testing.T) {
 
 func TestJWTService_ValidateRefreshToken_Success(t *testing.T) {
 	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
-	jwtService := services.NewJWTService(mockRefreshTokenRepo)
+	jwtService := services.NewJWTService(mockRefreshTokenRepo, nil, &config.Config{})
 
 	refreshToken := &models.RefreshToken{
 		ID:        1,
@@ -429,7 +451,7 @@ func TestJWTService_ValidateRefreshToken_Success(t *testing.T) {
 
 func TestJWTService_ValidateRefreshToken_Expired(t *testing.T) {
 	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
-	jwtService := services.NewJWTService(mockRefreshTokenRepo)
+	jwtService := services.NewJWTService(mockRefreshTokenRepo, nil, &config.Config{})
 
 	refreshToken := &models.RefreshToken{
 		ID:        1,
@@ -451,7 +473,7 @@ func TestJWTService_ValidateRefreshToken_Expired(t *testing.T) {
 
 func TestJWTService_ValidateRefreshToken_Revoked(t *testing.T) {
 	mockRefreshTokenRepo := new(MockRefreshTokenRepository)
-	jwtService := services.NewJWTService(mockRefreshTokenRepo)
+	jwtService := services.NewJWTService(mockRefreshTokenRepo, nil, &config.Config{})
 
 	refreshToken := &models.RefreshToken{
 		ID:        1,
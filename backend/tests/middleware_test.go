@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -41,7 +42,7 @@ func TestRateLimitMiddleware(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := gin.New()
-			r.Use(middleware.RateLimitMiddleware(tt.requestsPerMin))
+			r.Use(middleware.RateLimitMiddleware(middleware.NewRateLimiter(0), tt.requestsPerMin))
 			
 			r.GET("/test", func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{"message": "success"})
@@ -125,7 +126,7 @@ func TestCORSMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	r := gin.New()
-	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.CORSMiddleware(&config.SecurityConfig{CORSAllowCredentials: true, CORSMaxAge: 12 * time.Hour}))
 	
 	r.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
@@ -160,7 +161,7 @@ func TestSecurityHeadersMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	r := gin.New()
-	r.Use(middleware.SecurityHeadersMiddleware())
+	r.Use(middleware.SecurityHeadersMiddleware(&config.SecurityConfig{HSTS: true, ContentSecurityPolicy: "default-src 'self'"}))
 	
 	r.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
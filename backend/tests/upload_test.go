@@ -57,13 +57,15 @@ func TestUploadImageHandler(t *testing.T) {
 	// Initialize repositories and services
 	userRepo := repositories.NewUserRepository(db)
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
-	jwtService := services.NewJWTService(refreshTokenRepo)
-	authService := services.NewAuthService(userRepo, jwtService, cfg)
-	storageService := services.NewStorageService(cfg)
+	revokedTokenRepo := repositories.NewRevokedAccessTokenRepository(db)
+	jwtService := services.NewJWTService(refreshTokenRepo, revokedTokenRepo, cfg)
+	authService := services.NewAuthService(userRepo, repositories.NewPasswordResetTokenRepository(db), jwtService, cfg)
+	storageService, err := services.NewStorageService(cfg, repositories.NewFileUploadRepository(db))
+	require.NoError(t, err)
 	
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	uploadHandler := handlers.NewUploadHandler(storageService, cfg)
+	uploadHandler := handlers.NewUploadHandler(storageService, repositories.NewFileUploadRepository(db), cfg)
 	
 	// Setup router
 	r := gin.New()
@@ -294,7 +296,8 @@ func TestStorageServiceValidation(t *testing.T) {
 		MaxFileSize: 1024, // 1KB for testing
 	}
 	
-	storageService := services.NewLocalStorageService(cfg)
+	storageService, err := services.NewLocalStorageService(cfg, nil)
+	require.NoError(t, err)
 	defer os.RemoveAll(cfg.UploadDir)
 	
 	t.Run("Valid Image File", func(t *testing.T) {
@@ -319,7 +322,7 @@ func TestStorageServiceValidation(t *testing.T) {
 		fileHeader.Header.Set("Content-Type", "image/jpeg")
 		
 		err := storageService.ValidateImageFile(fileHeader)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, services.ErrFileTooLarge)
 		assert.Contains(t, err.Error(), "exceeds maximum allowed size")
 	})
 	
@@ -332,7 +335,7 @@ func TestStorageServiceValidation(t *testing.T) {
 		fileHeader.Header.Set("Content-Type", "application/pdf")
 		
 		err := storageService.ValidateImageFile(fileHeader)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, services.ErrInvalidImage)
 		assert.Contains(t, err.Error(), "file type not allowed")
 	})
 	
@@ -345,7 +348,7 @@ func TestStorageServiceValidation(t *testing.T) {
 		fileHeader.Header.Set("Content-Type", "text/plain")
 		
 		err := storageService.ValidateImageFile(fileHeader)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, services.ErrInvalidImage)
 		assert.Contains(t, err.Error(), "invalid MIME type")
 	})
 }